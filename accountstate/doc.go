@@ -0,0 +1,11 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides account state blob decoding, and BCS deserializers for common on-chain resources
+// that are not fully exposed by the JSON-RPC views, e.g. `DiemAccount::DiemAccount`,
+// `DiemAccount::Balance<Currency>`, `VASP::ParentVASP`, `DualAttestation::Credential`,
+// `AccountFreezing::FreezingBit`, `Roles::RoleId`, `SlidingNonce::SlidingNonce`,
+// `Diem::PreburnQueue<Currency>` and `DiemConfig::DiemConfig<DiemVMConfig::DiemVMConfig>`.
+//
+// Account state blob can be retrieved by `diemclient.StateClient#GetAccountStateBlob`.
+package accountstate
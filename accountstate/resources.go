@@ -0,0 +1,486 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package accountstate
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+var coreCodeAddress = diemtypes.AccountAddress{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+
+// Struct tags of the resources
+var (
+	DiemAccountTag  = structTag("DiemAccount", "DiemAccount")
+	ParentVASPTag   = structTag("VASP", "ParentVASP")
+	ChildVASPTag    = structTag("VASP", "ChildVASP")
+	CredentialTag   = structTag("DualAttestation", "Credential")
+	FreezingBitTag  = structTag("AccountFreezing", "FreezingBit")
+	RoleIDTag       = structTag("Roles", "RoleId")
+	SlidingNonceTag = structTag("SlidingNonce", "SlidingNonce")
+)
+
+// BalanceTag returns struct tag of `0x1::DiemAccount::Balance<Currency>` resource for the
+// given currency code
+func BalanceTag(currency string) diemtypes.StructTag {
+	ret := structTag("DiemAccount", "Balance")
+	ret.TypeParams = []diemtypes.TypeTag{diemtypes.Currency(currency)}
+	return ret
+}
+
+// PreburnQueueTag returns struct tag of `0x1::Diem::PreburnQueue<Currency>` resource for the
+// given currency code
+func PreburnQueueTag(currency string) diemtypes.StructTag {
+	ret := structTag("Diem", "PreburnQueue")
+	ret.TypeParams = []diemtypes.TypeTag{diemtypes.Currency(currency)}
+	return ret
+}
+
+// DiemVMConfigTag is struct tag of `0x1::DiemConfig::DiemConfig<0x1::DiemVMConfig::DiemVMConfig>`
+// resource, the on-chain VM config published under the Diem root account
+var DiemVMConfigTag = diemConfigTag(structTag("DiemVMConfig", "DiemVMConfig"))
+
+func diemConfigTag(config diemtypes.StructTag) diemtypes.StructTag {
+	ret := structTag("DiemConfig", "DiemConfig")
+	ret.TypeParams = []diemtypes.TypeTag{&diemtypes.TypeTag__Struct{Value: config}}
+	return ret
+}
+
+// RoleID is the value of `0x1::Roles::RoleId` resource
+type RoleID uint64
+
+// Role ids defined by `0x1::Roles` module
+const (
+	RoleDiemRoot           RoleID = 0
+	RoleTreasuryCompliance RoleID = 1
+	RoleDesignatedDealer   RoleID = 2
+	RoleValidator          RoleID = 3
+	RoleValidatorOperator  RoleID = 4
+	RoleParentVASP         RoleID = 5
+	RoleChildVASP          RoleID = 6
+)
+
+// String returns role name, e.g. "parent_vasp"
+func (r RoleID) String() string {
+	switch r {
+	case RoleDiemRoot:
+		return "diem_root"
+	case RoleTreasuryCompliance:
+		return "treasury_compliance"
+	case RoleDesignatedDealer:
+		return "designated_dealer"
+	case RoleValidator:
+		return "validator"
+	case RoleValidatorOperator:
+		return "validator_operator"
+	case RoleParentVASP:
+		return "parent_vasp"
+	case RoleChildVASP:
+		return "child_vasp"
+	}
+	return fmt.Sprintf("unknown(%d)", uint64(r))
+}
+
+// EventHandle is `0x1::Event::EventHandle` of a resource
+type EventHandle struct {
+	Count uint64
+	Key   []byte
+}
+
+// KeyHex returns hex-encoded event key, which is the key for `diemclient.Client#GetEvents`
+func (h *EventHandle) KeyHex() string {
+	return hex.EncodeToString(h.Key)
+}
+
+// DiemAccount is `0x1::DiemAccount::DiemAccount` resource
+type DiemAccount struct {
+	AuthenticationKey []byte
+	// WithdrawCapability is the account address of the withdraw capability, nil if the
+	// capability is extracted
+	WithdrawCapability *diemtypes.AccountAddress
+	// KeyRotationCapability is the account address of the key rotation capability, nil if the
+	// capability is extracted, e.g. delegated to a recovery address
+	KeyRotationCapability *diemtypes.AccountAddress
+	ReceivedEvents        EventHandle
+	SentEvents            EventHandle
+	SequenceNumber        uint64
+}
+
+// ParentVASP is `0x1::VASP::ParentVASP` resource
+type ParentVASP struct {
+	NumChildren uint64
+}
+
+// ChildVASP is `0x1::VASP::ChildVASP` resource
+type ChildVASP struct {
+	ParentVASPAddress diemtypes.AccountAddress
+}
+
+// Credential is `0x1::DualAttestation::Credential` resource
+type Credential struct {
+	HumanName                   string
+	BaseURL                     string
+	ComplianceKey               []byte
+	ExpirationDate              uint64
+	ComplianceKeyRotationEvents EventHandle
+	BaseURLRotationEvents       EventHandle
+}
+
+// FreezingBit is `0x1::AccountFreezing::FreezingBit` resource
+type FreezingBit struct {
+	IsFrozen bool
+}
+
+// PreburnQueue is `0x1::Diem::PreburnQueue<Currency>` resource of designated dealer accounts,
+// it holds outstanding preburn requests of the currency in order.
+type PreburnQueue struct {
+	Preburns []PreburnWithMetadata
+}
+
+// PreburnWithMetadata is `0x1::Diem::PreburnWithMetadata<Currency>`, a preburn request
+type PreburnWithMetadata struct {
+	// Amount is the amount to burn, i.e. value of `Diem::Preburn#to_burn`
+	Amount   uint64
+	Metadata []byte
+}
+
+// Total returns sum of amounts of all preburn requests
+func (q *PreburnQueue) Total() uint64 {
+	var ret uint64
+	for _, preburn := range q.Preburns {
+		ret += preburn.Amount
+	}
+	return ret
+}
+
+// Contains returns true if there is a preburn request of the amount, which can be burnt or
+// cancelled by `burn_with_amount` or `cancel_burn_with_amount` script.
+func (q *PreburnQueue) Contains(amount uint64) bool {
+	for _, preburn := range q.Preburns {
+		if preburn.Amount == amount {
+			return true
+		}
+	}
+	return false
+}
+
+// SlidingNonce is `0x1::SlidingNonce::SlidingNonce` resource of treasury compliance and
+// designated dealer accounts. Bit i of the `NonceMask` is set if nonce `MinNonce + i` is
+// recorded.
+type SlidingNonce struct {
+	MinNonce  uint64
+	NonceMask serde.Uint128
+}
+
+// IsAvailable returns true if the nonce is not recorded and not too old, i.e. it is not less
+// than `MinNonce`. It does not check the nonce is too new.
+func (n *SlidingNonce) IsAvailable(nonce uint64) bool {
+	if nonce < n.MinNonce {
+		return false
+	}
+	switch offset := nonce - n.MinNonce; {
+	case offset < 64:
+		return n.NonceMask.Low&(1<<offset) == 0
+	case offset < 128:
+		return n.NonceMask.High&(1<<(offset-64)) == 0
+	}
+	return true
+}
+
+// NextNonce returns the smallest nonce that is greater than all recorded nonces
+func (n *SlidingNonce) NextNonce() uint64 {
+	if n.NonceMask.High != 0 {
+		return n.MinNonce + 64 + uint64(bits.Len64(n.NonceMask.High))
+	}
+	return n.MinNonce + uint64(bits.Len64(n.NonceMask.Low))
+}
+
+// DiemVMConfig is `0x1::DiemVMConfig::DiemVMConfig` on-chain config, the instruction and native
+// gas cost tables are BCS bytes of the cost vectors.
+type DiemVMConfig struct {
+	InstructionSchedule []byte
+	NativeSchedule      []byte
+	GasConstants        GasConstants
+}
+
+// GasConstants is `0x1::DiemVMConfig::GasConstants` of the on-chain gas schedule. Gas costs
+// are in internal gas units, which are `GasUnitScalingFactor` times of the gas units of
+// transaction max gas amount and gas used; `MaximumNumberOfGasUnits` is in gas units.
+type GasConstants struct {
+	GlobalMemoryPerByteCost      uint64
+	GlobalMemoryPerByteWriteCost uint64
+	MinTransactionGasUnits       uint64
+	LargeTransactionCutoff       uint64
+	IntrinsicGasPerByte          uint64
+	MaximumNumberOfGasUnits      uint64
+	MinPricePerGasUnit           uint64
+	MaxPricePerGasUnit           uint64
+	MaxTransactionSizeInBytes    uint64
+	GasUnitScalingFactor         uint64
+	DefaultAccountSize           uint64
+}
+
+// IntrinsicGas returns the gas units charged for a transaction of given raw transaction BCS
+// size before executing it, which is the minimum gas used of the transaction.
+func (c *GasConstants) IntrinsicGas(txnSize uint64) uint64 {
+	ret := c.MinTransactionGasUnits
+	if txnSize > c.LargeTransactionCutoff {
+		ret += (txnSize - c.LargeTransactionCutoff) * c.IntrinsicGasPerByte
+	}
+	if c.GasUnitScalingFactor > 0 {
+		ret /= c.GasUnitScalingFactor
+	}
+	return ret
+}
+
+// DecodeDiemAccount decodes BCS bytes of `0x1::DiemAccount::DiemAccount` resource
+func DecodeDiemAccount(bytes []byte) (*DiemAccount, error) {
+	d := newDecoder(bytes)
+	ret := DiemAccount{
+		AuthenticationKey:     d.bytes(),
+		WithdrawCapability:    d.optionalAddress(),
+		KeyRotationCapability: d.optionalAddress(),
+		ReceivedEvents:        d.eventHandle(),
+		SentEvents:            d.eventHandle(),
+		SequenceNumber:        d.u64(),
+	}
+	if err := d.finish(DiemAccountTag); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// DecodeBalance decodes BCS bytes of `0x1::DiemAccount::Balance<Currency>` resource, returns the
+// balance amount
+func DecodeBalance(bytes []byte) (uint64, error) {
+	d := newDecoder(bytes)
+	ret := d.u64()
+	if err := d.finish(structTag("DiemAccount", "Balance")); err != nil {
+		return 0, err
+	}
+	return ret, nil
+}
+
+// DecodeParentVASP decodes BCS bytes of `0x1::VASP::ParentVASP` resource
+func DecodeParentVASP(bytes []byte) (*ParentVASP, error) {
+	d := newDecoder(bytes)
+	ret := ParentVASP{NumChildren: d.u64()}
+	if err := d.finish(ParentVASPTag); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// DecodeChildVASP decodes BCS bytes of `0x1::VASP::ChildVASP` resource
+func DecodeChildVASP(bytes []byte) (*ChildVASP, error) {
+	d := newDecoder(bytes)
+	ret := ChildVASP{ParentVASPAddress: d.address()}
+	if err := d.finish(ChildVASPTag); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// DecodeCredential decodes BCS bytes of `0x1::DualAttestation::Credential` resource
+func DecodeCredential(bytes []byte) (*Credential, error) {
+	d := newDecoder(bytes)
+	ret := Credential{
+		HumanName:                   string(d.bytes()),
+		BaseURL:                     string(d.bytes()),
+		ComplianceKey:               d.bytes(),
+		ExpirationDate:              d.u64(),
+		ComplianceKeyRotationEvents: d.eventHandle(),
+		BaseURLRotationEvents:       d.eventHandle(),
+	}
+	if err := d.finish(CredentialTag); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// DecodeFreezingBit decodes BCS bytes of `0x1::AccountFreezing::FreezingBit` resource
+func DecodeFreezingBit(bytes []byte) (*FreezingBit, error) {
+	d := newDecoder(bytes)
+	ret := FreezingBit{IsFrozen: d.boolean()}
+	if err := d.finish(FreezingBitTag); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// DecodeRoleID decodes BCS bytes of `0x1::Roles::RoleId` resource
+func DecodeRoleID(bytes []byte) (RoleID, error) {
+	d := newDecoder(bytes)
+	ret := RoleID(d.u64())
+	if err := d.finish(RoleIDTag); err != nil {
+		return 0, err
+	}
+	return ret, nil
+}
+
+// DecodeSlidingNonce decodes BCS bytes of `0x1::SlidingNonce::SlidingNonce` resource
+func DecodeSlidingNonce(bytes []byte) (*SlidingNonce, error) {
+	d := newDecoder(bytes)
+	ret := SlidingNonce{MinNonce: d.u64(), NonceMask: d.u128()}
+	if err := d.finish(SlidingNonceTag); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// DecodePreburnQueue decodes BCS bytes of `0x1::Diem::PreburnQueue<Currency>` resource
+func DecodePreburnQueue(bytes []byte) (*PreburnQueue, error) {
+	d := newDecoder(bytes)
+	var ret PreburnQueue
+	length := d.length()
+	for i := uint64(0); i < length && d.err == nil; i++ {
+		ret.Preburns = append(ret.Preburns, PreburnWithMetadata{Amount: d.u64(), Metadata: d.bytes()})
+	}
+	if err := d.finish(structTag("Diem", "PreburnQueue")); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// DecodeDiemVMConfig decodes BCS bytes of
+// `0x1::DiemConfig::DiemConfig<0x1::DiemVMConfig::DiemVMConfig>` resource
+func DecodeDiemVMConfig(bytes []byte) (*DiemVMConfig, error) {
+	d := newDecoder(bytes)
+	ret := DiemVMConfig{
+		InstructionSchedule: d.bytes(),
+		NativeSchedule:      d.bytes(),
+		GasConstants: GasConstants{
+			GlobalMemoryPerByteCost:      d.u64(),
+			GlobalMemoryPerByteWriteCost: d.u64(),
+			MinTransactionGasUnits:       d.u64(),
+			LargeTransactionCutoff:       d.u64(),
+			IntrinsicGasPerByte:          d.u64(),
+			MaximumNumberOfGasUnits:      d.u64(),
+			MinPricePerGasUnit:           d.u64(),
+			MaxPricePerGasUnit:           d.u64(),
+			MaxTransactionSizeInBytes:    d.u64(),
+			GasUnitScalingFactor:         d.u64(),
+			DefaultAccountSize:           d.u64(),
+		},
+	}
+	if err := d.finish(DiemVMConfigTag); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func structTag(module, name string) diemtypes.StructTag {
+	return diemtypes.StructTag{
+		Address:    coreCodeAddress,
+		Module:     diemtypes.Identifier(module),
+		Name:       diemtypes.Identifier(name),
+		TypeParams: []diemtypes.TypeTag{},
+	}
+}
+
+// decoder records the first error, following reads after error return zero values
+type decoder struct {
+	d     serde.Deserializer
+	input []byte
+	err   error
+}
+
+func newDecoder(input []byte) *decoder {
+	return &decoder{d: bcs.NewDeserializer(input), input: input}
+}
+
+func (d *decoder) bytes() []byte {
+	if d.err != nil {
+		return nil
+	}
+	ret, err := d.d.DeserializeBytes()
+	d.err = err
+	return ret
+}
+
+func (d *decoder) length() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	ret, err := d.d.DeserializeLen()
+	d.err = err
+	return ret
+}
+
+func (d *decoder) u64() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	ret, err := d.d.DeserializeU64()
+	d.err = err
+	return ret
+}
+
+func (d *decoder) u128() serde.Uint128 {
+	if d.err != nil {
+		return serde.Uint128{}
+	}
+	ret, err := d.d.DeserializeU128()
+	d.err = err
+	return ret
+}
+
+func (d *decoder) boolean() bool {
+	if d.err != nil {
+		return false
+	}
+	ret, err := d.d.DeserializeBool()
+	d.err = err
+	return ret
+}
+
+func (d *decoder) address() diemtypes.AccountAddress {
+	if d.err != nil {
+		return diemtypes.AccountAddress{}
+	}
+	ret, err := diemtypes.DeserializeAccountAddress(d.d)
+	d.err = err
+	return ret
+}
+
+// optionalAddress decodes Move `Option` of a struct with single address field, which is a
+// vector of zero or one element
+func (d *decoder) optionalAddress() *diemtypes.AccountAddress {
+	if d.err != nil {
+		return nil
+	}
+	length, err := d.d.DeserializeLen()
+	if err != nil {
+		d.err = err
+		return nil
+	}
+	switch length {
+	case 0:
+		return nil
+	case 1:
+		ret := d.address()
+		return &ret
+	}
+	d.err = fmt.Errorf("invalid option length %d", length)
+	return nil
+}
+
+func (d *decoder) eventHandle() EventHandle {
+	return EventHandle{Count: d.u64(), Key: d.bytes()}
+}
+
+func (d *decoder) finish(tag diemtypes.StructTag) error {
+	if d.err == nil && d.d.GetBufferOffset() != uint64(len(d.input)) {
+		d.err = fmt.Errorf("some input bytes were not read")
+	}
+	if d.err != nil {
+		return fmt.Errorf("decode %s failed: %v", &tag, d.err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package accountstate_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	address  = diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	eventKey = append([]byte{2, 0, 0, 0, 0, 0, 0, 0}, address[:]...)
+)
+
+// encode serializes values in order, supports []byte, uint64, bool, diemtypes.AccountAddress
+// and nil, which is serialized as empty Move `Option`
+func encode(t *testing.T, values ...interface{}) []byte {
+	s := bcs.NewSerializer()
+	for _, value := range values {
+		require.NoError(t, serialize(s, value))
+	}
+	return s.GetBytes()
+}
+
+func serialize(s serde.Serializer, value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return s.SerializeBytes(v)
+	case uint64:
+		return s.SerializeU64(v)
+	case bool:
+		return s.SerializeBool(v)
+	case diemtypes.AccountAddress:
+		return v.Serialize(s)
+	case *diemtypes.AccountAddress:
+		if err := s.SerializeLen(1); err != nil {
+			return err
+		}
+		return v.Serialize(s)
+	case nil:
+		return s.SerializeLen(0)
+	}
+	panic("unsupported value")
+}
+
+func diemAccountBytes(t *testing.T) []byte {
+	return encode(t, []byte{1, 2}, &address, nil, uint64(3), eventKey, uint64(4), eventKey, uint64(5))
+}
+
+func TestDecodeDiemAccount(t *testing.T) {
+	ret, err := accountstate.DecodeDiemAccount(diemAccountBytes(t))
+	require.NoError(t, err)
+	assert.Equal(t, &accountstate.DiemAccount{
+		AuthenticationKey:     []byte{1, 2},
+		WithdrawCapability:    &address,
+		KeyRotationCapability: nil,
+		ReceivedEvents:        accountstate.EventHandle{Count: 3, Key: eventKey},
+		SentEvents:            accountstate.EventHandle{Count: 4, Key: eventKey},
+		SequenceNumber:        5,
+	}, ret)
+	assert.Equal(t, "0200000000000000f72589b71ff4f8d139674a3f7369c69b", ret.ReceivedEvents.KeyHex())
+
+	t.Run("invalid option", func(t *testing.T) {
+		_, err := accountstate.DecodeDiemAccount(encode(t, []byte{1}, uint64(2)))
+		assert.Error(t, err)
+	})
+	t.Run("not all bytes read", func(t *testing.T) {
+		_, err := accountstate.DecodeDiemAccount(append(diemAccountBytes(t), 0))
+		assert.EqualError(t, err, "decode 0x1::DiemAccount::DiemAccount failed: some input bytes were not read")
+	})
+	t.Run("unexpected end of input", func(t *testing.T) {
+		bytes := diemAccountBytes(t)
+		_, err := accountstate.DecodeDiemAccount(bytes[:len(bytes)-1])
+		assert.Error(t, err)
+	})
+}
+
+func TestDecodeResources(t *testing.T) {
+	balance, err := accountstate.DecodeBalance(encode(t, uint64(100)))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), balance)
+
+	parent, err := accountstate.DecodeParentVASP(encode(t, uint64(2)))
+	require.NoError(t, err)
+	assert.Equal(t, &accountstate.ParentVASP{NumChildren: 2}, parent)
+
+	child, err := accountstate.DecodeChildVASP(encode(t, address))
+	require.NoError(t, err)
+	assert.Equal(t, &accountstate.ChildVASP{ParentVASPAddress: address}, child)
+
+	credential, err := accountstate.DecodeCredential(encode(t, []byte("vasp"), []byte("https://vasp.com"),
+		[]byte{0xab}, uint64(1000), uint64(1), eventKey, uint64(0), eventKey))
+	require.NoError(t, err)
+	assert.Equal(t, &accountstate.Credential{
+		HumanName:                   "vasp",
+		BaseURL:                     "https://vasp.com",
+		ComplianceKey:               []byte{0xab},
+		ExpirationDate:              1000,
+		ComplianceKeyRotationEvents: accountstate.EventHandle{Count: 1, Key: eventKey},
+		BaseURLRotationEvents:       accountstate.EventHandle{Count: 0, Key: eventKey},
+	}, credential)
+
+	freezing, err := accountstate.DecodeFreezingBit(encode(t, true))
+	require.NoError(t, err)
+	assert.True(t, freezing.IsFrozen)
+
+	role, err := accountstate.DecodeRoleID(encode(t, uint64(5)))
+	require.NoError(t, err)
+	assert.Equal(t, accountstate.RoleParentVASP, role)
+	assert.Equal(t, "parent_vasp", role.String())
+	assert.Equal(t, "unknown(9)", accountstate.RoleID(9).String())
+
+	nonce, err := accountstate.DecodeSlidingNonce(append(encode(t, uint64(10), uint64(0b101)), make([]byte, 8)...))
+	require.NoError(t, err)
+	assert.Equal(t, &accountstate.SlidingNonce{MinNonce: 10, NonceMask: serde.Uint128{Low: 0b101}}, nonce)
+	assert.Equal(t, uint64(13), nonce.NextNonce())
+	assert.Equal(t, uint64(10), (&accountstate.SlidingNonce{MinNonce: 10}).NextNonce())
+	for n, available := range map[uint64]bool{9: false, 10: false, 11: true, 12: false, 13: true, 200: true} {
+		assert.Equal(t, available, nonce.IsAvailable(n), n)
+	}
+	full := &accountstate.SlidingNonce{MinNonce: 10, NonceMask: serde.Uint128{High: 1 << 63, Low: 1}}
+	assert.Equal(t, uint64(138), full.NextNonce())
+	assert.False(t, full.IsAvailable(137))
+	assert.True(t, full.IsAvailable(136))
+
+	queue, err := accountstate.DecodePreburnQueue(append([]byte{2}, encode(t, uint64(100), []byte{}, uint64(200), []byte{1})...))
+	require.NoError(t, err)
+	assert.Equal(t, &accountstate.PreburnQueue{Preburns: []accountstate.PreburnWithMetadata{
+		{Amount: 100, Metadata: []byte{}},
+		{Amount: 200, Metadata: []byte{1}},
+	}}, queue)
+	assert.Equal(t, uint64(300), queue.Total())
+	assert.True(t, queue.Contains(100))
+	assert.False(t, queue.Contains(1))
+	empty, err := accountstate.DecodePreburnQueue([]byte{0})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), empty.Total())
+	_, err = accountstate.DecodePreburnQueue(append([]byte{2}, encode(t, uint64(100), []byte{})...))
+	assert.Error(t, err)
+
+	config, err := accountstate.DecodeDiemVMConfig(encode(t, []byte{1}, []byte{2}, uint64(4), uint64(4),
+		uint64(600_000), uint64(600), uint64(8_000), uint64(4_000_000), uint64(0), uint64(10_000),
+		uint64(4096), uint64(1000), uint64(800)))
+	require.NoError(t, err)
+	assert.Equal(t, &accountstate.DiemVMConfig{
+		InstructionSchedule: []byte{1},
+		NativeSchedule:      []byte{2},
+		GasConstants: accountstate.GasConstants{
+			GlobalMemoryPerByteCost:      4,
+			GlobalMemoryPerByteWriteCost: 4,
+			MinTransactionGasUnits:       600_000,
+			LargeTransactionCutoff:       600,
+			IntrinsicGasPerByte:          8_000,
+			MaximumNumberOfGasUnits:      4_000_000,
+			MinPricePerGasUnit:           0,
+			MaxPricePerGasUnit:           10_000,
+			MaxTransactionSizeInBytes:    4096,
+			GasUnitScalingFactor:         1000,
+			DefaultAccountSize:           800,
+		},
+	}, config)
+	assert.Equal(t, uint64(600), config.GasConstants.IntrinsicGas(100))
+	assert.Equal(t, uint64(1400), config.GasConstants.IntrinsicGas(700))
+	assert.Equal(t, "0x1::DiemConfig::DiemConfig<0x1::DiemVMConfig::DiemVMConfig>", accountstate.DiemVMConfigTag.String())
+
+	_, err = accountstate.DecodeFreezingBit([]byte{2})
+	assert.Error(t, err)
+	_, err = accountstate.DecodeBalance([]byte{1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package accountstate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+)
+
+// resourcePathTag is the BCS variant index of resource access path
+const resourcePathTag = 1
+
+// ErrResourceNotFound is returned when the resource is not published under the account
+var ErrResourceNotFound = errors.New("resource not found")
+
+// AccountState is decoded account state blob, it maps resource access path to resource BCS
+// bytes.
+type AccountState struct {
+	resources map[string][]byte
+}
+
+// Decode decodes BCS bytes of account state blob, which is the `blob` of JSON-RPC
+// "get_account_state_with_proof" method response.
+func Decode(blob []byte) (*AccountState, error) {
+	d := bcs.NewDeserializer(blob)
+	state, err := d.DeserializeBytes()
+	if err != nil {
+		return nil, fmt.Errorf("decode account state blob failed: %v", err)
+	}
+	if d.GetBufferOffset() != uint64(len(blob)) {
+		return nil, errors.New("decode account state blob failed: some input bytes were not read")
+	}
+
+	d = bcs.NewDeserializer(state)
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, fmt.Errorf("decode account state failed: %v", err)
+	}
+	ret := AccountState{resources: make(map[string][]byte, length)}
+	for i := uint64(0); i < length; i++ {
+		path, err := d.DeserializeBytes()
+		if err != nil {
+			return nil, fmt.Errorf("decode account state failed: %v", err)
+		}
+		value, err := d.DeserializeBytes()
+		if err != nil {
+			return nil, fmt.Errorf("decode account state failed: %v", err)
+		}
+		ret.resources[string(path)] = value
+	}
+	if d.GetBufferOffset() != uint64(len(state)) {
+		return nil, errors.New("decode account state failed: some input bytes were not read")
+	}
+	return &ret, nil
+}
+
+// ResourcePath returns access path of the resource struct tag
+func ResourcePath(tag diemtypes.StructTag) []byte {
+	return append([]byte{resourcePathTag}, diemtypes.ToBCS(&tag)...)
+}
+
+// Resource returns BCS bytes of the resource, returns false if the resource is not published
+// under the account.
+func (s *AccountState) Resource(tag diemtypes.StructTag) ([]byte, bool) {
+	ret, ok := s.resources[string(ResourcePath(tag))]
+	return ret, ok
+}
+
+// ResourceTags returns struct tags of all the resources in the account state
+func (s *AccountState) ResourceTags() []diemtypes.StructTag {
+	var ret []diemtypes.StructTag
+	for path := range s.resources {
+		if len(path) == 0 || path[0] != resourcePathTag {
+			continue
+		}
+		tag, err := diemtypes.BcsDeserializeStructTag([]byte(path[1:]))
+		if err != nil {
+			continue
+		}
+		ret = append(ret, tag)
+	}
+	return ret
+}
+
+// DiemAccount decodes `0x1::DiemAccount::DiemAccount` resource
+func (s *AccountState) DiemAccount() (*DiemAccount, error) {
+	bytes, err := s.resource(DiemAccountTag)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeDiemAccount(bytes)
+}
+
+// Balance decodes `0x1::DiemAccount::Balance<Currency>` resource and returns the balance
+// amount of the given currency code
+func (s *AccountState) Balance(currency string) (uint64, error) {
+	bytes, err := s.resource(BalanceTag(currency))
+	if err != nil {
+		return 0, err
+	}
+	return DecodeBalance(bytes)
+}
+
+// Balances decodes all `0x1::DiemAccount::Balance<Currency>` resources, returns balance amount
+// by currency code
+func (s *AccountState) Balances() (map[string]uint64, error) {
+	ret := make(map[string]uint64)
+	for _, tag := range s.ResourceTags() {
+		if tag.Address != DiemAccountTag.Address || tag.Module != "DiemAccount" ||
+			tag.Name != "Balance" || len(tag.TypeParams) != 1 {
+			continue
+		}
+		currency, ok := tag.TypeParams[0].(*diemtypes.TypeTag__Struct)
+		if !ok {
+			continue
+		}
+		amount, err := s.Balance(string(currency.Value.Name))
+		if err != nil {
+			return nil, err
+		}
+		ret[string(currency.Value.Name)] = amount
+	}
+	return ret, nil
+}
+
+// ParentVASP decodes `0x1::VASP::ParentVASP` resource
+func (s *AccountState) ParentVASP() (*ParentVASP, error) {
+	bytes, err := s.resource(ParentVASPTag)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeParentVASP(bytes)
+}
+
+// ChildVASP decodes `0x1::VASP::ChildVASP` resource
+func (s *AccountState) ChildVASP() (*ChildVASP, error) {
+	bytes, err := s.resource(ChildVASPTag)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeChildVASP(bytes)
+}
+
+// Credential decodes `0x1::DualAttestation::Credential` resource
+func (s *AccountState) Credential() (*Credential, error) {
+	bytes, err := s.resource(CredentialTag)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeCredential(bytes)
+}
+
+// FreezingBit decodes `0x1::AccountFreezing::FreezingBit` resource
+func (s *AccountState) FreezingBit() (*FreezingBit, error) {
+	bytes, err := s.resource(FreezingBitTag)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeFreezingBit(bytes)
+}
+
+// RoleID decodes `0x1::Roles::RoleId` resource
+func (s *AccountState) RoleID() (RoleID, error) {
+	bytes, err := s.resource(RoleIDTag)
+	if err != nil {
+		return 0, err
+	}
+	return DecodeRoleID(bytes)
+}
+
+// PreburnQueue decodes `0x1::Diem::PreburnQueue<Currency>` resource of the given currency code
+func (s *AccountState) PreburnQueue(currency string) (*PreburnQueue, error) {
+	bytes, err := s.resource(PreburnQueueTag(currency))
+	if err != nil {
+		return nil, err
+	}
+	return DecodePreburnQueue(bytes)
+}
+
+// SlidingNonce decodes `0x1::SlidingNonce::SlidingNonce` resource
+func (s *AccountState) SlidingNonce() (*SlidingNonce, error) {
+	bytes, err := s.resource(SlidingNonceTag)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSlidingNonce(bytes)
+}
+
+// DiemVMConfig decodes `0x1::DiemConfig::DiemConfig<0x1::DiemVMConfig::DiemVMConfig>` resource,
+// which is only published under the Diem root account
+func (s *AccountState) DiemVMConfig() (*DiemVMConfig, error) {
+	bytes, err := s.resource(DiemVMConfigTag)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeDiemVMConfig(bytes)
+}
+
+func (s *AccountState) resource(tag diemtypes.StructTag) ([]byte, error) {
+	ret, ok := s.Resource(tag)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, &tag)
+	}
+	return ret, nil
+}
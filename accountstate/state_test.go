@@ -0,0 +1,103 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package accountstate_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeBlob(t *testing.T, resources map[string][]byte) []byte {
+	s := bcs.NewSerializer()
+	require.NoError(t, s.SerializeLen(uint64(len(resources))))
+	paths := make([]string, 0, len(resources))
+	for path := range resources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		require.NoError(t, s.SerializeBytes([]byte(path)))
+		require.NoError(t, s.SerializeBytes(resources[path]))
+	}
+	blob := bcs.NewSerializer()
+	require.NoError(t, blob.SerializeBytes(s.GetBytes()))
+	return blob.GetBytes()
+}
+
+func TestDecode(t *testing.T) {
+	blob := encodeBlob(t, map[string][]byte{
+		string(accountstate.ResourcePath(accountstate.DiemAccountTag)):     diemAccountBytes(t),
+		string(accountstate.ResourcePath(accountstate.BalanceTag("XUS"))):  encode(t, uint64(100)),
+		string(accountstate.ResourcePath(accountstate.BalanceTag("XDX"))):  encode(t, uint64(0)),
+		string(accountstate.ResourcePath(accountstate.ChildVASPTag)):       encode(t, address),
+		string(accountstate.ResourcePath(accountstate.FreezingBitTag)):     encode(t, false),
+		string(accountstate.ResourcePath(accountstate.RoleIDTag)):          encode(t, uint64(6)),
+		string(accountstate.ResourcePath(accountstate.CredentialTag)[:10]): {1},
+	})
+	state, err := accountstate.Decode(blob)
+	require.NoError(t, err)
+
+	account, err := state.DiemAccount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), account.SequenceNumber)
+
+	balance, err := state.Balance("XUS")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), balance)
+	balances, err := state.Balances()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"XUS": 100, "XDX": 0}, balances)
+
+	child, err := state.ChildVASP()
+	require.NoError(t, err)
+	assert.Equal(t, address, child.ParentVASPAddress)
+	freezing, err := state.FreezingBit()
+	require.NoError(t, err)
+	assert.False(t, freezing.IsFrozen)
+	role, err := state.RoleID()
+	require.NoError(t, err)
+	assert.Equal(t, accountstate.RoleChildVASP, role)
+	assert.Len(t, state.ResourceTags(), 6)
+
+	_, err = state.ParentVASP()
+	assert.True(t, errors.Is(err, accountstate.ErrResourceNotFound))
+	assert.EqualError(t, err, "resource not found: 0x1::VASP::ParentVASP")
+	_, err = state.Balance("XXX")
+	assert.True(t, errors.Is(err, accountstate.ErrResourceNotFound))
+	_, err = state.Credential()
+	assert.True(t, errors.Is(err, accountstate.ErrResourceNotFound))
+
+	bytes, ok := state.Resource(accountstate.RoleIDTag)
+	assert.True(t, ok)
+	assert.Equal(t, encode(t, uint64(6)), bytes)
+
+	t.Run("invalid blob", func(t *testing.T) {
+		_, err := accountstate.Decode(append(blob, 0))
+		assert.Error(t, err)
+		_, err = accountstate.Decode(blob[:len(blob)-1])
+		assert.Error(t, err)
+		_, err = accountstate.Decode(encode(t, []byte{1}))
+		assert.Error(t, err)
+	})
+	t.Run("invalid resource", func(t *testing.T) {
+		state, err := accountstate.Decode(encodeBlob(t, map[string][]byte{
+			string(accountstate.ResourcePath(accountstate.BalanceTag("XUS"))): {1},
+		}))
+		require.NoError(t, err)
+		_, err = state.Balances()
+		assert.Error(t, err)
+	})
+}
+
+func TestResourcePath(t *testing.T) {
+	assert.Equal(t, "01000000000000000000000000000000010b4469656d4163636f756e740b4469656d4163636f756e7400",
+		hex.EncodeToString(accountstate.ResourcePath(accountstate.DiemAccountTag)))
+}
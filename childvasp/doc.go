@@ -0,0 +1,20 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides child VASP account provisioning by the parent VASP account: creating child VASP
+// accounts with initial balance, verifying the created account role and balance on-chain,
+// adding currencies to existing children, and enumerating children of the parent.
+//
+// There is no on-chain index of the children of a parent VASP, `Manager#Children` finds
+// them by scanning transactions sent by the parent VASP account for
+// `AccountCreationScripts::create_child_vasp_account` script function calls.
+//
+// Example:
+//
+//	manager := childvasp.New(client, parentAddress, diemsigner.NewKeysSigner(parentKeys))
+//	child, err := manager.Create(childvasp.Spec{
+//		AuthKey:        childKeys.AuthKey(),
+//		Currency:       "XUS",
+//		InitialBalance: 1_000_000,
+//	})
+package childvasp
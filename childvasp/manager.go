@@ -0,0 +1,203 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package childvasp
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnbuilder"
+)
+
+// TransactionsPageSize is the page size of loading parent VASP account transactions for
+// enumerating children
+const TransactionsPageSize = 100
+
+// coreCodeAddress is the hex-encoded address of the Diem framework modules
+const coreCodeAddress = "00000000000000000000000000000001"
+
+// Spec is the settings of a new child VASP account
+type Spec struct {
+	AuthKey diemkeys.AuthKey
+	// Currency is the currency of the child account balance, it is also the gas currency of
+	// the creation transaction
+	Currency string
+	// InitialBalance is transferred from the parent VASP balance of the currency, it can be 0
+	InitialBalance uint64
+	// AddAllCurrencies adds balances of all currencies to the child account
+	AddAllCurrencies bool
+}
+
+// Child is a child VASP account of the parent VASP
+type Child struct {
+	Address diemtypes.AccountAddress
+	Info    *diemclient.AccountInfo
+	// Transaction is the transaction of creating the account or adding currency, it is nil for
+	// the child returned by `Manager#Get`.
+	Transaction *diemclient.Transaction
+}
+
+// Balance returns balance amount of the currency, and false if the account has no balance of
+// the currency
+func (c *Child) Balance(currency string) (uint64, bool) {
+	for _, balance := range c.Info.Account.Balances {
+		if balance.Currency == currency {
+			return balance.Amount, true
+		}
+	}
+	return 0, false
+}
+
+// Manager provisions child VASP accounts of the parent VASP account, the `Address` and
+// `Signer` of the embedded `txnbuilder.Operator` are the parent VASP account address and its
+// signer.
+type Manager struct {
+	txnbuilder.Operator
+}
+
+// New creates `Manager` for the parent VASP account address and its signer
+func New(client diemclient.Client, parent diemtypes.AccountAddress, signer diemsigner.Signer) *Manager {
+	return &Manager{Operator: txnbuilder.NewOperator(client, parent, signer)}
+}
+
+// WithContext sets context for client calls
+func (m *Manager) WithContext(ctx context.Context) *Manager {
+	m.Operator.WithContext(ctx)
+	return m
+}
+
+// Create creates child VASP account of the spec, waits for the transaction executed, and
+// verifies the account is created on-chain as a child VASP of the parent with the initial
+// balance.
+func (m *Manager) Create(spec Spec) (*Child, error) {
+	if spec.Currency == "" {
+		return nil, errors.New("currency is required")
+	}
+	address := spec.AuthKey.AccountAddress()
+	txn, err := m.submit(m.Address, m.Signer, spec.Currency,
+		stdlib.EncodeCreateChildVaspAccountScriptFunction(
+			diemtypes.Currency(spec.Currency), address, spec.AuthKey.Prefix(),
+			spec.AddAllCurrencies, spec.InitialBalance))
+	if err != nil {
+		return nil, fmt.Errorf("create child vasp account %s failed: %w", address.Hex(), err)
+	}
+	child, err := m.Get(address)
+	if err != nil {
+		return nil, err
+	}
+	child.Transaction = txn
+	balance, ok := child.Balance(spec.Currency)
+	if !ok {
+		return nil, fmt.Errorf("child vasp account %s has no %s balance", address.Hex(), spec.Currency)
+	}
+	if balance < spec.InitialBalance {
+		return nil, fmt.Errorf("child vasp account %s %s balance %d is less than initial balance %d",
+			address.Hex(), spec.Currency, balance, spec.InitialBalance)
+	}
+	return child, nil
+}
+
+// AddCurrency adds balance of the currency to the existing child VASP account, the transaction
+// is sent by the child account, hence it is signed by the child signer. Waits for the
+// transaction executed and verifies the child account has balance of the currency.
+func (m *Manager) AddCurrency(child diemtypes.AccountAddress, signer diemsigner.Signer, currency string) (*Child, error) {
+	txn, err := m.submit(child, signer, currency,
+		stdlib.EncodeAddCurrencyToAccountScriptFunction(diemtypes.Currency(currency)))
+	if err != nil {
+		return nil, fmt.Errorf("add currency %s to child vasp account %s failed: %w", currency, child.Hex(), err)
+	}
+	ret, err := m.Get(child)
+	if err != nil {
+		return nil, err
+	}
+	ret.Transaction = txn
+	if _, ok := ret.Balance(currency); !ok {
+		return nil, fmt.Errorf("child vasp account %s has no %s balance", child.Hex(), currency)
+	}
+	return ret, nil
+}
+
+// Get gets the child VASP account, returns error if the account is not found or it is not a
+// child VASP account of the parent.
+func (m *Manager) Get(address diemtypes.AccountAddress) (*Child, error) {
+	account, err := diemclient.AsContextClient(m.Client).GetAccountWithContext(m.Context(), address)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("child vasp account %s not found", address.Hex())
+	}
+	info, err := diemclient.NewAccountInfo(account)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsChildOf(m.Address) {
+		return nil, fmt.Errorf("account %s is not a child vasp account of %s, role: %s",
+			address.Hex(), m.Address.Hex(), info.RoleType)
+	}
+	return &Child{Address: address, Info: info}, nil
+}
+
+// Children enumerates child VASP accounts created by the parent VASP account, in the order of
+// creation. Transactions sent by the parent are loaded by pages of `TransactionsPageSize`,
+// executed `create_child_vasp_account` script function calls are verified by `Get`.
+func (m *Manager) Children() ([]*Child, error) {
+	var ret []*Child
+	for start := uint64(0); ; start += TransactionsPageSize {
+		txns, err := diemclient.AsContextClient(m.Client).GetAccountTransactionsWithContext(m.Context(), m.Address, start, TransactionsPageSize, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, txn := range txns {
+			address, ok := createdChild(txn)
+			if !ok {
+				continue
+			}
+			child, err := m.Get(address)
+			if err != nil {
+				return nil, err
+			}
+			child.Transaction = txn
+			ret = append(ret, child)
+		}
+		if len(txns) < TransactionsPageSize {
+			return ret, nil
+		}
+	}
+}
+
+// createdChild returns the child address of executed `create_child_vasp_account` script
+// function call transaction
+func createdChild(txn *diemclient.Transaction) (diemtypes.AccountAddress, bool) {
+	var address diemtypes.AccountAddress
+	if txn.VmStatus == nil || txn.VmStatus.Type != diemclient.VmStatusExecuted ||
+		txn.Transaction == nil || txn.Transaction.Script == nil {
+		return address, false
+	}
+	script := txn.Transaction.Script
+	if script.ModuleAddress != coreCodeAddress || script.ModuleName != "AccountCreationScripts" ||
+		script.FunctionName != "create_child_vasp_account" || len(script.ArgumentsBcs) == 0 {
+		return address, false
+	}
+	bytes, err := hex.DecodeString(script.ArgumentsBcs[0])
+	if err != nil {
+		return address, false
+	}
+	address, err = diemtypes.MakeAccountAddressFromBytes(bytes)
+	return address, err == nil
+}
+
+func (m *Manager) submit(sender diemtypes.AccountAddress, signer diemsigner.Signer, currency string, payload diemtypes.TransactionPayload) (*diemclient.Transaction, error) {
+	return m.SubmitBuilder(txnbuilder.NewWithSigner(signer).
+		Sender(sender).
+		Payload(payload).
+		GasCurrency(currency))
+}
@@ -0,0 +1,142 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package childvasp_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/childvasp"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addChild adds the account expected to be created by the parent, the fake server does not
+// execute scripts.
+func addChild(server *diemclienttest.Server, parent diemtypes.AccountAddress, balances ...*diemclient.Amount) *diemkeys.Keys {
+	return server.GenAccount(&diemclient.Account{
+		Balances: balances,
+		Role: &diemclient.AccountRole{
+			Type:              diemclient.AccountRoleChildVASP,
+			ParentVaspAddress: parent.Hex(),
+		},
+	})
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("created", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		parent := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleParentVASP}})
+		manager := childvasp.New(server.Client(), parent.AccountAddress(), diemsigner.NewKeysSigner(parent))
+		keys := addChild(server, parent.AccountAddress(), &diemclient.Amount{Amount: 1000, Currency: "XUS"})
+		child, err := manager.Create(childvasp.Spec{
+			AuthKey:        keys.AuthKey(),
+			Currency:       "XUS",
+			InitialBalance: 1000,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, keys.AccountAddress(), child.Address)
+		assert.NotNil(t, child.Transaction)
+		balance, ok := child.Balance("XUS")
+		assert.True(t, ok)
+		assert.Equal(t, uint64(1000), balance)
+
+		require.Len(t, server.Submitted(), 1)
+		assert.Equal(t, stdlib.EncodeCreateChildVaspAccountScriptFunction(
+			diemtypes.Currency("XUS"), keys.AccountAddress(), keys.AuthKey().Prefix(), false, 1000),
+			server.Submitted()[0].RawTxn.Payload)
+	})
+	t.Run("account not created", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		parent := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleParentVASP}})
+		manager := childvasp.New(server.Client(), parent.AccountAddress(), diemsigner.NewKeysSigner(parent))
+		keys := diemkeys.MustGenKeys()
+		_, err := manager.Create(childvasp.Spec{AuthKey: keys.AuthKey(), Currency: "XUS"})
+		assert.EqualError(t, err, "child vasp account "+keys.AccountAddress().Hex()+" not found")
+	})
+	t.Run("account of another parent", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		parent := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleParentVASP}})
+		manager := childvasp.New(server.Client(), parent.AccountAddress(), diemsigner.NewKeysSigner(parent))
+		keys := addChild(server, diemkeys.MustGenKeys().AccountAddress(), &diemclient.Amount{Currency: "XUS"})
+		_, err := manager.Create(childvasp.Spec{AuthKey: keys.AuthKey(), Currency: "XUS"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not a child vasp account of")
+	})
+	t.Run("balance mismatch", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		parent := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleParentVASP}})
+		manager := childvasp.New(server.Client(), parent.AccountAddress(), diemsigner.NewKeysSigner(parent))
+		keys := addChild(server, parent.AccountAddress(), &diemclient.Amount{Amount: 0, Currency: "XUS"})
+		_, err := manager.Create(childvasp.Spec{AuthKey: keys.AuthKey(), Currency: "XUS", InitialBalance: 10})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "XUS balance 0 is less than initial balance 10")
+	})
+	t.Run("execution failed", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		parent := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleParentVASP}})
+		manager := childvasp.New(server.Client(), parent.AccountAddress(), diemsigner.NewKeysSigner(parent))
+		server.WithExecutor(func(_ *diemtypes.SignedTransaction, txn *diemclient.Transaction) {
+			txn.VmStatus = &diemclient.VmStatus{Type: diemclient.VmStatusMoveAbort}
+		})
+		keys := diemkeys.MustGenKeys()
+		_, err := manager.Create(childvasp.Spec{AuthKey: keys.AuthKey(), Currency: "XUS"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "create child vasp account "+keys.AccountAddress().Hex()+" failed")
+	})
+}
+
+func TestAddCurrency(t *testing.T) {
+	server := diemclienttest.NewServer()
+	parent := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleParentVASP}})
+	manager := childvasp.New(server.Client(), parent.AccountAddress(), diemsigner.NewKeysSigner(parent))
+	keys := addChild(server, parent.AccountAddress(), &diemclient.Amount{Currency: "XDX"})
+	child, err := manager.AddCurrency(keys.AccountAddress(), diemsigner.NewKeysSigner(keys), "XDX")
+	require.NoError(t, err)
+	assert.NotNil(t, child.Transaction)
+	require.Len(t, server.Submitted(), 1)
+	submitted := server.Submitted()[0].RawTxn
+	assert.Equal(t, keys.AccountAddress(), submitted.Sender)
+	assert.Equal(t, stdlib.EncodeAddCurrencyToAccountScriptFunction(diemtypes.Currency("XDX")), submitted.Payload)
+
+	_, err = manager.AddCurrency(keys.AccountAddress(), diemsigner.NewKeysSigner(keys), "XUS")
+	assert.EqualError(t, err, "child vasp account "+keys.AccountAddress().Hex()+" has no XUS balance")
+}
+
+func TestChildren(t *testing.T) {
+	server := diemclienttest.NewServer()
+	parent := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleParentVASP}})
+	manager := childvasp.New(server.Client(), parent.AccountAddress(), diemsigner.NewKeysSigner(parent))
+	var expected []diemtypes.AccountAddress
+	for i := 0; i < childvasp.TransactionsPageSize+1; i++ {
+		keys := addChild(server, parent.AccountAddress(), &diemclient.Amount{Currency: "XUS"})
+		_, err := manager.Create(childvasp.Spec{AuthKey: keys.AuthKey(), Currency: "XUS"})
+		require.NoError(t, err)
+		expected = append(expected, keys.AccountAddress())
+	}
+	// transactions of other scripts and failed creations are skipped
+	_, err := txnbuilder.New(parent).
+		Payload(stdlib.EncodeAddCurrencyToAccountScriptFunction(diemtypes.Currency("XDX"))).
+		SignSubmitAndWait(server.Client())
+	require.NoError(t, err)
+	server.WithExecutor(func(_ *diemtypes.SignedTransaction, txn *diemclient.Transaction) {
+		txn.VmStatus = &diemclient.VmStatus{Type: diemclient.VmStatusMoveAbort}
+	})
+	_, err = manager.Create(childvasp.Spec{AuthKey: diemkeys.MustGenKeys().AuthKey(), Currency: "XUS"})
+	require.Error(t, err)
+
+	children, err := manager.Children()
+	require.NoError(t, err)
+	require.Len(t, children, len(expected))
+	for i, child := range children {
+		assert.Equal(t, expected[i], child.Address)
+		assert.Equal(t, uint64(i), child.Transaction.Transaction.SequenceNumber)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// ABIFileExt is the file extension of BCS encoded script ABI files
+const ABIFileExt = ".abi"
+
+// ScriptABI is a transaction script (legacy) or script function ABI, decoded from Diem
+// framework release `.abi` files
+type ScriptABI struct {
+	// Name is snake case script or function name, e.g. "peer_to_peer_with_metadata"
+	Name string
+	// Module is set for script function ABI, nil for transaction script ABI
+	Module *diemtypes.ModuleId
+	Doc    string
+	// Code is transaction script bytecode, empty for script function ABI
+	Code   []byte
+	TyArgs []string
+	Args   []ArgumentABI
+}
+
+// ArgumentABI is a script argument name and Move type
+type ArgumentABI struct {
+	Name    string
+	TypeTag diemtypes.TypeTag
+}
+
+// IsScriptFunction returns true if the ABI is script function ABI
+func (abi *ScriptABI) IsScriptFunction() bool {
+	return abi.Module != nil
+}
+
+// ReadABIDirs reads all `.abi` files under the given directories recursively.
+// Returns ABIs sorted by name, transaction scripts first.
+func ReadABIDirs(dirs []string) ([]*ScriptABI, error) {
+	var ret []*ScriptABI
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ABIFileExt) {
+				return nil
+			}
+			bytes, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			abi, err := DecodeScriptABI(bytes)
+			if err != nil {
+				return fmt.Errorf("decode %s failed: %v", path, err)
+			}
+			ret = append(ret, abi)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].IsScriptFunction() != ret[j].IsScriptFunction() {
+			return !ret[i].IsScriptFunction()
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+// DecodeScriptABI decodes BCS bytes of Diem `ScriptABI` enum
+func DecodeScriptABI(bytes []byte) (*ScriptABI, error) {
+	d := bcs.NewDeserializer(bytes)
+	index, err := d.DeserializeVariantIndex()
+	if err != nil {
+		return nil, err
+	}
+	var abi ScriptABI
+	switch index {
+	case 0:
+		if abi.Name, err = d.DeserializeStr(); err != nil {
+			return nil, err
+		}
+		if abi.Doc, err = d.DeserializeStr(); err != nil {
+			return nil, err
+		}
+		if abi.Code, err = d.DeserializeBytes(); err != nil {
+			return nil, err
+		}
+	case 1:
+		if abi.Name, err = d.DeserializeStr(); err != nil {
+			return nil, err
+		}
+		module, err := diemtypes.DeserializeModuleId(d)
+		if err != nil {
+			return nil, err
+		}
+		abi.Module = &module
+		if abi.Doc, err = d.DeserializeStr(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown ScriptABI variant index %d", index)
+	}
+	if abi.TyArgs, err = deserializeTypeArgumentABIs(d); err != nil {
+		return nil, err
+	}
+	if abi.Args, err = deserializeArgumentABIs(d); err != nil {
+		return nil, err
+	}
+	if d.GetBufferOffset() != uint64(len(bytes)) {
+		return nil, fmt.Errorf("some input bytes were not read")
+	}
+	return &abi, nil
+}
+
+func deserializeTypeArgumentABIs(d serde.Deserializer) ([]string, error) {
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, length)
+	for i := range ret {
+		if ret[i], err = d.DeserializeStr(); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func deserializeArgumentABIs(d serde.Deserializer) ([]ArgumentABI, error) {
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]ArgumentABI, length)
+	for i := range ret {
+		if ret[i].Name, err = d.DeserializeStr(); err != nil {
+			return nil, err
+		}
+		if ret[i].TypeTag, err = diemtypes.DeserializeTypeTag(d); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
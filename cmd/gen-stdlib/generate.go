@@ -0,0 +1,441 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+const (
+	bcsPackage   = "github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	serdePackage = "github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// Config is code generation config
+type Config struct {
+	// PackageName is the generated Go package name, e.g. "stdlib"
+	PackageName string
+	// DiemPackageName is the Go module path providing `diemtypes` package, e.g.
+	// "github.com/diem/client-sdk-go"
+	DiemPackageName string
+}
+
+// argType is Move argument type supported by the generated code
+type argType struct {
+	// name is used for naming encode & decode helper functions, e.g. "u8vector"
+	name string
+	// goType is the Go type of the argument
+	goType string
+	// txnArg is the `diemtypes.TransactionArgument` variant name, e.g. "U8Vector"
+	txnArg string
+	// bcs is the BCS serializer & deserializer method name suffix, e.g. "Bytes"
+	bcs string
+}
+
+var argTypes = []*argType{
+	{name: "bool", goType: "bool", txnArg: "Bool", bcs: "Bool"},
+	{name: "u8", goType: "uint8", txnArg: "U8", bcs: "U8"},
+	{name: "u64", goType: "uint64", txnArg: "U64", bcs: "U64"},
+	{name: "u128", goType: "serde.Uint128", txnArg: "U128", bcs: "U128"},
+	{name: "address", goType: "diemtypes.AccountAddress", txnArg: "Address"},
+	{name: "u8vector", goType: "[]byte", txnArg: "U8Vector", bcs: "Bytes"},
+}
+
+func argTypeOf(tag diemtypes.TypeTag) (*argType, error) {
+	name := ""
+	switch tag := tag.(type) {
+	case *diemtypes.TypeTag__Bool:
+		name = "bool"
+	case *diemtypes.TypeTag__U8:
+		name = "u8"
+	case *diemtypes.TypeTag__U64:
+		name = "u64"
+	case *diemtypes.TypeTag__U128:
+		name = "u128"
+	case *diemtypes.TypeTag__Address:
+		name = "address"
+	case *diemtypes.TypeTag__Vector:
+		if _, ok := tag.Value.(*diemtypes.TypeTag__U8); ok {
+			name = "u8vector"
+		}
+	}
+	for _, t := range argTypes {
+		if t.name == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported argument type: %s", tag)
+}
+
+type generator struct {
+	config  Config
+	out     bytes.Buffer
+	used    map[string]bool
+	scripts []*ScriptABI
+	funcs   []*ScriptABI
+}
+
+// Generate generates Go source code of script & script function encoders and decoders for the
+// given ABIs, in the same layout with the `stdlib` package of this SDK.
+func Generate(abis []*ScriptABI, config Config) ([]byte, error) {
+	g := generator{config: config, used: make(map[string]bool)}
+	names := make(map[string]bool)
+	for _, abi := range abis {
+		key := fmt.Sprintf("%v:%s", abi.IsScriptFunction(), camelCase(abi.Name))
+		if names[key] {
+			return nil, fmt.Errorf("duplicated script name: %s", abi.Name)
+		}
+		names[key] = true
+		for _, arg := range abi.Args {
+			t, err := argTypeOf(arg.TypeTag)
+			if err != nil {
+				return nil, fmt.Errorf("%s argument %s: %v", abi.Name, arg.Name, err)
+			}
+			g.used[t.name] = true
+		}
+		if abi.IsScriptFunction() {
+			g.funcs = append(g.funcs, abi)
+		} else {
+			g.scripts = append(g.scripts, abi)
+		}
+	}
+	sort.SliceStable(g.scripts, func(i, j int) bool { return g.scripts[i].Name < g.scripts[j].Name })
+	sort.SliceStable(g.funcs, func(i, j int) bool { return g.funcs[i].Name < g.funcs[j].Name })
+
+	g.header()
+	g.callTypes("ScriptCall", "Structured representation of a call into a known Move transaction script (legacy).", g.scripts)
+	g.callTypes("ScriptFunctionCall", "Structured representation of a call into a known Move script function.", g.funcs)
+	g.encoders()
+	g.decoders()
+	for _, abi := range g.scripts {
+		g.encodeScript(abi)
+	}
+	for _, abi := range g.funcs {
+		g.encodeScriptFunction(abi)
+	}
+	for _, abi := range g.scripts {
+		g.decodeScript(abi)
+	}
+	for _, abi := range g.funcs {
+		g.decodeScriptFunction(abi)
+	}
+	for _, abi := range g.scripts {
+		g.printf("var %s_code = %#v\n\n", abi.Name, abi.Code)
+	}
+	g.decoderMaps()
+	g.helpers()
+
+	ret, err := format.Source(g.out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code failed: %v", err)
+	}
+	return ret, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.out, format, args...)
+}
+
+func (g *generator) header() {
+	g.printf("package %s\n\n", g.config.PackageName)
+	g.printf("import (\n\t\"fmt\"\n\t\"%s/diemtypes\"\n", g.config.DiemPackageName)
+	for name := range g.used {
+		// address arguments are serialized by diemtypes, other types need bcs
+		if name != "address" {
+			g.printf("\t\"%s\"\n", bcsPackage)
+			break
+		}
+	}
+	if g.used["u128"] {
+		g.printf("\t\"%s\"\n", serdePackage)
+	}
+	g.printf(")\n\n")
+}
+
+func (g *generator) doc(doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(doc, "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			g.printf("//\n")
+		} else {
+			g.printf("// %s\n", line)
+		}
+	}
+}
+
+func (g *generator) callTypes(iface string, doc string, abis []*ScriptABI) {
+	g.printf("// %s\ntype %s interface {\n\tis%s()\n}\n\n", doc, iface, iface)
+	for _, abi := range abis {
+		g.doc(abi.Doc)
+		g.printf("type %s__%s struct {\n", iface, camelCase(abi.Name))
+		for _, name := range abi.TyArgs {
+			g.printf("\t%s diemtypes.TypeTag\n", camelCase(name))
+		}
+		for _, arg := range abi.Args {
+			g.printf("\t%s %s\n", camelCase(arg.Name), mustArgType(arg.TypeTag).goType)
+		}
+		g.printf("}\n\nfunc (*%s__%s) is%s() {}\n\n", iface, camelCase(abi.Name), iface)
+	}
+}
+
+func (g *generator) encoders() {
+	g.printf("// Build a Diem `Script` from a structured object `ScriptCall`.\n")
+	g.printf("func EncodeScript(call ScriptCall) diemtypes.Script {\n\tswitch call := call.(type) {\n")
+	for _, abi := range g.scripts {
+		g.printf("\tcase *ScriptCall__%s:\n\t\treturn Encode%sScript(%s)\n",
+			camelCase(abi.Name), camelCase(abi.Name), callFields(abi))
+	}
+	g.printf("\t}\n\tpanic(\"unreachable\")\n}\n\n")
+
+	g.printf("// Build a Diem `TransactionPayload` from a structured object `ScriptFunctionCall`.\n")
+	g.printf("func EncodeScriptFunction(call ScriptFunctionCall) diemtypes.TransactionPayload {\n\tswitch call := call.(type) {\n")
+	for _, abi := range g.funcs {
+		g.printf("\tcase *ScriptFunctionCall__%s:\n\t\treturn Encode%sScriptFunction(%s)\n",
+			camelCase(abi.Name), camelCase(abi.Name), callFields(abi))
+	}
+	g.printf("\t}\n\tpanic(\"unreachable\")\n}\n\n")
+}
+
+func (g *generator) decoders() {
+	g.out.WriteString(`// Try to recognize a Diem ` + "`Script`" + ` and convert it into a structured object ` + "`ScriptCall`" + `.
+func DecodeScript(script *diemtypes.Script) (ScriptCall, error) {
+	if helper := script_decoder_map[string(script.Code)]; helper != nil {
+		val, err := helper(script)
+		return val, err
+	} else {
+		return nil, fmt.Errorf("Unknown script bytecode: %s", string(script.Code))
+	}
+}
+
+// Try to recognize a Diem ` + "`TransactionPayload`" + ` and convert it into a structured object ` + "`ScriptFunctionCall`" + `.
+func DecodeScriptFunctionPayload(script diemtypes.TransactionPayload) (ScriptFunctionCall, error) {
+	switch script := script.(type) {
+	case *diemtypes.TransactionPayload__ScriptFunction:
+		if helper := script_function_decoder_map[string(script.Value.Module.Name)+string(script.Value.Function)]; helper != nil {
+			val, err := helper(script)
+			return val, err
+		} else {
+			return nil, fmt.Errorf("Unknown script function: %s::%s", script.Value.Module.Name, script.Value.Function)
+		}
+	default:
+		return nil, fmt.Errorf("Unknown transaction payload encountered when decoding")
+	}
+}
+
+`)
+}
+
+func (g *generator) encodeScript(abi *ScriptABI) {
+	g.doc(abi.Doc)
+	g.printf("func Encode%sScript(%s) diemtypes.Script {\n", camelCase(abi.Name), params(abi))
+	g.printf("\treturn diemtypes.Script{\n")
+	g.printf("\t\tCode: append([]byte(nil), %s_code...),\n", abi.Name)
+	g.printf("\t\tTyArgs: []diemtypes.TypeTag{%s},\n", strings.Join(tyArgNames(abi), ", "))
+	args := make([]string, len(abi.Args))
+	for i, arg := range abi.Args {
+		t := mustArgType(arg.TypeTag)
+		if t.name == "address" {
+			args[i] = fmt.Sprintf("&diemtypes.TransactionArgument__Address{Value: %s}", paramName(arg.Name))
+		} else {
+			args[i] = fmt.Sprintf("(*diemtypes.TransactionArgument__%s)(&%s)", t.txnArg, paramName(arg.Name))
+		}
+	}
+	g.printf("\t\tArgs: []diemtypes.TransactionArgument{%s},\n", strings.Join(args, ", "))
+	g.printf("\t}\n}\n\n")
+}
+
+func (g *generator) encodeScriptFunction(abi *ScriptABI) {
+	g.doc(abi.Doc)
+	g.printf("func Encode%sScriptFunction(%s) diemtypes.TransactionPayload {\n", camelCase(abi.Name), params(abi))
+	g.printf("\treturn &diemtypes.TransactionPayload__ScriptFunction{\n\t\tValue: diemtypes.ScriptFunction{\n")
+	address := make([]string, len(abi.Module.Address))
+	for i, b := range abi.Module.Address {
+		address[i] = fmt.Sprint(b)
+	}
+	g.printf("\t\t\tModule: diemtypes.ModuleId{Address: [16]uint8{%s}, Name: %q},\n",
+		strings.Join(address, ", "), abi.Module.Name)
+	g.printf("\t\t\tFunction: %q,\n", abi.Name)
+	g.printf("\t\t\tTyArgs: []diemtypes.TypeTag{%s},\n", strings.Join(tyArgNames(abi), ", "))
+	args := make([]string, len(abi.Args))
+	for i, arg := range abi.Args {
+		args[i] = fmt.Sprintf("encode_%s_argument(%s)", mustArgType(arg.TypeTag).name, paramName(arg.Name))
+	}
+	g.printf("\t\t\tArgs: [][]byte{%s},\n", strings.Join(args, ", "))
+	g.printf("\t\t},\n\t}\n}\n\n")
+}
+
+func (g *generator) decodeScript(abi *ScriptABI) {
+	g.printf("func decode_%s_script(script *diemtypes.Script) (ScriptCall, error) {\n", abi.Name)
+	g.argsLengthCheck(abi, "script")
+	g.printf("\tvar call ScriptCall__%s\n", camelCase(abi.Name))
+	for i, name := range abi.TyArgs {
+		g.printf("\tcall.%s = script.TyArgs[%d]\n", camelCase(name), i)
+	}
+	for i, arg := range abi.Args {
+		g.printf("\tif val, err := decode_%s_argument(script.Args[%d]); err == nil {\n", mustArgType(arg.TypeTag).name, i)
+		g.printf("\t\tcall.%s = val\n\t} else {\n\t\treturn nil, err\n\t}\n\n", camelCase(arg.Name))
+	}
+	g.printf("\treturn &call, nil\n}\n\n")
+}
+
+func (g *generator) decodeScriptFunction(abi *ScriptABI) {
+	g.printf("func decode_%s_script_function(script diemtypes.TransactionPayload) (ScriptFunctionCall, error) {\n", abi.Name)
+	g.printf("\tswitch script := interface{}(script).(type) {\n\tcase *diemtypes.TransactionPayload__ScriptFunction:\n")
+	g.argsLengthCheck(abi, "script.Value")
+	g.printf("\t\tvar call ScriptFunctionCall__%s\n", camelCase(abi.Name))
+	for i, name := range abi.TyArgs {
+		g.printf("\t\tcall.%s = script.Value.TyArgs[%d]\n", camelCase(name), i)
+	}
+	for i, arg := range abi.Args {
+		t := mustArgType(arg.TypeTag)
+		if t.name == "address" {
+			g.printf("\t\tif val, err := diemtypes.BcsDeserializeAccountAddress(script.Value.Args[%d]); err == nil {\n", i)
+		} else {
+			g.printf("\t\tif val, err := bcs.NewDeserializer(script.Value.Args[%d]).Deserialize%s(); err == nil {\n", i, t.bcs)
+		}
+		g.printf("\t\t\tcall.%s = val\n\t\t} else {\n\t\t\treturn nil, err\n\t\t}\n\n", camelCase(arg.Name))
+	}
+	g.printf("\t\treturn &call, nil\n\tdefault:\n")
+	g.printf("\t\treturn nil, fmt.Errorf(\"Unexpected TransactionPayload encountered when decoding a script function\")\n\t}\n}\n\n")
+}
+
+func (g *generator) argsLengthCheck(abi *ScriptABI, script string) {
+	if n := len(abi.TyArgs); n > 0 {
+		g.printf("\tif len(%s.TyArgs) < %d {\n\t\treturn nil, fmt.Errorf(\"Was expecting %d type arguments\")\n\t}\n", script, n, n)
+	}
+	if n := len(abi.Args); n > 0 {
+		g.printf("\tif len(%s.Args) < %d {\n\t\treturn nil, fmt.Errorf(\"Was expecting %d regular arguments\")\n\t}\n", script, n, n)
+	}
+}
+
+func (g *generator) decoderMaps() {
+	g.printf("var script_decoder_map = map[string]func(*diemtypes.Script) (ScriptCall, error){\n")
+	for _, abi := range g.scripts {
+		g.printf("\tstring(%s_code): decode_%s_script,\n", abi.Name, abi.Name)
+	}
+	g.printf("}\n\n")
+	g.printf("var script_function_decoder_map = map[string]func(diemtypes.TransactionPayload) (ScriptFunctionCall, error){\n")
+	for _, abi := range g.funcs {
+		g.printf("\t%q: decode_%s_script_function,\n", string(abi.Module.Name)+abi.Name, abi.Name)
+	}
+	g.printf("}\n\n")
+}
+
+func (g *generator) helpers() {
+	for _, t := range argTypes {
+		if !g.used[t.name] {
+			continue
+		}
+		g.printf("func encode_%s_argument(arg %s) []byte {\n", t.name, t.goType)
+		if t.name == "address" {
+			g.printf("\tif val, err := arg.BcsSerialize(); err == nil {\n\t\treturn val\n\t}\n")
+		} else {
+			g.printf("\ts := bcs.NewSerializer()\n\tif err := s.Serialize%s(arg); err == nil {\n\t\treturn s.GetBytes()\n\t}\n", t.bcs)
+		}
+		g.printf("\tpanic(\"Unable to serialize argument of type %s\")\n}\n\n", t.name)
+	}
+	for _, t := range argTypes {
+		if !g.used[t.name] {
+			continue
+		}
+		value := fmt.Sprintf("%s(*arg)", t.goType)
+		switch t.name {
+		case "address":
+			value = "arg.Value"
+		case "u8vector":
+			value = "[]byte(*arg)"
+		case "u128":
+			value = "serde.Uint128(*arg)"
+		}
+		g.printf("func decode_%s_argument(arg diemtypes.TransactionArgument) (value %s, err error) {\n", t.name, t.goType)
+		g.printf("\tif arg, ok := arg.(*diemtypes.TransactionArgument__%s); ok {\n\t\tvalue = %s\n", t.txnArg, value)
+		g.printf("\t} else {\n\t\terr = fmt.Errorf(\"Was expecting a %s argument\")\n\t}\n\treturn\n}\n\n", t.txnArg)
+	}
+}
+
+func mustArgType(tag diemtypes.TypeTag) *argType {
+	t, err := argTypeOf(tag)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func tyArgNames(abi *ScriptABI) []string {
+	ret := make([]string, len(abi.TyArgs))
+	for i, name := range abi.TyArgs {
+		ret[i] = paramName(snakeCase(name))
+	}
+	return ret
+}
+
+func params(abi *ScriptABI) string {
+	var ret []string
+	for _, name := range tyArgNames(abi) {
+		ret = append(ret, name+" diemtypes.TypeTag")
+	}
+	for _, arg := range abi.Args {
+		ret = append(ret, paramName(arg.Name)+" "+mustArgType(arg.TypeTag).goType)
+	}
+	return strings.Join(ret, ", ")
+}
+
+func callFields(abi *ScriptABI) string {
+	var ret []string
+	for _, name := range abi.TyArgs {
+		ret = append(ret, "call."+camelCase(name))
+	}
+	for _, arg := range abi.Args {
+		ret = append(ret, "call."+camelCase(arg.Name))
+	}
+	return strings.Join(ret, ", ")
+}
+
+// paramName avoids Go keywords as parameter names, e.g. "type" to "type_"
+func paramName(name string) string {
+	if token.IsKeyword(name) {
+		return name + "_"
+	}
+	return name
+}
+
+// camelCase converts snake case name to camel case, e.g. "peer_to_peer" to "PeerToPeer"
+func camelCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		r := []rune(part)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// snakeCase converts camel case name to snake case, e.g. "CoinType" to "coin_type"
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
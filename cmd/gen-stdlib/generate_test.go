@@ -0,0 +1,191 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	u8Vector = &diemtypes.TypeTag__Vector{Value: &diemtypes.TypeTag__U8{}}
+	p2pArgs  = []ArgumentABI{
+		{Name: "payee", TypeTag: &diemtypes.TypeTag__Address{}},
+		{Name: "amount", TypeTag: &diemtypes.TypeTag__U64{}},
+		{Name: "metadata", TypeTag: u8Vector},
+		{Name: "metadata_signature", TypeTag: u8Vector},
+	}
+	scriptABI = &ScriptABI{
+		Name:   "peer_to_peer_with_metadata",
+		Doc:    "Transfers coins.\n\n# Parameters\n",
+		Code:   []byte{0xa1, 0x1c, 0xeb, 0x0b},
+		TyArgs: []string{"Currency"},
+		Args:   p2pArgs,
+	}
+	functionABI = &ScriptABI{
+		Name:   "peer_to_peer_with_metadata",
+		Module: &diemtypes.ModuleId{Address: diemtypes.MustMakeAccountAddress("00000000000000000000000000000001"), Name: "PaymentScripts"},
+		Doc:    "Transfers coins.",
+		TyArgs: []string{"Currency"},
+		Args:   p2pArgs,
+	}
+	allTypesABI = &ScriptABI{
+		Name:   "all_types",
+		Module: &diemtypes.ModuleId{Name: "Test"},
+		TyArgs: []string{"CoinType"},
+		Args: []ArgumentABI{
+			{Name: "b", TypeTag: &diemtypes.TypeTag__Bool{}},
+			{Name: "u8", TypeTag: &diemtypes.TypeTag__U8{}},
+			{Name: "u128", TypeTag: &diemtypes.TypeTag__U128{}},
+			{Name: "type", TypeTag: u8Vector},
+		},
+	}
+)
+
+func encodeScriptABI(t *testing.T, abi *ScriptABI) []byte {
+	s := bcs.NewSerializer()
+	if abi.IsScriptFunction() {
+		require.NoError(t, s.SerializeVariantIndex(1))
+		require.NoError(t, s.SerializeStr(abi.Name))
+		require.NoError(t, abi.Module.Serialize(s))
+		require.NoError(t, s.SerializeStr(abi.Doc))
+	} else {
+		require.NoError(t, s.SerializeVariantIndex(0))
+		require.NoError(t, s.SerializeStr(abi.Name))
+		require.NoError(t, s.SerializeStr(abi.Doc))
+		require.NoError(t, s.SerializeBytes(abi.Code))
+	}
+	require.NoError(t, s.SerializeLen(uint64(len(abi.TyArgs))))
+	for _, name := range abi.TyArgs {
+		require.NoError(t, s.SerializeStr(name))
+	}
+	require.NoError(t, s.SerializeLen(uint64(len(abi.Args))))
+	for _, arg := range abi.Args {
+		require.NoError(t, s.SerializeStr(arg.Name))
+		require.NoError(t, arg.TypeTag.Serialize(s))
+	}
+	return s.GetBytes()
+}
+
+func writeABIs(t *testing.T, abis map[string]*ScriptABI) string {
+	dir, err := ioutil.TempDir("", "gen-stdlib")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for path, abi := range abis {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, encodeScriptABI(t, abi), 0644))
+	}
+	return dir
+}
+
+func TestReadABIDirs(t *testing.T) {
+	dir := writeABIs(t, map[string]*ScriptABI{
+		"legacy/peer_to_peer_with_metadata.abi":                 scriptABI,
+		"current/PaymentScripts/peer_to_peer_with_metadata.abi": functionABI,
+		"current/Test/all_types.abi":                            allTypesABI,
+	})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0644))
+
+	abis, err := ReadABIDirs([]string{dir})
+	require.NoError(t, err)
+	assert.Equal(t, []*ScriptABI{scriptABI, allTypesABI, functionABI}, abis)
+
+	t.Run("invalid abi file", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "invalid.abi"), []byte{2}, 0644))
+		_, err := ReadABIDirs([]string{dir})
+		assert.Error(t, err)
+	})
+	t.Run("dir not found", func(t *testing.T) {
+		_, err := ReadABIDirs([]string{filepath.Join(dir, "not-found")})
+		assert.Error(t, err)
+	})
+}
+
+func TestDecodeScriptABI(t *testing.T) {
+	bytes := encodeScriptABI(t, scriptABI)
+	abi, err := DecodeScriptABI(bytes)
+	require.NoError(t, err)
+	assert.Equal(t, scriptABI, abi)
+
+	_, err = DecodeScriptABI(append(bytes, 0))
+	assert.Error(t, err)
+	_, err = DecodeScriptABI(bytes[:len(bytes)-1])
+	assert.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	code, err := Generate([]*ScriptABI{functionABI, allTypesABI, scriptABI}, Config{
+		PackageName:     "mystdlib",
+		DiemPackageName: "github.com/diem/client-sdk-go",
+	})
+	require.NoError(t, err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "lib.go", code, parser.ParseComments)
+	require.NoError(t, err)
+	assert.Equal(t, "mystdlib", file.Name.Name)
+	var imports []string
+	for _, spec := range file.Imports {
+		imports = append(imports, spec.Path.Value)
+	}
+	assert.ElementsMatch(t, []string{
+		`"fmt"`, `"github.com/diem/client-sdk-go/diemtypes"`, `"` + bcsPackage + `"`, `"` + serdePackage + `"`,
+	}, imports)
+
+	decls := make(map[string]bool)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			decls[decl.Name.Name] = true
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					decls[spec.Name.Name] = true
+				case *ast.ValueSpec:
+					decls[spec.Names[0].Name] = true
+				}
+			}
+		}
+	}
+	for _, name := range []string{
+		"ScriptCall", "ScriptFunctionCall", "ScriptCall__PeerToPeerWithMetadata",
+		"ScriptFunctionCall__PeerToPeerWithMetadata", "ScriptFunctionCall__AllTypes",
+		"EncodeScript", "EncodeScriptFunction", "DecodeScript", "DecodeScriptFunctionPayload",
+		"EncodePeerToPeerWithMetadataScript", "EncodePeerToPeerWithMetadataScriptFunction",
+		"EncodeAllTypesScriptFunction", "decode_peer_to_peer_with_metadata_script",
+		"decode_peer_to_peer_with_metadata_script_function", "decode_all_types_script_function",
+		"peer_to_peer_with_metadata_code", "script_decoder_map", "script_function_decoder_map",
+		"encode_bool_argument", "encode_u8_argument", "encode_u64_argument", "encode_u128_argument",
+		"encode_address_argument", "encode_u8vector_argument", "decode_address_argument",
+	} {
+		assert.True(t, decls[name], name)
+	}
+	assert.Contains(t, string(code), "// Transfers coins.\n//\n// # Parameters\ntype ScriptCall__PeerToPeerWithMetadata struct")
+	assert.Contains(t, string(code), "func EncodePeerToPeerWithMetadataScriptFunction(currency diemtypes.TypeTag, payee diemtypes.AccountAddress, amount uint64, metadata []byte, metadata_signature []byte) diemtypes.TransactionPayload")
+	assert.Contains(t, string(code), "func EncodeAllTypesScriptFunction(coin_type diemtypes.TypeTag, b bool, u8 uint8, u128 serde.Uint128, type_ []byte) diemtypes.TransactionPayload")
+	assert.Contains(t, string(code), `"PaymentScriptspeer_to_peer_with_metadata": decode_peer_to_peer_with_metadata_script_function`)
+
+	t.Run("unsupported argument type", func(t *testing.T) {
+		_, err := Generate([]*ScriptABI{{
+			Name: "f",
+			Args: []ArgumentABI{{Name: "a", TypeTag: &diemtypes.TypeTag__Vector{Value: &diemtypes.TypeTag__U64{}}}},
+		}}, Config{PackageName: "stdlib"})
+		assert.EqualError(t, err, "f argument a: unsupported argument type: vector<u64>")
+	})
+	t.Run("duplicated script name", func(t *testing.T) {
+		_, err := Generate([]*ScriptABI{functionABI, functionABI}, Config{PackageName: "stdlib"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,69 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// gen-stdlib generates Go encoders & decoders of Move transaction scripts and script functions
+// from Diem framework release ABI files, in the same layout with the `stdlib` package of this
+// SDK. It is for users who run a Diem fork or a newer Diem framework release:
+//
+//	go run ./cmd/gen-stdlib \
+//		--abi-dir diem/language/diem-framework/releases/legacy \
+//		--abi-dir diem/language/diem-framework/releases/artifacts/current \
+//		--out mystdlib/lib.go --module-name mystdlib
+//
+// All `.abi` files under the given directories are read recursively.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	var abiDirs stringsFlag
+	flag.Var(&abiDirs, "abi-dir", "directory of Diem framework ABI files, can be specified multiple times")
+	moduleName := flag.String("module-name", "stdlib", "generated Go package name")
+	diemPackageName := flag.String("diem-package-name", "github.com/diem/client-sdk-go", "Go module path of the diemtypes package")
+	out := flag.String("out", "", "output file path, default is stdout")
+	flag.Parse()
+
+	if err := run(abiDirs, *out, Config{PackageName: *moduleName, DiemPackageName: *diemPackageName}); err != nil {
+		fmt.Fprintf(os.Stderr, "gen-stdlib: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(abiDirs []string, out string, config Config) error {
+	if len(abiDirs) == 0 {
+		return fmt.Errorf("at least one --abi-dir is required")
+	}
+	abis, err := ReadABIDirs(abiDirs)
+	if err != nil {
+		return err
+	}
+	if len(abis) == 0 {
+		return fmt.Errorf("no %s file found in %s", ABIFileExt, strings.Join(abiDirs, ", "))
+	}
+	code, err := Generate(abis, config)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return ioutil.WriteFile(out, code, 0644)
+}
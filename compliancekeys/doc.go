@@ -0,0 +1,8 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides VASP compliance key management for dual attestation
+// (https://github.com/diem/dip/blob/main/dips/dip-1.mdx#on-chain-transaction-settlement):
+// signing travel rule metadata, verifying counterparty signatures by its on-chain compliance
+// public key, and rotating the compliance key by the `rotate_dual_attestation_info` script.
+package compliancekeys
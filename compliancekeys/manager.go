@@ -0,0 +1,115 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package compliancekeys
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// ErrInvalidSignature is returned when the dual attestation signature does not match the
+// counterparty compliance public key
+var ErrInvalidSignature = txnmetadata.ErrInvalidMetadataSignature
+
+// Manager manages compliance ed25519 key of a parent VASP account
+type Manager struct {
+	// Address is the parent VASP account address
+	Address diemtypes.AccountAddress
+	// Resolver resolves counterparty compliance public keys, defaults to
+	// `offchain.OnChainComplianceKeyResolver`
+	Resolver offchain.ComplianceKeyResolver
+
+	mux sync.RWMutex
+	key *diemkeys.Ed25519PrivateKey
+}
+
+// New creates `Manager` for the parent VASP account address and its compliance private key.
+// Counterparty compliance public keys are resolved from the on-chain parent VASP account by the
+// given client.
+func New(client diemclient.Client, address diemtypes.AccountAddress, key *diemkeys.Ed25519PrivateKey) *Manager {
+	return &Manager{
+		Address:  address,
+		Resolver: &offchain.OnChainComplianceKeyResolver{Client: client},
+		key:      key,
+	}
+}
+
+// PublicKey returns the current compliance public key
+func (m *Manager) PublicKey() *diemkeys.Ed25519PublicKey {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	return m.key.PublicKey()
+}
+
+// Sign signs given message by the current compliance private key
+func (m *Manager) Sign(msg []byte) []byte {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	return m.key.Sign(msg)
+}
+
+// SignTravelRuleMetadata is called by the payee VASP, it creates the travel rule metadata of
+// given off-chain reference id, and the dual attestation signature of the metadata, sender
+// account address and amount.
+// The metadata and signature are sent to the sender VASP for submitting the peer to peer
+// transaction.
+func (m *Manager) SignTravelRuleMetadata(offChainReferenceID string, sender diemtypes.AccountAddress, amount uint64) ([]byte, []byte) {
+	metadata, sigMsg := txnmetadata.NewTravelRuleMetadata(offChainReferenceID, sender, amount)
+	return metadata, m.Sign(sigMsg)
+}
+
+// VerifyTravelRuleMetadata is called by the sender VASP, it verifies the dual attestation
+// signature of the travel rule metadata, sender account address and amount is signed by the
+// payee parent VASP compliance key.
+// Returns `ErrInvalidSignature` if the signature is invalid.
+func (m *Manager) VerifyTravelRuleMetadata(
+	payee diemtypes.AccountAddress,
+	sender diemtypes.AccountAddress,
+	metadata []byte,
+	amount uint64,
+	signature []byte,
+) error {
+	key, err := m.Resolver.ComplianceKey(payee)
+	if err != nil {
+		return err
+	}
+	return txnmetadata.VerifyTravelRuleMetadataSignature(metadata, sender, amount, signature, key)
+}
+
+// RotationPayload returns `rotate_dual_attestation_info` script payload, which sets the parent
+// VASP account base url and compliance public key to the given new key.
+// The transaction should be signed by the parent VASP account key; after it is executed,
+// call `Rotate` to start signing with the new key.
+func (m *Manager) RotationPayload(baseURL string, newKey *diemkeys.Ed25519PrivateKey) diemtypes.TransactionPayload {
+	return &diemtypes.TransactionPayload__Script{
+		Value: stdlib.EncodeRotateDualAttestationInfoScript(
+			[]byte(baseURL), newKey.PublicKey().Bytes()),
+	}
+}
+
+// Rotate replaces the current compliance key by the given new key, after verifying the
+// on-chain parent VASP compliance public key is rotated to the new key.
+// The old key is destroyed after rotated.
+func (m *Manager) Rotate(newKey *diemkeys.Ed25519PrivateKey) error {
+	key, err := m.Resolver.ComplianceKey(m.Address)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(key, newKey.PublicKey().Bytes()) {
+		return fmt.Errorf("on-chain compliance key %x does not match the new key %s", []byte(key), newKey.PublicKey().Hex())
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.key.Destroy()
+	m.key = newKey
+	return nil
+}
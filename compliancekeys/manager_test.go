@@ -0,0 +1,104 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package compliancekeys_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/diem/client-sdk-go/compliancekeys"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	payee  = diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	sender = diemtypes.MustMakeAccountAddress("a74fd7c46952c497e75afb0a7932586d")
+)
+
+type keyResolver struct {
+	key ed25519.PublicKey
+}
+
+func (r *keyResolver) ComplianceKey(address diemtypes.AccountAddress) (ed25519.PublicKey, error) {
+	return r.key, nil
+}
+
+func newManager(key *diemkeys.Keys) (*compliancekeys.Manager, *keyResolver) {
+	privateKey := key.PrivateKey.(*diemkeys.Ed25519PrivateKey)
+	resolver := &keyResolver{key: key.PublicKey.Bytes()}
+	manager := compliancekeys.New(nil, payee, privateKey)
+	manager.Resolver = resolver
+	return manager, resolver
+}
+
+func TestSignAndVerifyTravelRuleMetadata(t *testing.T) {
+	manager, _ := newManager(diemkeys.MustGenKeys())
+
+	metadata, signature := manager.SignTravelRuleMetadata("ref id", sender, 1_000_000_000)
+	expected, sigMsg := txnmetadata.NewTravelRuleMetadata("ref id", sender, 1_000_000_000)
+	assert.Equal(t, expected, metadata)
+	assert.True(t, ed25519.Verify(manager.PublicKey().Bytes(), sigMsg, signature))
+
+	err := manager.VerifyTravelRuleMetadata(payee, sender, metadata, 1_000_000_000, signature)
+	assert.NoError(t, err)
+
+	err = manager.VerifyTravelRuleMetadata(payee, sender, metadata, 1_000_000_001, signature)
+	assert.Equal(t, compliancekeys.ErrInvalidSignature, err)
+}
+
+func TestVerifyByOnChainComplianceKey(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	account := json.RawMessage(`{
+  "address": "f72589b71ff4f8d139674a3f7369c69b",
+  "role": {
+    "type": "parent_vasp",
+    "human_name": "vasp",
+    "base_url": "http://vasp.com",
+    "compliance_key": "` + keys.PublicKey.Hex() + `"
+  }
+}`)
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+		Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: {Result: &account}},
+	})
+	manager := compliancekeys.New(client, payee, keys.PrivateKey.(*diemkeys.Ed25519PrivateKey))
+
+	metadata, signature := manager.SignTravelRuleMetadata("ref id", sender, 100)
+	err := manager.VerifyTravelRuleMetadata(payee, sender, metadata, 100, signature)
+	assert.NoError(t, err)
+}
+
+func TestRotate(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	manager, resolver := newManager(keys)
+	newKeys := diemkeys.MustGenKeys()
+	newKey := newKeys.PrivateKey.(*diemkeys.Ed25519PrivateKey)
+
+	payload := manager.RotationPayload("http://vasp.com", newKey).(*diemtypes.TransactionPayload__Script)
+	call, err := stdlib.DecodeScript(&payload.Value)
+	require.NoError(t, err)
+	rotate, ok := call.(*stdlib.ScriptCall__RotateDualAttestationInfo)
+	require.True(t, ok)
+	assert.Equal(t, []byte("http://vasp.com"), rotate.NewUrl)
+	assert.Equal(t, newKeys.PublicKey.Bytes(), rotate.NewKey)
+
+	err = manager.Rotate(newKey)
+	assert.EqualError(t, err, "on-chain compliance key "+keys.PublicKey.Hex()+
+		" does not match the new key "+newKeys.PublicKey.Hex())
+	assert.Equal(t, keys.PublicKey.Hex(), manager.PublicKey().Hex())
+
+	resolver.key = newKeys.PublicKey.Bytes()
+	require.NoError(t, manager.Rotate(newKey))
+	assert.Equal(t, newKeys.PublicKey.Hex(), manager.PublicKey().Hex())
+	assert.True(t, keys.PrivateKey.(*diemkeys.Ed25519PrivateKey).IsDestroyed())
+}
@@ -0,0 +1,267 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package deposits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/events"
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// CustomerResolver maps deposit sub-address to customer id
+type CustomerResolver interface {
+	// ResolveCustomer returns customer id owns the sub-address, empty string if the sub-address
+	// is unknown.
+	ResolveCustomer(ctx context.Context, subAddress diemtypes.SubAddress) (string, error)
+}
+
+// Status is deposit detection status
+type Status string
+
+const (
+	// StatusCredited deposit sub-address is resolved to a customer
+	StatusCredited Status = "credited"
+	// StatusReference deposit has travel rule or payment metadata, it should be matched by the
+	// off-chain reference id or payment reference id
+	StatusReference Status = "reference"
+	// StatusRefund deposit is a refund of a payment sent by the account
+	StatusRefund Status = "refund"
+	// StatusMissingSubAddress deposit has no receiver sub-address, requires refund
+	StatusMissingSubAddress Status = "missing_subaddress"
+	// StatusUnknownSubAddress deposit receiver sub-address is not resolved to a customer,
+	// requires refund
+	StatusUnknownSubAddress Status = "unknown_subaddress"
+	// StatusInvalidMetadata deposit metadata can't be decoded, requires refund
+	StatusInvalidMetadata Status = "invalid_metadata"
+)
+
+// Deposit is a payment received by the custodial account
+type Deposit struct {
+	// Version is the payment transaction version
+	Version uint64
+	// SequenceNumber is the receivedpayment event sequence number
+	SequenceNumber uint64
+	Sender         diemtypes.AccountAddress
+	Receiver       diemtypes.AccountAddress
+	Amount         diemamount.Amount
+	// Metadata is the decoded payment metadata, nil if the metadata is invalid
+	Metadata *txnmetadata.Inspection
+	// SenderSubAddress and SubAddress are from and to sub-addresses of general metadata
+	SenderSubAddress *diemtypes.SubAddress
+	SubAddress       *diemtypes.SubAddress
+	// CustomerID is resolved customer id of `SubAddress`
+	CustomerID string
+	Status     Status
+}
+
+// RequiresRefund returns true if the deposit can't be credited to a customer, and should be
+// refunded, e.g. by `refunds.Prepare` with `RefundReason`
+func (d *Deposit) RequiresRefund() bool {
+	switch d.Status {
+	case StatusMissingSubAddress, StatusUnknownSubAddress, StatusInvalidMetadata:
+		return true
+	}
+	return false
+}
+
+// RefundReason returns refund reason of the deposit requires refund, nil otherwise
+func (d *Deposit) RefundReason() diemtypes.RefundReason {
+	switch d.Status {
+	case StatusMissingSubAddress, StatusUnknownSubAddress:
+		return &diemtypes.RefundReason__InvalidSubaddress{}
+	case StatusInvalidMetadata:
+		return &diemtypes.RefundReason__OtherReason{}
+	}
+	return nil
+}
+
+// UndecodableEventError is error for a received payment event of which the data can't be
+// decoded. Retrying can't fix it, hence `Poll` and `Run` skip the event after sending it to
+// `Detector.OnUndecodable`.
+type UndecodableEventError struct {
+	Event *diemclient.Event
+	Err   error
+}
+
+// Error implements error interface
+func (e *UndecodableEventError) Error() string {
+	return fmt.Sprintf("undecodable event %d of key %s: %v", e.Event.SequenceNumber, e.Event.Key, e.Err)
+}
+
+// Unwrap returns the decoding error
+func (e *UndecodableEventError) Unwrap() error {
+	return e.Err
+}
+
+// Detector detects deposits from received payment events stream
+type Detector struct {
+	Stream   *events.Stream
+	Resolver CustomerResolver
+	// OnError is called when processing an event failed in `Run`, the event is retried after
+	// the stream interval.
+	OnError func(error)
+	// OnUndecodable is called with the event that can't be decoded by `Poll` and `Run`, e.g. for
+	// storing it into a dead letter queue for investigation; the event is skipped and the
+	// stream cursor moves on. `OnError` is called instead if it is nil.
+	OnUndecodable func(*UndecodableEventError)
+}
+
+// NewDetector creates `Detector` of the received payment events of given account address,
+// starts from given event sequence number. Save `Stream.Cursor()` after deposits are processed
+// for resuming.
+func NewDetector(ctx context.Context, client diemclient.Client, address diemtypes.AccountAddress, start uint64, resolver CustomerResolver) (*Detector, error) {
+	stream, err := events.NewReceivedPaymentsStream(ctx, client, address, start)
+	if err != nil {
+		return nil, err
+	}
+	return &Detector{Stream: stream, Resolver: resolver}, nil
+}
+
+// Poll polls a batch of received payment events, and returns detected deposits.
+// The stream cursor is not moved if resolving customer failed, hence the batch is re-polled by
+// next call. Undecodable events are skipped, see `OnUndecodable`.
+func (d *Detector) Poll(ctx context.Context) ([]*Deposit, error) {
+	cursor := d.Stream.Cursor()
+	batch, err := d.Stream.Poll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Deposit, 0, len(batch))
+	for _, event := range batch {
+		deposit, err := d.Detect(ctx, event)
+		if d.skipUndecodable(err) {
+			continue
+		}
+		if err != nil {
+			d.Stream.Seek(cursor)
+			return nil, err
+		}
+		if deposit != nil {
+			ret = append(ret, deposit)
+		}
+	}
+	return ret, nil
+}
+
+// Run detects deposits and sends them to the channel until the context is done.
+// Returns the context error.
+func (d *Detector) Run(ctx context.Context, deposits chan<- *Deposit) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	received := make(chan *diemclient.Event)
+	done := make(chan error, 1)
+	go func() { done <- d.Stream.Run(ctx, received) }()
+	for {
+		select {
+		case event := <-received:
+			deposit, err := d.detectWithRetry(ctx, event)
+			if err != nil {
+				return err
+			}
+			if deposit == nil {
+				continue
+			}
+			select {
+			case deposits <- deposit:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case err := <-done:
+			return err
+		}
+	}
+}
+
+func (d *Detector) detectWithRetry(ctx context.Context, event *diemclient.Event) (*Deposit, error) {
+	for {
+		deposit, err := d.Detect(ctx, event)
+		if err == nil {
+			return deposit, nil
+		}
+		if d.skipUndecodable(err) {
+			return nil, nil
+		}
+		if d.OnError != nil {
+			d.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(d.Stream.Interval):
+		}
+	}
+}
+
+func (d *Detector) skipUndecodable(err error) bool {
+	var undecodable *UndecodableEventError
+	if !errors.As(err, &undecodable) {
+		return false
+	}
+	if d.OnUndecodable != nil {
+		d.OnUndecodable(undecodable)
+	} else if d.OnError != nil {
+		d.OnError(undecodable)
+	}
+	return true
+}
+
+// Detect decodes the received payment event into `Deposit`, returns nil for other type events.
+// Returns `*UndecodableEventError` if the event data is invalid, or error if resolving customer
+// failed.
+func (d *Detector) Detect(ctx context.Context, event *diemclient.Event) (*Deposit, error) {
+	if event.Data == nil || event.Data.Type != events.ReceivedPaymentEventType {
+		return nil, nil
+	}
+	data, err := events.DecodeEventData(event)
+	if err != nil {
+		return nil, &UndecodableEventError{Event: event, Err: err}
+	}
+	payment, ok := data.(*events.ReceivedPaymentEvent)
+	if !ok {
+		return nil, nil
+	}
+	ret := &Deposit{
+		Version:        event.TransactionVersion,
+		SequenceNumber: event.SequenceNumber,
+		Sender:         payment.Sender,
+		Receiver:       payment.Receiver,
+		Amount:         payment.Amount,
+	}
+	ret.Metadata, err = txnmetadata.Inspect(payment.Metadata)
+	if err != nil {
+		ret.Status = StatusInvalidMetadata
+		return ret, nil
+	}
+	switch ret.Metadata.Kind {
+	case txnmetadata.MetadataKindTravelRule, txnmetadata.MetadataKindPayment:
+		ret.Status = StatusReference
+		return ret, nil
+	case txnmetadata.MetadataKindRefund:
+		ret.Status = StatusRefund
+		return ret, nil
+	}
+	ret.SenderSubAddress = ret.Metadata.FromSubAddress
+	ret.SubAddress = ret.Metadata.ToSubAddress
+	if ret.SubAddress == nil {
+		ret.Status = StatusMissingSubAddress
+		return ret, nil
+	}
+	ret.CustomerID, err = d.Resolver.ResolveCustomer(ctx, *ret.SubAddress)
+	if err != nil {
+		return nil, err
+	}
+	if ret.CustomerID == "" {
+		ret.Status = StatusUnknownSubAddress
+	} else {
+		ret.Status = StatusCredited
+	}
+	return ret, nil
+}
@@ -0,0 +1,238 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package deposits_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/deposits"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/events"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sender   = "f72589b71ff4f8d139674a3f7369c69b"
+	receiver = "a74fd7c46952c497e75afb0a7932586d"
+)
+
+type resolver struct {
+	customers map[diemtypes.SubAddress]string
+	err       error
+}
+
+func (r *resolver) ResolveCustomer(ctx context.Context, subAddress diemtypes.SubAddress) (string, error) {
+	return r.customers[subAddress], r.err
+}
+
+func newEvent(seq uint64, metadata []byte) *diemclient.Event {
+	return &diemclient.Event{
+		Key:                "key",
+		SequenceNumber:     seq,
+		TransactionVersion: 100 + seq,
+		Data: &diemclient.EventData{
+			Type:     events.ReceivedPaymentEventType,
+			Amount:   &diemclient.Amount{Amount: 1000000, Currency: "XUS"},
+			Sender:   sender,
+			Receiver: receiver,
+			Metadata: hex.EncodeToString(metadata),
+		},
+	}
+}
+
+func newDetector(r *resolver, events ...*diemclient.Event) *deposits.Detector {
+	result, _ := json.Marshal(events)
+	raw := json.RawMessage(result)
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+		Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: {Result: &raw}},
+	})
+	return &deposits.Detector{
+		Stream:   eventsStream(client),
+		Resolver: r,
+	}
+}
+
+func eventsStream(client diemclient.Client) *events.Stream {
+	stream := events.NewStream(client, "key", 0)
+	stream.Interval = time.Millisecond
+	return stream
+}
+
+func TestDetect(t *testing.T) {
+	known, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	unknown, _ := diemtypes.MakeSubAddress("111111153010a111")
+	referenceID, _ := txnmetadata.NewReferenceID()
+	travelRule, _ := txnmetadata.NewTravelRuleMetadata("ref id", diemtypes.MustMakeAccountAddress(sender), 1000000)
+	detector := newDetector(&resolver{customers: map[diemtypes.SubAddress]string{known: "customer"}})
+
+	cases := []struct {
+		name     string
+		metadata []byte
+		status   deposits.Status
+		customer string
+		refund   bool
+	}{
+		{
+			name:     "credited",
+			metadata: txnmetadata.NewGeneralMetadataToSubAddress(known),
+			status:   deposits.StatusCredited,
+			customer: "customer",
+		},
+		{
+			name:     "unknown sub-address",
+			metadata: txnmetadata.NewGeneralMetadataToSubAddress(unknown),
+			status:   deposits.StatusUnknownSubAddress,
+			refund:   true,
+		},
+		{
+			name:     "missing sub-address",
+			metadata: txnmetadata.NewGeneralMetadataFromSubAddress(known),
+			status:   deposits.StatusMissingSubAddress,
+			refund:   true,
+		},
+		{
+			name:   "no metadata",
+			status: deposits.StatusMissingSubAddress,
+			refund: true,
+		},
+		{
+			name:     "invalid metadata",
+			metadata: []byte{1},
+			status:   deposits.StatusInvalidMetadata,
+			refund:   true,
+		},
+		{
+			name:     "travel rule metadata",
+			metadata: travelRule,
+			status:   deposits.StatusReference,
+		},
+		{
+			name:     "payment metadata",
+			metadata: txnmetadata.NewPaymentMetadata(referenceID),
+			status:   deposits.StatusReference,
+		},
+		{
+			name:     "refund metadata",
+			metadata: txnmetadata.NewRefundMetadata(12, &diemtypes.RefundReason__OtherReason{}),
+			status:   deposits.StatusRefund,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			deposit, err := detector.Detect(context.Background(), newEvent(1, tc.metadata))
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, deposit.Status)
+			assert.Equal(t, tc.customer, deposit.CustomerID)
+			assert.Equal(t, tc.refund, deposit.RequiresRefund())
+			assert.Equal(t, tc.refund, deposit.RefundReason() != nil)
+			assert.Equal(t, uint64(101), deposit.Version)
+			assert.Equal(t, uint64(1), deposit.SequenceNumber)
+			assert.Equal(t, diemtypes.MustMakeAccountAddress(sender), deposit.Sender)
+			assert.Equal(t, uint64(1000000), deposit.Amount.Micro)
+		})
+	}
+
+	t.Run("ignore other type events", func(t *testing.T) {
+		deposit, err := detector.Detect(context.Background(), &diemclient.Event{
+			Data: &diemclient.EventData{Type: events.ReceivedMintEventType, DestinationAddress: receiver},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, deposit)
+	})
+}
+
+func TestPoll(t *testing.T) {
+	subAddress, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	r := &resolver{customers: map[diemtypes.SubAddress]string{subAddress: "customer"}, err: errors.New("unavailable")}
+	detector := newDetector(r,
+		newEvent(0, txnmetadata.NewGeneralMetadataToSubAddress(subAddress)),
+		newEvent(1, nil),
+	)
+
+	_, err := detector.Poll(context.Background())
+	assert.EqualError(t, err, "unavailable")
+	assert.Equal(t, uint64(0), detector.Stream.Cursor())
+
+	r.err = nil
+	ret, err := detector.Poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, ret, 2)
+	assert.Equal(t, deposits.StatusCredited, ret[0].Status)
+	assert.Equal(t, deposits.StatusMissingSubAddress, ret[1].Status)
+	assert.Equal(t, uint64(2), detector.Stream.Cursor())
+}
+
+func TestRun(t *testing.T) {
+	subAddress, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	r := &resolver{customers: map[diemtypes.SubAddress]string{subAddress: "customer"}}
+	detector := newDetector(r, newEvent(0, txnmetadata.NewGeneralMetadataToSubAddress(subAddress)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan *deposits.Deposit)
+	done := make(chan error)
+	go func() { done <- detector.Run(ctx, ch) }()
+
+	deposit := <-ch
+	assert.Equal(t, "customer", deposit.CustomerID)
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
+
+func TestUndecodableEvent(t *testing.T) {
+	subAddress, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	r := &resolver{customers: map[diemtypes.SubAddress]string{subAddress: "customer"}}
+	undecodable := newEvent(0, nil)
+	undecodable.Data.Sender = "invalid"
+	newDetectorWithDeadLetters := func() (*deposits.Detector, *[]*deposits.UndecodableEventError) {
+		detector := newDetector(r, undecodable, newEvent(1, txnmetadata.NewGeneralMetadataToSubAddress(subAddress)))
+		var deadLetters []*deposits.UndecodableEventError
+		detector.OnUndecodable = func(err *deposits.UndecodableEventError) {
+			deadLetters = append(deadLetters, err)
+		}
+		return detector, &deadLetters
+	}
+
+	t.Run("detect", func(t *testing.T) {
+		_, err := newDetector(r).Detect(context.Background(), undecodable)
+		var undecodableErr *deposits.UndecodableEventError
+		require.True(t, errors.As(err, &undecodableErr))
+		assert.Equal(t, undecodable, undecodableErr.Event)
+	})
+	t.Run("poll", func(t *testing.T) {
+		detector, deadLetters := newDetectorWithDeadLetters()
+		ret, err := detector.Poll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, ret, 1)
+		assert.Equal(t, uint64(1), ret[0].SequenceNumber)
+		assert.Equal(t, uint64(2), detector.Stream.Cursor())
+		require.Len(t, *deadLetters, 1)
+		assert.Equal(t, uint64(0), (*deadLetters)[0].Event.SequenceNumber)
+	})
+	t.Run("run", func(t *testing.T) {
+		detector, deadLetters := newDetectorWithDeadLetters()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := make(chan *deposits.Deposit)
+		done := make(chan error)
+		go func() { done <- detector.Run(ctx, ch) }()
+
+		deposit := <-ch
+		assert.Equal(t, uint64(1), deposit.SequenceNumber)
+		cancel()
+		assert.Equal(t, context.Canceled, <-done)
+		assert.Len(t, *deadLetters, 1)
+	})
+}
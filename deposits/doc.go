@@ -0,0 +1,7 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides incoming deposit detection for a custodial account: consumes receivedpayment events
+// of the account, decodes payment metadata, maps the receiver sub-address to customer, and
+// flags deposits that should be refunded.
+package deposits
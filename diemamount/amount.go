@@ -0,0 +1,151 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemamount
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/smallmath"
+)
+
+// MicroUnitsPerCoin is the default scaling factor of on-chain currencies: 1 coin = 1,000,000 micro-units
+const MicroUnitsPerCoin uint64 = 1_000_000
+
+// Currency is on-chain currency code, e.g. "XUS"
+type Currency string
+
+// Currencies
+const (
+	XUS Currency = "XUS"
+	XDX Currency = "XDX"
+)
+
+// TypeTag returns Move TypeTag of the currency, used as script type argument
+func (c Currency) TypeTag() diemtypes.TypeTag {
+	return diemtypes.Currency(string(c))
+}
+
+// CurrencyMismatchError is error for the case arithmetic or comparison
+// operation is called with amounts in different currencies
+type CurrencyMismatchError struct {
+	Expected Currency
+	Actual   Currency
+}
+
+// Error implements error interface
+func (e *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("currency mismatch: expected %s, but got %s", e.Expected, e.Actual)
+}
+
+// Amount is micro-units amount of a currency
+type Amount struct {
+	Currency Currency
+	Micro    uint64
+}
+
+// New creates `Amount` with given currency and micro-units
+func New(currency Currency, micro uint64) Amount {
+	return Amount{Currency: currency, Micro: micro}
+}
+
+// Zero returns zero amount of given currency
+func Zero(currency Currency) Amount {
+	return Amount{Currency: currency}
+}
+
+// IsZero returns true if the amount micro-units is 0
+func (a Amount) IsZero() bool {
+	return a.Micro == 0
+}
+
+// Add returns a + b. Returns `*CurrencyMismatchError` if b is in different currency,
+// or `*smallmath.OverflowError` if the result overflows.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if err := a.checkCurrency(b); err != nil {
+		return Amount{}, err
+	}
+	micro, err := smallmath.Add(a.Micro, b.Micro)
+	if err != nil {
+		return Amount{}, err
+	}
+	return New(a.Currency, micro), nil
+}
+
+// Sub returns a - b. Returns `*CurrencyMismatchError` if b is in different currency,
+// or `*smallmath.OverflowError` if b is greater than a.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if err := a.checkCurrency(b); err != nil {
+		return Amount{}, err
+	}
+	micro, err := smallmath.Sub(a.Micro, b.Micro)
+	if err != nil {
+		return Amount{}, err
+	}
+	return New(a.Currency, micro), nil
+}
+
+// Mul returns amount multiplied by given factor, or `*smallmath.OverflowError` if the
+// result overflows.
+func (a Amount) Mul(factor uint64) (Amount, error) {
+	micro, err := smallmath.Mul(a.Micro, factor)
+	if err != nil {
+		return Amount{}, err
+	}
+	return New(a.Currency, micro), nil
+}
+
+// Cmp compares a and b and returns -1 if a < b, 0 if a == b and 1 if a > b.
+// Returns `*CurrencyMismatchError` if b is in different currency.
+func (a Amount) Cmp(b Amount) (int, error) {
+	if err := a.checkCurrency(b); err != nil {
+		return 0, err
+	}
+	switch {
+	case a.Micro < b.Micro:
+		return -1, nil
+	case a.Micro > b.Micro:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// MulDiv returns amount * numerator / denominator rounded down, e.g. fee rate in basis points.
+// The intermediate product is computed in big integer, so it does not overflow unless the
+// result does. Returns `*smallmath.OverflowError` if the result overflows, or error if the
+// denominator is 0.
+func (a Amount) MulDiv(numerator uint64, denominator uint64) (Amount, error) {
+	if denominator == 0 {
+		return Amount{}, errors.New("division by zero")
+	}
+	ret := new(big.Int).SetUint64(a.Micro)
+	ret.Mul(ret, new(big.Int).SetUint64(numerator))
+	ret.Quo(ret, new(big.Int).SetUint64(denominator))
+	if !ret.IsUint64() {
+		return Amount{}, &smallmath.OverflowError{Op: "*", A: a.Micro, B: numerator}
+	}
+	return New(a.Currency, ret.Uint64()), nil
+}
+
+// Decimal returns decimal string of the amount in coins by the default scaling factor
+// `MicroUnitsPerCoin`, e.g. "1.5" for 1,500,000 micro-units. Use `Formatter` for currencies
+// with different on-chain scaling factor.
+func (a Amount) Decimal() string {
+	ret, _ := FormatDecimal(a.Micro, MicroUnitsPerCoin)
+	return ret
+}
+
+// String returns decimal amount with currency code, e.g. "1.5 XUS"
+func (a Amount) String() string {
+	return fmt.Sprintf("%s %s", a.Decimal(), a.Currency)
+}
+
+func (a Amount) checkCurrency(b Amount) error {
+	if a.Currency != b.Currency {
+		return &CurrencyMismatchError{Expected: a.Currency, Actual: b.Currency}
+	}
+	return nil
+}
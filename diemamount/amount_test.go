@@ -0,0 +1,90 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemamount_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/smallmath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmountArithmetic(t *testing.T) {
+	a := diemamount.New(diemamount.XUS, 1_500_000)
+	b := diemamount.New(diemamount.XUS, 500_000)
+
+	t.Run("add", func(t *testing.T) {
+		ret, err := a.Add(b)
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New(diemamount.XUS, 2_000_000), ret)
+	})
+	t.Run("sub", func(t *testing.T) {
+		ret, err := a.Sub(b)
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New(diemamount.XUS, 1_000_000), ret)
+
+		_, err = b.Sub(a)
+		assert.IsType(t, &smallmath.OverflowError{}, err)
+	})
+	t.Run("mul", func(t *testing.T) {
+		ret, err := b.Mul(3)
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New(diemamount.XUS, 1_500_000), ret)
+
+		_, err = a.Mul(math.MaxUint64)
+		assert.Error(t, err)
+	})
+	t.Run("mul div", func(t *testing.T) {
+		ret, err := a.MulDiv(25, 10_000)
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New(diemamount.XUS, 3_750), ret)
+
+		max := diemamount.New(diemamount.XUS, math.MaxUint64)
+		ret, err = max.MulDiv(3, 4)
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New(diemamount.XUS, math.MaxUint64/4*3+2), ret)
+
+		_, err = max.MulDiv(2, 1)
+		assert.IsType(t, &smallmath.OverflowError{}, err)
+		_, err = a.MulDiv(1, 0)
+		assert.EqualError(t, err, "division by zero")
+	})
+	t.Run("cmp", func(t *testing.T) {
+		ret, err := a.Cmp(b)
+		require.NoError(t, err)
+		assert.Equal(t, 1, ret)
+		ret, _ = b.Cmp(a)
+		assert.Equal(t, -1, ret)
+		ret, _ = a.Cmp(a)
+		assert.Equal(t, 0, ret)
+	})
+}
+
+func TestAmountCurrencyMismatch(t *testing.T) {
+	xus := diemamount.New(diemamount.XUS, 1)
+	xdx := diemamount.New(diemamount.XDX, 1)
+
+	_, err := xus.Add(xdx)
+	assert.EqualError(t, err, "currency mismatch: expected XUS, but got XDX")
+	_, err = xus.Sub(xdx)
+	assert.IsType(t, &diemamount.CurrencyMismatchError{}, err)
+	_, err = xus.Cmp(xdx)
+	assert.IsType(t, &diemamount.CurrencyMismatchError{}, err)
+}
+
+func TestAmountFormat(t *testing.T) {
+	assert.Equal(t, "0 XUS", diemamount.Zero(diemamount.XUS).String())
+	assert.Equal(t, "1 XUS", diemamount.New(diemamount.XUS, 1_000_000).String())
+	assert.Equal(t, "1.5 XDX", diemamount.New(diemamount.XDX, 1_500_000).String())
+	assert.Equal(t, "0.000001", diemamount.New(diemamount.XUS, 1).Decimal())
+	assert.True(t, diemamount.Zero(diemamount.XUS).IsZero())
+}
+
+func TestCurrencyTypeTag(t *testing.T) {
+	assert.Equal(t, diemtypes.Currency("XUS"), diemamount.XUS.TypeTag())
+}
@@ -0,0 +1,106 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemamount
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/diem/client-sdk-go/diemjsonrpctypes"
+)
+
+// CurrenciesGetter gets on-chain currencies info, `diemclient.Client` implements it.
+type CurrenciesGetter interface {
+	GetCurrencies() ([]*diemjsonrpctypes.CurrencyInfo, error)
+}
+
+// UnknownCurrencyError is error for the case converting an amount of currency
+// that is not found in on-chain currencies
+type UnknownCurrencyError struct {
+	Currency Currency
+}
+
+// Error implements error interface
+func (e *UnknownCurrencyError) Error() string {
+	return fmt.Sprintf("unknown currency: %s", e.Currency)
+}
+
+// Converter converts amounts between currencies by the on-chain `to_xdx_exchange_rate`
+// of the currencies info it was created with.
+// The rates are a snapshot, create a new Converter to refresh them.
+type Converter struct {
+	rates map[Currency]*big.Rat
+}
+
+// NewConverter creates `Converter` with given currencies info, e.g. result of
+// `diemclient.Client#GetCurrencies`
+func NewConverter(currencies []*diemjsonrpctypes.CurrencyInfo) *Converter {
+	rates := make(map[Currency]*big.Rat)
+	for _, c := range currencies {
+		// format float32 rate with shortest decimal representation, so that rate 0.1
+		// is 1/10 instead of binary approximation 0.100000001490116...
+		rate, ok := new(big.Rat).SetString(
+			strconv.FormatFloat(float64(c.ToXdxExchangeRate), 'f', -1, 32))
+		if ok {
+			rates[Currency(c.Code)] = rate
+		}
+	}
+	return &Converter{rates: rates}
+}
+
+// NewConverterFromServer fetches currencies info and creates `Converter`
+func NewConverterFromServer(getter CurrenciesGetter) (*Converter, error) {
+	currencies, err := getter.GetCurrencies()
+	if err != nil {
+		return nil, err
+	}
+	return NewConverter(currencies), nil
+}
+
+// ToXDX converts given amount into micro-XDX, the result is rounded down.
+func (c *Converter) ToXDX(a Amount) (Amount, error) {
+	return c.Convert(a, XDX)
+}
+
+// Convert converts given amount into an approximate amount of the target currency
+// through their XDX exchange rates, the result is rounded down.
+func (c *Converter) Convert(a Amount, to Currency) (Amount, error) {
+	if a.Currency == to {
+		return a, nil
+	}
+	from, err := c.rate(a.Currency)
+	if err != nil {
+		return Amount{}, err
+	}
+	target, err := c.rate(to)
+	if err != nil {
+		return Amount{}, err
+	}
+	if target.Sign() == 0 {
+		return Amount{}, fmt.Errorf("can't convert to %s: exchange rate is 0", to)
+	}
+	ret := new(big.Rat).SetInt(new(big.Int).SetUint64(a.Micro))
+	ret.Mul(ret, from)
+	ret.Quo(ret, target)
+	micro := new(big.Int).Quo(ret.Num(), ret.Denom())
+	if !micro.IsUint64() {
+		return Amount{}, fmt.Errorf("converted amount overflows: %s %s", micro, to)
+	}
+	return New(to, micro.Uint64()), nil
+}
+
+func (c *Converter) rate(currency Currency) (*big.Rat, error) {
+	if currency == XDX {
+		if rate, ok := c.rates[XDX]; ok && rate.Sign() > 0 {
+			return rate, nil
+		}
+		return big.NewRat(1, 1), nil
+	}
+	rate, ok := c.rates[currency]
+	if !ok {
+		return nil, &UnknownCurrencyError{Currency: currency}
+	}
+	return rate, nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemamount_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemjsonrpctypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var currencies = []*diemjsonrpctypes.CurrencyInfo{
+	{Code: "XUS", ScalingFactor: 1000000, FractionalPart: 100, ToXdxExchangeRate: 0.5},
+	{Code: "XDX", ScalingFactor: 1000000, FractionalPart: 1000, ToXdxExchangeRate: 1},
+	{Code: "ABC", ScalingFactor: 1000000, FractionalPart: 100, ToXdxExchangeRate: 0.1},
+}
+
+func TestConverter(t *testing.T) {
+	converter := diemamount.NewConverter(currencies)
+
+	t.Run("to xdx", func(t *testing.T) {
+		ret, err := converter.ToXDX(diemamount.New(diemamount.XUS, 3_000_001))
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New(diemamount.XDX, 1_500_000), ret)
+
+		ret, err = converter.ToXDX(diemamount.New("ABC", 10_000_000))
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New(diemamount.XDX, 1_000_000), ret)
+	})
+	t.Run("same currency", func(t *testing.T) {
+		ret, err := converter.Convert(diemamount.New(diemamount.XUS, 7), diemamount.XUS)
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New(diemamount.XUS, 7), ret)
+	})
+	t.Run("cross currency", func(t *testing.T) {
+		ret, err := converter.Convert(diemamount.New(diemamount.XUS, 1_000_000), "ABC")
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New("ABC", 5_000_000), ret)
+	})
+	t.Run("unknown currency", func(t *testing.T) {
+		_, err := converter.ToXDX(diemamount.New("XYZ", 1))
+		assert.EqualError(t, err, "unknown currency: XYZ")
+		_, err = converter.Convert(diemamount.New(diemamount.XUS, 1), "XYZ")
+		assert.IsType(t, &diemamount.UnknownCurrencyError{}, err)
+	})
+}
+
+func TestNewConverterFromServer(t *testing.T) {
+	converter, err := diemamount.NewConverterFromServer(getter{currencies: currencies})
+	require.NoError(t, err)
+	ret, err := converter.ToXDX(diemamount.New(diemamount.XUS, 2))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), ret.Micro)
+
+	_, err = diemamount.NewConverterFromServer(getter{err: errors.New("network")})
+	assert.EqualError(t, err, "network")
+}
+
+type getter struct {
+	currencies []*diemjsonrpctypes.CurrencyInfo
+	err        error
+}
+
+func (g getter) GetCurrencies() ([]*diemjsonrpctypes.CurrencyInfo, error) {
+	return g.currencies, g.err
+}
@@ -0,0 +1,152 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemamount
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/diem/client-sdk-go/diemjsonrpctypes"
+)
+
+// ErrInvalidDecimal is returned for parsing malformed decimal amount, or converting amount by
+// a scaling factor that is not a power of 10
+var ErrInvalidDecimal = errors.New("invalid decimal amount")
+
+// FormatDecimal formats micro-units into decimal string in coins by the currency scaling factor,
+// trailing zeros of the fractional part are trimmed, e.g. "1.5" for 1,500,000 micro-units with
+// scaling factor 1,000,000.
+func FormatDecimal(micro uint64, scalingFactor uint64) (string, error) {
+	digits, err := decimalDigits(scalingFactor)
+	if err != nil {
+		return "", err
+	}
+	whole := micro / scalingFactor
+	frac := micro % scalingFactor
+	if frac == 0 {
+		return fmt.Sprintf("%d", whole), nil
+	}
+	fracStr := strings.TrimRight(fmt.Sprintf("%0*d", digits, frac), "0")
+	return fmt.Sprintf("%d.%s", whole, fracStr), nil
+}
+
+// ParseDecimal parses decimal string in coins into micro-units by the currency scaling factor,
+// e.g. "1.5" is 1,500,000 micro-units with scaling factor 1,000,000.
+// Returns error wraps `ErrInvalidDecimal` if the string is not a non-negative decimal number, or
+// it has more fractional digits than the scaling factor allows instead of rounding it silently,
+// or the micro-units overflows uint64.
+func ParseDecimal(s string, scalingFactor uint64) (uint64, error) {
+	digits, err := decimalDigits(scalingFactor)
+	if err != nil {
+		return 0, err
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("%w: %#v", ErrInvalidDecimal, s)
+	}
+	trimmed := strings.TrimRight(frac, "0")
+	if len(trimmed) > digits {
+		return 0, fmt.Errorf("%w: %#v has more than %d fractional digits", ErrInvalidDecimal, s, digits)
+	}
+	micro, _ := new(big.Int).SetString(whole+trimmed+strings.Repeat("0", digits-len(trimmed)), 10)
+	if !micro.IsUint64() {
+		return 0, fmt.Errorf("%w: %#v overflows uint64 micro-units", ErrInvalidDecimal, s)
+	}
+	return micro.Uint64(), nil
+}
+
+// Parse parses decimal string in coins into `Amount` of given currency by the default
+// scaling factor `MicroUnitsPerCoin`, see `ParseDecimal`.
+func Parse(currency Currency, s string) (Amount, error) {
+	micro, err := ParseDecimal(s, MicroUnitsPerCoin)
+	if err != nil {
+		return Amount{}, err
+	}
+	return New(currency, micro), nil
+}
+
+// Formatter formats and parses decimal amounts by the on-chain `scaling_factor` of the
+// currencies info it was created with.
+type Formatter struct {
+	scalingFactors map[Currency]uint64
+}
+
+// NewFormatter creates `Formatter` with given currencies info, e.g. result of
+// `diemclient.Client#GetCurrencies`
+func NewFormatter(currencies []*diemjsonrpctypes.CurrencyInfo) *Formatter {
+	factors := make(map[Currency]uint64)
+	for _, c := range currencies {
+		factors[Currency(c.Code)] = c.ScalingFactor
+	}
+	return &Formatter{scalingFactors: factors}
+}
+
+// ScalingFactor returns scaling factor of the currency, or `*UnknownCurrencyError`
+func (f *Formatter) ScalingFactor(currency Currency) (uint64, error) {
+	factor, ok := f.scalingFactors[currency]
+	if !ok {
+		return 0, &UnknownCurrencyError{Currency: currency}
+	}
+	return factor, nil
+}
+
+// Format returns decimal string of the amount in coins, see `FormatDecimal`
+func (f *Formatter) Format(a Amount) (string, error) {
+	factor, err := f.ScalingFactor(a.Currency)
+	if err != nil {
+		return "", err
+	}
+	return FormatDecimal(a.Micro, factor)
+}
+
+// Parse parses decimal string in coins into `Amount` of the currency, see `ParseDecimal`
+func (f *Formatter) Parse(currency Currency, s string) (Amount, error) {
+	factor, err := f.ScalingFactor(currency)
+	if err != nil {
+		return Amount{}, err
+	}
+	micro, err := ParseDecimal(s, factor)
+	if err != nil {
+		return Amount{}, err
+	}
+	return New(currency, micro), nil
+}
+
+// decimalDigits returns number of fractional digits of the scaling factor, which must be a
+// power of 10
+func decimalDigits(scalingFactor uint64) (int, error) {
+	digits := 0
+	for factor := scalingFactor; factor > 1; factor /= 10 {
+		if factor%10 != 0 {
+			break
+		}
+		digits++
+	}
+	if scalingFactor == 0 || scalingFactor != pow10(digits) {
+		return 0, fmt.Errorf("%w: scaling factor %d is not a power of 10", ErrInvalidDecimal, scalingFactor)
+	}
+	return digits, nil
+}
+
+func pow10(n int) uint64 {
+	ret := uint64(1)
+	for i := 0; i < n; i++ {
+		ret *= 10
+	}
+	return ret
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
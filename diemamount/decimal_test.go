@@ -0,0 +1,108 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemamount_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemjsonrpctypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatDecimal(t *testing.T) {
+	cases := []struct {
+		micro    uint64
+		factor   uint64
+		expected string
+	}{
+		{0, 1_000_000, "0"},
+		{1, 1_000_000, "0.000001"},
+		{1_500_000, 1_000_000, "1.5"},
+		{18446744073709551615, 1_000_000, "18446744073709.551615"},
+		{150, 100, "1.5"},
+		{7, 1, "7"},
+	}
+	for _, tc := range cases {
+		ret, err := diemamount.FormatDecimal(tc.micro, tc.factor)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, ret)
+	}
+	_, err := diemamount.FormatDecimal(1, 1_000_001)
+	assert.True(t, errors.Is(err, diemamount.ErrInvalidDecimal))
+	_, err = diemamount.FormatDecimal(1, 0)
+	assert.True(t, errors.Is(err, diemamount.ErrInvalidDecimal))
+}
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected uint64
+		err      string
+	}{
+		{s: "0", expected: 0},
+		{s: "1", expected: 1_000_000},
+		{s: "1.5", expected: 1_500_000},
+		{s: "1.", expected: 1_000_000},
+		{s: ".5", expected: 500_000},
+		{s: "0.000001", expected: 1},
+		{s: "0.0000010", expected: 1},
+		{s: "00012.340000", expected: 12_340_000},
+		{s: "18446744073709.551615", expected: 18446744073709551615},
+		{s: "18446744073709.551616", err: `invalid decimal amount: "18446744073709.551616" overflows uint64 micro-units`},
+		{s: "0.0000001", err: `invalid decimal amount: "0.0000001" has more than 6 fractional digits`},
+		{s: "", err: `invalid decimal amount: ""`},
+		{s: ".", err: `invalid decimal amount: "."`},
+		{s: "-1", err: `invalid decimal amount: "-1"`},
+		{s: "1e6", err: `invalid decimal amount: "1e6"`},
+		{s: "1.2.3", err: `invalid decimal amount: "1.2.3"`},
+		{s: " 1", err: `invalid decimal amount: " 1"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.s, func(t *testing.T) {
+			ret, err := diemamount.ParseDecimal(tc.s, 1_000_000)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				assert.True(t, errors.Is(err, diemamount.ErrInvalidDecimal))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ret)
+		})
+	}
+
+	amount, err := diemamount.Parse(diemamount.XUS, "2.25")
+	require.NoError(t, err)
+	assert.Equal(t, diemamount.New(diemamount.XUS, 2_250_000), amount)
+	assert.Equal(t, "2.25 XUS", amount.String())
+}
+
+func TestFormatter(t *testing.T) {
+	formatter := diemamount.NewFormatter([]*diemjsonrpctypes.CurrencyInfo{
+		{Code: "XUS", ScalingFactor: 1_000_000},
+		{Code: "ABC", ScalingFactor: 100},
+		{Code: "BAD", ScalingFactor: 3},
+	})
+
+	ret, err := formatter.Format(diemamount.New("ABC", 1_234))
+	require.NoError(t, err)
+	assert.Equal(t, "12.34", ret)
+	amount, err := formatter.Parse("ABC", "12.34")
+	require.NoError(t, err)
+	assert.Equal(t, diemamount.New("ABC", 1_234), amount)
+	ret, err = formatter.Format(diemamount.New(diemamount.XUS, 1_234))
+	require.NoError(t, err)
+	assert.Equal(t, "0.001234", ret)
+
+	_, err = formatter.Parse("ABC", "0.001")
+	assert.True(t, errors.Is(err, diemamount.ErrInvalidDecimal))
+	_, err = formatter.Format(diemamount.New("BAD", 1))
+	assert.True(t, errors.Is(err, diemamount.ErrInvalidDecimal))
+	_, err = formatter.Format(diemamount.New("XYZ", 1))
+	assert.IsType(t, &diemamount.UnknownCurrencyError{}, err)
+	_, err = formatter.Parse("XYZ", "1")
+	assert.IsType(t, &diemamount.UnknownCurrencyError{}, err)
+}
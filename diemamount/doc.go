@@ -0,0 +1,8 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides currency typed amount, arithmetic is restricted to amounts of the same currency.
+// Amounts are micro-units on-chain, use `Parse` / `Amount#Decimal` or `Formatter` with on-chain
+// currencies scaling factor to convert between micro-units and human readable decimal amounts
+// without floating point rounding errors.
+package diemamount
@@ -0,0 +1,90 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"log"
+	"os"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// Default alert thresholds
+const (
+	// DefaultPersistentStalenessThreshold is number of consecutive stale responses to trigger
+	// `Alerts#PersistentStaleness`
+	DefaultPersistentStalenessThreshold = 10
+	// DefaultSubmissionFailuresThreshold is number of consecutive submission failures to
+	// trigger `Alerts#SubmissionFailures`
+	DefaultSubmissionFailuresThreshold = 3
+)
+
+// Alerts receives notable conditions detected by the SDK, implement it to forward the
+// conditions to your monitoring system.
+type Alerts interface {
+	// PersistentStaleness is called when client received `count` consecutive stale responses
+	PersistentStaleness(count int, err *StaleResponseError)
+	// ChainRegression is called when client detected chain regression
+	ChainRegression(err *ChainRegressionError)
+	// SubmissionFailures is called when `count` consecutive transaction submissions failed
+	SubmissionFailures(count int, err error)
+	// AccountFrozen is called when a watched account is found frozen
+	AccountFrozen(address diemtypes.AccountAddress)
+}
+
+// NopAlerts is an `Alerts` discards all alerts, it is the default `Alerts` of the client
+var NopAlerts Alerts = nopAlerts{}
+
+type nopAlerts struct{}
+
+func (nopAlerts) PersistentStaleness(int, *StaleResponseError) {}
+func (nopAlerts) ChainRegression(*ChainRegressionError)        {}
+func (nopAlerts) SubmissionFailures(int, error)                {}
+func (nopAlerts) AccountFrozen(diemtypes.AccountAddress)       {}
+
+// LogAlerts implements `Alerts` by writing alerts into the logger
+type LogAlerts struct {
+	Logger *log.Logger
+}
+
+// NewLogAlerts creates `LogAlerts` writes to stderr
+func NewLogAlerts() *LogAlerts {
+	return &LogAlerts{Logger: log.New(os.Stderr, "[diem alert] ", log.LstdFlags)}
+}
+
+// PersistentStaleness implements `Alerts` interface
+func (a *LogAlerts) PersistentStaleness(count int, err *StaleResponseError) {
+	a.Logger.Printf("received %d consecutive stale responses, last: %v", count, err)
+}
+
+// ChainRegression implements `Alerts` interface
+func (a *LogAlerts) ChainRegression(err *ChainRegressionError) {
+	a.Logger.Printf("%v", err)
+}
+
+// SubmissionFailures implements `Alerts` interface
+func (a *LogAlerts) SubmissionFailures(count int, err error) {
+	a.Logger.Printf("%d consecutive transaction submissions failed, last: %v", count, err)
+}
+
+// AccountFrozen implements `Alerts` interface
+func (a *LogAlerts) AccountFrozen(address diemtypes.AccountAddress) {
+	a.Logger.Printf("account %s is frozen", address.Hex())
+}
+
+// alertCounter counts consecutive occurrences of a condition and tells when the count
+// reaches threshold; it fires once until it is reset.
+type alertCounter struct {
+	threshold int
+	count     int
+}
+
+func (c *alertCounter) inc() (int, bool) {
+	c.count++
+	return c.count, c.threshold > 0 && c.count == c.threshold
+}
+
+func (c *alertCounter) reset() {
+	c.count = 0
+}
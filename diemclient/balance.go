@@ -0,0 +1,35 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// BalanceAtVersion returns the account balance of the currency as of the ledger version, e.g.
+// for audits and tax reporting. The balance is 0 if the account has no balance of the currency
+// at the version. Returns error if the account does not exist at the version, or
+// `*UnsupportedClientError` if the client does not implement `StateClient`.
+func BalanceAtVersion(ctx context.Context, c Client, address diemtypes.AccountAddress, currency string, version uint64) (uint64, error) {
+	state, ok := c.(StateClient)
+	if !ok {
+		return 0, &UnsupportedClientError{Client: c, Interface: "StateClient"}
+	}
+	account, err := state.GetAccountAtVersionWithContext(ctx, address, version)
+	if err != nil {
+		return 0, err
+	}
+	if account == nil {
+		return 0, fmt.Errorf("account %s not found at version %d", address.Hex(), version)
+	}
+	for _, balance := range account.Balances {
+		if balance.Currency == currency {
+			return balance.Amount, nil
+		}
+	}
+	return 0, nil
+}
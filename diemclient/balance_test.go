@@ -0,0 +1,66 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceAtVersion(t *testing.T) {
+	ctx := context.Background()
+	server := diemclienttest.NewServer()
+	client := server.Client(
+		diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()), diemclient.WithResponseCache(10))
+	address := diemkeys.MustGenKeys().AccountAddress()
+	account := &diemclient.Account{
+		Address:  address.Hex(),
+		Balances: []*diemclient.Amount{{Amount: 100, Currency: "XUS"}},
+	}
+	server.AddAccount(account)
+	v1 := server.LedgerState().Version
+
+	account.Balances[0].Amount = 250
+	server.AddTransactions(&diemclient.Transaction{})
+	v2 := server.LedgerState().Version
+	late := diemkeys.MustGenKeys().AccountAddress()
+	server.AddAccount(&diemclient.Account{Address: late.Hex()})
+	account.Balances[0].Amount = 300
+	server.AddTransactions(&diemclient.Transaction{})
+	v3 := server.LedgerState().Version
+
+	for version, expected := range map[uint64]uint64{v1: 100, v2: 250, v3: 300} {
+		balance, err := diemclient.BalanceAtVersion(ctx, client, address, "XUS", version)
+		require.NoError(t, err)
+		assert.Equal(t, expected, balance, "version %d", version)
+	}
+	balance, err := diemclient.BalanceAtVersion(ctx, client, address, "XDX", v1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), balance)
+
+	ret, err := client.(diemclient.StateClient).GetAccountAtVersion(late, v1)
+	require.NoError(t, err)
+	assert.Nil(t, ret)
+	_, err = diemclient.BalanceAtVersion(ctx, client, late, "XUS", v1)
+	assert.EqualError(t, err, "account "+late.Hex()+" not found at version 1")
+	ret, err = client.(diemclient.StateClient).GetAccountAtVersion(late, v2)
+	require.NoError(t, err)
+	require.NotNil(t, ret)
+	assert.Equal(t, late.Hex(), ret.Address)
+
+	_, err = client.(diemclient.StateClient).GetAccountAtVersion(address, v3+1)
+	assert.Error(t, err)
+
+	server.Fail(diemclient.GetAccount, diemclienttest.NetworkError(errors.New("connection reset")))
+	balance, err = diemclient.BalanceAtVersion(ctx, client, address, "XUS", v1)
+	require.NoError(t, err, "cached")
+	assert.Equal(t, uint64(100), balance)
+}
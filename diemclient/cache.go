@@ -0,0 +1,116 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+)
+
+// responseCacheKey identifies a cached response of a read at a ledger version; includeEvents
+// is only used by transactions, as a transaction without events can't serve a read with events;
+// account is only used by accounts.
+type responseCacheKey struct {
+	method        jsonrpc.Method
+	version       uint64
+	includeEvents bool
+	account       diemtypes.AccountAddress
+}
+
+type responseCacheEntry struct {
+	key   responseCacheKey
+	value interface{}
+}
+
+// responseCache is a LRU cache of responses of reads by ledger version, which never change
+// once the version is committed. A nil `*responseCache` is a disabled cache.
+type responseCache struct {
+	mux     sync.Mutex
+	size    int
+	entries map[responseCacheKey]*list.Element
+	lru     *list.List
+}
+
+func newResponseCache(size int) *responseCache {
+	if size <= 0 {
+		return nil
+	}
+	return &responseCache{
+		size:    size,
+		entries: make(map[responseCacheKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// get returns cached value and marks it as recently used
+func (c *responseCache) get(key responseCacheKey) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*responseCacheEntry).value, true
+}
+
+// add caches the value, evicts the least recently used value if the cache is full
+func (c *responseCache) add(key responseCacheKey, value interface{}) {
+	if c == nil {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*responseCacheEntry).value = value
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&responseCacheEntry{key: key, value: value})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*responseCacheEntry).key)
+	}
+}
+
+// reset drops all cached values, e.g. the chain is reset and versions are rewritten
+func (c *responseCache) reset() {
+	if c == nil {
+		return
+	}
+	c.mux.Lock()
+	c.entries = make(map[responseCacheKey]*list.Element)
+	c.lru.Init()
+	c.mux.Unlock()
+}
+
+// transactions returns cached transactions from start version up to limit, returns false if
+// any of them is not cached.
+func (c *responseCache) transactions(start uint64, limit uint64, includeEvents bool) ([]*Transaction, bool) {
+	if c == nil || limit == 0 || limit > uint64(c.size) || start+limit < start {
+		return nil, false
+	}
+	ret := make([]*Transaction, 0, limit)
+	for v := start; v < start+limit; v++ {
+		txn, ok := c.get(responseCacheKey{method: GetTransactions, version: v, includeEvents: includeEvents})
+		if !ok {
+			return nil, false
+		}
+		ret = append(ret, txn.(*Transaction))
+	}
+	return ret, true
+}
+
+func (c *responseCache) addTransactions(txns []*Transaction, includeEvents bool) {
+	for _, txn := range txns {
+		c.add(responseCacheKey{method: GetTransactions, version: txn.Version, includeEvents: includeEvents}, txn)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	t.Run("get transactions", func(t *testing.T) {
+		stub := &txnsStub{ledgerVersion: 100}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub, diemclient.WithResponseCache(10))
+
+		txns, err := client.GetTransactions(10, 5, false)
+		require.NoError(t, err)
+		require.Len(t, txns, 5)
+		assert.Equal(t, 1, stub.getTransactionsCalls())
+
+		cached, err := client.GetTransactions(11, 3, false)
+		require.NoError(t, err)
+		assert.Equal(t, txns[1:4], cached)
+		assert.Equal(t, 1, stub.getTransactionsCalls())
+
+		_, err = client.GetTransactions(10, 5, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stub.getTransactionsCalls(), "transactions without events can't serve events")
+
+		_, err = client.GetTransactions(12, 5, false)
+		require.NoError(t, err)
+		assert.Equal(t, 3, stub.getTransactionsCalls(), "partially cached")
+
+		_, err = client.GetTransactions(0, 20, false)
+		require.NoError(t, err)
+		assert.Equal(t, 4, stub.getTransactionsCalls())
+		_, err = client.GetTransactions(0, 20, false)
+		require.NoError(t, err)
+		assert.Equal(t, 5, stub.getTransactionsCalls(), "limit is greater than cache size")
+
+		_, err = client.GetTransactions(19, 1, false)
+		require.NoError(t, err)
+		assert.Equal(t, 5, stub.getTransactionsCalls(), "least recently used are evicted")
+		_, err = client.GetTransactions(0, 1, false)
+		require.NoError(t, err)
+		assert.Equal(t, 6, stub.getTransactionsCalls(), "least recently used are evicted")
+	})
+	t.Run("get metadata by version", func(t *testing.T) {
+		stub := newConfigsStub()
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub, diemclient.WithResponseCache(10))
+		for i := 0; i < 3; i++ {
+			ret, err := client.GetMetadataByVersion(100)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(100), ret.Version)
+		}
+		assert.Equal(t, 1, stub.calls[diemclient.GetMetadata])
+
+		_, err := client.GetMetadataByVersion(99)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stub.calls[diemclient.GetMetadata])
+
+		_, err = client.GetMetadata()
+		require.NoError(t, err)
+		assert.Equal(t, 3, stub.calls[diemclient.GetMetadata], "latest metadata is not cached")
+	})
+	t.Run("disabled by default", func(t *testing.T) {
+		stub := &txnsStub{ledgerVersion: 100}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub)
+		for i := 0; i < 3; i++ {
+			_, err := client.GetTransactions(10, 5, false)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 3, stub.getTransactionsCalls())
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemlog"
+)
+
+// ChainResetReason is the reason a chain reset is detected
+type ChainResetReason string
+
+const (
+	// ChainResetChainIDChanged indicates server response chain id is different from the
+	// client chain id.
+	ChainResetChainIDChanged ChainResetReason = "chain_id_changed"
+	// ChainResetLedgerRegression indicates server response ledger state is behind the client
+	// known ledger state beyond the chain regression tolerance.
+	ChainResetLedgerRegression ChainResetReason = "ledger_regression"
+)
+
+// ChainResetError is returned when the client is configured with `WithChainResetHandler` and
+// consecutive responses confirmed the chain it is connected to was reset, e.g. testnet is wiped
+// and restarted from a new genesis. By the time the error is returned, cached on-chain configs
+// are dropped; for `ChainResetLedgerRegression`, the last response ledger state is set to the
+// server ledger state, so that the client follows the new chain. The client chain id is never
+// changed, `ChainID` is the server chain id for `ChainResetChainIDChanged`.
+//
+// `ChainResetError` of `ChainResetLedgerRegression` is retryable, calls with retry enabled
+// recover after the reset; `Cause` is the `*ChainIDMismatchError` or `*ChainRegressionError`
+// detected.
+type ChainResetError struct {
+	Reason  ChainResetReason
+	ChainID byte
+	Client  LedgerState
+	Server  LedgerState
+	Cause   error
+}
+
+// Error implements error interface
+func (e *ChainResetError) Error() string {
+	return fmt.Sprintf("chain reset error (%s): %v", e.Reason, e.Cause)
+}
+
+// Unwrap returns the cause error
+func (e *ChainResetError) Unwrap() error {
+	return e.Cause
+}
+
+// IsChainReset returns true if the error indicates the chain the client connects to was reset
+// or regressed: `*ChainResetError`, `*ChainRegressionError` or `*ChainIDMismatchError`.
+func IsChainReset(err error) bool {
+	var reset *ChainResetError
+	var regression *ChainRegressionError
+	var mismatch *ChainIDMismatchError
+	return errors.As(err, &reset) || errors.As(err, &regression) || errors.As(err, &mismatch)
+}
+
+// resetSignal is the chain reset signalled by consecutive responses
+type resetSignal struct {
+	reason  ChainResetReason
+	chainID byte
+	state   LedgerState
+	count   int
+}
+
+// signalResetLocked records a response signalling chain reset, returns true when consecutive
+// responses consistently signalled it for `resetConfirmations` times; caller must hold the lock.
+// Responses are consistent if they have the same reason and chain id, and their ledger states
+// do not regress.
+func (c *client) signalResetLocked(reason ChainResetReason, chainID byte, state LedgerState) bool {
+	prev := c.resetSignal
+	if prev != nil && prev.reason == reason && prev.chainID == chainID &&
+		prev.state.Version <= state.Version && prev.state.TimestampUsec <= state.TimestampUsec {
+		prev.state = state
+		prev.count++
+	} else {
+		c.resetSignal = &resetSignal{reason: reason, chainID: chainID, state: state, count: 1}
+	}
+	if c.resetSignal.count < c.resetConfirmations {
+		return false
+	}
+	c.resetSignal = nil
+	return true
+}
+
+// resetLocked reinitializes client ledger state for a reset chain, caller must hold the lock.
+func (c *client) resetLocked(state LedgerState) {
+	c.last = state
+	c.lastRegression = nil
+	c.staleResponses.reset()
+	c.submitFailures.reset()
+}
+
+// chainReset drops cached on-chain configs and responses, and calls the chain reset handler
+func (c *client) chainReset(err *ChainResetError) {
+	c.currencies.reset()
+	c.metadata.reset()
+	c.gasConstants.reset()
+	c.responses.reset()
+	c.logger.Log(diemlog.LevelError, "chain reset detected", diemlog.F("error", err))
+	if c.onChainReset != nil {
+		c.onChainReset(err)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetChain responds all requests with metadata of the chain id and ledger version of the
+// nodes in round-robin order
+type resetChain struct {
+	nodes []chainNode
+	calls int
+}
+
+type chainNode struct {
+	chainID byte
+	version uint64
+}
+
+func (s *resetChain) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	node := s.nodes[s.calls%len(s.nodes)]
+	s.calls++
+	ret := make(map[jsonrpc.RequestID]*jsonrpc.Response)
+	for _, req := range requests {
+		id := req.ID
+		ret[id] = &jsonrpc.Response{
+			JsonRpc:                 req.JsonRpc,
+			ID:                      &id,
+			DiemChainID:             node.chainID,
+			DiemLedgerVersion:       node.version,
+			DiemLedgerTimestampusec: 1597722856123456 + node.version,
+			Result: toPtr(json.RawMessage(
+				`{"timestamp": 1597722856123456, "version": 10, "chain_id": 2}`)),
+		}
+	}
+	return ret, nil
+}
+
+func TestChainReset(t *testing.T) {
+	wiped := diemclient.LedgerState{Version: 100000, TimestampUsec: 1597722856123456 + 100000}
+	newClient := func(stub *resetChain, handler func(*diemclient.ChainResetError), opts ...diemclient.Option) diemclient.Client {
+		opts = append([]diemclient.Option{
+			diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()),
+			diemclient.WithChainResetHandler(handler),
+		}, opts...)
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub, opts...)
+		require.NoError(t, client.UpdateLastResponseLedgerState(wiped))
+		return client
+	}
+
+	t.Run("ledger regression", func(t *testing.T) {
+		var called *diemclient.ChainResetError
+		stub := &resetChain{nodes: []chainNode{{testnet.ChainID, 10}}}
+		client := newClient(stub, func(e *diemclient.ChainResetError) { called = e })
+
+		for i := 1; i < diemclient.DefaultChainResetConfirmations; i++ {
+			_, err := client.GetMetadata()
+			assert.IsType(t, &diemclient.ChainRegressionError{}, err)
+			assert.Nil(t, called)
+		}
+		_, err := client.GetMetadata()
+		require.Error(t, err)
+		require.NotNil(t, called)
+		assert.Equal(t, err, called)
+		assert.Equal(t, diemclient.ChainResetLedgerRegression, called.Reason)
+		assert.Equal(t, wiped, called.Client)
+		assert.Equal(t, uint64(10), called.Server.Version)
+		assert.IsType(t, &diemclient.ChainRegressionError{}, errors.Unwrap(err))
+		assert.True(t, diemclient.IsChainReset(err))
+		assert.True(t, diemclient.IsRetryable(err))
+		assert.Nil(t, client.(diemclient.ChainStateClient).LastChainRegression())
+		assert.Equal(t, uint64(10), client.LastResponseLedgerState().Version)
+
+		_, err = client.GetMetadata()
+		assert.NoError(t, err)
+	})
+	t.Run("single lagging node does not trigger reset", func(t *testing.T) {
+		resets := 0
+		stub := &resetChain{nodes: []chainNode{{testnet.ChainID, 10}, {testnet.ChainID, wiped.Version}}}
+		client := newClient(stub, func(e *diemclient.ChainResetError) { resets++ })
+		for i := 0; i < 10; i++ {
+			_, err := client.GetMetadata()
+			if i%2 == 0 {
+				assert.IsType(t, &diemclient.ChainRegressionError{}, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		}
+		assert.Equal(t, 0, resets)
+		assert.Equal(t, wiped, client.LastResponseLedgerState())
+	})
+	t.Run("chain id changed", func(t *testing.T) {
+		var called *diemclient.ChainResetError
+		stub := &resetChain{nodes: []chainNode{{testnet.ChainID + 1, 10}}}
+		client := newClient(stub, func(e *diemclient.ChainResetError) { called = e })
+
+		for i := 1; i < diemclient.DefaultChainResetConfirmations; i++ {
+			_, err := client.GetMetadata()
+			assert.IsType(t, &diemclient.ChainIDMismatchError{}, err)
+			assert.Nil(t, called)
+		}
+		_, err := client.GetMetadata()
+		require.NotNil(t, called)
+		assert.Equal(t, err, called)
+		assert.Equal(t, diemclient.ChainResetChainIDChanged, called.Reason)
+		assert.Equal(t, testnet.ChainID+1, called.ChainID)
+		assert.EqualError(t, err, "chain reset error (chain_id_changed): chain id mismatch error: expected server response chain id == 2, but got 3")
+		assert.False(t, diemclient.IsRetryable(err))
+		assert.Equal(t, testnet.ChainID, client.(diemclient.ChainStateClient).ChainID(), "never adopts the server chain id")
+		assert.Equal(t, wiped, client.LastResponseLedgerState())
+
+		_, err = client.GetMetadata()
+		assert.IsType(t, &diemclient.ChainIDMismatchError{}, err)
+	})
+	t.Run("single node of other chain does not trigger reset", func(t *testing.T) {
+		resets := 0
+		stub := &resetChain{nodes: []chainNode{{testnet.ChainID + 1, 10}, {testnet.ChainID, wiped.Version}}}
+		client := newClient(stub, func(e *diemclient.ChainResetError) { resets++ })
+		for i := 0; i < 10; i++ {
+			_, err := client.GetMetadata()
+			if i%2 == 0 {
+				assert.IsType(t, &diemclient.ChainIDMismatchError{}, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		}
+		assert.Equal(t, 0, resets)
+	})
+	t.Run("recovers with retry", func(t *testing.T) {
+		resets := 0
+		stub := &resetChain{nodes: []chainNode{{testnet.ChainID, 10}}}
+		client := newClient(stub, func(e *diemclient.ChainResetError) { resets++ },
+			diemclient.WithChainResetConfirmations(1),
+			diemclient.WithRetryPolicy(diemclient.RetryPolicy{
+				MaxAttempts: 2,
+				Backoff:     diemclient.ExponentialBackoff(time.Millisecond, time.Millisecond),
+				Retryable:   diemclient.IsRetryable,
+			}))
+
+		_, err := client.GetMetadata()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, resets)
+		assert.Equal(t, 2, stub.calls)
+	})
+	t.Run("without handler", func(t *testing.T) {
+		stub := &resetChain{nodes: []chainNode{{testnet.ChainID + 1, 10}}}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub,
+			diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		_, err := client.GetMetadata()
+		assert.IsType(t, &diemclient.ChainIDMismatchError{}, err)
+		assert.True(t, diemclient.IsChainReset(err))
+		assert.False(t, diemclient.IsRetryable(err))
+		assert.Equal(t, testnet.ChainID, client.(diemclient.ChainStateClient).ChainID())
+	})
+}
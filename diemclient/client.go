@@ -4,27 +4,34 @@
 package diemclient
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
 	"github.com/avast/retry-go"
+	"github.com/diem/client-sdk-go/diemlog"
 	"github.com/diem/client-sdk-go/diemtypes"
 	"github.com/diem/client-sdk-go/jsonrpc"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
 )
 
 // List of supported methods
 const (
-	GetCurrencies          jsonrpc.Method = "get_currencies"
-	GetMetadata            jsonrpc.Method = "get_metadata"
-	GetAccount             jsonrpc.Method = "get_account"
-	GetAccountTransaction  jsonrpc.Method = "get_account_transaction"
-	GetAccountTransactions jsonrpc.Method = "get_account_transactions"
-	GetTransactions        jsonrpc.Method = "get_transactions"
-	GetEvents              jsonrpc.Method = "get_events"
-	Submit                 jsonrpc.Method = "submit"
+	GetCurrencies            jsonrpc.Method = "get_currencies"
+	GetMetadata              jsonrpc.Method = "get_metadata"
+	GetAccount               jsonrpc.Method = "get_account"
+	GetAccountTransaction    jsonrpc.Method = "get_account_transaction"
+	GetAccountTransactions   jsonrpc.Method = "get_account_transactions"
+	GetTransactions          jsonrpc.Method = "get_transactions"
+	GetEvents                jsonrpc.Method = "get_events"
+	GetStateProof            jsonrpc.Method = "get_state_proof"
+	GetAccountStateWithProof jsonrpc.Method = "get_account_state_with_proof"
+	Submit                   jsonrpc.Method = "submit"
 
 	VmStatusExecuted = "executed"
 )
@@ -41,6 +48,36 @@ func (e *StaleResponseError) Error() string {
 	return fmt.Sprintf("stale response error: expected server response ledger %v >= %v", e.Server, e.Client)
 }
 
+// ChainRegressionError is error for the case server response latest ledger state is older than
+// client knows beyond the chain regression tolerance, which indicates the server may be on a fork
+// or lost its data, rather than just a few versions behind other servers.
+type ChainRegressionError struct {
+	Client LedgerState
+	Server LedgerState
+}
+
+// Error implements error interface
+func (e *ChainRegressionError) Error() string {
+	return fmt.Sprintf("chain regression error: server response ledger %v is far behind %v", e.Server, e.Client)
+}
+
+// ErrTransactionExpired is returned by waiting for a transaction that is not executed before its
+// expiration time
+var ErrTransactionExpired = errors.New("transaction expired")
+
+// ChainIDMismatchError is error for the case server response chain id is different from the
+// client chain id, e.g. a mainnet client is connected to a testnet full node. It is not
+// retryable.
+type ChainIDMismatchError struct {
+	Expected byte
+	Actual   byte
+}
+
+// Error implements error interface
+func (e *ChainIDMismatchError) Error() string {
+	return fmt.Sprintf("chain id mismatch error: expected server response chain id == %d, but got %d", e.Expected, e.Actual)
+}
+
 // InvalidTransactionError is error for get a transaction with unexpected details (e.g. vm status is failure)
 type InvalidTransactionError struct {
 	Transaction Transaction
@@ -52,7 +89,18 @@ func (e *InvalidTransactionError) Error() string {
 	return e.Msg
 }
 
-// Client is Diem client implements high level APIs
+// newInvalidTransactionError creates `InvalidTransactionError` with a copy of the transaction,
+// the transaction is copied by proto.Merge as a protobuf message must not be copied by value.
+func newInvalidTransactionError(txn *Transaction, msg string) *InvalidTransactionError {
+	ret := &InvalidTransactionError{Msg: msg}
+	proto.Merge(&ret.Transaction, txn)
+	return ret
+}
+
+// Client is Diem client implements high level APIs.
+// The client created by this package also implements `ContextClient`, `StateClient`,
+// `ComplianceClient`, `EstimateClient`, `PagingClient`, `WaitResultClient` and
+// `ChainStateClient`; type-assert the client for these capabilities.
 type Client interface {
 	GetCurrencies() ([]*CurrencyInfo, error)
 	GetMetadata() (*Metadata, error)
@@ -86,17 +134,155 @@ type Client interface {
 	WithRetryOptions(opts ...retry.Option) Client
 }
 
+// ContextClient is `Client` with variants of the `Client` methods accept `context.Context` for
+// cancellation and deadline. Use `AsContextClient` for calling them on any `Client`.
+type ContextClient interface {
+	Client
+
+	GetCurrenciesWithContext(ctx context.Context) ([]*CurrencyInfo, error)
+	GetMetadataWithContext(ctx context.Context) (*Metadata, error)
+	GetMetadataByVersionWithContext(ctx context.Context, version uint64) (*Metadata, error)
+	GetAccountWithContext(ctx context.Context, address diemtypes.AccountAddress) (*Account, error)
+	GetAccountTransactionWithContext(ctx context.Context, address diemtypes.AccountAddress, seq uint64, includeEvent bool) (*Transaction, error)
+	GetAccountTransactionsWithContext(ctx context.Context, address diemtypes.AccountAddress, start uint64, limit uint64, includeEvent bool) ([]*Transaction, error)
+	GetTransactionsWithContext(ctx context.Context, start uint64, limit uint64, includeEvent bool) ([]*Transaction, error)
+	GetEventsWithContext(ctx context.Context, key string, start uint64, limit uint64) ([]*Event, error)
+	SubmitWithContext(ctx context.Context, signedTxnHex string) error
+	SubmitTransactionWithContext(ctx context.Context, txn *diemtypes.SignedTransaction) error
+	WaitForTransactionWithContext(
+		ctx context.Context,
+		address diemtypes.AccountAddress,
+		seq uint64,
+		hash string,
+		expirationTimeSec uint64,
+	) (*Transaction, error)
+	WaitForTransaction2WithContext(ctx context.Context, txn *diemtypes.SignedTransaction) (*Transaction, error)
+	WaitForTransaction3WithContext(ctx context.Context, signedTxnHex string) (*Transaction, error)
+}
+
+// StateClient queries on-chain state: state proofs, account state blobs and account states at a
+// ledger version.
+type StateClient interface {
+	GetStateProof(version uint64) (*StateProof, error)
+	GetStateProofWithContext(ctx context.Context, version uint64) (*StateProof, error)
+	GetAccountStateBlob(address diemtypes.AccountAddress) ([]byte, error)
+	GetAccountStateBlobWithContext(ctx context.Context, address diemtypes.AccountAddress) ([]byte, error)
+	GetAccountAtVersion(address diemtypes.AccountAddress, version uint64) (*Account, error)
+	GetAccountAtVersionWithContext(ctx context.Context, address diemtypes.AccountAddress, version uint64) (*Account, error)
+}
+
+// ComplianceClient checks payments against on-chain compliance rules: frozen accounts and the
+// dual attestation limit.
+type ComplianceClient interface {
+	IsAccountFrozen(address diemtypes.AccountAddress) (bool, error)
+	IsAccountFrozenWithContext(ctx context.Context, address diemtypes.AccountAddress) (bool, error)
+	GetDualAttestationLimit() (uint64, error)
+	GetDualAttestationLimitWithContext(ctx context.Context) (uint64, error)
+	IsTravelRuleRequired(amount uint64, currency string) (bool, error)
+	IsTravelRuleRequiredWithContext(ctx context.Context, amount uint64, currency string) (bool, error)
+}
+
+// EstimateClient estimates transactions before submission
+type EstimateClient interface {
+	EstimateTransaction(rawTxn *diemtypes.RawTransaction) (*Estimate, error)
+	EstimateTransactionWithContext(ctx context.Context, rawTxn *diemtypes.RawTransaction) (*Estimate, error)
+	SimulateTransaction(rawTxn *diemtypes.RawTransaction) (*Estimate, error)
+	SimulateTransactionWithContext(ctx context.Context, rawTxn *diemtypes.RawTransaction) (*Estimate, error)
+}
+
+// PagingClient iterates account transactions and streams transactions
+type PagingClient interface {
+	GetAccountTransactionsPaged(address diemtypes.AccountAddress, start uint64, limit uint64) *AccountTransactionsIterator
+	GetAccountTransactionsPagedWithContext(ctx context.Context, address diemtypes.AccountAddress, start uint64, limit uint64) *AccountTransactionsIterator
+	StreamTransactions(ctx context.Context, startVersion uint64, batchSize uint64, includeEvents bool) *TransactionStream
+}
+
+// WaitResultClient waits for transactions with execution details
+type WaitResultClient interface {
+	WaitForTransactionResult(ctx context.Context, txn *diemtypes.SignedTransaction, onPoll func(*WaitPoll)) (*WaitResult, error)
+}
+
+// ChainStateClient reports the client chain id and detected chain regression
+type ChainStateClient interface {
+	ChainID() byte
+	LastChainRegression() *ChainRegressionError
+}
+
+// UnsupportedClientError is error for calling a capability that the given `Client` does not
+// implement, e.g. `StateClient` of a mock client.
+type UnsupportedClientError struct {
+	Client    Client
+	Interface string
+}
+
+// Error implements error interface
+func (e *UnsupportedClientError) Error() string {
+	return fmt.Sprintf("client %T does not implement diemclient.%s", e.Client, e.Interface)
+}
+
 // New creates a `DiemClient` connect to given server URL.
 // It creates default jsonrpc client `http.Transport` config, if you need to customize
-// `http.Transport` config (for better connection pool production usage), call `NewWithJsonRpcClient` with
+// `http.Transport` config (for better connection pool production usage), TLS config or proxy,
+// use option `WithHTTPClient` or `WithHTTPOptions`; or call `NewWithJsonRpcClient` with
 // `jsonrpc.NewClientWithTransport(url, <your http.Transport>)`
-func New(chainID byte, url string) Client {
-	return NewWithJsonRpcClient(chainID, jsonrpc.NewClient(url))
+func New(chainID byte, url string, opts ...Option) Client {
+	c := newClient(chainID, opts)
+	c.rpc = c.newJsonRpcClient(url)
+	return c
+}
+
+// NewWithFailover creates a `DiemClient` connect to multiple full-node URLs by
+// `jsonrpc.FailoverClient`: calls fail over between the URLs when one returns errors or stale
+// responses. Call `NewWithJsonRpcClient` with a configured `jsonrpc.FailoverClient` for
+// load balancing and health checking.
+func NewWithFailover(chainID byte, urls []string, opts ...Option) Client {
+	c := newClient(chainID, opts)
+	endpoints := make([]jsonrpc.Endpoint, len(urls))
+	for i, url := range urls {
+		endpoints[i] = jsonrpc.Endpoint{URL: url, Client: c.newJsonRpcClient(url)}
+	}
+	c.rpc = jsonrpc.NewFailoverClient(endpoints...)
+	return c
+}
+
+// NewWithJsonRpcClient creates a `DiemClient` with given `jsonrpc.Client`.
+// HTTP options `WithHTTPClient` and `WithHTTPOptions` do not apply to the given `jsonrpc.Client`,
+// they are ignored with a warning log; configure the `*http.Client` of the `jsonrpc.Client`
+// instead, e.g. `jsonrpc.NewClientWithHTTPClient(url, jsonrpc.NewHTTPClient(opts...))`.
+func NewWithJsonRpcClient(chainID byte, rpc jsonrpc.Client, opts ...Option) Client {
+	c := newClient(chainID, opts)
+	if c.httpClient != nil || len(c.httpOpts) > 0 {
+		c.logger.Log(diemlog.LevelWarn, "HTTP options are ignored, as the jsonrpc.Client is given")
+	}
+	c.rpc = rpc
+	return c
 }
 
-// NewWithJsonRpcClient creates a `DiemClient` with given `jsonrpc.Client`
-func NewWithJsonRpcClient(chainID byte, rpc jsonrpc.Client) Client {
-	return &client{chainID: chainID, rpc: rpc, retryOpts: []retry.Option{retry.LastErrorOnly(true)}}
+func newClient(chainID byte, opts []Option) *client {
+	c := &client{
+		chainID:            chainID,
+		retryOpts:          []retry.Option{retry.LastErrorOnly(true)},
+		retryPolicy:        DefaultRetryPolicy(),
+		resetConfirmations: DefaultChainResetConfirmations,
+		alerts:             NopAlerts,
+		staleResponses:     alertCounter{threshold: DefaultPersistentStalenessThreshold},
+		submitFailures:     alertCounter{threshold: DefaultSubmissionFailuresThreshold},
+		currencies:         &ttlCache{},
+		metadata:           &ttlCache{},
+		gasConstants:       &ttlCache{},
+		streamConcurrency:  DefaultStreamConcurrency,
+		logger:             diemlog.Nop,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.onChainReset != nil && c.regressionTolerance == nil {
+		c.regressionTolerance = &LedgerState{
+			Version:       DefaultChainRegressionVersionTolerance,
+			TimestampUsec: uint64(DefaultChainRegressionTimeTolerance.Microseconds()),
+		}
+	}
+	return c
 }
 
 // LedgerState represents response DiemLedgerTimestampusec & DiemLedgerVersion
@@ -111,6 +297,52 @@ type client struct {
 	mux       sync.RWMutex
 	last      LedgerState
 	retryOpts []retry.Option
+
+	retryPolicy RetryPolicy
+
+	// regressionTolerance is nil when chain regression detection is disabled
+	regressionTolerance *LedgerState
+	onChainRegression   func(*ChainRegressionError)
+	lastRegression      *ChainRegressionError
+	onChainReset        func(*ChainResetError)
+	resetConfirmations  int
+	resetSignal         *resetSignal
+
+	staleTolerance  LedgerState
+	onStaleResponse func(*StaleResponseError)
+
+	skipChainIDVerification bool
+
+	alerts         Alerts
+	staleResponses alertCounter
+	submitFailures alertCounter
+
+	httpClient *http.Client
+	httpOpts   []jsonrpc.HTTPOption
+
+	hooks  []Hooks
+	tracer trace.Tracer
+	logger diemlog.Logger
+
+	configsTTL   time.Duration
+	currencies   *ttlCache
+	metadata     *ttlCache
+	gasConstants *ttlCache
+
+	responses *responseCache
+
+	streamConcurrency int
+}
+
+func (c *client) newJsonRpcClient(url string) jsonrpc.Client {
+	if c.httpClient == nil {
+		return jsonrpc.NewClient(url, c.httpOpts...)
+	}
+	if jsonrpc.IgnoresHTTPOptions(c.httpClient, c.httpOpts...) {
+		c.logger.Log(diemlog.LevelWarn, "transport HTTP options are ignored, the round tripper is not *http.Transport",
+			diemlog.F("url", url))
+	}
+	return jsonrpc.NewClientWithHTTPClient(url, jsonrpc.ConfigureHTTPClient(c.httpClient, c.httpOpts...))
 }
 
 // WithRetryOptions appends given retry options
@@ -119,6 +351,13 @@ func (c *client) WithRetryOptions(opts ...retry.Option) Client {
 	return c
 }
 
+// ChainID returns chain id of the client
+func (c *client) ChainID() byte {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.chainID
+}
+
 // LastResponseLedgerState returns last recorded response ledger state
 func (c *client) LastResponseLedgerState() LedgerState {
 	c.mux.RLock()
@@ -126,24 +365,101 @@ func (c *client) LastResponseLedgerState() LedgerState {
 	return c.last
 }
 
-// UpdateLastResponseLedgerState updates LastResponseLedgerState
+// LastChainRegression returns last detected chain regression, nil if there is none.
+func (c *client) LastChainRegression() *ChainRegressionError {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.lastRegression
+}
+
+// UpdateLastResponseLedgerState updates LastResponseLedgerState.
+// Returns `*ChainRegressionError` if given state is behind last response ledger state more than
+// chain regression tolerance (see `WithChainRegressionTolerance`), otherwise returns `*StaleResponseError` if given state is older
+// beyond the stale response tolerance.
 func (c *client) UpdateLastResponseLedgerState(state LedgerState) error {
 	c.mux.Lock()
-	defer c.mux.Unlock()
 	var last = c.last
 	if last.Version == state.Version && last.TimestampUsec == state.TimestampUsec {
+		c.staleResponses.reset()
+		c.resetSignal = nil
+		c.mux.Unlock()
 		return nil
 	}
 	if last.Version > state.Version || last.TimestampUsec > state.TimestampUsec {
-		return &StaleResponseError{Client: last, Server: state}
+		if c.isRegression(last, state) {
+			regression := &ChainRegressionError{Client: last, Server: state}
+			c.lastRegression = regression
+			var reset *ChainResetError
+			if c.onChainReset != nil && c.signalResetLocked(ChainResetLedgerRegression, c.chainID, state) {
+				reset = &ChainResetError{Reason: ChainResetLedgerRegression, ChainID: c.chainID,
+					Client: last, Server: state, Cause: regression}
+				c.resetLocked(state)
+			}
+			c.mux.Unlock()
+			c.logger.Log(diemlog.LevelError, "chain regression detected",
+				diemlog.F("client_version", last.Version), diemlog.F("server_version", state.Version))
+			if c.onChainRegression != nil {
+				c.onChainRegression(regression)
+			}
+			if c.alerts != nil {
+				c.alerts.ChainRegression(regression)
+			}
+			if reset != nil {
+				c.chainReset(reset)
+				return reset
+			}
+			return regression
+		}
+		c.resetSignal = nil
+		if c.isWithinStaleTolerance(last, state) {
+			c.mux.Unlock()
+			return nil
+		}
+		stale := &StaleResponseError{Client: last, Server: state}
+		count, alert := c.staleResponses.inc()
+		c.mux.Unlock()
+		c.logger.Log(diemlog.LevelWarn, "stale response",
+			diemlog.F("client_version", last.Version), diemlog.F("server_version", state.Version),
+			diemlog.F("count", count))
+		if c.onStaleResponse != nil {
+			c.onStaleResponse(stale)
+		}
+		if alert && c.alerts != nil {
+			c.alerts.PersistentStaleness(count, stale)
+		}
+		return stale
 	}
 
 	c.last = state
+	c.staleResponses.reset()
+	c.resetSignal = nil
+	c.mux.Unlock()
 	return nil
 }
 
+func (c *client) isWithinStaleTolerance(last, state LedgerState) bool {
+	return last.Version <= state.Version+c.staleTolerance.Version &&
+		last.TimestampUsec <= state.TimestampUsec+c.staleTolerance.TimestampUsec
+}
+
+func (c *client) isRegression(last, state LedgerState) bool {
+	if c.regressionTolerance == nil {
+		return false
+	}
+	return last.Version > state.Version+c.regressionTolerance.Version ||
+		last.TimestampUsec > state.TimestampUsec+c.regressionTolerance.TimestampUsec
+}
+
 // WaitForTransaction3 waits for given `SignedTransaction` hex string
 func (c *client) WaitForTransaction3(signedTxnHex string, timeout time.Duration) (*Transaction, error) {
+	return c.waitWithTimeout(timeout, func(ctx context.Context) (*Transaction, error) {
+		return c.WaitForTransaction3WithContext(ctx, signedTxnHex)
+	})
+}
+
+// WaitForTransaction3WithContext waits for given `SignedTransaction` hex string until
+// the context is done
+func (c *client) WaitForTransaction3WithContext(ctx context.Context, signedTxnHex string) (*Transaction, error) {
 	bytes, err := hex.DecodeString(signedTxnHex)
 	if err != nil {
 		return nil, err
@@ -152,76 +468,78 @@ func (c *client) WaitForTransaction3(signedTxnHex string, timeout time.Duration)
 	if err != nil {
 		return nil, fmt.Errorf("Deserialize given hex string as SignedTransaction BCS failed: %v", err.Error())
 	}
-	return c.WaitForTransaction2(&txn, timeout)
+	return c.WaitForTransaction2WithContext(ctx, &txn)
 }
 
 // WaitForTransaction2 waits for given `SignedTransaction`
 func (c *client) WaitForTransaction2(txn *diemtypes.SignedTransaction, timeout time.Duration) (*Transaction, error) {
-	return c.WaitForTransaction(
+	return c.waitWithTimeout(timeout, func(ctx context.Context) (*Transaction, error) {
+		return c.WaitForTransaction2WithContext(ctx, txn)
+	})
+}
+
+// WaitForTransaction2WithContext waits for given `SignedTransaction` until the context is done
+func (c *client) WaitForTransaction2WithContext(ctx context.Context, txn *diemtypes.SignedTransaction) (*Transaction, error) {
+	return c.WaitForTransactionWithContext(
+		ctx,
 		txn.RawTxn.Sender,
 		txn.RawTxn.SequenceNumber,
 		txn.TransactionHash(),
 		txn.RawTxn.ExpirationTimestampSecs,
-		timeout,
 	)
 }
 
 // WaitForTransaction waits for given (address, sequence number, hash) transaction.
 func (c *client) WaitForTransaction(address diemtypes.AccountAddress, seq uint64, hash string, expirationTimeSec uint64, timeout time.Duration) (*Transaction, error) {
-	step := time.Millisecond * 500
-	start := time.Now()
-	for {
-		if time.Since(start) > timeout {
-			break
-		}
-		txn, err := c.GetAccountTransaction(address, seq, true)
-		if _, ok := err.(*StaleResponseError); ok {
-			continue
-		}
-		if err != nil {
-			return nil, err
-		}
-		if txn != nil {
-			if txn.Hash != hash {
-				return nil, &InvalidTransactionError{
-					Transaction: *txn,
-					Msg: fmt.Sprintf(
-						"transaction hash does not match, given %#v, but got %#v",
-						hash, txn.Hash),
-				}
-			}
-			if txn.VmStatus.Type != VmStatusExecuted {
-				return nil, &InvalidTransactionError{
-					Transaction: *txn,
-					Msg: fmt.Sprintf(
-						"transaction execution failed: %v", txn.VmStatus),
-				}
+	return c.waitWithTimeout(timeout, func(ctx context.Context) (*Transaction, error) {
+		return c.WaitForTransactionWithContext(ctx, address, seq, hash, expirationTimeSec)
+	})
+}
 
-			}
-			return txn, nil
-		}
-		if expirationTimeSec*1_000_000 <= c.LastResponseLedgerState().TimestampUsec {
-			return nil, errors.New("transaction expired")
-		}
-		time.Sleep(step)
+// WaitForTransactionWithContext waits for given (address, sequence number, hash) transaction
+// until the context is done; returns the context error if it is canceled or deadline exceeded.
+func (c *client) WaitForTransactionWithContext(ctx context.Context, address diemtypes.AccountAddress, seq uint64, hash string, expirationTimeSec uint64) (*Transaction, error) {
+	return c.waitForTransaction(ctx, address, seq, hash, expirationTimeSec, nil)
+}
+
+func (c *client) waitWithTimeout(timeout time.Duration, wait func(context.Context) (*Transaction, error)) (*Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	txn, err := wait(ctx)
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("transaction not found within timeout period: %v", timeout)
 	}
-	return nil, fmt.Errorf("transaction not found within timeout period: %v", timeout)
+	return txn, err
 }
 
-// GetCurrencies calls to "get_currencies" method
+// GetCurrencies calls to "get_currencies" method, the result is cached for the on-chain configs
+// cache TTL when it is enabled by `WithOnChainConfigsCacheTTL`.
 func (c *client) GetCurrencies() ([]*CurrencyInfo, error) {
-	var ret []*CurrencyInfo
-	ok, err := c.call(GetCurrencies, &ret)
-	if !ok {
+	return c.GetCurrenciesWithContext(context.Background())
+}
+
+// GetCurrenciesWithContext calls to "get_currencies" method with context, the result is cached
+// for the on-chain configs cache TTL when it is enabled.
+func (c *client) GetCurrenciesWithContext(ctx context.Context) ([]*CurrencyInfo, error) {
+	ret, err := c.currencies.get(ctx, c.configsTTL, func() (interface{}, bool, error) {
+		var ret []*CurrencyInfo
+		ok, err := c.call(ctx, GetCurrencies, &ret)
+		return ret, ok, err
+	})
+	if ret == nil {
 		return nil, err
 	}
 
-	return ret, nil
+	return ret.([]*CurrencyInfo), nil
 }
 
 func (c *client) GetMetadata() (*Metadata, error) {
+	return c.GetMetadataWithContext(context.Background())
+}
+
+func (c *client) GetMetadataWithContext(ctx context.Context) (*Metadata, error) {
 	var ret Metadata
-	ok, err := c.call(GetMetadata, &ret)
+	ok, err := c.call(ctx, GetMetadata, &ret)
 	if !ok {
 		return nil, err
 	}
@@ -229,19 +547,38 @@ func (c *client) GetMetadata() (*Metadata, error) {
 	return &ret, nil
 }
 
+// GetMetadataByVersion calls to "get_metadata" method with the version, the result is cached
+// when the response cache is enabled, see `WithResponseCache`.
 func (c *client) GetMetadataByVersion(version uint64) (*Metadata, error) {
+	return c.GetMetadataByVersionWithContext(context.Background(), version)
+}
+
+// GetMetadataByVersionWithContext is `GetMetadataByVersion` with context
+func (c *client) GetMetadataByVersionWithContext(ctx context.Context, version uint64) (*Metadata, error) {
+	key := responseCacheKey{method: GetMetadata, version: version}
+	if cached, ok := c.responses.get(key); ok {
+		return cached.(*Metadata), nil
+	}
 	var ret Metadata
-	ok, err := c.call(GetMetadata, &ret, version)
+	ok, err := c.call(ctx, GetMetadata, &ret, version)
 	if !ok {
 		return nil, err
 	}
+	c.responses.add(key, &ret)
 
 	return &ret, nil
 }
 
 func (c *client) GetAccount(address diemtypes.AccountAddress) (*Account, error) {
+	return c.GetAccountWithContext(context.Background(), address)
+}
+
+func (c *client) GetAccountWithContext(ctx context.Context, address diemtypes.AccountAddress) (_ *Account, err error) {
+	ctx, span := c.startSpan(ctx, "diemclient.GetAccount", AttributeAccountAddress.String(address.Hex()))
+	defer func() { c.endSpan(span, err) }()
+
 	var ret Account
-	ok, err := c.call(GetAccount, &ret, address.Hex())
+	ok, err := c.call(ctx, GetAccount, &ret, address.Hex())
 	if !ok {
 		return nil, err
 	}
@@ -249,9 +586,43 @@ func (c *client) GetAccount(address diemtypes.AccountAddress) (*Account, error)
 	return &ret, nil
 }
 
+// GetAccountAtVersion calls to "get_account" method with the ledger version, returns the account
+// state at the version, e.g. balances for audits; returns nil if the account does not exist at
+// the version. The result is cached when the response cache is enabled, see `WithResponseCache`.
+func (c *client) GetAccountAtVersion(address diemtypes.AccountAddress, version uint64) (*Account, error) {
+	return c.GetAccountAtVersionWithContext(context.Background(), address, version)
+}
+
+// GetAccountAtVersionWithContext is `GetAccountAtVersion` with context
+func (c *client) GetAccountAtVersionWithContext(ctx context.Context, address diemtypes.AccountAddress, version uint64) (_ *Account, err error) {
+	ctx, span := c.startSpan(ctx, "diemclient.GetAccountAtVersion", AttributeAccountAddress.String(address.Hex()))
+	defer func() { c.endSpan(span, err) }()
+
+	key := responseCacheKey{method: GetAccount, version: version, account: address}
+	if cached, ok := c.responses.get(key); ok {
+		return cached.(*Account), nil
+	}
+	var ret Account
+	ok, err := c.call(ctx, GetAccount, &ret, address.Hex(), version)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		c.responses.add(key, (*Account)(nil))
+		return nil, nil
+	}
+	c.responses.add(key, &ret)
+
+	return &ret, nil
+}
+
 func (c *client) GetAccountTransaction(address diemtypes.AccountAddress, sequenceNum uint64, includeEvent bool) (*Transaction, error) {
+	return c.GetAccountTransactionWithContext(context.Background(), address, sequenceNum, includeEvent)
+}
+
+func (c *client) GetAccountTransactionWithContext(ctx context.Context, address diemtypes.AccountAddress, sequenceNum uint64, includeEvent bool) (*Transaction, error) {
 	var ret Transaction
-	ok, err := c.call(GetAccountTransaction, &ret, address.Hex(), sequenceNum, includeEvent)
+	ok, err := c.call(ctx, GetAccountTransaction, &ret, address.Hex(), sequenceNum, includeEvent)
 	if !ok {
 		return nil, err
 	}
@@ -259,76 +630,189 @@ func (c *client) GetAccountTransaction(address diemtypes.AccountAddress, sequenc
 }
 
 func (c *client) GetAccountTransactions(address diemtypes.AccountAddress, start uint64, limit uint64, includeEvent bool) ([]*Transaction, error) {
+	return c.GetAccountTransactionsWithContext(context.Background(), address, start, limit, includeEvent)
+}
+
+func (c *client) GetAccountTransactionsWithContext(ctx context.Context, address diemtypes.AccountAddress, start uint64, limit uint64, includeEvent bool) ([]*Transaction, error) {
 	var ret []*Transaction
-	_, err := c.call(GetAccountTransactions, &ret, address.Hex(), start, limit, includeEvent)
+	_, err := c.call(ctx, GetAccountTransactions, &ret, address.Hex(), start, limit, includeEvent)
 	if err != nil {
 		return nil, err
 	}
 	return ret, nil
 }
 
+// GetTransactions calls to "get_transactions" method, the transactions are cached by version
+// when the response cache is enabled, see `WithResponseCache`.
 func (c *client) GetTransactions(startVersion uint64, limit uint64, includeEvent bool) ([]*Transaction, error) {
+	return c.GetTransactionsWithContext(context.Background(), startVersion, limit, includeEvent)
+}
+
+// GetTransactionsWithContext is `GetTransactions` with context
+func (c *client) GetTransactionsWithContext(ctx context.Context, startVersion uint64, limit uint64, includeEvent bool) ([]*Transaction, error) {
+	if cached, ok := c.responses.transactions(startVersion, limit, includeEvent); ok {
+		return cached, nil
+	}
 	var ret []*Transaction
-	ok, err := c.call(GetTransactions, &ret, startVersion, limit, includeEvent)
+	ok, err := c.call(ctx, GetTransactions, &ret, startVersion, limit, includeEvent)
 	if !ok {
 		return nil, err
 	}
+	c.responses.addTransactions(ret, includeEvent)
 	return ret, nil
 }
 
 func (c *client) GetEvents(key string, start uint64, limit uint64) ([]*Event, error) {
+	return c.GetEventsWithContext(context.Background(), key, start, limit)
+}
+
+func (c *client) GetEventsWithContext(ctx context.Context, key string, start uint64, limit uint64) ([]*Event, error) {
 	var ret []*Event
-	ok, err := c.call(GetEvents, &ret, key, start, limit)
+	ok, err := c.call(ctx, GetEvents, &ret, key, start, limit)
 	if !ok {
 		return nil, err
 	}
 	return ret, nil
 }
 
+// GetStateProof calls to "get_state_proof" method, returns proofs from given known version to
+// server latest ledger info. See package `ledgerverify` for verifying the proofs.
+func (c *client) GetStateProof(version uint64) (*StateProof, error) {
+	return c.GetStateProofWithContext(context.Background(), version)
+}
+
+// GetStateProofWithContext calls to "get_state_proof" method with context
+func (c *client) GetStateProofWithContext(ctx context.Context, version uint64) (*StateProof, error) {
+	var ret StateProof
+	ok, err := c.call(ctx, GetStateProof, &ret, version)
+	if !ok {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// GetAccountStateBlob calls to "get_account_state_with_proof" method, returns account state blob
+// BCS bytes at the latest ledger version; returns nil if the account does not exist.
+// See package `accountstate` for decoding the resources in the account state blob.
+func (c *client) GetAccountStateBlob(address diemtypes.AccountAddress) ([]byte, error) {
+	return c.GetAccountStateBlobWithContext(context.Background(), address)
+}
+
+// GetAccountStateBlobWithContext calls to "get_account_state_with_proof" method with context
+func (c *client) GetAccountStateBlobWithContext(ctx context.Context, address diemtypes.AccountAddress) ([]byte, error) {
+	var ret AccountStateWithProof
+	ok, err := c.call(ctx, GetAccountStateWithProof, &ret, address.Hex())
+	if !ok || ret.Blob == "" {
+		return nil, err
+	}
+	blob, err := hex.DecodeString(ret.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("decode account state blob hex failed: %v", err)
+	}
+	return blob, nil
+}
+
 // Submit hex-encoded signed transaction bytes to mempool.
 // This function ignores StaleResponseError and does not retry on any errors.
 func (c *client) Submit(data string) error {
-	ok, err := c.callWithoutRetry(Submit, nil, data)
+	return c.SubmitWithContext(context.Background(), data)
+}
+
+// SubmitWithContext submits hex-encoded signed transaction bytes to mempool with context.
+// This function ignores StaleResponseError and does not retry on any errors.
+func (c *client) SubmitWithContext(ctx context.Context, data string) error {
+	return c.submit(ctx, data)
+}
+
+// submit submits the signed transaction hex, and logs the result with given fields
+func (c *client) submit(ctx context.Context, data string, fields ...diemlog.Field) error {
+	ok, err := c.callWithoutRetry(ctx, Submit, nil, data)
 	if !ok {
 		if _, ok := err.(*StaleResponseError); ok {
-			return nil
+			err = nil
 		}
-		return err
 	}
-	return nil
+	err = newSubmitError(err)
+	c.recordSubmission(err)
+	if err != nil {
+		c.logger.Log(diemlog.LevelWarn, "transaction submission failed", append(fields, diemlog.F("error", err))...)
+	} else {
+		c.logger.Log(diemlog.LevelInfo, "transaction submitted", fields...)
+	}
+	return err
+}
+
+func (c *client) recordSubmission(err error) {
+	c.mux.Lock()
+	if err == nil {
+		c.submitFailures.reset()
+		c.mux.Unlock()
+		return
+	}
+	count, alert := c.submitFailures.inc()
+	c.mux.Unlock()
+	if alert && c.alerts != nil {
+		c.alerts.SubmissionFailures(count, err)
+	}
 }
 
 func (c *client) SubmitTransaction(txn *diemtypes.SignedTransaction) error {
-	return c.Submit(diemtypes.ToHex(txn))
+	return c.SubmitTransactionWithContext(context.Background(), txn)
 }
 
-func (c *client) call(method jsonrpc.Method, ret interface{}, params ...jsonrpc.Param) (ok bool, err error) {
+func (c *client) SubmitTransactionWithContext(ctx context.Context, txn *diemtypes.SignedTransaction) (err error) {
+	ctx, span := c.startSpan(ctx, "diemclient.SubmitTransaction",
+		AttributeTransactionHash.String(txn.TransactionHash()),
+		AttributeAccountAddress.String(txn.RawTxn.Sender.Hex()),
+		AttributeSequenceNumber.Uint64(txn.RawTxn.SequenceNumber))
+	defer func() { c.endSpan(span, err) }()
+
+	return c.submit(ctx, diemtypes.ToHex(txn),
+		diemlog.F("hash", txn.TransactionHash()),
+		diemlog.F("sender", txn.RawTxn.Sender.Hex()),
+		diemlog.F("sequence_number", txn.RawTxn.SequenceNumber))
+}
+
+func (c *client) call(ctx context.Context, method jsonrpc.Method, ret interface{}, params ...jsonrpc.Param) (ok bool, err error) {
+	opts := c.retryPolicy.options()
+	opts = append(opts, c.retryOpts...)
 	err = retry.Do(
 		func() error {
-			ok, err = c.callWithoutRetry(method, ret, params...)
+			ok, err = c.callWithoutRetry(ctx, method, ret, params...)
 			return err
 		},
-		c.retryOpts...,
+		append(opts, retry.Context(ctx))...,
 	)
 	return ok, err
 }
 
-func (c *client) callWithoutRetry(method jsonrpc.Method, ret interface{}, params ...jsonrpc.Param) (bool, error) {
+func (c *client) callWithoutRetry(ctx context.Context, method jsonrpc.Method, ret interface{}, params ...jsonrpc.Param) (ok bool, err error) {
 	req := jsonrpc.NewRequest(method, params...)
-	resps, err := c.rpc.Call(req)
+	var resp *jsonrpc.Response
+	c.beforeCall(ctx, req)
+	defer func(start time.Time) {
+		c.afterCall(ctx, req, resp, start, err)
+		fields := []diemlog.Field{diemlog.F("method", method), diemlog.F("latency", time.Since(start))}
+		if err != nil {
+			fields = append(fields, diemlog.F("error", err))
+		}
+		c.logger.Log(diemlog.LevelDebug, "JSON-RPC call", fields...)
+	}(time.Now())
+
+	resps, err := jsonrpc.CallWithContext(ctx, c.rpc, req)
 	if err != nil {
 		return false, err
 	}
-	resp := resps[req.ID]
+	resp = resps[req.ID]
 
-	if err = c.validateChainID(byte(resp.DiemChainID)); err != nil {
-		return false, err
-	}
-	err = c.UpdateLastResponseLedgerState(LedgerState{
+	state := LedgerState{
 		TimestampUsec: resp.DiemLedgerTimestampusec,
 		Version:       resp.DiemLedgerVersion,
-	})
-	if err != nil {
+	}
+	if err = c.validateChainID(byte(resp.DiemChainID), state); err != nil {
+		return false, err
+	}
+	if err = c.UpdateLastResponseLedgerState(state); err != nil {
 		return false, err
 	}
 
@@ -338,9 +822,27 @@ func (c *client) callWithoutRetry(method jsonrpc.Method, ret interface{}, params
 	return resp.UnmarshalResult(ret)
 }
 
-func (c *client) validateChainID(chainID byte) error {
-	if c.chainID != chainID {
-		return fmt.Errorf("chain id mismatch error: expected server response chain id == %d, but got %d", c.chainID, chainID)
+// validateChainID returns `*ChainIDMismatchError` if the given chain id is different from the
+// client chain id; when chain reset handler is configured and consecutive responses confirmed
+// the chain id change, `*ChainResetError` is returned instead. The client chain id is never
+// changed.
+func (c *client) validateChainID(chainID byte, state LedgerState) error {
+	if c.skipChainIDVerification {
+		return nil
 	}
-	return nil
+	c.mux.Lock()
+	if c.chainID == chainID {
+		c.mux.Unlock()
+		return nil
+	}
+	mismatch := &ChainIDMismatchError{Expected: c.chainID, Actual: chainID}
+	if c.onChainReset == nil || !c.signalResetLocked(ChainResetChainIDChanged, chainID, state) {
+		c.mux.Unlock()
+		return mismatch
+	}
+	reset := &ChainResetError{Reason: ChainResetChainIDChanged, ChainID: chainID,
+		Client: c.last, Server: state, Cause: mismatch}
+	c.mux.Unlock()
+	c.chainReset(reset)
+	return reset
 }
@@ -4,7 +4,9 @@
 package diemclient_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/avast/retry-go"
 	"github.com/diem/client-sdk-go/diemclient"
 	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
 	"github.com/diem/client-sdk-go/jsonrpc"
 	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
 	"github.com/diem/client-sdk-go/testnet"
@@ -137,6 +140,9 @@ func TestWaitForTransaction(t *testing.T) {
 					time.Second*5,
 				)
 				assert.EqualError(t, err, "transaction hash does not match, given \"mismatched hash\", but got \"0fa27a781a9086e80a870851ea4f1b14090fb8b5bd9933e27447ab806443e08e\"")
+				var invalid *diemclient.InvalidTransactionError
+				require.True(t, errors.As(err, &invalid))
+				assert.Equal(t, uint64(106548), invalid.Transaction.Version)
 				assert.Nil(t, ret)
 			},
 		},
@@ -188,6 +194,7 @@ func TestWaitForTransaction(t *testing.T) {
 					time.Second*5,
 				)
 				assert.EqualError(t, err, "transaction expired")
+				assert.True(t, diemclient.IsTransactionExpired(err))
 				assert.Nil(t, ret)
 			},
 		},
@@ -210,6 +217,40 @@ func TestWaitForTransaction(t *testing.T) {
 				assert.Nil(t, ret)
 			},
 		},
+		{
+			name:     "wait for transaction with context: canceled",
+			response: jsonrpc.Response{Result: nil},
+			call: func(t *testing.T, client diemclient.Client) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				account := diemkeys.MustGenKeys()
+				ret, err := client.(diemclient.ContextClient).WaitForTransactionWithContext(
+					ctx,
+					account.AccountAddress(),
+					0,
+					"0fa27a781a9086e80a870851ea4f1b14090fb8b5bd9933e27447ab806443e08e",
+					uint64(time.Now().Add(time.Hour).Unix()),
+				)
+				assert.Equal(t, context.Canceled, err)
+				assert.Nil(t, ret)
+			},
+		},
+		{
+			name:     "wait for transaction: timeout",
+			response: jsonrpc.Response{Result: nil},
+			call: func(t *testing.T, client diemclient.Client) {
+				account := diemkeys.MustGenKeys()
+				ret, err := client.WaitForTransaction(
+					account.AccountAddress(),
+					0,
+					"0fa27a781a9086e80a870851ea4f1b14090fb8b5bd9933e27447ab806443e08e",
+					uint64(time.Now().Add(time.Hour).Unix()),
+					10*time.Millisecond,
+				)
+				assert.EqualError(t, err, "transaction not found within timeout period: 10ms")
+				assert.Nil(t, ret)
+			},
+		},
 		{
 			name:     "wait for transaction3 invalid hex string",
 			response: jsonrpc.Response{},
@@ -330,10 +371,182 @@ func TestHandleStaleResponse(t *testing.T) {
 	}
 }
 
+func TestChainRegression(t *testing.T) {
+	response := jsonrpc.Response{
+		DiemLedgerVersion:       10,
+		DiemLedgerTimestampusec: 1597722856123456,
+		Result: toPtr(json.RawMessage(`{
+    "timestamp": 1597722856123456,
+    "version": 10,
+    "chain_id": 2
+}`)),
+	}
+	newClient := func(opts ...diemclient.Option) diemclient.Client {
+		return diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+			Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: response},
+		}, opts...).WithRetryOptions(retry.Attempts(1))
+	}
+
+	t.Run("stale response within tolerance", func(t *testing.T) {
+		client := newClient()
+		client.UpdateLastResponseLedgerState(diemclient.LedgerState{
+			Version:       11,
+			TimestampUsec: 1597722856123457,
+		})
+		_, err := client.GetMetadata()
+		assert.IsType(t, &diemclient.StaleResponseError{}, err)
+		assert.Nil(t, client.(diemclient.ChainStateClient).LastChainRegression())
+	})
+	t.Run("lagging response is stale response without tolerance", func(t *testing.T) {
+		client := newClient()
+		client.UpdateLastResponseLedgerState(diemclient.LedgerState{
+			Version:       10 + diemclient.DefaultChainRegressionVersionTolerance + 1,
+			TimestampUsec: 1597722856123456 + uint64(time.Hour.Microseconds()),
+		})
+		_, err := client.GetMetadata()
+		assert.IsType(t, &diemclient.StaleResponseError{}, err)
+		assert.True(t, diemclient.IsRetryable(err))
+		assert.Nil(t, client.(diemclient.ChainStateClient).LastChainRegression())
+	})
+	t.Run("version regression beyond tolerance", func(t *testing.T) {
+		var called *diemclient.ChainRegressionError
+		client := newClient(
+			diemclient.WithChainRegressionTolerance(5, time.Minute),
+			diemclient.WithChainRegressionCallback(func(e *diemclient.ChainRegressionError) {
+				called = e
+			}),
+		)
+		client.UpdateLastResponseLedgerState(diemclient.LedgerState{
+			Version:       16,
+			TimestampUsec: 1597722856123457,
+		})
+		_, err := client.GetMetadata()
+		assert.EqualError(t, err, "chain regression error: server response ledger {1597722856123456 10} is far behind {1597722856123457 16}")
+		require.NotNil(t, called)
+		assert.Equal(t, err, called)
+		assert.Equal(t, called, client.(diemclient.ChainStateClient).LastChainRegression())
+		assert.Equal(t, uint64(16), client.LastResponseLedgerState().Version)
+	})
+	t.Run("timestamp regression beyond tolerance", func(t *testing.T) {
+		client := newClient(diemclient.WithChainRegressionTolerance(5, time.Second))
+		client.UpdateLastResponseLedgerState(diemclient.LedgerState{
+			Version:       10,
+			TimestampUsec: 1597722856123456 + 2_000_000,
+		})
+		_, err := client.GetMetadata()
+		assert.IsType(t, &diemclient.ChainRegressionError{}, err)
+		assert.NotNil(t, client.(diemclient.ChainStateClient).LastChainRegression())
+	})
+}
+
+func TestStaleResponseTolerance(t *testing.T) {
+	response := jsonrpc.Response{
+		DiemLedgerVersion:       10,
+		DiemLedgerTimestampusec: 1597722856123456,
+		Result:                  toPtr(json.RawMessage(`{"timestamp": 1597722856123456, "version": 10, "chain_id": 2}`)),
+	}
+	newClient := func(opts ...diemclient.Option) diemclient.Client {
+		return diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+			Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: response},
+		}, opts...).WithRetryOptions(retry.Attempts(1))
+	}
+	known := diemclient.LedgerState{Version: 15, TimestampUsec: 1597722856123456 + 1_000_000}
+
+	t.Run("accept response behind within tolerance", func(t *testing.T) {
+		client := newClient(diemclient.WithStaleResponseTolerance(5, time.Second))
+		client.UpdateLastResponseLedgerState(known)
+		_, err := client.GetMetadata()
+		assert.NoError(t, err)
+		assert.Equal(t, known, client.LastResponseLedgerState())
+	})
+	t.Run("stale response beyond tolerance", func(t *testing.T) {
+		var called *diemclient.StaleResponseError
+		client := newClient(
+			diemclient.WithStaleResponseTolerance(4, time.Second),
+			diemclient.WithStaleResponseCallback(func(e *diemclient.StaleResponseError) {
+				called = e
+			}),
+		)
+		client.UpdateLastResponseLedgerState(known)
+		_, err := client.GetMetadata()
+		assert.IsType(t, &diemclient.StaleResponseError{}, err)
+		require.NotNil(t, called)
+		assert.Equal(t, known, called.Client)
+		assert.Equal(t, uint64(10), called.Server.Version)
+	})
+}
+
+func TestAlerts(t *testing.T) {
+	newClient := func(response jsonrpc.Response, alerts diemclient.Alerts) diemclient.Client {
+		return diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+			Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: response},
+		}, diemclient.WithAlerts(alerts), diemclient.WithAlertThresholds(2, 2),
+			diemclient.WithChainRegressionTolerance(diemclient.DefaultChainRegressionVersionTolerance, time.Minute)).
+			WithRetryOptions(retry.Attempts(1))
+	}
+
+	t.Run("persistent staleness", func(t *testing.T) {
+		alerts := new(recordAlerts)
+		client := newClient(jsonrpc.Response{DiemLedgerVersion: 10, DiemLedgerTimestampusec: 10}, alerts)
+		client.UpdateLastResponseLedgerState(diemclient.LedgerState{Version: 11, TimestampUsec: 11})
+		client.GetMetadata()
+		assert.Empty(t, alerts.staleness)
+		client.GetMetadata()
+		assert.Equal(t, []int{2}, alerts.staleness)
+	})
+	t.Run("chain regression", func(t *testing.T) {
+		alerts := new(recordAlerts)
+		client := newClient(jsonrpc.Response{DiemLedgerVersion: 10, DiemLedgerTimestampusec: 10}, alerts)
+		client.UpdateLastResponseLedgerState(diemclient.LedgerState{
+			Version:       10 + diemclient.DefaultChainRegressionVersionTolerance + 1,
+			TimestampUsec: 10,
+		})
+		client.GetMetadata()
+		assert.Len(t, alerts.regressions, 1)
+	})
+	t.Run("submission failures", func(t *testing.T) {
+		alerts := new(recordAlerts)
+		client := newClient(jsonrpc.Response{
+			Error: &jsonrpc.ResponseError{Code: -32001, Message: "invalid"},
+		}, alerts)
+		assert.Error(t, client.Submit("00"))
+		assert.Empty(t, alerts.submissions)
+		assert.Error(t, client.Submit("00"))
+		assert.Equal(t, []int{2}, alerts.submissions)
+		assert.Error(t, client.Submit("00"))
+		assert.Error(t, client.Submit("00"))
+		assert.Equal(t, []int{2}, alerts.submissions, "fires once per streak")
+	})
+}
+
+type recordAlerts struct {
+	staleness   []int
+	regressions []*diemclient.ChainRegressionError
+	submissions []int
+	frozen      []diemtypes.AccountAddress
+}
+
+func (a *recordAlerts) PersistentStaleness(count int, err *diemclient.StaleResponseError) {
+	a.staleness = append(a.staleness, count)
+}
+
+func (a *recordAlerts) ChainRegression(err *diemclient.ChainRegressionError) {
+	a.regressions = append(a.regressions, err)
+}
+
+func (a *recordAlerts) SubmissionFailures(count int, err error) {
+	a.submissions = append(a.submissions, count)
+}
+
+func (a *recordAlerts) AccountFrozen(address diemtypes.AccountAddress) {
+	a.frozen = append(a.frozen, address)
+}
+
 func TestValidateChainID(t *testing.T) {
 	cases := []struct {
 		name     string
 		response jsonrpc.Response
+		opts     []diemclient.Option
 		call     func(t *testing.T, client diemclient.Client)
 	}{
 		{
@@ -349,9 +562,24 @@ func TestValidateChainID(t *testing.T) {
 			call: func(t *testing.T, client diemclient.Client) {
 				ret, err := client.GetMetadata()
 				assert.EqualError(t, err, fmt.Sprintf("chain id mismatch error: expected server response chain id == %d, but got 9", testnet.ChainID))
+				assert.Equal(t, &diemclient.ChainIDMismatchError{Expected: testnet.ChainID, Actual: 9}, err)
+				assert.False(t, diemclient.IsRetryable(err))
 				assert.Nil(t, ret)
 			},
 		},
+		{
+			name: "skip chain id verification",
+			response: jsonrpc.Response{
+				DiemChainID: 9,
+				Result:      toPtr(json.RawMessage(`{"timestamp": 1597722856123456, "version": 9, "chain_id": 9}`)),
+			},
+			opts: []diemclient.Option{diemclient.WithChainIDVerification(false)},
+			call: func(t *testing.T, client diemclient.Client) {
+				ret, err := client.GetMetadata()
+				require.NoError(t, err)
+				assert.Equal(t, uint64(9), ret.Version)
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -360,7 +588,7 @@ func TestValidateChainID(t *testing.T) {
 				Responses: map[jsonrpc.RequestID]jsonrpc.Response{
 					1: tc.response,
 				},
-			}).WithRetryOptions(retry.Attempts(1))
+			}, tc.opts...).WithRetryOptions(retry.Attempts(1))
 			tc.call(t, client)
 		})
 	}
@@ -369,3 +597,27 @@ func TestValidateChainID(t *testing.T) {
 func toPtr(msg json.RawMessage) *json.RawMessage {
 	return &msg
 }
+
+func TestGetAccountStateBlob(t *testing.T) {
+	newClient := func(result string) diemclient.Client {
+		resp := jsonrpc.Response{}
+		if result != "" {
+			resp.Result = toPtr(json.RawMessage(result))
+		}
+		return diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+			Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: resp},
+		}).WithRetryOptions(retry.Attempts(1))
+	}
+	address := diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+
+	blob, err := newClient(`{"version": 10, "blob": "0201ab", "proof": {}}`).(diemclient.StateClient).GetAccountStateBlob(address)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 1, 0xab}, blob)
+
+	blob, err = newClient(`{"version": 10, "proof": {}}`).(diemclient.StateClient).GetAccountStateBlob(address)
+	require.NoError(t, err)
+	assert.Nil(t, blob)
+
+	_, err = newClient(`{"version": 10, "blob": "xyz"}`).(diemclient.StateClient).GetAccountStateBlob(address)
+	assert.Error(t, err)
+}
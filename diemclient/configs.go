@@ -0,0 +1,110 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemamount"
+)
+
+// GetDualAttestationLimit returns the on-chain dual attestation limit in micro-XDX, the result is
+// cached for the on-chain configs cache TTL when it is enabled by `WithOnChainConfigsCacheTTL`.
+func (c *client) GetDualAttestationLimit() (uint64, error) {
+	return c.GetDualAttestationLimitWithContext(context.Background())
+}
+
+// GetDualAttestationLimitWithContext returns the on-chain dual attestation limit in micro-XDX
+// with context.
+func (c *client) GetDualAttestationLimitWithContext(ctx context.Context) (uint64, error) {
+	ret, err := c.metadata.get(ctx, c.configsTTL, func() (interface{}, bool, error) {
+		var ret Metadata
+		ok, err := c.call(ctx, GetMetadata, &ret)
+		return &ret, ok, err
+	})
+	if ret == nil {
+		if err == nil {
+			err = errors.New("metadata not found")
+		}
+		return 0, err
+	}
+	return ret.(*Metadata).DualAttestationLimit, nil
+}
+
+// IsTravelRuleRequired returns true if the amount of the currency is greater than or equal to
+// the dual attestation limit after converted into XDX by the on-chain exchange rate, i.e. a
+// payment of the amount between two different VASPs requires the travel rule off-chain flow.
+// The currencies info and dual attestation limit are cached for the on-chain configs cache TTL
+// when it is enabled.
+func (c *client) IsTravelRuleRequired(amount uint64, currency string) (bool, error) {
+	return c.IsTravelRuleRequiredWithContext(context.Background(), amount, currency)
+}
+
+// IsTravelRuleRequiredWithContext is `IsTravelRuleRequired` with context
+func (c *client) IsTravelRuleRequiredWithContext(ctx context.Context, amount uint64, currency string) (bool, error) {
+	limit, err := c.GetDualAttestationLimitWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	currencies, err := c.GetCurrenciesWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	xdx, err := diemamount.NewConverter(currencies).ToXDX(
+		diemamount.New(diemamount.Currency(currency), amount))
+	if err != nil {
+		return false, err
+	}
+	return xdx.Micro >= limit, nil
+}
+
+type bypassConfigsCacheKey struct{}
+
+// BypassOnChainConfigsCache returns a context for calls that must load on-chain configs from the
+// server, e.g. `GetCurrenciesWithContext` for the latest exchange rates. The loaded value still
+// refreshes the on-chain configs cache, see `WithOnChainConfigsCacheTTL`.
+func BypassOnChainConfigsCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassConfigsCacheKey{}, true)
+}
+
+// ttlCache caches the loaded value until it expires; errors and not found results are not
+// cached.
+type ttlCache struct {
+	mux       sync.Mutex
+	value     interface{}
+	expiresAt time.Time
+}
+
+// get returns cached value if it is not expired, otherwise calls load to reload the value; the
+// cached value is not used when the context is created by `BypassOnChainConfigsCache`.
+// Returns nil value if load returns not ok.
+func (c *ttlCache) get(ctx context.Context, ttl time.Duration, load func() (interface{}, bool, error)) (interface{}, error) {
+	c.mux.Lock()
+	value, expiresAt := c.value, c.expiresAt
+	c.mux.Unlock()
+	if value != nil && time.Now().Before(expiresAt) && ctx.Value(bypassConfigsCacheKey{}) == nil {
+		return value, nil
+	}
+
+	value, ok, err := load()
+	if !ok {
+		return nil, err
+	}
+	if ttl > 0 {
+		c.mux.Lock()
+		c.value, c.expiresAt = value, time.Now().Add(ttl)
+		c.mux.Unlock()
+	}
+	return value, nil
+}
+
+// reset drops the cached value
+func (c *ttlCache) reset() {
+	c.mux.Lock()
+	c.value, c.expiresAt = nil, time.Time{}
+	c.mux.Unlock()
+}
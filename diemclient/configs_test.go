@@ -0,0 +1,162 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const currenciesResult = `[
+  {"code": "XUS", "scaling_factor": 1000000, "fractional_part": 100, "to_xdx_exchange_rate": 1},
+  {"code": "XDX", "scaling_factor": 1000000, "fractional_part": 1000, "to_xdx_exchange_rate": 1},
+  {"code": "HALF", "scaling_factor": 1000000, "fractional_part": 100, "to_xdx_exchange_rate": 0.5}
+]`
+
+const metadataResult = `{"version": 100, "timestamp": 1000, "chain_id": 2, "dual_attestation_limit": 1000000000}`
+
+// methodStub responds result by request method and counts calls of each method
+type methodStub struct {
+	results map[jsonrpc.Method]json.RawMessage
+	calls   map[jsonrpc.Method]int
+}
+
+func (s *methodStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	if s.calls == nil {
+		s.calls = make(map[jsonrpc.Method]int)
+	}
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{}}
+	for _, req := range requests {
+		s.calls[req.Method]++
+		var resp jsonrpc.Response
+		if result, ok := s.results[req.Method]; ok {
+			resp.Result = &result
+		}
+		stub.Responses[req.ID] = resp
+	}
+	return stub.Call(requests...)
+}
+
+func newConfigsStub() *methodStub {
+	return &methodStub{results: map[jsonrpc.Method]json.RawMessage{
+		diemclient.GetCurrencies: json.RawMessage(currenciesResult),
+		diemclient.GetMetadata:   json.RawMessage(metadataResult),
+	}}
+}
+
+func TestGetDualAttestationLimit(t *testing.T) {
+	stub := newConfigsStub()
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub,
+		diemclient.WithOnChainConfigsCacheTTL(time.Hour))
+	for i := 0; i < 3; i++ {
+		limit, err := client.(diemclient.ComplianceClient).GetDualAttestationLimit()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1000000000), limit)
+	}
+	assert.Equal(t, 1, stub.calls[diemclient.GetMetadata])
+
+	t.Run("metadata not found", func(t *testing.T) {
+		stub := &methodStub{}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub).WithRetryOptions(retry.Attempts(1))
+		_, err := client.(diemclient.ComplianceClient).GetDualAttestationLimit()
+		assert.EqualError(t, err, "metadata not found")
+		_, err = client.(diemclient.ComplianceClient).GetDualAttestationLimit()
+		assert.Error(t, err)
+		assert.Equal(t, 2, stub.calls[diemclient.GetMetadata])
+	})
+}
+
+func TestGetCurrenciesCache(t *testing.T) {
+	cases := []struct {
+		name  string
+		ttl   time.Duration
+		calls int
+	}{
+		{name: "cached", ttl: time.Hour, calls: 1},
+		{name: "disabled by default", ttl: -1, calls: 3},
+		{name: "cache disabled", ttl: 0, calls: 3},
+		{name: "cache expired", ttl: time.Nanosecond, calls: 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := newConfigsStub()
+			var opts []diemclient.Option
+			if tc.ttl >= 0 {
+				opts = append(opts, diemclient.WithOnChainConfigsCacheTTL(tc.ttl))
+			}
+			client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub, opts...)
+			for i := 0; i < 3; i++ {
+				currencies, err := client.GetCurrencies()
+				require.NoError(t, err)
+				assert.Len(t, currencies, 3)
+				time.Sleep(time.Microsecond)
+			}
+			assert.Equal(t, tc.calls, stub.calls[diemclient.GetCurrencies])
+		})
+	}
+}
+
+func TestBypassOnChainConfigsCache(t *testing.T) {
+	stub := newConfigsStub()
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub,
+		diemclient.WithOnChainConfigsCacheTTL(time.Hour))
+	ctx := diemclient.BypassOnChainConfigsCache(context.Background())
+	for i := 0; i < 2; i++ {
+		_, err := client.(diemclient.ContextClient).GetCurrenciesWithContext(ctx)
+		require.NoError(t, err)
+		_, err = client.(diemclient.ComplianceClient).GetDualAttestationLimitWithContext(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, stub.calls[diemclient.GetCurrencies])
+	assert.Equal(t, 2, stub.calls[diemclient.GetMetadata])
+
+	_, err := client.GetCurrencies()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stub.calls[diemclient.GetCurrencies], "loaded value is cached")
+}
+
+func TestIsTravelRuleRequired(t *testing.T) {
+	cases := []struct {
+		name     string
+		amount   uint64
+		currency string
+		expected bool
+		err      error
+	}{
+		{name: "below limit", amount: 999999999, currency: "XUS", expected: false},
+		{name: "equal to limit", amount: 1000000000, currency: "XUS", expected: true},
+		{name: "above limit", amount: 1000000001, currency: "XDX", expected: true},
+		{name: "converted by exchange rate", amount: 1999999999, currency: "HALF", expected: false},
+		{name: "converted amount equal to limit", amount: 2000000000, currency: "HALF", expected: true},
+		{name: "unknown currency", amount: 1, currency: "XXX",
+			err: &diemamount.UnknownCurrencyError{Currency: "XXX"}},
+	}
+	stub := newConfigsStub()
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub,
+		diemclient.WithOnChainConfigsCacheTTL(time.Hour))
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := client.(diemclient.ComplianceClient).IsTravelRuleRequired(tc.amount, tc.currency)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ret)
+		})
+	}
+	assert.Equal(t, 1, stub.calls[diemclient.GetCurrencies])
+	assert.Equal(t, 1, stub.calls[diemclient.GetMetadata])
+}
@@ -0,0 +1,126 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// AsContextClient returns the given client if it implements `ContextClient`, otherwise returns
+// `ContextClient` calls the `Client` methods: the context is checked before each call, but it
+// does not cancel calls in flight; waiting for a transaction times out at the context deadline.
+func AsContextClient(c Client) ContextClient {
+	if ret, ok := c.(ContextClient); ok {
+		return ret
+	}
+	return &contextClient{c}
+}
+
+type contextClient struct {
+	Client
+}
+
+func (c *contextClient) GetCurrenciesWithContext(ctx context.Context) ([]*CurrencyInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.GetCurrencies()
+}
+
+func (c *contextClient) GetMetadataWithContext(ctx context.Context) (*Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.GetMetadata()
+}
+
+func (c *contextClient) GetMetadataByVersionWithContext(ctx context.Context, version uint64) (*Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.GetMetadataByVersion(version)
+}
+
+func (c *contextClient) GetAccountWithContext(ctx context.Context, address diemtypes.AccountAddress) (*Account, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.GetAccount(address)
+}
+
+func (c *contextClient) GetAccountTransactionWithContext(ctx context.Context, address diemtypes.AccountAddress, seq uint64, includeEvent bool) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.GetAccountTransaction(address, seq, includeEvent)
+}
+
+func (c *contextClient) GetAccountTransactionsWithContext(ctx context.Context, address diemtypes.AccountAddress, start uint64, limit uint64, includeEvent bool) ([]*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.GetAccountTransactions(address, start, limit, includeEvent)
+}
+
+func (c *contextClient) GetTransactionsWithContext(ctx context.Context, start uint64, limit uint64, includeEvent bool) ([]*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.GetTransactions(start, limit, includeEvent)
+}
+
+func (c *contextClient) GetEventsWithContext(ctx context.Context, key string, start uint64, limit uint64) ([]*Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.GetEvents(key, start, limit)
+}
+
+func (c *contextClient) SubmitWithContext(ctx context.Context, signedTxnHex string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Submit(signedTxnHex)
+}
+
+func (c *contextClient) SubmitTransactionWithContext(ctx context.Context, txn *diemtypes.SignedTransaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.SubmitTransaction(txn)
+}
+
+func (c *contextClient) WaitForTransactionWithContext(ctx context.Context, address diemtypes.AccountAddress, seq uint64, hash string, expirationTimeSec uint64) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.WaitForTransaction(address, seq, hash, expirationTimeSec, waitTimeout(ctx))
+}
+
+func (c *contextClient) WaitForTransaction2WithContext(ctx context.Context, txn *diemtypes.SignedTransaction) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.WaitForTransaction2(txn, waitTimeout(ctx))
+}
+
+func (c *contextClient) WaitForTransaction3WithContext(ctx context.Context, signedTxnHex string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.WaitForTransaction3(signedTxnHex, waitTimeout(ctx))
+}
+
+// waitTimeout returns duration until the context deadline, or max duration if there is no
+// deadline.
+func waitTimeout(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return math.MaxInt64
+}
@@ -0,0 +1,50 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accountClient implements only `GetAccount` of `diemclient.Client`
+type accountClient struct {
+	diemclient.Client
+	calls int
+}
+
+func (c *accountClient) GetAccount(address diemtypes.AccountAddress) (*diemclient.Account, error) {
+	c.calls++
+	return &diemclient.Account{Address: address.Hex()}, nil
+}
+
+func TestAsContextClient(t *testing.T) {
+	client := diemclient.New(testnet.ChainID, testnet.URL)
+	assert.Equal(t, client, diemclient.AsContextClient(client))
+
+	mock := &accountClient{}
+	address := diemkeys.MustGenKeys().AccountAddress()
+	account, err := diemclient.AsContextClient(mock).GetAccountWithContext(context.Background(), address)
+	require.NoError(t, err)
+	assert.Equal(t, address.Hex(), account.Address)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = diemclient.AsContextClient(mock).GetAccountWithContext(ctx, address)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 1, mock.calls)
+
+	_, err = diemclient.BalanceAtVersion(context.Background(), mock, address, "XUS", 1)
+	var unsupported *diemclient.UnsupportedClientError
+	require.True(t, errors.As(err, &unsupported))
+	assert.Equal(t, "StateClient", unsupported.Interface)
+}
@@ -1,5 +1,13 @@
 // Copyright (c) The Diem Core Contributors
 // SPDX-License-Identifier: Apache-2.0
 
-// Provides builders for creating JSON-RPC response result in test. Should only be used in test code.
+// Provides builders for creating JSON-RPC response result in test, and `Server`, an in-process
+// fake full node JSON-RPC server with canned accounts, transactions and event streams, and
+// failure injection (stale responses, timeouts, mempool and network errors) for unit testing
+// retry and reconciliation logic without network access. Should only be used in test code.
+//
+//	server := diemclienttest.NewServer()
+//	server.AddAccount(&diemclient.Account{Address: address.Hex(), Balances: balances})
+//	server.Fail(diemclient.Submit, diemclienttest.MempoolIsFull())
+//	client := server.Client()
 package diemclienttest
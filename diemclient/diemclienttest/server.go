@@ -0,0 +1,744 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclienttest
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"google.golang.org/protobuf/proto"
+)
+
+// JSON-RPC spec error codes returned by `Server`
+const (
+	ErrCodeInvalidParams  int32 = -32602
+	ErrCodeMethodNotFound int32 = -32601
+)
+
+// Server is an in-process fake full node JSON-RPC server serving canned accounts, transactions
+// and event streams; submitted transactions are executed immediately unless held by
+// `HoldTransactions`.
+//
+// It implements `jsonrpc.Client` for `diemclient.NewWithJsonRpcClient`, and `http.Handler` for
+// serving by `httptest.NewServer`. Failures can be injected by `Fail`.
+type Server struct {
+	ChainID byte
+
+	mu                   sync.Mutex
+	version              uint64
+	timestampUsec        uint64
+	timestamps           map[uint64]uint64
+	accounts             map[diemtypes.AccountAddress]*diemclient.Account
+	accountHistory       map[diemtypes.AccountAddress][]*accountSnapshot
+	txns                 []*diemclient.Transaction
+	accountTxns          map[diemtypes.AccountAddress][]*diemclient.Transaction
+	events               map[string][]*diemclient.Event
+	currencies           []*diemclient.CurrencyInfo
+	dualAttestationLimit uint64
+	pending              []*diemtypes.SignedTransaction
+	submitted            []*diemtypes.SignedTransaction
+	hold                 bool
+	executor             func(*diemtypes.SignedTransaction, *diemclient.Transaction)
+	failures             map[jsonrpc.Method][]Failure
+}
+
+// accountSnapshot is a copy of the account state at a ledger version
+type accountSnapshot struct {
+	version uint64
+	account *diemclient.Account
+}
+
+// Failure is a failure injected into `Server` responses
+type Failure struct {
+	// Stale responds ledger state older than the last response
+	Stale bool
+	// Delay delays the response, `Server#CallWithContext` returns the context error if the
+	// context is done before the delay.
+	Delay time.Duration
+	// Error responds the JSON-RPC error
+	Error *jsonrpc.ResponseError
+	// NetworkError fails the call without response
+	NetworkError error
+}
+
+// StaleResponse returns `Failure` responding stale ledger state
+func StaleResponse() Failure {
+	return Failure{Stale: true}
+}
+
+// Timeout returns `Failure` delaying the response for the duration
+func Timeout(delay time.Duration) Failure {
+	return Failure{Delay: delay}
+}
+
+// ResponseError returns `Failure` responding JSON-RPC error of the code
+func ResponseError(code int32, message string) Failure {
+	return Failure{Error: &jsonrpc.ResponseError{Code: code, Message: message}}
+}
+
+// MempoolIsFull returns `Failure` responding mempool is full error
+func MempoolIsFull() Failure {
+	return ResponseError(diemclient.ErrCodeMempoolIsFull, "Mempool is full")
+}
+
+// NetworkError returns `Failure` failing the call with the error
+func NetworkError(err error) Failure {
+	return Failure{NetworkError: err}
+}
+
+// NewServer creates `Server` of testnet chain id, with XUS and XDX currencies.
+func NewServer() *Server {
+	now := uint64(time.Now().UnixNano() / 1000)
+	return &Server{
+		ChainID:        testnet.ChainID,
+		version:        1,
+		timestampUsec:  now,
+		timestamps:     map[uint64]uint64{1: now},
+		accounts:       make(map[diemtypes.AccountAddress]*diemclient.Account),
+		accountHistory: make(map[diemtypes.AccountAddress][]*accountSnapshot),
+		accountTxns:    make(map[diemtypes.AccountAddress][]*diemclient.Transaction),
+		events:         make(map[string][]*diemclient.Event),
+		currencies: []*diemclient.CurrencyInfo{
+			{Code: "XUS", ScalingFactor: 1_000_000, FractionalPart: 100, ToXdxExchangeRate: 1},
+			{Code: "XDX", ScalingFactor: 1_000_000, FractionalPart: 1000, ToXdxExchangeRate: 1},
+		},
+		failures: make(map[jsonrpc.Method][]Failure),
+	}
+}
+
+// Client creates `diemclient.Client` connecting to the server
+func (s *Server) Client(opts ...diemclient.Option) diemclient.Client {
+	return diemclient.NewWithJsonRpcClient(s.ChainID, s, opts...)
+}
+
+// AddAccount adds or replaces the account by its address at the current version. The account
+// is served by "get_account" at the latest version, changes made to it are visible at earlier
+// versions only after next transaction is committed.
+func (s *Server) AddAccount(account *diemclient.Account) {
+	address := diemtypes.MustMakeAccountAddress(account.Address)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts[address] = account
+	s.snapshot(address)
+}
+
+// GenAccount generates keys and adds the account with the address and authentication key of
+// the keys, other fields of the account are kept, e.g. role and balances. Returns the keys.
+func (s *Server) GenAccount(account *diemclient.Account) *diemkeys.Keys {
+	keys := diemkeys.MustGenKeys()
+	account.Address = keys.AccountAddress().Hex()
+	account.AuthenticationKey = keys.AuthKey().Hex()
+	s.AddAccount(account)
+	return keys
+}
+
+// Account returns the account of the address, nil if not found
+func (s *Server) Account(address diemtypes.AccountAddress) *diemclient.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accounts[address]
+}
+
+// SetFrozen sets the account frozen status of "get_account" and "get_account_state_with_proof"
+// methods responses, it does nothing if the account is not found.
+func (s *Server) SetFrozen(address diemtypes.AccountAddress, frozen bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if account, ok := s.accounts[address]; ok {
+		account.IsFrozen = frozen
+		s.snapshot(address)
+	}
+}
+
+// AddTransactions appends committed transactions, the version of transaction and its events
+// are assigned by the server. User transactions are indexed by sender and sequence number, and
+// events are appended to the event streams of their keys.
+func (s *Server) AddTransactions(txns ...*diemclient.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, txn := range txns {
+		s.commit(txn)
+	}
+}
+
+// AddEvents appends events to the event stream of the key, sequence number of the events are
+// assigned by the server.
+func (s *Server) AddEvents(key string, events ...*diemclient.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		event.Key = key
+		s.appendEvent(event)
+	}
+}
+
+// SetCurrencies sets response of "get_currencies" method
+func (s *Server) SetCurrencies(currencies ...*diemclient.CurrencyInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.currencies = currencies
+}
+
+// SetDualAttestationLimit sets dual attestation limit of "get_metadata" method response
+func (s *Server) SetDualAttestationLimit(limit uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dualAttestationLimit = limit
+}
+
+// HoldTransactions holds submitted transactions in mempool until `ExecutePending` is called,
+// or executes them immediately if hold is false.
+func (s *Server) HoldTransactions(hold bool) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hold = hold
+	return s
+}
+
+// WithExecutor sets function customizing executed transaction of submitted transaction, e.g.
+// vm status, gas used and events. Transactions are executed with "executed" vm status by
+// default.
+func (s *Server) WithExecutor(fn func(*diemtypes.SignedTransaction, *diemclient.Transaction)) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executor = fn
+	return s
+}
+
+// ExecutePending executes pending transactions which are next to the sequence number of their
+// senders, returns the executed transactions.
+func (s *Server) ExecutePending() []*diemclient.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.executePending()
+}
+
+// Submitted returns all transactions accepted by "submit" method
+func (s *Server) Submitted() []*diemtypes.SignedTransaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*diemtypes.SignedTransaction(nil), s.submitted...)
+}
+
+// Fail queues failures of the method, each call of the method consumes one failure in order.
+// Failures of empty method apply to any method.
+func (s *Server) Fail(method jsonrpc.Method, failures ...Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = append(s.failures[method], failures...)
+}
+
+// LedgerState returns current ledger state of the server
+func (s *Server) LedgerState() diemclient.LedgerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return diemclient.LedgerState{Version: s.version, TimestampUsec: s.timestampUsec}
+}
+
+// Call implements `jsonrpc.Client`
+func (s *Server) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	return s.CallWithContext(context.Background(), requests...)
+}
+
+// CallWithContext implements `jsonrpc.ContextClient`
+func (s *Server) CallWithContext(ctx context.Context, requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	ret := make(map[jsonrpc.RequestID]*jsonrpc.Response, len(requests))
+	for _, req := range requests {
+		failure := s.nextFailure(req.Method)
+		if failure.Delay > 0 {
+			select {
+			case <-time.After(failure.Delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if failure.NetworkError != nil {
+			return nil, failure.NetworkError
+		}
+		ret[req.ID] = s.handle(req, failure)
+	}
+	return ret, nil
+}
+
+// ServeHTTP implements `http.Handler`, it serves single and batch JSON-RPC requests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var requests []*jsonrpc.Request
+	batch := len(body) > 0 && body[0] == '['
+	if batch {
+		err = json.Unmarshal(body, &requests)
+	} else {
+		var req jsonrpc.Request
+		err = json.Unmarshal(body, &req)
+		requests = append(requests, &req)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resps, err := s.CallWithContext(r.Context(), requests...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	var ret interface{} = resps[requests[0].ID]
+	if batch {
+		list := make([]*jsonrpc.Response, 0, len(requests))
+		for _, req := range requests {
+			list = append(list, resps[req.ID])
+		}
+		ret = list
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ret)
+}
+
+func (s *Server) nextFailure(method jsonrpc.Method) Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range []jsonrpc.Method{method, ""} {
+		if failures := s.failures[m]; len(failures) > 0 {
+			s.failures[m] = failures[1:]
+			return failures[0]
+		}
+	}
+	return Failure{}
+}
+
+func (s *Server) handle(req *jsonrpc.Request, failure Failure) *jsonrpc.Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := req.ID
+	resp := &jsonrpc.Response{
+		JsonRpc:                 "2.0",
+		ID:                      &id,
+		DiemChainID:             s.ChainID,
+		DiemLedgerVersion:       s.version,
+		DiemLedgerTimestampusec: s.timestampUsec,
+	}
+	if failure.Stale {
+		resp.DiemLedgerVersion--
+		resp.DiemLedgerTimestampusec--
+	}
+	if failure.Error != nil {
+		resp.Error = failure.Error
+		return resp
+	}
+	result, err := s.dispatch(req)
+	if err != nil {
+		resp.Error = err
+		return resp
+	}
+	if result != nil {
+		bytes, jsonErr := json.Marshal(result)
+		if jsonErr != nil {
+			resp.Error = &jsonrpc.ResponseError{Code: diemclient.ErrCodeDefaultServerError, Message: jsonErr.Error()}
+			return resp
+		}
+		raw := json.RawMessage(bytes)
+		resp.Result = &raw
+	}
+	return resp
+}
+
+func (s *Server) dispatch(req *jsonrpc.Request) (interface{}, *jsonrpc.ResponseError) {
+	switch req.Method {
+	case diemclient.GetMetadata:
+		version := s.version
+		if len(req.Params) > 0 {
+			if err := params(req, &version); err != nil {
+				return nil, err
+			}
+			if version > s.version {
+				return nil, invalidParams(fmt.Errorf("version %d is greater than latest version %d", version, s.version))
+			}
+		}
+		metadata := &diemclient.Metadata{
+			Version:              version,
+			Timestamp:            s.timestampAt(version),
+			ChainId:              uint32(s.ChainID),
+			DualAttestationLimit: s.dualAttestationLimit,
+		}
+		return metadata, nil
+	case diemclient.GetCurrencies:
+		return s.currencies, nil
+	case diemclient.GetAccount:
+		var address string
+		version := s.version
+		var err *jsonrpc.ResponseError
+		if len(req.Params) > 1 {
+			err = params(req, &address, &version)
+		} else {
+			err = params(req, &address)
+		}
+		if err != nil {
+			return nil, err
+		}
+		addr, addrErr := diemtypes.MakeAccountAddress(address)
+		if addrErr != nil {
+			return nil, invalidParams(addrErr)
+		}
+		if version > s.version {
+			return nil, invalidParams(fmt.Errorf("version %d is greater than latest version %d", version, s.version))
+		}
+		if account := s.accountAt(addr, version); account != nil {
+			return account, nil
+		}
+		return nil, nil
+	case diemclient.GetAccountStateWithProof:
+		var address string
+		if err := params(req, &address); err != nil {
+			return nil, err
+		}
+		addr, err := diemtypes.MakeAccountAddress(address)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+		ret := &diemclient.AccountStateWithProof{Version: s.version}
+		if account, ok := s.accounts[addr]; ok {
+			ret.Blob = hex.EncodeToString(accountStateBlob(account))
+		}
+		return ret, nil
+	case diemclient.GetAccountTransaction:
+		var address string
+		var seq uint64
+		var includeEvents bool
+		if err := params(req, &address, &seq, &includeEvents); err != nil {
+			return nil, err
+		}
+		addr, err := diemtypes.MakeAccountAddress(address)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+		if txns := s.accountTxns[addr]; seq < uint64(len(txns)) && txns[seq] != nil {
+			return view(txns[seq], includeEvents), nil
+		}
+		return nil, nil
+	case diemclient.GetAccountTransactions:
+		var address string
+		var start, limit uint64
+		var includeEvents bool
+		if err := params(req, &address, &start, &limit, &includeEvents); err != nil {
+			return nil, err
+		}
+		addr, err := diemtypes.MakeAccountAddress(address)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+		ret := []*diemclient.Transaction{}
+		for _, txn := range pageTransactions(s.accountTxns[addr], start, limit) {
+			if txn != nil {
+				ret = append(ret, view(txn, includeEvents))
+			}
+		}
+		return ret, nil
+	case diemclient.GetTransactions:
+		var start, limit uint64
+		var includeEvents bool
+		if err := params(req, &start, &limit, &includeEvents); err != nil {
+			return nil, err
+		}
+		ret := []*diemclient.Transaction{}
+		for _, txn := range s.txns {
+			if txn.Version >= start && uint64(len(ret)) < limit {
+				ret = append(ret, view(txn, includeEvents))
+			}
+		}
+		return ret, nil
+	case diemclient.GetEvents:
+		var key string
+		var start, limit uint64
+		if err := params(req, &key, &start, &limit); err != nil {
+			return nil, err
+		}
+		ret := []*diemclient.Event{}
+		return append(ret, pageEvents(s.events[key], start, limit)...), nil
+	case diemclient.Submit:
+		var data string
+		if err := params(req, &data); err != nil {
+			return nil, err
+		}
+		return nil, s.submit(data)
+	}
+	return nil, &jsonrpc.ResponseError{
+		Code:    ErrCodeMethodNotFound,
+		Message: fmt.Sprintf("Method not found: %s", req.Method),
+	}
+}
+
+func (s *Server) submit(data string) *jsonrpc.ResponseError {
+	bytes, err := hex.DecodeString(data)
+	if err != nil {
+		return invalidParams(err)
+	}
+	txn, err := diemtypes.BcsDeserializeSignedTransaction(bytes)
+	if err != nil {
+		return &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmDeserializationError, Message: err.Error()}
+	}
+	account, ok := s.accounts[txn.RawTxn.Sender]
+	if !ok {
+		return vmValidationError("SENDING_ACCOUNT_DOES_NOT_EXIST")
+	}
+	if txn.RawTxn.SequenceNumber < account.SequenceNumber {
+		return vmValidationError("SEQUENCE_NUMBER_TOO_OLD")
+	}
+	if txn.RawTxn.ChainId != diemtypes.ChainId(s.ChainID) {
+		return vmValidationError("BAD_CHAIN_ID")
+	}
+	for i, pending := range s.pending {
+		if pending.RawTxn.Sender == txn.RawTxn.Sender && pending.RawTxn.SequenceNumber == txn.RawTxn.SequenceNumber {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			break
+		}
+	}
+	s.pending = append(s.pending, &txn)
+	s.submitted = append(s.submitted, &txn)
+	if !s.hold {
+		s.executePending()
+	}
+	return nil
+}
+
+func (s *Server) executePending() []*diemclient.Transaction {
+	var ret []*diemclient.Transaction
+	for executed := true; executed; {
+		executed = false
+		for i, txn := range s.pending {
+			account := s.accounts[txn.RawTxn.Sender]
+			if account == nil || txn.RawTxn.SequenceNumber != account.SequenceNumber {
+				continue
+			}
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			ret = append(ret, s.execute(txn))
+			executed = true
+			break
+		}
+	}
+	return ret
+}
+
+func (s *Server) execute(txn *diemtypes.SignedTransaction) *diemclient.Transaction {
+	raw := txn.RawTxn
+	ret := &diemclient.Transaction{
+		Hash: txn.TransactionHash(),
+		Transaction: &diemclient.TransactionData{
+			Type:                    "user",
+			Sender:                  raw.Sender.Hex(),
+			SequenceNumber:          raw.SequenceNumber,
+			ChainId:                 uint32(raw.ChainId),
+			MaxGasAmount:            raw.MaxGasAmount,
+			GasUnitPrice:            raw.GasUnitPrice,
+			GasCurrency:             raw.GasCurrencyCode,
+			ExpirationTimestampSecs: raw.ExpirationTimestampSecs,
+		},
+		VmStatus: &diemclient.VmStatus{Type: diemclient.VmStatusExecuted},
+	}
+	if payload, ok := raw.Payload.(*diemtypes.TransactionPayload__ScriptFunction); ok {
+		ret.Transaction.Script = scriptFunction(&payload.Value)
+	}
+	if s.executor != nil {
+		s.executor(txn, ret)
+	}
+	s.commit(ret)
+	return ret
+}
+
+// accountStateBlob returns account state blob of the account, which only has the
+// `AccountFreezing::FreezingBit` resource.
+func accountStateBlob(account *diemclient.Account) []byte {
+	bit := bcs.NewSerializer()
+	_ = bit.SerializeBool(account.IsFrozen)
+
+	state := bcs.NewSerializer()
+	_ = state.SerializeLen(1)
+	_ = state.SerializeBytes(accountstate.ResourcePath(accountstate.FreezingBitTag))
+	_ = state.SerializeBytes(bit.GetBytes())
+
+	blob := bcs.NewSerializer()
+	_ = blob.SerializeBytes(state.GetBytes())
+	return blob.GetBytes()
+}
+
+// scriptFunction returns the script view of the script function call, arguments are BCS bytes
+// without type information.
+func scriptFunction(fn *diemtypes.ScriptFunction) *diemclient.Script {
+	ret := &diemclient.Script{
+		Type:          "script_function",
+		ModuleAddress: fn.Module.Address.Hex(),
+		ModuleName:    string(fn.Module.Name),
+		FunctionName:  string(fn.Function),
+	}
+	for _, arg := range fn.Args {
+		ret.ArgumentsBcs = append(ret.ArgumentsBcs, hex.EncodeToString(arg))
+	}
+	return ret
+}
+
+// commit appends the transaction to the ledger and advances the ledger state, the sender
+// account sequence number is increased for user transaction. Account states are snapshotted at
+// the new version for "get_account" at a version.
+func (s *Server) commit(txn *diemclient.Transaction) {
+	s.version++
+	defer func() {
+		for address := range s.accounts {
+			s.snapshot(address)
+		}
+	}()
+	if now := uint64(time.Now().UnixNano() / 1000); now > s.timestampUsec {
+		s.timestampUsec = now
+	} else {
+		s.timestampUsec++
+	}
+	s.timestamps[s.version] = s.timestampUsec
+	txn.Version = s.version
+	s.txns = append(s.txns, txn)
+	for _, event := range txn.Events {
+		event.TransactionVersion = txn.Version
+		s.appendEvent(event)
+	}
+	if txn.Transaction == nil || txn.Transaction.Type != "user" {
+		return
+	}
+	sender, err := diemtypes.MakeAccountAddress(txn.Transaction.Sender)
+	if err != nil {
+		return
+	}
+	txns := s.accountTxns[sender]
+	for uint64(len(txns)) <= txn.Transaction.SequenceNumber {
+		txns = append(txns, nil)
+	}
+	txns[txn.Transaction.SequenceNumber] = txn
+	s.accountTxns[sender] = txns
+	if account, ok := s.accounts[sender]; ok && account.SequenceNumber <= txn.Transaction.SequenceNumber {
+		account.SequenceNumber = txn.Transaction.SequenceNumber + 1
+		account.Version = txn.Version
+	}
+}
+
+// snapshot records a copy of the account state at the current version, replacing the snapshot
+// recorded at the same version.
+func (s *Server) snapshot(address diemtypes.AccountAddress) {
+	snapshot := &accountSnapshot{
+		version: s.version,
+		account: proto.Clone(s.accounts[address]).(*diemclient.Account),
+	}
+	history := s.accountHistory[address]
+	if n := len(history); n > 0 && history[n-1].version == s.version {
+		history[n-1] = snapshot
+		return
+	}
+	s.accountHistory[address] = append(history, snapshot)
+}
+
+// accountAt returns the account state at the version: the live account at the latest version,
+// otherwise the last snapshot recorded at or before the version; nil if not found.
+func (s *Server) accountAt(address diemtypes.AccountAddress, version uint64) *diemclient.Account {
+	if version == s.version {
+		return s.accounts[address]
+	}
+	history := s.accountHistory[address]
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].version <= version {
+			return history[i].account
+		}
+	}
+	return nil
+}
+
+// timestampAt returns ledger timestamp of the version, which is the timestamp of the last
+// committed version at or before the version.
+func (s *Server) timestampAt(version uint64) uint64 {
+	for ; version > 0; version-- {
+		if timestamp, ok := s.timestamps[version]; ok {
+			return timestamp
+		}
+	}
+	return 0
+}
+
+func (s *Server) appendEvent(event *diemclient.Event) {
+	event.SequenceNumber = uint64(len(s.events[event.Key]))
+	s.events[event.Key] = append(s.events[event.Key], event)
+}
+
+// view returns the transaction for response, events are removed unless includeEvents is true
+func view(txn *diemclient.Transaction, includeEvents bool) *diemclient.Transaction {
+	if includeEvents {
+		return txn
+	}
+	return &diemclient.Transaction{
+		Version:     txn.Version,
+		Transaction: txn.Transaction,
+		Hash:        txn.Hash,
+		Bytes:       txn.Bytes,
+		VmStatus:    txn.VmStatus,
+		GasUsed:     txn.GasUsed,
+	}
+}
+
+func pageTransactions(list []*diemclient.Transaction, start, limit uint64) []*diemclient.Transaction {
+	if start >= uint64(len(list)) {
+		return nil
+	}
+	if limit < uint64(len(list))-start {
+		return list[start : start+limit]
+	}
+	return list[start:]
+}
+
+func pageEvents(list []*diemclient.Event, start, limit uint64) []*diemclient.Event {
+	if start >= uint64(len(list)) {
+		return nil
+	}
+	if limit < uint64(len(list))-start {
+		return list[start : start+limit]
+	}
+	return list[start:]
+}
+
+// params decodes request params into the targets by JSON, it accepts params of Go values from
+// `Call` and JSON values from `ServeHTTP`.
+func params(req *jsonrpc.Request, targets ...interface{}) *jsonrpc.ResponseError {
+	if len(req.Params) != len(targets) {
+		return invalidParams(fmt.Errorf("expected %d params, but got %d", len(targets), len(req.Params)))
+	}
+	for i, param := range req.Params {
+		bytes, err := json.Marshal(param)
+		if err == nil {
+			err = json.Unmarshal(bytes, targets[i])
+		}
+		if err != nil {
+			return invalidParams(fmt.Errorf("invalid param %d: %v", i, err))
+		}
+	}
+	return nil
+}
+
+func invalidParams(err error) *jsonrpc.ResponseError {
+	return &jsonrpc.ResponseError{Code: ErrCodeInvalidParams, Message: fmt.Sprintf("Invalid params: %v", err)}
+}
+
+func vmValidationError(status string) *jsonrpc.ResponseError {
+	return &jsonrpc.ResponseError{
+		Code:    diemclient.ErrCodeVmValidationError,
+		Message: fmt.Sprintf("Server error: VM Validation error: %s", status),
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclienttest_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccount(server *diemclienttest.Server) *diemkeys.Keys {
+	keys := diemkeys.MustGenKeys()
+	server.AddAccount(&diemclient.Account{
+		Address:           keys.AccountAddress().Hex(),
+		AuthenticationKey: keys.AuthKey().Hex(),
+		ReceivedEventsKey: "received-" + keys.AccountAddress().Hex(),
+		Balances:          []*diemclient.Amount{{Amount: 1000, Currency: "XUS"}},
+	})
+	return keys
+}
+
+func transfer(sender *diemkeys.Keys, receiver diemtypes.AccountAddress) *txnbuilder.Builder {
+	return txnbuilder.New(sender).Payload(stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+		diemtypes.Currency("XUS"), receiver, 100, nil, nil))
+}
+
+func TestServer(t *testing.T) {
+	server := diemclienttest.NewServer()
+	client := server.Client()
+	sender := newAccount(server)
+	receiver := newAccount(server)
+
+	account, err := client.GetAccount(sender.AccountAddress())
+	require.NoError(t, err)
+	assert.Equal(t, sender.AuthKey().Hex(), account.AuthenticationKey)
+	account, err = client.GetAccount(diemkeys.MustGenKeys().AccountAddress())
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	server.WithExecutor(func(signed *diemtypes.SignedTransaction, txn *diemclient.Transaction) {
+		txn.GasUsed = 10
+		txn.Events = []*diemclient.Event{{Key: "received-" + receiver.AccountAddress().Hex()}}
+	})
+	txn, err := transfer(sender, receiver.AccountAddress()).SignSubmitAndWait(client)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), txn.Version)
+	assert.Equal(t, uint64(10), txn.GasUsed)
+	assert.Equal(t, "PaymentScripts", txn.Transaction.Script.ModuleName)
+	assert.Equal(t, "peer_to_peer_with_metadata", txn.Transaction.Script.FunctionName)
+	assert.Equal(t, receiver.AccountAddress().Hex(), txn.Transaction.Script.ArgumentsBcs[0])
+	assert.Equal(t, uint64(1), server.Account(sender.AccountAddress()).SequenceNumber)
+	assert.Len(t, server.Submitted(), 1)
+
+	txns, err := client.GetAccountTransactions(sender.AccountAddress(), 0, 10, false)
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, txn.Hash, txns[0].Hash)
+	assert.Empty(t, txns[0].Events)
+	txns, err = client.GetTransactions(0, 10, true)
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Len(t, txns[0].Events, 1)
+
+	server.AddEvents("received-"+receiver.AccountAddress().Hex(), &diemclient.Event{})
+	events, err := client.GetEvents("received-"+receiver.AccountAddress().Hex(), 1, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(1), events[0].SequenceNumber)
+	events, err = client.GetEvents("unknown", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	server.SetDualAttestationLimit(1_000_000_000)
+	limit, err := client.(diemclient.ComplianceClient).GetDualAttestationLimit()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1_000_000_000), limit)
+	currencies, err := client.GetCurrencies()
+	require.NoError(t, err)
+	assert.Len(t, currencies, 2)
+
+	t.Run("sequence number too old", func(t *testing.T) {
+		err := client.Submit(signedHex(t, client, transfer(sender, receiver.AccountAddress()).SequenceNumber(0)))
+		submitErr, ok := err.(*diemclient.SubmitError)
+		require.True(t, ok, err)
+		assert.Equal(t, diemclient.ErrCodeVmValidationError, submitErr.Code)
+	})
+}
+
+func TestServerHoldTransactions(t *testing.T) {
+	server := diemclienttest.NewServer().HoldTransactions(true)
+	client := server.Client()
+	sender := newAccount(server)
+
+	require.NoError(t, client.Submit(signedHex(t, client, transfer(sender, sender.AccountAddress()).SequenceNumber(1))))
+	require.NoError(t, client.Submit(signedHex(t, client, transfer(sender, sender.AccountAddress()).SequenceNumber(0))))
+	txn, err := client.GetAccountTransaction(sender.AccountAddress(), 0, false)
+	require.NoError(t, err)
+	assert.Nil(t, txn)
+
+	executed := server.ExecutePending()
+	require.Len(t, executed, 2)
+	assert.Equal(t, uint64(0), executed[0].Transaction.SequenceNumber)
+	assert.Equal(t, uint64(1), executed[1].Transaction.SequenceNumber)
+	assert.Equal(t, uint64(2), server.Account(sender.AccountAddress()).SequenceNumber)
+	assert.Empty(t, server.ExecutePending())
+}
+
+func TestServerFailures(t *testing.T) {
+	server := diemclienttest.NewServer()
+	client := server.Client(diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+	sender := newAccount(server)
+
+	_, err := client.GetMetadata()
+	require.NoError(t, err)
+	server.Fail(diemclient.GetMetadata, diemclienttest.StaleResponse())
+	_, err = client.GetMetadata()
+	assert.IsType(t, &diemclient.StaleResponseError{}, err)
+
+	server.Fail(diemclient.GetAccount, diemclienttest.NetworkError(errors.New("connection reset")))
+	_, err = client.GetAccount(sender.AccountAddress())
+	assert.EqualError(t, err, "connection reset")
+	_, err = client.GetAccount(sender.AccountAddress())
+	assert.NoError(t, err)
+
+	server.Fail("", diemclienttest.Timeout(time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.(diemclient.ContextClient).GetAccountWithContext(ctx, sender.AccountAddress())
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	server.Fail(diemclient.Submit, diemclienttest.MempoolIsFull())
+	err = client.Submit(signedHex(t, client, transfer(sender, sender.AccountAddress())))
+	submitErr, ok := err.(*diemclient.SubmitError)
+	require.True(t, ok, err)
+	assert.Equal(t, diemclient.SubmitErrorMempoolFull, submitErr.Kind)
+	assert.Empty(t, server.Submitted())
+
+	t.Run("retry", func(t *testing.T) {
+		server.Fail(diemclient.GetAccount, diemclienttest.StaleResponse(), diemclienttest.StaleResponse())
+		account, err := server.Client().GetAccount(sender.AccountAddress())
+		require.NoError(t, err)
+		assert.NotNil(t, account)
+	})
+}
+
+func TestServerHTTP(t *testing.T) {
+	server := diemclienttest.NewServer()
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	client := diemclient.New(server.ChainID, httpServer.URL)
+	sender := newAccount(server)
+
+	txn, err := transfer(sender, sender.AccountAddress()).SignSubmitAndWait(client)
+	require.NoError(t, err)
+	assert.Equal(t, server.LedgerState().Version, txn.Version)
+
+	_, err = client.(diemclient.StateClient).GetStateProof(txn.Version)
+	assert.EqualError(t, err, "-32601 - Method not found: get_state_proof")
+}
+
+func signedHex(t *testing.T, client diemclient.Client, builder *txnbuilder.Builder) string {
+	txn, err := builder.Sign(client)
+	require.NoError(t, err)
+	return diemtypes.ToHex(txn)
+}
@@ -0,0 +1,196 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+)
+
+// Diem JSON-RPC server error codes
+const (
+	ErrCodeDefaultServerError         int32 = -32000
+	ErrCodeVmValidationError          int32 = -32001
+	ErrCodeVmVerificationError        int32 = -32002
+	ErrCodeVmInvariantViolationError  int32 = -32003
+	ErrCodeVmDeserializationError     int32 = -32004
+	ErrCodeVmExecutionError           int32 = -32005
+	ErrCodeVmUnknownError             int32 = -32006
+	ErrCodeMempoolInvalidSeqNumber    int32 = -32007
+	ErrCodeMempoolIsFull              int32 = -32008
+	ErrCodeMempoolTooManyTransactions int32 = -32009
+	ErrCodeMempoolInvalidUpdate       int32 = -32010
+	ErrCodeMempoolVmValidationError   int32 = -32011
+	ErrCodeMempoolUnknownError        int32 = -32012
+	minServerErrorCode                int32 = ErrCodeMempoolUnknownError
+	maxServerErrorCode                int32 = ErrCodeDefaultServerError
+)
+
+// Transaction vm status types
+const (
+	VmStatusOutOfGas           = "out_of_gas"
+	VmStatusMoveAbort          = "move_abort"
+	VmStatusExecutionFailure   = "execution_failure"
+	VmStatusMiscellaneousError = "miscellaneous_error"
+)
+
+// SubmitErrorKind classifies `SubmitError`
+type SubmitErrorKind string
+
+// List of submit error kinds
+const (
+	SubmitErrorUnknown              SubmitErrorKind = "unknown"
+	SubmitErrorMempoolFull          SubmitErrorKind = "mempool_full"
+	SubmitErrorSequenceNumberTooOld SubmitErrorKind = "sequence_number_too_old"
+	SubmitErrorSequenceNumberTooNew SubmitErrorKind = "sequence_number_too_new"
+	SubmitErrorInsufficientGas      SubmitErrorKind = "insufficient_gas"
+	SubmitErrorInvalidSignature     SubmitErrorKind = "invalid_signature"
+	SubmitErrorTransactionExpired   SubmitErrorKind = "transaction_expired"
+)
+
+// Sentinel submit errors for matching `SubmitError` kind by `errors.Is`
+var (
+	ErrMempoolFull          = &SubmitError{Kind: SubmitErrorMempoolFull}
+	ErrSequenceNumberTooOld = &SubmitError{Kind: SubmitErrorSequenceNumberTooOld}
+	ErrSequenceNumberTooNew = &SubmitError{Kind: SubmitErrorSequenceNumberTooNew}
+	ErrInsufficientGas      = &SubmitError{Kind: SubmitErrorInsufficientGas}
+	ErrInvalidSignature     = &SubmitError{Kind: SubmitErrorInvalidSignature}
+)
+
+// vm validation status codes of each submit error kind, in matching order
+var submitErrorStatuses = []struct {
+	kind     SubmitErrorKind
+	statuses []string
+}{
+	{SubmitErrorMempoolFull, []string{"MEMPOOL_IS_FULL"}},
+	{SubmitErrorSequenceNumberTooOld, []string{"SEQUENCE_NUMBER_TOO_OLD"}},
+	{SubmitErrorSequenceNumberTooNew, []string{"SEQUENCE_NUMBER_TOO_NEW"}},
+	{SubmitErrorInsufficientGas, []string{
+		"INSUFFICIENT_BALANCE_FOR_TRANSACTION_FEE",
+		"MAX_GAS_UNITS_BELOW_MIN_TRANSACTION_GAS_UNITS",
+		"MAX_GAS_UNITS_EXCEEDS_MAX_GAS_UNITS_BOUND",
+		"GAS_UNIT_PRICE_BELOW_MIN_BOUND",
+		"GAS_UNIT_PRICE_ABOVE_MAX_BOUND",
+	}},
+	{SubmitErrorInvalidSignature, []string{"INVALID_SIGNATURE", "INVALID_AUTH_KEY"}},
+	{SubmitErrorTransactionExpired, []string{"TRANSACTION_EXPIRED"}},
+}
+
+// SubmitError is error for transaction submission rejected by server VM validation or mempool.
+// It wraps the `*jsonrpc.ResponseError`, and exposes the response error code and data.
+type SubmitError struct {
+	Kind    SubmitErrorKind
+	Code    int32
+	Message string
+	Data    interface{}
+}
+
+// Error implements error interface
+func (e *SubmitError) Error() string {
+	return fmt.Sprintf("%s: %d - %s", e.Kind, e.Code, e.Message)
+}
+
+// Unwrap returns the JSON-RPC response error
+func (e *SubmitError) Unwrap() error {
+	return &jsonrpc.ResponseError{Code: e.Code, Message: e.Message, Data: e.Data}
+}
+
+// Is matches submit error with same kind, and `ErrTransactionExpired` for kind
+// `SubmitErrorTransactionExpired`
+func (e *SubmitError) Is(target error) bool {
+	if target == ErrTransactionExpired {
+		return e.Kind == SubmitErrorTransactionExpired
+	}
+	t, ok := target.(*SubmitError)
+	return ok && t.Kind == e.Kind
+}
+
+// ExecutionError is error for transaction executed with a failure vm status, e.g. move_abort.
+// It unwraps to `*InvalidTransactionError`, which was the error type of failed executions.
+type ExecutionError struct {
+	Transaction *Transaction
+}
+
+// Error implements error interface
+func (e *ExecutionError) Error() string {
+	return fmt.Sprintf("transaction execution failed: %v", e.Transaction.VmStatus)
+}
+
+// VmStatus returns vm status of the failed transaction
+func (e *ExecutionError) VmStatus() *VmStatus {
+	return e.Transaction.VmStatus
+}
+
+// Unwrap returns `*InvalidTransactionError` of the failed transaction
+func (e *ExecutionError) Unwrap() error {
+	return newInvalidTransactionError(e.Transaction, e.Error())
+}
+
+// AccountFrozenError is error for a payment of which the payer or payee account is frozen, the
+// transaction would fail validation or abort.
+type AccountFrozenError struct {
+	Address diemtypes.AccountAddress
+}
+
+// Error implements error interface
+func (e *AccountFrozenError) Error() string {
+	return fmt.Sprintf("account %s is frozen", e.Address.Hex())
+}
+
+// IsTransactionExpired returns true if the error is `ErrTransactionExpired` or submission failed by
+// VM validation status TRANSACTION_EXPIRED
+func IsTransactionExpired(err error) bool {
+	return errors.Is(err, ErrTransactionExpired)
+}
+
+// IsSequenceNumberTooOld returns true if the error is submission failed by VM validation
+// status SEQUENCE_NUMBER_TOO_OLD
+func IsSequenceNumberTooOld(err error) bool {
+	return errors.Is(err, ErrSequenceNumberTooOld)
+}
+
+// newSubmitError converts Diem server error response into `*SubmitError`, other errors
+// are returned as it is.
+func newSubmitError(err error) error {
+	rpcErr, ok := err.(*jsonrpc.ResponseError)
+	if !ok || rpcErr.Code < minServerErrorCode || rpcErr.Code > maxServerErrorCode {
+		return err
+	}
+	return NewSubmitError(rpcErr.Code, rpcErr.Message, rpcErr.Data)
+}
+
+// NewSubmitError creates `*SubmitError` of the given server error code, message and data; the
+// kind is classified by the code and VM validation status in the message. It is for clients of
+// other full node APIs, e.g. package `restclient`, to return the same submission errors.
+func NewSubmitError(code int32, message string, data interface{}) *SubmitError {
+	return &SubmitError{
+		Kind:    submitErrorKind(&jsonrpc.ResponseError{Code: code, Message: message, Data: data}),
+		Code:    code,
+		Message: message,
+		Data:    data,
+	}
+}
+
+func submitErrorKind(err *jsonrpc.ResponseError) SubmitErrorKind {
+	for _, kind := range submitErrorStatuses {
+		for _, status := range kind.statuses {
+			if strings.Contains(err.Message, status) {
+				return kind.kind
+			}
+		}
+	}
+	switch err.Code {
+	case ErrCodeMempoolIsFull:
+		return SubmitErrorMempoolFull
+	case ErrCodeMempoolInvalidSeqNumber:
+		// mempool rejects sequence number less than the account sequence number, sequence
+		// number too new is rejected by VM validation with status SEQUENCE_NUMBER_TOO_NEW
+		return SubmitErrorSequenceNumberTooOld
+	}
+	return SubmitErrorUnknown
+}
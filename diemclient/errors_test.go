@@ -0,0 +1,126 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      *jsonrpc.ResponseError
+		kind     diemclient.SubmitErrorKind
+		sentinel error
+	}{
+		{
+			name:     "mempool is full",
+			err:      &jsonrpc.ResponseError{Code: diemclient.ErrCodeMempoolIsFull, Message: "Server error: Mempool is full"},
+			kind:     diemclient.SubmitErrorMempoolFull,
+			sentinel: diemclient.ErrMempoolFull,
+		},
+		{
+			name:     "sequence number too old",
+			err:      &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmValidationError, Message: "Server error: VM Validation error: SEQUENCE_NUMBER_TOO_OLD"},
+			kind:     diemclient.SubmitErrorSequenceNumberTooOld,
+			sentinel: diemclient.ErrSequenceNumberTooOld,
+		},
+		{
+			name:     "sequence number too new",
+			err:      &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmValidationError, Message: "Server error: VM Validation error: SEQUENCE_NUMBER_TOO_NEW"},
+			kind:     diemclient.SubmitErrorSequenceNumberTooNew,
+			sentinel: diemclient.ErrSequenceNumberTooNew,
+		},
+		{
+			name:     "mempool invalid sequence number",
+			err:      &jsonrpc.ResponseError{Code: diemclient.ErrCodeMempoolInvalidSeqNumber, Message: "Server error: Mempool submission error: transaction sequence number is 3, current sequence number is 5"},
+			kind:     diemclient.SubmitErrorSequenceNumberTooOld,
+			sentinel: diemclient.ErrSequenceNumberTooOld,
+		},
+		{
+			name:     "mempool invalid sequence number too new",
+			err:      &jsonrpc.ResponseError{Code: diemclient.ErrCodeMempoolInvalidSeqNumber, Message: "Server error: SEQUENCE_NUMBER_TOO_NEW"},
+			kind:     diemclient.SubmitErrorSequenceNumberTooNew,
+			sentinel: diemclient.ErrSequenceNumberTooNew,
+		},
+		{
+			name:     "insufficient gas",
+			err:      &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmValidationError, Message: "Server error: VM Validation error: INSUFFICIENT_BALANCE_FOR_TRANSACTION_FEE"},
+			kind:     diemclient.SubmitErrorInsufficientGas,
+			sentinel: diemclient.ErrInsufficientGas,
+		},
+		{
+			name:     "invalid signature",
+			err:      &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmValidationError, Message: "Server error: VM Validation error: INVALID_SIGNATURE", Data: "data"},
+			kind:     diemclient.SubmitErrorInvalidSignature,
+			sentinel: diemclient.ErrInvalidSignature,
+		},
+		{
+			name:     "transaction expired",
+			err:      &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmValidationError, Message: "Server error: VM Validation error: TRANSACTION_EXPIRED"},
+			kind:     diemclient.SubmitErrorTransactionExpired,
+			sentinel: diemclient.ErrTransactionExpired,
+		},
+		{
+			name:     "multiple statuses are matched in order",
+			err:      &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmValidationError, Message: "Server error: VM Validation error: TRANSACTION_EXPIRED, SEQUENCE_NUMBER_TOO_OLD"},
+			kind:     diemclient.SubmitErrorSequenceNumberTooOld,
+			sentinel: diemclient.ErrSequenceNumberTooOld,
+		},
+		{
+			name: "unknown",
+			err:  &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmValidationError, Message: "Server error: VM Validation error: UNKNOWN_SCRIPT"},
+			kind: diemclient.SubmitErrorUnknown,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+				Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: {Error: tc.err}},
+			})
+			err := client.Submit("00")
+			var submitErr *diemclient.SubmitError
+			require.True(t, errors.As(err, &submitErr))
+			assert.Equal(t, tc.kind, submitErr.Kind)
+			assert.Equal(t, tc.err.Code, submitErr.Code)
+			assert.Equal(t, tc.err.Data, submitErr.Data)
+			if tc.sentinel != nil {
+				assert.True(t, errors.Is(err, tc.sentinel))
+			}
+
+			var rpcErr *jsonrpc.ResponseError
+			require.True(t, errors.As(err, &rpcErr))
+			assert.Equal(t, tc.err.Message, rpcErr.Message)
+		})
+	}
+
+	t.Run("non server error is not converted", func(t *testing.T) {
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+			Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: {Error: &jsonrpc.ResponseError{Code: -32602, Message: "Invalid params for method 'submit'"}}},
+		})
+		err := client.Submit("00")
+		_, ok := err.(*jsonrpc.ResponseError)
+		assert.True(t, ok)
+	})
+}
+
+func TestExecutionErrorUnwrap(t *testing.T) {
+	txn := &diemclient.Transaction{Version: 3, VmStatus: &diemclient.VmStatus{Type: "move_abort"}}
+	err := fmt.Errorf("wrap: %w", &diemclient.ExecutionError{Transaction: txn})
+
+	var invalid *diemclient.InvalidTransactionError
+	require.True(t, errors.As(err, &invalid))
+	assert.Equal(t, uint64(3), invalid.Transaction.Version)
+	assert.Equal(t, "move_abort", invalid.Transaction.VmStatus.Type)
+	assert.Contains(t, invalid.Error(), "transaction execution failed: ")
+}
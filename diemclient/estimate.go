@@ -0,0 +1,218 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// diemRootAddress is the account address of Diem root account, which publishes the on-chain
+// VM config
+var diemRootAddress = diemtypes.MustMakeAccountAddress("0000000000000000000000000a550c18")
+
+// Estimate defaults
+const (
+	// DefaultEstimateSamples is max number of sender recent transactions fetched for estimating
+	// gas used
+	DefaultEstimateSamples uint64 = 100
+	// DefaultGasMarginPercent is margin added to the estimated gas used for suggesting max gas amount
+	DefaultGasMarginPercent uint64 = 20
+)
+
+// Estimated vm statuses: transaction execution vm status types, and names of Diem VM validation
+// status codes
+const (
+	EstimateStatusExecuted                    = VmStatusExecuted
+	EstimateStatusSendingAccountDoesNotExist  = "SENDING_ACCOUNT_DOES_NOT_EXIST"
+	EstimateStatusSequenceNumberTooOld        = "SEQUENCE_NUMBER_TOO_OLD"
+	EstimateStatusTransactionExpired          = "TRANSACTION_EXPIRED"
+	EstimateStatusBadChainID                  = "BAD_CHAIN_ID"
+	EstimateStatusInsufficientBalanceForFee   = "INSUFFICIENT_BALANCE_FOR_TRANSACTION_FEE"
+	EstimateStatusOutOfGas                    = VmStatusOutOfGas
+	EstimateStatusExceededMaxTransactionSize  = "EXCEEDED_MAX_TRANSACTION_SIZE"
+	EstimateStatusMaxGasUnitsExceedsBound     = "MAX_GAS_UNITS_EXCEEDS_MAX_GAS_UNITS_BOUND"
+	EstimateStatusMaxGasUnitsBelowMinGasUnits = "MAX_GAS_UNITS_BELOW_MIN_TRANSACTION_GAS_UNITS"
+	EstimateStatusGasUnitPriceBelowMinBound   = "GAS_UNIT_PRICE_BELOW_MIN_BOUND"
+	EstimateStatusGasUnitPriceAboveMaxBound   = "GAS_UNIT_PRICE_ABOVE_MAX_BOUND"
+)
+
+// Estimate is result of `EstimateTransaction` and `SimulateTransaction`. It is not a dry run
+// result, the transaction is not executed; it may fail with a status or use more gas than
+// estimated.
+type Estimate struct {
+	// VmStatus is expected vm status of the transaction, `EstimateStatusExecuted` if the
+	// transaction is expected to pass validation and execution.
+	VmStatus string
+	// GasUsed is estimated gas used; it is `IntrinsicGas` if there is no sample.
+	GasUsed uint64
+	// IntrinsicGas is gas units charged for the transaction size by the on-chain gas schedule,
+	// the minimum gas used of the transaction; 0 if the on-chain VM config is not found.
+	IntrinsicGas uint64
+	// MaxGasAmount is suggested max gas amount: `GasUsed` plus `DefaultGasMarginPercent` margin;
+	// it is the given raw transaction max gas amount if there is no sample.
+	MaxGasAmount uint64
+	// Samples is number of executed transactions that gas used is estimated from.
+	Samples int
+}
+
+// EstimateTransaction estimates gas usage and vm status of given raw transaction before
+// submission from the sender account history; it does not execute the transaction.
+// Diem JSON-RPC full node has no dry-run API, hence the vm status is estimated by checking the
+// transaction against a subset of VM validation rules with the sender account state, and gas
+// used is estimated as max gas used of the sender's recent executed transactions running the
+// same script. Script function payloads and new scripts fall back to the intrinsic gas of the
+// on-chain gas schedule, which does not include the execution cost.
+func (c *client) EstimateTransaction(rawTxn *diemtypes.RawTransaction) (*Estimate, error) {
+	return c.EstimateTransactionWithContext(context.Background(), rawTxn)
+}
+
+// EstimateTransactionWithContext is `EstimateTransaction` with context
+func (c *client) EstimateTransactionWithContext(ctx context.Context, rawTxn *diemtypes.RawTransaction) (*Estimate, error) {
+	ret := &Estimate{VmStatus: EstimateStatusExecuted, MaxGasAmount: rawTxn.MaxGasAmount}
+	if byte(rawTxn.ChainId) != c.ChainID() {
+		ret.VmStatus = EstimateStatusBadChainID
+		return ret, nil
+	}
+	constants, err := c.getGasConstants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if constants != nil {
+		size := uint64(len(diemtypes.ToBCS(rawTxn)))
+		ret.IntrinsicGas = constants.IntrinsicGas(size)
+		if status := checkGas(rawTxn, size, ret.IntrinsicGas, constants); status != "" {
+			ret.VmStatus = status
+			return ret, nil
+		}
+	}
+	account, err := c.GetAccountWithContext(ctx, rawTxn.Sender)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		ret.VmStatus = EstimateStatusSendingAccountDoesNotExist
+		return ret, nil
+	}
+	if err := c.estimateGasUsed(ctx, rawTxn, account, ret); err != nil {
+		return nil, err
+	}
+	if ret.Samples == 0 {
+		ret.GasUsed = ret.IntrinsicGas
+	}
+	ret.VmStatus = c.estimateVmStatus(rawTxn, account, ret)
+	return ret, nil
+}
+
+// SimulateTransaction is `EstimateTransaction`: Diem JSON-RPC full node has no dry-run API, the
+// transaction is not executed.
+func (c *client) SimulateTransaction(rawTxn *diemtypes.RawTransaction) (*Estimate, error) {
+	return c.EstimateTransactionWithContext(context.Background(), rawTxn)
+}
+
+// SimulateTransactionWithContext is `SimulateTransaction` with context
+func (c *client) SimulateTransactionWithContext(ctx context.Context, rawTxn *diemtypes.RawTransaction) (*Estimate, error) {
+	return c.EstimateTransactionWithContext(ctx, rawTxn)
+}
+
+// getGasConstants returns gas constants of the on-chain VM config, cached for the on-chain
+// configs cache TTL; returns nil if the Diem root account or the VM config is not found.
+func (c *client) getGasConstants(ctx context.Context) (*accountstate.GasConstants, error) {
+	ret, err := c.gasConstants.get(ctx, c.configsTTL, func() (interface{}, bool, error) {
+		blob, err := c.GetAccountStateBlobWithContext(ctx, diemRootAddress)
+		if blob == nil {
+			return nil, false, err
+		}
+		state, err := accountstate.Decode(blob)
+		if err != nil {
+			return nil, false, err
+		}
+		config, err := state.DiemVMConfig()
+		if errors.Is(err, accountstate.ErrResourceNotFound) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return &config.GasConstants, true, nil
+	})
+	if ret == nil {
+		return nil, err
+	}
+	return ret.(*accountstate.GasConstants), nil
+}
+
+// checkGas returns the VM validation status of the transaction gas parameters checked against
+// the gas constants, returns empty string if the gas parameters are valid.
+func checkGas(rawTxn *diemtypes.RawTransaction, size, intrinsicGas uint64, constants *accountstate.GasConstants) string {
+	switch {
+	case size > constants.MaxTransactionSizeInBytes:
+		return EstimateStatusExceededMaxTransactionSize
+	case rawTxn.MaxGasAmount > constants.MaximumNumberOfGasUnits:
+		return EstimateStatusMaxGasUnitsExceedsBound
+	case rawTxn.MaxGasAmount < intrinsicGas:
+		return EstimateStatusMaxGasUnitsBelowMinGasUnits
+	case rawTxn.GasUnitPrice < constants.MinPricePerGasUnit:
+		return EstimateStatusGasUnitPriceBelowMinBound
+	case rawTxn.GasUnitPrice > constants.MaxPricePerGasUnit:
+		return EstimateStatusGasUnitPriceAboveMaxBound
+	}
+	return ""
+}
+
+func (c *client) estimateGasUsed(ctx context.Context, rawTxn *diemtypes.RawTransaction, account *Account, ret *Estimate) error {
+	script, ok := rawTxn.Payload.(*diemtypes.TransactionPayload__Script)
+	if !ok || account.SequenceNumber == 0 {
+		return nil
+	}
+	scriptHash := hex.EncodeToString(diemtypes.Hash(nil, script.Value.Code))
+	start := uint64(0)
+	if account.SequenceNumber > DefaultEstimateSamples {
+		start = account.SequenceNumber - DefaultEstimateSamples
+	}
+	txns, err := c.GetAccountTransactionsWithContext(ctx, rawTxn.Sender, start, DefaultEstimateSamples, false)
+	if err != nil {
+		return err
+	}
+	for _, txn := range txns {
+		if txn.Transaction == nil || txn.Transaction.ScriptHash != scriptHash ||
+			txn.VmStatus == nil || txn.VmStatus.Type != VmStatusExecuted {
+			continue
+		}
+		ret.Samples++
+		if txn.GasUsed > ret.GasUsed {
+			ret.GasUsed = txn.GasUsed
+		}
+	}
+	if ret.Samples > 0 {
+		ret.MaxGasAmount = ret.GasUsed + ret.GasUsed*DefaultGasMarginPercent/100
+	}
+	return nil
+}
+
+func (c *client) estimateVmStatus(rawTxn *diemtypes.RawTransaction, account *Account, est *Estimate) string {
+	if rawTxn.SequenceNumber < account.SequenceNumber {
+		return EstimateStatusSequenceNumberTooOld
+	}
+	if rawTxn.ExpirationTimestampSecs*1_000_000 <= c.LastResponseLedgerState().TimestampUsec {
+		return EstimateStatusTransactionExpired
+	}
+	var balance uint64
+	for _, b := range account.Balances {
+		if b.Currency == rawTxn.GasCurrencyCode {
+			balance = b.Amount
+		}
+	}
+	if rawTxn.GasUnitPrice > 0 && balance/rawTxn.GasUnitPrice < rawTxn.MaxGasAmount {
+		return EstimateStatusInsufficientBalanceForFee
+	}
+	if est.Samples > 0 && rawTxn.MaxGasAmount < est.GasUsed {
+		return EstimateStatusOutOfGas
+	}
+	return EstimateStatusExecuted
+}
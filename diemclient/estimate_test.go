@@ -0,0 +1,165 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accountHistoryStub serves get_account, get_account_transactions and
+// get_account_state_with_proof
+type accountHistoryStub struct {
+	account      string
+	transactions string
+	accountState string
+}
+
+func (s *accountHistoryStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	req := requests[0]
+	var resp jsonrpc.Response
+	var result string
+	switch req.Method {
+	case diemclient.GetAccount:
+		result = s.account
+	case diemclient.GetAccountTransactions:
+		result = s.transactions
+	case diemclient.GetAccountStateWithProof:
+		result = s.accountState
+	}
+	if result != "" {
+		raw := json.RawMessage(result)
+		resp.Result = &raw
+	}
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{req.ID: resp}}
+	return stub.Call(requests...)
+}
+
+func TestEstimateTransaction(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	script := stdlib.EncodePeerToPeerWithMetadataScript(testnet.XUS, keys.AccountAddress(), 1, nil, nil)
+	scriptHash := hex.EncodeToString(diemtypes.Hash(nil, script.Code))
+	rawTxn := func(seq, maxGas, gasUnitPrice uint64, expiration time.Duration) *diemtypes.RawTransaction {
+		txn, _ := diemsigner.NewRawTransactionAndSigningMsg(keys.AccountAddress(), seq,
+			&diemtypes.TransactionPayload__Script{Value: script},
+			maxGas, gasUnitPrice, "XUS", uint64(time.Now().Add(expiration).Unix()), testnet.ChainID)
+		return txn
+	}
+	stub := &accountHistoryStub{
+		account: `{"sequence_number": 3, "balances": [{"amount": 1000, "currency": "XUS"}]}`,
+		transactions: fmt.Sprintf(`[
+			{"transaction": {"script_hash": %q}, "gas_used": 400, "vm_status": {"type": "executed"}},
+			{"transaction": {"script_hash": %q}, "gas_used": 500, "vm_status": {"type": "executed"}},
+			{"transaction": {"script_hash": "other"}, "gas_used": 900, "vm_status": {"type": "executed"}}
+		]`, scriptHash, scriptHash),
+	}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub)
+
+	cases := []struct {
+		name     string
+		txn      *diemtypes.RawTransaction
+		vmStatus string
+	}{
+		{"executed", rawTxn(3, 1_000_000, 0, time.Minute), diemclient.EstimateStatusExecuted},
+		{"sequence number too old", rawTxn(2, 1_000_000, 0, time.Minute), diemclient.EstimateStatusSequenceNumberTooOld},
+		{"expired", rawTxn(3, 1_000_000, 0, -time.Minute), diemclient.EstimateStatusTransactionExpired},
+		{"insufficient balance for fee", rawTxn(3, 1_000, 2, time.Minute), diemclient.EstimateStatusInsufficientBalanceForFee},
+		{"out of gas", rawTxn(3, 450, 0, time.Minute), diemclient.EstimateStatusOutOfGas},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := client.(diemclient.EstimateClient).EstimateTransaction(tc.txn)
+			require.NoError(t, err)
+			assert.Equal(t, tc.vmStatus, ret.VmStatus)
+			assert.Equal(t, 2, ret.Samples)
+			assert.Equal(t, uint64(500), ret.GasUsed)
+			assert.Equal(t, uint64(600), ret.MaxGasAmount)
+		})
+	}
+
+	t.Run("account not found", func(t *testing.T) {
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &accountHistoryStub{})
+		ret, err := client.(diemclient.EstimateClient).EstimateTransaction(rawTxn(0, 1_000_000, 0, time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, diemclient.EstimateStatusSendingAccountDoesNotExist, ret.VmStatus)
+		assert.Equal(t, uint64(1_000_000), ret.MaxGasAmount)
+	})
+}
+
+// diemRootStateWithProof returns "get_account_state_with_proof" result of Diem root account state
+// with the VM config of given gas constants
+func diemRootStateWithProof(constants ...uint64) string {
+	config := bcs.NewSerializer()
+	_ = config.SerializeBytes(nil)
+	_ = config.SerializeBytes(nil)
+	for _, c := range constants {
+		_ = config.SerializeU64(c)
+	}
+	state := bcs.NewSerializer()
+	_ = state.SerializeLen(1)
+	_ = state.SerializeBytes(accountstate.ResourcePath(accountstate.DiemVMConfigTag))
+	_ = state.SerializeBytes(config.GetBytes())
+	blob := bcs.NewSerializer()
+	_ = blob.SerializeBytes(state.GetBytes())
+	return fmt.Sprintf(`{"version": 1, "blob": %q}`, hex.EncodeToString(blob.GetBytes()))
+}
+
+func TestSimulateTransactionWithGasSchedule(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	payload := stdlib.EncodePeerToPeerWithMetadataScriptFunction(testnet.XUS, keys.AccountAddress(), 1, nil, nil)
+	rawTxn := func(maxGas, gasUnitPrice uint64) *diemtypes.RawTransaction {
+		txn, _ := diemsigner.NewRawTransactionAndSigningMsg(keys.AccountAddress(), 3, payload,
+			maxGas, gasUnitPrice, "XUS", uint64(time.Now().Add(time.Minute).Unix()), testnet.ChainID)
+		return txn
+	}
+	stub := &accountHistoryStub{
+		account:      `{"sequence_number": 3, "balances": [{"amount": 1000000, "currency": "XUS"}]}`,
+		transactions: `[{"transaction": {"script_hash": ""}, "gas_used": 900, "vm_status": {"type": "executed"}}]`,
+		// min transaction gas units 600_000, max gas units 4_000_000, gas unit price 0 to 10,
+		// scaling factor 1000
+		accountState: diemRootStateWithProof(4, 4, 600_000, 600, 8_000, 4_000_000, 0, 10, 4096, 1000, 800),
+	}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub)
+
+	cases := []struct {
+		name     string
+		txn      *diemtypes.RawTransaction
+		vmStatus string
+	}{
+		{"executed", rawTxn(1_000_000, 0), diemclient.EstimateStatusExecuted},
+		{"exceeds max gas units", rawTxn(5_000_000, 0), diemclient.EstimateStatusMaxGasUnitsExceedsBound},
+		{"below min transaction gas units", rawTxn(500, 0), diemclient.EstimateStatusMaxGasUnitsBelowMinGasUnits},
+		{"gas unit price above max", rawTxn(1_000, 11), diemclient.EstimateStatusGasUnitPriceAboveMaxBound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := client.(diemclient.EstimateClient).SimulateTransaction(tc.txn)
+			require.NoError(t, err)
+			assert.Equal(t, tc.vmStatus, ret.VmStatus)
+			assert.Equal(t, uint64(600), ret.IntrinsicGas)
+		})
+	}
+
+	ret, err := client.(diemclient.EstimateClient).SimulateTransaction(rawTxn(1_000_000, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 0, ret.Samples)
+	assert.Equal(t, uint64(600), ret.GasUsed)
+	assert.Equal(t, uint64(1_000_000), ret.MaxGasAmount)
+}
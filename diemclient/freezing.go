@@ -0,0 +1,64 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// IsAccountFrozen returns true if the account is frozen by the Treasury Compliance account,
+// by reading the `AccountFreezing::FreezingBit` resource of the account state at the latest
+// ledger version. A frozen account can't send or receive payments.
+// Returns error if the account does not exist.
+func (c *client) IsAccountFrozen(address diemtypes.AccountAddress) (bool, error) {
+	return c.IsAccountFrozenWithContext(context.Background(), address)
+}
+
+// IsAccountFrozenWithContext is `IsAccountFrozen` with context
+func (c *client) IsAccountFrozenWithContext(ctx context.Context, address diemtypes.AccountAddress) (bool, error) {
+	blob, err := c.GetAccountStateBlobWithContext(ctx, address)
+	if err != nil {
+		return false, err
+	}
+	if blob == nil {
+		return false, fmt.Errorf("account %s not found", address.Hex())
+	}
+	state, err := accountstate.Decode(blob)
+	if err != nil {
+		return false, err
+	}
+	bit, err := state.FreezingBit()
+	if errors.Is(err, accountstate.ErrResourceNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return bit.IsFrozen, nil
+}
+
+// CheckNotFrozen returns `*AccountFrozenError` for the first frozen account of the addresses,
+// e.g. payer and payee of a payment before submitting it.
+// Returns `*UnsupportedClientError` if the client does not implement `ComplianceClient`.
+func CheckNotFrozen(ctx context.Context, c Client, addresses ...diemtypes.AccountAddress) error {
+	compliance, ok := c.(ComplianceClient)
+	if !ok {
+		return &UnsupportedClientError{Client: c, Interface: "ComplianceClient"}
+	}
+	for _, address := range addresses {
+		frozen, err := compliance.IsAccountFrozenWithContext(ctx, address)
+		if err != nil {
+			return err
+		}
+		if frozen {
+			return &AccountFrozenError{Address: address}
+		}
+	}
+	return nil
+}
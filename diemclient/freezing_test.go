@@ -0,0 +1,44 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAccountFrozen(t *testing.T) {
+	server := diemclienttest.NewServer()
+	client := server.Client()
+	account := diemkeys.MustGenKeys().AccountAddress()
+	other := diemkeys.MustGenKeys().AccountAddress()
+	server.AddAccount(&diemclient.Account{Address: account.Hex()})
+	server.AddAccount(&diemclient.Account{Address: other.Hex()})
+
+	frozen, err := client.(diemclient.ComplianceClient).IsAccountFrozen(account)
+	require.NoError(t, err)
+	assert.False(t, frozen)
+	require.NoError(t, diemclient.CheckNotFrozen(context.Background(), client, account, other))
+
+	server.SetFrozen(other, true)
+	frozen, err = client.(diemclient.ComplianceClient).IsAccountFrozen(other)
+	require.NoError(t, err)
+	assert.True(t, frozen)
+	err = diemclient.CheckNotFrozen(context.Background(), client, account, other)
+	var frozenErr *diemclient.AccountFrozenError
+	require.True(t, errors.As(err, &frozenErr))
+	assert.Equal(t, other, frozenErr.Address)
+	assert.EqualError(t, err, "account "+other.Hex()+" is frozen")
+
+	unknown := diemkeys.MustGenKeys().AccountAddress()
+	_, err = client.(diemclient.ComplianceClient).IsAccountFrozen(unknown)
+	assert.EqualError(t, err, "account "+unknown.Hex()+" not found")
+}
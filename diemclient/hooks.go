@@ -0,0 +1,105 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/diem/client-sdk-go/jsonrpc"
+)
+
+// Hooks are middleware-style callbacks of every JSON-RPC call made by the client, including
+// retries. They are for plugging in metrics, tracing or structured logging; any of them can
+// be nil.
+type Hooks struct {
+	// OnRequest is called before sending the request
+	OnRequest func(ctx context.Context, req *jsonrpc.Request)
+	// OnResponse is called after the call succeeded
+	OnResponse func(ctx context.Context, call *CallInfo)
+	// OnError is called after the call failed, including JSON-RPC error response, chain id
+	// mismatch and stale response
+	OnError func(ctx context.Context, call *CallInfo)
+	// OnWait is called after waiting for a transaction finished, successfully or not
+	OnWait func(ctx context.Context, wait *WaitInfo)
+}
+
+// WaitInfo describes a finished wait for transaction
+type WaitInfo struct {
+	Hash string
+	// Transaction is the executed transaction, it is nil if the transaction is not found
+	Transaction *Transaction
+	Duration    time.Duration
+	// Err is `*ExecutionError` if the transaction execution failed, `ErrTransactionExpired` if
+	// the transaction expired, or the error stopped waiting.
+	Err error
+}
+
+// CallInfo describes a finished JSON-RPC call
+type CallInfo struct {
+	Method  jsonrpc.Method
+	Request *jsonrpc.Request
+	// Response is nil when the call failed without response, e.g. network error
+	Response *jsonrpc.Response
+	Latency  time.Duration
+	// LedgerState is the response ledger state, it is zero if there is no response
+	LedgerState LedgerState
+	Err         error
+}
+
+// WithHooks adds `Hooks` to the client, hooks are called in the order they are added
+func WithHooks(hooks Hooks) Option {
+	return func(c *client) {
+		c.hooks = append(c.hooks, hooks)
+	}
+}
+
+func (c *client) beforeCall(ctx context.Context, req *jsonrpc.Request) {
+	for _, h := range c.hooks {
+		if h.OnRequest != nil {
+			h.OnRequest(ctx, req)
+		}
+	}
+}
+
+func (c *client) afterCall(ctx context.Context, req *jsonrpc.Request, resp *jsonrpc.Response, start time.Time, err error) {
+	if len(c.hooks) == 0 {
+		return
+	}
+	call := &CallInfo{
+		Method:   req.Method,
+		Request:  req,
+		Response: resp,
+		Latency:  time.Since(start),
+		Err:      err,
+	}
+	if resp != nil {
+		call.LedgerState = LedgerState{
+			TimestampUsec: resp.DiemLedgerTimestampusec,
+			Version:       resp.DiemLedgerVersion,
+		}
+	}
+	for _, h := range c.hooks {
+		if err == nil && h.OnResponse != nil {
+			h.OnResponse(ctx, call)
+		} else if err != nil && h.OnError != nil {
+			h.OnError(ctx, call)
+		}
+	}
+}
+
+func (c *client) afterWait(ctx context.Context, hash string, txn *Transaction, start time.Time, err error) {
+	if len(c.hooks) == 0 {
+		return
+	}
+	wait := &WaitInfo{Hash: hash, Transaction: txn, Duration: time.Since(start), Err: err}
+	if execErr, ok := err.(*ExecutionError); ok {
+		wait.Transaction = execErr.Transaction
+	}
+	for _, h := range c.hooks {
+		if h.OnWait != nil {
+			h.OnWait(ctx, wait)
+		}
+	}
+}
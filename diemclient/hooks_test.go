@@ -0,0 +1,86 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemlog"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	var requests []jsonrpc.Method
+	var responses, errors []*diemclient.CallInfo
+	hooks := diemclient.WithHooks(diemclient.Hooks{
+		OnRequest: func(ctx context.Context, req *jsonrpc.Request) {
+			requests = append(requests, req.Method)
+		},
+		OnResponse: func(ctx context.Context, call *diemclient.CallInfo) {
+			responses = append(responses, call)
+		},
+		OnError: func(ctx context.Context, call *diemclient.CallInfo) {
+			errors = append(errors, call)
+		},
+	})
+
+	t.Run("response", func(t *testing.T) {
+		requests, responses, errors = nil, nil, nil
+		result := json.RawMessage(`{"version": 1}`)
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+			Responses: map[jsonrpc.RequestID]jsonrpc.Response{
+				1: {Result: &result, DiemLedgerVersion: 42},
+			},
+		}, hooks)
+		_, err := client.GetMetadata()
+		require.NoError(t, err)
+		assert.Equal(t, []jsonrpc.Method{diemclient.GetMetadata}, requests)
+		require.Len(t, responses, 1)
+		assert.Empty(t, errors)
+		assert.Equal(t, diemclient.GetMetadata, responses[0].Method)
+		assert.Equal(t, uint64(42), responses[0].LedgerState.Version)
+		assert.NotNil(t, responses[0].Response)
+		assert.NoError(t, responses[0].Err)
+	})
+	t.Run("error", func(t *testing.T) {
+		requests, responses, errors = nil, nil, nil
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+			Responses: map[jsonrpc.RequestID]jsonrpc.Response{
+				1: {Error: &jsonrpc.ResponseError{Code: -32602, Message: "invalid params"}},
+			},
+		}, hooks, diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		err := client.Submit("00")
+		require.Error(t, err)
+		assert.Equal(t, []jsonrpc.Method{diemclient.Submit}, requests)
+		assert.Empty(t, responses)
+		require.Len(t, errors, 1)
+		assert.Equal(t, diemclient.Submit, errors[0].Method)
+		assert.EqualError(t, errors[0].Err, "-32602 - invalid params")
+	})
+}
+
+func TestWithLogger(t *testing.T) {
+	var msgs []string
+	var fields []diemlog.Field
+	logger := diemlog.LoggerFunc(func(level diemlog.Level, msg string, fs ...diemlog.Field) {
+		msgs = append(msgs, level.String()+" "+msg)
+		fields = append(fields, fs...)
+	})
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+		Responses: map[jsonrpc.RequestID]jsonrpc.Response{
+			1: {Error: &jsonrpc.ResponseError{Code: -32602, Message: "invalid params"}},
+		},
+	}, diemclient.WithLogger(logger), diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+	require.Error(t, client.Submit("00"))
+	assert.Equal(t, []string{"DEBUG JSON-RPC call", "WARN transaction submission failed"}, msgs)
+	assert.Equal(t, diemclient.Submit, diemlog.Map(fields)["method"])
+	assert.EqualError(t, diemlog.Map(fields)["error"].(error), "-32602 - invalid params")
+}
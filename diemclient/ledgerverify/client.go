@@ -0,0 +1,138 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgerverify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemclient"
+)
+
+// UnverifiedLedgerStateError is error for server response ledger state does not match the
+// verified ledger info of same version, or is newer than the server can prove.
+type UnverifiedLedgerStateError struct {
+	Server   diemclient.LedgerState
+	Verified diemclient.LedgerState
+}
+
+// Error implements error interface
+func (e *UnverifiedLedgerStateError) Error() string {
+	return fmt.Sprintf("unverified ledger state error: server response ledger %v, verified ledger %v", e.Server, e.Verified)
+}
+
+// Client is `diemclient.Client` ratchets trusted state by verifying server state proofs, and
+// verifies server response ledger versions and timestamps.
+// All `diemclient.Client` methods are delegated to the wrapped client without verification,
+// use `VerifyLedgerState` or `GetMetadata` for verified ledger versions and timestamps; response
+// data other than the ledger info is never verified: accumulator and sparse merkle proofs of
+// account states, transactions and events are not checked, a dishonest full node can still
+// return forged data with a verified ledger version and timestamp.
+// Create it by `NewWithStore` to persist the trusted state across process restarts.
+type Client struct {
+	diemclient.Client
+
+	mux     sync.Mutex
+	trusted *TrustedState
+	store   TrustedStateStore
+}
+
+// New creates `Client` with the given initial trusted state
+func New(client diemclient.Client, trusted *TrustedState) *Client {
+	return &Client{Client: client, trusted: trusted}
+}
+
+// NewWithStore creates `Client` resumes from the trusted state in the given
+// store, the given initial trusted state is used if the store is empty. The trusted state is
+// saved into the store after it is ratcheted by `Sync`.
+func NewWithStore(client diemclient.Client, store TrustedStateStore, initial *TrustedState) (*Client, error) {
+	trusted, err := store.Get()
+	if err != nil {
+		return nil, err
+	}
+	if trusted == nil || trusted.Version < initial.Version {
+		trusted = initial
+	}
+	return &Client{Client: client, trusted: trusted, store: store}, nil
+}
+
+// TrustedState returns current trusted state
+func (c *Client) TrustedState() *TrustedState {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.trusted
+}
+
+// Sync fetches state proof from the trusted state version, and ratchets the trusted state to
+// the verified latest ledger info.
+func (c *Client) Sync(ctx context.Context) (*TrustedState, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	client, ok := c.Client.(diemclient.StateClient)
+	if !ok {
+		return nil, &diemclient.UnsupportedClientError{Client: c.Client, Interface: "StateClient"}
+	}
+	proof, err := client.GetStateProofWithContext(ctx, c.trusted.Version)
+	if err != nil {
+		return nil, err
+	}
+	trusted, err := c.trusted.Verify(proof)
+	if err != nil {
+		return nil, err
+	}
+	if c.store != nil && (trusted.Version != c.trusted.Version || trusted.Epoch() != c.trusted.Epoch()) {
+		if err := c.store.Put(trusted); err != nil {
+			return nil, err
+		}
+	}
+	c.trusted = trusted
+	return trusted, nil
+}
+
+// VerifyLedgerState verifies given server response ledger state. It syncs the trusted state if
+// the given state is newer than the trusted version, then the state must have same timestamp
+// with verified ledger info of the same version.
+// States older than the trusted version can not be verified without the ledger history and are
+// accepted.
+// Returns `*UnverifiedLedgerStateError` if verification failed.
+func (c *Client) VerifyLedgerState(ctx context.Context, state diemclient.LedgerState) error {
+	trusted := c.TrustedState()
+	if state.Version > trusted.Version || trusted.LedgerInfo == nil {
+		var err error
+		if trusted, err = c.Sync(ctx); err != nil {
+			return err
+		}
+	}
+	if state.Version < trusted.Version {
+		return nil
+	}
+	verified := diemclient.LedgerState{
+		Version:       trusted.LedgerInfo.Version(),
+		TimestampUsec: trusted.LedgerInfo.TimestampUsec(),
+	}
+	if state != verified {
+		return &UnverifiedLedgerStateError{Server: state, Verified: verified}
+	}
+	return nil
+}
+
+// GetMetadata calls to "get_metadata" method and verifies the response ledger version and
+// timestamp
+func (c *Client) GetMetadata() (*diemclient.Metadata, error) {
+	return c.GetMetadataWithContext(context.Background())
+}
+
+// GetMetadataWithContext is `GetMetadata` with context
+func (c *Client) GetMetadataWithContext(ctx context.Context) (*diemclient.Metadata, error) {
+	ret, err := diemclient.AsContextClient(c.Client).GetMetadataWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = c.VerifyLedgerState(ctx, diemclient.LedgerState{Version: ret.Version, TimestampUsec: ret.Timestamp})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgerverify_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/ledgerverify"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	e1 := newEpoch(t, 1, 4)
+	stub := &nodeStub{version: 10, timestamp: 10_000, stateProof: e1.ledgerInfo(t, 10, nil, 3)}
+	client := ledgerverify.New(
+		diemclient.NewWithJsonRpcClient(testnet.ChainID, stub),
+		ledgerverify.NewTrustedState(0, e1.state()),
+	)
+
+	t.Run("verified metadata", func(t *testing.T) {
+		ret, err := client.GetMetadata()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), ret.Version)
+		assert.Equal(t, uint64(10), client.TrustedState().Version)
+	})
+	t.Run("server returns ledger state not matching the state proof", func(t *testing.T) {
+		stub.version = 11
+		stub.timestamp = 11_000
+		_, err := client.GetMetadata()
+		assert.IsType(t, &ledgerverify.UnverifiedLedgerStateError{}, err)
+	})
+	t.Run("server returns forged state proof", func(t *testing.T) {
+		stub.stateProof = newEpoch(t, 1, 4).ledgerInfo(t, 11, nil, 3)
+		err := client.VerifyLedgerState(context.Background(), diemclient.LedgerState{Version: 11, TimestampUsec: 11_000})
+		assert.IsType(t, &ledgerverify.VerificationError{}, err)
+		assert.Equal(t, uint64(10), client.TrustedState().Version)
+	})
+}
+
+// nodeStub responds "get_metadata" and "get_state_proof" requests
+type nodeStub struct {
+	version    uint64
+	timestamp  uint64
+	stateProof string
+}
+
+func (s *nodeStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	ret := make(map[jsonrpc.RequestID]*jsonrpc.Response)
+	for _, req := range requests {
+		var result string
+		switch req.Method {
+		case diemclient.GetMetadata:
+			result = fmt.Sprintf(`{"version": %d, "timestamp": %d, "chain_id": %d}`, s.version, s.timestamp, testnet.ChainID)
+		case diemclient.GetStateProof:
+			result = fmt.Sprintf(`{"ledger_info_with_signatures": %q}`, s.stateProof)
+		}
+		raw := json.RawMessage(result)
+		ret[req.ID] = &jsonrpc.Response{
+			JsonRpc:                 req.JsonRpc,
+			ID:                      &req.ID,
+			DiemChainID:             testnet.ChainID,
+			DiemLedgerVersion:       s.version,
+			DiemLedgerTimestampusec: s.timestamp,
+			Result:                  &raw,
+		}
+	}
+	return ret, nil
+}
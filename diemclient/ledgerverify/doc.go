@@ -0,0 +1,5 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides verifying ledger infos of an untrusted Diem full node by state proofs.
+package ledgerverify
@@ -0,0 +1,177 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgerverify
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// TrustedStateStore persists the latest verified trusted state, so that verification can resume
+// from it after restart instead of the initial trusted state.
+type TrustedStateStore interface {
+	// Get returns the stored trusted state, returns nil without error if not found
+	Get() (*TrustedState, error)
+	// Put saves the trusted state, overwrites the existing one
+	Put(state *TrustedState) error
+}
+
+// MemoryTrustedStateStore implements `TrustedStateStore` in memory, the state is lost after
+// restart.
+type MemoryTrustedStateStore struct {
+	mux   sync.RWMutex
+	state *TrustedState
+}
+
+// NewMemoryTrustedStateStore creates `MemoryTrustedStateStore`
+func NewMemoryTrustedStateStore() *MemoryTrustedStateStore {
+	return &MemoryTrustedStateStore{}
+}
+
+// Get implements `TrustedStateStore`
+func (s *MemoryTrustedStateStore) Get() (*TrustedState, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.state, nil
+}
+
+// Put implements `TrustedStateStore`
+func (s *MemoryTrustedStateStore) Put(state *TrustedState) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.state = state
+	return nil
+}
+
+// FileTrustedStateStore implements `TrustedStateStore` with a JSON file. The file is replaced
+// by renaming a temporary file written in the same directory, so that a crash does not leave a
+// partially written state.
+// The latest verified ledger info is not stored, `TrustedState.LedgerInfo` is nil after `Get`.
+type FileTrustedStateStore struct {
+	Path string
+	mux  sync.Mutex
+}
+
+// NewFileTrustedStateStore creates `FileTrustedStateStore` with given file path
+func NewFileTrustedStateStore(path string) *FileTrustedStateStore {
+	return &FileTrustedStateStore{Path: path}
+}
+
+// Get implements `TrustedStateStore`
+func (s *FileTrustedStateStore) Get() (*TrustedState, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	data, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var stored storedTrustedState
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode trusted state file %s failed: %v", s.Path, err)
+	}
+	return stored.toTrustedState()
+}
+
+// Put implements `TrustedStateStore`
+func (s *FileTrustedStateStore) Put(state *TrustedState) error {
+	data, err := json.MarshalIndent(newStoredTrustedState(state), "", "  ")
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+type storedTrustedState struct {
+	Version    uint64            `json:"version"`
+	Epoch      uint64            `json:"epoch"`
+	Validators []storedValidator `json:"validators"`
+	Waypoint   string            `json:"waypoint,omitempty"`
+}
+
+type storedValidator struct {
+	Address     string `json:"address"`
+	PublicKey   string `json:"public_key"`
+	VotingPower uint64 `json:"voting_power"`
+}
+
+func newStoredTrustedState(state *TrustedState) *storedTrustedState {
+	ret := storedTrustedState{Version: state.Version, Epoch: state.Epoch()}
+	if state.Waypoint != nil {
+		ret.Waypoint = state.Waypoint.String()
+	}
+	if state.EpochState == nil {
+		return &ret
+	}
+	for address, info := range state.EpochState.Verifier.Validators {
+		ret.Validators = append(ret.Validators, storedValidator{
+			Address:     address.Hex(),
+			PublicKey:   hex.EncodeToString(info.PublicKey),
+			VotingPower: info.VotingPower,
+		})
+	}
+	sort.Slice(ret.Validators, func(i, j int) bool {
+		return ret.Validators[i].Address < ret.Validators[j].Address
+	})
+	return &ret
+}
+
+func (s *storedTrustedState) toTrustedState() (*TrustedState, error) {
+	var waypoint *diemtypes.Waypoint
+	if s.Waypoint != "" {
+		var err error
+		if waypoint, err = diemtypes.ParseWaypoint(s.Waypoint); err != nil {
+			return nil, err
+		}
+		if s.Validators == nil {
+			ret := NewTrustedStateFromWaypoint(waypoint)
+			ret.Version = s.Version
+			return ret, nil
+		}
+	}
+	validators := make(map[diemtypes.AccountAddress]ValidatorConsensusInfo, len(s.Validators))
+	for _, v := range s.Validators {
+		address, err := diemtypes.MakeAccountAddress(v.Address)
+		if err != nil {
+			return nil, err
+		}
+		key, err := hex.DecodeString(v.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		validators[address] = ValidatorConsensusInfo{PublicKey: key, VotingPower: v.VotingPower}
+	}
+	ret := NewTrustedState(s.Version, &EpochState{
+		Epoch:    s.Epoch,
+		Verifier: NewValidatorVerifier(validators),
+	})
+	ret.Waypoint = waypoint
+	return ret, nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgerverify_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/ledgerverify"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedStateStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ledgerverify")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	stores := map[string]ledgerverify.TrustedStateStore{
+		"memory": ledgerverify.NewMemoryTrustedStateStore(),
+		"file":   ledgerverify.NewFileTrustedStateStore(filepath.Join(dir, "trusted_state.json")),
+	}
+	e1 := newEpoch(t, 1, 4)
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ret, err := store.Get()
+			require.NoError(t, err)
+			assert.Nil(t, ret)
+
+			require.NoError(t, store.Put(ledgerverify.NewTrustedState(10, e1.state())))
+			ret, err = store.Get()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(10), ret.Version)
+			assert.Equal(t, e1.state(), ret.EpochState)
+
+			w := waypoint(t, e1.ledgerInfo(t, 10, newEpoch(t, 2, 4), 3))
+			require.NoError(t, store.Put(ledgerverify.NewTrustedStateFromWaypoint(w)))
+			ret, err = store.Get()
+			require.NoError(t, err)
+			assert.Nil(t, ret.EpochState)
+			assert.Equal(t, w.String(), ret.Waypoint.String())
+		})
+	}
+
+	t.Run("file store with invalid file", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte("{"), 0600))
+		_, err := ledgerverify.NewFileTrustedStateStore(path).Get()
+		assert.Error(t, err)
+	})
+}
+
+func TestClientResumesFromStore(t *testing.T) {
+	e1 := newEpoch(t, 1, 4)
+	e2 := newEpoch(t, 2, 4)
+	store := ledgerverify.NewMemoryTrustedStateStore()
+	stub := &nodeStub{version: 20, timestamp: 20_000, stateProof: e2.ledgerInfo(t, 20, nil, 3)}
+
+	client, err := ledgerverify.NewWithStore(
+		diemclient.NewWithJsonRpcClient(testnet.ChainID, stub), store, ledgerverify.NewTrustedState(0, e1.state()))
+	require.NoError(t, err)
+	_, err = client.GetMetadata()
+	assert.IsType(t, &ledgerverify.VerificationError{}, err)
+
+	require.NoError(t, store.Put(ledgerverify.NewTrustedState(10, e2.state())))
+	client, err = ledgerverify.NewWithStore(
+		diemclient.NewWithJsonRpcClient(testnet.ChainID, stub), store, ledgerverify.NewTrustedState(0, e1.state()))
+	require.NoError(t, err)
+	_, err = client.GetMetadata()
+	require.NoError(t, err)
+
+	saved, err := store.Get()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), saved.Version)
+}
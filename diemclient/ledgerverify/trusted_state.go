@@ -0,0 +1,153 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgerverify
+
+import (
+	"bytes"
+
+	"github.com/diem/client-sdk-go/diemjsonrpctypes"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// TrustedState is the latest verified epoch state and ledger version
+type TrustedState struct {
+	Version uint64
+	// EpochState is nil for the trusted state created from a waypoint, before the epoch ending
+	// ledger info of the waypoint is verified.
+	EpochState *EpochState
+	// Waypoint is the waypoint the trusted state is created from, nil if it is created from
+	// an epoch state.
+	Waypoint *diemtypes.Waypoint
+	// LedgerInfo is the latest verified ledger info, it is nil for the initial trusted state
+	LedgerInfo *LedgerInfo
+}
+
+// NewTrustedState creates `TrustedState` trusts the given epoch validators from the given
+// version; the epoch state should come from a trusted source, e.g. the genesis or an epoch ending
+// ledger info verified out of band.
+func NewTrustedState(version uint64, epochState *EpochState) *TrustedState {
+	return &TrustedState{Version: version, EpochState: epochState}
+}
+
+// NewTrustedStateFromWaypoint creates `TrustedState` trusts the epoch ending ledger info
+// matches the given waypoint, e.g. a waypoint of the genesis or pinned by operators.
+// The first state proof verified must include the epoch ending ledger info of the waypoint
+// version.
+func NewTrustedStateFromWaypoint(waypoint *diemtypes.Waypoint) *TrustedState {
+	return &TrustedState{Version: waypoint.Version, Waypoint: waypoint}
+}
+
+// Epoch returns the trusted epoch, 0 if the epoch state is unknown yet
+func (s *TrustedState) Epoch() uint64 {
+	if s.EpochState == nil {
+		return 0
+	}
+	return s.EpochState.Epoch
+}
+
+// Verify verifies "get_state_proof" response requested with the trusted state version, and
+// returns the new trusted state ratcheted to the latest ledger info of the proof.
+//
+// The epoch change proof ledger infos are verified one by one from the trusted epoch, each must
+// be signed by the validators of its epoch and carries the validators of the next epoch; the
+// latest ledger info is verified by the validators of its epoch. For trusted state created from
+// a waypoint, the epoch ending ledger info of the waypoint version must match the waypoint,
+// and it is trusted without verifying signatures. The ledger consistency proof
+// is not verified, as the quorum signatures certify the ledger info.
+//
+// Returns `*VerificationError` if the proof is invalid.
+func (s *TrustedState) Verify(proof *diemjsonrpctypes.StateProof) (*TrustedState, error) {
+	latest, err := DecodeLedgerInfoWithSignatures(proof.LedgerInfoWithSignatures)
+	if err != nil {
+		return nil, err
+	}
+	if s.EpochState == nil && s.Waypoint == nil {
+		return nil, newVerificationError(latest, "trusted state has neither epoch state nor waypoint")
+	}
+	epochState := s.EpochState
+	var lastChange *LedgerInfoWithSignatures
+	if proof.EpochChangeProof != "" {
+		changes, err := DecodeEpochChangeProof(proof.EpochChangeProof)
+		if err != nil {
+			return nil, err
+		}
+		if epochState, lastChange, err = s.verifyEpochChanges(changes); err != nil {
+			return nil, err
+		}
+	}
+	return s.ratchet(epochState, lastChange, latest)
+}
+
+// verifyEpochChanges returns the epoch state after the changes and the last verified epoch
+// change ledger info
+func (s *TrustedState) verifyEpochChanges(proof *EpochChangeProof) (*EpochState, *LedgerInfoWithSignatures, error) {
+	epochState := s.EpochState
+	var last *LedgerInfoWithSignatures
+	for _, li := range proof.LedgerInfoWithSignatures {
+		if epochState == nil {
+			if li.LedgerInfo.Version() < s.Waypoint.Version {
+				continue
+			}
+			// the epoch ending ledger info of the waypoint is trusted without signatures
+			if err := s.verifyWaypoint(li); err != nil {
+				return nil, nil, err
+			}
+			epochState = li.LedgerInfo.CommitInfo.NextEpochState
+			last = li
+			continue
+		}
+		if li.LedgerInfo.Epoch() < epochState.Epoch {
+			// stale epoch change ledger info that trusted state already verified
+			continue
+		}
+		if li.LedgerInfo.Epoch() != epochState.Epoch {
+			return nil, nil, newVerificationError(li, "expected epoch %d", epochState.Epoch)
+		}
+		if !li.LedgerInfo.EndsEpoch() {
+			return nil, nil, newVerificationError(li, "epoch change ledger info has no next epoch state")
+		}
+		if err := epochState.Verifier.VerifySignatures(li); err != nil {
+			return nil, nil, err
+		}
+		epochState = li.LedgerInfo.CommitInfo.NextEpochState
+		last = li
+	}
+	return epochState, last, nil
+}
+
+func (s *TrustedState) ratchet(epochState *EpochState, lastChange, latest *LedgerInfoWithSignatures) (*TrustedState, error) {
+	li := &latest.LedgerInfo
+	if li.Version() < s.Version {
+		return nil, newVerificationError(latest, "version is older than trusted version %d", s.Version)
+	}
+	if epochState == nil {
+		if err := s.verifyWaypoint(latest); err != nil {
+			return nil, err
+		}
+		epochState = li.CommitInfo.NextEpochState
+	} else if li.Epoch() != epochState.Epoch {
+		// the latest ledger info can only be the last verified epoch change ledger info
+		if lastChange == nil || !bytes.Equal(lastChange.signingMsg, latest.signingMsg) {
+			return nil, newVerificationError(latest, "expected epoch %d", epochState.Epoch)
+		}
+	} else {
+		if err := epochState.Verifier.VerifySignatures(latest); err != nil {
+			return nil, err
+		}
+		if li.EndsEpoch() {
+			epochState = li.CommitInfo.NextEpochState
+		}
+	}
+	return &TrustedState{Version: li.Version(), EpochState: epochState, Waypoint: s.Waypoint, LedgerInfo: li}, nil
+}
+
+func (s *TrustedState) verifyWaypoint(li *LedgerInfoWithSignatures) error {
+	if !li.LedgerInfo.EndsEpoch() {
+		return newVerificationError(li, "expected epoch ending ledger info of waypoint %s", s.Waypoint)
+	}
+	if !s.Waypoint.Equal(li.LedgerInfo.Waypoint()) {
+		return newVerificationError(li, "ledger info does not match waypoint %s", s.Waypoint)
+	}
+	return nil
+}
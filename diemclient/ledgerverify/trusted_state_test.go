@@ -0,0 +1,213 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgerverify_test
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/ledgerverify"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyStateProof(t *testing.T) {
+	e1 := newEpoch(t, 1, 4)
+	e2 := newEpoch(t, 2, 4)
+	e3 := newEpoch(t, 3, 4)
+
+	t.Run("latest ledger info in trusted epoch", func(t *testing.T) {
+		trusted := ledgerverify.NewTrustedState(0, e1.state())
+		ret, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e1.ledgerInfo(t, 10, nil, 3),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), ret.Version)
+		assert.Equal(t, uint64(1), ret.Epoch())
+		assert.Equal(t, uint64(10_000), ret.LedgerInfo.TimestampUsec())
+	})
+	t.Run("epoch changes", func(t *testing.T) {
+		trusted := ledgerverify.NewTrustedState(0, e1.state())
+		ret, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e3.ledgerInfo(t, 30, nil, 3),
+			EpochChangeProof: epochChangeProof(t,
+				e1.ledgerInfo(t, 10, e2, 3),
+				e2.ledgerInfo(t, 20, e3, 3),
+			),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(30), ret.Version)
+		assert.Equal(t, uint64(3), ret.Epoch())
+	})
+	t.Run("latest ledger info is the epoch change ledger info", func(t *testing.T) {
+		trusted := ledgerverify.NewTrustedState(0, e1.state())
+		li := e1.ledgerInfo(t, 10, e2, 3)
+		ret, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: li,
+			EpochChangeProof:         epochChangeProof(t, li),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), ret.Version)
+		assert.Equal(t, uint64(2), ret.Epoch())
+	})
+	t.Run("not enough voting power", func(t *testing.T) {
+		trusted := ledgerverify.NewTrustedState(0, e1.state())
+		_, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e1.ledgerInfo(t, 10, nil, 2),
+		})
+		require.Error(t, err)
+		assert.IsType(t, &ledgerverify.VerificationError{}, err)
+		assert.Contains(t, err.Error(), "voting power 2 is less than quorum voting power 3")
+	})
+	t.Run("signed by unknown validators", func(t *testing.T) {
+		trusted := ledgerverify.NewTrustedState(0, e1.state())
+		_, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e2.ledgerInfo(t, 10, nil, 3),
+		})
+		assert.IsType(t, &ledgerverify.VerificationError{}, err)
+	})
+	t.Run("forged epoch change", func(t *testing.T) {
+		trusted := ledgerverify.NewTrustedState(0, e1.state())
+		_, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e3.ledgerInfo(t, 30, nil, 3),
+			EpochChangeProof:         epochChangeProof(t, e2.ledgerInfo(t, 20, e3, 3)),
+		})
+		assert.IsType(t, &ledgerverify.VerificationError{}, err)
+	})
+	t.Run("no epoch state nor waypoint", func(t *testing.T) {
+		trusted := &ledgerverify.TrustedState{}
+		_, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e2.ledgerInfo(t, 20, nil, 3),
+			EpochChangeProof:         epochChangeProof(t, e1.ledgerInfo(t, 10, e2, 3)),
+		})
+		assert.IsType(t, &ledgerverify.VerificationError{}, err)
+		_, err = trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e1.ledgerInfo(t, 10, e2, 3),
+		})
+		assert.IsType(t, &ledgerverify.VerificationError{}, err)
+	})
+	t.Run("version older than trusted version", func(t *testing.T) {
+		trusted := ledgerverify.NewTrustedState(20, e1.state())
+		_, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e1.ledgerInfo(t, 10, nil, 4),
+		})
+		assert.IsType(t, &ledgerverify.VerificationError{}, err)
+	})
+	t.Run("bootstrap from waypoint", func(t *testing.T) {
+		li := e1.ledgerInfo(t, 10, e2, 3)
+		trusted := ledgerverify.NewTrustedStateFromWaypoint(waypoint(t, li))
+		assert.Equal(t, uint64(0), trusted.Epoch())
+		ret, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e2.ledgerInfo(t, 20, nil, 3),
+			EpochChangeProof:         epochChangeProof(t, li),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(20), ret.Version)
+		assert.Equal(t, uint64(2), ret.Epoch())
+	})
+	t.Run("ledger info does not match waypoint", func(t *testing.T) {
+		trusted := ledgerverify.NewTrustedStateFromWaypoint(waypoint(t, e1.ledgerInfo(t, 10, e2, 3)))
+		_, err := trusted.Verify(&diemclient.StateProof{
+			LedgerInfoWithSignatures: e3.ledgerInfo(t, 20, nil, 3),
+			EpochChangeProof:         epochChangeProof(t, e1.ledgerInfo(t, 10, e3, 3)),
+		})
+		assert.IsType(t, &ledgerverify.VerificationError{}, err)
+		assert.Contains(t, err.Error(), "does not match waypoint")
+	})
+	t.Run("invalid bcs", func(t *testing.T) {
+		trusted := ledgerverify.NewTrustedState(0, e1.state())
+		_, err := trusted.Verify(&diemclient.StateProof{LedgerInfoWithSignatures: "00ff"})
+		assert.Error(t, err)
+	})
+}
+
+type validator struct {
+	address diemtypes.AccountAddress
+	key     ed25519.PrivateKey
+}
+
+type epoch struct {
+	number     uint64
+	validators []validator
+}
+
+func newEpoch(t *testing.T, number uint64, size int) *epoch {
+	ret := epoch{number: number}
+	for i := 0; i < size; i++ {
+		_, key, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		address := diemtypes.AccountAddress{byte(number), byte(i)}
+		ret.validators = append(ret.validators, validator{address, key})
+	}
+	return &ret
+}
+
+func (e *epoch) state() *ledgerverify.EpochState {
+	validators := make(map[diemtypes.AccountAddress]ledgerverify.ValidatorConsensusInfo)
+	for _, v := range e.validators {
+		validators[v.address] = ledgerverify.ValidatorConsensusInfo{
+			PublicKey:   v.key.Public().(ed25519.PublicKey),
+			VotingPower: 1,
+		}
+	}
+	return &ledgerverify.EpochState{Epoch: e.number, Verifier: ledgerverify.NewValidatorVerifier(validators)}
+}
+
+// ledgerInfo returns hex-encoded LedgerInfoWithSignatures signed by the first given number of
+// validators
+func (e *epoch) ledgerInfo(t *testing.T, version uint64, next *epoch, signers int) string {
+	s := bcs.NewSerializer()
+	require.NoError(t, s.SerializeU64(e.number))
+	require.NoError(t, s.SerializeU64(version))
+	require.NoError(t, s.SerializeBytes(make([]byte, 32)))
+	require.NoError(t, s.SerializeBytes(make([]byte, 32)))
+	require.NoError(t, s.SerializeU64(version))
+	require.NoError(t, s.SerializeU64(version*1000))
+	require.NoError(t, s.SerializeOptionTag(next != nil))
+	if next != nil {
+		require.NoError(t, s.SerializeU64(next.number))
+		require.NoError(t, s.SerializeLen(uint64(len(next.validators))))
+		for _, v := range next.validators {
+			require.NoError(t, v.address.Serialize(s))
+			require.NoError(t, s.SerializeBytes(v.key.Public().(ed25519.PublicKey)))
+			require.NoError(t, s.SerializeU64(1))
+		}
+	}
+	require.NoError(t, s.SerializeBytes(make([]byte, 32)))
+	li := s.GetBytes()
+	msg := append(diemtypes.HashPrefix(ledgerverify.LedgerInfoHashPrefix), li...)
+
+	sigs := bcs.NewSerializer()
+	require.NoError(t, sigs.SerializeLen(uint64(signers)))
+	for _, v := range e.validators[:signers] {
+		require.NoError(t, v.address.Serialize(sigs))
+		require.NoError(t, sigs.SerializeBytes(ed25519.Sign(v.key, msg)))
+	}
+	ret := append([]byte{0}, li...)
+	return hex.EncodeToString(append(ret, sigs.GetBytes()...))
+}
+
+func waypoint(t *testing.T, ledgerInfo string) *diemtypes.Waypoint {
+	li, err := ledgerverify.DecodeLedgerInfoWithSignatures(ledgerInfo)
+	require.NoError(t, err)
+	ret, err := diemtypes.ParseWaypoint(li.LedgerInfo.Waypoint().String())
+	require.NoError(t, err)
+	return ret
+}
+
+func epochChangeProof(t *testing.T, ledgerInfos ...string) string {
+	s := bcs.NewSerializer()
+	require.NoError(t, s.SerializeLen(uint64(len(ledgerInfos))))
+	ret := s.GetBytes()
+	for _, li := range ledgerInfos {
+		bytes, err := hex.DecodeString(li)
+		require.NoError(t, err)
+		ret = append(ret, bytes...)
+	}
+	return hex.EncodeToString(append(ret, 0))
+}
@@ -0,0 +1,285 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgerverify
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// LedgerInfoHashPrefix is the Diem hashing prefix type name of `LedgerInfo`, the validators
+// sign `HashPrefix("LedgerInfo") | BCS(LedgerInfo)`.
+const LedgerInfoHashPrefix = "LedgerInfo"
+
+// BlockInfo is the committed block of a ledger info
+type BlockInfo struct {
+	Epoch           uint64
+	Round           uint64
+	ID              []byte
+	ExecutedStateID []byte
+	Version         uint64
+	TimestampUsec   uint64
+	// NextEpochState is not nil if the block ends the epoch
+	NextEpochState *EpochState
+}
+
+// LedgerInfo is the ledger state certified by validators
+type LedgerInfo struct {
+	CommitInfo        BlockInfo
+	ConsensusDataHash []byte
+}
+
+// Epoch returns epoch of the ledger info
+func (li *LedgerInfo) Epoch() uint64 {
+	return li.CommitInfo.Epoch
+}
+
+// Version returns ledger version of the ledger info
+func (li *LedgerInfo) Version() uint64 {
+	return li.CommitInfo.Version
+}
+
+// TimestampUsec returns ledger timestamp in microseconds of the ledger info
+func (li *LedgerInfo) TimestampUsec() uint64 {
+	return li.CommitInfo.TimestampUsec
+}
+
+// EndsEpoch returns true if the ledger info is the last one of its epoch
+func (li *LedgerInfo) EndsEpoch() bool {
+	return li.CommitInfo.NextEpochState != nil
+}
+
+// Waypoint returns waypoint of the ledger info.
+// It panics if bcs serialization failed.
+func (li *LedgerInfo) Waypoint() *diemtypes.Waypoint {
+	converter, err := li.serializeWaypointConverter()
+	if err != nil {
+		panic(fmt.Sprintf("bcs serialize failed: %v", err.Error()))
+	}
+	return diemtypes.NewWaypoint(li.Version(), converter)
+}
+
+func (li *LedgerInfo) serializeWaypointConverter() ([]byte, error) {
+	s := bcs.NewSerializer()
+	info := &li.CommitInfo
+	if err := s.SerializeU64(info.Epoch); err != nil {
+		return nil, err
+	}
+	if err := s.SerializeBytes(info.ExecutedStateID); err != nil {
+		return nil, err
+	}
+	if err := s.SerializeU64(info.Version); err != nil {
+		return nil, err
+	}
+	if err := s.SerializeU64(info.TimestampUsec); err != nil {
+		return nil, err
+	}
+	if err := s.SerializeOptionTag(info.NextEpochState != nil); err != nil {
+		return nil, err
+	}
+	if info.NextEpochState != nil {
+		if err := serializeEpochState(s, info.NextEpochState); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetBytes(), nil
+}
+
+// LedgerInfoWithSignatures is `LedgerInfo` with validator signatures
+type LedgerInfoWithSignatures struct {
+	LedgerInfo LedgerInfo
+	Signatures map[diemtypes.AccountAddress][]byte
+
+	// signingMsg is the message validators signed, it is kept from the deserialized bytes, so
+	// that the signatures are verified against the exact bytes server returned.
+	signingMsg []byte
+}
+
+// EpochState is the validator set of an epoch
+type EpochState struct {
+	Epoch    uint64
+	Verifier *ValidatorVerifier
+}
+
+// EpochChangeProof is list of epoch ending ledger infos
+type EpochChangeProof struct {
+	LedgerInfoWithSignatures []*LedgerInfoWithSignatures
+	More                     bool
+}
+
+// DecodeLedgerInfoWithSignatures decodes hex-encoded BCS bytes of `LedgerInfoWithSignatures`
+func DecodeLedgerInfoWithSignatures(hexStr string) (*LedgerInfoWithSignatures, error) {
+	var ret *LedgerInfoWithSignatures
+	err := decode(hexStr, func(d serde.Deserializer, input []byte) (err error) {
+		ret, err = deserializeLedgerInfoWithSignatures(d, input)
+		return err
+	})
+	return ret, err
+}
+
+// DecodeEpochChangeProof decodes hex-encoded BCS bytes of `EpochChangeProof`
+func DecodeEpochChangeProof(hexStr string) (*EpochChangeProof, error) {
+	var ret EpochChangeProof
+	err := decode(hexStr, func(d serde.Deserializer, input []byte) error {
+		length, err := d.DeserializeLen()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < length; i++ {
+			li, err := deserializeLedgerInfoWithSignatures(d, input)
+			if err != nil {
+				return err
+			}
+			ret.LedgerInfoWithSignatures = append(ret.LedgerInfoWithSignatures, li)
+		}
+		ret.More, err = d.DeserializeBool()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func decode(hexStr string, fn func(serde.Deserializer, []byte) error) error {
+	input, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return err
+	}
+	d := bcs.NewDeserializer(input)
+	if err = fn(d, input); err != nil {
+		return err
+	}
+	if d.GetBufferOffset() < uint64(len(input)) {
+		return fmt.Errorf("Some input bytes were not read")
+	}
+	return nil
+}
+
+func deserializeLedgerInfoWithSignatures(d serde.Deserializer, input []byte) (*LedgerInfoWithSignatures, error) {
+	index, err := d.DeserializeVariantIndex()
+	if err != nil {
+		return nil, err
+	}
+	if index != 0 {
+		return nil, fmt.Errorf("Unknown variant index for LedgerInfoWithSignatures: %d", index)
+	}
+	var ret LedgerInfoWithSignatures
+	start := d.GetBufferOffset()
+	if ret.LedgerInfo, err = deserializeLedgerInfo(d); err != nil {
+		return nil, err
+	}
+	ret.signingMsg = append(diemtypes.HashPrefix(LedgerInfoHashPrefix), input[start:d.GetBufferOffset()]...)
+
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	ret.Signatures = make(map[diemtypes.AccountAddress][]byte, length)
+	for i := uint64(0); i < length; i++ {
+		address, err := diemtypes.DeserializeAccountAddress(d)
+		if err != nil {
+			return nil, err
+		}
+		if ret.Signatures[address], err = d.DeserializeBytes(); err != nil {
+			return nil, err
+		}
+	}
+	return &ret, nil
+}
+
+func deserializeLedgerInfo(d serde.Deserializer) (ret LedgerInfo, err error) {
+	info := &ret.CommitInfo
+	if info.Epoch, err = d.DeserializeU64(); err != nil {
+		return
+	}
+	if info.Round, err = d.DeserializeU64(); err != nil {
+		return
+	}
+	if info.ID, err = d.DeserializeBytes(); err != nil {
+		return
+	}
+	if info.ExecutedStateID, err = d.DeserializeBytes(); err != nil {
+		return
+	}
+	if info.Version, err = d.DeserializeU64(); err != nil {
+		return
+	}
+	if info.TimestampUsec, err = d.DeserializeU64(); err != nil {
+		return
+	}
+	hasNext, err := d.DeserializeOptionTag()
+	if err != nil {
+		return
+	}
+	if hasNext {
+		if info.NextEpochState, err = deserializeEpochState(d); err != nil {
+			return
+		}
+	}
+	ret.ConsensusDataHash, err = d.DeserializeBytes()
+	return
+}
+
+func deserializeEpochState(d serde.Deserializer) (*EpochState, error) {
+	epoch, err := d.DeserializeU64()
+	if err != nil {
+		return nil, err
+	}
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	validators := make(map[diemtypes.AccountAddress]ValidatorConsensusInfo, length)
+	for i := uint64(0); i < length; i++ {
+		address, err := diemtypes.DeserializeAccountAddress(d)
+		if err != nil {
+			return nil, err
+		}
+		var info ValidatorConsensusInfo
+		if info.PublicKey, err = d.DeserializeBytes(); err != nil {
+			return nil, err
+		}
+		if info.VotingPower, err = d.DeserializeU64(); err != nil {
+			return nil, err
+		}
+		validators[address] = info
+	}
+	return &EpochState{Epoch: epoch, Verifier: NewValidatorVerifier(validators)}, nil
+}
+
+func serializeEpochState(s serde.Serializer, state *EpochState) error {
+	if err := s.SerializeU64(state.Epoch); err != nil {
+		return err
+	}
+	addresses := make([]diemtypes.AccountAddress, 0, len(state.Verifier.Validators))
+	for address := range state.Verifier.Validators {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	if err := s.SerializeLen(uint64(len(addresses))); err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		info := state.Verifier.Validators[address]
+		if err := address.Serialize(s); err != nil {
+			return err
+		}
+		if err := s.SerializeBytes(info.PublicKey); err != nil {
+			return err
+		}
+		if err := s.SerializeU64(info.VotingPower); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgerverify
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// ValidatorConsensusInfo is validator consensus public key and voting power
+type ValidatorConsensusInfo struct {
+	PublicKey   ed25519.PublicKey
+	VotingPower uint64
+}
+
+// ValidatorVerifier verifies ledger info signatures of a validator set
+type ValidatorVerifier struct {
+	Validators map[diemtypes.AccountAddress]ValidatorConsensusInfo
+
+	quorumVotingPower uint64
+}
+
+// NewValidatorVerifier creates `ValidatorVerifier`, quorum voting power is more than 2/3 of the
+// total voting power.
+func NewValidatorVerifier(validators map[diemtypes.AccountAddress]ValidatorConsensusInfo) *ValidatorVerifier {
+	var total uint64
+	for _, v := range validators {
+		total += v.VotingPower
+	}
+	quorum := uint64(0)
+	if len(validators) > 0 {
+		quorum = total*2/3 + 1
+	}
+	return &ValidatorVerifier{Validators: validators, quorumVotingPower: quorum}
+}
+
+// QuorumVotingPower returns minimum voting power required for a ledger info to be certified
+func (v *ValidatorVerifier) QuorumVotingPower() uint64 {
+	return v.quorumVotingPower
+}
+
+// VerifySignatures verifies ledger info signatures are signed by the validators with voting
+// power reaches quorum.
+// Returns `*VerificationError` if a signature is from unknown validator, a signature is invalid
+// or the voting power is not enough.
+func (v *ValidatorVerifier) VerifySignatures(li *LedgerInfoWithSignatures) error {
+	var power uint64
+	for address, signature := range li.Signatures {
+		info, ok := v.Validators[address]
+		if !ok {
+			return newVerificationError(li, "unknown validator %s", address.Hex())
+		}
+		if !ed25519.Verify(info.PublicKey, li.signingMsg, signature) {
+			return newVerificationError(li, "invalid signature of validator %s", address.Hex())
+		}
+		power += info.VotingPower
+	}
+	if power < v.quorumVotingPower {
+		return newVerificationError(li, "voting power %d is less than quorum voting power %d",
+			power, v.quorumVotingPower)
+	}
+	return nil
+}
+
+// VerificationError is error for state proof failed verification
+type VerificationError struct {
+	Epoch   uint64
+	Version uint64
+	Reason  string
+}
+
+// Error implements error interface
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("verify ledger info (epoch: %d, version: %d) failed: %s", e.Epoch, e.Version, e.Reason)
+}
+
+func newVerificationError(li *LedgerInfoWithSignatures, format string, args ...interface{}) *VerificationError {
+	return &VerificationError{
+		Epoch:   li.LedgerInfo.Epoch(),
+		Version: li.LedgerInfo.Version(),
+		Reason:  fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemlog"
+	"github.com/diem/client-sdk-go/jsonrpc"
+)
+
+// Default chain regression tolerance used by `WithChainResetHandler`: server response ledger
+// state is allowed to be behind client known state within the tolerance, and it is considered
+// as stale response.
+const (
+	DefaultChainRegressionVersionTolerance uint64 = 10_000
+	DefaultChainRegressionTimeTolerance           = 5 * time.Minute
+)
+
+// DefaultChainResetConfirmations is the default number of consecutive responses that must
+// consistently signal a chain reset before the reset is declared, see `WithChainResetHandler`.
+const DefaultChainResetConfirmations = 3
+
+// Option configures the client created by `New` or `NewWithJsonRpcClient`
+type Option func(*client)
+
+// WithChainRegressionTolerance enables detecting chain regression: server response ledger state
+// behind the client known ledger state more than the given max number of versions or max
+// duration of time is considered as chain regression, `*ChainRegressionError` is returned and
+// it is not retryable. Without this option, responses behind the client known ledger state are
+// stale responses and they are retried, e.g. a lagging node behind a load balancer.
+func WithChainRegressionTolerance(versions uint64, duration time.Duration) Option {
+	return func(c *client) {
+		c.regressionTolerance = &LedgerState{
+			Version:       versions,
+			TimestampUsec: uint64(duration.Microseconds()),
+		}
+	}
+}
+
+// WithChainRegressionCallback sets callback function that is called when chain regression
+// is detected, see `WithChainRegressionTolerance`.
+func WithChainRegressionCallback(fn func(*ChainRegressionError)) Option {
+	return func(c *client) {
+		c.onChainRegression = fn
+	}
+}
+
+// WithChainResetHandler enables detecting chain resets, e.g. testnet is wiped and restarted
+// from a new genesis. A reset is declared only after consecutive responses consistently signal
+// it (see `WithChainResetConfirmations`), so that a single lagging node behind a load balancer
+// can't trigger one; until then, `*ChainIDMismatchError` or `*ChainRegressionError` is returned
+// as without the handler.
+//
+// When the consecutive responses have the same chain id that is different from the client
+// chain id, the client drops cached on-chain configs, calls the handler and returns
+// `*ChainResetError`; the client never adopts the server chain id, the handler decides what to
+// do, e.g. create a new client for the new chain id.
+// When the consecutive responses regressed beyond the chain regression tolerance (enabled with
+// the default tolerance if `WithChainRegressionTolerance` is not set) and do not regress among
+// themselves, the client adopts the last server ledger state, drops cached on-chain
+// configs, calls the handler and returns `*ChainResetError`.
+//
+// The handler is called synchronously, it can be used for reinitializing application state
+// that depends on the chain, e.g. recreating and funding testnet accounts. A nil handler
+// enables the reset without callback.
+// Do not use this option for mainnet clients, where chain id mismatch is a configuration error.
+func WithChainResetHandler(fn func(*ChainResetError)) Option {
+	return func(c *client) {
+		if fn == nil {
+			fn = func(*ChainResetError) {}
+		}
+		c.onChainReset = fn
+	}
+}
+
+// WithChainResetConfirmations sets number of consecutive responses that must consistently
+// signal a chain reset before it is declared, default is `DefaultChainResetConfirmations`.
+// Values less than 1 are treated as 1.
+func WithChainResetConfirmations(n int) Option {
+	return func(c *client) {
+		c.resetConfirmations = n
+	}
+}
+
+// WithStaleResponseTolerance sets max number of versions and max duration of time server
+// response ledger state can be behind the client known ledger state and still be accepted as
+// fresh. Default is 0, any response older than the client known ledger state is
+// `*StaleResponseError`.
+func WithStaleResponseTolerance(versions uint64, duration time.Duration) Option {
+	return func(c *client) {
+		c.staleTolerance = LedgerState{
+			Version:       versions,
+			TimestampUsec: uint64(duration.Microseconds()),
+		}
+	}
+}
+
+// WithStaleResponseCallback sets callback function that is called when a server response is
+// stale, i.e. the server is behind the client known ledger state beyond the stale response
+// tolerance. It is for alerting on full nodes serving stale data.
+func WithStaleResponseCallback(fn func(*StaleResponseError)) Option {
+	return func(c *client) {
+		c.onStaleResponse = fn
+	}
+}
+
+// WithChainIDVerification enables or disables verifying chain id of every server response against
+// the client chain id, default is enabled. Responses of a different chain id fail with
+// `*ChainIDMismatchError`.
+func WithChainIDVerification(enabled bool) Option {
+	return func(c *client) {
+		c.skipChainIDVerification = !enabled
+	}
+}
+
+// WithAlerts sets `Alerts` for receiving notable conditions detected by the client, default is
+// `NopAlerts`; set `NewLogAlerts()` for writing alerts to stderr.
+func WithAlerts(alerts Alerts) Option {
+	return func(c *client) {
+		c.alerts = alerts
+	}
+}
+
+// WithAlertThresholds sets number of consecutive stale responses and consecutive submission
+// failures to trigger alerts. Set 0 to disable the alert.
+func WithAlertThresholds(staleResponses int, submissionFailures int) Option {
+	return func(c *client) {
+		c.staleResponses.threshold = staleResponses
+		c.submitFailures.threshold = submissionFailures
+	}
+}
+
+// WithHTTPClient sets `*http.Client` for the JSON-RPC client created by `New` and
+// `NewWithFailover`, e.g. a client with mTLS transport. `WithHTTPOptions` are applied to a copy
+// of it, see `jsonrpc.ConfigureHTTPClient`.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHTTPOptions configures the `*http.Client` of the JSON-RPC client created by `New` and
+// `NewWithFailover`, e.g. `jsonrpc.WithRoundTripper`, `jsonrpc.WithTLSConfig` and
+// `jsonrpc.WithProxy`; the options are order independent. Transport options are ignored with a
+// warning log when the round tripper in use is not a `*http.Transport`.
+// High-QPS submitters should raise the connection pool limits to their concurrency by
+// `jsonrpc.WithMaxIdleConns`, otherwise requests beyond the idle limit pay a new connection
+// (and TLS handshake) each, see `BenchmarkHTTPClient` in jsonrpc package.
+func WithHTTPOptions(opts ...jsonrpc.HTTPOption) Option {
+	return func(c *client) {
+		c.httpOpts = append(c.httpOpts, opts...)
+	}
+}
+
+// WithOnChainConfigsCacheTTL enables caching on-chain configs: currencies info, dual
+// attestation limit and VM config gas constants, for the given time to live. The cache is
+// disabled by default (TTL 0), as `GetCurrencies` results may be out of date for the TTL;
+// currencies exchange rates and dual attestation limit are rarely updated, e.g. one minute is
+// fresh enough for deciding whether a payment requires the travel rule off-chain flow by
+// `IsTravelRuleRequired`. Calls with context created by `BypassOnChainConfigsCache` always load
+// the configs from the server.
+func WithOnChainConfigsCacheTTL(ttl time.Duration) Option {
+	return func(c *client) {
+		c.configsTTL = ttl
+	}
+}
+
+// WithStreamConcurrency sets number of batches `StreamTransactions` fetches concurrently,
+// default is `DefaultStreamConcurrency`. It is also the max number of fetched batches held in
+// memory waiting for the consumer.
+func WithStreamConcurrency(n int) Option {
+	return func(c *client) {
+		c.streamConcurrency = n
+	}
+}
+
+// WithResponseCache enables a LRU cache of given size for reads by ledger version, which never
+// change once the version is committed: `GetMetadataByVersion`, `GetAccountAtVersion` and
+// `GetTransactions` (each transaction is an entry). It cuts redundant requests of jobs re-reading same versions, e.g.
+// reconciliation. `GetCurrencies` can be cached by `WithOnChainConfigsCacheTTL` instead, as it has
+// no version parameter. Cached responses are shared between calls and must not be modified.
+// The cache is dropped on chain reset. Default is disabled; set 0 to disable it.
+func WithResponseCache(size int) Option {
+	return func(c *client) {
+		c.responses = newResponseCache(size)
+	}
+}
+
+// WithLogger sets logger for logging JSON-RPC calls (debug), stale responses, transaction
+// submissions and results of waiting for transactions; default is `diemlog.Nop`. Sensitive
+// fields are redacted, see `diemlog.Redacting`.
+func WithLogger(logger diemlog.Logger) Option {
+	return func(c *client) {
+		c.logger = diemlog.Redacting(logger)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// AccountTransactionsIterator pages through account transactions by "get_account_transactions".
+// The iteration is pinned to the ledger version of the first page response: transactions
+// committed after it are not returned, so that the iteration is a consistent snapshot.
+//
+//	it := client.GetAccountTransactionsPaged(address, 0, 100)
+//	for it.Next() {
+//		txn := it.Transaction()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type AccountTransactionsIterator struct {
+	client   *client
+	ctx      context.Context
+	address  diemtypes.AccountAddress
+	next     uint64
+	limit    uint64
+	pinned   uint64
+	page     []*Transaction
+	current  *Transaction
+	done     bool
+	err      error
+	started  bool
+	lastPage bool
+}
+
+// GetAccountTransactionsPaged returns iterator of account transactions from sequence number
+// `start`, fetching `limit` transactions with events per page.
+func (c *client) GetAccountTransactionsPaged(address diemtypes.AccountAddress, start uint64, limit uint64) *AccountTransactionsIterator {
+	return c.GetAccountTransactionsPagedWithContext(context.Background(), address, start, limit)
+}
+
+// GetAccountTransactionsPagedWithContext is `GetAccountTransactionsPaged` with context
+func (c *client) GetAccountTransactionsPagedWithContext(ctx context.Context, address diemtypes.AccountAddress, start uint64, limit uint64) *AccountTransactionsIterator {
+	if limit == 0 {
+		limit = 1
+	}
+	return &AccountTransactionsIterator{client: c, ctx: ctx, address: address, next: start, limit: limit}
+}
+
+// Next moves to next transaction, returns false when there is no more transactions or
+// an error occurred.
+func (it *AccountTransactionsIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if len(it.page) == 0 {
+		if it.lastPage || !it.fetch() || len(it.page) == 0 {
+			it.done = true
+			it.current = nil
+			return false
+		}
+	}
+	txn := it.page[0]
+	if txn.Version > it.pinned {
+		it.done = true
+		it.current = nil
+		return false
+	}
+	it.current = txn
+	it.page = it.page[1:]
+	return true
+}
+
+// Transaction returns current transaction
+func (it *AccountTransactionsIterator) Transaction() *Transaction {
+	return it.current
+}
+
+// Err returns error occurred during iteration
+func (it *AccountTransactionsIterator) Err() error {
+	return it.err
+}
+
+// NextSequenceNumber returns sequence number of the next transaction to fetch, it can be
+// used for resuming the iteration.
+func (it *AccountTransactionsIterator) NextSequenceNumber() uint64 {
+	return it.next - uint64(len(it.page))
+}
+
+func (it *AccountTransactionsIterator) fetch() bool {
+	txns, err := it.client.GetAccountTransactionsWithContext(it.ctx, it.address, it.next, it.limit, true)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if !it.started {
+		it.started = true
+		it.pinned = it.client.LastResponseLedgerState().Version
+	}
+	it.page = txns
+	it.next += uint64(len(txns))
+	it.lastPage = uint64(len(txns)) < it.limit
+	return true
+}
@@ -0,0 +1,83 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accountTxnsStub serves get_account_transactions, transaction i has version `(i + 1) * 10`
+type accountTxnsStub struct {
+	count         uint64
+	ledgerVersion uint64
+	err           error
+}
+
+func (s *accountTxnsStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	req := requests[0]
+	start := req.Params[1].(uint64)
+	limit := req.Params[2].(uint64)
+	txns := []*diemclient.Transaction{}
+	for i := start; i < s.count && i < start+limit; i++ {
+		txns = append(txns, &diemclient.Transaction{Version: (i + 1) * 10})
+	}
+	result, _ := json.Marshal(txns)
+	raw := json.RawMessage(result)
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{
+		req.ID: {Result: &raw, DiemLedgerVersion: s.ledgerVersion},
+	}}
+	return stub.Call(requests...)
+}
+
+func TestGetAccountTransactionsPaged(t *testing.T) {
+	address := diemkeys.MustGenKeys().AccountAddress()
+
+	t.Run("pages until exhaustion", func(t *testing.T) {
+		stub := &accountTxnsStub{count: 5, ledgerVersion: 100}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub)
+		it := client.(diemclient.PagingClient).GetAccountTransactionsPaged(address, 1, 2)
+		var versions []uint64
+		for it.Next() {
+			versions = append(versions, it.Transaction().Version)
+		}
+		require.NoError(t, it.Err())
+		assert.Equal(t, []uint64{20, 30, 40, 50}, versions)
+		assert.Equal(t, uint64(5), it.NextSequenceNumber())
+	})
+	t.Run("pinned to first page ledger version", func(t *testing.T) {
+		stub := &accountTxnsStub{count: 3, ledgerVersion: 30}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub)
+		it := client.(diemclient.PagingClient).GetAccountTransactionsPaged(address, 0, 2)
+		require.True(t, it.Next())
+		// a new transaction is committed after the first page
+		stub.count, stub.ledgerVersion = 4, 40
+		require.True(t, it.Next())
+		require.True(t, it.Next())
+		assert.Equal(t, uint64(30), it.Transaction().Version)
+		assert.False(t, it.Next())
+		assert.NoError(t, it.Err())
+		assert.Equal(t, uint64(3), it.NextSequenceNumber())
+	})
+	t.Run("error", func(t *testing.T) {
+		stub := &accountTxnsStub{err: errors.New("server error")}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub,
+			diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		it := client.(diemclient.PagingClient).GetAccountTransactionsPaged(address, 0, 2)
+		assert.False(t, it.Next())
+		assert.EqualError(t, it.Err(), "server error")
+	})
+}
@@ -0,0 +1,205 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// ErrBCSNotSupported is returned by BCS methods when the server responds other content type
+// than `ContentTypeBCS`, callers may fall back to the JSON methods.
+var ErrBCSNotSupported = errors.New("server does not support BCS response")
+
+// OnChainTransaction is BCS decoded transaction with its execution info and events, it is the
+// item of BCS "/transactions" endpoints responses.
+type OnChainTransaction struct {
+	Version     uint64
+	Transaction diemtypes.Transaction
+	Info        TransactionInfo
+	Events      []diemtypes.ContractEvent
+}
+
+// TransactionInfo is the transaction execution info
+type TransactionInfo struct {
+	TransactionHash []byte
+	StateRootHash   []byte
+	EventRootHash   []byte
+	GasUsed         uint64
+	// Status is same with JSON-RPC transaction vm status, the location is formatted as
+	// "<module address hex>::<module name>" or "Script".
+	Status *diemclient.VmStatus
+}
+
+// SignedTransaction returns the user signed transaction, false if the transaction is not
+// a user transaction.
+func (t *OnChainTransaction) SignedTransaction() (*diemtypes.SignedTransaction, bool) {
+	if user, ok := t.Transaction.(*diemtypes.Transaction__UserTransaction); ok {
+		return &user.Value, true
+	}
+	return nil, false
+}
+
+func (c *client) GetTransactionsBCS(start uint64, limit uint64) ([]*OnChainTransaction, error) {
+	return c.GetTransactionsBCSWithContext(context.Background(), start, limit)
+}
+
+// GetTransactionsBCSWithContext gets transactions from the start version in BCS and decodes
+// them into `diemtypes`, it avoids decoding JSON and hex-encoded transaction bytes.
+// Returns `ErrBCSNotSupported` if the server does not support BCS responses.
+func (c *client) GetTransactionsBCSWithContext(ctx context.Context, start uint64, limit uint64) ([]*OnChainTransaction, error) {
+	body, err := c.getBCS(ctx, "/transactions", pageQuery(start, limit))
+	if err != nil {
+		return nil, err
+	}
+	return decodeOnChainTransactions(body)
+}
+
+func (c *client) GetAccountTransactionsBCS(address diemtypes.AccountAddress, start uint64, limit uint64) ([]*OnChainTransaction, error) {
+	return c.GetAccountTransactionsBCSWithContext(context.Background(), address, start, limit)
+}
+
+// GetAccountTransactionsBCSWithContext gets transactions sent by the account from the start
+// sequence number in BCS and decodes them into `diemtypes`.
+// Returns `ErrBCSNotSupported` if the server does not support BCS responses.
+func (c *client) GetAccountTransactionsBCSWithContext(ctx context.Context, address diemtypes.AccountAddress, start uint64, limit uint64) ([]*OnChainTransaction, error) {
+	body, err := c.getBCS(ctx, "/accounts/0x"+address.Hex()+"/transactions", pageQuery(start, limit))
+	if err != nil {
+		return nil, err
+	}
+	return decodeOnChainTransactions(body)
+}
+
+func decodeOnChainTransactions(body []byte) ([]*OnChainTransaction, error) {
+	d := bcs.NewDeserializer(body)
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, fmt.Errorf("decode transactions bcs failed: %v", err)
+	}
+	ret := make([]*OnChainTransaction, 0, length)
+	for i := uint64(0); i < length; i++ {
+		txn, err := decodeOnChainTransaction(d)
+		if err != nil {
+			return nil, fmt.Errorf("decode transactions bcs failed: %v", err)
+		}
+		ret = append(ret, txn)
+	}
+	if d.GetBufferOffset() != uint64(len(body)) {
+		return nil, errors.New("decode transactions bcs failed: some input bytes were not read")
+	}
+	return ret, nil
+}
+
+func decodeOnChainTransaction(d serde.Deserializer) (*OnChainTransaction, error) {
+	var ret OnChainTransaction
+	var err error
+	if ret.Version, err = d.DeserializeU64(); err != nil {
+		return nil, err
+	}
+	if ret.Transaction, err = diemtypes.DeserializeTransaction(d); err != nil {
+		return nil, err
+	}
+	if ret.Info, err = decodeTransactionInfo(d); err != nil {
+		return nil, err
+	}
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < length; i++ {
+		event, err := diemtypes.DeserializeContractEvent(d)
+		if err != nil {
+			return nil, err
+		}
+		ret.Events = append(ret.Events, event)
+	}
+	return &ret, nil
+}
+
+func decodeTransactionInfo(d serde.Deserializer) (ret TransactionInfo, err error) {
+	index, err := d.DeserializeVariantIndex()
+	if err != nil {
+		return ret, err
+	}
+	if index != 0 {
+		return ret, fmt.Errorf("unknown variant index for TransactionInfo: %d", index)
+	}
+	for _, hash := range []*[]byte{&ret.TransactionHash, &ret.StateRootHash, &ret.EventRootHash} {
+		if *hash, err = d.DeserializeBytes(); err != nil {
+			return ret, err
+		}
+	}
+	if ret.GasUsed, err = d.DeserializeU64(); err != nil {
+		return ret, err
+	}
+	ret.Status, err = decodeKeptVMStatus(d)
+	return ret, err
+}
+
+// decodeKeptVMStatus decodes `KeptVMStatus`: Executed, OutOfGas, MoveAbort(location, code),
+// ExecutionFailure{location, function, code_offset} and MiscellaneousError.
+func decodeKeptVMStatus(d serde.Deserializer) (*diemclient.VmStatus, error) {
+	index, err := d.DeserializeVariantIndex()
+	if err != nil {
+		return nil, err
+	}
+	switch index {
+	case 0:
+		return &diemclient.VmStatus{Type: diemclient.VmStatusExecuted}, nil
+	case 1:
+		return &diemclient.VmStatus{Type: diemclient.VmStatusOutOfGas}, nil
+	case 2:
+		location, err := decodeAbortLocation(d)
+		if err != nil {
+			return nil, err
+		}
+		code, err := d.DeserializeU64()
+		if err != nil {
+			return nil, err
+		}
+		return &diemclient.VmStatus{Type: diemclient.VmStatusMoveAbort, Location: location, AbortCode: code}, nil
+	case 3:
+		location, err := decodeAbortLocation(d)
+		if err != nil {
+			return nil, err
+		}
+		function, err := d.DeserializeU16()
+		if err != nil {
+			return nil, err
+		}
+		offset, err := d.DeserializeU16()
+		if err != nil {
+			return nil, err
+		}
+		return &diemclient.VmStatus{Type: diemclient.VmStatusExecutionFailure, Location: location,
+			FunctionIndex: uint32(function), CodeOffset: uint32(offset)}, nil
+	case 4:
+		return &diemclient.VmStatus{Type: diemclient.VmStatusMiscellaneousError}, nil
+	}
+	return nil, fmt.Errorf("unknown variant index for KeptVMStatus: %d", index)
+}
+
+func decodeAbortLocation(d serde.Deserializer) (string, error) {
+	index, err := d.DeserializeVariantIndex()
+	if err != nil {
+		return "", err
+	}
+	switch index {
+	case 0:
+		module, err := diemtypes.DeserializeModuleId(d)
+		if err != nil {
+			return "", err
+		}
+		return module.Address.Hex() + "::" + string(module.Name), nil
+	case 1:
+		return "Script", nil
+	}
+	return "", fmt.Errorf("unknown variant index for AbortLocation: %d", index)
+}
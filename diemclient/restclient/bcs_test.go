@@ -0,0 +1,101 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package restclient_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/restclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeTransactions(t *testing.T, txn *diemtypes.SignedTransaction) []byte {
+	hash := make([]byte, 32)
+	s := bcs.NewSerializer()
+	require.NoError(t, s.SerializeLen(2))
+
+	// user transaction aborted with events
+	require.NoError(t, s.SerializeU64(42))
+	require.NoError(t, (&diemtypes.Transaction__UserTransaction{Value: *txn}).Serialize(s))
+	require.NoError(t, s.SerializeVariantIndex(0))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.SerializeBytes(hash))
+	}
+	require.NoError(t, s.SerializeU64(7))
+	require.NoError(t, s.SerializeVariantIndex(2))
+	require.NoError(t, s.SerializeVariantIndex(0))
+	module := diemtypes.ModuleId{Address: diemtypes.AccountAddress{15: 1}, Name: "DiemAccount"}
+	require.NoError(t, module.Serialize(s))
+	require.NoError(t, s.SerializeU64(1288))
+	require.NoError(t, s.SerializeLen(1))
+	event := &diemtypes.ContractEvent__V0{Value: diemtypes.ContractEventV0{
+		Key: diemtypes.EventKey{1}, SequenceNumber: 3, TypeTag: &diemtypes.TypeTag__Bool{}, EventData: []byte{1},
+	}}
+	require.NoError(t, event.Serialize(s))
+
+	// block metadata transaction executed without events
+	require.NoError(t, s.SerializeU64(43))
+	block := &diemtypes.Transaction__BlockMetadata{Value: diemtypes.BlockMetadata{Id: hash, Round: 1}}
+	require.NoError(t, block.Serialize(s))
+	require.NoError(t, s.SerializeVariantIndex(0))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.SerializeBytes(hash))
+	}
+	require.NoError(t, s.SerializeU64(0))
+	require.NoError(t, s.SerializeVariantIndex(0))
+	require.NoError(t, s.SerializeLen(0))
+	return s.GetBytes()
+}
+
+func TestGetTransactionsBCS(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	signed := diemsigner.SignTxn(keys, keys.AccountAddress(), 1,
+		stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			diemtypes.Currency("XUS"), diemtypes.MustMakeAccountAddress(receiver), 100, nil, nil),
+		1_000_000, 0, "XUS", 1600000030, chainID)
+	body := string(encodeTransactions(t, signed))
+	server := newServer(t, map[string]http.HandlerFunc{
+		"/transactions": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, restclient.ContentTypeBCS, r.Header.Get("Accept"))
+			respond(restclient.ContentTypeBCS, http.StatusOK, body)(w, r)
+		},
+		"/accounts/0x" + keys.AccountAddress().Hex() + "/transactions": respondJSON("[]"),
+	})
+	client := restclient.New(chainID, server.URL)
+
+	txns, err := client.GetTransactionsBCS(42, 2)
+	require.NoError(t, err)
+	require.Len(t, txns, 2)
+
+	assert.Equal(t, uint64(42), txns[0].Version)
+	user, ok := txns[0].SignedTransaction()
+	require.True(t, ok)
+	assert.Equal(t, signed.TransactionHash(), user.TransactionHash())
+	assert.Equal(t, uint64(7), txns[0].Info.GasUsed)
+	assert.Equal(t, &diemclient.VmStatus{
+		Type:      diemclient.VmStatusMoveAbort,
+		Location:  "00000000000000000000000000000001::DiemAccount",
+		AbortCode: 1288,
+	}, txns[0].Info.Status)
+	require.Len(t, txns[0].Events, 1)
+	assert.Equal(t, uint64(3), txns[0].Events[0].(*diemtypes.ContractEvent__V0).Value.SequenceNumber)
+
+	assert.Equal(t, uint64(43), txns[1].Version)
+	_, ok = txns[1].SignedTransaction()
+	assert.False(t, ok)
+	assert.Equal(t, diemclient.VmStatusExecuted, txns[1].Info.Status.Type)
+	assert.Empty(t, txns[1].Events)
+
+	_, err = client.GetAccountTransactionsBCS(keys.AccountAddress(), 0, 10)
+	assert.True(t, errors.Is(err, restclient.ErrBCSNotSupported))
+}
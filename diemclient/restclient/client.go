@@ -0,0 +1,435 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+)
+
+// Content types for request bodies and response content negotiation
+const (
+	ContentTypeJSON                 = "application/json"
+	ContentTypeBCS                  = "application/x-bcs"
+	ContentTypeSignedTransactionBCS = "application/x.diem.signed_transaction+bcs"
+)
+
+// Response headers of the server ledger state
+const (
+	HeaderChainID             = "X-Diem-Chain-Id"
+	HeaderLedgerVersion       = "X-Diem-Ledger-Version"
+	HeaderLedgerTimestampUsec = "X-Diem-Ledger-TimestampUsec"
+)
+
+// Client is Diem REST API client, results are same types with `diemclient.Client`.
+// Server REST API does not have the JSON-RPC `include_events` parameter, transactions are always
+// returned with events.
+type Client interface {
+	GetMetadata() (*diemclient.Metadata, error)
+	GetAccount(diemtypes.AccountAddress) (*diemclient.Account, error)
+	GetAccountStateBlob(diemtypes.AccountAddress) ([]byte, error)
+	GetAccountTransactions(address diemtypes.AccountAddress, start uint64, limit uint64) ([]*diemclient.Transaction, error)
+	GetTransactions(start uint64, limit uint64) ([]*diemclient.Transaction, error)
+	GetTransactionByHash(hash string) (*diemclient.Transaction, error)
+	GetEvents(key string, start uint64, limit uint64) ([]*diemclient.Event, error)
+	SubmitTransaction(txn *diemtypes.SignedTransaction) error
+
+	// BCS variants request BCS response and decode it into `diemtypes`, they return
+	// `ErrBCSNotSupported` if the server does not support BCS responses.
+	GetTransactionsBCS(start uint64, limit uint64) ([]*OnChainTransaction, error)
+	GetAccountTransactionsBCS(address diemtypes.AccountAddress, start uint64, limit uint64) ([]*OnChainTransaction, error)
+
+	// WithContext variants accept `context.Context` for cancellation and deadline.
+	GetMetadataWithContext(ctx context.Context) (*diemclient.Metadata, error)
+	GetAccountWithContext(ctx context.Context, address diemtypes.AccountAddress) (*diemclient.Account, error)
+	GetAccountStateBlobWithContext(ctx context.Context, address diemtypes.AccountAddress) ([]byte, error)
+	GetAccountTransactionsWithContext(ctx context.Context, address diemtypes.AccountAddress, start uint64, limit uint64) ([]*diemclient.Transaction, error)
+	GetTransactionsWithContext(ctx context.Context, start uint64, limit uint64) ([]*diemclient.Transaction, error)
+	GetTransactionByHashWithContext(ctx context.Context, hash string) (*diemclient.Transaction, error)
+	GetEventsWithContext(ctx context.Context, key string, start uint64, limit uint64) ([]*diemclient.Event, error)
+	SubmitTransactionWithContext(ctx context.Context, txn *diemtypes.SignedTransaction) error
+	GetTransactionsBCSWithContext(ctx context.Context, start uint64, limit uint64) ([]*OnChainTransaction, error)
+	GetAccountTransactionsBCSWithContext(ctx context.Context, address diemtypes.AccountAddress, start uint64, limit uint64) ([]*OnChainTransaction, error)
+
+	ChainID() byte
+	LastResponseLedgerState() diemclient.LedgerState
+
+	// DiemClient returns `diemclient.Client` adapted to the REST API, for applications and
+	// packages depending on `diemclient.Client`. It shares the ledger state with this client.
+	// Methods that have no REST API equivalent, e.g. `GetCurrencies`, `GetStateProof` and the
+	// calls at a given ledger version, fail with `*jsonrpc.ResponseError` of code
+	// `ErrCodeMethodNotSupported`.
+	DiemClient() diemclient.Client
+}
+
+// Option configures the client created by `New`
+type Option func(*client)
+
+// WithHTTPClient sets `*http.Client` of the client, `WithHTTPOptions` are applied to a copy of it
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.http = httpClient
+	}
+}
+
+// WithHTTPOptions configures the `*http.Client` of the client, default is created by
+// `jsonrpc.NewHTTPClient`; see `jsonrpc.ConfigureHTTPClient` for how options apply to a given
+// `*http.Client`
+func WithHTTPOptions(opts ...jsonrpc.HTTPOption) Option {
+	return func(c *client) {
+		c.httpOpts = append(c.httpOpts, opts...)
+	}
+}
+
+// WithBCS enables or disables requesting account resources in BCS, default is disabled.
+// When enabled, the client accepts both BCS and JSON, and decodes the response by its content
+// type, hence it works with servers that do not support BCS responses.
+func WithBCS(enabled bool) Option {
+	return func(c *client) {
+		c.bcs = enabled
+	}
+}
+
+// WithChainIDVerification enables or disables verifying chain id of every server response
+// against the client chain id, default is enabled.
+func WithChainIDVerification(enabled bool) Option {
+	return func(c *client) {
+		c.skipChainIDVerification = !enabled
+	}
+}
+
+// WithDiemClientOptions configures the `diemclient.Client` that validates response ledger
+// states and is returned by `Client#DiemClient`, e.g. `diemclient.WithStaleResponseTolerance`,
+// `diemclient.WithChainRegressionTolerance`, `diemclient.WithChainResetHandler` and
+// `diemclient.WithRetryPolicy`.
+func WithDiemClientOptions(opts ...diemclient.Option) Option {
+	return func(c *client) {
+		c.diemOpts = append(c.diemOpts, opts...)
+	}
+}
+
+// New creates a REST API `Client` connects to the given server base URL, e.g.
+// "http://localhost:8080/v1"
+func New(chainID byte, baseURL string, opts ...Option) Client {
+	c := &client{chainID: chainID, baseURL: strings.TrimSuffix(baseURL, "/")}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.http == nil {
+		c.http = jsonrpc.NewHTTPClient(c.httpOpts...)
+	} else if len(c.httpOpts) > 0 {
+		c.http = jsonrpc.ConfigureHTTPClient(c.http, c.httpOpts...)
+	}
+	c.diem = diemclient.NewWithJsonRpcClient(chainID, &jsonRPCClient{client: c}, c.diemOpts...)
+	return c
+}
+
+type client struct {
+	chainID  byte
+	baseURL  string
+	http     *http.Client
+	httpOpts []jsonrpc.HTTPOption
+	bcs      bool
+
+	skipChainIDVerification bool
+
+	// diem validates and records response ledger states
+	diem     diemclient.Client
+	diemOpts []diemclient.Option
+}
+
+type response struct {
+	contentType string
+	body        []byte
+}
+
+// ChainID returns chain id of the client
+func (c *client) ChainID() byte {
+	return c.chainID
+}
+
+// LastResponseLedgerState returns the latest ledger state of server responses
+func (c *client) LastResponseLedgerState() diemclient.LedgerState {
+	return c.diem.LastResponseLedgerState()
+}
+
+// DiemClient returns the `diemclient.Client` adapted to the REST API
+func (c *client) DiemClient() diemclient.Client {
+	return c.diem
+}
+
+func (c *client) GetMetadata() (*diemclient.Metadata, error) {
+	return c.GetMetadataWithContext(context.Background())
+}
+
+// GetMetadataWithContext gets ledger info by the index endpoint
+func (c *client) GetMetadataWithContext(ctx context.Context) (*diemclient.Metadata, error) {
+	var ret LedgerInfo
+	if err := c.getJSON(ctx, "/", nil, &ret); err != nil {
+		return nil, err
+	}
+	return &diemclient.Metadata{
+		Version:   uint64(ret.LedgerVersion),
+		Timestamp: uint64(ret.LedgerTimestamp),
+		ChainId:   uint32(ret.ChainID),
+	}, nil
+}
+
+func (c *client) GetAccount(address diemtypes.AccountAddress) (*diemclient.Account, error) {
+	return c.GetAccountWithContext(context.Background(), address)
+}
+
+// GetAccountWithContext gets account resources and converts them into `diemclient.Account`,
+// returns nil if the account does not exist. The account role is not decoded.
+func (c *client) GetAccountWithContext(ctx context.Context, address diemtypes.AccountAddress) (*diemclient.Account, error) {
+	accept := ContentTypeJSON
+	if c.bcs {
+		accept = ContentTypeBCS + ", " + ContentTypeJSON + ";q=0.9"
+	}
+	resp, err := c.get(ctx, accountResourcesPath(address), nil, accept)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.contentType == ContentTypeBCS {
+		return accountFromStateBlob(address, bcsBytes(resp.body))
+	}
+	var resources []*Resource
+	if err := json.Unmarshal(resp.body, &resources); err != nil {
+		return nil, fmt.Errorf("parse account resources json failed: %v", err)
+	}
+	return accountFromResources(address, resources)
+}
+
+func (c *client) GetAccountStateBlob(address diemtypes.AccountAddress) ([]byte, error) {
+	return c.GetAccountStateBlobWithContext(context.Background(), address)
+}
+
+// GetAccountStateBlobWithContext gets account resources in BCS, returns account state blob
+// bytes same with `diemclient.StateClient#GetAccountStateBlob` for decoding by package
+// `accountstate`; returns nil if the account does not exist.
+// Returns `ErrBCSNotSupported` if the server does not support BCS responses.
+func (c *client) GetAccountStateBlobWithContext(ctx context.Context, address diemtypes.AccountAddress) ([]byte, error) {
+	body, err := c.getBCS(ctx, accountResourcesPath(address), nil)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bcsBytes(body), nil
+}
+
+func (c *client) GetAccountTransactions(address diemtypes.AccountAddress, start uint64, limit uint64) ([]*diemclient.Transaction, error) {
+	return c.GetAccountTransactionsWithContext(context.Background(), address, start, limit)
+}
+
+// GetAccountTransactionsWithContext gets transactions sent by the account from the start
+// sequence number
+func (c *client) GetAccountTransactionsWithContext(ctx context.Context, address diemtypes.AccountAddress, start uint64, limit uint64) ([]*diemclient.Transaction, error) {
+	var ret []*Transaction
+	err := c.getJSON(ctx, "/accounts/0x"+address.Hex()+"/transactions", pageQuery(start, limit), &ret)
+	if err != nil {
+		return nil, err
+	}
+	return c.toTransactions(ret)
+}
+
+func (c *client) GetTransactions(start uint64, limit uint64) ([]*diemclient.Transaction, error) {
+	return c.GetTransactionsWithContext(context.Background(), start, limit)
+}
+
+// GetTransactionsWithContext gets transactions from the start version
+func (c *client) GetTransactionsWithContext(ctx context.Context, start uint64, limit uint64) ([]*diemclient.Transaction, error) {
+	var ret []*Transaction
+	if err := c.getJSON(ctx, "/transactions", pageQuery(start, limit), &ret); err != nil {
+		return nil, err
+	}
+	return c.toTransactions(ret)
+}
+
+func (c *client) GetTransactionByHash(hash string) (*diemclient.Transaction, error) {
+	return c.GetTransactionByHashWithContext(context.Background(), hash)
+}
+
+// GetTransactionByHashWithContext gets transaction by hex-encoded hash, returns nil if the
+// transaction is not found or still pending in mempool.
+func (c *client) GetTransactionByHashWithContext(ctx context.Context, hash string) (*diemclient.Transaction, error) {
+	var ret Transaction
+	err := c.getJSON(ctx, "/transactions/0x"+strings.TrimPrefix(hash, "0x"), nil, &ret)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ret.Type == PendingTransaction {
+		return nil, nil
+	}
+	return toTransaction(&ret, c.chainID)
+}
+
+func (c *client) GetEvents(key string, start uint64, limit uint64) ([]*diemclient.Event, error) {
+	return c.GetEventsWithContext(context.Background(), key, start, limit)
+}
+
+// GetEventsWithContext gets events of the hex-encoded event key from the start sequence number
+func (c *client) GetEventsWithContext(ctx context.Context, key string, start uint64, limit uint64) ([]*diemclient.Event, error) {
+	var events []*Event
+	err := c.getJSON(ctx, "/events/0x"+strings.TrimPrefix(key, "0x"), pageQuery(start, limit), &events)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*diemclient.Event, len(events))
+	for i, event := range events {
+		if ret[i], err = toEvent(event, uint64(event.Version)); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func (c *client) SubmitTransaction(txn *diemtypes.SignedTransaction) error {
+	return c.SubmitTransactionWithContext(context.Background(), txn)
+}
+
+// SubmitTransactionWithContext submits BCS signed transaction. Rejected submission returns
+// `*diemclient.SubmitError` with code `diemclient.ErrCodeVmValidationError`, and the kind
+// classified by the VM status in the error message.
+// It ignores `*diemclient.StaleResponseError`, same with `diemclient.Client#SubmitTransaction`.
+func (c *client) SubmitTransactionWithContext(ctx context.Context, txn *diemtypes.SignedTransaction) error {
+	_, err := c.do(ctx, http.MethodPost, "/transactions", nil,
+		diemtypes.ToBCS(txn), ContentTypeSignedTransactionBCS, ContentTypeJSON)
+	if _, ok := err.(*diemclient.StaleResponseError); ok {
+		return nil
+	}
+	if e, ok := err.(*Error); ok && e.StatusCode >= 400 && e.StatusCode < 500 {
+		return diemclient.NewSubmitError(diemclient.ErrCodeVmValidationError, e.Message, e)
+	}
+	return err
+}
+
+func (c *client) toTransactions(txns []*Transaction) ([]*diemclient.Transaction, error) {
+	ret := make([]*diemclient.Transaction, len(txns))
+	for i, txn := range txns {
+		var err error
+		if ret[i], err = toTransaction(txn, c.chainID); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func (c *client) getJSON(ctx context.Context, path string, query url.Values, ret interface{}) error {
+	resp, err := c.get(ctx, path, query, ContentTypeJSON)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(resp.body, ret); err != nil {
+		return fmt.Errorf("parse response json failed: %v", err)
+	}
+	return nil
+}
+
+func (c *client) getBCS(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	resp, err := c.get(ctx, path, query, ContentTypeBCS)
+	if err != nil {
+		return nil, err
+	}
+	if resp.contentType != ContentTypeBCS {
+		return nil, fmt.Errorf("%w: response content type is %q", ErrBCSNotSupported, resp.contentType)
+	}
+	return resp.body, nil
+}
+
+func (c *client) get(ctx context.Context, path string, query url.Values, accept string) (*response, error) {
+	return c.do(ctx, http.MethodGet, path, query, nil, "", accept)
+}
+
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body []byte, contentType, accept string) (*response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("http call failed: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", accept)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http call failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read http response body failed: %w", err)
+	}
+	if err := c.handleLedgerState(resp.Header); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		ret := Error{Message: string(respBody)}
+		_ = json.Unmarshal(respBody, &ret)
+		ret.StatusCode = resp.StatusCode
+		return nil, &ret
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return &response{contentType: mediaType, body: respBody}, nil
+}
+
+// handleLedgerState verifies response chain id, and validates the response ledger state by
+// `diemclient.Client#UpdateLastResponseLedgerState`, which returns `*diemclient.StaleResponseError`
+// or `*diemclient.ChainRegressionError` for responses behind the last response ledger state.
+// Headers are optional for responses of proxies or load balancers.
+func (c *client) handleLedgerState(header http.Header) error {
+	if chainID := header.Get(HeaderChainID); chainID != "" && !c.skipChainIDVerification {
+		id, err := strconv.ParseUint(chainID, 10, 8)
+		if err != nil {
+			return fmt.Errorf("invalid %s header %q: %v", HeaderChainID, chainID, err)
+		}
+		if byte(id) != c.chainID {
+			return &diemclient.ChainIDMismatchError{Expected: c.chainID, Actual: byte(id)}
+		}
+	}
+	version, err := strconv.ParseUint(header.Get(HeaderLedgerVersion), 10, 64)
+	if err != nil {
+		return nil
+	}
+	timestamp, _ := strconv.ParseUint(header.Get(HeaderLedgerTimestampUsec), 10, 64)
+	return c.diem.UpdateLastResponseLedgerState(diemclient.LedgerState{Version: version, TimestampUsec: timestamp})
+}
+
+func accountResourcesPath(address diemtypes.AccountAddress) string {
+	return "/accounts/0x" + address.Hex() + "/resources"
+}
+
+func pageQuery(start, limit uint64) url.Values {
+	return url.Values{
+		"start": {strconv.FormatUint(start, 10)},
+		"limit": {strconv.FormatUint(limit, 10)},
+	}
+}
+
+func isNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.IsNotFound()
+}
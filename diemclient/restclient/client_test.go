@@ -0,0 +1,430 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package restclient_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/restclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chainID  = 4
+	sender   = "f72589b71ff4f8d139674a3f7369c69b"
+	receiver = "a74fd7c46952c497e75afb0a7932586d"
+	eventKey = "0200000000000000" + receiver
+)
+
+func newServer(t *testing.T, routes map[string]http.HandlerFunc) *httptest.Server {
+	mux := http.NewServeMux()
+	for path, handler := range routes {
+		handler := handler
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(restclient.HeaderChainID, fmt.Sprint(chainID))
+			w.Header().Set(restclient.HeaderLedgerVersion, "100")
+			w.Header().Set(restclient.HeaderLedgerTimestampUsec, "1600000000000000")
+			handler(w, r)
+		})
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func respond(contentType string, status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func respondJSON(body string) http.HandlerFunc {
+	return respond(restclient.ContentTypeJSON, http.StatusOK, body)
+}
+
+const resourcesJSON = `[
+  {"type": "0x1::DiemAccount::DiemAccount", "data": {
+    "authentication_key": "0x0102",
+    "sequence_number": "5",
+    "received_events": {"counter": "3", "guid": "0x` + eventKey + `"},
+    "sent_events": {"counter": "4", "guid": "0x` + eventKey + `"},
+    "withdraw_capability": {"vec": [{"account_address": "0x` + receiver + `"}]},
+    "key_rotation_capability": {"vec": []}
+  }},
+  {"type": "0x1::DiemAccount::Balance<0x1::XUS::XUS>", "data": {"coin": {"value": "100"}}},
+  {"type": "0x1::DiemAccount::Balance<0x1::XDX::XDX>", "data": {"coin": {"value": "0"}}},
+  {"type": "0x1::AccountFreezing::FreezingBit", "data": {"is_frozen": true}}
+]`
+
+const transactionJSON = `{
+  "type": "user_transaction",
+  "version": "42",
+  "hash": "0xabcd",
+  "gas_used": "7",
+  "success": true,
+  "vm_status": "Executed successfully",
+  "sender": "0x` + sender + `",
+  "sequence_number": "1",
+  "max_gas_amount": "1000000",
+  "gas_unit_price": "0",
+  "gas_currency_code": "XUS",
+  "expiration_timestamp_secs": "1600000030",
+  "payload": {
+    "type": "script_function_payload",
+    "function": "0x1::PaymentScripts::peer_to_peer_with_metadata",
+    "type_arguments": ["0x1::XUS::XUS"],
+    "arguments": ["0x` + receiver + `", "1000", "0x0102", "0x"]
+  },
+  "signature": {"type": "ed25519_signature", "public_key": "0x0a", "signature": "0x0b"},
+  "events": [{
+    "key": "0x` + eventKey + `",
+    "sequence_number": "2",
+    "type": "0x1::DiemAccount::ReceivedPaymentEvent",
+    "data": {"amount": "1000", "currency_code": "0x585553", "payer": "0x` + sender + `", "metadata": "0x0102"}
+  }, {
+    "key": "0x` + eventKey + `",
+    "sequence_number": "3",
+    "type": "0x1::Other::Event",
+    "data": {}
+  }]
+}`
+
+func encodeAccountState(t *testing.T) []byte {
+	address := diemtypes.MustMakeAccountAddress(receiver)
+	key, _ := hex.DecodeString(eventKey)
+	account := bcs.NewSerializer()
+	require.NoError(t, account.SerializeBytes([]byte{1, 2}))
+	require.NoError(t, account.SerializeLen(1))
+	require.NoError(t, address.Serialize(account))
+	require.NoError(t, account.SerializeLen(0))
+	for _, v := range []uint64{3, 4} {
+		require.NoError(t, account.SerializeU64(v))
+		require.NoError(t, account.SerializeBytes(key))
+	}
+	require.NoError(t, account.SerializeU64(5))
+	balance := bcs.NewSerializer()
+	require.NoError(t, balance.SerializeU64(100))
+
+	s := bcs.NewSerializer()
+	require.NoError(t, s.SerializeLen(2))
+	require.NoError(t, s.SerializeBytes(accountstate.ResourcePath(accountstate.DiemAccountTag)))
+	require.NoError(t, s.SerializeBytes(account.GetBytes()))
+	require.NoError(t, s.SerializeBytes(accountstate.ResourcePath(accountstate.BalanceTag("XUS"))))
+	require.NoError(t, s.SerializeBytes(balance.GetBytes()))
+	return s.GetBytes()
+}
+
+func TestGetMetadata(t *testing.T) {
+	server := newServer(t, map[string]http.HandlerFunc{
+		"/v1/": respondJSON(`{"chain_id": 4, "ledger_version": "100", "ledger_timestamp": "1600000000000000"}`),
+	})
+	client := restclient.New(chainID, server.URL+"/v1/")
+	metadata, err := client.GetMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), metadata.Version)
+	assert.Equal(t, uint64(1600000000000000), metadata.Timestamp)
+	assert.Equal(t, uint32(chainID), metadata.ChainId)
+	assert.Equal(t, diemclient.LedgerState{Version: 100, TimestampUsec: 1600000000000000},
+		client.LastResponseLedgerState())
+
+	_, err = restclient.New(2, server.URL+"/v1").GetMetadata()
+	var mismatch *diemclient.ChainIDMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, byte(chainID), mismatch.Actual)
+
+	_, err = restclient.New(2, server.URL+"/v1", restclient.WithChainIDVerification(false)).GetMetadata()
+	assert.NoError(t, err)
+}
+
+func TestGetAccount(t *testing.T) {
+	address := diemtypes.MustMakeAccountAddress(receiver)
+	expected := &diemclient.Account{
+		Address:                        receiver,
+		Balances:                       []*diemclient.Amount{{Amount: 0, Currency: "XDX"}, {Amount: 100, Currency: "XUS"}},
+		SequenceNumber:                 5,
+		AuthenticationKey:              "0102",
+		SentEventsKey:                  eventKey,
+		ReceivedEventsKey:              eventKey,
+		DelegatedKeyRotationCapability: true,
+		IsFrozen:                       true,
+	}
+	path := "/accounts/0x" + receiver + "/resources"
+
+	t.Run("json", func(t *testing.T) {
+		server := newServer(t, map[string]http.HandlerFunc{
+			path: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, restclient.ContentTypeJSON, r.Header.Get("Accept"))
+				respondJSON(resourcesJSON)(w, r)
+			},
+		})
+		account, err := restclient.New(chainID, server.URL).GetAccount(address)
+		require.NoError(t, err)
+		assert.Equal(t, expected.String(), account.String())
+	})
+
+	t.Run("bcs", func(t *testing.T) {
+		server := newServer(t, map[string]http.HandlerFunc{
+			path: func(w http.ResponseWriter, r *http.Request) {
+				assert.True(t, strings.HasPrefix(r.Header.Get("Accept"), restclient.ContentTypeBCS))
+				respond(restclient.ContentTypeBCS, http.StatusOK, string(encodeAccountState(t)))(w, r)
+			},
+		})
+		client := restclient.New(chainID, server.URL, restclient.WithBCS(true))
+		account, err := client.GetAccount(address)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), account.SequenceNumber)
+		assert.Equal(t, eventKey, account.ReceivedEventsKey)
+		assert.Equal(t, []*diemclient.Amount{{Amount: 100, Currency: "XUS"}}, account.Balances)
+		assert.False(t, account.IsFrozen)
+
+		blob, err := client.GetAccountStateBlob(address)
+		require.NoError(t, err)
+		state, err := accountstate.Decode(blob)
+		require.NoError(t, err)
+		balance, err := state.Balance("XUS")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(100), balance)
+	})
+
+	t.Run("bcs not supported by server", func(t *testing.T) {
+		server := newServer(t, map[string]http.HandlerFunc{path: respondJSON(resourcesJSON)})
+		client := restclient.New(chainID, server.URL, restclient.WithBCS(true))
+		account, err := client.GetAccount(address)
+		require.NoError(t, err)
+		assert.Equal(t, expected.String(), account.String())
+
+		_, err = client.GetAccountStateBlob(address)
+		assert.True(t, errors.Is(err, restclient.ErrBCSNotSupported))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		server := newServer(t, map[string]http.HandlerFunc{
+			path: respond(restclient.ContentTypeJSON, http.StatusNotFound, `{"code": 404, "message": "account not found"}`),
+		})
+		client := restclient.New(chainID, server.URL)
+		account, err := client.GetAccount(address)
+		require.NoError(t, err)
+		assert.Nil(t, account)
+		blob, err := client.GetAccountStateBlob(address)
+		require.NoError(t, err)
+		assert.Nil(t, blob)
+	})
+}
+
+func TestGetTransactions(t *testing.T) {
+	server := newServer(t, map[string]http.HandlerFunc{
+		"/transactions": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "42", r.URL.Query().Get("start"))
+			assert.Equal(t, "10", r.URL.Query().Get("limit"))
+			respondJSON("["+transactionJSON+`, {"type": "block_metadata_transaction", "version": "43",
+			  "hash": "0x01", "success": true, "timestamp": "1600000000000001"}]`)(w, r)
+		},
+		"/transactions/0xabcd":                    respondJSON(transactionJSON),
+		"/transactions/0xeeee":                    respondJSON(`{"type": "pending_transaction", "hash": "0xeeee"}`),
+		"/transactions/0xffff":                    respond(restclient.ContentTypeJSON, http.StatusNotFound, `{"code": 404, "message": "not found"}`),
+		"/accounts/0x" + sender + "/transactions": respondJSON("[" + transactionJSON + "]"),
+	})
+	client := restclient.New(chainID, server.URL)
+
+	txns, err := client.GetTransactions(42, 10)
+	require.NoError(t, err)
+	require.Len(t, txns, 2)
+	txn := txns[0]
+	assert.Equal(t, uint64(42), txn.Version)
+	assert.Equal(t, "abcd", txn.Hash)
+	assert.Equal(t, uint64(7), txn.GasUsed)
+	assert.Equal(t, diemclient.VmStatusExecuted, txn.VmStatus.Type)
+	assert.Equal(t, "user", txn.Transaction.Type)
+	assert.Equal(t, sender, txn.Transaction.Sender)
+	assert.Equal(t, uint64(1), txn.Transaction.SequenceNumber)
+	assert.Equal(t, uint32(chainID), txn.Transaction.ChainId)
+	assert.Equal(t, "Scheme::Ed25519", txn.Transaction.SignatureScheme)
+	assert.Equal(t, "0a", txn.Transaction.PublicKey)
+	script := txn.Transaction.Script
+	assert.Equal(t, "script_function", script.Type)
+	assert.Equal(t, "00000000000000000000000000000001", script.ModuleAddress)
+	assert.Equal(t, "peer_to_peer_with_metadata", script.FunctionName)
+	assert.Equal(t, receiver, script.Receiver)
+	assert.Equal(t, uint64(1000), script.Amount)
+	assert.Equal(t, "XUS", script.Currency)
+	assert.Equal(t, "0102", script.Metadata)
+	assert.Equal(t, "", script.MetadataSignature)
+	require.Len(t, txn.Events, 2)
+	event := txn.Events[0]
+	assert.Equal(t, eventKey, event.Key)
+	assert.Equal(t, uint64(42), event.TransactionVersion)
+	assert.Equal(t, "receivedpayment", event.Data.Type)
+	assert.Equal(t, &diemclient.Amount{Amount: 1000, Currency: "XUS"}, event.Data.Amount)
+	assert.Equal(t, sender, event.Data.Sender)
+	assert.Equal(t, receiver, event.Data.Receiver)
+	assert.Equal(t, "unknown", txn.Events[1].Data.Type)
+	assert.Equal(t, "blockmetadata", txns[1].Transaction.Type)
+	assert.Equal(t, uint64(1600000000000001), txns[1].Transaction.TimestampUsecs)
+
+	txn, err = client.GetTransactionByHash("0xabcd")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), txn.Version)
+	txn, err = client.GetTransactionByHash("eeee")
+	require.NoError(t, err)
+	assert.Nil(t, txn)
+	txn, err = client.GetTransactionByHash("ffff")
+	require.NoError(t, err)
+	assert.Nil(t, txn)
+
+	txns, err = client.GetAccountTransactions(diemtypes.MustMakeAccountAddress(sender), 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, txns, 1)
+}
+
+func TestGetEvents(t *testing.T) {
+	server := newServer(t, map[string]http.HandlerFunc{
+		"/events/0x" + eventKey: respondJSON(`[{"key": "0x` + eventKey + `", "sequence_number": "2", "version": "42",
+		  "type": "0x1::DiemAccount::SentPaymentEvent",
+		  "data": {"amount": "1000", "currency_code": "0x585553", "payee": "0x` + sender + `", "metadata": "0x"}}]`),
+	})
+	events, err := restclient.New(chainID, server.URL).GetEvents(eventKey, 2, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(42), events[0].TransactionVersion)
+	assert.Equal(t, "sentpayment", events[0].Data.Type)
+	assert.Equal(t, receiver, events[0].Data.Sender)
+	assert.Equal(t, sender, events[0].Data.Receiver)
+}
+
+func TestSubmitTransaction(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	txn := diemsigner.SignTxn(keys, keys.AccountAddress(), 0,
+		stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			diemtypes.Currency("XUS"), diemtypes.MustMakeAccountAddress(receiver), 100, nil, nil),
+		1_000_000, 0, "XUS", 1600000030, chainID)
+
+	var submitted []byte
+	server := newServer(t, map[string]http.HandlerFunc{
+		"/transactions": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, restclient.ContentTypeSignedTransactionBCS, r.Header.Get("Content-Type"))
+			submitted, _ = ioutil.ReadAll(r.Body)
+			respond(restclient.ContentTypeJSON, http.StatusBadRequest,
+				`{"code": 400, "message": "transaction validation failed: SEQUENCE_NUMBER_TOO_OLD"}`)(w, r)
+		},
+	})
+	err := restclient.New(chainID, server.URL).SubmitTransaction(txn)
+	assert.Equal(t, diemtypes.ToBCS(txn), submitted)
+	assert.True(t, diemclient.IsSequenceNumberTooOld(err))
+}
+
+func TestLedgerStateValidation(t *testing.T) {
+	version := "100"
+	server := newServer(t, map[string]http.HandlerFunc{
+		"/": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(restclient.HeaderLedgerVersion, version)
+			respondJSON(`{"chain_id": 4, "ledger_version": "`+version+`", "ledger_timestamp": "1600000000000000"}`)(w, r)
+		},
+	})
+	var regressions []*diemclient.ChainRegressionError
+	client := restclient.New(chainID, server.URL, restclient.WithDiemClientOptions(
+		diemclient.WithAlerts(nil),
+		diemclient.WithStaleResponseTolerance(0, 0),
+		diemclient.WithChainRegressionTolerance(50, time.Hour),
+		diemclient.WithChainRegressionCallback(func(err *diemclient.ChainRegressionError) {
+			regressions = append(regressions, err)
+		}),
+	))
+	_, err := client.GetMetadata()
+	require.NoError(t, err)
+
+	version = "90"
+	_, err = client.GetMetadata()
+	var stale *diemclient.StaleResponseError
+	require.True(t, errors.As(err, &stale))
+	assert.Equal(t, uint64(90), stale.Server.Version)
+	assert.Equal(t, uint64(100), client.LastResponseLedgerState().Version)
+
+	version = "10"
+	_, err = client.GetMetadata()
+	var regression *diemclient.ChainRegressionError
+	require.True(t, errors.As(err, &regression))
+	assert.Len(t, regressions, 1)
+	assert.Equal(t, regression, client.DiemClient().(diemclient.ChainStateClient).LastChainRegression())
+
+	version = "101"
+	_, err = client.DiemClient().GetMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(101), client.LastResponseLedgerState().Version)
+}
+
+func TestDiemClient(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	txn := diemsigner.SignTxn(keys, keys.AccountAddress(), 1,
+		stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			diemtypes.Currency("XUS"), diemtypes.MustMakeAccountAddress(receiver), 100, nil, nil),
+		1_000_000, 0, "XUS", 1600000030, chainID)
+	var submitted []byte
+	server := newServer(t, map[string]http.HandlerFunc{
+		"/accounts/0x" + receiver + "/resources": respondJSON(resourcesJSON),
+		"/accounts/0x" + sender + "/resources": respond(restclient.ContentTypeJSON, http.StatusNotFound,
+			`{"code": 404, "message": "account not found"}`),
+		"/accounts/0x" + sender + "/transactions": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "1", r.URL.Query().Get("start"))
+			respondJSON("["+transactionJSON+"]")(w, r)
+		},
+		"/transactions": func(w http.ResponseWriter, r *http.Request) {
+			submitted, _ = ioutil.ReadAll(r.Body)
+			respond(restclient.ContentTypeJSON, http.StatusBadRequest,
+				`{"code": 400, "message": "transaction validation failed: SEQUENCE_NUMBER_TOO_OLD"}`)(w, r)
+		},
+	})
+	client := restclient.New(chainID, server.URL, restclient.WithDiemClientOptions(
+		diemclient.WithRetryPolicy(diemclient.RetryPolicy{MaxAttempts: 1})))
+	var diem diemclient.Client = client.DiemClient()
+
+	account, err := diem.GetAccount(diemtypes.MustMakeAccountAddress(receiver))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), account.SequenceNumber)
+	assert.Equal(t, uint64(100), diem.LastResponseLedgerState().Version)
+
+	account, err = diem.GetAccount(diemtypes.MustMakeAccountAddress(sender))
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	txn1, err := diem.GetAccountTransaction(diemtypes.MustMakeAccountAddress(sender), 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), txn1.Version)
+	assert.Empty(t, txn1.Events)
+	txns, err := diem.GetAccountTransactions(diemtypes.MustMakeAccountAddress(sender), 1, 10, true)
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Len(t, txns[0].Events, 2)
+
+	err = diem.SubmitTransaction(txn)
+	assert.Equal(t, diemtypes.ToBCS(txn), submitted)
+	assert.True(t, diemclient.IsSequenceNumberTooOld(err))
+
+	_, err = diem.GetCurrencies()
+	var rpcErr *jsonrpc.ResponseError
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, restclient.ErrCodeMethodNotSupported, rpcErr.Code)
+	_, err = diem.(diemclient.StateClient).GetAccountAtVersion(diemtypes.MustMakeAccountAddress(receiver), 10)
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, restclient.ErrCodeMethodNotSupported, rpcErr.Code)
+}
@@ -0,0 +1,379 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package restclient
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+)
+
+// Resource and event struct tags converted into `diemclient` types
+const (
+	diemAccountType          = "0x1::DiemAccount::DiemAccount"
+	balanceTypePrefix        = "0x1::DiemAccount::Balance<"
+	freezingBitType          = "0x1::AccountFreezing::FreezingBit"
+	receivedPaymentEventType = "0x1::DiemAccount::ReceivedPaymentEvent"
+	sentPaymentEventType     = "0x1::DiemAccount::SentPaymentEvent"
+)
+
+// eventKeyCreationNumberLength is the length of the creation number prefix of the event key,
+// followed by the account address of the event handle
+const eventKeyCreationNumberLength = 8
+
+var moveAbortPattern = regexp.MustCompile(`^Move abort: code (\d+) at (\S+)`)
+
+type diemAccountData struct {
+	AuthenticationKey     HexBytes        `json:"authentication_key"`
+	SequenceNumber        U64             `json:"sequence_number"`
+	SentEvents            eventHandleData `json:"sent_events"`
+	ReceivedEvents        eventHandleData `json:"received_events"`
+	WithdrawCapability    optionData      `json:"withdraw_capability"`
+	KeyRotationCapability optionData      `json:"key_rotation_capability"`
+}
+
+type eventHandleData struct {
+	Counter U64      `json:"counter"`
+	GUID    HexBytes `json:"guid"`
+}
+
+type optionData struct {
+	Vec []json.RawMessage `json:"vec"`
+}
+
+type balanceData struct {
+	Coin struct {
+		Value U64 `json:"value"`
+	} `json:"coin"`
+}
+
+type freezingBitData struct {
+	IsFrozen bool `json:"is_frozen"`
+}
+
+type paymentEventData struct {
+	Amount       U64      `json:"amount"`
+	CurrencyCode string   `json:"currency_code"`
+	Payer        string   `json:"payer"`
+	Payee        string   `json:"payee"`
+	Metadata     HexBytes `json:"metadata"`
+}
+
+// bcsBytes wraps BCS account state bytes as account state blob, i.e. BCS serialized bytes
+func bcsBytes(state []byte) []byte {
+	s := bcs.NewSerializer()
+	_ = s.SerializeBytes(state)
+	return s.GetBytes()
+}
+
+func accountFromStateBlob(address diemtypes.AccountAddress, blob []byte) (*diemclient.Account, error) {
+	state, err := accountstate.Decode(blob)
+	if err != nil {
+		return nil, err
+	}
+	account, err := state.DiemAccount()
+	if err != nil {
+		return nil, err
+	}
+	balances, err := state.Balances()
+	if err != nil {
+		return nil, err
+	}
+	var frozen bool
+	bit, err := state.FreezingBit()
+	if err == nil {
+		frozen = bit.IsFrozen
+	} else if !errors.Is(err, accountstate.ErrResourceNotFound) {
+		return nil, err
+	}
+	return toAccount(address, account, balances, frozen), nil
+}
+
+func accountFromResources(address diemtypes.AccountAddress, resources []*Resource) (*diemclient.Account, error) {
+	var account *accountstate.DiemAccount
+	balances := make(map[string]uint64)
+	var frozen bool
+	for _, r := range resources {
+		switch {
+		case r.Type == diemAccountType:
+			var data diemAccountData
+			if err := json.Unmarshal(r.Data, &data); err != nil {
+				return nil, fmt.Errorf("parse %s resource failed: %v", r.Type, err)
+			}
+			account = &accountstate.DiemAccount{
+				AuthenticationKey: data.AuthenticationKey,
+				SequenceNumber:    uint64(data.SequenceNumber),
+				SentEvents:        accountstate.EventHandle{Count: uint64(data.SentEvents.Counter), Key: data.SentEvents.GUID},
+				ReceivedEvents:    accountstate.EventHandle{Count: uint64(data.ReceivedEvents.Counter), Key: data.ReceivedEvents.GUID},
+			}
+			if len(data.WithdrawCapability.Vec) > 0 {
+				account.WithdrawCapability = &address
+			}
+			if len(data.KeyRotationCapability.Vec) > 0 {
+				account.KeyRotationCapability = &address
+			}
+		case strings.HasPrefix(r.Type, balanceTypePrefix) && strings.HasSuffix(r.Type, ">"):
+			var data balanceData
+			if err := json.Unmarshal(r.Data, &data); err != nil {
+				return nil, fmt.Errorf("parse %s resource failed: %v", r.Type, err)
+			}
+			currency := strings.TrimSuffix(strings.TrimPrefix(r.Type, balanceTypePrefix), ">")
+			balances[structName(currency)] = uint64(data.Coin.Value)
+		case r.Type == freezingBitType:
+			var data freezingBitData
+			if err := json.Unmarshal(r.Data, &data); err != nil {
+				return nil, fmt.Errorf("parse %s resource failed: %v", r.Type, err)
+			}
+			frozen = data.IsFrozen
+		}
+	}
+	if account == nil {
+		return nil, fmt.Errorf("%s resource not found", diemAccountType)
+	}
+	return toAccount(address, account, balances, frozen), nil
+}
+
+func toAccount(address diemtypes.AccountAddress, account *accountstate.DiemAccount, balances map[string]uint64, frozen bool) *diemclient.Account {
+	currencies := make([]string, 0, len(balances))
+	for currency := range balances {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	ret := &diemclient.Account{
+		Address:                        address.Hex(),
+		SequenceNumber:                 account.SequenceNumber,
+		AuthenticationKey:              hex.EncodeToString(account.AuthenticationKey),
+		SentEventsKey:                  account.SentEvents.KeyHex(),
+		ReceivedEventsKey:              account.ReceivedEvents.KeyHex(),
+		DelegatedKeyRotationCapability: account.KeyRotationCapability == nil,
+		DelegatedWithdrawalCapability:  account.WithdrawCapability == nil,
+		IsFrozen:                       frozen,
+	}
+	for _, currency := range currencies {
+		ret.Balances = append(ret.Balances, &diemclient.Amount{Amount: balances[currency], Currency: currency})
+	}
+	return ret
+}
+
+func toTransaction(t *Transaction, chainID byte) (*diemclient.Transaction, error) {
+	ret := &diemclient.Transaction{
+		Version:     uint64(t.Version),
+		Hash:        t.Hash.Hex(),
+		GasUsed:     uint64(t.GasUsed),
+		VmStatus:    toVmStatus(t.Success, t.VMStatus),
+		Transaction: &diemclient.TransactionData{Type: "unknown"},
+	}
+	switch t.Type {
+	case UserTransaction:
+		sender, err := parseAddress(t.Sender)
+		if err != nil {
+			return nil, err
+		}
+		ret.Transaction = &diemclient.TransactionData{
+			Type:                    "user",
+			Sender:                  sender.Hex(),
+			SequenceNumber:          uint64(t.SequenceNumber),
+			ChainId:                 uint32(chainID),
+			MaxGasAmount:            uint64(t.MaxGasAmount),
+			GasUnitPrice:            uint64(t.GasUnitPrice),
+			GasCurrency:             t.GasCurrencyCode,
+			ExpirationTimestampSecs: uint64(t.ExpirationTimestampSecs),
+		}
+		if t.Signature != nil {
+			ret.Transaction.SignatureScheme = signatureScheme(t.Signature.Type)
+			ret.Transaction.PublicKey = t.Signature.PublicKey.Hex()
+			ret.Transaction.Signature = t.Signature.Signature.Hex()
+		}
+		if ret.Transaction.Script, err = toScript(t.Payload); err != nil {
+			return nil, err
+		}
+	case BlockMetadataTransaction:
+		ret.Transaction = &diemclient.TransactionData{Type: "blockmetadata", TimestampUsecs: uint64(t.Timestamp)}
+	case GenesisTransaction:
+		ret.Transaction = &diemclient.TransactionData{Type: "writeset"}
+	}
+	for _, event := range t.Events {
+		e, err := toEvent(event, ret.Version)
+		if err != nil {
+			return nil, err
+		}
+		ret.Events = append(ret.Events, e)
+	}
+	return ret, nil
+}
+
+func toVmStatus(success bool, status string) *diemclient.VmStatus {
+	if success {
+		return &diemclient.VmStatus{Type: diemclient.VmStatusExecuted}
+	}
+	switch {
+	case strings.HasPrefix(status, "Move abort"):
+		ret := &diemclient.VmStatus{Type: diemclient.VmStatusMoveAbort}
+		if m := moveAbortPattern.FindStringSubmatch(status); m != nil {
+			ret.AbortCode, _ = strconv.ParseUint(m[1], 10, 64)
+			ret.Location = m[2]
+		}
+		return ret
+	case strings.HasPrefix(status, "Out of gas"):
+		return &diemclient.VmStatus{Type: diemclient.VmStatusOutOfGas}
+	case strings.HasPrefix(status, "Execution failed"):
+		return &diemclient.VmStatus{Type: diemclient.VmStatusExecutionFailure}
+	default:
+		return &diemclient.VmStatus{Type: diemclient.VmStatusMiscellaneousError}
+	}
+}
+
+func signatureScheme(signatureType string) string {
+	switch signatureType {
+	case "ed25519_signature":
+		return "Scheme::Ed25519"
+	case "multi_ed25519_signature":
+		return "Scheme::MultiEd25519"
+	}
+	return signatureType
+}
+
+// toScript converts script function payload, and fills receiver, amount, currency and metadata
+// for `0x1::PaymentScripts::peer_to_peer_with_metadata`.
+func toScript(p *Payload) (*diemclient.Script, error) {
+	if p == nil {
+		return nil, nil
+	}
+	switch p.Type {
+	case "script_function_payload":
+		parts := strings.Split(p.Function, "::")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid script function %q", p.Function)
+		}
+		module, err := parseAddress(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		ret := &diemclient.Script{
+			Type:          "script_function",
+			ModuleAddress: module.Hex(),
+			ModuleName:    parts[1],
+			FunctionName:  parts[2],
+			TypeArguments: p.TypeArguments,
+		}
+		if parts[1] == "PaymentScripts" && parts[2] == "peer_to_peer_with_metadata" &&
+			len(p.TypeArguments) == 1 && len(p.Arguments) == 4 {
+			if err := fillPeerToPeer(ret, p.Arguments); err != nil {
+				return nil, err
+			}
+		}
+		return ret, nil
+	case "script_payload":
+		ret := &diemclient.Script{Type: "unknown", TypeArguments: p.TypeArguments}
+		if p.Code != nil {
+			ret.Code = p.Code.Bytecode.Hex()
+		}
+		return ret, nil
+	}
+	return &diemclient.Script{Type: "unknown"}, nil
+}
+
+func fillPeerToPeer(script *diemclient.Script, args []json.RawMessage) error {
+	var payee string
+	var amount U64
+	var metadata, signature HexBytes
+	for i, arg := range []interface{}{&payee, &amount, &metadata, &signature} {
+		if err := json.Unmarshal(args[i], arg); err != nil {
+			return fmt.Errorf("parse peer_to_peer_with_metadata argument %d failed: %v", i, err)
+		}
+	}
+	receiver, err := parseAddress(payee)
+	if err != nil {
+		return err
+	}
+	script.Receiver = receiver.Hex()
+	script.Amount = uint64(amount)
+	script.Currency = structName(script.TypeArguments[0])
+	script.Metadata = metadata.Hex()
+	script.MetadataSignature = signature.Hex()
+	return nil
+}
+
+func toEvent(e *Event, version uint64) (*diemclient.Event, error) {
+	ret := &diemclient.Event{
+		Key:                e.Key.Hex(),
+		SequenceNumber:     uint64(e.SequenceNumber),
+		TransactionVersion: version,
+		Data:               &diemclient.EventData{Type: "unknown"},
+	}
+	if e.Type != receivedPaymentEventType && e.Type != sentPaymentEventType {
+		return ret, nil
+	}
+	var data paymentEventData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, fmt.Errorf("parse %s data failed: %v", e.Type, err)
+	}
+	if len(e.Key) != eventKeyCreationNumberLength+diemtypes.AccountAddressLength {
+		return nil, fmt.Errorf("invalid event key %q", e.Key.Hex())
+	}
+	owner := hex.EncodeToString(e.Key[eventKeyCreationNumberLength:])
+	ret.Data = &diemclient.EventData{
+		Amount:   &diemclient.Amount{Amount: uint64(data.Amount), Currency: currencyCode(data.CurrencyCode)},
+		Metadata: data.Metadata.Hex(),
+	}
+	if e.Type == receivedPaymentEventType {
+		sender, err := parseAddress(data.Payer)
+		if err != nil {
+			return nil, err
+		}
+		ret.Data.Type = "receivedpayment"
+		ret.Data.Sender, ret.Data.Receiver = sender.Hex(), owner
+	} else {
+		receiver, err := parseAddress(data.Payee)
+		if err != nil {
+			return nil, err
+		}
+		ret.Data.Type = "sentpayment"
+		ret.Data.Sender, ret.Data.Receiver = owner, receiver.Hex()
+	}
+	return ret, nil
+}
+
+// parseAddress parses "0x" prefixed hex account address, leading zeros may be omitted, e.g. "0x1"
+func parseAddress(address string) (diemtypes.AccountAddress, error) {
+	s := strings.TrimPrefix(address, "0x")
+	if len(s) < diemtypes.AccountAddressLength*2 {
+		s = strings.Repeat("0", diemtypes.AccountAddressLength*2-len(s)) + s
+	}
+	ret, err := diemtypes.MakeAccountAddress(s)
+	if err != nil {
+		return ret, fmt.Errorf("invalid account address %q: %v", address, err)
+	}
+	return ret, nil
+}
+
+// currencyCode decodes currency code of move `vector<u8>`, which is "0x" prefixed hex string
+func currencyCode(code string) string {
+	if !strings.HasPrefix(code, "0x") {
+		return code
+	}
+	bytes, err := hex.DecodeString(code[2:])
+	if err != nil {
+		return code
+	}
+	return string(bytes)
+}
+
+// structName returns struct name of the struct tag, e.g. "XUS" of "0x1::XUS::XUS"
+func structName(tag string) string {
+	if i := strings.LastIndex(tag, "::"); i >= 0 {
+		return tag[i+len("::"):]
+	}
+	return tag
+}
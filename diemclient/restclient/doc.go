@@ -0,0 +1,10 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides Diem REST API (v1) client, it returns same result types with package `diemclient`
+// JSON-RPC client, for applications migrating full nodes off JSON-RPC.
+// Account resources are requested in BCS by content negotiation when enabled by option `WithBCS`,
+// and transactions can be requested in BCS and decoded into `diemtypes` by the BCS methods.
+// Response ledger states are validated same with `diemclient.Client`, and `Client#DiemClient`
+// adapts the REST API client to `diemclient.Client`.
+package restclient
@@ -0,0 +1,28 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error is REST API error response
+type Error struct {
+	// StatusCode is the HTTP response status code
+	StatusCode int    `json:"code"`
+	Message    string `json:"message"`
+	// DiemLedgerVersion is the server ledger version of the error, nil if it is not given
+	DiemLedgerVersion *U64 `json:"diem_ledger_version,omitempty"`
+}
+
+// Error implements error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("rest api error: %d - %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if the error is 404 not found, e.g. account does not exist
+func (e *Error) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,181 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package restclient
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+)
+
+// ErrCodeMethodNotSupported is the JSON-RPC error code returned by `Client#DiemClient` for
+// methods and parameters that have no REST API equivalent, e.g. "get_currencies",
+// "get_state_proof" and the calls at a given ledger version.
+const ErrCodeMethodNotSupported int32 = -32601
+
+// jsonRPCClient implements `jsonrpc.ContextClient` by the REST API client, it is the transport
+// of the `diemclient.Client` returned by `Client#DiemClient`.
+// Every request is translated into REST calls, whose responses are validated by the REST client
+// against the shared ledger state; the JSON-RPC response is created with the client chain id and
+// the last response ledger state, hence it is never stale for the `diemclient.Client`.
+type jsonRPCClient struct {
+	client *client
+}
+
+func (j *jsonRPCClient) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	return j.CallWithContext(context.Background(), requests...)
+}
+
+func (j *jsonRPCClient) CallWithContext(ctx context.Context, requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	ret := make(map[jsonrpc.RequestID]*jsonrpc.Response, len(requests))
+	for _, req := range requests {
+		result, rpcErr, err := j.call(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		id := req.ID
+		state := j.client.LastResponseLedgerState()
+		resp := &jsonrpc.Response{
+			JsonRpc:                 req.JsonRpc,
+			ID:                      &id,
+			DiemChainID:             j.client.chainID,
+			DiemLedgerVersion:       state.Version,
+			DiemLedgerTimestampusec: state.TimestampUsec,
+			Error:                   rpcErr,
+		}
+		if rpcErr == nil {
+			bytes, err := json.Marshal(result)
+			if err != nil {
+				return nil, fmt.Errorf("encode %s result failed: %v", req.Method, err)
+			}
+			// null result is decoded as nil, same with JSON-RPC responses
+			if string(bytes) != "null" {
+				raw := json.RawMessage(bytes)
+				resp.Result = &raw
+			}
+		}
+		ret[req.ID] = resp
+	}
+	return ret, nil
+}
+
+func (j *jsonRPCClient) call(ctx context.Context, req *jsonrpc.Request) (interface{}, *jsonrpc.ResponseError, error) {
+	var (
+		c            = j.client
+		address      diemtypes.AccountAddress
+		start, limit uint64
+		includeEvent bool
+		key, data    string
+	)
+	switch req.Method {
+	case diemclient.GetMetadata:
+		if len(req.Params) != 0 {
+			break
+		}
+		ret, err := c.GetMetadataWithContext(ctx)
+		return ret, nil, err
+	case diemclient.GetAccount:
+		if err := parseParams(req, &address); err != nil {
+			break
+		}
+		ret, err := c.GetAccountWithContext(ctx, address)
+		return ret, nil, err
+	case diemclient.GetAccountTransaction:
+		if err := parseParams(req, &address, &start, &includeEvent); err != nil {
+			break
+		}
+		txns, err := c.GetAccountTransactionsWithContext(ctx, address, start, 1)
+		if err != nil || len(txns) == 0 {
+			return nil, nil, err
+		}
+		return withEvents(txns, includeEvent)[0], nil, nil
+	case diemclient.GetAccountTransactions:
+		if err := parseParams(req, &address, &start, &limit, &includeEvent); err != nil {
+			break
+		}
+		txns, err := c.GetAccountTransactionsWithContext(ctx, address, start, limit)
+		return withEvents(txns, includeEvent), nil, err
+	case diemclient.GetTransactions:
+		if err := parseParams(req, &start, &limit, &includeEvent); err != nil {
+			break
+		}
+		txns, err := c.GetTransactionsWithContext(ctx, start, limit)
+		return withEvents(txns, includeEvent), nil, err
+	case diemclient.GetEvents:
+		if err := parseParams(req, &key, &start, &limit); err != nil {
+			break
+		}
+		ret, err := c.GetEventsWithContext(ctx, key, start, limit)
+		return ret, nil, err
+	case diemclient.GetAccountStateWithProof:
+		if err := parseParams(req, &address); err != nil {
+			break
+		}
+		blob, err := c.GetAccountStateBlobWithContext(ctx, address)
+		return &diemclient.AccountStateWithProof{Blob: hex.EncodeToString(blob)}, nil, err
+	case diemclient.Submit:
+		if err := parseParams(req, &data); err != nil {
+			break
+		}
+		txn, err := decodeSignedTransaction(data)
+		if err != nil {
+			return nil, &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmDeserializationError, Message: err.Error()}, nil
+		}
+		err = c.SubmitTransactionWithContext(ctx, txn)
+		if e, ok := err.(*diemclient.SubmitError); ok {
+			return nil, &jsonrpc.ResponseError{Code: e.Code, Message: e.Message, Data: e.Data}, nil
+		}
+		return nil, nil, err
+	}
+	return nil, &jsonrpc.ResponseError{
+		Code:    ErrCodeMethodNotSupported,
+		Message: fmt.Sprintf("method %s with params %v is not supported by REST API", req.Method, req.Params),
+	}, nil
+}
+
+func decodeSignedTransaction(data string) (*diemtypes.SignedTransaction, error) {
+	bytes, err := hex.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode signed transaction hex failed: %v", err)
+	}
+	txn, err := diemtypes.BcsDeserializeSignedTransaction(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("decode signed transaction bcs failed: %v", err)
+	}
+	return &txn, nil
+}
+
+// parseParams decodes the request params into the given pointers by JSON, the number of params
+// must match.
+func parseParams(req *jsonrpc.Request, ret ...interface{}) error {
+	if len(req.Params) != len(ret) {
+		return fmt.Errorf("expected %d params, but got %d", len(ret), len(req.Params))
+	}
+	for i, param := range req.Params {
+		bytes, err := json.Marshal(param)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(bytes, ret[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withEvents removes transaction events unless includeEvent is true, as REST API always returns
+// transactions with events.
+func withEvents(txns []*diemclient.Transaction, includeEvent bool) []*diemclient.Transaction {
+	if !includeEvent {
+		for _, txn := range txns {
+			txn.Events = nil
+		}
+	}
+	return txns
+}
@@ -0,0 +1,134 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package restclient
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// REST API transaction types
+const (
+	UserTransaction          = "user_transaction"
+	BlockMetadataTransaction = "block_metadata_transaction"
+	GenesisTransaction       = "genesis_transaction"
+	PendingTransaction       = "pending_transaction"
+)
+
+// U64 is uint64 encoded as JSON string by the REST API
+type U64 uint64
+
+// UnmarshalJSON decodes JSON string or number
+func (u *U64) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid u64 %s: %v", string(data), err)
+	}
+	*u = U64(v)
+	return nil
+}
+
+// MarshalJSON encodes as JSON string
+func (u U64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(u), 10))
+}
+
+// HexBytes is bytes encoded as "0x" prefixed hex string by the REST API
+type HexBytes []byte
+
+// UnmarshalJSON decodes hex string with or without "0x" prefix
+func (b *HexBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	bytes, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid hex string %q: %v", s, err)
+	}
+	*b = bytes
+	return nil
+}
+
+// MarshalJSON encodes as "0x" prefixed hex string
+func (b HexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal("0x" + b.Hex())
+}
+
+// Hex returns hex-encoded string without "0x" prefix
+func (b HexBytes) Hex() string {
+	return hex.EncodeToString(b)
+}
+
+// LedgerInfo is the index ("/") endpoint response
+type LedgerInfo struct {
+	ChainID         byte `json:"chain_id"`
+	LedgerVersion   U64  `json:"ledger_version"`
+	LedgerTimestamp U64  `json:"ledger_timestamp"`
+}
+
+// Resource is an account resource of "/accounts/{address}/resources" endpoint response
+type Resource struct {
+	// Type is the resource struct tag, e.g. "0x1::DiemAccount::Balance<0x1::XUS::XUS>"
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Transaction is a transaction of "/transactions" endpoints response
+type Transaction struct {
+	Type     string   `json:"type"`
+	Version  U64      `json:"version"`
+	Hash     HexBytes `json:"hash"`
+	GasUsed  U64      `json:"gas_used"`
+	Success  bool     `json:"success"`
+	VMStatus string   `json:"vm_status"`
+	Events   []*Event `json:"events"`
+	// Timestamp is the block timestamp in microseconds
+	Timestamp U64 `json:"timestamp"`
+
+	// user transaction only
+	Sender                  string     `json:"sender"`
+	SequenceNumber          U64        `json:"sequence_number"`
+	MaxGasAmount            U64        `json:"max_gas_amount"`
+	GasUnitPrice            U64        `json:"gas_unit_price"`
+	GasCurrencyCode         string     `json:"gas_currency_code"`
+	ExpirationTimestampSecs U64        `json:"expiration_timestamp_secs"`
+	Payload                 *Payload   `json:"payload"`
+	Signature               *Signature `json:"signature"`
+}
+
+// Payload is user transaction payload
+type Payload struct {
+	// Type is "script_function_payload", "script_payload" or "write_set_payload"
+	Type string `json:"type"`
+	// Function is the script function id, e.g. "0x1::PaymentScripts::peer_to_peer_with_metadata"
+	Function      string            `json:"function"`
+	TypeArguments []string          `json:"type_arguments"`
+	Arguments     []json.RawMessage `json:"arguments"`
+	Code          *struct {
+		Bytecode HexBytes `json:"bytecode"`
+	} `json:"code"`
+}
+
+// Signature is user transaction signature
+type Signature struct {
+	// Type is "ed25519_signature" or "multi_ed25519_signature"
+	Type      string   `json:"type"`
+	PublicKey HexBytes `json:"public_key"`
+	Signature HexBytes `json:"signature"`
+}
+
+// Event is an event of transaction or "/events/{event_key}" endpoint response
+type Event struct {
+	Key            HexBytes `json:"key"`
+	SequenceNumber U64      `json:"sequence_number"`
+	// Version is the transaction version of the event, only given by the events endpoint
+	Version U64 `json:"version"`
+	// Type is the event struct tag, e.g. "0x1::DiemAccount::ReceivedPaymentEvent"
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
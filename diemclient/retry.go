@@ -0,0 +1,115 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/diem/client-sdk-go/jsonrpc"
+)
+
+// Default retry policy: retries `IsRetryable` errors with jittered exponential backoff.
+const (
+	DefaultRetryMaxAttempts uint = 5
+	DefaultRetryBaseDelay        = 100 * time.Millisecond
+	DefaultRetryMaxDelay         = 2 * time.Second
+)
+
+// BackoffFunc returns delay before the next attempt; attempt starts from 0 for the delay after
+// the first failed attempt.
+type BackoffFunc func(attempt uint) time.Duration
+
+// RetryPolicy configures how the client retries failed calls.
+// Submit is never retried, because a retried submission may be a duplicate.
+type RetryPolicy struct {
+	// MaxAttempts is max number of attempts including the first call, 1 disables retry.
+	MaxAttempts uint
+	Backoff     BackoffFunc
+	// Retryable classifies errors, only retryable errors are retried.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy returns policy with `DefaultRetryMaxAttempts`, `ExponentialBackoff` of
+// `DefaultRetryBaseDelay` and `DefaultRetryMaxDelay`, and `IsRetryable` classifier.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: DefaultRetryMaxAttempts,
+		Backoff:     ExponentialBackoff(DefaultRetryBaseDelay, DefaultRetryMaxDelay),
+		Retryable:   IsRetryable,
+	}
+}
+
+// NoRetryPolicy returns policy that never retries
+func NoRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// ExponentialBackoff returns "full jitter" exponential backoff: a random delay between 0 and
+// min(max, base * 2^attempt).
+func ExponentialBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt uint) time.Duration {
+		delay := max
+		if attempt < 32 && base<<attempt > 0 && base<<attempt < max {
+			delay = base << attempt
+		}
+		if delay <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+}
+
+// IsRetryable returns true for `*StaleResponseError`, `*ChainResetError` of ledger regression
+// and transient network failures: http call errors, read response body errors and `net.Error`.
+// Canceled context, `*ChainRegressionError`, `*ChainResetError` of chain id change and JSON-RPC
+// server errors are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch e := err.(type) {
+	case *StaleResponseError:
+		return true
+	case *ChainResetError:
+		return e.Reason == ChainResetLedgerRegression
+	}
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.ErrorType == jsonrpc.HttpCallError ||
+			rpcErr.ErrorType == jsonrpc.ReadHttpResponseBodyError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// options converts the policy to retry-go options
+func (p RetryPolicy) options() []retry.Option {
+	attempts := p.MaxAttempts
+	if attempts == 0 {
+		attempts = 1
+	}
+	opts := []retry.Option{retry.Attempts(attempts)}
+	if p.Backoff != nil {
+		opts = append(opts, retry.DelayType(func(n uint, _ error, _ *retry.Config) time.Duration {
+			return p.Backoff(n)
+		}))
+	}
+	if p.Retryable != nil {
+		opts = append(opts, retry.RetryIf(p.Retryable))
+	}
+	return opts
+}
+
+// WithRetryPolicy sets retry policy of the client, default is `DefaultRetryPolicy()`.
+// Options appended by `Client.WithRetryOptions` override the policy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *client) {
+		c.retryPolicy = policy
+	}
+}
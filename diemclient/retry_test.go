@@ -0,0 +1,98 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyStub fails first `failures` calls with given error
+type flakyStub struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (s *flakyStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, s.err
+	}
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{}}
+	return stub.Call(requests...)
+}
+
+func noDelay(uint) time.Duration { return 0 }
+
+func TestRetryPolicy(t *testing.T) {
+	networkErr := &jsonrpc.Error{ErrorType: jsonrpc.HttpCallError, Cause: errors.New("connection reset")}
+	policy := diemclient.RetryPolicy{MaxAttempts: 3, Backoff: noDelay, Retryable: diemclient.IsRetryable}
+
+	t.Run("retry transient failures", func(t *testing.T) {
+		rpc := &flakyStub{failures: 2, err: networkErr}
+		client := diemclient.NewWithJsonRpcClient(2, rpc, diemclient.WithRetryPolicy(policy))
+		_, err := client.GetCurrencies()
+		require.NoError(t, err)
+		assert.Equal(t, 3, rpc.calls)
+	})
+	t.Run("give up after max attempts", func(t *testing.T) {
+		rpc := &flakyStub{failures: 3, err: networkErr}
+		client := diemclient.NewWithJsonRpcClient(2, rpc, diemclient.WithRetryPolicy(policy))
+		_, err := client.GetCurrencies()
+		assert.Equal(t, networkErr, err)
+		assert.Equal(t, 3, rpc.calls)
+	})
+	t.Run("do not retry non-retryable error", func(t *testing.T) {
+		rpc := &flakyStub{failures: 1, err: errors.New("invalid params")}
+		client := diemclient.NewWithJsonRpcClient(2, rpc, diemclient.WithRetryPolicy(policy))
+		_, err := client.GetCurrencies()
+		assert.EqualError(t, err, "invalid params")
+		assert.Equal(t, 1, rpc.calls)
+	})
+	t.Run("no retry policy", func(t *testing.T) {
+		rpc := &flakyStub{failures: 1, err: networkErr}
+		client := diemclient.NewWithJsonRpcClient(2, rpc, diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		_, err := client.GetCurrencies()
+		assert.Error(t, err)
+		assert.Equal(t, 1, rpc.calls)
+	})
+	t.Run("submit is not retried", func(t *testing.T) {
+		rpc := &flakyStub{failures: 1, err: networkErr}
+		client := diemclient.NewWithJsonRpcClient(2, rpc, diemclient.WithRetryPolicy(policy))
+		assert.Error(t, client.Submit("00"))
+		assert.Equal(t, 1, rpc.calls)
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, diemclient.IsRetryable(&diemclient.StaleResponseError{}))
+	assert.True(t, diemclient.IsRetryable(&jsonrpc.Error{ErrorType: jsonrpc.ReadHttpResponseBodyError, Cause: errors.New("EOF")}))
+	assert.False(t, diemclient.IsRetryable(&jsonrpc.Error{ErrorType: jsonrpc.ParseResponseJsonError, Cause: errors.New("EOF")}))
+	assert.False(t, diemclient.IsRetryable(&jsonrpc.Error{ErrorType: jsonrpc.HttpCallError, Cause: context.Canceled}))
+	assert.False(t, diemclient.IsRetryable(&diemclient.ChainRegressionError{}))
+	assert.True(t, diemclient.IsRetryable(&diemclient.ChainResetError{Reason: diemclient.ChainResetLedgerRegression}))
+	assert.False(t, diemclient.IsRetryable(&diemclient.ChainResetError{Reason: diemclient.ChainResetChainIDChanged}))
+	assert.False(t, diemclient.IsRetryable(nil))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := diemclient.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	for attempt := uint(0); attempt < 100; attempt++ {
+		delay := backoff(attempt)
+		assert.True(t, delay >= 0)
+		assert.True(t, delay <= 50*time.Millisecond)
+		if attempt == 0 {
+			assert.True(t, delay <= 10*time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// ParentVASPRole is role data of parent VASP account
+type ParentVASPRole struct {
+	HumanName string
+	BaseURL   string
+	// ComplianceKey is hex-encoded ed25519 public key
+	ComplianceKey  string
+	ExpirationTime uint64
+	NumChildren    uint64
+	// ComplianceKeyRotationEventsKey and BaseURLRotationEventsKey are hex-encoded event keys
+	ComplianceKeyRotationEventsKey string
+	BaseURLRotationEventsKey       string
+}
+
+// ChildVASPRole is role data of child VASP account
+type ChildVASPRole struct {
+	ParentVASPAddress diemtypes.AccountAddress
+}
+
+// IsChildOf returns true if the given address is the parent VASP address
+func (r *ChildVASPRole) IsChildOf(parent diemtypes.AccountAddress) bool {
+	return r.ParentVASPAddress == parent
+}
+
+// DesignatedDealerRole is role data of designated dealer account
+type DesignatedDealerRole struct {
+	HumanName string
+	BaseURL   string
+	// ComplianceKey is hex-encoded ed25519 public key
+	ComplianceKey  string
+	ExpirationTime uint64
+	// ReceivedMintEventsKey is hex-encoded event key
+	ReceivedMintEventsKey string
+	PreburnBalances       []*Amount
+	PreburnQueues         []*PreburnQueue
+}
+
+// PreburnBalance returns preburn balance amount of the given currency code, returns 0 if
+// there is no preburn balance of the currency.
+func (r *DesignatedDealerRole) PreburnBalance(currency string) uint64 {
+	for _, balance := range r.PreburnBalances {
+		if balance.Currency == currency {
+			return balance.Amount
+		}
+	}
+	return 0
+}
+
+// AccountInfo is typed view of `Account`, exactly one of the `ParentVASP`, `ChildVASP` and
+// `DesignatedDealer` is not nil if the account has the role; all of them are nil for other roles.
+type AccountInfo struct {
+	Address diemtypes.AccountAddress
+	// RoleType is the role type string, e.g. "parent_vasp", "unknown"
+	RoleType         string
+	ParentVASP       *ParentVASPRole
+	ChildVASP        *ChildVASPRole
+	DesignatedDealer *DesignatedDealerRole
+	// Account is the `get_account` method response the info is created from
+	Account *Account
+}
+
+// NewAccountInfo creates `AccountInfo` from `get_account` method response, returns error if the
+// account address or role data is invalid.
+func NewAccountInfo(account *Account) (*AccountInfo, error) {
+	address, err := diemtypes.MakeAccountAddress(account.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address %#v: %v", account.Address, err)
+	}
+	ret := AccountInfo{Address: address, RoleType: AccountRoleUnknown, Account: account}
+	role := account.Role
+	if role == nil {
+		return &ret, nil
+	}
+	ret.RoleType = role.Type
+	switch role.Type {
+	case AccountRoleParentVASP:
+		ret.ParentVASP = &ParentVASPRole{
+			HumanName:                      role.HumanName,
+			BaseURL:                        role.BaseUrl,
+			ComplianceKey:                  role.ComplianceKey,
+			ExpirationTime:                 role.ExpirationTime,
+			NumChildren:                    role.NumChildren,
+			ComplianceKeyRotationEventsKey: role.ComplianceKeyRotationEventsKey,
+			BaseURLRotationEventsKey:       role.BaseUrlRotationEventsKey,
+		}
+	case AccountRoleChildVASP:
+		parent, err := diemtypes.MakeAccountAddress(role.ParentVaspAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent vasp address %#v: %v", role.ParentVaspAddress, err)
+		}
+		ret.ChildVASP = &ChildVASPRole{ParentVASPAddress: parent}
+	case AccountRoleDesignatedDealer:
+		ret.DesignatedDealer = &DesignatedDealerRole{
+			HumanName:             role.HumanName,
+			BaseURL:               role.BaseUrl,
+			ComplianceKey:         role.ComplianceKey,
+			ExpirationTime:        role.ExpirationTime,
+			ReceivedMintEventsKey: role.ReceivedMintEventsKey,
+			PreburnBalances:       role.PreburnBalances,
+			PreburnQueues:         role.PreburnQueues,
+		}
+	}
+	return &ret, nil
+}
+
+// GetAccountInfo gets account by the given address and converts it into `AccountInfo`.
+// Returns nil without error if the account is not found.
+func GetAccountInfo(c Client, address diemtypes.AccountAddress) (*AccountInfo, error) {
+	account, err := c.GetAccount(address)
+	if err != nil || account == nil {
+		return nil, err
+	}
+	return NewAccountInfo(account)
+}
+
+// IsVASP returns true if the account is a parent or child VASP account
+func (a *AccountInfo) IsVASP() bool {
+	return a.ParentVASP != nil || a.ChildVASP != nil
+}
+
+// IsChildOf returns true if the account is a child VASP account of the given parent VASP address
+func (a *AccountInfo) IsChildOf(parent diemtypes.AccountAddress) bool {
+	return a.ChildVASP != nil && a.ChildVASP.IsChildOf(parent)
+}
+
+// ParentVASPAddress returns the account address for parent VASP account, and the parent VASP
+// address for child VASP account. Returns false if the account is not a VASP account.
+func (a *AccountInfo) ParentVASPAddress() (diemtypes.AccountAddress, bool) {
+	switch {
+	case a.ParentVASP != nil:
+		return a.Address, true
+	case a.ChildVASP != nil:
+		return a.ChildVASP.ParentVASPAddress, true
+	}
+	return diemtypes.AccountAddress{}, false
+}
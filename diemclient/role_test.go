@@ -0,0 +1,113 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/avast/retry-go"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const designatedDealerAccount = `{
+  "address": "000000000000000000000000000000dd",
+  "role": {
+    "type": "designated_dealer",
+    "human_name": "dd",
+    "base_url": "http://dd.com",
+    "expiration_time": 18446744073709551615,
+    "compliance_key": "447fc3be296803c2303951c7816624c7566730a5cc6860a4a1bd3c04731569f5",
+    "received_mint_events_key": "0000000000000000000000000000000000000000000000dd",
+    "preburn_balances": [
+      {"amount": 100, "currency": "XUS"},
+      {"amount": 0, "currency": "XDX"}
+    ],
+    "preburn_queues": [
+      {"currency": "XUS", "preburns": [{"preburn": {"amount": 100, "currency": "XUS"}, "metadata": "01"}]}
+    ]
+  }
+}`
+
+func TestGetAccountInfo(t *testing.T) {
+	parentAddress := diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	getAccountInfo := func(t *testing.T, account string) *diemclient.AccountInfo {
+		stub := &sequenceStub{results: []json.RawMessage{json.RawMessage(account)}}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub).WithRetryOptions(retry.Attempts(1))
+		ret, err := diemclient.GetAccountInfo(client, parentAddress)
+		require.NoError(t, err)
+		return ret
+	}
+
+	t.Run("parent vasp", func(t *testing.T) {
+		info := getAccountInfo(t, parentVASPAccount)
+		assert.Equal(t, parentAddress, info.Address)
+		assert.Equal(t, diemclient.AccountRoleParentVASP, info.RoleType)
+		assert.Equal(t, &diemclient.ParentVASPRole{
+			HumanName:                      "vasp",
+			BaseURL:                        "http://vasp.com",
+			ComplianceKey:                  "447fc3be296803c2303951c7816624c7566730a5cc6860a4a1bd3c04731569f5",
+			ComplianceKeyRotationEventsKey: "0000000000000000f72589b71ff4f8d139674a3f7369c69b",
+		}, info.ParentVASP)
+		assert.Nil(t, info.ChildVASP)
+		assert.Nil(t, info.DesignatedDealer)
+		assert.True(t, info.IsVASP())
+		assert.False(t, info.IsChildOf(parentAddress))
+		address, ok := info.ParentVASPAddress()
+		assert.True(t, ok)
+		assert.Equal(t, parentAddress, address)
+	})
+	t.Run("child vasp", func(t *testing.T) {
+		info := getAccountInfo(t, childVASPAccount)
+		assert.Equal(t, diemclient.AccountRoleChildVASP, info.RoleType)
+		assert.Equal(t, &diemclient.ChildVASPRole{ParentVASPAddress: parentAddress}, info.ChildVASP)
+		assert.Nil(t, info.ParentVASP)
+		assert.True(t, info.IsVASP())
+		assert.True(t, info.IsChildOf(parentAddress))
+		assert.False(t, info.IsChildOf(info.Address))
+		address, ok := info.ParentVASPAddress()
+		assert.True(t, ok)
+		assert.Equal(t, parentAddress, address)
+	})
+	t.Run("designated dealer", func(t *testing.T) {
+		info := getAccountInfo(t, designatedDealerAccount)
+		assert.Equal(t, diemclient.AccountRoleDesignatedDealer, info.RoleType)
+		require.NotNil(t, info.DesignatedDealer)
+		dd := info.DesignatedDealer
+		assert.Equal(t, "dd", dd.HumanName)
+		assert.Equal(t, "http://dd.com", dd.BaseURL)
+		assert.Equal(t, uint64(18446744073709551615), dd.ExpirationTime)
+		assert.Equal(t, "0000000000000000000000000000000000000000000000dd", dd.ReceivedMintEventsKey)
+		assert.Equal(t, uint64(100), dd.PreburnBalance("XUS"))
+		assert.Equal(t, uint64(0), dd.PreburnBalance("XDX"))
+		assert.Equal(t, uint64(0), dd.PreburnBalance("XXX"))
+		require.Len(t, dd.PreburnQueues, 1)
+		assert.Equal(t, "01", dd.PreburnQueues[0].Preburns[0].Metadata)
+		assert.False(t, info.IsVASP())
+		_, ok := info.ParentVASPAddress()
+		assert.False(t, ok)
+	})
+	t.Run("no role", func(t *testing.T) {
+		info := getAccountInfo(t, `{"address": "f72589b71ff4f8d139674a3f7369c69b"}`)
+		assert.Equal(t, diemclient.AccountRoleUnknown, info.RoleType)
+		assert.False(t, info.IsVASP())
+	})
+	t.Run("account not found", func(t *testing.T) {
+		assert.Nil(t, getAccountInfo(t, "null"))
+	})
+}
+
+func TestNewAccountInfoError(t *testing.T) {
+	_, err := diemclient.NewAccountInfo(&diemclient.Account{Address: "invalid"})
+	assert.Error(t, err)
+	_, err = diemclient.NewAccountInfo(&diemclient.Account{
+		Address: "f72589b71ff4f8d139674a3f7369c69b",
+		Role:    &diemclient.AccountRole{Type: diemclient.AccountRoleChildVASP, ParentVaspAddress: "invalid"},
+	})
+	assert.EqualError(t, err, `invalid parent vasp address "invalid": encoding/hex: invalid byte: U+0069 'i'`)
+}
@@ -0,0 +1,257 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// DefaultSequenceNumberRetries is default max number of resubmissions after sequence number
+// is refreshed
+const DefaultSequenceNumberRetries = 3
+
+// SequenceNumberManager tracks and reserves sequence numbers of sender accounts locally, so that
+// many transactions can be submitted from one account concurrently without racing on
+// `GetAccount`. Sequence number is loaded from chain on first reservation and after `Reset`.
+//
+// A reserved sequence number stays in flight until it is confirmed by `Confirm` (the transaction
+// is executed), given back by `Release` (the transaction is not submitted, rejected or expired),
+// or the submitted transaction expires by the client last response ledger time. Released
+// sequence numbers are reused by next reservations, lowest first, before new sequence numbers
+// are reserved, so that a gap does not hold transactions of later sequence numbers in mempool.
+// An expired sequence number may or may not be executed, it is closed by reloading sequence
+// number from chain once nothing is in flight. Sequence number reloaded from chain is never
+// below sequence numbers in flight or confirmed, as a transaction accepted by mempool does not
+// bump the on-chain sequence number until it is executed.
+type SequenceNumberManager struct {
+	Client  Client
+	Retries int
+
+	mux      sync.Mutex
+	accounts map[diemtypes.AccountAddress]*sequenceState
+}
+
+type sequenceState struct {
+	mux      sync.Mutex
+	loaded   bool
+	stale    bool
+	next     uint64
+	executed uint64
+	// inFlight maps reserved sequence numbers to expiration timestamp seconds of the submitted
+	// transactions, 0 if the transaction is not submitted yet
+	inFlight map[uint64]uint64
+	// released is the sequence numbers below next given back by `Release`
+	released map[uint64]struct{}
+}
+
+// NewSequenceNumberManager creates `SequenceNumberManager`
+func NewSequenceNumberManager(client Client) *SequenceNumberManager {
+	return &SequenceNumberManager{
+		Client:   client,
+		Retries:  DefaultSequenceNumberRetries,
+		accounts: make(map[diemtypes.AccountAddress]*sequenceState),
+	}
+}
+
+// Reserve reserves the lowest released sequence number of given account, or next sequence
+// number if there is none released.
+func (m *SequenceNumberManager) Reserve(ctx context.Context, address diemtypes.AccountAddress) (uint64, error) {
+	state := m.state(address)
+	state.mux.Lock()
+	defer state.mux.Unlock()
+	m.expire(state)
+	if !state.loaded || (state.stale && len(state.inFlight) == 0) {
+		account, err := AsContextClient(m.Client).GetAccountWithContext(ctx, address)
+		if err != nil {
+			return 0, err
+		}
+		if account == nil {
+			return 0, fmt.Errorf("account %s not found", address.Hex())
+		}
+		state.next = state.floor()
+		if account.SequenceNumber > state.next {
+			state.next = account.SequenceNumber
+		}
+		for seq := range state.released {
+			if seq < account.SequenceNumber || seq >= state.next {
+				delete(state.released, seq)
+			}
+		}
+		state.loaded = true
+		state.stale = false
+	}
+	seq, ok := state.lowestReleased()
+	if ok {
+		delete(state.released, seq)
+	} else {
+		seq = state.next
+		state.next++
+	}
+	state.inFlight[seq] = 0
+	return seq, nil
+}
+
+// Confirm marks the reserved sequence number is used on chain, e.g. the transaction is executed
+func (m *SequenceNumberManager) Confirm(address diemtypes.AccountAddress, seq uint64) {
+	state := m.state(address)
+	state.mux.Lock()
+	defer state.mux.Unlock()
+	delete(state.inFlight, seq)
+	delete(state.released, seq)
+	if seq >= state.executed {
+		state.executed = seq + 1
+	}
+}
+
+// Release gives back the reserved sequence number that is not used by any transaction executed
+// or pending on chain, e.g. the transaction is rejected or expired. It is reused by next
+// reservation before any sequence number above it.
+func (m *SequenceNumberManager) Release(address diemtypes.AccountAddress, seq uint64) {
+	state := m.state(address)
+	state.mux.Lock()
+	defer state.mux.Unlock()
+	if _, ok := state.inFlight[seq]; !ok {
+		// confirmed or expired sequence number
+		if state.loaded && seq < state.next {
+			state.stale = true
+		}
+		return
+	}
+	delete(state.inFlight, seq)
+	state.released[seq] = struct{}{}
+	// shrink next while the last reserved sequence numbers are released
+	for state.next > 0 {
+		if _, ok := state.released[state.next-1]; !ok {
+			break
+		}
+		state.next--
+		delete(state.released, state.next)
+	}
+}
+
+// Reset drops local sequence number of given account, it is reloaded from chain by next
+// reservation. The reloaded sequence number is not below sequence numbers in flight or
+// confirmed.
+func (m *SequenceNumberManager) Reset(address diemtypes.AccountAddress) {
+	state := m.state(address)
+	state.mux.Lock()
+	defer state.mux.Unlock()
+	state.loaded = false
+}
+
+// SubmitWithSequenceNumber reserves sequence number, creates signed transaction by `sign` and
+// submits it.
+//
+// The sequence number of the submitted transaction stays in flight until `Confirm` is called
+// after the transaction is executed, or the transaction expires. When the submission failed by
+// sequence number too old, the sequence number is confirmed as it is used on chain, and the
+// account sequence number is reloaded from chain and the transaction is signed and submitted
+// with a new sequence number, up to `Retries` times. When the transaction is rejected for other
+// reasons, including sequence number too new, the reserved sequence number is released and the
+// error is returned. When the submission result is unknown, e.g. network error, or mempool has
+// a transaction of the sequence number, the sequence number stays in flight until the
+// transaction expires.
+func (m *SequenceNumberManager) SubmitWithSequenceNumber(
+	ctx context.Context,
+	address diemtypes.AccountAddress,
+	sign func(sequenceNumber uint64) (*diemtypes.SignedTransaction, error),
+) (*diemtypes.SignedTransaction, error) {
+	for attempt := 0; ; attempt++ {
+		seq, err := m.Reserve(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		txn, err := sign(seq)
+		if err != nil {
+			m.Release(address, seq)
+			return nil, err
+		}
+		err = AsContextClient(m.Client).SubmitTransactionWithContext(ctx, txn)
+		if err == nil {
+			m.submitted(address, seq, txn.RawTxn.ExpirationTimestampSecs)
+			return txn, nil
+		}
+		var submitErr *SubmitError
+		switch {
+		case IsSequenceNumberTooOld(err):
+			m.Confirm(address, seq)
+			m.Reset(address)
+			if attempt >= m.Retries {
+				return nil, err
+			}
+		case errors.As(err, &submitErr) && submitErr.Code != ErrCodeMempoolInvalidUpdate:
+			m.Release(address, seq)
+			return nil, err
+		default:
+			m.submitted(address, seq, txn.RawTxn.ExpirationTimestampSecs)
+			return nil, err
+		}
+	}
+}
+
+// submitted records expiration time of the transaction submitted with the in flight sequence
+// number.
+func (m *SequenceNumberManager) submitted(address diemtypes.AccountAddress, seq uint64, expirationTimeSec uint64) {
+	state := m.state(address)
+	state.mux.Lock()
+	defer state.mux.Unlock()
+	if _, ok := state.inFlight[seq]; ok {
+		state.inFlight[seq] = expirationTimeSec
+	}
+}
+
+// expire drops in flight sequence numbers of submitted transactions expired by the client last
+// response ledger time, and reloads sequence number from chain once nothing is in flight.
+func (m *SequenceNumberManager) expire(state *sequenceState) {
+	now := m.Client.LastResponseLedgerState().TimestampUsec
+	for seq, expiration := range state.inFlight {
+		if expiration > 0 && expiration*1_000_000 <= now {
+			delete(state.inFlight, seq)
+			state.stale = true
+		}
+	}
+}
+
+// lowestReleased returns the lowest released sequence number not confirmed, released sequence
+// numbers below confirmed ones are dropped.
+func (s *sequenceState) lowestReleased() (uint64, bool) {
+	var ret uint64
+	found := false
+	for seq := range s.released {
+		if seq < s.executed {
+			delete(s.released, seq)
+		} else if !found || seq < ret {
+			ret, found = seq, true
+		}
+	}
+	return ret, found
+}
+
+// floor returns the min sequence number to reserve: next to the sequence numbers in flight and
+// confirmed.
+func (s *sequenceState) floor() uint64 {
+	ret := s.executed
+	for seq := range s.inFlight {
+		if seq >= ret {
+			ret = seq + 1
+		}
+	}
+	return ret
+}
+
+func (m *SequenceNumberManager) state(address diemtypes.AccountAddress) *sequenceState {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	state, ok := m.accounts[address]
+	if !ok {
+		state = &sequenceState{inFlight: make(map[uint64]uint64), released: make(map[uint64]struct{})}
+		m.accounts[address] = state
+	}
+	return state
+}
@@ -0,0 +1,225 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceChain serves get_account with on-chain sequence number, and rejects submitted
+// transactions with sequence number less than it, or not less than tooNew when it is set.
+type sequenceChain struct {
+	mux         sync.Mutex
+	sequence    uint64
+	tooNew      uint64
+	timestamp   uint64
+	getAccounts int
+	submitted   []uint64
+}
+
+func (s *sequenceChain) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	req := requests[0]
+	resp := jsonrpc.Response{DiemLedgerTimestampusec: s.timestamp * 1_000_000}
+	switch req.Method {
+	case diemclient.GetAccount:
+		s.getAccounts++
+		result := json.RawMessage(fmt.Sprintf(`{"sequence_number": %d}`, s.sequence))
+		resp.Result = &result
+	case diemclient.Submit:
+		txn, _ := diemtypes.BcsDeserializeSignedTransaction(mustDecodeHex(req.Params[0].(string)))
+		if txn.RawTxn.SequenceNumber < s.sequence {
+			resp.Error = &jsonrpc.ResponseError{Code: -32001, Message: "Server error: VM Validation error: SEQUENCE_NUMBER_TOO_OLD"}
+		} else if s.tooNew > 0 && txn.RawTxn.SequenceNumber >= s.tooNew {
+			resp.Error = &jsonrpc.ResponseError{Code: -32001, Message: "Server error: VM Validation error: SEQUENCE_NUMBER_TOO_NEW"}
+		} else {
+			s.submitted = append(s.submitted, txn.RawTxn.SequenceNumber)
+		}
+	}
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{req.ID: resp}}
+	return stub.Call(requests...)
+}
+
+func TestSequenceNumberManager(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	address := keys.AccountAddress()
+	chain := &sequenceChain{sequence: 5}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, chain)
+	manager := diemclient.NewSequenceNumberManager(client)
+	sign := func(seq uint64) (*diemtypes.SignedTransaction, error) {
+		return diemsigner.Sign(keys, address, seq,
+			stdlib.EncodePeerToPeerWithMetadataScript(testnet.XUS, address, 1, nil, nil),
+			1_000_000, 0, "XUS", uint64(time.Now().Add(time.Minute).Unix()), testnet.ChainID), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := manager.SubmitWithSequenceNumber(context.Background(), address, sign)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, chain.getAccounts)
+	assert.ElementsMatch(t, []uint64{5, 6, 7, 8, 9, 10, 11, 12, 13, 14}, chain.submitted)
+
+	// transactions are submitted by another process, local sequence number is too old
+	chain.sequence = 20
+	txn, err := manager.SubmitWithSequenceNumber(context.Background(), address, sign)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), txn.RawTxn.SequenceNumber)
+	assert.Equal(t, 2, chain.getAccounts)
+
+	seq, err := manager.Reserve(context.Background(), address)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(21), seq)
+}
+
+func TestSequenceNumberManagerTooNew(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	address := keys.AccountAddress()
+	chain := &sequenceChain{sequence: 5, tooNew: 7}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, chain)
+	manager := diemclient.NewSequenceNumberManager(client)
+	sign := func(seq uint64) (*diemtypes.SignedTransaction, error) {
+		return diemsigner.Sign(keys, address, seq,
+			stdlib.EncodePeerToPeerWithMetadataScript(testnet.XUS, address, 1, nil, nil),
+			1_000_000, 0, "XUS", uint64(time.Now().Add(time.Minute).Unix()), testnet.ChainID), nil
+	}
+
+	// 5 is in flight, 6 is accepted, 7 is rejected as too new
+	inFlight, err := manager.Reserve(context.Background(), address)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), inFlight)
+	txn, err := manager.SubmitWithSequenceNumber(context.Background(), address, sign)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), txn.RawTxn.SequenceNumber)
+	_, err = manager.SubmitWithSequenceNumber(context.Background(), address, sign)
+	assert.True(t, errors.Is(err, diemclient.ErrSequenceNumberTooNew))
+	assert.Equal(t, 1, chain.getAccounts)
+	assert.Equal(t, []uint64{6}, chain.submitted)
+
+	// released 7 is the last reserved, it is reused without reloading from chain
+	seq, err := manager.Reserve(context.Background(), address)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), seq)
+	manager.Release(address, seq)
+	assert.Equal(t, 1, chain.getAccounts)
+}
+
+func TestSequenceNumberManagerRelease(t *testing.T) {
+	address := diemkeys.MustGenKeys().AccountAddress()
+	chain := &sequenceChain{sequence: 5}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, chain)
+	manager := diemclient.NewSequenceNumberManager(client)
+	reserve := func() uint64 {
+		seq, err := manager.Reserve(context.Background(), address)
+		require.NoError(t, err)
+		return seq
+	}
+
+	first, second, third := reserve(), reserve(), reserve()
+	assert.Equal(t, []uint64{5, 6, 7}, []uint64{first, second, third})
+
+	// a released sequence number in the middle is reused before advancing
+	manager.Release(address, second)
+	assert.Equal(t, uint64(6), reserve())
+	assert.Equal(t, uint64(8), reserve())
+
+	// released sequence numbers are reused lowest first
+	manager.Release(address, third)
+	manager.Release(address, second)
+	assert.Equal(t, []uint64{6, 7, 9}, []uint64{reserve(), reserve(), reserve()})
+	assert.Equal(t, 1, chain.getAccounts)
+
+	// released sequence numbers below a confirmed one are not reused
+	manager.Release(address, 6)
+	manager.Confirm(address, 7)
+	assert.Equal(t, uint64(10), reserve())
+
+	// releasing the last reserved sequence numbers moves next back
+	manager.Release(address, 9)
+	manager.Release(address, 10)
+	assert.Equal(t, uint64(9), reserve())
+
+	// releasing sequence number not reserved yet is ignored
+	manager.Release(address, 12)
+	assert.Equal(t, uint64(10), reserve())
+	assert.Equal(t, 1, chain.getAccounts)
+}
+
+func TestSequenceNumberManagerInFlightUntilExecuted(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	address := keys.AccountAddress()
+	chain := &sequenceChain{sequence: 5, timestamp: 1000}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, chain)
+	manager := diemclient.NewSequenceNumberManager(client)
+	sign := func(seq uint64) (*diemtypes.SignedTransaction, error) {
+		return diemsigner.Sign(keys, address, seq,
+			stdlib.EncodePeerToPeerWithMetadataScript(testnet.XUS, address, 1, nil, nil),
+			1_000_000, 0, "XUS", 2000, testnet.ChainID), nil
+	}
+	reserve := func() uint64 {
+		seq, err := manager.Reserve(context.Background(), address)
+		require.NoError(t, err)
+		return seq
+	}
+
+	// 5 and 6 are accepted by mempool, on-chain sequence number is not changed until executed
+	for _, expected := range []uint64{5, 6} {
+		txn, err := manager.SubmitWithSequenceNumber(context.Background(), address, sign)
+		require.NoError(t, err)
+		assert.Equal(t, expected, txn.RawTxn.SequenceNumber)
+	}
+	manager.Reset(address)
+	assert.Equal(t, uint64(7), reserve(), "never reload below sequence numbers in flight")
+	assert.Equal(t, 2, chain.getAccounts)
+
+	// 7 is released as the gap of 8 and reused, the pending transactions stay in flight until
+	// executed
+	assert.Equal(t, uint64(8), reserve())
+	manager.Release(address, 7)
+	manager.Confirm(address, 5)
+	chain.sequence = 6
+	assert.Equal(t, uint64(7), reserve())
+	assert.Equal(t, 2, chain.getAccounts)
+
+	// the pending transaction 6 expires by ledger time, gap is closed once nothing is in flight
+	manager.Release(address, 7)
+	manager.Release(address, 8)
+	chain.timestamp = 3000
+	_, err := client.GetMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), reserve())
+	assert.Equal(t, 3, chain.getAccounts)
+}
+
+func mustDecodeHex(s string) []byte {
+	bytes, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
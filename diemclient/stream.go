@@ -0,0 +1,223 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/avast/retry-go"
+)
+
+// DefaultStreamConcurrency is the default number of batches `StreamTransactions` fetches
+// concurrently.
+const DefaultStreamConcurrency = 4
+
+// TransactionStream streams transactions by "get_transactions" in version order. Batches are
+// fetched concurrently ahead of the consumer; at most the stream concurrency number of batches
+// are held in memory, and fetching is paused until the consumer catches up.
+//
+// The stream is pinned to the ledger version of "get_metadata" response when it starts:
+// transactions committed after it are not returned, call `StreamTransactions` again from
+// `NextVersion` to continue.
+//
+//	stream := client.StreamTransactions(ctx, 0, 500, true)
+//	defer stream.Close()
+//	for stream.Next() {
+//		txn := stream.Transaction()
+//	}
+//	if err := stream.Err(); err != nil {
+//		...
+//	}
+type TransactionStream struct {
+	client  *client
+	ctx     context.Context
+	cancel  context.CancelFunc
+	batches chan *streamBatch
+	page    []*Transaction
+	current *Transaction
+	next    uint64
+	err     error
+	// complete is set when all batches are dispatched, it is read after batches is closed
+	complete bool
+}
+
+type streamBatch struct {
+	start uint64
+	count uint64
+	txns  []*Transaction
+	err   error
+	done  chan struct{}
+}
+
+// StreamTransactions returns stream of transactions from version `startVersion` to the latest
+// ledger version, fetching `batchSize` transactions per "get_transactions" call.
+// A batch responded by a server behind the batch versions is retried as `*StaleResponseError`
+// by the client retry policy. The stream stops at the first error after delivering all
+// transactions before the failed batch.
+func (c *client) StreamTransactions(ctx context.Context, startVersion uint64, batchSize uint64, includeEvents bool) *TransactionStream {
+	if batchSize == 0 {
+		batchSize = 1
+	}
+	concurrency := c.streamConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &TransactionStream{
+		client:  c,
+		ctx:     ctx,
+		cancel:  cancel,
+		batches: make(chan *streamBatch, concurrency),
+		next:    startVersion,
+	}
+	go s.run(startVersion, batchSize, includeEvents, concurrency)
+	return s
+}
+
+// Next moves to next transaction, returns false when there is no more transactions or
+// an error occurred.
+func (s *TransactionStream) Next() bool {
+	for len(s.page) == 0 {
+		if s.err != nil {
+			s.current = nil
+			return false
+		}
+		batch, ok := <-s.batches
+		if !ok {
+			if !s.complete {
+				s.err = s.ctx.Err()
+			}
+			s.current = nil
+			s.cancel()
+			return false
+		}
+		<-batch.done
+		if batch.err != nil {
+			s.err = batch.err
+			s.cancel()
+		}
+		s.page = batch.txns
+	}
+	s.current = s.page[0]
+	s.page = s.page[1:]
+	s.next = s.current.Version + 1
+	return true
+}
+
+// Transaction returns current transaction
+func (s *TransactionStream) Transaction() *Transaction {
+	return s.current
+}
+
+// Err returns error occurred during streaming
+func (s *TransactionStream) Err() error {
+	return s.err
+}
+
+// NextVersion returns version of the next transaction to deliver, it can be used for resuming
+// the stream.
+func (s *TransactionStream) NextVersion() uint64 {
+	return s.next
+}
+
+// Close stops fetching batches; it is safe to call multiple times.
+func (s *TransactionStream) Close() {
+	s.cancel()
+}
+
+func (s *TransactionStream) run(start, batchSize uint64, includeEvents bool, concurrency int) {
+	defer close(s.batches)
+	metadata, err := s.client.GetMetadataWithContext(s.ctx)
+	if err != nil {
+		s.batches <- &streamBatch{err: err, done: closedChan()}
+		return
+	}
+	end := metadata.Version
+	if start > end {
+		s.complete = true
+		return
+	}
+
+	work := make(chan *streamBatch)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range work {
+				s.fetch(batch, includeEvents)
+				close(batch.done)
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(work)
+
+	for version := start; ; version += batchSize {
+		batch := &streamBatch{start: version, count: batchSize, done: make(chan struct{})}
+		if end-version < batchSize {
+			batch.count = end - version + 1
+		}
+		select {
+		case s.batches <- batch:
+		case <-s.ctx.Done():
+			return
+		}
+		select {
+		case work <- batch:
+		case <-s.ctx.Done():
+			batch.err = s.ctx.Err()
+			close(batch.done)
+			return
+		}
+		if batch.count < batchSize || end-version == batchSize-1 {
+			break
+		}
+	}
+	s.complete = true
+}
+
+// fetch gets transactions of the batch until all are fetched, a server behind the batch
+// versions responds less transactions, the remaining is fetched again.
+func (s *TransactionStream) fetch(batch *streamBatch, includeEvents bool) {
+	opts := s.client.retryPolicy.options()
+	opts = append(opts, s.client.retryOpts...)
+	opts = append(opts, retry.Context(s.ctx), retry.RetryIf(func(err error) bool {
+		_, ok := err.(*StaleResponseError)
+		return ok
+	}))
+	for uint64(len(batch.txns)) < batch.count {
+		start := batch.start + uint64(len(batch.txns))
+		var txns []*Transaction
+		batch.err = retry.Do(func() error {
+			var err error
+			txns, err = s.client.GetTransactionsWithContext(s.ctx, start, batch.count-uint64(len(batch.txns)), includeEvents)
+			if err == nil && len(txns) == 0 {
+				err = &StaleResponseError{
+					Client: LedgerState{Version: batch.start + batch.count - 1},
+					Server: LedgerState{Version: start - 1},
+				}
+			}
+			return err
+		}, opts...)
+		if batch.err != nil {
+			return
+		}
+		for i, txn := range txns {
+			if txn.Version != start+uint64(i) {
+				batch.err = fmt.Errorf("unexpected transaction version %d, expected %d", txn.Version, start+uint64(i))
+				return
+			}
+		}
+		batch.txns = append(batch.txns, txns...)
+	}
+}
+
+func closedChan() chan struct{} {
+	ret := make(chan struct{})
+	close(ret)
+	return ret
+}
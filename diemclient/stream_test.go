@@ -0,0 +1,160 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// txnsStub serves get_metadata and get_transactions, transaction versions are 0 to
+// `ledgerVersion`
+type txnsStub struct {
+	mu            sync.Mutex
+	ledgerVersion uint64
+	// behind is number of times responding no transactions for the start version
+	behind map[uint64]int
+	// errs is error responded for the start version
+	errs  map[uint64]error
+	calls int
+}
+
+func (s *txnsStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req := requests[0]
+	var result interface{}
+	switch req.Method {
+	case diemclient.GetMetadata:
+		result = &diemclient.Metadata{Version: s.ledgerVersion}
+	case diemclient.GetTransactions:
+		s.calls++
+		start := req.Params[0].(uint64)
+		limit := req.Params[1].(uint64)
+		if err := s.errs[start]; err != nil {
+			return nil, err
+		}
+		txns := []*diemclient.Transaction{}
+		if s.behind[start] > 0 {
+			s.behind[start]--
+		} else {
+			for v := start; v <= s.ledgerVersion && v < start+limit; v++ {
+				txns = append(txns, &diemclient.Transaction{Version: v})
+			}
+		}
+		result = txns
+	}
+	bytes, _ := json.Marshal(result)
+	raw := json.RawMessage(bytes)
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{
+		req.ID: {Result: &raw, DiemLedgerVersion: s.ledgerVersion, DiemLedgerTimestampusec: 1},
+	}}
+	return stub.Call(requests...)
+}
+
+func (s *txnsStub) getTransactionsCalls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func collectVersions(t *testing.T, stream *diemclient.TransactionStream) []uint64 {
+	var ret []uint64
+	for stream.Next() {
+		ret = append(ret, stream.Transaction().Version)
+	}
+	return ret
+}
+
+func versionRange(start, end uint64) []uint64 {
+	var ret []uint64
+	for v := start; v <= end; v++ {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+func TestStreamTransactions(t *testing.T) {
+	fastRetry := diemclient.WithRetryPolicy(diemclient.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     func(uint) time.Duration { return 0 },
+		Retryable:   diemclient.IsRetryable,
+	})
+
+	t.Run("in order", func(t *testing.T) {
+		stub := &txnsStub{ledgerVersion: 99}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub, diemclient.WithStreamConcurrency(3))
+		stream := client.(diemclient.PagingClient).StreamTransactions(context.Background(), 3, 10, true)
+		defer stream.Close()
+		assert.Equal(t, versionRange(3, 99), collectVersions(t, stream))
+		require.NoError(t, stream.Err())
+		assert.Equal(t, uint64(100), stream.NextVersion())
+		assert.Equal(t, 10, stub.getTransactionsCalls())
+	})
+	t.Run("start after latest version", func(t *testing.T) {
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &txnsStub{ledgerVersion: 9})
+		stream := client.(diemclient.PagingClient).StreamTransactions(context.Background(), 10, 10, true)
+		assert.False(t, stream.Next())
+		assert.NoError(t, stream.Err())
+		assert.Equal(t, uint64(10), stream.NextVersion())
+	})
+	t.Run("retry server behind", func(t *testing.T) {
+		stub := &txnsStub{ledgerVersion: 49, behind: map[uint64]int{20: 2, 40: 1}}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub, fastRetry)
+		stream := client.(diemclient.PagingClient).StreamTransactions(context.Background(), 0, 10, false)
+		assert.Equal(t, versionRange(0, 49), collectVersions(t, stream))
+		require.NoError(t, stream.Err())
+	})
+	t.Run("server behind beyond retry attempts", func(t *testing.T) {
+		stub := &txnsStub{ledgerVersion: 49, behind: map[uint64]int{20: 3}}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub, fastRetry)
+		stream := client.(diemclient.PagingClient).StreamTransactions(context.Background(), 0, 10, false)
+		assert.Equal(t, versionRange(0, 19), collectVersions(t, stream))
+		var stale *diemclient.StaleResponseError
+		require.True(t, errors.As(stream.Err(), &stale))
+		assert.Equal(t, uint64(29), stale.Client.Version)
+	})
+	t.Run("error", func(t *testing.T) {
+		stub := &txnsStub{ledgerVersion: 99, errs: map[uint64]error{50: errors.New("server error")}}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub,
+			diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		stream := client.(diemclient.PagingClient).StreamTransactions(context.Background(), 0, 10, false)
+		assert.Equal(t, versionRange(0, 49), collectVersions(t, stream))
+		assert.EqualError(t, stream.Err(), "server error")
+		assert.Equal(t, uint64(50), stream.NextVersion())
+	})
+	t.Run("backpressure", func(t *testing.T) {
+		stub := &txnsStub{ledgerVersion: 9999}
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub, diemclient.WithStreamConcurrency(2))
+		stream := client.(diemclient.PagingClient).StreamTransactions(context.Background(), 0, 10, false)
+		defer stream.Close()
+		assert.Eventually(t, func() bool { return stub.getTransactionsCalls() == 2 }, time.Second, time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, 2, stub.getTransactionsCalls())
+
+		require.True(t, stream.Next())
+		assert.Eventually(t, func() bool { return stub.getTransactionsCalls() == 3 }, time.Second, time.Millisecond)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &txnsStub{ledgerVersion: 9999})
+		stream := client.(diemclient.PagingClient).StreamTransactions(ctx, 0, 10, false)
+		require.True(t, stream.Next())
+		cancel()
+		for stream.Next() {
+		}
+		assert.True(t, errors.Is(stream.Err(), context.Canceled))
+	})
+}
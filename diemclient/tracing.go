@@ -0,0 +1,55 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the OpenTelemetry instrumentation name of the client
+const TracerName = "github.com/diem/client-sdk-go/diemclient"
+
+// OpenTelemetry span attribute keys
+const (
+	AttributeChainID         = label.Key("diem.chain_id")
+	AttributeLedgerVersion   = label.Key("diem.ledger_version")
+	AttributeTransactionHash = label.Key("diem.transaction_hash")
+	AttributeAccountAddress  = label.Key("diem.account_address")
+	AttributeSequenceNumber  = label.Key("diem.sequence_number")
+)
+
+// WithTracing enables OpenTelemetry tracing: creates client spans for `SubmitTransaction`,
+// `WaitForTransaction` and `GetAccount` methods (including their variants) by the tracer of
+// given `trace.TracerProvider`.
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(c *client) {
+		c.tracer = tp.Tracer(TracerName)
+	}
+}
+
+func (c *client) startSpan(ctx context.Context, name string, attrs ...label.KeyValue) (context.Context, trace.Span) {
+	if c.tracer == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	attrs = append(attrs, AttributeChainID.Int64(int64(c.ChainID())))
+	return c.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+func (c *client) endSpan(span trace.Span, err error) {
+	if c.tracer == nil {
+		return
+	}
+	span.SetAttributes(AttributeLedgerVersion.Uint64(c.LastResponseLedgerState().Version))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,61 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := new(oteltest.StandardSpanRecorder)
+	tp := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(recorder))
+	result := json.RawMessage(`{"sequence_number": 1}`)
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+		Responses: map[jsonrpc.RequestID]jsonrpc.Response{
+			1: {Result: &result, DiemLedgerVersion: 42},
+		},
+	}, diemclient.WithTracing(tp))
+	keys := diemkeys.MustGenKeys()
+
+	_, err := client.GetAccount(keys.AccountAddress())
+	require.NoError(t, err)
+	spans := recorder.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "diemclient.GetAccount", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	attrs := spans[0].Attributes()
+	assert.Equal(t, int64(testnet.ChainID), attrs[diemclient.AttributeChainID].AsInt64())
+	assert.Equal(t, uint64(42), attrs[diemclient.AttributeLedgerVersion].AsUint64())
+	assert.Equal(t, keys.AccountAddress().Hex(), attrs[diemclient.AttributeAccountAddress].AsString())
+
+	client = diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+		Responses: map[jsonrpc.RequestID]jsonrpc.Response{
+			1: {Error: &jsonrpc.ResponseError{Code: diemclient.ErrCodeVmValidationError, Message: "INVALID_SIGNATURE"}},
+		},
+	}, diemclient.WithTracing(tp))
+	txn := diemsigner.Sign(keys, keys.AccountAddress(), 0,
+		stdlib.EncodePeerToPeerWithMetadataScript(testnet.XUS, keys.AccountAddress(), 1, nil, nil),
+		1_000_000, 0, "XUS", 100, testnet.ChainID)
+	err = client.SubmitTransaction(txn)
+	require.Error(t, err)
+	spans = recorder.Completed()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "diemclient.SubmitTransaction", spans[1].Name())
+	assert.Equal(t, txn.TransactionHash(), spans[1].Attributes()[diemclient.AttributeTransactionHash].AsString())
+	assert.Equal(t, codes.Error, spans[1].StatusCode())
+}
@@ -16,6 +16,9 @@ type CurrencyInfo = diemjsonrpctypes.CurrencyInfo
 // AccountRole represents role specific data for account
 type AccountRole = diemjsonrpctypes.AccountRole
 
+// PreburnQueue is designated dealer's preburn requests of a currency
+type PreburnQueue = diemjsonrpctypes.PreburnQueue
+
 // Account is get_account method response
 type Account = diemjsonrpctypes.Account
 
@@ -0,0 +1,81 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// Account role types
+const (
+	AccountRoleParentVASP       = "parent_vasp"
+	AccountRoleChildVASP        = "child_vasp"
+	AccountRoleDesignatedDealer = "designated_dealer"
+	AccountRoleUnknown          = "unknown"
+)
+
+// VASPInfo is parent VASP account info required for off-chain communication and
+// dual attestation.
+type VASPInfo struct {
+	ParentVASPAddress diemtypes.AccountAddress
+	HumanName         string
+	BaseURL           string
+	// ComplianceKey is hex-encoded ed25519 public key
+	ComplianceKey string
+	// ComplianceKeyRotationEventsKey is hex-encoded event key of compliance key rotation events
+	ComplianceKeyRotationEventsKey string
+}
+
+// GetParentVASPInfo gets the parent VASP info of given address.
+// If given address is a child VASP account, it follows the parent VASP address to get
+// the parent VASP account.
+// Returns error if the account or its parent VASP account is not found, or the account
+// is not a VASP account.
+func GetParentVASPInfo(c Client, address diemtypes.AccountAddress) (*VASPInfo, error) {
+	account, err := getVASPAccount(c, address)
+	if err != nil {
+		return nil, err
+	}
+	if account.Role.Type == AccountRoleChildVASP {
+		parentAddress, err := diemtypes.MakeAccountAddress(account.Role.ParentVaspAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent vasp address %#v: %v", account.Role.ParentVaspAddress, err)
+		}
+		account, err = getVASPAccount(c, parentAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if account.Role.Type != AccountRoleParentVASP {
+		return nil, fmt.Errorf("account %s is not a parent vasp account, role: %s", account.Address, account.Role.Type)
+	}
+	parentAddress, err := diemtypes.MakeAccountAddress(account.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address %#v: %v", account.Address, err)
+	}
+	return &VASPInfo{
+		ParentVASPAddress:              parentAddress,
+		HumanName:                      account.Role.HumanName,
+		BaseURL:                        account.Role.BaseUrl,
+		ComplianceKey:                  account.Role.ComplianceKey,
+		ComplianceKeyRotationEventsKey: account.Role.ComplianceKeyRotationEventsKey,
+	}, nil
+}
+
+func getVASPAccount(c Client, address diemtypes.AccountAddress) (*Account, error) {
+	account, err := c.GetAccount(address)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("account %s not found", address.Hex())
+	}
+	if account.Role == nil ||
+		(account.Role.Type != AccountRoleParentVASP && account.Role.Type != AccountRoleChildVASP) {
+		return nil, fmt.Errorf("account %s is not a vasp account", address.Hex())
+	}
+	return account, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/avast/retry-go"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const parentVASPAccount = `{
+  "address": "f72589b71ff4f8d139674a3f7369c69b",
+  "role": {
+    "type": "parent_vasp",
+    "human_name": "vasp",
+    "base_url": "http://vasp.com",
+    "compliance_key": "447fc3be296803c2303951c7816624c7566730a5cc6860a4a1bd3c04731569f5",
+    "compliance_key_rotation_events_key": "0000000000000000f72589b71ff4f8d139674a3f7369c69b"
+  }
+}`
+
+const childVASPAccount = `{
+  "address": "a74fd7c46952c497e75afb0a7932586d",
+  "role": {
+    "type": "child_vasp",
+    "parent_vasp_address": "f72589b71ff4f8d139674a3f7369c69b"
+  }
+}`
+
+func TestGetParentVASPInfo(t *testing.T) {
+	expected := &diemclient.VASPInfo{
+		ParentVASPAddress:              diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b"),
+		HumanName:                      "vasp",
+		BaseURL:                        "http://vasp.com",
+		ComplianceKey:                  "447fc3be296803c2303951c7816624c7566730a5cc6860a4a1bd3c04731569f5",
+		ComplianceKeyRotationEventsKey: "0000000000000000f72589b71ff4f8d139674a3f7369c69b",
+	}
+	cases := []struct {
+		name     string
+		accounts []string
+		expected *diemclient.VASPInfo
+		err      string
+	}{
+		{
+			name:     "parent vasp",
+			accounts: []string{parentVASPAccount},
+			expected: expected,
+		},
+		{
+			name:     "child vasp",
+			accounts: []string{childVASPAccount, parentVASPAccount},
+			expected: expected,
+		},
+		{
+			name:     "account not found",
+			accounts: []string{"null"},
+			err:      "account a74fd7c46952c497e75afb0a7932586d not found",
+		},
+		{
+			name:     "not a vasp account",
+			accounts: []string{`{"address": "a74fd7c46952c497e75afb0a7932586d", "role": {"type": "unknown"}}`},
+			err:      "account a74fd7c46952c497e75afb0a7932586d is not a vasp account",
+		},
+		{
+			name:     "parent of child vasp is not parent vasp",
+			accounts: []string{childVASPAccount, childVASPAccount},
+			err:      "account a74fd7c46952c497e75afb0a7932586d is not a parent vasp account, role: child_vasp",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &sequenceStub{}
+			for _, account := range tc.accounts {
+				stub.results = append(stub.results, json.RawMessage(account))
+			}
+			client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub).WithRetryOptions(retry.Attempts(1))
+			ret, err := diemclient.GetParentVASPInfo(
+				client, diemtypes.MustMakeAccountAddress("a74fd7c46952c497e75afb0a7932586d"))
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ret)
+		})
+	}
+}
+
+// sequenceStub responds results in sequence, one result for each call
+type sequenceStub struct {
+	results []json.RawMessage
+	calls   int
+}
+
+func (s *sequenceStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	var resp jsonrpc.Response
+	if result := s.results[s.calls]; string(result) != "null" {
+		resp.Result = &result
+	}
+	s.calls++
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{
+		requests[0].ID: resp,
+	}}
+	return stub.Call(requests...)
+}
@@ -0,0 +1,150 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemlog"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// DefaultWaitPollInterval is interval between polls of waiting for a transaction
+const DefaultWaitPollInterval = 500 * time.Millisecond
+
+// WaitResult is result of waiting for a transaction
+type WaitResult struct {
+	Transaction *Transaction
+	VmStatus    *VmStatus
+	GasUsed     uint64
+	Events      []*Event
+	// Polls is number of get_account_transaction calls made for waiting
+	Polls int
+	// Duration is time elapsed from start waiting to the transaction found
+	Duration time.Duration
+}
+
+// WaitPoll describes a poll of waiting for a transaction, it is passed to the
+// `onPoll` callback of `WaitForTransactionResult` after each poll.
+type WaitPoll struct {
+	// Attempt starts from 1
+	Attempt int
+	// Latency is the get_account_transaction call latency
+	Latency time.Duration
+	// Elapsed is time elapsed from start waiting
+	Elapsed     time.Duration
+	LedgerState LedgerState
+	// Found is true when the transaction is found on chain
+	Found bool
+	Err   error
+}
+
+// WaitForTransactionResult waits for given `SignedTransaction` until the context is done, and
+// calls `onPoll` after each poll if it is not nil.
+// Returns `WaitResult` with vm status, gas used and events of the transaction; the result is
+// also returned with `*ExecutionError` when the transaction execution failed.
+func (c *client) WaitForTransactionResult(ctx context.Context, txn *diemtypes.SignedTransaction, onPoll func(*WaitPoll)) (*WaitResult, error) {
+	start := time.Now()
+	polls := 0
+	executed, err := c.waitForTransaction(
+		ctx,
+		txn.RawTxn.Sender,
+		txn.RawTxn.SequenceNumber,
+		txn.TransactionHash(),
+		txn.RawTxn.ExpirationTimestampSecs,
+		func(poll *WaitPoll) {
+			polls = poll.Attempt
+			if onPoll != nil {
+				onPoll(poll)
+			}
+		},
+	)
+	if execErr, ok := err.(*ExecutionError); ok {
+		executed = execErr.Transaction
+	}
+	if executed == nil {
+		return nil, err
+	}
+	return &WaitResult{
+		Transaction: executed,
+		VmStatus:    executed.VmStatus,
+		GasUsed:     executed.GasUsed,
+		Events:      executed.Events,
+		Polls:       polls,
+		Duration:    time.Since(start),
+	}, err
+}
+
+func (c *client) waitForTransaction(ctx context.Context, address diemtypes.AccountAddress, seq uint64, hash string, expirationTimeSec uint64, onPoll func(*WaitPoll)) (ret *Transaction, err error) {
+	ctx, span := c.startSpan(ctx, "diemclient.WaitForTransaction",
+		AttributeTransactionHash.String(hash),
+		AttributeAccountAddress.String(address.Hex()),
+		AttributeSequenceNumber.Uint64(seq))
+	defer func() { c.endSpan(span, err) }()
+
+	start := time.Now()
+	defer func() {
+		c.logWaitResult(hash, ret, err)
+		c.afterWait(ctx, hash, ret, start, err)
+	}()
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		callStart := time.Now()
+		txn, err := c.GetAccountTransactionWithContext(ctx, address, seq, true)
+		if onPoll != nil {
+			onPoll(&WaitPoll{
+				Attempt:     attempt,
+				Latency:     time.Since(callStart),
+				Elapsed:     time.Since(start),
+				LedgerState: c.LastResponseLedgerState(),
+				Found:       txn != nil,
+				Err:         err,
+			})
+		}
+		if _, ok := err.(*StaleResponseError); ok {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if txn != nil {
+			if txn.Hash != hash {
+				return nil, newInvalidTransactionError(txn, fmt.Sprintf(
+					"transaction hash does not match, given %#v, but got %#v",
+					hash, txn.Hash))
+			}
+			if txn.VmStatus.Type != VmStatusExecuted {
+				return nil, &ExecutionError{Transaction: txn}
+			}
+			return txn, nil
+		}
+		if expirationTimeSec*1_000_000 <= c.LastResponseLedgerState().TimestampUsec {
+			return nil, ErrTransactionExpired
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(DefaultWaitPollInterval):
+		}
+	}
+}
+
+func (c *client) logWaitResult(hash string, txn *Transaction, err error) {
+	switch e := err.(type) {
+	case nil:
+		c.logger.Log(diemlog.LevelInfo, "transaction executed", diemlog.F("hash", hash),
+			diemlog.F("version", txn.Version), diemlog.F("gas_used", txn.GasUsed))
+	case *ExecutionError:
+		c.logger.Log(diemlog.LevelWarn, "transaction execution failed", diemlog.F("hash", hash),
+			diemlog.F("version", e.Transaction.Version), diemlog.F("error", err))
+	default:
+		c.logger.Log(diemlog.LevelWarn, "waiting for transaction failed", diemlog.F("hash", hash),
+			diemlog.F("error", err))
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pendingTxnStub returns transaction not found for first `pending` calls
+type pendingTxnStub struct {
+	pending int
+	result  string
+}
+
+func (s *pendingTxnStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	var resp jsonrpc.Response
+	if s.pending > 0 {
+		s.pending--
+	} else {
+		raw := json.RawMessage(s.result)
+		resp.Result = &raw
+	}
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{requests[0].ID: resp}}
+	return stub.Call(requests...)
+}
+
+func TestWaitForTransactionResult(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	txn := diemsigner.Sign(keys, keys.AccountAddress(), 0,
+		stdlib.EncodePeerToPeerWithMetadataScript(testnet.XUS, keys.AccountAddress(), 1, nil, nil),
+		1_000_000, 0, "XUS", uint64(time.Now().Add(time.Minute).Unix()), testnet.ChainID)
+
+	t.Run("executed", func(t *testing.T) {
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &pendingTxnStub{
+			pending: 1,
+			result: fmt.Sprintf(`{"version": 10, "hash": %q, "gas_used": 480, "vm_status": {"type": "executed"},
+				"events": [{"key": "0000000000000000000000000000000000000000000000000000000000000001"}]}`, txn.TransactionHash()),
+		})
+		var polls []*diemclient.WaitPoll
+		ret, err := client.(diemclient.WaitResultClient).WaitForTransactionResult(context.Background(), txn, func(poll *diemclient.WaitPoll) {
+			polls = append(polls, poll)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), ret.Transaction.Version)
+		assert.Equal(t, diemclient.VmStatusExecuted, ret.VmStatus.Type)
+		assert.Equal(t, uint64(480), ret.GasUsed)
+		assert.Len(t, ret.Events, 1)
+		assert.Equal(t, 2, ret.Polls)
+		require.Len(t, polls, 2)
+		assert.Equal(t, 1, polls[0].Attempt)
+		assert.False(t, polls[0].Found)
+		assert.True(t, polls[1].Found)
+		assert.Equal(t, uint64(100), polls[1].LedgerState.Version)
+	})
+	t.Run("execution failed", func(t *testing.T) {
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &pendingTxnStub{
+			result: fmt.Sprintf(`{"version": 10, "hash": %q, "gas_used": 100, "vm_status": {"type": "out_of_gas"}}`, txn.TransactionHash()),
+		})
+		ret, err := client.(diemclient.WaitResultClient).WaitForTransactionResult(context.Background(), txn, nil)
+		var execErr *diemclient.ExecutionError
+		require.True(t, errors.As(err, &execErr))
+		assert.Equal(t, diemclient.VmStatusOutOfGas, execErr.VmStatus().Type)
+		require.NotNil(t, ret)
+		assert.Equal(t, diemclient.VmStatusOutOfGas, ret.VmStatus.Type)
+		assert.Equal(t, uint64(100), ret.GasUsed)
+	})
+}
@@ -8,6 +8,7 @@ package diemid
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/diem/client-sdk-go/diemid/bech32"
 	"github.com/diem/client-sdk-go/diemtypes"
@@ -60,13 +61,33 @@ func EncodeAccount(prefix NetworkPrefix, accountAddress diemtypes.AccountAddress
 // DecodeToAccount decode given encoded account identifier string to `Account`.
 // Given NetworkPrefix is used to validate account identifier network prefix, and returns error
 // if the network prefix mismatched.
+// Returns `*DecodeError` for invalid account identifier, its kind distinguishes malformed
+// string, bad checksum, wrong network prefix, wrong version and wrong payload length.
 func DecodeToAccount(prefix NetworkPrefix, encodedAccountIdentifier string) (*Account, error) {
+	hrp, data, err := bech32.Decode(encodedAccountIdentifier)
+	if err == bech32.ErrInvalidChecksum {
+		return nil, newDecodeError(DecodeErrorInvalidChecksum, prefix, err.Error())
+	} else if err != nil {
+		return nil, newDecodeError(DecodeErrorInvalidFormat, prefix, err.Error())
+	}
+	if hrp != string(prefix) {
+		ret := newDecodeError(DecodeErrorWrongNetwork, prefix, "expected network prefix %s, got %s", prefix, hrp)
+		ret.Actual = NetworkPrefix(hrp)
+		return nil, ret
+	}
+	if len(data) < 1 {
+		return nil, newDecodeError(DecodeErrorInvalidLength, prefix, "missing version")
+	}
+	if data[0] != int(V1) {
+		return nil, newDecodeError(DecodeErrorInvalidVersion, prefix, "unsupported version: %d", data[0])
+	}
 	version, data, err := bech32.SegwitAddrDecode(string(prefix), encodedAccountIdentifier)
 	if err != nil {
-		return nil, err
+		return nil, newDecodeError(DecodeErrorInvalidLength, prefix, err.Error())
 	}
 	if len(data) != AccountAddressLength+SubAddressLength {
-		return nil, errors.New("invalid account identifier, account address and sub-address length does not match")
+		return nil, newDecodeError(DecodeErrorInvalidLength, prefix,
+			"account address and sub-address length does not match: %d", len(data))
 	}
 
 	address, _ := diemtypes.MakeAccountAddressFromBytes(
@@ -81,6 +102,22 @@ func DecodeToAccount(prefix NetworkPrefix, encodedAccountIdentifier string) (*Ac
 	}, nil
 }
 
+// SuggestNetwork returns network prefix of the given account identifier, it is useful for
+// telling user which network an account identifier belongs to when `DecodeToAccount` returns
+// `ErrWrongNetwork` error.
+// Returns error if the account identifier is malformed or the network prefix is not registered,
+// see `RegisterNetworkPrefix`.
+func SuggestNetwork(encodedAccountIdentifier string) (NetworkPrefix, error) {
+	hrp, _, err := bech32.Decode(encodedAccountIdentifier)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := NetworkName(NetworkPrefix(hrp)); ok {
+		return NetworkPrefix(hrp), nil
+	}
+	return "", fmt.Errorf("unknown network prefix: %s", hrp)
+}
+
 // Encode encodes Account into SegwitAddr string
 func (ai *Account) Encode() (string, error) {
 	if len(ai.SubAddress) != SubAddressLength {
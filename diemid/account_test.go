@@ -4,6 +4,7 @@
 package diemid_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/diem/client-sdk-go/diemid"
@@ -70,3 +71,67 @@ func TestDecodeInvalidAccountIdentifierString(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid account identifier")
 	})
 }
+
+func TestDecodeAccountIdentifierErrorKinds(t *testing.T) {
+	address, _ := diemtypes.MakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	subAddress, _ := diemtypes.MakeSubAddress("cf64428bdeb62af2")
+	mainnet, err := diemid.EncodeAccount(diemid.MainnetPrefix, address, subAddress)
+	require.NoError(t, err)
+	account := diemid.Account{Prefix: diemid.MainnetPrefix, Version: 2, AccountAddress: address, SubAddress: subAddress}
+	v2, err := account.Encode()
+	require.NoError(t, err)
+	badChecksum := mainnet[:len(mainnet)-1] + "q"
+	if mainnet[len(mainnet)-1] == 'q' {
+		badChecksum = mainnet[:len(mainnet)-1] + "p"
+	}
+	short, err := bech32.SegwitAddrEncode(string(diemid.MainnetPrefix), 1, []int{1, 2, 3})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		encoded  string
+		sentinel error
+		kind     diemid.DecodeErrorKind
+	}{
+		{"bad checksum", badChecksum, diemid.ErrInvalidChecksum, diemid.DecodeErrorInvalidChecksum},
+		{"mixed case", "D" + mainnet[1:], diemid.ErrInvalidFormat, diemid.DecodeErrorInvalidFormat},
+		{"wrong version", v2, diemid.ErrInvalidVersion, diemid.DecodeErrorInvalidVersion},
+		{"wrong length", short, diemid.ErrInvalidLength, diemid.DecodeErrorInvalidLength},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := diemid.DecodeToAccount(diemid.MainnetPrefix, tc.encoded)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tc.sentinel))
+			assert.Equal(t, tc.kind, err.(*diemid.DecodeError).Kind)
+		})
+	}
+
+	t.Run("wrong network", func(t *testing.T) {
+		_, err := diemid.DecodeToAccount(diemid.TestnetPrefix, mainnet)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, diemid.ErrWrongNetwork))
+		decodeErr := err.(*diemid.DecodeError)
+		assert.Equal(t, diemid.TestnetPrefix, decodeErr.Expected)
+		assert.Equal(t, diemid.MainnetPrefix, decodeErr.Actual)
+	})
+}
+
+func TestSuggestNetwork(t *testing.T) {
+	address, _ := diemtypes.MakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	for _, prefix := range []diemid.NetworkPrefix{diemid.MainnetPrefix, diemid.TestnetPrefix, diemid.PreMainnetPrefix} {
+		encoded, err := diemid.EncodeAccount(prefix, address, diemtypes.EmptySubAddress)
+		require.NoError(t, err)
+		ret, err := diemid.SuggestNetwork(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, prefix, ret)
+	}
+
+	encoded, err := diemid.EncodeAccount("xdm", address, diemtypes.EmptySubAddress)
+	require.NoError(t, err)
+	_, err = diemid.SuggestNetwork(encoded)
+	assert.EqualError(t, err, "unknown network prefix: xdm")
+
+	_, err = diemid.SuggestNetwork("invalid")
+	assert.Error(t, err)
+}
@@ -22,10 +22,14 @@ package bech32
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// ErrInvalidChecksum is returned by `Decode` when the checksum does not match
+var ErrInvalidChecksum = errors.New("invalid checksum")
+
 var charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
 
 var generator = []int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
@@ -133,7 +137,7 @@ func Decode(bechString string) (string, []int, error) {
 		data = append(data, d)
 	}
 	if !verifyChecksum(hrp, data) {
-		return "", nil, fmt.Errorf("invalid checksum")
+		return "", nil, ErrInvalidChecksum
 	}
 	return hrp, data[:len(data)-6], nil
 }
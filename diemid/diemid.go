@@ -0,0 +1,81 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// This file implemenets DiemID identifier proposal
+// https://github.com/diem/dip/blob/main/dips/dip-10.md
+
+package diemid
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxUserIdentifierLength is max length of DiemID user identifier
+	MaxUserIdentifierLength = 64
+	// MaxVaspDomainIdentifierLength is max length of DiemID VASP domain identifier
+	MaxVaspDomainIdentifierLength = 63
+)
+
+var (
+	userIdentifierPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._]*$`)
+	vaspDomainPattern     = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.]*$`)
+)
+
+// DiemID captures parts of DiemID identifier `user_identifier@vasp_domain_identifier`
+type DiemID struct {
+	UserIdentifier       string
+	VaspDomainIdentifier string
+}
+
+// NewDiemID creates `DiemID`, returns error if the user identifier or VASP domain identifier
+// is invalid.
+func NewDiemID(userIdentifier, vaspDomainIdentifier string) (*DiemID, error) {
+	if err := ValidateUserIdentifier(userIdentifier); err != nil {
+		return nil, err
+	}
+	if err := ValidateVaspDomainIdentifier(vaspDomainIdentifier); err != nil {
+		return nil, err
+	}
+	return &DiemID{UserIdentifier: userIdentifier, VaspDomainIdentifier: vaspDomainIdentifier}, nil
+}
+
+// ParseDiemID parses DiemID string, e.g. "alice@avasp"
+func ParseDiemID(id string) (*DiemID, error) {
+	parts := strings.Split(id, "@")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid diem id %#v: expected format user_identifier@vasp_domain_identifier", id)
+	}
+	return NewDiemID(parts[0], parts[1])
+}
+
+// String returns DiemID string
+func (id *DiemID) String() string {
+	return id.UserIdentifier + "@" + id.VaspDomainIdentifier
+}
+
+// ValidateUserIdentifier validates DiemID user identifier: 1 to `MaxUserIdentifierLength`
+// letters, digits, "." or "_", starts with a letter or digit.
+func ValidateUserIdentifier(userIdentifier string) error {
+	if len(userIdentifier) > MaxUserIdentifierLength {
+		return fmt.Errorf("invalid user identifier %#v: longer than %d", userIdentifier, MaxUserIdentifierLength)
+	}
+	if !userIdentifierPattern.MatchString(userIdentifier) {
+		return fmt.Errorf("invalid user identifier %#v: invalid characters", userIdentifier)
+	}
+	return nil
+}
+
+// ValidateVaspDomainIdentifier validates DiemID VASP domain identifier: 1 to
+// `MaxVaspDomainIdentifierLength` letters, digits or ".", starts with a letter or digit.
+func ValidateVaspDomainIdentifier(domain string) error {
+	if len(domain) > MaxVaspDomainIdentifierLength {
+		return fmt.Errorf("invalid vasp domain identifier %#v: longer than %d", domain, MaxVaspDomainIdentifierLength)
+	}
+	if !vaspDomainPattern.MatchString(domain) {
+		return fmt.Errorf("invalid vasp domain identifier %#v: invalid characters", domain)
+	}
+	return nil
+}
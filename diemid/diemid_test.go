@@ -0,0 +1,43 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemid_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiemID(t *testing.T) {
+	id, err := diemid.ParseDiemID("alice.1_a@avasp.com")
+	require.NoError(t, err)
+	assert.Equal(t, "alice.1_a", id.UserIdentifier)
+	assert.Equal(t, "avasp.com", id.VaspDomainIdentifier)
+	assert.Equal(t, "alice.1_a@avasp.com", id.String())
+
+	cases := []struct {
+		id  string
+		err string
+	}{
+		{"alice", "expected format user_identifier@vasp_domain_identifier"},
+		{"alice@bob@avasp", "expected format user_identifier@vasp_domain_identifier"},
+		{"@avasp", "invalid user identifier"},
+		{"_alice@avasp", "invalid user identifier"},
+		{"ali ce@avasp", "invalid user identifier"},
+		{strings.Repeat("a", 65) + "@avasp", "longer than 64"},
+		{"alice@", "invalid vasp domain identifier"},
+		{"alice@a_vasp", "invalid vasp domain identifier"},
+		{"alice@" + strings.Repeat("a", 64), "longer than 63"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.id, func(t *testing.T) {
+			_, err := diemid.ParseDiemID(tc.id)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -2,5 +2,6 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Provides utility functions for Diem Intent Identifier and Account Identifier
-// (https://github.com/diem/lip/blob/master/lips/lip-5.md)
+// (https://github.com/diem/lip/blob/master/lips/lip-5.md), and DiemID identifier
+// (https://github.com/diem/dip/blob/main/dips/dip-10.md)
 package diemid
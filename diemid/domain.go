@@ -0,0 +1,163 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+)
+
+// TreasuryComplianceAddress is address of the treasury compliance account, which emits
+// DiemIdDomain events when a VASP domain is added or removed.
+var TreasuryComplianceAddress = diemtypes.AccountAddress{12: 0x0B, 13: 0x1E, 14: 0x55, 15: 0xED}
+
+// DefaultDomainEventsBatchSize is number of DiemIdDomain events fetched by one get_events call
+const DefaultDomainEventsBatchSize uint64 = 1000
+
+// ErrDomainNotFound is returned when the VASP domain is not registered on chain
+var ErrDomainNotFound = errors.New("vasp domain not found")
+
+// DomainResolver resolves DiemID VASP domain identifier to parent VASP account address
+type DomainResolver interface {
+	ResolveDomain(ctx context.Context, domain string) (diemtypes.AccountAddress, error)
+}
+
+// OnChainDomainResolver resolves VASP domains by replaying DiemIdDomain events of the treasury
+// compliance account, domains are cached and new events are fetched when a domain is not
+// found in cache.
+type OnChainDomainResolver struct {
+	Client    jsonrpc.Client
+	BatchSize uint64
+
+	mux       sync.Mutex
+	eventsKey string
+	cursor    uint64
+	domains   map[string]diemtypes.AccountAddress
+}
+
+// NewOnChainDomainResolver creates `OnChainDomainResolver` with the JSON-RPC client connecting
+// to a Diem full node.
+func NewOnChainDomainResolver(client jsonrpc.Client) *OnChainDomainResolver {
+	return &OnChainDomainResolver{
+		Client:    client,
+		BatchSize: DefaultDomainEventsBatchSize,
+		domains:   make(map[string]diemtypes.AccountAddress),
+	}
+}
+
+type domainEvent struct {
+	Data struct {
+		Type    string `json:"type"`
+		Removed bool   `json:"removed"`
+		Domain  string `json:"domain"`
+		Address string `json:"address"`
+	} `json:"data"`
+}
+
+// ResolveDomain implements `DomainResolver`, domain is case-insensitive.
+// Returns `ErrDomainNotFound` if the domain is not registered or has been removed.
+func (r *OnChainDomainResolver) ResolveDomain(ctx context.Context, domain string) (diemtypes.AccountAddress, error) {
+	domain = strings.ToLower(domain)
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if address, ok := r.domains[domain]; ok {
+		return address, nil
+	}
+	if err := r.sync(ctx); err != nil {
+		return diemtypes.AccountAddress{}, err
+	}
+	if address, ok := r.domains[domain]; ok {
+		return address, nil
+	}
+	return diemtypes.AccountAddress{}, fmt.Errorf("%w: %s", ErrDomainNotFound, domain)
+}
+
+// Resolve resolves parent VASP account address of the DiemID
+func (r *OnChainDomainResolver) Resolve(ctx context.Context, id *DiemID) (diemtypes.AccountAddress, error) {
+	return r.ResolveDomain(ctx, id.VaspDomainIdentifier)
+}
+
+func (r *OnChainDomainResolver) sync(ctx context.Context) error {
+	if r.eventsKey == "" {
+		key, err := r.domainEventsKey(ctx)
+		if err != nil {
+			return err
+		}
+		r.eventsKey = key
+	}
+	if r.domains == nil {
+		r.domains = make(map[string]diemtypes.AccountAddress)
+	}
+	limit := r.BatchSize
+	if limit == 0 {
+		limit = DefaultDomainEventsBatchSize
+	}
+	for {
+		var events []*domainEvent
+		if err := r.call(ctx, &events, "get_events", r.eventsKey, r.cursor, limit); err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := r.apply(event); err != nil {
+				return err
+			}
+			r.cursor++
+		}
+		if uint64(len(events)) < limit {
+			return nil
+		}
+	}
+}
+
+func (r *OnChainDomainResolver) apply(event *domainEvent) error {
+	domain := strings.ToLower(event.Data.Domain)
+	if event.Data.Removed {
+		delete(r.domains, domain)
+		return nil
+	}
+	address, err := diemtypes.MakeAccountAddress(event.Data.Address)
+	if err != nil {
+		return fmt.Errorf("invalid address of domain %s: %v", domain, err)
+	}
+	r.domains[domain] = address
+	return nil
+}
+
+func (r *OnChainDomainResolver) domainEventsKey(ctx context.Context) (string, error) {
+	var account *struct {
+		Role struct {
+			DiemIDDomainEventsKey string `json:"diem_id_domain_events_key"`
+		} `json:"role"`
+	}
+	if err := r.call(ctx, &account, "get_account", TreasuryComplianceAddress.Hex()); err != nil {
+		return "", err
+	}
+	if account == nil || account.Role.DiemIDDomainEventsKey == "" {
+		return "", errors.New("treasury compliance account diem id domain events key not found")
+	}
+	return account.Role.DiemIDDomainEventsKey, nil
+}
+
+func (r *OnChainDomainResolver) call(ctx context.Context, ret interface{}, method jsonrpc.Method, params ...jsonrpc.Param) error {
+	req := jsonrpc.NewRequest(method, params...)
+	resps, err := jsonrpc.CallWithContext(ctx, r.Client, req)
+	if err != nil {
+		return err
+	}
+	resp, ok := resps[req.ID]
+	if !ok {
+		return fmt.Errorf("missing %s response", method)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	_, err = resp.UnmarshalResult(ret)
+	return err
+}
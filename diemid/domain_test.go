@@ -0,0 +1,79 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemid_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// domainStub responds get_account with treasury compliance account, and get_events with
+// the domain events starting from the requested sequence number.
+type domainStub struct {
+	events []string
+	calls  int
+}
+
+func (s *domainStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	s.calls++
+	req := requests[0]
+	var result json.RawMessage
+	switch req.Method {
+	case "get_account":
+		result = json.RawMessage(`{"role": {"type": "treasury_compliance", "diem_id_domain_events_key": "key"}}`)
+	case "get_events":
+		start, limit := req.Params[1].(uint64), req.Params[2].(uint64)
+		events := []json.RawMessage{}
+		for i := start; i < uint64(len(s.events)) && i < start+limit; i++ {
+			events = append(events, json.RawMessage(s.events[i]))
+		}
+		result, _ = json.Marshal(events)
+	}
+	return map[jsonrpc.RequestID]*jsonrpc.Response{req.ID: {ID: &req.ID, Result: &result}}, nil
+}
+
+func domainEvent(domain string, address string, removed bool) string {
+	return fmt.Sprintf(`{"data": {"type": "diemiddomain", "domain": %q, "address": %q, "removed": %v}}`,
+		domain, address, removed)
+}
+
+func TestOnChainDomainResolver(t *testing.T) {
+	vasp := "f72589b71ff4f8d139674a3f7369c69b"
+	stub := &domainStub{events: []string{
+		domainEvent("avasp", vasp, false),
+		domainEvent("bvasp", vasp, false),
+		domainEvent("bvasp", vasp, true),
+	}}
+	resolver := diemid.NewOnChainDomainResolver(stub)
+	resolver.BatchSize = 2
+
+	id, err := diemid.ParseDiemID("alice@AVASP")
+	require.NoError(t, err)
+	address, err := resolver.Resolve(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, diemtypes.MustMakeAccountAddress(vasp), address)
+	assert.Equal(t, 3, stub.calls)
+
+	// cached
+	_, err = resolver.ResolveDomain(context.Background(), "avasp")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stub.calls)
+
+	_, err = resolver.ResolveDomain(context.Background(), "bvasp")
+	assert.True(t, errors.Is(err, diemid.ErrDomainNotFound))
+
+	stub.events = append(stub.events, domainEvent("cvasp", vasp, false))
+	address, err = resolver.ResolveDomain(context.Background(), "cvasp")
+	require.NoError(t, err)
+	assert.Equal(t, diemtypes.MustMakeAccountAddress(vasp), address)
+}
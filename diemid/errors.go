@@ -0,0 +1,54 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemid
+
+import "fmt"
+
+// DecodeErrorKind classifies `DecodeError`
+type DecodeErrorKind string
+
+// List of account identifier decode error kinds
+const (
+	// DecodeErrorInvalidFormat is for malformed bech32 string, e.g. mixed case, invalid
+	// characters or missing separator
+	DecodeErrorInvalidFormat   DecodeErrorKind = "invalid_format"
+	DecodeErrorInvalidChecksum DecodeErrorKind = "invalid_checksum"
+	// DecodeErrorWrongNetwork is for valid account identifier of a different network prefix
+	DecodeErrorWrongNetwork   DecodeErrorKind = "wrong_network"
+	DecodeErrorInvalidVersion DecodeErrorKind = "invalid_version"
+	DecodeErrorInvalidLength  DecodeErrorKind = "invalid_length"
+)
+
+// Sentinel decode errors for matching `DecodeError` kind by `errors.Is`
+var (
+	ErrInvalidFormat   = &DecodeError{Kind: DecodeErrorInvalidFormat}
+	ErrInvalidChecksum = &DecodeError{Kind: DecodeErrorInvalidChecksum}
+	ErrWrongNetwork    = &DecodeError{Kind: DecodeErrorWrongNetwork}
+	ErrInvalidVersion  = &DecodeError{Kind: DecodeErrorInvalidVersion}
+	ErrInvalidLength   = &DecodeError{Kind: DecodeErrorInvalidLength}
+)
+
+// DecodeError is error returned by `DecodeToAccount` for invalid account identifier.
+// For `DecodeErrorWrongNetwork`, `Actual` is the network prefix of the account identifier.
+type DecodeError struct {
+	Kind     DecodeErrorKind
+	Expected NetworkPrefix
+	Actual   NetworkPrefix
+	Msg      string
+}
+
+// Error implements error interface
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("invalid account identifier, %s: %s", e.Kind, e.Msg)
+}
+
+// Is matches decode error with same kind
+func (e *DecodeError) Is(target error) bool {
+	t, ok := target.(*DecodeError)
+	return ok && t.Kind == e.Kind
+}
+
+func newDecodeError(kind DecodeErrorKind, expected NetworkPrefix, format string, args ...interface{}) *DecodeError {
+	return &DecodeError{Kind: kind, Expected: expected, Msg: fmt.Sprintf(format, args...)}
+}
@@ -13,15 +13,34 @@ import (
 )
 
 const (
-	DiemScheme        = "diem"
-	CurrencyParamName = "c"
-	AmountParamName   = "am"
+	DiemScheme                 = "diem"
+	CurrencyParamName          = "c"
+	AmountParamName            = "am"
+	ExpirationTimeParamName    = "exp"
+	MerchantReferenceParamName = "ref"
+	RedirectURLParamName       = "redirect"
 )
 
+var knownParamNames = map[string]bool{
+	CurrencyParamName:          true,
+	AmountParamName:            true,
+	ExpirationTimeParamName:    true,
+	MerchantReferenceParamName: true,
+	RedirectURLParamName:       true,
+}
+
 // Params for Intent
 type Params struct {
 	Currency string
 	Amount   *uint64
+	// ExpirationTime is unix timestamp in seconds after which the intent should not be paid
+	ExpirationTime *uint64
+	// MerchantReference is opaque reference id of the merchant order
+	MerchantReference string
+	// RedirectURL is the url wallet redirects to after payment
+	RedirectURL string
+	// Extra holds query parameters other than the above, nil if there is none
+	Extra map[string]string
 }
 
 // Intent captures all parts of intent identifier
@@ -44,15 +63,40 @@ func DecodeToIntent(networkPrefix NetworkPrefix, intent string) (*Intent, error)
 	if err != nil {
 		return nil, fmt.Errorf("invalid account identifier: %s", err.Error())
 	}
-	return &Intent{
-		Account: *account,
-		Params: Params{
-			Currency: u.Query().Get(CurrencyParamName),
-			Amount:   toIntPtr(u.Query().Get(AmountParamName)),
-		},
-	}, nil
+	query := u.Query()
+	params := Params{
+		Currency:          query.Get(CurrencyParamName),
+		Amount:            toIntPtr(query.Get(AmountParamName)),
+		MerchantReference: query.Get(MerchantReferenceParamName),
+		RedirectURL:       query.Get(RedirectURLParamName),
+	}
+	if exp := query.Get(ExpirationTimeParamName); exp != "" {
+		expirationTime, err := strconv.ParseUint(exp, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiration time: %s", err.Error())
+		}
+		params.ExpirationTime = &expirationTime
+	}
+	if params.RedirectURL != "" {
+		if err := ValidateRedirectURL(params.RedirectURL); err != nil {
+			return nil, err
+		}
+	}
+	for name := range query {
+		if knownParamNames[name] {
+			continue
+		}
+		if params.Extra == nil {
+			params.Extra = make(map[string]string)
+		}
+		params.Extra[name] = query.Get(name)
+	}
+	return &Intent{Account: *account, Params: params}, nil
 }
 
+// Encode encodes intent into url string, query parameters are sorted by name.
+// Returns error if `Params.RedirectURL` is not an http or https url, or a name of `Params.Extra` conflicts with
+// known parameter names.
 func (i *Intent) Encode() (string, error) {
 	encoded, err := i.Account.Encode()
 	if err != nil {
@@ -66,10 +110,44 @@ func (i *Intent) Encode() (string, error) {
 	if i.Params.Amount != nil {
 		q.Add(AmountParamName, strconv.FormatUint(*i.Params.Amount, 10))
 	}
+	if i.Params.ExpirationTime != nil {
+		q.Add(ExpirationTimeParamName, strconv.FormatUint(*i.Params.ExpirationTime, 10))
+	}
+	if i.Params.MerchantReference != "" {
+		q.Add(MerchantReferenceParamName, i.Params.MerchantReference)
+	}
+	if i.Params.RedirectURL != "" {
+		if err := ValidateRedirectURL(i.Params.RedirectURL); err != nil {
+			return "", err
+		}
+		q.Add(RedirectURLParamName, i.Params.RedirectURL)
+	}
+	for name, value := range i.Params.Extra {
+		if knownParamNames[name] {
+			return "", fmt.Errorf("extra param name conflicts with known param: %s", name)
+		}
+		q.Add(name, value)
+	}
 	u.RawQuery = q.Encode()
 	return u.String(), nil
 }
 
+// validateRedirectURL only accepts absolute http or https url, so that a wallet won't follow
+// a redirect url like `javascript:...`
+func ValidateRedirectURL(redirectURL string) error {
+	u, err := url.ParseRequestURI(redirectURL)
+	if err != nil {
+		return fmt.Errorf("invalid redirect url: %s", err.Error())
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid redirect url scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid redirect url: missing host")
+	}
+	return nil
+}
+
 func toIntPtr(str string) *uint64 {
 	ret, err := strconv.ParseUint(str, 10, 64)
 	if err != nil {
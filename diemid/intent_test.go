@@ -5,6 +5,7 @@ package diemid_test
 
 import (
 	"fmt"
+	"net/url"
 	"testing"
 
 	"github.com/diem/client-sdk-go/diemid"
@@ -49,6 +50,36 @@ func TestEncodeDecodeIntent(t *testing.T) {
 		require.NotNil(t, ret)
 		assert.Equal(t, intent, *ret)
 	})
+
+	t.Run("with extension params", func(t *testing.T) {
+		amount := uint64(123)
+		expiration := uint64(1611792876)
+		intent := diemid.Intent{
+			Account: *account,
+			Params: diemid.Params{
+				Currency:          "XUS",
+				Amount:            &amount,
+				ExpirationTime:    &expiration,
+				MerchantReference: "order 1&2",
+				RedirectURL:       "https://merchant.com/orders/1?status=paid",
+				Extra:             map[string]string{"memo": "thank you"},
+			},
+		}
+		intentEncode, err := intent.Encode()
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(
+			"diem://%s?am=123&c=XUS&exp=1611792876&memo=thank+you&redirect=https%%3A%%2F%%2Fmerchant.com%%2Forders%%2F1%%3Fstatus%%3Dpaid&ref=order+1%%262",
+			accountEncode), intentEncode)
+
+		ret, err := diemid.DecodeToIntent(diemid.MainnetPrefix, intentEncode)
+		require.NoError(t, err)
+		require.NotNil(t, ret)
+		assert.Equal(t, intent, *ret)
+
+		again, err := ret.Encode()
+		require.NoError(t, err)
+		assert.Equal(t, intentEncode, again)
+	})
 }
 
 func TestDecodeIntentErrors(t *testing.T) {
@@ -70,6 +101,36 @@ func TestDecodeIntentErrors(t *testing.T) {
 		require.Nil(t, ret)
 		assert.Contains(t, err.Error(), "invalid account identifier")
 	})
+	t.Run("invalid redirect url", func(t *testing.T) {
+		address, _ := diemtypes.MakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+		accountEncode, _ := diemid.EncodeAccount(diemid.MainnetPrefix, address, diemtypes.EmptySubAddress)
+		ret, err := diemid.DecodeToIntent(diemid.MainnetPrefix, "diem://"+accountEncode+"?redirect=orders")
+		require.Error(t, err)
+		require.Nil(t, ret)
+		assert.Contains(t, err.Error(), "invalid redirect url")
+	})
+	t.Run("redirect url scheme is not http or https", func(t *testing.T) {
+		address, _ := diemtypes.MakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+		accountEncode, _ := diemid.EncodeAccount(diemid.MainnetPrefix, address, diemtypes.EmptySubAddress)
+		ret, err := diemid.DecodeToIntent(diemid.MainnetPrefix,
+			"diem://"+accountEncode+"?redirect="+url.QueryEscape("javascript:alert(1)"))
+		require.Error(t, err)
+		require.Nil(t, ret)
+		assert.Contains(t, err.Error(), "invalid redirect url scheme")
+
+		account := diemid.NewAccount(diemid.MainnetPrefix, address, diemtypes.EmptySubAddress)
+		intent := diemid.Intent{Account: *account, Params: diemid.Params{RedirectURL: "javascript:alert(1)"}}
+		_, err = intent.Encode()
+		assert.Error(t, err)
+	})
+	t.Run("invalid expiration time", func(t *testing.T) {
+		address, _ := diemtypes.MakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+		accountEncode, _ := diemid.EncodeAccount(diemid.MainnetPrefix, address, diemtypes.EmptySubAddress)
+		ret, err := diemid.DecodeToIntent(diemid.MainnetPrefix, "diem://"+accountEncode+"?exp=tomorrow")
+		require.Error(t, err)
+		require.Nil(t, ret)
+		assert.Contains(t, err.Error(), "invalid expiration time")
+	})
 }
 
 func TestEncodeIntentErrors(t *testing.T) {
@@ -80,4 +141,13 @@ func TestEncodeIntentErrors(t *testing.T) {
 		require.Empty(t, ret)
 		assert.Contains(t, err.Error(), "encode account identifier failed")
 	})
+	t.Run("extra param name conflicts", func(t *testing.T) {
+		address, _ := diemtypes.MakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+		account := diemid.NewAccount(diemid.MainnetPrefix, address, diemtypes.EmptySubAddress)
+		intent := diemid.Intent{Account: *account, Params: diemid.Params{Extra: map[string]string{"c": "XUS"}}}
+		ret, err := intent.Encode()
+		require.Error(t, err)
+		require.Empty(t, ret)
+		assert.Contains(t, err.Error(), "extra param name conflicts with known param: c")
+	})
 }
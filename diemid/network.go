@@ -0,0 +1,103 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MaxNetworkPrefixLength is max length of network prefix, so that account identifier with
+// sub-address fits in 90 characters bech32 string.
+const MaxNetworkPrefixLength = 43
+
+var (
+	networksMux sync.RWMutex
+	networks    = map[string]NetworkPrefix{
+		"mainnet":       MainnetPrefix,
+		"testnet":       TestnetPrefix,
+		"premainnet":    PreMainnetPrefix,
+		"dryrunmainnet": DryRunMainnetPrefix,
+	}
+)
+
+// RegisterNetworkPrefix registers network prefix with a network name, e.g. for private or
+// consortium deployments. Registered prefixes are recognized by `SuggestNetwork` and
+// `NetworkPrefixByName`.
+// Returns error if the prefix is invalid, or the name or prefix is registered for a different
+// network.
+func RegisterNetworkPrefix(name string, prefix NetworkPrefix) error {
+	if name == "" {
+		return fmt.Errorf("network name is empty")
+	}
+	if err := ValidateNetworkPrefix(prefix); err != nil {
+		return err
+	}
+	networksMux.Lock()
+	defer networksMux.Unlock()
+	if existing, ok := networks[name]; ok {
+		if existing == prefix {
+			return nil
+		}
+		return fmt.Errorf("network %s is registered with prefix %s", name, existing)
+	}
+	for existingName, existing := range networks {
+		if existing == prefix {
+			return fmt.Errorf("network prefix %s is registered by network %s", prefix, existingName)
+		}
+	}
+	networks[name] = prefix
+	return nil
+}
+
+// ValidateNetworkPrefix validates the prefix meets bech32 human-readable part rules: 1 to
+// `MaxNetworkPrefixLength` lower case US-ASCII characters in range [33, 126].
+func ValidateNetworkPrefix(prefix NetworkPrefix) error {
+	if len(prefix) < 1 || len(prefix) > MaxNetworkPrefixLength {
+		return fmt.Errorf("invalid network prefix %#v: length must be between 1 and %d", prefix, MaxNetworkPrefixLength)
+	}
+	for i, c := range prefix {
+		if c < 33 || c > 126 {
+			return fmt.Errorf("invalid network prefix %#v: invalid character at %d", prefix, i)
+		}
+	}
+	if strings.ToLower(string(prefix)) != string(prefix) {
+		return fmt.Errorf("invalid network prefix %#v: must be lower case", prefix)
+	}
+	return nil
+}
+
+// NetworkPrefixByName returns registered network prefix of given network name
+func NetworkPrefixByName(name string) (NetworkPrefix, bool) {
+	networksMux.RLock()
+	defer networksMux.RUnlock()
+	prefix, ok := networks[name]
+	return prefix, ok
+}
+
+// NetworkName returns registered network name of given network prefix
+func NetworkName(prefix NetworkPrefix) (string, bool) {
+	networksMux.RLock()
+	defer networksMux.RUnlock()
+	for name, p := range networks {
+		if p == prefix {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// NetworkNames returns sorted names of registered networks
+func NetworkNames() []string {
+	networksMux.RLock()
+	defer networksMux.RUnlock()
+	ret := make([]string, 0, len(networks))
+	for name := range networks {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
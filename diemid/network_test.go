@@ -0,0 +1,61 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemid_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterNetworkPrefix(t *testing.T) {
+	prefix, ok := diemid.NetworkPrefixByName("testnet")
+	assert.True(t, ok)
+	assert.Equal(t, diemid.TestnetPrefix, prefix)
+
+	require.NoError(t, diemid.RegisterNetworkPrefix("consortium", "cdm"))
+	require.NoError(t, diemid.RegisterNetworkPrefix("consortium", "cdm"))
+	prefix, ok = diemid.NetworkPrefixByName("consortium")
+	assert.True(t, ok)
+	assert.Equal(t, diemid.NetworkPrefix("cdm"), prefix)
+	name, ok := diemid.NetworkName("cdm")
+	assert.True(t, ok)
+	assert.Equal(t, "consortium", name)
+	assert.Contains(t, diemid.NetworkNames(), "consortium")
+
+	address, _ := diemtypes.MakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	encoded, err := diemid.EncodeAccount("cdm", address, diemtypes.EmptySubAddress)
+	require.NoError(t, err)
+	suggested, err := diemid.SuggestNetwork(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, diemid.NetworkPrefix("cdm"), suggested)
+
+	assert.EqualError(t, diemid.RegisterNetworkPrefix("consortium", "cdm2"),
+		"network consortium is registered with prefix cdm")
+	assert.EqualError(t, diemid.RegisterNetworkPrefix("other", "tdm"),
+		"network prefix tdm is registered by network testnet")
+	assert.Error(t, diemid.RegisterNetworkPrefix("", "odm"))
+}
+
+func TestValidateNetworkPrefix(t *testing.T) {
+	assert.NoError(t, diemid.ValidateNetworkPrefix("dm"))
+	assert.Error(t, diemid.ValidateNetworkPrefix(""))
+	assert.Error(t, diemid.ValidateNetworkPrefix("DM"))
+	assert.Error(t, diemid.ValidateNetworkPrefix("d m"))
+	assert.Error(t, diemid.ValidateNetworkPrefix("dmé"))
+	assert.Error(t, diemid.ValidateNetworkPrefix(diemid.NetworkPrefix(strings.Repeat("d", 44))))
+
+	// longest prefix still encodes account identifier with sub-address
+	address, _ := diemtypes.MakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	prefix := diemid.NetworkPrefix(strings.Repeat("d", diemid.MaxNetworkPrefixLength))
+	assert.NoError(t, diemid.ValidateNetworkPrefix(prefix))
+	encoded, err := diemid.EncodeAccount(prefix, address, diemtypes.EmptySubAddress)
+	require.NoError(t, err)
+	_, err = diemid.DecodeToAccount(prefix, encoded)
+	require.NoError(t, err)
+}
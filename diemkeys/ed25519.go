@@ -6,6 +6,7 @@ package diemkeys
 import (
 	"crypto/ed25519"
 	"encoding/hex"
+	"fmt"
 )
 
 // Ed25519PublicKey implements `PublicKey` interface for ed25519 public key
@@ -13,9 +14,11 @@ type Ed25519PublicKey struct {
 	pk ed25519.PublicKey
 }
 
-// Ed25519PrivateKey implements `PrivateKey` interface for ed25519 private key
+// Ed25519PrivateKey implements `PrivateKey` and `Destroyer` interfaces for ed25519 private key.
+// The key bytes are held in a memory locked buffer when the platform supports it, which is
+// zeroed by `Destroy`, or by finalizer when the key is garbage collected.
 type Ed25519PrivateKey struct {
-	pk ed25519.PrivateKey
+	secret *secret
 }
 
 // NewEd25519PublicKey creates `Ed25519PublicKey`
@@ -23,9 +26,10 @@ func NewEd25519PublicKey(key ed25519.PublicKey) *Ed25519PublicKey {
 	return &Ed25519PublicKey{key}
 }
 
-// NewEd25519PrivateKey creates `Ed25519PrivateKey`
+// NewEd25519PrivateKey creates `Ed25519PrivateKey`.
+// The given key bytes are copied, caller should `Zero` the given key if it is not used anymore.
 func NewEd25519PrivateKey(key ed25519.PrivateKey) *Ed25519PrivateKey {
-	return &Ed25519PrivateKey{key}
+	return &Ed25519PrivateKey{newSecret(key)}
 }
 
 // NewEd25519PublicKeyFromString creates `*Ed25519PublicKey` from given hex-encoded
@@ -45,6 +49,10 @@ func NewEd25519PrivateKeyFromString(key string) (*Ed25519PrivateKey, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer Zero(bytes)
+	if len(bytes) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid ed25519 private key size %d, expected %d", len(bytes), ed25519.PrivateKeySize)
+	}
 	return NewEd25519PrivateKey(ed25519.PrivateKey(bytes)), nil
 }
 
@@ -63,12 +71,57 @@ func (k *Ed25519PublicKey) Bytes() []byte {
 	return []byte(k.pk)
 }
 
-// Sign signs given message bytes by private key
+// Sign signs given message bytes by private key.
+// Panics with `ErrKeyDestroyed` if the key is destroyed.
 func (k *Ed25519PrivateKey) Sign(msg []byte) []byte {
-	return ed25519.Sign(k.pk, msg)
+	var ret []byte
+	k.use(func(key ed25519.PrivateKey) {
+		ret = ed25519.Sign(key, msg)
+	})
+	return ret
 }
 
-// Hex returns hex string of private key, used for testing
+// Hex returns hex string of private key, used for testing.
+// Panics with `ErrKeyDestroyed` if the key is destroyed.
 func (k *Ed25519PrivateKey) Hex() string {
-	return hex.EncodeToString(k.pk)
+	var ret string
+	k.use(func(key ed25519.PrivateKey) {
+		ret = hex.EncodeToString(key)
+	})
+	return ret
+}
+
+// PublicKey returns `Ed25519PublicKey` of the private key.
+// Panics with `ErrKeyDestroyed` if the key is destroyed.
+func (k *Ed25519PrivateKey) PublicKey() *Ed25519PublicKey {
+	var ret ed25519.PublicKey
+	k.use(func(key ed25519.PrivateKey) {
+		ret = key.Public().(ed25519.PublicKey)
+	})
+	return NewEd25519PublicKey(ret)
+}
+
+// Destroy zeroes the private key bytes, the key can't be used after destroyed. It is best
+// effort, see `Destroyer`. It is safe to call Destroy multiple times.
+func (k *Ed25519PrivateKey) Destroy() {
+	k.secret.destroy()
+}
+
+// IsDestroyed returns true if the key is destroyed
+func (k *Ed25519PrivateKey) IsDestroyed() bool {
+	return k.secret.destroyed()
+}
+
+// use calls given function with the private key, panics with `ErrKeyDestroyed` if the key is
+// destroyed; see `tryUse` for returning the error.
+func (k *Ed25519PrivateKey) use(fn func(ed25519.PrivateKey)) {
+	if err := k.tryUse(fn); err != nil {
+		panic(err)
+	}
+}
+
+func (k *Ed25519PrivateKey) tryUse(fn func(ed25519.PrivateKey)) error {
+	return k.secret.use(func(b []byte) {
+		fn(ed25519.PrivateKey(b))
+	})
 }
@@ -44,9 +44,40 @@ func TestEd25519PrivateKey(t *testing.T) {
 	t.Run("hex", func(t *testing.T) {
 		assert.Equal(t, keyHex, key.Hex())
 	})
+	t.Run("public key", func(t *testing.T) {
+		assert.Equal(t, keyHex[64:], key.PublicKey().Hex())
+	})
+	t.Run("given key bytes are copied", func(t *testing.T) {
+		bytes, _ := hex.DecodeString(keyHex)
+		copied := diemkeys.NewEd25519PrivateKey(bytes)
+		diemkeys.Zero(bytes)
+		assert.Equal(t, make([]byte, len(bytes)), bytes)
+		assert.Equal(t, keyHex, copied.Hex())
+	})
+}
+
+func TestEd25519PrivateKeyDestroy(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	key := keys.PrivateKey.(*diemkeys.Ed25519PrivateKey)
+	assert.False(t, key.IsDestroyed())
+
+	keys.Destroy()
+	assert.True(t, key.IsDestroyed())
+	assert.PanicsWithValue(t, diemkeys.ErrKeyDestroyed, func() { key.Sign([]byte("test")) })
+	assert.PanicsWithValue(t, diemkeys.ErrKeyDestroyed, func() { key.Hex() })
+	_, err := diemkeys.MarshalPrivateKeyPEM(key)
+	assert.Equal(t, diemkeys.ErrKeyDestroyed, err)
+	_, err = diemkeys.MarshalOpenSSHPrivateKey(key, "")
+	assert.Equal(t, diemkeys.ErrKeyDestroyed, err)
+	assert.NotPanics(t, key.Destroy)
 }
 
 func TestNewEd25519PrivateKeyFromStringError(t *testing.T) {
 	_, err := diemkeys.NewEd25519PrivateKeyFromString("invalid")
 	assert.Error(t, err)
+	_, err = diemkeys.NewEd25519PrivateKeyFromString("")
+	assert.EqualError(t, err, "invalid ed25519 private key size 0, expected 64")
+	_, err = diemkeys.NewEd25519PrivateKeyFromString("0102")
+	assert.EqualError(t, err, "invalid ed25519 private key size 2, expected 64")
+	assert.NotPanics(t, func() { diemkeys.NewEd25519PrivateKey(nil).Destroy() })
 }
@@ -0,0 +1,123 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// HD key derivation constants
+const (
+	// HardenedOffset is added to hardened child key index, SLIP-0010 ed25519 only supports
+	// hardened child keys
+	HardenedOffset uint32 = 0x80000000
+	// CoinType is BIP-44 coin type of Diem derivation path
+	CoinType uint32 = 637
+
+	slip10Ed25519Seed = "ed25519 seed"
+)
+
+// GenerateMnemonic generates BIP39 English mnemonic with random entropy of given bits size,
+// which must be a multiple of 32 within [128, 256]; 256 bits entropy is 24 words.
+func GenerateMnemonic(bitSize int) (string, error) {
+	entropy, err := bip39.NewEntropy(bitSize)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// MnemonicToSeed validates the BIP39 mnemonic checksum, and returns the seed derived from the
+// mnemonic and passphrase.
+func MnemonicToSeed(mnemonic string, passphrase string) ([]byte, error) {
+	return bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+}
+
+// DerivationPath returns Diem BIP-44 derivation path of given account and address index:
+// m/44'/637'/<account>'/0'/<index>'
+func DerivationPath(account uint32, index uint32) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/0'/%d'", CoinType, account, index)
+}
+
+// ExtendedKey is SLIP-0010 ed25519 extended private key: the private key seed and chain code
+type ExtendedKey struct {
+	Key       []byte
+	ChainCode []byte
+}
+
+// NewMasterKey creates SLIP-0010 ed25519 master key from BIP39 seed
+func NewMasterKey(seed []byte) *ExtendedKey {
+	return newExtendedKey([]byte(slip10Ed25519Seed), seed)
+}
+
+// NewKeysFromMnemonic recovers `Keys` of given account and address index from the mnemonic,
+// see `DerivationPath`.
+func NewKeysFromMnemonic(mnemonic string, passphrase string, account uint32, index uint32) (*Keys, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	key, err := NewMasterKey(seed).Derive(DerivationPath(account, index))
+	if err != nil {
+		return nil, err
+	}
+	return key.Keys(), nil
+}
+
+// Child derives hardened child key of given index, `HardenedOffset` is added to the index if
+// it is not a hardened index.
+func (k *ExtendedKey) Child(index uint32) *ExtendedKey {
+	data := make([]byte, 0, 1+len(k.Key)+4)
+	data = append(data, 0)
+	data = append(data, k.Key...)
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], index|HardenedOffset)
+	return newExtendedKey(k.ChainCode, data)
+}
+
+// Derive derives child key by given path, e.g. "m/44'/637'/0'/0'/0'"; all the path
+// indexes must be hardened, as SLIP-0010 ed25519 only supports hardened child keys.
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path %q: should start with m", path)
+	}
+	ret := k
+	for _, segment := range segments[1:] {
+		if !strings.HasSuffix(segment, "'") {
+			return nil, fmt.Errorf("invalid derivation path %q: %q is not hardened", path, segment)
+		}
+		index, err := strconv.ParseUint(strings.TrimSuffix(segment, "'"), 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, fmt.Errorf("invalid derivation path %q: invalid index %q", path, segment)
+		}
+		ret = ret.Child(uint32(index))
+	}
+	return ret, nil
+}
+
+// Keys returns ed25519 `Keys` of the extended key
+func (k *ExtendedKey) Keys() *Keys {
+	privateKey := ed25519.NewKeyFromSeed(k.Key)
+	defer Zero(privateKey)
+	return NewKeysFromPublicAndPrivateKeys(
+		NewEd25519PublicKey(privateKey.Public().(ed25519.PublicKey)),
+		NewEd25519PrivateKey(privateKey),
+	)
+}
+
+func newExtendedKey(key []byte, data []byte) *ExtendedKey {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return &ExtendedKey{Key: sum[:32], ChainCode: sum[32:]}
+}
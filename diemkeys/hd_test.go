@@ -0,0 +1,79 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemkeys_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMnemonic(t *testing.T) {
+	t.Run("generate and recover", func(t *testing.T) {
+		mnemonic, err := diemkeys.GenerateMnemonic(256)
+		require.NoError(t, err)
+		assert.Len(t, strings.Fields(mnemonic), 24)
+
+		keys, err := diemkeys.NewKeysFromMnemonic(mnemonic, "", 0, 0)
+		require.NoError(t, err)
+		recovered, err := diemkeys.NewKeysFromMnemonic(mnemonic, "", 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, keys.AccountAddress(), recovered.AccountAddress())
+
+		other, err := diemkeys.NewKeysFromMnemonic(mnemonic, "", 0, 1)
+		require.NoError(t, err)
+		assert.NotEqual(t, keys.AccountAddress(), other.AccountAddress())
+	})
+	t.Run("seed", func(t *testing.T) {
+		seed, err := diemkeys.MnemonicToSeed(strings.Repeat("abandon ", 11)+"about", "TREZOR")
+		require.NoError(t, err)
+		assert.Equal(t, "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04", hex.EncodeToString(seed))
+	})
+	t.Run("invalid mnemonic", func(t *testing.T) {
+		_, err := diemkeys.MnemonicToSeed(strings.Repeat("abandon ", 12), "")
+		assert.Error(t, err)
+		_, err = diemkeys.GenerateMnemonic(100)
+		assert.Error(t, err)
+	})
+}
+
+func TestExtendedKey(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master := diemkeys.NewMasterKey(seed)
+
+	cases := []struct {
+		path      string
+		key       string
+		chainCode string
+	}{
+		{"m", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7", "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb"},
+		{"m/0'", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3", "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69"},
+		{"m/0'/1'", "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2", "a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			key, err := master.Derive(tc.path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.key, hex.EncodeToString(key.Key))
+			assert.Equal(t, tc.chainCode, hex.EncodeToString(key.ChainCode))
+		})
+	}
+
+	t.Run("derivation path", func(t *testing.T) {
+		assert.Equal(t, "m/44'/637'/1'/0'/2'", diemkeys.DerivationPath(1, 2))
+		key, err := master.Derive(diemkeys.DerivationPath(1, 2))
+		require.NoError(t, err)
+		assert.Equal(t, master.Child(44).Child(637).Child(1).Child(0).Child(2), key)
+	})
+	t.Run("invalid path", func(t *testing.T) {
+		for _, path := range []string{"", "0'", "m/0", "m/x'", "m/2147483648'"} {
+			_, err := master.Derive(path)
+			assert.Error(t, err, path)
+		}
+	})
+}
@@ -23,6 +23,14 @@ type PrivateKey interface {
 	Sign(msg []byte) []byte
 }
 
+// Destroyer is optionally implemented by `PrivateKey` holding key bytes in process memory.
+// Destroy zeroes the private key bytes, the key can't be used for signing after destroyed.
+// It is best effort: copies of the key bytes made before, e.g. by the Go runtime, the expanded
+// key cached by crypto/ed25519, or the caller's decoded key bytes, are not zeroed.
+type Destroyer interface {
+	Destroy()
+}
+
 // Keys holds Diem local account keys
 type Keys struct {
 	PublicKey  PublicKey
@@ -38,6 +46,13 @@ func (k *Keys) AuthKey() AuthKey {
 	return NewAuthKey(k.PublicKey)
 }
 
+// Destroy destroys the private key if it implements `Destroyer`, otherwise it does nothing
+func (k *Keys) Destroy() {
+	if d, ok := k.PrivateKey.(Destroyer); ok {
+		d.Destroy()
+	}
+}
+
 // NewKeysFromPublicAndPrivateKeys creates new `Keys` from given public key and private key
 func NewKeysFromPublicAndPrivateKeys(publicKey PublicKey, privateKey PrivateKey) *Keys {
 	return &Keys{
@@ -52,6 +67,7 @@ func MustGenKeys() *Keys {
 	if err != nil {
 		panic(err)
 	}
+	defer Zero(privateKey)
 	return NewKeysFromPublicAndPrivateKeys(
 		NewEd25519PublicKey(publicKey), NewEd25519PrivateKey(privateKey))
 }
@@ -69,6 +85,7 @@ func MustGenMultiSigKeys() *Keys {
 		if err != nil {
 			panic(err)
 		}
+		defer Zero(privateKeys[i])
 	}
 	threshold := 1 + rand.Intn(numOfKeys)
 	return NewKeysFromPublicAndPrivateKeys(
@@ -30,3 +30,12 @@ func TestMustGenMultiSigKeys(t *testing.T) {
 		assert.NotEqual(t, keys.PrivateKey, keys2.PrivateKey)
 	}
 }
+
+type remoteKey struct{}
+
+func (remoteKey) Sign(msg []byte) []byte { return nil }
+
+func TestKeysDestroyWithoutDestroyer(t *testing.T) {
+	keys := diemkeys.NewKeysFromPublicAndPrivateKeys(diemkeys.MustGenKeys().PublicKey, remoteKey{})
+	assert.NotPanics(t, keys.Destroy)
+}
@@ -0,0 +1,9 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides encrypted-at-rest keystore for ed25519 account keys.
+//
+// Each key is stored as a JSON file named by the account address in the keystore directory; the
+// private key is encrypted by AES-256-GCM with a key derived from the passphrase by scrypt.
+// Key files with scrypt parameters above `MaxScryptN` or `MaxScryptP` are rejected.
+package keystore
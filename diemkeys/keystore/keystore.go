@@ -0,0 +1,295 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Scrypt parameters
+const (
+	// StandardScryptN is scrypt N parameter for keys stored in production, it takes about 1
+	// second to derive the encryption key on a modern CPU.
+	StandardScryptN = 1 << 18
+	// LightScryptN is scrypt N parameter for testing or memory constrained environments
+	LightScryptN = 1 << 12
+	// StandardScryptP is scrypt P parameter
+	StandardScryptP = 1
+	// MaxScryptN and MaxScryptP are the max scrypt parameters accepted for decrypting a key file,
+	// they bound the memory and CPU a key file can make `Decrypt` spend.
+	MaxScryptN = 1 << 20
+	MaxScryptP = 16
+
+	scryptR     = 8
+	scryptDKLen = 32
+	version     = 1
+	fileExt     = ".json"
+)
+
+// ErrDecrypt is returned when the passphrase is wrong or the key file is corrupted
+var ErrDecrypt = errors.New("could not decrypt key with given passphrase")
+
+// KeyStore stores encrypted ed25519 keys in a directory
+type KeyStore struct {
+	Dir     string
+	ScryptN int
+	ScryptP int
+}
+
+// New creates `KeyStore` with given directory and standard scrypt parameters
+func New(dir string) *KeyStore {
+	return &KeyStore{Dir: dir, ScryptN: StandardScryptN, ScryptP: StandardScryptP}
+}
+
+// Store encrypts the keys by given passphrase and writes the key file, overwrites existing key
+// file of the same account address. Only ed25519 keys are supported.
+func (ks *KeyStore) Store(keys *diemkeys.Keys, passphrase string) (diemtypes.AccountAddress, error) {
+	address := keys.AccountAddress()
+	data, err := Encrypt(keys, passphrase, ks.ScryptN, ks.ScryptP)
+	if err != nil {
+		return address, err
+	}
+	if err := os.MkdirAll(ks.Dir, 0700); err != nil {
+		return address, err
+	}
+	return address, writeFile(ks.path(address), data)
+}
+
+// Load reads and decrypts the keys of given account address
+func (ks *KeyStore) Load(address diemtypes.AccountAddress, passphrase string) (*diemkeys.Keys, error) {
+	data, err := ioutil.ReadFile(ks.path(address))
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(data, passphrase)
+}
+
+// List returns account addresses of the stored keys, ordered by address
+func (ks *KeyStore) List() ([]diemtypes.AccountAddress, error) {
+	files, err := ioutil.ReadDir(ks.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ret []diemtypes.AccountAddress
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), fileExt) {
+			continue
+		}
+		address, err := diemtypes.MakeAccountAddress(strings.TrimSuffix(f.Name(), fileExt))
+		if err != nil {
+			continue
+		}
+		ret = append(ret, address)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Hex() < ret[j].Hex() })
+	return ret, nil
+}
+
+// ChangePassphrase re-encrypts the keys of given account address with the new passphrase
+func (ks *KeyStore) ChangePassphrase(address diemtypes.AccountAddress, passphrase, newPassphrase string) error {
+	keys, err := ks.Load(address, passphrase)
+	if err != nil {
+		return err
+	}
+	data, err := Encrypt(keys, newPassphrase, ks.ScryptN, ks.ScryptP)
+	if err != nil {
+		return err
+	}
+	return writeFile(ks.path(address), data)
+}
+
+// Delete removes the key file of given account address after verifying the passphrase
+func (ks *KeyStore) Delete(address diemtypes.AccountAddress, passphrase string) error {
+	if _, err := ks.Load(address, passphrase); err != nil {
+		return err
+	}
+	return os.Remove(ks.path(address))
+}
+
+func (ks *KeyStore) path(address diemtypes.AccountAddress) string {
+	return filepath.Join(ks.Dir, address.Hex()+fileExt)
+}
+
+type keyFile struct {
+	Version   int        `json:"version"`
+	Address   string     `json:"address"`
+	PublicKey string     `json:"public_key"`
+	Crypto    cryptoJSON `json:"crypto"`
+}
+
+type cryptoJSON struct {
+	Cipher     string     `json:"cipher"`
+	CipherText string     `json:"ciphertext"`
+	Nonce      string     `json:"nonce"`
+	KDF        string     `json:"kdf"`
+	KDFParams  scryptJSON `json:"kdfparams"`
+}
+
+type scryptJSON struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// Encrypt encrypts the ed25519 keys by given passphrase and scrypt parameters, returns the key
+// file JSON.
+func Encrypt(keys *diemkeys.Keys, passphrase string, scryptN, scryptP int) ([]byte, error) {
+	privateKey, ok := keys.PrivateKey.(*diemkeys.Ed25519PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type: %T", keys.PrivateKey)
+	}
+	plaintext, err := hex.DecodeString(privateKey.Hex())
+	if err != nil {
+		return nil, err
+	}
+	defer diemkeys.Zero(plaintext)
+	if err := validateScryptParams(scryptN, scryptR, scryptP, scryptDKLen); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	address := keys.AccountAddress()
+	ciphertext := gcm.Seal(nil, nonce, plaintext, address[:])
+	return json.MarshalIndent(keyFile{
+		Version:   version,
+		Address:   address.Hex(),
+		PublicKey: keys.PublicKey.Hex(),
+		Crypto: cryptoJSON{
+			Cipher:     "aes-256-gcm",
+			CipherText: hex.EncodeToString(ciphertext),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        "scrypt",
+			KDFParams: scryptJSON{
+				N: scryptN, R: scryptR, P: scryptP, DKLen: scryptDKLen,
+				Salt: hex.EncodeToString(salt),
+			},
+		},
+	}, "", "  ")
+}
+
+// Decrypt decrypts key file JSON by given passphrase.
+// Returns `ErrDecrypt` if the passphrase is wrong.
+func Decrypt(data []byte, passphrase string) (*diemkeys.Keys, error) {
+	var f keyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Version != version || f.Crypto.Cipher != "aes-256-gcm" || f.Crypto.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported key file version %d, cipher %s, kdf %s", f.Version, f.Crypto.Cipher, f.Crypto.KDF)
+	}
+	address, err := diemtypes.MakeAccountAddress(f.Address)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(f.Crypto.KDFParams.Salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(f.Crypto.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := hex.DecodeString(f.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	params := f.Crypto.KDFParams
+	if err := validateScryptParams(params.N, params.R, params.P, params.DKLen); err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, address[:])
+	if err != nil || len(plaintext) != ed25519.PrivateKeySize {
+		return nil, ErrDecrypt
+	}
+	defer diemkeys.Zero(plaintext)
+	privateKey := ed25519.PrivateKey(plaintext)
+	keys := diemkeys.NewKeysFromPublicAndPrivateKeys(
+		diemkeys.NewEd25519PublicKey(privateKey.Public().(ed25519.PublicKey)),
+		diemkeys.NewEd25519PrivateKey(privateKey),
+	)
+	if keys.AccountAddress() != address {
+		keys.Destroy()
+		return nil, ErrDecrypt
+	}
+	return keys, nil
+}
+
+func validateScryptParams(n, r, p, dkLen int) error {
+	if n <= 1 || n > MaxScryptN || n&(n-1) != 0 || r != scryptR || p < 1 || p > MaxScryptP || dkLen != scryptDKLen {
+		return fmt.Errorf("unsupported scrypt parameters n=%d, r=%d, p=%d, dklen=%d", n, r, p, dkLen)
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func writeFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
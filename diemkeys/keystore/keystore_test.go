@@ -0,0 +1,102 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package keystore_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemkeys/keystore"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ks := &keystore.KeyStore{Dir: dir, ScryptN: keystore.LightScryptN, ScryptP: keystore.StandardScryptP}
+	keys := diemkeys.MustGenKeys()
+	address, err := ks.Store(keys, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, keys.AccountAddress(), address)
+
+	t.Run("load", func(t *testing.T) {
+		loaded, err := ks.Load(address, "secret")
+		require.NoError(t, err)
+		assert.Equal(t, keys.PublicKey.Hex(), loaded.PublicKey.Hex())
+		assert.Equal(t, keys.PrivateKey.Sign([]byte("msg")), loaded.PrivateKey.Sign([]byte("msg")))
+	})
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := ks.Load(address, "wrong")
+		assert.Equal(t, keystore.ErrDecrypt, err)
+	})
+	t.Run("list", func(t *testing.T) {
+		other, err := ks.Store(diemkeys.MustGenKeys(), "other")
+		require.NoError(t, err)
+		list, err := ks.List()
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []diemtypes.AccountAddress{address, other}, list)
+	})
+	t.Run("change passphrase", func(t *testing.T) {
+		require.NoError(t, ks.ChangePassphrase(address, "secret", "new secret"))
+		_, err := ks.Load(address, "secret")
+		assert.Equal(t, keystore.ErrDecrypt, err)
+		loaded, err := ks.Load(address, "new secret")
+		require.NoError(t, err)
+		assert.Equal(t, keys.AccountAddress(), loaded.AccountAddress())
+
+		assert.Equal(t, keystore.ErrDecrypt, ks.ChangePassphrase(address, "secret", "x"))
+	})
+	t.Run("delete", func(t *testing.T) {
+		assert.Equal(t, keystore.ErrDecrypt, ks.Delete(address, "wrong"))
+		require.NoError(t, ks.Delete(address, "new secret"))
+		list, err := ks.List()
+		require.NoError(t, err)
+		assert.NotContains(t, list, address)
+	})
+	t.Run("multi sig keys are not supported", func(t *testing.T) {
+		_, err := ks.Store(diemkeys.MustGenMultiSigKeys(), "secret")
+		assert.Error(t, err)
+	})
+	t.Run("list not exist dir", func(t *testing.T) {
+		list, err := keystore.New(dir + "/not-exist").List()
+		require.NoError(t, err)
+		assert.Empty(t, list)
+	})
+}
+
+func TestDecryptRejectsUnboundedScryptParams(t *testing.T) {
+	data, err := keystore.Encrypt(diemkeys.MustGenKeys(), "secret", keystore.LightScryptN, keystore.StandardScryptP)
+	require.NoError(t, err)
+
+	cases := map[string]func(params map[string]interface{}){
+		"n too large":      func(p map[string]interface{}) { p["n"] = keystore.MaxScryptN * 2 },
+		"n not power of 2": func(p map[string]interface{}) { p["n"] = keystore.LightScryptN + 1 },
+		"p too large":      func(p map[string]interface{}) { p["p"] = keystore.MaxScryptP + 1 },
+		"r changed":        func(p map[string]interface{}) { p["r"] = 1 << 20 },
+		"dklen changed":    func(p map[string]interface{}) { p["dklen"] = 1 << 30 },
+	}
+	for name, modify := range cases {
+		t.Run(name, func(t *testing.T) {
+			var file map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &file))
+			modify(file["crypto"].(map[string]interface{})["kdfparams"].(map[string]interface{}))
+			modified, err := json.Marshal(file)
+			require.NoError(t, err)
+			_, err = keystore.Decrypt(modified, "secret")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "unsupported scrypt parameters")
+		})
+	}
+	t.Run("encrypt", func(t *testing.T) {
+		_, err := keystore.Encrypt(diemkeys.MustGenKeys(), "secret", keystore.MaxScryptN*2, 1)
+		assert.Error(t, err)
+	})
+}
@@ -6,6 +6,8 @@ package diemkeys
 import (
 	"crypto/ed25519"
 	"encoding/hex"
+	"fmt"
+	"sort"
 )
 
 const (
@@ -24,7 +26,7 @@ type MultiEd25519PublicKey struct {
 
 // MultiEd25519PrivateKey implements `PrivateKey` interface with multi ed25519 sig support
 type MultiEd25519PrivateKey struct {
-	keys      []ed25519.PrivateKey
+	keys      []*Ed25519PrivateKey
 	threshold byte
 }
 
@@ -36,16 +38,24 @@ func NewMultiEd25519PublicKey(keys []ed25519.PublicKey, threshold byte) PublicKe
 }
 
 // NewMultiEd25519PrivateKey creates new `MultiEd25519PrivateKey` as `PrivateKey`
-// with given keys and threshold
+// with given keys and threshold.
+// The given key bytes are copied, caller should `Zero` the given keys if they are not used anymore.
 func NewMultiEd25519PrivateKey(keys []ed25519.PrivateKey, threshold byte) PrivateKey {
 	validate(len(keys), threshold)
-	return &MultiEd25519PrivateKey{keys, threshold}
+	privateKeys := make([]*Ed25519PrivateKey, len(keys))
+	for i, key := range keys {
+		privateKeys[i] = NewEd25519PrivateKey(key)
+	}
+	return &MultiEd25519PrivateKey{privateKeys, threshold}
 }
 
 func validate(keysLen int, threshold byte) {
 	if keysLen == 0 {
 		panic("should at least have 1 key")
 	}
+	if threshold == 0 {
+		panic("threshold should be at least 1")
+	}
 	if int(threshold) > keysLen {
 		panic("threshold should be less or equal to len(keys)")
 	}
@@ -73,17 +83,72 @@ func (k *MultiEd25519PublicKey) Bytes() []byte {
 	return append(ret, k.threshold)
 }
 
+// Keys returns the ed25519 public keys
+func (k *MultiEd25519PublicKey) Keys() []ed25519.PublicKey {
+	return k.keys
+}
+
+// Threshold returns number of signatures required
+func (k *MultiEd25519PublicKey) Threshold() byte {
+	return k.threshold
+}
+
+// PublicKey returns the `MultiEd25519PublicKey` of the private keys with same threshold
+func (k *MultiEd25519PrivateKey) PublicKey() PublicKey {
+	keys := make([]ed25519.PublicKey, len(k.keys))
+	for i, key := range k.keys {
+		keys[i] = key.PublicKey().pk
+	}
+	return NewMultiEd25519PublicKey(keys, k.threshold)
+}
+
 // Sign implements `PrivateKey` interface, signs arbitrary message bytes and return it's signature.
+// Panics with `ErrKeyDestroyed` if the keys are destroyed.
 func (k *MultiEd25519PrivateKey) Sign(msg []byte) []byte {
 	var bitmap [BitmapNumOfBytes]byte
 	var ret []byte
 	for i, key := range k.keys[:k.threshold] {
 		bitmapSetBit(&bitmap, byte(i))
-		ret = append(ret, ed25519.Sign(key, msg)...)
+		ret = append(ret, key.Sign(msg)...)
 	}
 	return append(ret, bitmap[:]...)
 }
 
+// Destroy destroys all the private keys, see `Ed25519PrivateKey.Destroy`
+func (k *MultiEd25519PrivateKey) Destroy() {
+	for _, key := range k.keys {
+		key.Destroy()
+	}
+}
+
+// CombineMultiEd25519Signatures combines ed25519 signatures signed by keys of a multi ed25519
+// public key into a MultiEd25519 signature: the signatures ordered by key index, followed by the
+// bitmap of the key indexes.
+// It is for K-of-N multisig accounts, which keys are held by different parties: each party signs
+// the transaction signing message with its own key, and the signatures are combined by the key
+// index in the `MultiEd25519PublicKey`.
+// Returns error if a key index is out of range or a signature length is invalid.
+func CombineMultiEd25519Signatures(signatures map[byte][]byte) ([]byte, error) {
+	indexes := make([]int, 0, len(signatures))
+	for index, sig := range signatures {
+		if index >= MaxNumOfKeys {
+			return nil, fmt.Errorf("key index %d is out of range [0, %d)", index, MaxNumOfKeys)
+		}
+		if len(sig) != ed25519.SignatureSize {
+			return nil, fmt.Errorf("invalid signature length of key index %d: %d", index, len(sig))
+		}
+		indexes = append(indexes, int(index))
+	}
+	sort.Ints(indexes)
+	var bitmap [BitmapNumOfBytes]byte
+	var ret []byte
+	for _, index := range indexes {
+		bitmapSetBit(&bitmap, byte(index))
+		ret = append(ret, signatures[byte(index)]...)
+	}
+	return append(ret, bitmap[:]...), nil
+}
+
 func bitmapSetBit(input *[BitmapNumOfBytes]byte, index byte) {
 	bucket := index / 8
 	// It's always invoked with index < 32, thus there is no need to check range.
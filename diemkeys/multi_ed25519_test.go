@@ -9,8 +9,13 @@ import (
 	"testing"
 
 	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
 	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMultiEd25519PublicKey(t *testing.T) {
@@ -61,6 +66,55 @@ func TestMultiEd25519PrivateKey(t *testing.T) {
 	})
 }
 
+func TestMultiEd25519KOfN(t *testing.T) {
+	privateKeys := make([]ed25519.PrivateKey, 3)
+	for i := range privateKeys {
+		_, privateKeys[i], _ = ed25519.GenerateKey(nil)
+	}
+	privateKey := diemkeys.NewMultiEd25519PrivateKey(privateKeys, 2).(*diemkeys.MultiEd25519PrivateKey)
+	publicKey := privateKey.PublicKey().(*diemkeys.MultiEd25519PublicKey)
+	assert.Equal(t, byte(2), publicKey.Threshold())
+	assert.Len(t, publicKey.Keys(), 3)
+
+	keys := diemkeys.NewKeysFromPublicAndPrivateKeys(publicKey, privateKey)
+	script := stdlib.EncodePeerToPeerWithMetadataScript(
+		diemtypes.Currency("XUS"), diemkeys.MustGenKeys().AccountAddress(), 100, nil, nil)
+	rawTxn, msg := diemsigner.NewRawTransactionAndSigningMsg(
+		keys.AccountAddress(), 0, &diemtypes.TransactionPayload__Script{Value: script},
+		1_000_000, 0, "XUS", 1593189628, testnet.ChainID)
+
+	t.Run("auth key", func(t *testing.T) {
+		assert.Equal(t, diemkeys.NewAuthKey(publicKey), keys.AuthKey())
+		assert.NotEqual(t, diemkeys.NewAuthKey(diemkeys.NewMultiEd25519PublicKey(publicKey.Keys(), 1)), keys.AuthKey())
+	})
+	t.Run("sign", func(t *testing.T) {
+		txn := diemsigner.NewSignedTransaction(publicKey, rawTxn, privateKey.Sign(msg))
+		assert.NoError(t, txn.VerifySignature())
+	})
+	t.Run("combine signatures signed by different parties", func(t *testing.T) {
+		sig, err := diemkeys.CombineMultiEd25519Signatures(map[byte][]byte{
+			2: ed25519.Sign(privateKeys[2], msg),
+			0: ed25519.Sign(privateKeys[0], msg),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0xa0, 0, 0, 0}, sig[len(sig)-4:])
+		txn := diemsigner.NewSignedTransaction(publicKey, rawTxn, sig)
+		assert.NoError(t, txn.VerifySignature())
+	})
+	t.Run("signatures less than threshold", func(t *testing.T) {
+		sig, err := diemkeys.CombineMultiEd25519Signatures(map[byte][]byte{1: ed25519.Sign(privateKeys[1], msg)})
+		require.NoError(t, err)
+		txn := diemsigner.NewSignedTransaction(publicKey, rawTxn, sig)
+		assert.Error(t, txn.VerifySignature())
+	})
+	t.Run("invalid signatures", func(t *testing.T) {
+		_, err := diemkeys.CombineMultiEd25519Signatures(map[byte][]byte{32: ed25519.Sign(privateKeys[0], msg)})
+		assert.Error(t, err)
+		_, err = diemkeys.CombineMultiEd25519Signatures(map[byte][]byte{0: []byte("sig")})
+		assert.Error(t, err)
+	})
+}
+
 func TestNewMultiEd25519PrivateKeyErrors(t *testing.T) {
 	t.Run("empty keys", func(t *testing.T) {
 		defer func() {
@@ -104,6 +158,12 @@ func TestNewMultiEd25519PublicKeyErrors(t *testing.T) {
 		}()
 		diemkeys.NewMultiEd25519PublicKey(nil, 0)
 	})
+	t.Run("threshold is 0", func(t *testing.T) {
+		publicKey, _, _ := ed25519.GenerateKey(nil)
+		assert.Panics(t, func() {
+			diemkeys.NewMultiEd25519PublicKey([]ed25519.PublicKey{publicKey}, 0)
+		})
+	})
 	t.Run("threshold > len(keys)", func(t *testing.T) {
 		publicKey, _, _ := ed25519.GenerateKey(nil)
 
@@ -127,6 +187,12 @@ func TestNewMultiEd25519PublicKeyErrors(t *testing.T) {
 	})
 }
 
+func TestMultiEd25519PrivateKeyDestroy(t *testing.T) {
+	keys := diemkeys.MustGenMultiSigKeys()
+	keys.Destroy()
+	assert.PanicsWithValue(t, diemkeys.ErrKeyDestroyed, func() { keys.PrivateKey.Sign([]byte("test")) })
+}
+
 func bcsBytes(bytes []byte) string {
 	s := bcs.NewSerializer()
 	s.SerializeBytes(bytes)
@@ -0,0 +1,201 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// PEM block types
+const (
+	PEMPrivateKeyType        = "PRIVATE KEY"
+	PEMPublicKeyType         = "PUBLIC KEY"
+	PEMOpenSSHPrivateKeyType = "OPENSSH PRIVATE KEY"
+
+	openSSHKeyMagic = "openssh-key-v1\x00"
+)
+
+// MarshalPrivateKeyPEM encodes the private key into PKCS#8 PEM "PRIVATE KEY" block.
+// Returns `ErrKeyDestroyed` if the key is destroyed.
+func MarshalPrivateKeyPEM(key *Ed25519PrivateKey) ([]byte, error) {
+	var der []byte
+	var err error
+	if useErr := key.tryUse(func(pk ed25519.PrivateKey) {
+		der, err = x509.MarshalPKCS8PrivateKey(pk)
+	}); useErr != nil {
+		return nil, useErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer Zero(der)
+	return pem.EncodeToMemory(&pem.Block{Type: PEMPrivateKeyType, Bytes: der}), nil
+}
+
+// MarshalPublicKeyPEM encodes the public key into PKIX PEM "PUBLIC KEY" block
+func MarshalPublicKeyPEM(key *Ed25519PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.pk)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: PEMPublicKeyType, Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM decodes ed25519 private key from PKCS#8 "PRIVATE KEY" or unencrypted
+// OpenSSH "OPENSSH PRIVATE KEY" PEM block, returns `Keys` of the private key
+func ParsePrivateKeyPEM(data []byte) (*Keys, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case PEMPrivateKeyType:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case PEMOpenSSHPrivateKeyType:
+		key, err = ssh.ParseRawPrivateKey(data)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer Zero(block.Bytes)
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		defer Zero(k)
+		return newEd25519Keys(k), nil
+	case *ed25519.PrivateKey:
+		defer Zero(*k)
+		return newEd25519Keys(*k), nil
+	default:
+		return nil, fmt.Errorf("not an ed25519 private key: %T", key)
+	}
+}
+
+// ParsePublicKeyPEM decodes ed25519 public key from PKIX "PUBLIC KEY" PEM block
+func ParsePublicKeyPEM(data []byte) (*Ed25519PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != PEMPublicKeyType {
+		return nil, errors.New("no PUBLIC KEY PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an ed25519 public key: %T", key)
+	}
+	return NewEd25519PublicKey(publicKey), nil
+}
+
+// MarshalOpenSSHPrivateKey encodes the private key into unencrypted OpenSSH
+// "OPENSSH PRIVATE KEY" PEM block with given comment.
+// Returns `ErrKeyDestroyed` if the key is destroyed.
+func MarshalOpenSSHPrivateKey(key *Ed25519PrivateKey, comment string) ([]byte, error) {
+	var publicKey ed25519.PublicKey
+	var privateKey []byte
+	if err := key.tryUse(func(pk ed25519.PrivateKey) {
+		publicKey = pk.Public().(ed25519.PublicKey)
+		privateKey = append([]byte(nil), pk...)
+	}); err != nil {
+		return nil, err
+	}
+	defer Zero(privateKey)
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, err
+	}
+	private := struct {
+		Check1  uint32
+		Check2  uint32
+		KeyType string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+		Pad     []byte `ssh:"rest"`
+	}{
+		Check1:  binary.BigEndian.Uint32(check[:]),
+		Check2:  binary.BigEndian.Uint32(check[:]),
+		KeyType: ssh.KeyAlgoED25519,
+		Pub:     publicKey,
+		Priv:    privateKey,
+		Comment: comment,
+	}
+	// pad private key block to the block size 8 of cipher "none"
+	unpadded := ssh.Marshal(private)
+	for i := 0; (len(unpadded)+i)%8 != 0; i++ {
+		private.Pad = append(private.Pad, byte(i+1))
+	}
+	Zero(unpadded)
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	privateBlock := ssh.Marshal(private)
+	defer Zero(privateBlock)
+	body := ssh.Marshal(struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       sshPublicKey.Marshal(),
+		PrivKeyBlock: privateBlock,
+	})
+	defer Zero(body)
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  PEMOpenSSHPrivateKeyType,
+		Bytes: append([]byte(openSSHKeyMagic), body...),
+	}), nil
+}
+
+// MarshalAuthorizedKey encodes the public key into OpenSSH authorized_keys format
+func MarshalAuthorizedKey(key *Ed25519PublicKey) ([]byte, error) {
+	sshPublicKey, err := ssh.NewPublicKey(key.pk)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.MarshalAuthorizedKey(sshPublicKey), nil
+}
+
+// ParseAuthorizedKey decodes ed25519 public key from OpenSSH authorized_keys format, e.g.
+// "ssh-ed25519 AAAA... comment"
+func ParseAuthorizedKey(data []byte) (*Ed25519PublicKey, error) {
+	sshPublicKey, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, err
+	}
+	cryptoKey, ok := sshPublicKey.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported ssh public key type: %s", sshPublicKey.Type())
+	}
+	publicKey, ok := cryptoKey.CryptoPublicKey().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an ed25519 public key: %s", sshPublicKey.Type())
+	}
+	return NewEd25519PublicKey(publicKey), nil
+}
+
+func newEd25519Keys(key ed25519.PrivateKey) *Keys {
+	return NewKeysFromPublicAndPrivateKeys(
+		NewEd25519PublicKey(key.Public().(ed25519.PublicKey)),
+		NewEd25519PrivateKey(key),
+	)
+}
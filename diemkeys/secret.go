@@ -0,0 +1,84 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemkeys
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// ErrKeyDestroyed is the panic value of using a private key after it is destroyed
+var ErrKeyDestroyed = errors.New("private key is destroyed")
+
+// Zero overwrites given bytes with zeros, it should be called for releasing a copy of private
+// key bytes, e.g. decoded or decrypted private key bytes after creating private key from it.
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// secret holds a copy of private key bytes in a buffer locked in memory when the platform
+// supports it, see `allocLocked`. The buffer is zeroed and unlocked by `destroy`, or by finalizer
+// after the secret becomes unreachable.
+// Zeroing is best effort: copies made by the runtime or other packages are not zeroed.
+type secret struct {
+	mu     sync.RWMutex
+	buf    []byte
+	unlock func()
+}
+
+func newSecret(src []byte) *secret {
+	s := &secret{}
+	s.buf, s.unlock = allocLocked(len(src))
+	copy(s.buf, src)
+	runtime.SetFinalizer(s, (*secret).destroy)
+	return s
+}
+
+// use calls given function with the secret bytes, the bytes must not be retained after the
+// function returns. Returns `ErrKeyDestroyed` if the secret is destroyed.
+func (s *secret) use(fn func([]byte)) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.buf == nil {
+		return ErrKeyDestroyed
+	}
+	fn(s.buf)
+	return nil
+}
+
+func (s *secret) destroyed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.buf == nil
+}
+
+func (s *secret) destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.buf == nil {
+		return
+	}
+	Zero(s.buf)
+	s.unlock()
+	s.buf = nil
+	runtime.SetFinalizer(s, nil)
+}
+
+// allocPage allocates a buffer of the size rounded up to whole pages on Go heap, the buffer is
+// kept on Go heap because crypto/ed25519 caches expanded keys by weak pointers to the key bytes.
+// Returns false if the buffer is empty or does not start at a page boundary, then it may share
+// pages with other objects and must not be locked.
+func allocPage(size int) ([]byte, bool) {
+	if size == 0 {
+		return []byte{}, false
+	}
+	pageSize := os.Getpagesize()
+	buf := make([]byte, (size+pageSize-1)/pageSize*pageSize)
+	return buf, uintptr(unsafe.Pointer(&buf[0]))%uintptr(pageSize) == 0
+}
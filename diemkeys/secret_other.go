@@ -0,0 +1,12 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package diemkeys
+
+// allocLocked allocates buffer on heap, memory locking is not supported on the platform.
+func allocLocked(size int) ([]byte, func()) {
+	return make([]byte, size), func() {}
+}
@@ -0,0 +1,23 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package diemkeys
+
+import "syscall"
+
+// allocLocked allocates buffer by `allocPage` and locks its pages by mlock, returns the buffer
+// and the func unlocking the pages by munlock.
+// Locking is best effort: it is skipped if the buffer is not page aligned, and fails when
+// exceeding RLIMIT_MEMLOCK; the buffer is still zeroed by `secret.destroy`.
+func allocLocked(size int) ([]byte, func()) {
+	buf, aligned := allocPage(size)
+	if !aligned || syscall.Mlock(buf) != nil {
+		return buf[:size], func() {}
+	}
+	return buf[:size], func() {
+		_ = syscall.Munlock(buf)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemkeys
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+)
+
+// SharedEd25519PublicKey is the ed25519 public key published by an account as its
+// `SharedEd25519PublicKey` resource: the account authentication key is rotated to the auth key
+// of the public key, so that the account owner and an off-chain service holding the private key
+// can both sign transactions for the account.
+type SharedEd25519PublicKey struct {
+	*Ed25519PublicKey
+}
+
+// NewSharedEd25519PublicKey creates `SharedEd25519PublicKey`, returns error if the key length
+// is not `ed25519.PublicKeySize`.
+func NewSharedEd25519PublicKey(key ed25519.PublicKey) (*SharedEd25519PublicKey, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key length: %d, expected %d", len(key), ed25519.PublicKeySize)
+	}
+	return &SharedEd25519PublicKey{NewEd25519PublicKey(key)}, nil
+}
+
+// NewSharedEd25519PublicKeyFromString creates `SharedEd25519PublicKey` from given hex-encoded
+// ed25519 public key string
+func NewSharedEd25519PublicKeyFromString(key string) (*SharedEd25519PublicKey, error) {
+	bytes, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewSharedEd25519PublicKey(bytes)
+}
+
+// AuthKey returns the authentication key of the account after the shared key is published or
+// rotated.
+func (k *SharedEd25519PublicKey) AuthKey() AuthKey {
+	return NewAuthKey(k.Ed25519PublicKey)
+}
+
+// PublishPayload returns `publish_shared_ed25519_public_key` script payload, which publishes the
+// shared key under the sender account and rotates the sender authentication key to `AuthKey`.
+func (k *SharedEd25519PublicKey) PublishPayload() diemtypes.TransactionPayload {
+	return &diemtypes.TransactionPayload__Script{
+		Value: stdlib.EncodePublishSharedEd25519PublicKeyScript(k.Bytes()),
+	}
+}
+
+// RotatePayload returns `rotate_shared_ed25519_public_key` script payload, which rotates the
+// sender published shared key to this key and the sender authentication key to `AuthKey`.
+func (k *SharedEd25519PublicKey) RotatePayload() diemtypes.TransactionPayload {
+	return &diemtypes.TransactionPayload__Script{
+		Value: stdlib.EncodeRotateSharedEd25519PublicKeyScript(k.Bytes()),
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemkeys_test
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedEd25519PublicKey(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	key, err := diemkeys.NewSharedEd25519PublicKeyFromString(keys.PublicKey.Hex())
+	require.NoError(t, err)
+
+	t.Run("auth key", func(t *testing.T) {
+		assert.Equal(t, keys.AuthKey(), key.AuthKey())
+	})
+	t.Run("publish payload", func(t *testing.T) {
+		payload := key.PublishPayload().(*diemtypes.TransactionPayload__Script)
+		call, err := stdlib.DecodeScript(&payload.Value)
+		require.NoError(t, err)
+		publish, ok := call.(*stdlib.ScriptCall__PublishSharedEd25519PublicKey)
+		require.True(t, ok)
+		assert.Equal(t, keys.PublicKey.Bytes(), publish.PublicKey)
+	})
+	t.Run("rotate payload", func(t *testing.T) {
+		payload := key.RotatePayload().(*diemtypes.TransactionPayload__Script)
+		call, err := stdlib.DecodeScript(&payload.Value)
+		require.NoError(t, err)
+		rotate, ok := call.(*stdlib.ScriptCall__RotateSharedEd25519PublicKey)
+		require.True(t, ok)
+		assert.Equal(t, keys.PublicKey.Bytes(), rotate.PublicKey)
+	})
+	t.Run("invalid key length", func(t *testing.T) {
+		_, err := diemkeys.NewSharedEd25519PublicKey(make(ed25519.PublicKey, 31))
+		assert.Error(t, err)
+		_, err = diemkeys.NewSharedEd25519PublicKeyFromString("invalid")
+		assert.Error(t, err)
+	})
+}
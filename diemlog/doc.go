@@ -0,0 +1,33 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides a leveled structured logging interface used by the SDK packages, with redaction of
+// sensitive fields.
+//
+// `Logger` has a single method, adapting it to a logging library takes a few lines, e.g.
+// zap:
+//
+//	diemlog.LoggerFunc(func(level diemlog.Level, msg string, fields ...diemlog.Field) {
+//		zfs := make([]zap.Field, len(fields))
+//		for i, f := range fields {
+//			zfs[i] = zap.Any(f.Key, f.Value)
+//		}
+//		zapLogger.Check(zapcore.Level(level/4), msg).Write(zfs...)
+//	})
+//
+// logrus:
+//
+//	diemlog.LoggerFunc(func(level diemlog.Level, msg string, fields ...diemlog.Field) {
+//		entry := logrus.WithFields(diemlog.Map(fields))
+//		switch level {
+//		case diemlog.LevelDebug:
+//			entry.Debug(msg)
+//		...
+//		}
+//	})
+//
+// and `NewSlogLogger` adapts `*slog.Logger` (Go 1.21+).
+//
+// Loggers given to the SDK are wrapped by `Redacting`, so sensitive field values, e.g. keys,
+// signatures and travel rule metadata, never reach the underlying logger.
+package diemlog
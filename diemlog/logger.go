@@ -0,0 +1,155 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemlog
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Level is log level, values are same with `log/slog` levels
+type Level int
+
+// Log levels
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+// String returns level name
+func (l Level) String() string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	case l < LevelError:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+// Redacted replaces value of sensitive fields
+const Redacted = "[REDACTED]"
+
+// Field is a key value pair of structured log
+type Field struct {
+	Key   string
+	Value interface{}
+	// Sensitive field value is replaced by `Redacted` before it is logged
+	Sensitive bool
+}
+
+// F creates a `Field`, it is redacted if the key is one of `SensitiveKeys`
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Secret creates a sensitive `Field`
+func Secret(key string, value interface{}) Field {
+	return Field{Key: key, Value: value, Sensitive: true}
+}
+
+// SensitiveKeys are keys of fields always redacted by `Redact`
+var SensitiveKeys = []string{
+	"private_key",
+	"seed",
+	"mnemonic",
+	"signature",
+	"metadata",
+	"metadata_signature",
+	"signed_txn",
+}
+
+func isSensitiveKey(key string) bool {
+	for _, k := range SensitiveKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Map converts fields into a map, e.g. for `logrus.Fields`
+func Map(fields []Field) map[string]interface{} {
+	ret := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		ret[f.Key] = f.Value
+	}
+	return ret
+}
+
+// Logger logs leveled structured messages
+type Logger interface {
+	Log(level Level, msg string, fields ...Field)
+}
+
+// LoggerFunc is a function implements `Logger`
+type LoggerFunc func(level Level, msg string, fields ...Field)
+
+// Log implements `Logger`
+func (f LoggerFunc) Log(level Level, msg string, fields ...Field) {
+	f(level, msg, fields...)
+}
+
+// Nop is a `Logger` discards all logs, it is the default logger of the SDK
+var Nop Logger = nop{}
+
+type nop struct{}
+
+func (nop) Log(Level, string, ...Field) {}
+
+// Redacting wraps the logger, replaces sensitive field values by `Redacted` before logging.
+// Returns `Nop` if the logger is nil.
+func Redacting(logger Logger) Logger {
+	if logger == nil {
+		return Nop
+	}
+	if _, ok := logger.(*redacting); ok {
+		return logger
+	}
+	return &redacting{logger}
+}
+
+type redacting struct {
+	next Logger
+}
+
+func (r *redacting) Log(level Level, msg string, fields ...Field) {
+	r.next.Log(level, msg, Redact(fields)...)
+}
+
+// Redact returns a copy of the fields with sensitive values replaced by `Redacted`
+func Redact(fields []Field) []Field {
+	ret := make([]Field, len(fields))
+	for i, f := range fields {
+		if f.Sensitive || isSensitiveKey(f.Key) {
+			f.Value = Redacted
+		}
+		ret[i] = f
+	}
+	return ret
+}
+
+// NewStdLogger creates `Logger` writes logs at or above the min level into the
+// `*log.Logger` in format: `LEVEL msg key=value ...`
+func NewStdLogger(logger *log.Logger, min Level) Logger {
+	return LoggerFunc(func(level Level, msg string, fields ...Field) {
+		if level < min {
+			return
+		}
+		var b strings.Builder
+		b.WriteString(level.String())
+		b.WriteByte(' ')
+		b.WriteString(msg)
+		for _, f := range fields {
+			fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+		}
+		logger.Print(b.String())
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemlog_test
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type entry struct {
+	level  diemlog.Level
+	msg    string
+	fields []diemlog.Field
+}
+
+type recorder struct {
+	entries []entry
+}
+
+func (r *recorder) Log(level diemlog.Level, msg string, fields ...diemlog.Field) {
+	r.entries = append(r.entries, entry{level, msg, fields})
+}
+
+func TestRedacting(t *testing.T) {
+	rec := &recorder{}
+	logger := diemlog.Redacting(rec)
+	assert.Same(t, logger, diemlog.Redacting(logger))
+
+	fields := []diemlog.Field{
+		diemlog.F("hash", "abc"),
+		diemlog.F("metadata", []byte{1, 2}),
+		diemlog.Secret("api_key", "secret"),
+	}
+	logger.Log(diemlog.LevelInfo, "hello", fields...)
+	require.Len(t, rec.entries, 1)
+	assert.Equal(t, "hello", rec.entries[0].msg)
+	assert.Equal(t, map[string]interface{}{
+		"hash":     "abc",
+		"metadata": diemlog.Redacted,
+		"api_key":  diemlog.Redacted,
+	}, diemlog.Map(rec.entries[0].fields))
+	assert.Equal(t, []byte{1, 2}, fields[1].Value, "given fields are not modified")
+
+	assert.Equal(t, diemlog.Nop, diemlog.Redacting(nil))
+}
+
+func TestNewStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := diemlog.Redacting(diemlog.NewStdLogger(log.New(&buf, "", 0), diemlog.LevelInfo))
+	logger.Log(diemlog.LevelDebug, "ignored")
+	logger.Log(diemlog.LevelWarn, "stale response", diemlog.F("version", 1), diemlog.F("signature", "sig"))
+	assert.Equal(t, "WARN stale response version=1 signature=[REDACTED]\n", buf.String())
+}
+
+func TestLevelString(t *testing.T) {
+	assert.Equal(t, "DEBUG", diemlog.LevelDebug.String())
+	assert.Equal(t, "INFO", diemlog.LevelInfo.String())
+	assert.Equal(t, "WARN", diemlog.LevelWarn.String())
+	assert.Equal(t, "ERROR", diemlog.LevelError.String())
+}
@@ -0,0 +1,23 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.21
+// +build go1.21
+
+package diemlog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogLogger creates `Logger` writes logs into the `*slog.Logger`
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return LoggerFunc(func(level Level, msg string, fields ...Field) {
+		attrs := make([]slog.Attr, len(fields))
+		for i, f := range fields {
+			attrs[i] = slog.Any(f.Key, f.Value)
+		}
+		logger.LogAttrs(context.Background(), slog.Level(level), msg, attrs...)
+	})
+}
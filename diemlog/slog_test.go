@@ -0,0 +1,33 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.21
+// +build go1.21
+
+package diemlog_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemlog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := diemlog.Redacting(diemlog.NewSlogLogger(slog.New(handler)))
+	logger.Log(diemlog.LevelDebug, "ignored")
+	logger.Log(diemlog.LevelError, "chain reset", diemlog.F("version", 1), diemlog.F("private_key", "key"))
+	assert.Equal(t, "level=ERROR msg=\"chain reset\" version=1 private_key=[REDACTED]\n", buf.String())
+}
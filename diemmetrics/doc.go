@@ -0,0 +1,10 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides Prometheus metrics of diemclient JSON-RPC calls, transaction submissions, waiting
+// for transactions, stale responses and testnet faucet calls.
+//
+//	m, err := diemmetrics.New(prometheus.DefaultRegisterer)
+//	client := diemclient.New(chainID, url, m.ClientOption())
+//	faucet := m.InstrumentFaucet(testnet.NewFaucet(config))
+package diemmetrics
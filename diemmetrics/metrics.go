@@ -0,0 +1,204 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemmetrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace is prefix of all metric names
+const Namespace = "diem"
+
+// Status label values of JSON-RPC calls, submissions and waits
+const (
+	StatusOK              = "ok"
+	StatusError           = "error"
+	StatusRPCError        = "rpc_error"
+	StatusStaleResponse   = "stale_response"
+	StatusChainIDMismatch = "chain_id_mismatch"
+	StatusChainRegression = "chain_regression"
+	StatusExecuted        = "executed"
+	StatusExecutionFailed = "execution_failed"
+	StatusExpired         = "expired"
+)
+
+// Metrics holds the collectors, they are exported for custom usages, e.g. observing calls made
+// by other clients.
+type Metrics struct {
+	// Calls counts JSON-RPC calls by method and status, including retries
+	Calls *prometheus.CounterVec
+	// CallDuration observes JSON-RPC call latency by method
+	CallDuration *prometheus.HistogramVec
+	// SubmitDuration observes transaction submission latency by status
+	SubmitDuration *prometheus.HistogramVec
+	// WaitDuration observes duration of waiting for transactions by status
+	WaitDuration *prometheus.HistogramVec
+	// StaleResponses counts stale responses
+	StaleResponses prometheus.Counter
+	// FaucetCalls counts faucet calls by HTTP status code, or "error" for network errors
+	FaucetCalls *prometheus.CounterVec
+}
+
+// New creates `Metrics` and registers the collectors on the registerer, returns error if any
+// of them failed to register, e.g. registered twice.
+func New(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		Calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "jsonrpc",
+			Name:      "calls_total",
+			Help:      "Number of JSON-RPC calls by method and status.",
+		}, []string{"method", "status"}),
+		CallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "jsonrpc",
+			Name:      "call_duration_seconds",
+			Help:      "JSON-RPC call latency by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		SubmitDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "submit_duration_seconds",
+			Help:      "Transaction submission latency by status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"status"}),
+		WaitDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "wait_duration_seconds",
+			Help:      "Duration of waiting for transactions by status.",
+			Buckets:   []float64{0.5, 1, 2, 3, 5, 10, 20, 30, 60, 120},
+		}, []string{"status"}),
+		StaleResponses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "jsonrpc",
+			Name:      "stale_responses_total",
+			Help:      "Number of stale responses.",
+		}),
+		FaucetCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "faucet",
+			Name:      "calls_total",
+			Help:      "Number of faucet calls by HTTP status code.",
+		}, []string{"status"}),
+	}
+	for _, c := range []prometheus.Collector{
+		m.Calls, m.CallDuration, m.SubmitDuration, m.WaitDuration, m.StaleResponses, m.FaucetCalls,
+	} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ClientOption returns `diemclient.Option` observes calls, submissions, waits and stale
+// responses of the client
+func (m *Metrics) ClientOption() diemclient.Option {
+	return diemclient.WithHooks(diemclient.Hooks{
+		OnResponse: m.observeCall,
+		OnError:    m.observeCall,
+		OnWait: func(_ context.Context, wait *diemclient.WaitInfo) {
+			m.WaitDuration.WithLabelValues(waitStatus(wait.Err)).Observe(wait.Duration.Seconds())
+		},
+	})
+}
+
+func (m *Metrics) observeCall(_ context.Context, call *diemclient.CallInfo) {
+	method := string(call.Method)
+	status := callStatus(call.Err)
+	m.Calls.WithLabelValues(method, status).Inc()
+	m.CallDuration.WithLabelValues(method).Observe(call.Latency.Seconds())
+	if status == StatusStaleResponse {
+		m.StaleResponses.Inc()
+	}
+	if call.Method == diemclient.Submit {
+		submitStatus := StatusOK
+		// submit ignores stale response, the transaction is accepted
+		if call.Err != nil && status != StatusStaleResponse {
+			submitStatus = StatusError
+		}
+		m.SubmitDuration.WithLabelValues(submitStatus).Observe(call.Latency.Seconds())
+	}
+}
+
+func callStatus(err error) string {
+	var rpcErr *jsonrpc.ResponseError
+	var stale *diemclient.StaleResponseError
+	var mismatch *diemclient.ChainIDMismatchError
+	var regression *diemclient.ChainRegressionError
+	var reset *diemclient.ChainResetError
+	switch {
+	case err == nil:
+		return StatusOK
+	case errors.As(err, &stale):
+		return StatusStaleResponse
+	case errors.As(err, &regression):
+		return StatusChainRegression
+	case errors.As(err, &mismatch):
+		return StatusChainIDMismatch
+	case errors.As(err, &reset):
+		return StatusChainRegression
+	case errors.As(err, &rpcErr):
+		return StatusRPCError
+	default:
+		return StatusError
+	}
+}
+
+func waitStatus(err error) string {
+	var execErr *diemclient.ExecutionError
+	switch {
+	case err == nil:
+		return StatusExecuted
+	case errors.As(err, &execErr):
+		return StatusExecutionFailed
+	case errors.Is(err, diemclient.ErrTransactionExpired):
+		return StatusExpired
+	default:
+		return StatusError
+	}
+}
+
+// InstrumentFaucet counts the faucet calls by wrapping transport of the faucet http client,
+// returns the given faucet.
+func (m *Metrics) InstrumentFaucet(faucet *testnet.Faucet) *testnet.Faucet {
+	client := http.DefaultClient
+	if faucet.HTTPClient != nil {
+		client = faucet.HTTPClient
+	}
+	instrumented := *client
+	instrumented.Transport = m.FaucetRoundTripper(client.Transport)
+	return faucet.WithHTTPClient(&instrumented)
+}
+
+// FaucetRoundTripper wraps the round tripper to count faucet calls, `http.DefaultTransport`
+// is used if the given round tripper is nil.
+func (m *Metrics) FaucetRoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			m.FaucetCalls.WithLabelValues(StatusError).Inc()
+		} else {
+			m.FaucetCalls.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+		}
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemmetrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemmetrics"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnbuilder"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	_, err := diemmetrics.New(registry)
+	require.NoError(t, err)
+	_, err = diemmetrics.New(registry)
+	assert.Error(t, err)
+}
+
+func TestClientOption(t *testing.T) {
+	m, err := diemmetrics.New(prometheus.NewRegistry())
+	require.NoError(t, err)
+	server := diemclienttest.NewServer()
+	client := server.Client(m.ClientOption(), diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+
+	sender := diemkeys.MustGenKeys()
+	server.AddAccount(&diemclient.Account{
+		Address:           sender.AccountAddress().Hex(),
+		AuthenticationKey: sender.AuthKey().Hex(),
+	})
+	_, err = txnbuilder.New(sender).Payload(stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+		diemtypes.Currency("XUS"), diemkeys.MustGenKeys().AccountAddress(), 100, nil, nil)).
+		SignSubmitAndWait(client)
+	require.NoError(t, err)
+
+	server.Fail(diemclient.GetMetadata, diemclienttest.StaleResponse(), diemclienttest.ResponseError(-32602, "invalid params"))
+	_, err = client.GetMetadata()
+	require.Error(t, err)
+	_, err = client.GetMetadata()
+	require.Error(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.Calls.WithLabelValues("submit", diemmetrics.StatusOK)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.Calls.WithLabelValues("get_metadata", diemmetrics.StatusStaleResponse)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.Calls.WithLabelValues("get_metadata", diemmetrics.StatusRPCError)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.StaleResponses))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.SubmitDuration))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.WaitDuration))
+	assert.Equal(t, 0, testutil.CollectAndCount(m.FaucetCalls))
+}
+
+func TestInstrumentFaucet(t *testing.T) {
+	m, err := diemmetrics.New(prometheus.NewRegistry())
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	faucet := m.InstrumentFaucet(&testnet.Faucet{URL: server.URL})
+	_, err = faucet.Mint(diemkeys.MustGenKeys().AuthKey().Hex(), 100, "XUS")
+	require.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.FaucetCalls.WithLabelValues("503")))
+}
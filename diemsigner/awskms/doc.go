@@ -0,0 +1,31 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides `diemsigner.Signer` backed by AWS KMS asymmetric ed25519 keys (key spec
+// ECC_NIST_EDWARDS25519), the private key never leaves KMS.
+//
+// This package does not depend on AWS SDK; implement `KMS` interface by AWS SDK KMS client:
+//
+//	type kmsClient struct{ c *kms.Client }
+//
+//	func (k kmsClient) Sign(ctx context.Context, keyID string, msg []byte) ([]byte, error) {
+//		out, err := k.c.Sign(ctx, &kms.SignInput{
+//			KeyId:            aws.String(keyID),
+//			Message:          msg,
+//			MessageType:      types.MessageTypeRaw,
+//			SigningAlgorithm: types.SigningAlgorithmSpecEd25519Sha512,
+//		})
+//		if err != nil {
+//			return nil, err
+//		}
+//		return out.Signature, nil
+//	}
+//
+//	func (k kmsClient) GetPublicKey(ctx context.Context, keyID string) ([]byte, error) {
+//		out, err := k.c.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(keyID)})
+//		if err != nil {
+//			return nil, err
+//		}
+//		return out.PublicKey, nil
+//	}
+package awskms
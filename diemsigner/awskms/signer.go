@@ -0,0 +1,82 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package awskms
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// MaxMessageSize is max size of message KMS signs with message type RAW, larger transaction
+// signing messages can't be signed by KMS ed25519 keys.
+const MaxMessageSize = 4096
+
+// KMS is the subset of AWS KMS API used by `Signer`
+type KMS interface {
+	// Sign calls kms:Sign with message type RAW and signing algorithm ED25519_SHA_512,
+	// returns the signature
+	Sign(ctx context.Context, keyID string, msg []byte) ([]byte, error)
+	// GetPublicKey calls kms:GetPublicKey, returns the DER-encoded X.509 public key
+	GetPublicKey(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// Signer implements `diemsigner.Signer` by an AWS KMS ed25519 key
+type Signer struct {
+	KMS       KMS
+	KeyID     string
+	publicKey *diemkeys.Ed25519PublicKey
+}
+
+// NewSigner creates `Signer` for the given KMS key id, key alias or key ARN.
+// It retrieves the key public key from KMS, returns error if the key is not an ed25519 key.
+func NewSigner(ctx context.Context, kms KMS, keyID string) (*Signer, error) {
+	der, err := kms.GetPublicKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key of KMS key %s failed: %v", keyID, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("KMS key %s is not an ed25519 key: %T", keyID, key)
+	}
+	return &Signer{KMS: kms, KeyID: keyID, publicKey: diemkeys.NewEd25519PublicKey(publicKey)}, nil
+}
+
+// SignMessage implements `diemsigner.Signer`
+func (s *Signer) SignMessage(ctx context.Context, msg []byte) ([]byte, error) {
+	if len(msg) > MaxMessageSize {
+		return nil, fmt.Errorf("message size %d exceeds KMS max message size %d", len(msg), MaxMessageSize)
+	}
+	sig, err := s.KMS.Sign(ctx, s.KeyID, msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid ed25519 signature length from KMS key %s: %d", s.KeyID, len(sig))
+	}
+	return sig, nil
+}
+
+// PublicKey implements `diemsigner.Signer`
+func (s *Signer) PublicKey() diemkeys.PublicKey {
+	return s.publicKey
+}
+
+// AuthKey returns authentication key of the KMS key
+func (s *Signer) AuthKey() diemkeys.AuthKey {
+	return diemkeys.NewAuthKey(s.publicKey)
+}
+
+// AccountAddress returns account address derived from the KMS key authentication key
+func (s *Signer) AccountAddress() diemtypes.AccountAddress {
+	return s.AuthKey().AccountAddress()
+}
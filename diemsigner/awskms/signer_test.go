@@ -0,0 +1,82 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package awskms_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemsigner/awskms"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	kms := &fakeKMS{keys: map[string]ed25519.PrivateKey{"alias/treasury": privateKey}, der: der}
+
+	signer, err := awskms.NewSigner(context.Background(), kms, "alias/treasury")
+	require.NoError(t, err)
+	keys := diemkeys.NewKeysFromPublicAndPrivateKeys(diemkeys.NewEd25519PublicKey(publicKey), nil)
+	assert.Equal(t, keys.AuthKey(), signer.AuthKey())
+	assert.Equal(t, keys.AccountAddress(), signer.AccountAddress())
+
+	t.Run("sign transaction", func(t *testing.T) {
+		script := stdlib.EncodePeerToPeerWithMetadataScript(
+			diemtypes.Currency("XUS"), diemkeys.MustGenKeys().AccountAddress(), 100, nil, nil)
+		txn, err := diemsigner.SignTxnWithSigner(context.Background(), signer,
+			signer.AccountAddress(), 0, &diemtypes.TransactionPayload__Script{Value: script},
+			1_000_000, 0, "XUS", 1593189628, testnet.ChainID)
+		require.NoError(t, err)
+		assert.NoError(t, txn.VerifySignature())
+	})
+	t.Run("message too large", func(t *testing.T) {
+		_, err := signer.SignMessage(context.Background(), make([]byte, awskms.MaxMessageSize+1))
+		assert.Error(t, err)
+	})
+	t.Run("kms error", func(t *testing.T) {
+		signer := &awskms.Signer{KMS: kms, KeyID: "unknown"}
+		_, err := signer.SignMessage(context.Background(), []byte("msg"))
+		assert.EqualError(t, err, "NotFoundException")
+	})
+	t.Run("not ed25519 key", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		_, err = awskms.NewSigner(context.Background(), &fakeKMS{der: der}, "ecc")
+		assert.Error(t, err)
+	})
+}
+
+type fakeKMS struct {
+	keys map[string]ed25519.PrivateKey
+	der  []byte
+}
+
+func (k *fakeKMS) Sign(_ context.Context, keyID string, msg []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, errors.New("NotFoundException")
+	}
+	return ed25519.Sign(key, msg), nil
+}
+
+func (k *fakeKMS) GetPublicKey(context.Context, string) ([]byte, error) {
+	return k.der, nil
+}
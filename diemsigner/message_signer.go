@@ -0,0 +1,66 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemsigner
+
+import (
+	"context"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// Signer signs messages by a key it holds. It decouples transaction signing from in-memory
+// private keys, so that keys held in HSM, Vault or a remote signing service can be used without
+// exposing key material to the SDK.
+type Signer interface {
+	// SignMessage signs the message, returns ed25519 signature, or MultiEd25519 signature for
+	// multi ed25519 public key
+	SignMessage(ctx context.Context, msg []byte) ([]byte, error)
+	// PublicKey returns public key of the signing key
+	PublicKey() diemkeys.PublicKey
+}
+
+// KeysSigner implements `Signer` with in-memory `diemkeys.Keys`
+type KeysSigner struct {
+	Keys *diemkeys.Keys
+}
+
+// NewKeysSigner creates `KeysSigner` with given keys
+func NewKeysSigner(keys *diemkeys.Keys) *KeysSigner {
+	return &KeysSigner{Keys: keys}
+}
+
+// SignMessage implements `Signer`
+func (s *KeysSigner) SignMessage(_ context.Context, msg []byte) ([]byte, error) {
+	return s.Keys.PrivateKey.Sign(msg), nil
+}
+
+// PublicKey implements `Signer`
+func (s *KeysSigner) PublicKey() diemkeys.PublicKey {
+	return s.Keys.PublicKey
+}
+
+// SignTxnWithSigner signs transaction with `diemtypes.TransactionPayload` by given `Signer`
+func SignTxnWithSigner(
+	ctx context.Context,
+	signer Signer,
+	accountAddress diemtypes.AccountAddress,
+	sequenceNum uint64, payload diemtypes.TransactionPayload,
+	maxGasAmmount uint64, gasUnitPrice uint64, gasCurrencyCode string,
+	expirationTimeSec uint64,
+	chainID byte,
+) (*diemtypes.SignedTransaction, error) {
+	rawTxn, signingMsg := NewRawTransactionAndSigningMsg(
+		accountAddress,
+		sequenceNum, payload,
+		maxGasAmmount, gasUnitPrice, gasCurrencyCode,
+		expirationTimeSec,
+		chainID)
+
+	signature, err := signer.SignMessage(ctx, signingMsg)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignedTransaction(signer.PublicKey(), rawTxn, signature), nil
+}
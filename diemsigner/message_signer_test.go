@@ -0,0 +1,53 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemsigner_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignTxnWithSigner(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	payload := &diemtypes.TransactionPayload__Script{
+		Value: stdlib.EncodePeerToPeerWithMetadataScript(
+			diemtypes.Currency("XUS"), diemkeys.MustGenKeys().AccountAddress(), 100, nil, nil),
+	}
+
+	t.Run("keys signer", func(t *testing.T) {
+		txn, err := diemsigner.SignTxnWithSigner(context.Background(), diemsigner.NewKeysSigner(keys),
+			keys.AccountAddress(), 1, payload, 1_000_000, 0, "XUS", 1593189628, testnet.ChainID)
+		require.NoError(t, err)
+		assert.NoError(t, txn.VerifySignature())
+
+		expected := diemsigner.SignTxn(keys, keys.AccountAddress(), 1, payload, 1_000_000, 0, "XUS", 1593189628, testnet.ChainID)
+		assert.Equal(t, diemtypes.ToHex(expected), diemtypes.ToHex(txn))
+	})
+	t.Run("signer error", func(t *testing.T) {
+		_, err := diemsigner.SignTxnWithSigner(context.Background(), &failedSigner{keys},
+			keys.AccountAddress(), 1, payload, 1_000_000, 0, "XUS", 1593189628, testnet.ChainID)
+		assert.EqualError(t, err, "signing service unavailable")
+	})
+}
+
+type failedSigner struct {
+	keys *diemkeys.Keys
+}
+
+func (s *failedSigner) SignMessage(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("signing service unavailable")
+}
+
+func (s *failedSigner) PublicKey() diemkeys.PublicKey {
+	return s.keys.PublicKey
+}
@@ -66,8 +66,7 @@ func NewRawTransactionAndSigningMsg(
 		ChainId:                 diemtypes.ChainId(chainID),
 	}
 
-	signingMsg := append(diemtypes.HashPrefix("RawTransaction"), diemtypes.ToBCS(&rawTxn)...)
-	return &rawTxn, signingMsg
+	return &rawTxn, rawTxn.SigningMessage()
 }
 
 // NewSignedTransaction creates new `SignedTransaction`
@@ -0,0 +1,189 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// NewBoolArgument creates `bool` transaction script argument
+func NewBoolArgument(v bool) TransactionArgument {
+	return (*TransactionArgument__Bool)(&v)
+}
+
+// NewU8Argument creates `u8` transaction script argument
+func NewU8Argument(v uint8) TransactionArgument {
+	return (*TransactionArgument__U8)(&v)
+}
+
+// NewU64Argument creates `u64` transaction script argument
+func NewU64Argument(v uint64) TransactionArgument {
+	return (*TransactionArgument__U64)(&v)
+}
+
+// NewU128Argument creates `u128` transaction script argument
+func NewU128Argument(v serde.Uint128) TransactionArgument {
+	return (*TransactionArgument__U128)(&v)
+}
+
+// NewAddressArgument creates `address` transaction script argument
+func NewAddressArgument(v AccountAddress) TransactionArgument {
+	return &TransactionArgument__Address{Value: v}
+}
+
+// NewU8VectorArgument creates `vector<u8>` transaction script argument
+func NewU8VectorArgument(v []byte) TransactionArgument {
+	return (*TransactionArgument__U8Vector)(&v)
+}
+
+// IsTransactionArgumentType returns true if the Move type can be transaction script argument
+// type: bool, u8, u64, u128, address or vector<u8>.
+func IsTransactionArgumentType(typ TypeTag) bool {
+	switch typ := typ.(type) {
+	case *TypeTag__Bool, *TypeTag__U8, *TypeTag__U64, *TypeTag__U128, *TypeTag__Address:
+		return true
+	case *TypeTag__Vector:
+		_, ok := typ.Value.(*TypeTag__U8)
+		return ok
+	}
+	return false
+}
+
+// IsBCSArgumentType returns true if the Move type can be script function argument type: bool,
+// u8, u64, u128, address, or vector of these types, e.g. vector<u64> and vector<vector<u8>>.
+func IsBCSArgumentType(typ TypeTag) bool {
+	switch typ := typ.(type) {
+	case *TypeTag__Bool, *TypeTag__U8, *TypeTag__U64, *TypeTag__U128, *TypeTag__Address:
+		return true
+	case *TypeTag__Vector:
+		return IsBCSArgumentType(typ.Value)
+	}
+	return false
+}
+
+// NewTransactionArgument creates transaction script argument of the given Move type; the value
+// Go type must be bool, uint8, uint64, serde.Uint128, AccountAddress or []byte accordingly.
+// Returns error if the Move type can't be transaction script argument type, or the value type
+// mismatches.
+func NewTransactionArgument(typ TypeTag, value interface{}) (TransactionArgument, error) {
+	if !IsTransactionArgumentType(typ) {
+		return nil, fmt.Errorf("unsupported transaction argument type: %s", typ)
+	}
+	switch v := value.(type) {
+	case bool:
+		if _, ok := typ.(*TypeTag__Bool); ok {
+			return NewBoolArgument(v), nil
+		}
+	case uint8:
+		if _, ok := typ.(*TypeTag__U8); ok {
+			return NewU8Argument(v), nil
+		}
+	case uint64:
+		if _, ok := typ.(*TypeTag__U64); ok {
+			return NewU64Argument(v), nil
+		}
+	case serde.Uint128:
+		if _, ok := typ.(*TypeTag__U128); ok {
+			return NewU128Argument(v), nil
+		}
+	case AccountAddress:
+		if _, ok := typ.(*TypeTag__Address); ok {
+			return NewAddressArgument(v), nil
+		}
+	case []byte:
+		if _, ok := typ.(*TypeTag__Vector); ok {
+			return NewU8VectorArgument(v), nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s value, got %T", typ, value)
+}
+
+// EncodeBCSArgument serializes script function argument value of the given Move type into BCS
+// bytes. Value Go types are same with `NewTransactionArgument`, and vector value can be any Go
+// slice of the element value type, e.g. []uint64 for vector<u64>, [][]byte for
+// vector<vector<u8>> and []interface{} for any vector type.
+// Returns error if the Move type can't be script function argument type, or the value type
+// mismatches.
+func EncodeBCSArgument(typ TypeTag, value interface{}) ([]byte, error) {
+	if !IsBCSArgumentType(typ) {
+		return nil, fmt.Errorf("unsupported script function argument type: %s", typ)
+	}
+	s := bcs.NewSerializer()
+	if err := serializeArgument(s, typ, value); err != nil {
+		return nil, err
+	}
+	return s.GetBytes(), nil
+}
+
+// EncodeTransactionArgumentBCS serializes the value of transaction script argument into BCS
+// bytes, which is the script function argument of the same value.
+func EncodeTransactionArgumentBCS(arg TransactionArgument) ([]byte, error) {
+	s := bcs.NewSerializer()
+	var err error
+	switch arg := arg.(type) {
+	case *TransactionArgument__Bool:
+		err = s.SerializeBool(bool(*arg))
+	case *TransactionArgument__U8:
+		err = s.SerializeU8(uint8(*arg))
+	case *TransactionArgument__U64:
+		err = s.SerializeU64(uint64(*arg))
+	case *TransactionArgument__U128:
+		err = s.SerializeU128(serde.Uint128(*arg))
+	case *TransactionArgument__Address:
+		err = arg.Value.Serialize(s)
+	case *TransactionArgument__U8Vector:
+		err = s.SerializeBytes([]byte(*arg))
+	default:
+		err = fmt.Errorf("unknown transaction argument %T", arg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetBytes(), nil
+}
+
+func serializeArgument(s serde.Serializer, typ TypeTag, value interface{}) error {
+	switch v := value.(type) {
+	case bool:
+		if _, ok := typ.(*TypeTag__Bool); ok {
+			return s.SerializeBool(v)
+		}
+	case uint8:
+		if _, ok := typ.(*TypeTag__U8); ok {
+			return s.SerializeU8(v)
+		}
+	case uint64:
+		if _, ok := typ.(*TypeTag__U64); ok {
+			return s.SerializeU64(v)
+		}
+	case serde.Uint128:
+		if _, ok := typ.(*TypeTag__U128); ok {
+			return s.SerializeU128(v)
+		}
+	case AccountAddress:
+		if _, ok := typ.(*TypeTag__Address); ok {
+			return v.Serialize(s)
+		}
+	default:
+		vector, ok := typ.(*TypeTag__Vector)
+		rv := reflect.ValueOf(value)
+		if !ok || rv.Kind() != reflect.Slice {
+			break
+		}
+		if err := s.SerializeLen(uint64(rv.Len())); err != nil {
+			return err
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := serializeArgument(s, vector.Value, rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("expected %s value, got %T", typ, value)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransactionArgument(t *testing.T) {
+	address := diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	cases := []struct {
+		typ      string
+		value    interface{}
+		expected diemtypes.TransactionArgument
+		bcs      []byte
+	}{
+		{"bool", true, diemtypes.NewBoolArgument(true), []byte{1}},
+		{"u8", uint8(8), diemtypes.NewU8Argument(8), []byte{8}},
+		{"u64", uint64(64), diemtypes.NewU64Argument(64), []byte{64, 0, 0, 0, 0, 0, 0, 0}},
+		{"u128", serde.Uint128{High: 1, Low: 2}, diemtypes.NewU128Argument(serde.Uint128{High: 1, Low: 2}),
+			[]byte{2, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}},
+		{"address", address, diemtypes.NewAddressArgument(address), address[:]},
+		{"vector<u8>", []byte{0xab}, diemtypes.NewU8VectorArgument([]byte{0xab}), []byte{1, 0xab}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.typ, func(t *testing.T) {
+			typ := diemtypes.MustParseTypeTag(tc.typ)
+			assert.True(t, diemtypes.IsTransactionArgumentType(typ))
+			arg, err := diemtypes.NewTransactionArgument(typ, tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, arg)
+
+			bytes, err := diemtypes.EncodeTransactionArgumentBCS(arg)
+			require.NoError(t, err)
+			assert.Equal(t, tc.bcs, bytes)
+			bytes, err = diemtypes.EncodeBCSArgument(typ, tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.bcs, bytes)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := diemtypes.NewTransactionArgument(&diemtypes.TypeTag__U64{}, 1)
+		assert.EqualError(t, err, "expected u64 value, got int")
+		_, err = diemtypes.NewTransactionArgument(&diemtypes.TypeTag__Address{}, []byte{1})
+		assert.EqualError(t, err, "expected address value, got []uint8")
+		_, err = diemtypes.NewTransactionArgument(diemtypes.MustParseTypeTag("vector<u64>"), []uint64{1})
+		assert.EqualError(t, err, "unsupported transaction argument type: vector<u64>")
+	})
+}
+
+func TestEncodeBCSArgument(t *testing.T) {
+	address := diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	cases := []struct {
+		typ      string
+		value    interface{}
+		expected []byte
+	}{
+		{"vector<u64>", []uint64{1, 2}, []byte{2, 1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0}},
+		{"vector<bool>", []interface{}{true, false}, []byte{2, 1, 0}},
+		{"vector<address>", []diemtypes.AccountAddress{address}, append([]byte{1}, address[:]...)},
+		{"vector<vector<u8>>", [][]byte{{1}, {}}, []byte{2, 1, 1, 0}},
+		{"vector<u128>", []serde.Uint128{}, []byte{0}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.typ, func(t *testing.T) {
+			typ := diemtypes.MustParseTypeTag(tc.typ)
+			assert.True(t, diemtypes.IsBCSArgumentType(typ))
+			assert.False(t, diemtypes.IsTransactionArgumentType(typ))
+			bytes, err := diemtypes.EncodeBCSArgument(typ, tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, bytes)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := diemtypes.EncodeBCSArgument(diemtypes.MustParseTypeTag("vector<u64>"), []interface{}{uint64(1), 2})
+		assert.EqualError(t, err, "expected u64 value, got int")
+		_, err = diemtypes.EncodeBCSArgument(diemtypes.MustParseTypeTag("vector<u64>"), uint64(1))
+		assert.EqualError(t, err, "expected vector<u64> value, got uint64")
+		_, err = diemtypes.EncodeBCSArgument(diemtypes.Currency("XUS"), nil)
+		assert.EqualError(t, err, "unsupported script function argument type: 0x1::XUS::XUS")
+		_, err = diemtypes.EncodeBCSArgument(diemtypes.MustParseTypeTag("vector<signer>"), nil)
+		assert.Error(t, err)
+	})
+}
@@ -2,4 +2,8 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Provides Diem on-chain data types, utility functions for converting types.
+//
+// AccountAddress and SubAddress are encoded as hex strings by encoding/json, and formatted as
+// hex strings by "%v", "%s" and "%x" verbs; prior versions encoded them as arrays of numbers,
+// so JSON data written by prior versions is not decodable.
 package diemtypes
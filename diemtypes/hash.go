@@ -22,11 +22,18 @@ func Hash(prefix []byte, bytes []byte) []byte {
 	return sha256.Sum(nil)
 }
 
+// Hash returns the hash of the signed transaction as it is executed on chain: sha3 256 of
+// "Transaction" hashing prefix and BCS bytes of the user transaction. It can be computed before
+// submitting the transaction for matching the hash of transaction returned by server.
+func (t *SignedTransaction) Hash() HashValue {
+	return Hash(
+		HashPrefix("Transaction"),
+		ToBCS(&Transaction__UserTransaction{*t}),
+	)
+}
+
 // TransactionHash returns hex-encoded hash string of the
 // transaction that `SignedTransaction` may executed.
 func (t *SignedTransaction) TransactionHash() string {
-	return hex.EncodeToString(Hash(
-		HashPrefix("Transaction"),
-		ToBCS(&Transaction__UserTransaction{*t}),
-	))
+	return hex.EncodeToString(t.Hash())
 }
@@ -0,0 +1,882 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// JSON format of BCS types:
+//   - byte arrays, account addresses and keys are hex-encoded strings
+//   - type tags are canonical type tag strings, see `ParseTypeTag`
+//   - unit enum variants, e.g. `RefundReason`, are snake case variant name strings
+//   - other enum variants are objects: {"type": snake case variant name, "value": variant value}
+//   - u64 is JSON number and u128 is decimal string
+//   - `WriteSetPayload` is hex-encoded BCS bytes
+//
+// Interface values can be decoded by `UnmarshalMetadataJSON`, `UnmarshalTransactionPayloadJSON`,
+// `UnmarshalTransactionArgumentJSON` and `UnmarshalTransactionAuthenticatorJSON`.
+
+type jsonEnum struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func marshalEnum(typ string, value interface{}) ([]byte, error) {
+	if value == nil {
+		return json.Marshal(jsonEnum{Type: typ})
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonEnum{Type: typ, Value: raw})
+}
+
+func unmarshalEnum(data []byte, name string) (*jsonEnum, error) {
+	var ret jsonEnum
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return nil, fmt.Errorf("invalid %s json: %v", name, err)
+	}
+	if ret.Type == "" {
+		return nil, fmt.Errorf("invalid %s json: missing type", name)
+	}
+	return &ret, nil
+}
+
+func unmarshalEnumValue(enum *jsonEnum, name string, ret interface{}) error {
+	if len(enum.Value) == 0 {
+		return fmt.Errorf("invalid %s json: missing %s value", name, enum.Type)
+	}
+	if err := json.Unmarshal(enum.Value, ret); err != nil {
+		return fmt.Errorf("invalid %s json: %v", name, err)
+	}
+	return nil
+}
+
+func unknownEnumType(enum *jsonEnum, name string) error {
+	return fmt.Errorf("invalid %s json: unknown type %q", name, enum.Type)
+}
+
+func isJSONNull(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) == 0 || bytes.Equal(data, []byte("null"))
+}
+
+// hexBytes is []byte marshaled as hex-encoded string
+type hexBytes []byte
+
+func (b hexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+func (b *hexBytes) UnmarshalText(text []byte) error {
+	ret, err := hex.DecodeString(trimHexPrefix(string(text)))
+	if err != nil {
+		return fmt.Errorf("invalid hex string %q: %v", text, err)
+	}
+	*b = ret
+	return nil
+}
+
+func typeTagStrings(tags []TypeTag) []string {
+	ret := make([]string, len(tags))
+	for i, tag := range tags {
+		ret[i] = fmt.Sprint(tag)
+	}
+	return ret
+}
+
+func parseTypeTags(strs []string) ([]TypeTag, error) {
+	ret := make([]TypeTag, len(strs))
+	for i, str := range strs {
+		tag, err := ParseTypeTag(str)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = tag
+	}
+	return ret, nil
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionArgument__U8) MarshalJSON() ([]byte, error) {
+	return marshalEnum("u8", uint8(*obj))
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionArgument__U64) MarshalJSON() ([]byte, error) {
+	return marshalEnum("u64", uint64(*obj))
+}
+
+// MarshalJSON implements `json.Marshaler`, the value is decimal string
+func (obj *TransactionArgument__U128) MarshalJSON() ([]byte, error) {
+	return marshalEnum("u128", uint128String(serde.Uint128(*obj)))
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionArgument__Address) MarshalJSON() ([]byte, error) {
+	return marshalEnum("address", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionArgument__U8Vector) MarshalJSON() ([]byte, error) {
+	return marshalEnum("u8vector", hexBytes(*obj))
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionArgument__Bool) MarshalJSON() ([]byte, error) {
+	return marshalEnum("bool", bool(*obj))
+}
+
+// UnmarshalTransactionArgumentJSON decodes `TransactionArgument` JSON
+func UnmarshalTransactionArgumentJSON(data []byte) (TransactionArgument, error) {
+	const name = "transaction argument"
+	enum, err := unmarshalEnum(data, name)
+	if err != nil {
+		return nil, err
+	}
+	switch enum.Type {
+	case "u8":
+		var ret TransactionArgument__U8
+		return &ret, unmarshalEnumValue(enum, name, &ret)
+	case "u64":
+		var ret TransactionArgument__U64
+		return &ret, unmarshalEnumValue(enum, name, &ret)
+	case "u128":
+		var str string
+		if err := unmarshalEnumValue(enum, name, &str); err != nil {
+			return nil, err
+		}
+		value, err := parseUint128(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s json: %v", name, err)
+		}
+		ret := TransactionArgument__U128(value)
+		return &ret, nil
+	case "address":
+		var ret TransactionArgument__Address
+		return &ret, unmarshalEnumValue(enum, name, &ret.Value)
+	case "u8vector":
+		var value hexBytes
+		if err := unmarshalEnumValue(enum, name, &value); err != nil {
+			return nil, err
+		}
+		ret := TransactionArgument__U8Vector(value)
+		return &ret, nil
+	case "bool":
+		var ret TransactionArgument__Bool
+		return &ret, unmarshalEnumValue(enum, name, &ret)
+	}
+	return nil, unknownEnumType(enum, name)
+}
+
+var maxUint64 = new(big.Int).SetUint64(^uint64(0))
+
+func uint128String(value serde.Uint128) string {
+	ret := new(big.Int).SetUint64(value.High)
+	ret.Lsh(ret, 64)
+	ret.Or(ret, new(big.Int).SetUint64(value.Low))
+	return ret.String()
+}
+
+func parseUint128(str string) (serde.Uint128, error) {
+	value, ok := new(big.Int).SetString(str, 10)
+	if !ok || value.Sign() < 0 || value.BitLen() > 128 {
+		return serde.Uint128{}, fmt.Errorf("invalid u128 %q", str)
+	}
+	return serde.Uint128{
+		High: new(big.Int).Rsh(value, 64).Uint64(),
+		Low:  new(big.Int).And(value, maxUint64).Uint64(),
+	}, nil
+}
+
+type jsonScript struct {
+	Code   hexBytes          `json:"code"`
+	TyArgs []string          `json:"ty_args"`
+	Args   []json.RawMessage `json:"args"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj Script) MarshalJSON() ([]byte, error) {
+	args := make([]json.RawMessage, len(obj.Args))
+	for i, arg := range obj.Args {
+		raw, err := json.Marshal(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = raw
+	}
+	return json.Marshal(jsonScript{Code: obj.Code, TyArgs: typeTagStrings(obj.TyArgs), Args: args})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *Script) UnmarshalJSON(data []byte) error {
+	var script jsonScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		return err
+	}
+	tyArgs, err := parseTypeTags(script.TyArgs)
+	if err != nil {
+		return err
+	}
+	args := make([]TransactionArgument, len(script.Args))
+	for i, raw := range script.Args {
+		if args[i], err = UnmarshalTransactionArgumentJSON(raw); err != nil {
+			return err
+		}
+	}
+	*obj = Script{Code: script.Code, TyArgs: tyArgs, Args: args}
+	return nil
+}
+
+type jsonModuleID struct {
+	Address AccountAddress `json:"address"`
+	Name    Identifier     `json:"name"`
+}
+
+type jsonScriptFunction struct {
+	Module   jsonModuleID `json:"module"`
+	Function Identifier   `json:"function"`
+	TyArgs   []string     `json:"ty_args"`
+	Args     []hexBytes   `json:"args"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj ScriptFunction) MarshalJSON() ([]byte, error) {
+	args := make([]hexBytes, len(obj.Args))
+	for i, arg := range obj.Args {
+		args[i] = arg
+	}
+	return json.Marshal(jsonScriptFunction{
+		Module:   jsonModuleID(obj.Module),
+		Function: obj.Function,
+		TyArgs:   typeTagStrings(obj.TyArgs),
+		Args:     args,
+	})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *ScriptFunction) UnmarshalJSON(data []byte) error {
+	var fn jsonScriptFunction
+	if err := json.Unmarshal(data, &fn); err != nil {
+		return err
+	}
+	tyArgs, err := parseTypeTags(fn.TyArgs)
+	if err != nil {
+		return err
+	}
+	args := make([][]byte, len(fn.Args))
+	for i, arg := range fn.Args {
+		args[i] = arg
+	}
+	*obj = ScriptFunction{
+		Module:   ModuleId(fn.Module),
+		Function: fn.Function,
+		TyArgs:   tyArgs,
+		Args:     args,
+	}
+	return nil
+}
+
+type jsonModule struct {
+	Code hexBytes `json:"code"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj Module) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonModule{Code: obj.Code})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *Module) UnmarshalJSON(data []byte) error {
+	var module jsonModule
+	if err := json.Unmarshal(data, &module); err != nil {
+		return err
+	}
+	obj.Code = module.Code
+	return nil
+}
+
+// MarshalJSON implements `json.Marshaler`, the value is hex-encoded BCS bytes of the
+// `WriteSetPayload`
+func (obj *TransactionPayload__WriteSet) MarshalJSON() ([]byte, error) {
+	bytes, err := obj.Value.BcsSerialize()
+	if err != nil {
+		return nil, err
+	}
+	return marshalEnum("write_set", hexBytes(bytes))
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionPayload__Script) MarshalJSON() ([]byte, error) {
+	return marshalEnum("script", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionPayload__Module) MarshalJSON() ([]byte, error) {
+	return marshalEnum("module", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionPayload__ScriptFunction) MarshalJSON() ([]byte, error) {
+	return marshalEnum("script_function", obj.Value)
+}
+
+// UnmarshalTransactionPayloadJSON decodes `TransactionPayload` JSON
+func UnmarshalTransactionPayloadJSON(data []byte) (TransactionPayload, error) {
+	const name = "transaction payload"
+	enum, err := unmarshalEnum(data, name)
+	if err != nil {
+		return nil, err
+	}
+	switch enum.Type {
+	case "write_set":
+		var bytes hexBytes
+		if err := unmarshalEnumValue(enum, name, &bytes); err != nil {
+			return nil, err
+		}
+		value, err := BcsDeserializeWriteSetPayload(bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s json: %v", name, err)
+		}
+		return &TransactionPayload__WriteSet{Value: value}, nil
+	case "script":
+		var ret TransactionPayload__Script
+		return &ret, unmarshalEnumValue(enum, name, &ret.Value)
+	case "module":
+		var ret TransactionPayload__Module
+		return &ret, unmarshalEnumValue(enum, name, &ret.Value)
+	case "script_function":
+		var ret TransactionPayload__ScriptFunction
+		return &ret, unmarshalEnumValue(enum, name, &ret.Value)
+	}
+	return nil, unknownEnumType(enum, name)
+}
+
+type jsonRawTransaction struct {
+	Sender                  AccountAddress  `json:"sender"`
+	SequenceNumber          uint64          `json:"sequence_number"`
+	Payload                 json.RawMessage `json:"payload"`
+	MaxGasAmount            uint64          `json:"max_gas_amount"`
+	GasUnitPrice            uint64          `json:"gas_unit_price"`
+	GasCurrencyCode         string          `json:"gas_currency_code"`
+	ExpirationTimestampSecs uint64          `json:"expiration_timestamp_secs"`
+	ChainID                 ChainId         `json:"chain_id"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj RawTransaction) MarshalJSON() ([]byte, error) {
+	payload, err := json.Marshal(obj.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonRawTransaction{
+		Sender:                  obj.Sender,
+		SequenceNumber:          obj.SequenceNumber,
+		Payload:                 payload,
+		MaxGasAmount:            obj.MaxGasAmount,
+		GasUnitPrice:            obj.GasUnitPrice,
+		GasCurrencyCode:         obj.GasCurrencyCode,
+		ExpirationTimestampSecs: obj.ExpirationTimestampSecs,
+		ChainID:                 obj.ChainId,
+	})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *RawTransaction) UnmarshalJSON(data []byte) error {
+	var txn jsonRawTransaction
+	if err := json.Unmarshal(data, &txn); err != nil {
+		return err
+	}
+	var payload TransactionPayload
+	if !isJSONNull(txn.Payload) {
+		var err error
+		if payload, err = UnmarshalTransactionPayloadJSON(txn.Payload); err != nil {
+			return err
+		}
+	}
+	*obj = RawTransaction{
+		Sender:                  txn.Sender,
+		SequenceNumber:          txn.SequenceNumber,
+		Payload:                 payload,
+		MaxGasAmount:            txn.MaxGasAmount,
+		GasUnitPrice:            txn.GasUnitPrice,
+		GasCurrencyCode:         txn.GasCurrencyCode,
+		ExpirationTimestampSecs: txn.ExpirationTimestampSecs,
+		ChainId:                 txn.ChainID,
+	}
+	return nil
+}
+
+type jsonSignedTransaction struct {
+	RawTxn        RawTransaction  `json:"raw_txn"`
+	Authenticator json.RawMessage `json:"authenticator"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj SignedTransaction) MarshalJSON() ([]byte, error) {
+	auth, err := json.Marshal(obj.Authenticator)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonSignedTransaction{RawTxn: obj.RawTxn, Authenticator: auth})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *SignedTransaction) UnmarshalJSON(data []byte) error {
+	var txn jsonSignedTransaction
+	if err := json.Unmarshal(data, &txn); err != nil {
+		return err
+	}
+	var auth TransactionAuthenticator
+	if !isJSONNull(txn.Authenticator) {
+		var err error
+		if auth, err = UnmarshalTransactionAuthenticatorJSON(txn.Authenticator); err != nil {
+			return err
+		}
+	}
+	*obj = SignedTransaction{RawTxn: txn.RawTxn, Authenticator: auth}
+	return nil
+}
+
+type jsonSignature struct {
+	PublicKey hexBytes `json:"public_key"`
+	Signature hexBytes `json:"signature"`
+}
+
+type jsonMultiAgentAuthenticator struct {
+	Sender                   json.RawMessage   `json:"sender"`
+	SecondarySignerAddresses []AccountAddress  `json:"secondary_signer_addresses"`
+	SecondarySigners         []json.RawMessage `json:"secondary_signers"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionAuthenticator__Ed25519) MarshalJSON() ([]byte, error) {
+	return marshalEnum("ed25519", jsonSignature{PublicKey: hexBytes(obj.PublicKey), Signature: hexBytes(obj.Signature)})
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionAuthenticator__MultiEd25519) MarshalJSON() ([]byte, error) {
+	return marshalEnum("multi_ed25519", jsonSignature{PublicKey: hexBytes(obj.PublicKey), Signature: hexBytes(obj.Signature)})
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TransactionAuthenticator__MultiAgent) MarshalJSON() ([]byte, error) {
+	sender, err := json.Marshal(obj.Sender)
+	if err != nil {
+		return nil, err
+	}
+	signers := make([]json.RawMessage, len(obj.SecondarySigners))
+	for i, signer := range obj.SecondarySigners {
+		if signers[i], err = json.Marshal(signer); err != nil {
+			return nil, err
+		}
+	}
+	addresses := obj.SecondarySignerAddresses
+	if addresses == nil {
+		addresses = []AccountAddress{}
+	}
+	return marshalEnum("multi_agent", jsonMultiAgentAuthenticator{
+		Sender:                   sender,
+		SecondarySignerAddresses: addresses,
+		SecondarySigners:         signers,
+	})
+}
+
+// UnmarshalTransactionAuthenticatorJSON decodes `TransactionAuthenticator` JSON
+func UnmarshalTransactionAuthenticatorJSON(data []byte) (TransactionAuthenticator, error) {
+	const name = "transaction authenticator"
+	enum, err := unmarshalEnum(data, name)
+	if err != nil {
+		return nil, err
+	}
+	switch enum.Type {
+	case "ed25519":
+		var sig jsonSignature
+		if err := unmarshalEnumValue(enum, name, &sig); err != nil {
+			return nil, err
+		}
+		return &TransactionAuthenticator__Ed25519{
+			PublicKey: Ed25519PublicKey(sig.PublicKey),
+			Signature: Ed25519Signature(sig.Signature),
+		}, nil
+	case "multi_ed25519":
+		var sig jsonSignature
+		if err := unmarshalEnumValue(enum, name, &sig); err != nil {
+			return nil, err
+		}
+		return &TransactionAuthenticator__MultiEd25519{
+			PublicKey: MultiEd25519PublicKey(sig.PublicKey),
+			Signature: MultiEd25519Signature(sig.Signature),
+		}, nil
+	case "multi_agent":
+		var auth jsonMultiAgentAuthenticator
+		if err := unmarshalEnumValue(enum, name, &auth); err != nil {
+			return nil, err
+		}
+		sender, err := unmarshalAccountAuthenticatorJSON(auth.Sender)
+		if err != nil {
+			return nil, err
+		}
+		signers := make([]AccountAuthenticator, len(auth.SecondarySigners))
+		for i, raw := range auth.SecondarySigners {
+			if signers[i], err = unmarshalAccountAuthenticatorJSON(raw); err != nil {
+				return nil, err
+			}
+		}
+		addresses := auth.SecondarySignerAddresses
+		if addresses == nil {
+			addresses = []AccountAddress{}
+		}
+		return &TransactionAuthenticator__MultiAgent{
+			Sender:                   sender,
+			SecondarySignerAddresses: addresses,
+			SecondarySigners:         signers,
+		}, nil
+	}
+	return nil, unknownEnumType(enum, name)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *AccountAuthenticator__Ed25519) MarshalJSON() ([]byte, error) {
+	return marshalEnum("ed25519", jsonSignature{PublicKey: hexBytes(obj.PublicKey), Signature: hexBytes(obj.Signature)})
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *AccountAuthenticator__MultiEd25519) MarshalJSON() ([]byte, error) {
+	return marshalEnum("multi_ed25519", jsonSignature{PublicKey: hexBytes(obj.PublicKey), Signature: hexBytes(obj.Signature)})
+}
+
+func unmarshalAccountAuthenticatorJSON(data []byte) (AccountAuthenticator, error) {
+	const name = "account authenticator"
+	enum, err := unmarshalEnum(data, name)
+	if err != nil {
+		return nil, err
+	}
+	var sig jsonSignature
+	switch enum.Type {
+	case "ed25519":
+		if err := unmarshalEnumValue(enum, name, &sig); err != nil {
+			return nil, err
+		}
+		return &AccountAuthenticator__Ed25519{
+			PublicKey: Ed25519PublicKey(sig.PublicKey),
+			Signature: Ed25519Signature(sig.Signature),
+		}, nil
+	case "multi_ed25519":
+		if err := unmarshalEnumValue(enum, name, &sig); err != nil {
+			return nil, err
+		}
+		return &AccountAuthenticator__MultiEd25519{
+			PublicKey: MultiEd25519PublicKey(sig.PublicKey),
+			Signature: MultiEd25519Signature(sig.Signature),
+		}, nil
+	}
+	return nil, unknownEnumType(enum, name)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *Metadata__Undefined) MarshalJSON() ([]byte, error) {
+	return marshalEnum("undefined", nil)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *Metadata__GeneralMetadata) MarshalJSON() ([]byte, error) {
+	return marshalEnum("general_metadata", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *Metadata__TravelRuleMetadata) MarshalJSON() ([]byte, error) {
+	return marshalEnum("travel_rule_metadata", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *Metadata__UnstructuredBytesMetadata) MarshalJSON() ([]byte, error) {
+	return marshalEnum("unstructured_bytes_metadata", jsonUnstructuredBytesMetadata{
+		Metadata: (*hexBytes)(obj.Value.Metadata),
+	})
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *Metadata__RefundMetadata) MarshalJSON() ([]byte, error) {
+	return marshalEnum("refund_metadata", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *Metadata__CoinTradeMetadata) MarshalJSON() ([]byte, error) {
+	return marshalEnum("coin_trade_metadata", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *Metadata__PaymentMetadata) MarshalJSON() ([]byte, error) {
+	return marshalEnum("payment_metadata", obj.Value)
+}
+
+// UnmarshalMetadataJSON decodes `Metadata` JSON
+func UnmarshalMetadataJSON(data []byte) (Metadata, error) {
+	const name = "metadata"
+	enum, err := unmarshalEnum(data, name)
+	if err != nil {
+		return nil, err
+	}
+	switch enum.Type {
+	case "undefined":
+		return &Metadata__Undefined{}, nil
+	case "general_metadata":
+		var v0 GeneralMetadata__GeneralMetadataVersion0
+		if err := unmarshalVersion0(enum, name, &v0.Value); err != nil {
+			return nil, err
+		}
+		return &Metadata__GeneralMetadata{Value: &v0}, nil
+	case "travel_rule_metadata":
+		var v0 TravelRuleMetadata__TravelRuleMetadataVersion0
+		if err := unmarshalVersion0(enum, name, &v0.Value); err != nil {
+			return nil, err
+		}
+		return &Metadata__TravelRuleMetadata{Value: &v0}, nil
+	case "unstructured_bytes_metadata":
+		var value jsonUnstructuredBytesMetadata
+		if err := unmarshalEnumValue(enum, name, &value); err != nil {
+			return nil, err
+		}
+		return &Metadata__UnstructuredBytesMetadata{
+			Value: UnstructuredBytesMetadata{Metadata: (*[]byte)(value.Metadata)},
+		}, nil
+	case "refund_metadata":
+		var v0 RefundMetadata__RefundMetadataV0
+		if err := unmarshalVersion0(enum, name, &v0.Value); err != nil {
+			return nil, err
+		}
+		return &Metadata__RefundMetadata{Value: &v0}, nil
+	case "coin_trade_metadata":
+		var v0 CoinTradeMetadata__CoinTradeMetadataV0
+		if err := unmarshalVersion0(enum, name, &v0.Value); err != nil {
+			return nil, err
+		}
+		return &Metadata__CoinTradeMetadata{Value: &v0}, nil
+	case "payment_metadata":
+		var v0 PaymentMetadata__PaymentMetadataVersion0
+		if err := unmarshalVersion0(enum, name, &v0.Value); err != nil {
+			return nil, err
+		}
+		return &Metadata__PaymentMetadata{Value: &v0}, nil
+	}
+	return nil, unknownEnumType(enum, name)
+}
+
+// unmarshalVersion0 decodes versioned metadata enum value {"type": "v0", "value": ...}, which is
+// the only version defined
+func unmarshalVersion0(metadata *jsonEnum, name string, ret interface{}) error {
+	var version jsonEnum
+	if err := unmarshalEnumValue(metadata, name, &version); err != nil {
+		return err
+	}
+	if version.Type != "v0" {
+		return fmt.Errorf("invalid %s json: unknown %s version %q", name, metadata.Type, version.Type)
+	}
+	return unmarshalEnumValue(&version, name, ret)
+}
+
+type jsonGeneralMetadataV0 struct {
+	ToSubaddress    *hexBytes `json:"to_subaddress,omitempty"`
+	FromSubaddress  *hexBytes `json:"from_subaddress,omitempty"`
+	ReferencedEvent *uint64   `json:"referenced_event,omitempty"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *GeneralMetadata__GeneralMetadataVersion0) MarshalJSON() ([]byte, error) {
+	return marshalEnum("v0", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj GeneralMetadataV0) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonGeneralMetadataV0{
+		ToSubaddress:    (*hexBytes)(obj.ToSubaddress),
+		FromSubaddress:  (*hexBytes)(obj.FromSubaddress),
+		ReferencedEvent: obj.ReferencedEvent,
+	})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *GeneralMetadataV0) UnmarshalJSON(data []byte) error {
+	var v0 jsonGeneralMetadataV0
+	if err := json.Unmarshal(data, &v0); err != nil {
+		return err
+	}
+	*obj = GeneralMetadataV0{
+		ToSubaddress:    (*[]byte)(v0.ToSubaddress),
+		FromSubaddress:  (*[]byte)(v0.FromSubaddress),
+		ReferencedEvent: v0.ReferencedEvent,
+	}
+	return nil
+}
+
+type jsonTravelRuleMetadataV0 struct {
+	OffChainReferenceID *string `json:"off_chain_reference_id,omitempty"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *TravelRuleMetadata__TravelRuleMetadataVersion0) MarshalJSON() ([]byte, error) {
+	return marshalEnum("v0", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj TravelRuleMetadataV0) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTravelRuleMetadataV0{OffChainReferenceID: obj.OffChainReferenceId})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *TravelRuleMetadataV0) UnmarshalJSON(data []byte) error {
+	var v0 jsonTravelRuleMetadataV0
+	if err := json.Unmarshal(data, &v0); err != nil {
+		return err
+	}
+	obj.OffChainReferenceId = v0.OffChainReferenceID
+	return nil
+}
+
+type jsonUnstructuredBytesMetadata struct {
+	Metadata *hexBytes `json:"metadata,omitempty"`
+}
+
+type jsonRefundMetadataV0 struct {
+	TransactionVersion uint64          `json:"transaction_version"`
+	Reason             json.RawMessage `json:"reason"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *RefundMetadata__RefundMetadataV0) MarshalJSON() ([]byte, error) {
+	return marshalEnum("v0", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj RefundMetadataV0) MarshalJSON() ([]byte, error) {
+	reason, err := json.Marshal(obj.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonRefundMetadataV0{TransactionVersion: obj.TransactionVersion, Reason: reason})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *RefundMetadataV0) UnmarshalJSON(data []byte) error {
+	var v0 jsonRefundMetadataV0
+	if err := json.Unmarshal(data, &v0); err != nil {
+		return err
+	}
+	var name string
+	if err := json.Unmarshal(v0.Reason, &name); err != nil {
+		return fmt.Errorf("invalid refund reason json: %v", err)
+	}
+	reason, err := parseRefundReason(name)
+	if err != nil {
+		return err
+	}
+	*obj = RefundMetadataV0{TransactionVersion: v0.TransactionVersion, Reason: reason}
+	return nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (*RefundReason__OtherReason) MarshalText() ([]byte, error) {
+	return []byte("other_reason"), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (*RefundReason__InvalidSubaddress) MarshalText() ([]byte, error) {
+	return []byte("invalid_subaddress"), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (*RefundReason__UserInitiatedPartialRefund) MarshalText() ([]byte, error) {
+	return []byte("user_initiated_partial_refund"), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (*RefundReason__UserInitiatedFullRefund) MarshalText() ([]byte, error) {
+	return []byte("user_initiated_full_refund"), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (*RefundReason__InvalidReferenceId) MarshalText() ([]byte, error) {
+	return []byte("invalid_reference_id"), nil
+}
+
+func parseRefundReason(name string) (RefundReason, error) {
+	switch name {
+	case "other_reason":
+		return &RefundReason__OtherReason{}, nil
+	case "invalid_subaddress":
+		return &RefundReason__InvalidSubaddress{}, nil
+	case "user_initiated_partial_refund":
+		return &RefundReason__UserInitiatedPartialRefund{}, nil
+	case "user_initiated_full_refund":
+		return &RefundReason__UserInitiatedFullRefund{}, nil
+	case "invalid_reference_id":
+		return &RefundReason__InvalidReferenceId{}, nil
+	}
+	return nil, fmt.Errorf("invalid refund reason %q", name)
+}
+
+type jsonCoinTradeMetadataV0 struct {
+	TradeIDs []string `json:"trade_ids"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *CoinTradeMetadata__CoinTradeMetadataV0) MarshalJSON() ([]byte, error) {
+	return marshalEnum("v0", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj CoinTradeMetadataV0) MarshalJSON() ([]byte, error) {
+	ids := obj.TradeIds
+	if ids == nil {
+		ids = []string{}
+	}
+	return json.Marshal(jsonCoinTradeMetadataV0{TradeIDs: ids})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *CoinTradeMetadataV0) UnmarshalJSON(data []byte) error {
+	var v0 jsonCoinTradeMetadataV0
+	if err := json.Unmarshal(data, &v0); err != nil {
+		return err
+	}
+	obj.TradeIds = append(make([]string, 0, len(v0.TradeIDs)), v0.TradeIDs...)
+	return nil
+}
+
+type jsonPaymentMetadataV0 struct {
+	ReferenceID hexBytes `json:"reference_id"`
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj *PaymentMetadata__PaymentMetadataVersion0) MarshalJSON() ([]byte, error) {
+	return marshalEnum("v0", obj.Value)
+}
+
+// MarshalJSON implements `json.Marshaler`
+func (obj PaymentMetadataV0) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonPaymentMetadataV0{ReferenceID: obj.ReferenceId[:]})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (obj *PaymentMetadataV0) UnmarshalJSON(data []byte) error {
+	var v0 jsonPaymentMetadataV0
+	if err := json.Unmarshal(data, &v0); err != nil {
+		return err
+	}
+	if len(v0.ReferenceID) != len(obj.ReferenceId) {
+		return errors.New("invalid payment metadata json: reference id should be 16 bytes")
+	}
+	copy(obj.ReferenceId[:], v0.ReferenceID)
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedTransactionJSON(t *testing.T) {
+	receiver := diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	payloads := map[string]diemtypes.TransactionPayload{
+		"script": &diemtypes.TransactionPayload__Script{Value: stdlib.EncodePeerToPeerWithMetadataScript(
+			diemtypes.Currency("XUS"), receiver, 100, []byte{1, 2}, nil)},
+		"script function": stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			diemtypes.Currency("XUS"), receiver, 100, []byte{1, 2}, []byte{}),
+		"module": &diemtypes.TransactionPayload__Module{Value: diemtypes.Module{Code: []byte{0xa1, 0x1c}}},
+	}
+	for name, payload := range payloads {
+		t.Run(name, func(t *testing.T) {
+			for _, keys := range []*diemkeys.Keys{diemkeys.MustGenKeys(), diemkeys.MustGenMultiSigKeys()} {
+				txn := diemsigner.SignTxn(keys, keys.AccountAddress(), 1, payload, 1_000_000, 0, "XUS", 1593189628, testnet.ChainID)
+
+				data, err := json.Marshal(txn)
+				require.NoError(t, err)
+				var ret diemtypes.SignedTransaction
+				require.NoError(t, json.Unmarshal(data, &ret))
+				assert.Equal(t, diemtypes.ToHex(txn), diemtypes.ToHex(&ret))
+				assert.NoError(t, ret.VerifySignature())
+			}
+		})
+	}
+}
+
+func TestScriptJSON(t *testing.T) {
+	u128 := diemtypes.TransactionArgument__U128{High: 1, Low: 2}
+	u8 := diemtypes.TransactionArgument__U8(8)
+	u64 := diemtypes.TransactionArgument__U64(64)
+	bytes := diemtypes.TransactionArgument__U8Vector([]byte{0xab})
+	boolean := diemtypes.TransactionArgument__Bool(true)
+	script := diemtypes.Script{
+		Code:   []byte{0xa1, 0x1c},
+		TyArgs: []diemtypes.TypeTag{diemtypes.Currency("XUS"), &diemtypes.TypeTag__U8{}},
+		Args: []diemtypes.TransactionArgument{
+			&u8, &u64, &u128, &bytes, &boolean,
+			&diemtypes.TransactionArgument__Address{Value: diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")},
+		},
+	}
+	data, err := json.Marshal(script)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"code": "a11c",
+		"ty_args": ["0x1::XUS::XUS", "u8"],
+		"args": [
+			{"type": "u8", "value": 8},
+			{"type": "u64", "value": 64},
+			{"type": "u128", "value": "18446744073709551618"},
+			{"type": "u8vector", "value": "ab"},
+			{"type": "bool", "value": true},
+			{"type": "address", "value": "f72589b71ff4f8d139674a3f7369c69b"}
+		]
+	}`, string(data))
+
+	var ret diemtypes.Script
+	require.NoError(t, json.Unmarshal(data, &ret))
+	assert.Equal(t, script, ret)
+
+	t.Run("invalid", func(t *testing.T) {
+		cases := []string{
+			`{"code": "xyz"}`,
+			`{"ty_args": ["0x1::"]}`,
+			`{"args": [{"type": "u256", "value": 1}]}`,
+			`{"args": [{"type": "u128", "value": "-1"}]}`,
+			`{"args": [{"type": "u128", "value": "340282366920938463463374607431768211456"}]}`,
+			`{"args": [{"value": 1}]}`,
+			`{"args": [{"type": "u8"}]}`,
+		}
+		for _, data := range cases {
+			assert.Error(t, json.Unmarshal([]byte(data), &ret), data)
+		}
+	})
+}
+
+func TestMetadataJSON(t *testing.T) {
+	subAddress, err := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	require.NoError(t, err)
+	referenceID, err := txnmetadata.ParseReferenceID("4185027f-0574-6f55-2668-3a38fdb5de98")
+	require.NoError(t, err)
+	offChainReferenceID := "ref"
+	cases := []struct {
+		name     string
+		metadata []byte
+		json     string
+	}{
+		{
+			name:     "general metadata",
+			metadata: txnmetadata.NewGeneralMetadataToSubAddress(subAddress),
+			json:     `{"type": "general_metadata", "value": {"type": "v0", "value": {"to_subaddress": "8f8b82153010a1bd"}}}`,
+		},
+		{
+			name: "travel rule metadata",
+			metadata: diemtypes.ToBCS(&diemtypes.Metadata__TravelRuleMetadata{
+				Value: &diemtypes.TravelRuleMetadata__TravelRuleMetadataVersion0{
+					Value: diemtypes.TravelRuleMetadataV0{OffChainReferenceId: &offChainReferenceID},
+				},
+			}),
+			json: `{"type": "travel_rule_metadata", "value": {"type": "v0", "value": {"off_chain_reference_id": "ref"}}}`,
+		},
+		{
+			name:     "refund metadata",
+			metadata: txnmetadata.NewRefundMetadata(123, &diemtypes.RefundReason__InvalidSubaddress{}),
+			json:     `{"type": "refund_metadata", "value": {"type": "v0", "value": {"transaction_version": 123, "reason": "invalid_subaddress"}}}`,
+		},
+		{
+			name:     "payment metadata",
+			metadata: txnmetadata.NewPaymentMetadata(referenceID),
+			json:     `{"type": "payment_metadata", "value": {"type": "v0", "value": {"reference_id": "4185027f05746f5526683a38fdb5de98"}}}`,
+		},
+		{
+			name:     "coin trade metadata",
+			metadata: txnmetadata.NewCoinTradeMetadata([]string{"t1", "t2"}),
+			json:     `{"type": "coin_trade_metadata", "value": {"type": "v0", "value": {"trade_ids": ["t1", "t2"]}}}`,
+		},
+		{
+			name: "unstructured bytes metadata",
+			metadata: diemtypes.ToBCS(&diemtypes.Metadata__UnstructuredBytesMetadata{
+				Value: diemtypes.UnstructuredBytesMetadata{Metadata: &[]byte{0xab}},
+			}),
+			json: `{"type": "unstructured_bytes_metadata", "value": {"metadata": "ab"}}`,
+		},
+		{
+			name:     "undefined",
+			metadata: diemtypes.ToBCS(&diemtypes.Metadata__Undefined{}),
+			json:     `{"type": "undefined"}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			metadata, err := diemtypes.BcsDeserializeMetadata(tc.metadata)
+			require.NoError(t, err)
+			data, err := json.Marshal(metadata)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.json, string(data))
+
+			ret, err := diemtypes.UnmarshalMetadataJSON(data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.metadata, diemtypes.ToBCS(ret))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		cases := []string{
+			`{"type": "unknown"}`,
+			`{"type": "general_metadata", "value": {"type": "v1", "value": {}}}`,
+			`{"type": "payment_metadata", "value": {"type": "v0", "value": {"reference_id": "ab"}}}`,
+			`{"type": "refund_metadata", "value": {"type": "v0", "value": {"reason": "unknown"}}}`,
+			`[]`,
+		}
+		for _, data := range cases {
+			_, err := diemtypes.UnmarshalMetadataJSON([]byte(data))
+			assert.Error(t, err, data)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+)
+
+// multi ed25519 public key and signature layout
+const (
+	multiEd25519MaxNumOfKeys     = 32
+	multiEd25519BitmapNumOfBytes = 4
+)
+
+// ErrInvalidSignature is returned by `VerifySignature` when the signature is not valid for
+// the transaction, the returned error wraps it with details.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// SigningMessage returns the message the transaction sender signs: "RawTransaction" hashing
+// prefix and BCS bytes of the raw transaction
+func (t *RawTransaction) SigningMessage() []byte {
+	return append(HashPrefix("RawTransaction"), ToBCS(t)...)
+}
+
+// VerifySignature verifies the given single signer authenticator is signed for the raw
+// transaction; Ed25519 and MultiEd25519 authenticators are supported.
+// Returns error wraps `ErrInvalidSignature` if verification failed.
+func (t *RawTransaction) VerifySignature(auth TransactionAuthenticator) error {
+	msg := t.SigningMessage()
+	switch a := auth.(type) {
+	case *TransactionAuthenticator__Ed25519:
+		return verifyEd25519(a.PublicKey, a.Signature, msg)
+	case *TransactionAuthenticator__MultiEd25519:
+		return verifyMultiEd25519(a.PublicKey, a.Signature, msg)
+	default:
+		return fmt.Errorf("%w: unsupported authenticator %T", ErrInvalidSignature, auth)
+	}
+}
+
+// VerifySignature verifies the signed transaction authenticator is signed for its raw
+// transaction, see `RawTransaction.VerifySignature`.
+// It does not verify the authenticator public key matches the sender account auth key.
+func (t *SignedTransaction) VerifySignature() error {
+	return t.RawTxn.VerifySignature(t.Authenticator)
+}
+
+func verifyEd25519(publicKey Ed25519PublicKey, signature Ed25519Signature, msg []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: invalid ed25519 public key length: %d", ErrInvalidSignature, len(publicKey))
+	}
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), msg, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func verifyMultiEd25519(publicKey MultiEd25519PublicKey, signature MultiEd25519Signature, msg []byte) error {
+	numOfKeys := len(publicKey) / ed25519.PublicKeySize
+	if len(publicKey)%ed25519.PublicKeySize != 1 || numOfKeys == 0 || numOfKeys > multiEd25519MaxNumOfKeys {
+		return fmt.Errorf("%w: invalid multi ed25519 public key length: %d", ErrInvalidSignature, len(publicKey))
+	}
+	threshold := int(publicKey[len(publicKey)-1])
+	if threshold == 0 || threshold > numOfKeys {
+		return fmt.Errorf("%w: invalid multi ed25519 threshold: %d", ErrInvalidSignature, threshold)
+	}
+	if len(signature) < multiEd25519BitmapNumOfBytes ||
+		(len(signature)-multiEd25519BitmapNumOfBytes)%ed25519.SignatureSize != 0 {
+		return fmt.Errorf("%w: invalid multi ed25519 signature length: %d", ErrInvalidSignature, len(signature))
+	}
+	bitmap := signature[len(signature)-multiEd25519BitmapNumOfBytes:]
+	signatures := signature[:len(signature)-multiEd25519BitmapNumOfBytes]
+	numOfSignatures := len(signatures) / ed25519.SignatureSize
+	if numOfSignatures < threshold {
+		return fmt.Errorf("%w: %d signatures less than threshold %d", ErrInvalidSignature, numOfSignatures, threshold)
+	}
+	var verified int
+	for index := 0; index < multiEd25519BitmapNumOfBytes*8; index++ {
+		if bitmap[index/8]&(128>>uint(index%8)) == 0 {
+			continue
+		}
+		if index >= numOfKeys || verified >= numOfSignatures {
+			return fmt.Errorf("%w: invalid multi ed25519 signature bitmap", ErrInvalidSignature)
+		}
+		key := publicKey[index*ed25519.PublicKeySize : (index+1)*ed25519.PublicKeySize]
+		sig := signatures[verified*ed25519.SignatureSize : (verified+1)*ed25519.SignatureSize]
+		if !ed25519.Verify(ed25519.PublicKey(key), msg, sig) {
+			return fmt.Errorf("%w: signature of key %d", ErrInvalidSignature, index)
+		}
+		verified++
+	}
+	if verified != numOfSignatures {
+		return fmt.Errorf("%w: invalid multi ed25519 signature bitmap", ErrInvalidSignature)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+	sign := func(keys *diemkeys.Keys) *diemtypes.SignedTransaction {
+		script := stdlib.EncodePeerToPeerWithMetadataScript(
+			diemtypes.Currency("XUS"), diemkeys.MustGenKeys().AccountAddress(), 100, nil, nil)
+		return diemsigner.Sign(keys, keys.AccountAddress(), 1, script, 1_000_000, 0, "XUS", 1593189628, testnet.ChainID)
+	}
+
+	t.Run("ed25519", func(t *testing.T) {
+		txn := sign(diemkeys.MustGenKeys())
+		assert.NoError(t, txn.VerifySignature())
+	})
+	t.Run("multi ed25519", func(t *testing.T) {
+		txn := sign(diemkeys.MustGenMultiSigKeys())
+		assert.NoError(t, txn.VerifySignature())
+	})
+	t.Run("raw transaction changed", func(t *testing.T) {
+		for _, keys := range []*diemkeys.Keys{diemkeys.MustGenKeys(), diemkeys.MustGenMultiSigKeys()} {
+			txn := sign(keys)
+			txn.RawTxn.SequenceNumber++
+			err := txn.VerifySignature()
+			assert.True(t, errors.Is(err, diemtypes.ErrInvalidSignature), err)
+		}
+	})
+	t.Run("signed by other key", func(t *testing.T) {
+		txn := sign(diemkeys.MustGenKeys())
+		other := sign(diemkeys.MustGenKeys())
+		txn.Authenticator = &diemtypes.TransactionAuthenticator__Ed25519{
+			PublicKey: other.Authenticator.(*diemtypes.TransactionAuthenticator__Ed25519).PublicKey,
+			Signature: txn.Authenticator.(*diemtypes.TransactionAuthenticator__Ed25519).Signature,
+		}
+		assert.True(t, errors.Is(txn.VerifySignature(), diemtypes.ErrInvalidSignature))
+	})
+	t.Run("invalid multi ed25519 signature", func(t *testing.T) {
+		txn := sign(diemkeys.MustGenMultiSigKeys())
+		auth := txn.Authenticator.(*diemtypes.TransactionAuthenticator__MultiEd25519)
+		for _, sig := range [][]byte{nil, {0, 0, 0, 0}, auth.Signature[4:]} {
+			err := txn.RawTxn.VerifySignature(&diemtypes.TransactionAuthenticator__MultiEd25519{
+				PublicKey: auth.PublicKey,
+				Signature: sig,
+			})
+			assert.True(t, errors.Is(err, diemtypes.ErrInvalidSignature), err)
+		}
+	})
+}
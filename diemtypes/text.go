@@ -0,0 +1,321 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Canonical string forms:
+//   - AccountAddress and SubAddress: lower case hex-encoded bytes
+//   - TypeTag: Move type tag string, e.g. "u64", "vector<u8>" and "0x1::XUS::XUS", see
+//     `ParseTypeTag`
+//   - Metadata and TransactionPayload: lower case hex-encoded BCS bytes
+//
+// Breaking change: AccountAddress and SubAddress implement `fmt.Stringer` and
+// `encoding.TextMarshaler`, which changes their wire and formatted output:
+//   - encoding/json encodes them as hex strings instead of arrays of numbers, and decodes only
+//     hex strings; JSON produced by prior versions must be migrated
+//   - "%x" formats the hex-encoded string, i.e. the hex of the hex, use "%s" or `Hex()` for
+//     the hex-encoded bytes
+
+// String returns hex-encoded string of the address
+func (a AccountAddress) String() string {
+	return a.Hex()
+}
+
+// MarshalText implements `encoding.TextMarshaler`; the address is encoded as hex string by
+// encoding/json, see the breaking change note above.
+func (a AccountAddress) MarshalText() ([]byte, error) {
+	return []byte(a.Hex()), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`, accepts hex string with or without
+// "0x" prefix.
+func (a *AccountAddress) UnmarshalText(text []byte) error {
+	ret, err := MakeAccountAddress(trimHexPrefix(string(text)))
+	if err != nil {
+		return fmt.Errorf("invalid account address %q: %v", text, err)
+	}
+	*a = ret
+	return nil
+}
+
+// String returns hex-encoded string of the sub-address
+func (a SubAddress) String() string {
+	return a.Hex()
+}
+
+// MarshalText implements `encoding.TextMarshaler`; the sub-address is encoded as hex string by
+// encoding/json, see the breaking change note above.
+func (a SubAddress) MarshalText() ([]byte, error) {
+	return []byte(a.Hex()), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`, accepts hex string with or without
+// "0x" prefix.
+func (a *SubAddress) UnmarshalText(text []byte) error {
+	ret, err := MakeSubAddress(trimHexPrefix(string(text)))
+	if err != nil {
+		return fmt.Errorf("invalid sub-address %q: %v", text, err)
+	}
+	*a = ret
+	return nil
+}
+
+// String returns Move struct tag string, e.g. "0x1::XUS::XUS"; address is formatted as
+// short hex string with "0x" prefix.
+func (obj *StructTag) String() string {
+	var b strings.Builder
+	b.WriteString(shortAddressHex(obj.Address))
+	b.WriteString("::")
+	b.WriteString(string(obj.Module))
+	b.WriteString("::")
+	b.WriteString(string(obj.Name))
+	if len(obj.TypeParams) > 0 {
+		params := make([]string, len(obj.TypeParams))
+		for i, param := range obj.TypeParams {
+			params[i] = fmt.Sprint(param)
+		}
+		b.WriteString("<")
+		b.WriteString(strings.Join(params, ", "))
+		b.WriteString(">")
+	}
+	return b.String()
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *StructTag) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`, see `ParseStructTag`
+func (obj *StructTag) UnmarshalText(text []byte) error {
+	ret, err := ParseStructTag(string(text))
+	if err != nil {
+		return err
+	}
+	*obj = *ret
+	return nil
+}
+
+// String returns "bool"
+func (*TypeTag__Bool) String() string { return "bool" }
+
+// String returns "u8"
+func (*TypeTag__U8) String() string { return "u8" }
+
+// String returns "u64"
+func (*TypeTag__U64) String() string { return "u64" }
+
+// String returns "u128"
+func (*TypeTag__U128) String() string { return "u128" }
+
+// String returns "address"
+func (*TypeTag__Address) String() string { return "address" }
+
+// String returns "signer"
+func (*TypeTag__Signer) String() string { return "signer" }
+
+// String returns "vector<element type>"
+func (obj *TypeTag__Vector) String() string {
+	return fmt.Sprintf("vector<%s>", obj.Value)
+}
+
+// String returns struct tag string, see `StructTag.String`
+func (obj *TypeTag__Struct) String() string {
+	return obj.Value.String()
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TypeTag__Bool) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TypeTag__U8) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TypeTag__U64) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TypeTag__U128) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TypeTag__Address) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TypeTag__Signer) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TypeTag__Vector) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TypeTag__Struct) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// ParseMetadata decodes hex-encoded BCS bytes of `Metadata`, the string returned by
+// `String` method of metadata variants.
+func ParseMetadata(str string) (Metadata, error) {
+	bytes, err := hex.DecodeString(trimHexPrefix(str))
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata %q: %v", str, err)
+	}
+	return BcsDeserializeMetadata(bytes)
+}
+
+// String returns hex-encoded BCS bytes of the metadata
+func (obj *Metadata__Undefined) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *Metadata__Undefined) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// String returns hex-encoded BCS bytes of the metadata
+func (obj *Metadata__GeneralMetadata) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *Metadata__GeneralMetadata) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// String returns hex-encoded BCS bytes of the metadata
+func (obj *Metadata__TravelRuleMetadata) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *Metadata__TravelRuleMetadata) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// String returns hex-encoded BCS bytes of the metadata
+func (obj *Metadata__UnstructuredBytesMetadata) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *Metadata__UnstructuredBytesMetadata) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// String returns hex-encoded BCS bytes of the metadata
+func (obj *Metadata__RefundMetadata) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *Metadata__RefundMetadata) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// String returns hex-encoded BCS bytes of the metadata
+func (obj *Metadata__CoinTradeMetadata) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *Metadata__CoinTradeMetadata) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// String returns hex-encoded BCS bytes of the metadata
+func (obj *Metadata__PaymentMetadata) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *Metadata__PaymentMetadata) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// ParseTransactionPayload decodes hex-encoded BCS bytes of `TransactionPayload`, the string
+// returned by `String` method of transaction payload variants.
+func ParseTransactionPayload(str string) (TransactionPayload, error) {
+	bytes, err := hex.DecodeString(trimHexPrefix(str))
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction payload %q: %v", str, err)
+	}
+	return BcsDeserializeTransactionPayload(bytes)
+}
+
+// String returns hex-encoded BCS bytes of the transaction payload
+func (obj *TransactionPayload__WriteSet) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TransactionPayload__WriteSet) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// String returns hex-encoded BCS bytes of the transaction payload
+func (obj *TransactionPayload__Script) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TransactionPayload__Script) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// String returns hex-encoded BCS bytes of the transaction payload
+func (obj *TransactionPayload__Module) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TransactionPayload__Module) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+// String returns hex-encoded BCS bytes of the transaction payload
+func (obj *TransactionPayload__ScriptFunction) String() string {
+	return ToHex(obj)
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (obj *TransactionPayload__ScriptFunction) MarshalText() ([]byte, error) {
+	return marshalBCSText(obj)
+}
+
+func marshalBCSText(obj BCSable) ([]byte, error) {
+	bytes, err := obj.BcsSerialize()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(bytes)), nil
+}
+
+func trimHexPrefix(str string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(str, "0x"), "0X")
+}
+
+func shortAddressHex(address AccountAddress) string {
+	ret := strings.TrimLeft(address.Hex(), "0")
+	if ret == "" {
+		ret = "0"
+	}
+	return "0x" + ret
+}
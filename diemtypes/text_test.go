@@ -0,0 +1,142 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountAddressText(t *testing.T) {
+	address := diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	assert.Equal(t, "f72589b71ff4f8d139674a3f7369c69b", address.String())
+	assert.Equal(t, "f72589b71ff4f8d139674a3f7369c69b", fmt.Sprint(address))
+
+	text, err := address.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "f72589b71ff4f8d139674a3f7369c69b", string(text))
+
+	var ret diemtypes.AccountAddress
+	require.NoError(t, ret.UnmarshalText(text))
+	assert.Equal(t, address, ret)
+	require.NoError(t, ret.UnmarshalText([]byte("0xf72589b71ff4f8d139674a3f7369c69b")))
+	assert.Equal(t, address, ret)
+	assert.Error(t, ret.UnmarshalText([]byte("f72589")))
+
+	var config struct {
+		Address diemtypes.AccountAddress `json:"address"`
+	}
+	config.Address = address
+	bytes, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.Equal(t, `{"address":"f72589b71ff4f8d139674a3f7369c69b"}`, string(bytes))
+	config.Address = diemtypes.AccountAddress{}
+	require.NoError(t, json.Unmarshal(bytes, &config))
+	assert.Equal(t, address, config.Address)
+	assert.Error(t, json.Unmarshal([]byte(`{"address":[247,37,137,183,31,244,248,209,57,103,74,63,115,105,198,155]}`), &config),
+		"breaking change: array of numbers encoded by prior versions")
+	assert.Equal(t, "f72589b71ff4f8d139674a3f7369c69b", fmt.Sprintf("%s", address))
+	assert.Equal(t, "f72589b71ff4f8d139674a3f7369c69b", fmt.Sprintf("%x", address[:]))
+}
+
+func TestSubAddressText(t *testing.T) {
+	subAddress, err := diemtypes.MakeSubAddress("cf64428bdeb62af2")
+	require.NoError(t, err)
+	assert.Equal(t, "cf64428bdeb62af2", subAddress.String())
+
+	text, err := subAddress.MarshalText()
+	require.NoError(t, err)
+	var ret diemtypes.SubAddress
+	require.NoError(t, ret.UnmarshalText(text))
+	assert.Equal(t, subAddress, ret)
+	assert.Error(t, ret.UnmarshalText([]byte("xyz")))
+}
+
+func TestTypeTagString(t *testing.T) {
+	cases := []string{
+		"bool",
+		"u8",
+		"u64",
+		"u128",
+		"address",
+		"signer",
+		"vector<vector<u8>>",
+		"0x1::XUS::XUS",
+		"0x1::Diem::Preburn<0x1::XUS::XUS>",
+		"0xa550c18::Pair::Pair<u64, vector<address>>",
+		"0x0::Zero::Zero",
+	}
+	for _, str := range cases {
+		t.Run(str, func(t *testing.T) {
+			tag, err := diemtypes.ParseTypeTag(str)
+			require.NoError(t, err)
+			assert.Equal(t, str, fmt.Sprint(tag))
+
+			text, err := tag.(interface{ MarshalText() ([]byte, error) }).MarshalText()
+			require.NoError(t, err)
+			ret, err := diemtypes.ParseTypeTag(string(text))
+			require.NoError(t, err)
+			assert.Equal(t, tag, ret)
+		})
+	}
+
+	t.Run("struct tag", func(t *testing.T) {
+		tag, err := diemtypes.ParseStructTag("00000000000000000000000000000001::XUS::XUS")
+		require.NoError(t, err)
+		assert.Equal(t, "0x1::XUS::XUS", tag.String())
+
+		var ret diemtypes.StructTag
+		require.NoError(t, ret.UnmarshalText([]byte(tag.String())))
+		assert.Equal(t, *tag, ret)
+		assert.Error(t, ret.UnmarshalText([]byte("u64")))
+	})
+}
+
+func TestMetadataText(t *testing.T) {
+	metadata := &diemtypes.Metadata__GeneralMetadata{
+		Value: &diemtypes.GeneralMetadata__GeneralMetadataVersion0{
+			Value: diemtypes.GeneralMetadataV0{
+				ToSubaddress: &[]byte{1, 2, 3},
+			},
+		},
+	}
+	str := metadata.String()
+	assert.Equal(t, diemtypes.ToHex(metadata), str)
+
+	text, err := metadata.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, str, string(text))
+
+	ret, err := diemtypes.ParseMetadata(str)
+	require.NoError(t, err)
+	assert.Equal(t, metadata, ret)
+
+	_, err = diemtypes.ParseMetadata("xyz")
+	assert.Error(t, err)
+}
+
+func TestTransactionPayloadText(t *testing.T) {
+	amount := diemtypes.TransactionArgument__U64(10)
+	payload := &diemtypes.TransactionPayload__Script{
+		Value: diemtypes.Script{
+			Code:   []byte{1, 2, 3},
+			TyArgs: []diemtypes.TypeTag{diemtypes.Currency("XUS")},
+			Args:   []diemtypes.TransactionArgument{&amount},
+		},
+	}
+	str := payload.String()
+	assert.Equal(t, diemtypes.ToHex(payload), str)
+
+	ret, err := diemtypes.ParseTransactionPayload("0x" + str)
+	require.NoError(t, err)
+	assert.Equal(t, payload, ret)
+
+	_, err = diemtypes.ParseTransactionPayload("00ff")
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ParseTypeTag parses Move type tag canonical string, e.g. "0x1::XUS::XUS", "u64",
+// "vector<u8>" and "0x1::Diem::Diem<0x1::XUS::XUS>".
+// Struct address is hex-encoded with or without "0x" prefix, and short address is left padded
+// with zeros, e.g. "0x1".
+func ParseTypeTag(str string) (TypeTag, error) {
+	p := &typeTagParser{input: str, tokens: tokenizeTypeTag(str)}
+	ret, err := p.parseTypeTag()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("invalid type tag %q: unexpected %q", str, p.tokens[p.pos])
+	}
+	return ret, nil
+}
+
+// MustParseTypeTag panics if parse given type tag string failed
+func MustParseTypeTag(str string) TypeTag {
+	ret, err := ParseTypeTag(str)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// ParseStructTag parses Move struct tag canonical string, e.g. "0x1::XUS::XUS"
+func ParseStructTag(str string) (*StructTag, error) {
+	tag, err := ParseTypeTag(str)
+	if err != nil {
+		return nil, err
+	}
+	st, ok := tag.(*TypeTag__Struct)
+	if !ok {
+		return nil, fmt.Errorf("invalid struct tag %q: not a struct", str)
+	}
+	return &st.Value, nil
+}
+
+type typeTagParser struct {
+	input  string
+	tokens []string
+	pos    int
+}
+
+func (p *typeTagParser) parseTypeTag() (TypeTag, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case "bool":
+		return &TypeTag__Bool{}, nil
+	case "u8":
+		return &TypeTag__U8{}, nil
+	case "u64":
+		return &TypeTag__U64{}, nil
+	case "u128":
+		return &TypeTag__U128{}, nil
+	case "address":
+		return &TypeTag__Address{}, nil
+	case "signer":
+		return &TypeTag__Signer{}, nil
+	case "vector":
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseTypeTag()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+		return &TypeTag__Vector{Value: elem}, nil
+	}
+	return p.parseStructTag(token)
+}
+
+func (p *typeTagParser) parseStructTag(addressToken string) (TypeTag, error) {
+	address, err := parseShortAccountAddress(addressToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type tag %q: %v", p.input, err)
+	}
+	module, err := p.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	params := []TypeTag{}
+	if p.peek() == "<" {
+		p.pos++
+		for {
+			param, err := p.parseTypeTag()
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, param)
+			token, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if token == ">" {
+				break
+			}
+			if token != "," {
+				return nil, fmt.Errorf("invalid type tag %q: expected \",\" or \">\", got %q", p.input, token)
+			}
+		}
+	}
+	return &TypeTag__Struct{Value: StructTag{
+		Address:    address,
+		Module:     module,
+		Name:       name,
+		TypeParams: params,
+	}}, nil
+}
+
+func (p *typeTagParser) parseIdentifier() (Identifier, error) {
+	if err := p.expect("::"); err != nil {
+		return "", err
+	}
+	token, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if !isIdentifier(token) {
+		return "", fmt.Errorf("invalid type tag %q: invalid identifier %q", p.input, token)
+	}
+	return Identifier(token), nil
+}
+
+func (p *typeTagParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("invalid type tag %q: unexpected end", p.input)
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *typeTagParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *typeTagParser) expect(token string) error {
+	got, err := p.next()
+	if err != nil {
+		return err
+	}
+	if got != token {
+		return fmt.Errorf("invalid type tag %q: expected %q, got %q", p.input, token, got)
+	}
+	return nil
+}
+
+// tokenizeTypeTag splits type tag string into "<", ">", ",", "::" and words, whitespaces are
+// ignored.
+func tokenizeTypeTag(str string) []string {
+	var tokens []string
+	for i := 0; i < len(str); {
+		c := str[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '<' || c == '>' || c == ',':
+			tokens = append(tokens, str[i:i+1])
+			i++
+		case strings.HasPrefix(str[i:], "::"):
+			tokens = append(tokens, "::")
+			i += 2
+		default:
+			j := i + 1
+			for j < len(str) && isIdentifierChar(str[j]) && isIdentifierChar(c) {
+				j++
+			}
+			tokens = append(tokens, str[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isIdentifier(token string) bool {
+	if token == "" || token[0] >= '0' && token[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(token); i++ {
+		if !isIdentifierChar(token[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseShortAccountAddress parses hex-encoded account address with or without "0x" prefix,
+// short address is left padded with zeros.
+func parseShortAccountAddress(str string) (AccountAddress, error) {
+	hexStr := trimHexPrefix(str)
+	if hexStr == "" || len(hexStr) > AccountAddressLength*2 {
+		return AccountAddress{}, fmt.Errorf("invalid account address %q", str)
+	}
+	if len(hexStr)%2 == 1 {
+		hexStr = "0" + hexStr
+	}
+	bytes, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return AccountAddress{}, fmt.Errorf("invalid account address %q: %v", str, err)
+	}
+	var ret AccountAddress
+	copy(ret[AccountAddressLength-len(bytes):], bytes)
+	return ret, nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTypeTag(t *testing.T) {
+	cases := []struct {
+		str      string
+		expected diemtypes.TypeTag
+	}{
+		{"bool", &diemtypes.TypeTag__Bool{}},
+		{"u8", &diemtypes.TypeTag__U8{}},
+		{"u64", &diemtypes.TypeTag__U64{}},
+		{"u128", &diemtypes.TypeTag__U128{}},
+		{"address", &diemtypes.TypeTag__Address{}},
+		{"signer", &diemtypes.TypeTag__Signer{}},
+		{"vector<vector<u8>>", &diemtypes.TypeTag__Vector{Value: &diemtypes.TypeTag__Vector{Value: &diemtypes.TypeTag__U8{}}}},
+		{"0x1::XUS::XUS", diemtypes.Currency("XUS")},
+		{"00000000000000000000000000000001::XDX::XDX", diemtypes.Currency("XDX")},
+		{
+			"0x1::Diem::Preburn< 0x1::XUS::XUS >",
+			&diemtypes.TypeTag__Struct{Value: diemtypes.StructTag{
+				Address:    diemtypes.MustMakeAccountAddress("00000000000000000000000000000001"),
+				Module:     "Diem",
+				Name:       "Preburn",
+				TypeParams: []diemtypes.TypeTag{diemtypes.Currency("XUS")},
+			}},
+		},
+		{
+			"0xa550c18::Pair::Pair<u64, vector<address>>",
+			&diemtypes.TypeTag__Struct{Value: diemtypes.StructTag{
+				Address: diemtypes.MustMakeAccountAddress("0000000000000000000000000a550c18"),
+				Module:  "Pair",
+				Name:    "Pair",
+				TypeParams: []diemtypes.TypeTag{
+					&diemtypes.TypeTag__U64{},
+					&diemtypes.TypeTag__Vector{Value: &diemtypes.TypeTag__Address{}},
+				},
+			}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.str, func(t *testing.T) {
+			tag, err := diemtypes.ParseTypeTag(tc.str)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tag)
+		})
+	}
+}
+
+func TestParseTypeTagErrors(t *testing.T) {
+	for _, str := range []string{
+		"",
+		"u32",
+		"vector<u8",
+		"vector<u8>>",
+		"0x1::XUS",
+		"0x1::XUS::XUS<",
+		"0x1::XUS::XUS<u8 u8>",
+		"0xzz::XUS::XUS",
+		"0x1::1XUS::XUS",
+		"0x000000000000000000000000000000001::XUS::XUS",
+	} {
+		t.Run(str, func(t *testing.T) {
+			_, err := diemtypes.ParseTypeTag(str)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseStructTag(t *testing.T) {
+	tag, err := diemtypes.ParseStructTag("0x1::XUS::XUS")
+	require.NoError(t, err)
+	assert.Equal(t, diemtypes.Identifier("XUS"), tag.Module)
+
+	_, err = diemtypes.ParseStructTag("u8")
+	assert.EqualError(t, err, `invalid struct tag "u8": not a struct`)
+
+	assert.Panics(t, func() { diemtypes.MustParseTypeTag("u32") })
+}
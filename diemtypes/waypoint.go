@@ -0,0 +1,71 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WaypointHashPrefix is the Diem hashing prefix type name of waypoint value
+const WaypointHashPrefix = "Ledger2WaypointConverter"
+
+// Waypoint is a known-good ledger state: a ledger version and hash of the ledger info at the
+// version. It is used for bootstrapping verification of ledger infos, and formatted as
+// "version:hash".
+type Waypoint struct {
+	Version uint64
+	Value   HashValue
+}
+
+// ParseWaypoint parses "version:hash" format waypoint string, hash is hex-encoded 32 bytes
+func ParseWaypoint(str string) (*Waypoint, error) {
+	parts := strings.Split(str, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid waypoint %q: expected format version:hash", str)
+	}
+	version, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid waypoint %q version: %v", str, err)
+	}
+	value, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid waypoint %q hash: %v", str, err)
+	}
+	if len(value) != 32 {
+		return nil, fmt.Errorf("invalid waypoint %q hash bytes length: %d", str, len(value))
+	}
+	return &Waypoint{Version: version, Value: value}, nil
+}
+
+// MustParseWaypoint panics if parse given waypoint string failed
+func MustParseWaypoint(str string) *Waypoint {
+	ret, err := ParseWaypoint(str)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// NewWaypoint creates `Waypoint` with given version and BCS bytes of the ledger info waypoint
+// converter: epoch, executed state root hash, version, timestamp and next epoch state.
+func NewWaypoint(version uint64, converterBCS []byte) *Waypoint {
+	return &Waypoint{
+		Version: version,
+		Value:   Hash(HashPrefix(WaypointHashPrefix), converterBCS),
+	}
+}
+
+// String returns "version:hash" format string
+func (w *Waypoint) String() string {
+	return fmt.Sprintf("%d:%s", w.Version, hex.EncodeToString(w.Value))
+}
+
+// Equal returns true if the given waypoint has same version and hash
+func (w *Waypoint) Equal(other *Waypoint) bool {
+	return other != nil && w.Version == other.Version && bytes.Equal(w.Value, other.Value)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diemtypes_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaypoint(t *testing.T) {
+	str := "0:4d3ca8b7b4bd24e8a98b1c5e2f82c85c12a0cf1ad6d3f0f6ae9a9c7bb2c3bd5a"
+
+	t.Run("parse and format", func(t *testing.T) {
+		w, err := diemtypes.ParseWaypoint(str)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), w.Version)
+		assert.Len(t, w.Value, 32)
+		assert.Equal(t, str, w.String())
+		assert.True(t, w.Equal(diemtypes.MustParseWaypoint(str)))
+		assert.False(t, w.Equal(diemtypes.NewWaypoint(0, []byte("ledger info"))))
+		assert.False(t, w.Equal(nil))
+	})
+
+	t.Run("new waypoint", func(t *testing.T) {
+		w := diemtypes.NewWaypoint(10, []byte("ledger info"))
+		assert.Equal(t, uint64(10), w.Version)
+		assert.Equal(t, diemtypes.Hash(diemtypes.HashPrefix(diemtypes.WaypointHashPrefix), []byte("ledger info")), []byte(w.Value))
+		parsed, err := diemtypes.ParseWaypoint(w.String())
+		require.NoError(t, err)
+		assert.True(t, w.Equal(parsed))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, s := range []string{"", "0", "x:00", "0:xx", "0:00", "1:2:3"} {
+			_, err := diemtypes.ParseWaypoint(s)
+			assert.Error(t, err, s)
+		}
+		assert.Panics(t, func() { diemtypes.MustParseWaypoint("0") })
+	})
+}
@@ -0,0 +1,18 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides end-to-end test harness and scenarios running against testnet or a devnet.
+//
+// The scenarios are exposed as reusable Go test helpers, so that applications can run the same
+// scenarios against their own deployments:
+//
+//	func TestE2E(t *testing.T) {
+//		e2e.RunAll(t, e2e.Setup(t))
+//	}
+//
+// Tests are skipped unless `DIEM_E2E` environment variable is set. By default scenarios target
+// testnet; set `DIEM_E2E_DEVNET=1` to boot a devnet of a validator and faucet by docker-compose
+// (`make e2e-devnet`), which is torn down when the test finishes; or set
+// `DIEM_E2E_JSONRPC_URL`, `DIEM_E2E_FAUCET_URL` and `DIEM_E2E_CHAIN_ID` to target a running
+// devnet.
+package e2e
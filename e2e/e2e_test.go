@@ -0,0 +1,28 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package e2e_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/e2e"
+)
+
+func TestE2E(t *testing.T) {
+	e2e.RunAll(t, e2e.Setup(t))
+}
+
+func TestSetupDevnetWithoutDocker(t *testing.T) {
+	t.Setenv(e2e.EnableEnvVar, "1")
+	t.Setenv(e2e.DevnetEnvVar, "1")
+	t.Setenv("PATH", "")
+	var sub *testing.T
+	t.Run("devnet", func(t *testing.T) {
+		sub = t
+		e2e.Setup(t)
+	})
+	if !sub.Skipped() {
+		t.Fatal("expected devnet test skipped without docker-compose")
+	}
+}
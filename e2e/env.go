@@ -0,0 +1,150 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/testsuite"
+)
+
+// Environment variables for configuring `Env`
+const (
+	EnableEnvVar     = "DIEM_E2E"
+	DevnetEnvVar     = "DIEM_E2E_DEVNET"
+	JSONRPCURLEnvVar = "DIEM_E2E_JSONRPC_URL"
+	FaucetURLEnvVar  = "DIEM_E2E_FAUCET_URL"
+	ChainIDEnvVar    = "DIEM_E2E_CHAIN_ID"
+)
+
+const (
+	// Currency is the currency used by scenarios
+	Currency = "XUS"
+	// TravelRuleThreshold is the on-chain dual attestation limit in micro units
+	TravelRuleThreshold uint64 = 1_000_000_000
+	// DefaultTimeout is default timeout of waiting for transaction executed
+	DefaultTimeout = 30 * time.Second
+)
+
+// Env is the target network of scenarios
+type Env struct {
+	ChainID byte
+	Client  diemclient.Client
+	Faucet  *testnet.Faucet
+	Timeout time.Duration
+}
+
+// Testnet returns `Env` targets testnet
+func Testnet() *Env {
+	return &Env{
+		ChainID: testnet.ChainID,
+		Client:  testnet.Client,
+		Faucet:  testnet.DefaultFaucet,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// NewEnv creates `Env` targets given JSON-RPC and faucet service
+func NewEnv(chainID byte, jsonRPCURL, faucetURL string) *Env {
+	faucet := testnet.NewFaucet(testnet.Config{JSONRPCURL: jsonRPCURL, FaucetURL: faucetURL, ChainID: chainID})
+	return &Env{
+		ChainID: chainID,
+		Client:  faucet.Client,
+		Faucet:  faucet,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// Devnet boots a devnet of a validator and faucet by `testsuite.StartLocalNetwork` with the
+// given docker-compose file, or `testsuite.ComposeFile` if the file is empty, and returns `Env`
+// targets it. The devnet is torn down when the test finishes.
+func Devnet(t testing.TB, composeFile string) *Env {
+	t.Helper()
+	network := testsuite.StartLocalNetwork(t, composeFile)
+	return &Env{
+		ChainID: network.ChainID,
+		Client:  network.Client,
+		Faucet:  network.Faucet,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// FromEnviron creates `Env` by environment variables, returns `Testnet()` if
+// `DIEM_E2E_JSONRPC_URL` is not set.
+func FromEnviron() (*Env, error) {
+	url := os.Getenv(JSONRPCURLEnvVar)
+	if url == "" {
+		return Testnet(), nil
+	}
+	faucetURL := os.Getenv(FaucetURLEnvVar)
+	if faucetURL == "" {
+		return nil, fmt.Errorf("%s is required when %s is set", FaucetURLEnvVar, JSONRPCURLEnvVar)
+	}
+	chainID, err := strconv.ParseUint(os.Getenv(ChainIDEnvVar), 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", ChainIDEnvVar, err)
+	}
+	return NewEnv(byte(chainID), url, faucetURL), nil
+}
+
+// Setup skips the test unless `DIEM_E2E` is set. It returns `Env` of a devnet booted by
+// `Devnet` with the compose file of `DIEM_COMPOSE_FILE` if `DIEM_E2E_DEVNET` is set, otherwise
+// returns `Env` created by `FromEnviron`.
+func Setup(t testing.TB) *Env {
+	t.Helper()
+	if os.Getenv(EnableEnvVar) == "" {
+		t.Skipf("set %s to run end-to-end tests", EnableEnvVar)
+	}
+	if os.Getenv(DevnetEnvVar) != "" {
+		return Devnet(t, os.Getenv(testsuite.ComposeFileEnvVar))
+	}
+	env, err := FromEnviron()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+// GenAccount creates an account by faucet with given amount of `Currency`.
+// Faucet creates a ParentVASP account for a new authentication key.
+func (e *Env) GenAccount(t testing.TB, amount uint64) *diemkeys.Keys {
+	keys := diemkeys.MustGenKeys()
+	e.Mint(t, keys, amount)
+	return keys
+}
+
+// Mint mints `Currency` to given account
+func (e *Env) Mint(t testing.TB, keys *diemkeys.Keys, amount uint64) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatal(r)
+		}
+	}()
+	e.Faucet.MustMint(keys.AuthKey().Hex(), amount, Currency)
+}
+
+// Balance returns `Currency` balance of given account
+func (e *Env) Balance(t testing.TB, address diemtypes.AccountAddress) uint64 {
+	account, err := e.Client.GetAccount(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account == nil {
+		t.Fatalf("account %s not found", address.Hex())
+	}
+	for _, balance := range account.Balances {
+		if balance.Currency == Currency {
+			return balance.Amount
+		}
+	}
+	return 0
+}
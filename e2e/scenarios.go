@@ -0,0 +1,217 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package e2e
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/diem/client-sdk-go/wallet"
+	"github.com/diem/client-sdk-go/watcher"
+)
+
+// Scenario is a named end-to-end scenario
+type Scenario struct {
+	Name string
+	Run  func(t *testing.T, env *Env)
+}
+
+// Scenarios are all scenarios run by `RunAll`
+var Scenarios = []Scenario{
+	{"p2p under travel rule threshold", P2PUnderThreshold},
+	{"p2p over travel rule threshold", P2POverThreshold},
+	{"refund", Refund},
+	{"key rotation", KeyRotation},
+	{"deposit detection", DepositDetection},
+}
+
+// RunAll runs all `Scenarios` as sub-tests
+func RunAll(t *testing.T, env *Env) {
+	for _, s := range Scenarios {
+		t.Run(s.Name, func(t *testing.T) {
+			s.Run(t, env)
+		})
+	}
+}
+
+// P2PUnderThreshold transfers between custodial accounts with from / to sub-addresses
+func P2PUnderThreshold(t *testing.T, env *Env) {
+	sender := env.NewChildVASP(t, env.NewParentVASP(t, "http://sender.vasp", 2_000_000), 1_000_000)
+	receiver := env.NewChildVASP(t, env.NewParentVASP(t, "http://receiver.vasp", 2_000_000), 0)
+	amount := uint64(10_000)
+
+	env.SubmitAndWait(t, sender, stdlib.EncodePeerToPeerWithMetadataScript(
+		diemtypes.Currency(Currency),
+		receiver.AccountAddress(),
+		amount,
+		txnmetadata.NewGeneralMetadataWithFromToSubAddresses(
+			diemtypes.MustGenSubAddress(), diemtypes.MustGenSubAddress()),
+		nil,
+	))
+	assertBalance(t, env, receiver, amount)
+}
+
+// P2POverThreshold transfers between custodial accounts with travel rule metadata and
+// receiver's compliance key signature.
+func P2POverThreshold(t *testing.T, env *Env) {
+	amount := TravelRuleThreshold + 1
+	sender := env.GenAccount(t, amount+1_000_000)
+	receiverVASP := env.NewParentVASP(t, "http://receiver.vasp", 1_000_000)
+	receiver := env.NewChildVASP(t, receiverVASP, 0)
+
+	referenceID, err := offchain.NewReferenceID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, sigMsg := txnmetadata.NewTravelRuleMetadata(
+		referenceID.String(), sender.AccountAddress(), amount)
+	env.SubmitAndWait(t, sender, stdlib.EncodePeerToPeerWithMetadataScript(
+		diemtypes.Currency(Currency),
+		receiver.AccountAddress(),
+		amount,
+		metadata,
+		ed25519.Sign(receiverVASP.ComplianceKey, sigMsg),
+	))
+	assertBalance(t, env, receiver, amount)
+}
+
+// Refund refunds a payment received on an invalid sub-address
+func Refund(t *testing.T, env *Env) {
+	sender := env.NewChildVASP(t, env.NewParentVASP(t, "http://sender.vasp", 2_000_000), 1_000_000)
+	receiver := env.NewChildVASP(t, env.NewParentVASP(t, "http://receiver.vasp", 2_000_000), 100_000)
+	amount := uint64(10_000)
+
+	txn := env.SubmitAndWait(t, sender, stdlib.EncodePeerToPeerWithMetadataScript(
+		diemtypes.Currency(Currency),
+		receiver.AccountAddress(),
+		amount,
+		txnmetadata.NewGeneralMetadataToSubAddress(diemtypes.MustGenSubAddress()),
+		nil,
+	))
+	before := env.Balance(t, sender.AccountAddress())
+	env.SubmitAndWait(t, receiver, stdlib.EncodePeerToPeerWithMetadataScript(
+		diemtypes.Currency(Currency),
+		sender.AccountAddress(),
+		amount,
+		txnmetadata.NewRefundMetadata(txn.Version, &diemtypes.RefundReason__InvalidSubaddress{}),
+		nil,
+	))
+	if after := env.Balance(t, sender.AccountAddress()); after != before+amount {
+		t.Fatalf("expected sender balance %d after refund, got %d", before+amount, after)
+	}
+}
+
+// KeyRotation runs `wallet.KeyRotationDrill` and transfers with the rotated key
+func KeyRotation(t *testing.T, env *Env) {
+	keys := env.GenAccount(t, 1_000_000)
+	backend := wallet.NewMemoryKeyBackend()
+	drill := &wallet.KeyRotationDrill{
+		Client:       env.Client,
+		Keys:         backend,
+		Address:      keys.AccountAddress(),
+		CurrentKeyID: backend.Import(keys),
+		ChainID:      env.ChainID,
+		Timeout:      env.Timeout,
+	}
+	report, err := drill.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := backend.Signer(report.NewKeyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated := diemkeys.NewKeysFromPublicAndPrivateKeys(signer.PublicKey(), &signerKey{t, signer})
+	receiver := env.GenAccount(t, 0)
+	env.SubmitAndWaitAs(t, keys.AccountAddress(), rotated, stdlib.EncodePeerToPeerWithMetadataScript(
+		diemtypes.Currency(Currency), receiver.AccountAddress(), 1_000, nil, nil))
+}
+
+// signerKey is `diemkeys.PrivateKey` signs by `diemsigner.Signer`
+type signerKey struct {
+	t      testing.TB
+	signer diemsigner.Signer
+}
+
+func (k *signerKey) Sign(msg []byte) []byte {
+	signature, err := k.signer.SignMessage(context.Background(), msg)
+	if err != nil {
+		k.t.Fatal(err)
+	}
+	return signature
+}
+
+// DepositDetection detects deposit by `watcher.BalanceWatcher` and routes it by
+// `wallet.DepositRouter`.
+func DepositDetection(t *testing.T, env *Env) {
+	sender := env.GenAccount(t, 1_000_000)
+	receiver := env.NewChildVASP(t, env.NewParentVASP(t, "http://receiver.vasp", 2_000_000), 0)
+	subAddresses := wallet.NewSubAddresses(wallet.SubAddressPolicy{SingleUse: true})
+	router := wallet.NewDepositRouter(subAddresses)
+	record, err := subAddresses.Allocate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := watcher.NewBalanceWatcher(env.Client, receiver.AccountAddress())
+	if _, err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	amount := uint64(10_000)
+	txn := env.SubmitAndWait(t, sender, stdlib.EncodePeerToPeerWithMetadataScript(
+		diemtypes.Currency(Currency),
+		receiver.AccountAddress(),
+		amount,
+		txnmetadata.NewGeneralMetadataToSubAddress(record.SubAddress),
+		nil,
+	))
+	changes, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].After-changes[0].Before != amount {
+		t.Fatalf("expected one balance change of %d, got %+v", amount, changes)
+	}
+
+	event := txnmetadata.FindRefundReferenceEventFromTransaction(txn, receiver.AccountAddress())
+	metadata, err := txnmetadata.DeserializeMetadata(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gm, ok := metadata.(*diemtypes.Metadata__GeneralMetadata)
+	if !ok {
+		t.Fatalf("expected general metadata, got %T", metadata)
+	}
+	gmv0 := gm.Value.(*diemtypes.GeneralMetadata__GeneralMetadataVersion0)
+	subAddress, err := diemtypes.MakeSubAddressFromBytes(*gmv0.Value.ToSubaddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deposit := wallet.Deposit{
+		Version:    txn.Version,
+		Sender:     sender.AccountAddress(),
+		SubAddress: subAddress,
+		Amount:     diemamount.New(diemamount.XUS, amount),
+	}
+	if outcome, err := router.Route(deposit); err != nil || outcome != wallet.DepositCredited {
+		t.Fatalf("expected deposit credited, got %s, %v", outcome, err)
+	}
+	if outcome, err := router.Route(deposit); err != nil || outcome != wallet.DepositInReview {
+		t.Fatalf("expected deposit to used sub-address in review, got %s, %v", outcome, err)
+	}
+}
+
+func assertBalance(t *testing.T, env *Env, account *diemkeys.Keys, expected uint64) {
+	if balance := env.Balance(t, account.AccountAddress()); balance != expected {
+		t.Fatalf("expected balance %d, got %d", expected, balance)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package e2e
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnbuilder"
+)
+
+// VASP is a provisioned ParentVASP account with compliance key
+type VASP struct {
+	Parent        *diemkeys.Keys
+	BaseURL       string
+	ComplianceKey ed25519.PrivateKey
+}
+
+// NewParentVASP creates ParentVASP account by faucet, and rotates its dual attestation info
+// to given base url and a generated compliance key.
+func (e *Env) NewParentVASP(t testing.TB, baseURL string, amount uint64) *VASP {
+	vasp := &VASP{Parent: e.GenAccount(t, amount), BaseURL: baseURL}
+	vasp.RotateComplianceKey(t, e)
+	return vasp
+}
+
+// RotateComplianceKey generates new compliance key and rotates it on-chain
+func (v *VASP) RotateComplianceKey(t testing.TB, e *Env) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SubmitAndWait(t, v.Parent, stdlib.EncodeRotateDualAttestationInfoScript(
+		[]byte(v.BaseURL), []byte(public)))
+	v.ComplianceKey = private
+}
+
+// NewChildVASP creates ChildVASP account of the VASP with given initial balance
+func (e *Env) NewChildVASP(t testing.TB, v *VASP, amount uint64) *diemkeys.Keys {
+	child := diemkeys.MustGenKeys()
+	e.SubmitAndWait(t, v.Parent, stdlib.EncodeCreateChildVaspAccountScript(
+		diemtypes.Currency(Currency),
+		child.AccountAddress(),
+		child.AuthKey().Prefix(),
+		false,
+		amount,
+	))
+	return child
+}
+
+// SubmitAndWait signs the script by sender keys, submits and waits for the transaction
+// executed; it fails the test if the transaction is not executed successfully.
+func (e *Env) SubmitAndWait(t testing.TB, sender *diemkeys.Keys, script diemtypes.Script) *diemclient.Transaction {
+	return e.SubmitAndWaitAs(t, sender.AccountAddress(), sender, script)
+}
+
+// SubmitAndWaitAs is `SubmitAndWait` for an account whose authentication key is rotated,
+// hence its address can't be derived from the keys.
+func (e *Env) SubmitAndWaitAs(t testing.TB, address diemtypes.AccountAddress, sender *diemkeys.Keys, script diemtypes.Script) *diemclient.Transaction {
+	executed, err := txnbuilder.New(sender).
+		Sender(address).
+		Script(script).
+		GasCurrency(Currency).
+		ExpireIn(e.Timeout).
+		WaitTimeout(e.Timeout).
+		ChainID(e.ChainID).
+		SignSubmitAndWait(e.Client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return executed
+}
@@ -0,0 +1,285 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// Event data types, value of `diemclient.EventData.Type`
+const (
+	BurnEventType                    = "burn"
+	CancelBurnEventType              = "cancelburn"
+	MintEventType                    = "mint"
+	PreburnEventType                 = "preburn"
+	ReceivedPaymentEventType         = "receivedpayment"
+	SentPaymentEventType             = "sentpayment"
+	ReceivedMintEventType            = "receivedmint"
+	ToXDXExchangeRateUpdateEventType = "to_xdx_exchange_rate_update"
+	NewEpochEventType                = "newepoch"
+	NewBlockEventType                = "newblock"
+	ComplianceKeyRotationEventType   = "compliancekeyrotation"
+	BaseURLRotationEventType         = "baseurlrotation"
+	CreateAccountEventType           = "createaccount"
+	AdminTransactionEventType        = "admintransaction"
+	UnknownEventType                 = "unknown"
+)
+
+// Data is typed event data decoded by `DecodeEventData`
+type Data interface {
+	EventType() string
+}
+
+// BurnEvent is "burn" event data
+type BurnEvent struct {
+	Amount         diemamount.Amount
+	PreburnAddress diemtypes.AccountAddress
+}
+
+// CancelBurnEvent is "cancelburn" event data
+type CancelBurnEvent struct {
+	Amount         diemamount.Amount
+	PreburnAddress diemtypes.AccountAddress
+}
+
+// MintEvent is "mint" event data
+type MintEvent struct {
+	Amount diemamount.Amount
+}
+
+// PreburnEvent is "preburn" event data
+type PreburnEvent struct {
+	Amount         diemamount.Amount
+	PreburnAddress diemtypes.AccountAddress
+}
+
+// ReceivedPaymentEvent is "receivedpayment" event data
+type ReceivedPaymentEvent struct {
+	Amount   diemamount.Amount
+	Sender   diemtypes.AccountAddress
+	Receiver diemtypes.AccountAddress
+	// Metadata is BCS bytes of `diemtypes.Metadata`, nil if there is no metadata
+	Metadata []byte
+}
+
+// SentPaymentEvent is "sentpayment" event data
+type SentPaymentEvent struct {
+	Amount   diemamount.Amount
+	Sender   diemtypes.AccountAddress
+	Receiver diemtypes.AccountAddress
+	// Metadata is BCS bytes of `diemtypes.Metadata`, nil if there is no metadata
+	Metadata []byte
+}
+
+// ReceivedMintEvent is "receivedmint" event data
+type ReceivedMintEvent struct {
+	Amount             diemamount.Amount
+	DestinationAddress diemtypes.AccountAddress
+}
+
+// ToXDXExchangeRateUpdateEvent is "to_xdx_exchange_rate_update" event data
+type ToXDXExchangeRateUpdateEvent struct {
+	Currency             diemamount.Currency
+	NewToXDXExchangeRate float32
+}
+
+// NewEpochEvent is "newepoch" event data
+type NewEpochEvent struct {
+	Epoch uint64
+}
+
+// NewBlockEvent is "newblock" event data
+type NewBlockEvent struct {
+	Round        uint64
+	Proposer     diemtypes.AccountAddress
+	ProposedTime uint64
+}
+
+// ComplianceKeyRotationEvent is "compliancekeyrotation" event data
+type ComplianceKeyRotationEvent struct {
+	NewCompliancePublicKey ed25519.PublicKey
+	TimeRotatedSeconds     uint64
+}
+
+// BaseURLRotationEvent is "baseurlrotation" event data
+type BaseURLRotationEvent struct {
+	NewBaseURL         string
+	TimeRotatedSeconds uint64
+}
+
+// CreateAccountEvent is "createaccount" event data
+type CreateAccountEvent struct {
+	CreatedAddress diemtypes.AccountAddress
+	RoleID         uint64
+}
+
+// AdminTransactionEvent is "admintransaction" event data
+type AdminTransactionEvent struct {
+	CommittedTimestampSecs uint64
+}
+
+// UnknownEvent is event data of "unknown" type or a type not supported by this package
+type UnknownEvent struct {
+	Type string
+	// Bytes is BCS bytes of the event data
+	Bytes []byte
+}
+
+// EventType implements `Data`
+func (*BurnEvent) EventType() string { return BurnEventType }
+
+// EventType implements `Data`
+func (*CancelBurnEvent) EventType() string { return CancelBurnEventType }
+
+// EventType implements `Data`
+func (*MintEvent) EventType() string { return MintEventType }
+
+// EventType implements `Data`
+func (*PreburnEvent) EventType() string { return PreburnEventType }
+
+// EventType implements `Data`
+func (*ReceivedPaymentEvent) EventType() string { return ReceivedPaymentEventType }
+
+// EventType implements `Data`
+func (*SentPaymentEvent) EventType() string { return SentPaymentEventType }
+
+// EventType implements `Data`
+func (*ReceivedMintEvent) EventType() string { return ReceivedMintEventType }
+
+// EventType implements `Data`
+func (*ToXDXExchangeRateUpdateEvent) EventType() string { return ToXDXExchangeRateUpdateEventType }
+
+// EventType implements `Data`
+func (*NewEpochEvent) EventType() string { return NewEpochEventType }
+
+// EventType implements `Data`
+func (*NewBlockEvent) EventType() string { return NewBlockEventType }
+
+// EventType implements `Data`
+func (*ComplianceKeyRotationEvent) EventType() string { return ComplianceKeyRotationEventType }
+
+// EventType implements `Data`
+func (*BaseURLRotationEvent) EventType() string { return BaseURLRotationEventType }
+
+// EventType implements `Data`
+func (*CreateAccountEvent) EventType() string { return CreateAccountEventType }
+
+// EventType implements `Data`
+func (*AdminTransactionEvent) EventType() string { return AdminTransactionEventType }
+
+// EventType implements `Data`
+func (e *UnknownEvent) EventType() string { return e.Type }
+
+// DecodeEventData decodes event data into the typed struct of its type, e.g.
+// `*ReceivedPaymentEvent` for "receivedpayment" event. Event types not supported by this
+// package are decoded as `*UnknownEvent`.
+func DecodeEventData(event *diemclient.Event) (Data, error) {
+	if event == nil || event.Data == nil {
+		return nil, errors.New("event data is nil")
+	}
+	d := &decoder{data: event.Data}
+	var ret Data
+	switch event.Data.Type {
+	case BurnEventType:
+		ret = &BurnEvent{Amount: d.amount(), PreburnAddress: d.address("preburn_address", event.Data.PreburnAddress)}
+	case CancelBurnEventType:
+		ret = &CancelBurnEvent{Amount: d.amount(), PreburnAddress: d.address("preburn_address", event.Data.PreburnAddress)}
+	case MintEventType:
+		ret = &MintEvent{Amount: d.amount()}
+	case PreburnEventType:
+		ret = &PreburnEvent{Amount: d.amount(), PreburnAddress: d.address("preburn_address", event.Data.PreburnAddress)}
+	case ReceivedPaymentEventType:
+		ret = &ReceivedPaymentEvent{
+			Amount:   d.amount(),
+			Sender:   d.address("sender", event.Data.Sender),
+			Receiver: d.address("receiver", event.Data.Receiver),
+			Metadata: d.bytes("metadata", event.Data.Metadata),
+		}
+	case SentPaymentEventType:
+		ret = &SentPaymentEvent{
+			Amount:   d.amount(),
+			Sender:   d.address("sender", event.Data.Sender),
+			Receiver: d.address("receiver", event.Data.Receiver),
+			Metadata: d.bytes("metadata", event.Data.Metadata),
+		}
+	case ReceivedMintEventType:
+		ret = &ReceivedMintEvent{Amount: d.amount(), DestinationAddress: d.address("destination_address", event.Data.DestinationAddress)}
+	case ToXDXExchangeRateUpdateEventType:
+		ret = &ToXDXExchangeRateUpdateEvent{
+			Currency:             diemamount.Currency(event.Data.CurrencyCode),
+			NewToXDXExchangeRate: event.Data.NewToXdxExchangeRate,
+		}
+	case NewEpochEventType:
+		ret = &NewEpochEvent{Epoch: event.Data.Epoch}
+	case NewBlockEventType:
+		ret = &NewBlockEvent{
+			Round:        event.Data.Round,
+			Proposer:     d.address("proposer", event.Data.Proposer),
+			ProposedTime: event.Data.ProposedTime,
+		}
+	case ComplianceKeyRotationEventType:
+		ret = &ComplianceKeyRotationEvent{
+			NewCompliancePublicKey: ed25519.PublicKey(d.bytes("new_compliance_public_key", event.Data.NewCompliancePublicKey)),
+			TimeRotatedSeconds:     event.Data.TimeRotatedSeconds,
+		}
+	case BaseURLRotationEventType:
+		ret = &BaseURLRotationEvent{NewBaseURL: event.Data.NewBaseUrl, TimeRotatedSeconds: event.Data.TimeRotatedSeconds}
+	case CreateAccountEventType:
+		ret = &CreateAccountEvent{CreatedAddress: d.address("created_address", event.Data.CreatedAddress), RoleID: event.Data.RoleId}
+	case AdminTransactionEventType:
+		ret = &AdminTransactionEvent{CommittedTimestampSecs: event.Data.CommittedTimestampSecs}
+	default:
+		ret = &UnknownEvent{Type: event.Data.Type, Bytes: d.bytes("bytes", event.Data.Bytes)}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return ret, nil
+}
+
+// decoder decodes event data fields, keeps the first error
+type decoder struct {
+	data *diemclient.EventData
+	err  error
+}
+
+func (d *decoder) amount() diemamount.Amount {
+	if d.data.Amount == nil {
+		d.fail(errors.New("missing amount"))
+		return diemamount.Amount{}
+	}
+	return diemamount.New(diemamount.Currency(d.data.Amount.Currency), d.data.Amount.Amount)
+}
+
+func (d *decoder) address(field, value string) diemtypes.AccountAddress {
+	address, err := diemtypes.MakeAccountAddress(value)
+	if err != nil {
+		d.fail(fmt.Errorf("invalid %s: %v", field, err))
+	}
+	return address
+}
+
+func (d *decoder) bytes(field, value string) []byte {
+	if value == "" {
+		return nil
+	}
+	bytes, err := hex.DecodeString(value)
+	if err != nil {
+		d.fail(fmt.Errorf("invalid %s: %v", field, err))
+	}
+	return bytes
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = fmt.Errorf("decode %s event data failed: %v", d.data.Type, err)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, data string) (events.Data, error) {
+	var event diemclient.Event
+	require.NoError(t, json.Unmarshal([]byte(`{"key": "00", "data": `+data+`}`), &event))
+	return events.DecodeEventData(&event)
+}
+
+func TestDecodeEventData(t *testing.T) {
+	t.Run("receivedpayment", func(t *testing.T) {
+		data, err := decode(t, `{
+			"type": "receivedpayment",
+			"amount": {"amount": 1000, "currency": "XUS"},
+			"sender": "f72589b71ff4f8d139674a3f7369c69b",
+			"receiver": "c5ab123458df0003415689adbb47326d",
+			"metadata": "0100"
+		}`)
+		require.NoError(t, err)
+		assert.Equal(t, &events.ReceivedPaymentEvent{
+			Amount:   diemamount.New(diemamount.XUS, 1000),
+			Sender:   diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b"),
+			Receiver: diemtypes.MustMakeAccountAddress("c5ab123458df0003415689adbb47326d"),
+			Metadata: []byte{1, 0},
+		}, data)
+		assert.Equal(t, events.ReceivedPaymentEventType, data.EventType())
+	})
+	t.Run("baseurlrotation", func(t *testing.T) {
+		data, err := decode(t, `{"type": "baseurlrotation", "new_base_url": "http://vasp", "time_rotated_seconds": 10}`)
+		require.NoError(t, err)
+		assert.Equal(t, &events.BaseURLRotationEvent{NewBaseURL: "http://vasp", TimeRotatedSeconds: 10}, data)
+	})
+	t.Run("compliancekeyrotation", func(t *testing.T) {
+		data, err := decode(t, `{"type": "compliancekeyrotation", "new_compliance_public_key": "aabb"}`)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0xaa, 0xbb}, []byte(data.(*events.ComplianceKeyRotationEvent).NewCompliancePublicKey))
+	})
+	t.Run("unknown", func(t *testing.T) {
+		data, err := decode(t, `{"type": "unknown", "bytes": "01"}`)
+		require.NoError(t, err)
+		assert.Equal(t, &events.UnknownEvent{Type: "unknown", Bytes: []byte{1}}, data)
+	})
+	t.Run("invalid address", func(t *testing.T) {
+		_, err := decode(t, `{"type": "sentpayment", "amount": {"amount": 1, "currency": "XUS"}, "sender": "xx"}`)
+		assert.EqualError(t, err, "decode sentpayment event data failed: invalid sender: encoding/hex: invalid byte: U+0078 'x'")
+	})
+	t.Run("missing amount", func(t *testing.T) {
+		_, err := decode(t, `{"type": "mint"}`)
+		assert.EqualError(t, err, "decode mint event data failed: missing amount")
+	})
+	t.Run("nil data", func(t *testing.T) {
+		_, err := events.DecodeEventData(&diemclient.Event{})
+		assert.EqualError(t, err, "event data is nil")
+	})
+}
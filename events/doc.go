@@ -0,0 +1,5 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides event streaming by polling `get_events` with a cursor, and typed event data decoding.
+package events
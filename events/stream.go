@@ -0,0 +1,163 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// Default stream config
+const (
+	DefaultBatchSize    uint64 = 100
+	DefaultPollInterval        = time.Second
+	DefaultMaxBackoff          = 30 * time.Second
+)
+
+// Stream polls events of an event key from a cursor (next event sequence number).
+// Save `Cursor()` after events are processed, and create stream with the saved cursor to
+// resume after restarting.
+type Stream struct {
+	Client    diemclient.Client
+	Key       string
+	BatchSize uint64
+	Interval  time.Duration
+	// Backoff is delay after failed poll, default is `diemclient.ExponentialBackoff` of
+	// `Interval` and `DefaultMaxBackoff`.
+	Backoff diemclient.BackoffFunc
+	// OnError is called when a poll failed in `Run`, the stream keeps polling after backoff.
+	OnError func(error)
+
+	mux    sync.Mutex
+	cursor uint64
+}
+
+// NewStream creates `Stream` for given event key starts from given sequence number
+func NewStream(client diemclient.Client, key string, start uint64) *Stream {
+	return &Stream{
+		Client:    client,
+		Key:       key,
+		BatchSize: DefaultBatchSize,
+		Interval:  DefaultPollInterval,
+		cursor:    start,
+	}
+}
+
+// NewReceivedPaymentsStream creates `Stream` for received events of given account.
+// Use `Stream.Key` for resuming the stream by `NewStream`.
+func NewReceivedPaymentsStream(ctx context.Context, client diemclient.Client, address diemtypes.AccountAddress, start uint64) (*Stream, error) {
+	account, err := diemclient.AsContextClient(client).GetAccountWithContext(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("account %s not found", address.Hex())
+	}
+	return NewStream(client, account.ReceivedEventsKey, start), nil
+}
+
+// Cursor returns next event sequence number to poll
+func (s *Stream) Cursor() uint64 {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.cursor
+}
+
+// Seek moves the cursor to given event sequence number, e.g. for re-polling events that failed
+// to be processed
+func (s *Stream) Seek(cursor uint64) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.cursor = cursor
+}
+
+// Poll polls a batch of events after the cursor, and moves the cursor forward.
+// Events already seen (sequence number less than cursor) are dropped, and events after a gap
+// of sequence numbers are polled by next call.
+func (s *Stream) Poll(ctx context.Context) ([]*diemclient.Event, error) {
+	events, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 {
+		s.advance(events[len(events)-1])
+	}
+	return events, nil
+}
+
+func (s *Stream) fetch(ctx context.Context) ([]*diemclient.Event, error) {
+	cursor := s.Cursor()
+	events, err := diemclient.AsContextClient(s.Client).GetEventsWithContext(ctx, s.Key, cursor, s.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*diemclient.Event, 0, len(events))
+	for _, event := range events {
+		if event.SequenceNumber < cursor {
+			continue
+		}
+		if event.SequenceNumber > cursor {
+			break
+		}
+		ret = append(ret, event)
+		cursor++
+	}
+	return ret, nil
+}
+
+func (s *Stream) advance(event *diemclient.Event) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.cursor = event.SequenceNumber + 1
+}
+
+// Run polls events and sends them to the channel until the context is done; the cursor moves
+// forward after each event is received from the channel.
+// It polls next batch immediately when a full batch is received, otherwise waits for `Interval`.
+// Failed polls (e.g. stale responses or network failures) are reported to `OnError` and retried
+// after backoff. Returns the context error.
+func (s *Stream) Run(ctx context.Context, events chan<- *diemclient.Event) error {
+	backoff := s.Backoff
+	if backoff == nil {
+		backoff = diemclient.ExponentialBackoff(s.Interval, DefaultMaxBackoff)
+	}
+	var failures uint
+	for {
+		batch, err := s.fetch(ctx)
+		delay := s.Interval
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if s.OnError != nil {
+				s.OnError(err)
+			}
+			delay = backoff(failures)
+			failures++
+		} else {
+			failures = 0
+			for _, event := range batch {
+				select {
+				case events <- event:
+					s.advance(event)
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			if uint64(len(batch)) == s.BatchSize {
+				delay = 0
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/events"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventsServer serves get_events from a list of events, fails next `failures` calls
+type eventsServer struct {
+	mux      sync.Mutex
+	events   []*diemclient.Event
+	failures int
+}
+
+func (s *eventsServer) add(events ...*diemclient.Event) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.events = append(s.events, events...)
+}
+
+func (s *eventsServer) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return nil, errors.New("connection refused")
+	}
+	req := requests[0]
+	start := req.Params[1].(uint64)
+	limit := req.Params[2].(uint64)
+	ret := []*diemclient.Event{}
+	for _, e := range s.events {
+		if e.SequenceNumber >= start && uint64(len(ret)) < limit {
+			ret = append(ret, e)
+		}
+	}
+	result, _ := json.Marshal(ret)
+	raw := json.RawMessage(result)
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{req.ID: {Result: &raw}}}
+	return stub.Call(requests...)
+}
+
+func newEvent(seq uint64) *diemclient.Event {
+	return &diemclient.Event{Key: "key", SequenceNumber: seq, Data: &diemclient.EventData{Type: "receivedpayment"}}
+}
+
+func newClient(server *eventsServer) diemclient.Client {
+	return diemclient.NewWithJsonRpcClient(testnet.ChainID, server,
+		diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+}
+
+func TestStreamPoll(t *testing.T) {
+	server := &eventsServer{}
+	server.add(newEvent(0), newEvent(1), newEvent(2))
+	stream := events.NewStream(newClient(server), "key", 1)
+	stream.BatchSize = 1
+
+	ret, err := stream.Poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, ret, 1)
+	assert.Equal(t, uint64(1), ret[0].SequenceNumber)
+	assert.Equal(t, uint64(2), stream.Cursor())
+
+	ret, err = stream.Poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, ret, 1)
+	assert.Equal(t, uint64(3), stream.Cursor())
+
+	ret, err = stream.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, uint64(3), stream.Cursor())
+
+	stream.Seek(1)
+	ret, err = stream.Poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, ret, 1)
+	assert.Equal(t, uint64(1), ret[0].SequenceNumber)
+}
+
+func TestStreamRun(t *testing.T) {
+	server := &eventsServer{failures: 2}
+	server.add(newEvent(0), newEvent(1))
+	stream := events.NewStream(newClient(server), "key", 0)
+	stream.Interval = time.Millisecond
+	var errs []error
+	stream.OnError = func(err error) { errs = append(errs, err) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan *diemclient.Event)
+	done := make(chan error)
+	go func() { done <- stream.Run(ctx, ch) }()
+
+	assert.Equal(t, uint64(0), (<-ch).SequenceNumber)
+	assert.Equal(t, uint64(1), (<-ch).SequenceNumber)
+	server.add(newEvent(2))
+	assert.Equal(t, uint64(2), (<-ch).SequenceNumber)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, uint64(3), stream.Cursor())
+}
@@ -15,16 +15,11 @@ import (
 
 // networkToPrefix converts from a human friendly format to a prefix usable by the bech32 address format
 func networkToPrefix(network string) (diemid.NetworkPrefix, error) {
-	switch network {
-	case "mainnet":
-		return diemid.MainnetPrefix, nil
-	case "premainnet":
-		return diemid.PreMainnetPrefix, nil
-	case "testnet":
-		return diemid.TestnetPrefix, nil
-	default:
-		return diemid.NetworkPrefix(""), fmt.Errorf("Invalid network=%s supplied, no network prefix")
+	prefix, ok := diemid.NetworkPrefixByName(network)
+	if !ok {
+		return diemid.NetworkPrefix(""), fmt.Errorf("Invalid network=%s supplied, no network prefix", network)
 	}
+	return prefix, nil
 }
 
 // encode converts a onchainAddress + subAddress or publickey to a bech32 address format
@@ -74,6 +69,11 @@ func encode(networkPrefix diemid.NetworkPrefix, onchainAddress, publicKey string
 // decode decodes the given bech32 encoded network public address and returns the hex account address and subAddress
 func decode(networkPrefix diemid.NetworkPrefix, encodedAddress string) (string, string, error) {
 	account, err := diemid.DecodeToAccount(networkPrefix, encodedAddress)
+	if errors.Is(err, diemid.ErrWrongNetwork) {
+		if prefix, suggestErr := diemid.SuggestNetwork(encodedAddress); suggestErr == nil {
+			return "", "", fmt.Errorf("Failed to decode to account, the address belongs to network %q: %w", prefix, err)
+		}
+	}
 	if err != nil {
 		return "", "", fmt.Errorf("Failed to decode to account: %w", err)
 	}
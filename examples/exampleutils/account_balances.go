@@ -6,7 +6,6 @@ package exampleutils
 import (
 	"fmt"
 
-	"github.com/diem/client-sdk-go/diemclient"
 	"github.com/diem/client-sdk-go/diemkeys"
 )
 
@@ -19,11 +18,10 @@ func PrintAccountsBalances(title string, sender, receiver *diemkeys.Keys) {
 
 // PrintAccountBalances prints given account balances
 func PrintAccountBalances(name string, account *diemkeys.Keys) {
-RetryGetAccount:
+	// stale response is retried by the client default retry policy
 	ret, err := Client.GetAccount(account.AccountAddress())
-	if _, ok := err.(*diemclient.StaleResponseError); ok {
-		// retry to hit another server if got stale response
-		goto RetryGetAccount
+	if err != nil {
+		panic(err)
 	}
 	fmt.Println(name)
 	for _, b := range ret.Balances {
@@ -9,9 +9,9 @@ import (
 
 	"github.com/diem/client-sdk-go/diemclient"
 	"github.com/diem/client-sdk-go/diemkeys"
-	"github.com/diem/client-sdk-go/diemsigner"
 	"github.com/diem/client-sdk-go/diemtypes"
 	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnbuilder"
 )
 
 // Client should be singleton instance for your application.
@@ -29,40 +29,18 @@ var Client = diemclient.New(testnet.ChainID, testnet.URL)
 // This function returns back executed transaction version.
 func SubmitAndWait(title string, sender *diemkeys.Keys, script diemtypes.Script) uint64 {
 	fmt.Println(title)
-	address := sender.AccountAddress()
-Retry:
-	account, err := Client.GetAccount(address)
-	if err != nil {
-		if _, ok := err.(*diemclient.StaleResponseError); ok {
-			// retry to hit another server if got stale response
-			goto Retry
-		}
-		panic(err)
-	}
-	sequenceNum := account.SequenceNumber
-	// it is recommended to set short expiration time for peer to peer transaction,
+	// txnbuilder fetches sender account sequence number, signs and submits the transaction;
+	// stale response and transient network failures are retried by the client default retry
+	// policy, see `diemclient.WithRetryPolicy`.
+	// It is recommended to set short expiration time for peer to peer transaction,
 	// as Diem blockchain transaction execution is fast.
-	expirationDuration := 30 * time.Second
-	expiration := uint64(time.Now().Add(expirationDuration).Unix())
-	txn := diemsigner.Sign(
-		sender,
-		address,
-		sequenceNum,
-		script,
-		1_000_000, 0, "XUS",
-		expiration,
-		testnet.ChainID,
-	)
-	err = Client.SubmitTransaction(txn)
-	if err != nil {
-		if _, ok := err.(*diemclient.StaleResponseError); !ok {
-			panic(err)
-		} else {
-			// ignore *diemclient.StaleResponseError as we know
-			// submit probably succeed even hit a stale server
-		}
-	}
-	transaction, err := Client.WaitForTransaction2(txn, expirationDuration)
+	transaction, err := txnbuilder.New(sender).
+		Script(script).
+		MaxGas(1_000_000).
+		GasUnitPrice(0).
+		GasCurrency("XUS").
+		ExpireIn(30 * time.Second).
+		SignSubmitAndWait(Client)
 	if err != nil {
 		// WaitForTransaction retried for *diemclient.StaleResponseError
 		// already, hence here we panic if got error (including timeout error)
@@ -0,0 +1,20 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides exchange rate service: on-chain `to_xdx_exchange_rate` of currencies are read from
+// `get_currencies`, bypassing the on-chain configs cache of the client, and cached with TTL for
+// converting amounts between currencies and micro-XDX; rate changes are notified by streaming the
+// `to_xdx_exchange_rate_update` events of the currencies, which also update the cached rates and
+// are not replaced by older currencies info.
+//
+// Example:
+//
+//	rates := exchangerates.New(client)
+//	xdx, err := rates.ToXDX(ctx, diemamount.New("XUS", 1_000_000))
+//
+//	updates := make(chan *exchangerates.Update)
+//	go rates.Watch(ctx, "XUS", cursor, updates)
+//	for update := range updates {
+//		fmt.Println(update.Currency, update.Rate)
+//	}
+package exchangerates
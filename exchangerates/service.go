@@ -0,0 +1,245 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package exchangerates
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/events"
+)
+
+// DefaultTTL is the default time to live of cached exchange rates
+const DefaultTTL = time.Minute
+
+// Update is an exchange rate change of a currency
+type Update struct {
+	Currency diemamount.Currency
+	// Rate is the new to XDX exchange rate
+	Rate float32
+	// Event is the `to_xdx_exchange_rate_update` event, resume watching from its sequence
+	// number + 1
+	Event *diemclient.Event
+}
+
+// Service provides cached on-chain exchange rates. Create it by `New`.
+type Service struct {
+	Client diemclient.Client
+	// TTL is the time to live of cached currencies info, 0 for no cache
+	TTL time.Duration
+	// PollInterval is the interval of polling exchange rate update events by `Watch`
+	PollInterval time.Duration
+	// OnError is called when polling or decoding exchange rate update events failed in
+	// `Watch`, which keeps polling.
+	OnError func(error)
+
+	// load serializes loading currencies info, mux guards the fields below
+	load       sync.Mutex
+	mux        sync.Mutex
+	currencies []*diemclient.CurrencyInfo
+	expiresAt  time.Time
+	// applied is the latest exchange rate update event applied of each currency
+	applied map[diemamount.Currency]*appliedRate
+}
+
+type appliedRate struct {
+	event   *events.ToXDXExchangeRateUpdateEvent
+	version uint64
+}
+
+// New creates `Service` with default settings
+func New(client diemclient.Client) *Service {
+	return &Service{
+		Client:       client,
+		TTL:          DefaultTTL,
+		PollInterval: events.DefaultPollInterval,
+	}
+}
+
+// Currencies returns currencies info, loaded by `get_currencies` if the cache is expired.
+// Currencies info is loaded from the server by one call at a time, bypassing the on-chain configs
+// cache of the client. Rates applied from update events by `Watch` are not replaced by loaded
+// currencies info that may be older than the events.
+func (s *Service) Currencies(ctx context.Context) ([]*diemclient.CurrencyInfo, error) {
+	if currencies := s.cached(); currencies != nil {
+		return currencies, nil
+	}
+	s.load.Lock()
+	defer s.load.Unlock()
+	// loaded by a concurrent call
+	if currencies := s.cached(); currencies != nil {
+		return currencies, nil
+	}
+	// the response ledger version is the last response ledger version or later
+	version := s.Client.LastResponseLedgerState().Version
+	currencies, err := diemclient.AsContextClient(s.Client).GetCurrenciesWithContext(diemclient.BypassOnChainConfigsCache(ctx))
+	if err != nil {
+		return nil, err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, applied := range s.applied {
+		if applied.version >= version {
+			currencies = withRate(currencies, applied.event)
+		}
+	}
+	if s.TTL > 0 {
+		s.currencies, s.expiresAt = currencies, time.Now().Add(s.TTL)
+	}
+	return currencies, nil
+}
+
+func (s *Service) cached() []*diemclient.CurrencyInfo {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.currencies != nil && time.Now().Before(s.expiresAt) {
+		return s.currencies
+	}
+	return nil
+}
+
+// Refresh drops the cached rates, next call loads them from chain; the on-chain configs cache
+// of the client is bypassed, see `Currencies`.
+func (s *Service) Refresh() {
+	s.mux.Lock()
+	s.currencies, s.expiresAt = nil, time.Time{}
+	s.mux.Unlock()
+}
+
+// Rate returns to XDX exchange rate of the currency
+func (s *Service) Rate(ctx context.Context, currency diemamount.Currency) (float32, error) {
+	info, err := s.currency(ctx, currency)
+	if err != nil {
+		return 0, err
+	}
+	return info.ToXdxExchangeRate, nil
+}
+
+// Converter returns `diemamount.Converter` of the cached rates
+func (s *Service) Converter(ctx context.Context) (*diemamount.Converter, error) {
+	currencies, err := s.Currencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return diemamount.NewConverter(currencies), nil
+}
+
+// Convert converts the amount into the target currency, see `diemamount.Converter#Convert`
+func (s *Service) Convert(ctx context.Context, amount diemamount.Amount, to diemamount.Currency) (diemamount.Amount, error) {
+	converter, err := s.Converter(ctx)
+	if err != nil {
+		return diemamount.Amount{}, err
+	}
+	return converter.Convert(amount, to)
+}
+
+// ToXDX converts the amount into micro-XDX
+func (s *Service) ToXDX(ctx context.Context, amount diemamount.Amount) (diemamount.Amount, error) {
+	return s.Convert(ctx, amount, diemamount.XDX)
+}
+
+// FromXDX converts the micro-XDX amount into the target currency
+func (s *Service) FromXDX(ctx context.Context, micro uint64, to diemamount.Currency) (diemamount.Amount, error) {
+	return s.Convert(ctx, diemamount.New(diemamount.XDX, micro), to)
+}
+
+// Watch streams `to_xdx_exchange_rate_update` events of the currency from the start event
+// sequence number, updates the cached rate and sends the update to the channel for each event,
+// until the context is done. Returns the context error, or error if the currency is unknown.
+func (s *Service) Watch(ctx context.Context, currency diemamount.Currency, start uint64, updates chan<- *Update) error {
+	info, err := s.currency(ctx, currency)
+	if err != nil {
+		return err
+	}
+	stream := events.NewStream(s.Client, info.ExchangeRateUpdateEventsKey, start)
+	stream.Interval = s.PollInterval
+	stream.OnError = s.OnError
+	received := make(chan *diemclient.Event)
+	done := make(chan error, 1)
+	go func() { done <- stream.Run(ctx, received) }()
+	for {
+		select {
+		case event := <-received:
+			update, err := s.apply(event)
+			if err != nil {
+				if s.OnError != nil {
+					s.OnError(err)
+				}
+				continue
+			}
+			select {
+			case updates <- update:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case err := <-done:
+			return err
+		}
+	}
+}
+
+// apply decodes the exchange rate update event and updates the cached rate, events older than
+// the applied event of the currency are ignored.
+func (s *Service) apply(event *diemclient.Event) (*Update, error) {
+	data, err := events.DecodeEventData(event)
+	if err != nil {
+		return nil, err
+	}
+	rate, ok := data.(*events.ToXDXExchangeRateUpdateEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected event type %s in exchange rate update events", data.EventType())
+	}
+	s.mux.Lock()
+	if last := s.applied[rate.Currency]; last == nil || last.version <= event.TransactionVersion {
+		if s.applied == nil {
+			s.applied = make(map[diemamount.Currency]*appliedRate)
+		}
+		s.applied[rate.Currency] = &appliedRate{event: rate, version: event.TransactionVersion}
+		if s.currencies != nil {
+			s.currencies = withRate(s.currencies, rate)
+		}
+	}
+	s.mux.Unlock()
+	return &Update{Currency: rate.Currency, Rate: rate.NewToXDXExchangeRate, Event: event}, nil
+}
+
+// withRate returns copy of the currencies info with the new rate of the updated currency, the
+// cached currencies info may be shared with callers of `Currencies`.
+func withRate(currencies []*diemclient.CurrencyInfo, rate *events.ToXDXExchangeRateUpdateEvent) []*diemclient.CurrencyInfo {
+	ret := make([]*diemclient.CurrencyInfo, len(currencies))
+	for i, info := range currencies {
+		if info.Code == string(rate.Currency) {
+			info = &diemclient.CurrencyInfo{
+				Code:                        info.Code,
+				ScalingFactor:               info.ScalingFactor,
+				FractionalPart:              info.FractionalPart,
+				ToXdxExchangeRate:           rate.NewToXDXExchangeRate,
+				MintEventsKey:               info.MintEventsKey,
+				BurnEventsKey:               info.BurnEventsKey,
+				PreburnEventsKey:            info.PreburnEventsKey,
+				CancelBurnEventsKey:         info.CancelBurnEventsKey,
+				ExchangeRateUpdateEventsKey: info.ExchangeRateUpdateEventsKey,
+			}
+		}
+		ret[i] = info
+	}
+	return ret
+}
+
+func (s *Service) currency(ctx context.Context, currency diemamount.Currency) (*diemclient.CurrencyInfo, error) {
+	currencies, err := s.Currencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range currencies {
+		if info.Code == string(currency) {
+			return info, nil
+		}
+	}
+	return nil, &diemamount.UnknownCurrencyError{Currency: currency}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package exchangerates_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/exchangerates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const halfUpdatesKey = "half-exchange-rate-updates"
+
+func newServer() *diemclienttest.Server {
+	server := diemclienttest.NewServer()
+	server.SetCurrencies(
+		&diemclient.CurrencyInfo{Code: "XDX", ScalingFactor: 1_000_000, ToXdxExchangeRate: 1},
+		&diemclient.CurrencyInfo{Code: "HALF", ScalingFactor: 1_000_000, ToXdxExchangeRate: 0.5,
+			ExchangeRateUpdateEventsKey: halfUpdatesKey},
+	)
+	return server
+}
+
+func rateUpdate(rate float32) *diemclient.Event {
+	return &diemclient.Event{
+		Key: halfUpdatesKey,
+		Data: &diemclient.EventData{
+			Type:                 "to_xdx_exchange_rate_update",
+			CurrencyCode:         "HALF",
+			NewToXdxExchangeRate: rate,
+		},
+	}
+}
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+	server := newServer()
+	rates := exchangerates.New(server.Client(diemclient.WithOnChainConfigsCacheTTL(0)))
+
+	rate, err := rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.5), rate)
+	xdx, err := rates.ToXDX(ctx, diemamount.New("HALF", 1000))
+	require.NoError(t, err)
+	assert.Equal(t, diemamount.New(diemamount.XDX, 500), xdx)
+	half, err := rates.FromXDX(ctx, 500, "HALF")
+	require.NoError(t, err)
+	assert.Equal(t, diemamount.New("HALF", 1000), half)
+
+	_, err = rates.Rate(ctx, "XXX")
+	assert.Equal(t, &diemamount.UnknownCurrencyError{Currency: "XXX"}, err)
+	_, err = rates.ToXDX(ctx, diemamount.New("XXX", 1))
+	assert.Equal(t, &diemamount.UnknownCurrencyError{Currency: "XXX"}, err)
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	server := newServer()
+	// on-chain configs cache of the client is bypassed
+	rates := exchangerates.New(server.Client(diemclient.WithOnChainConfigsCacheTTL(time.Hour)))
+	_, err := rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+
+	server.SetCurrencies(&diemclient.CurrencyInfo{Code: "HALF", ScalingFactor: 1_000_000, ToXdxExchangeRate: 0.25})
+	rate, err := rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.5), rate, "cached")
+
+	rates.Refresh()
+	rate, err = rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.25), rate)
+
+	rates.Refresh()
+	rates.TTL = time.Millisecond
+	_, err = rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+	server.SetCurrencies(&diemclient.CurrencyInfo{Code: "HALF", ScalingFactor: 1_000_000, ToXdxExchangeRate: 0.2})
+	time.Sleep(2 * time.Millisecond)
+	rate, err = rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.2), rate, "expired")
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := newServer()
+	server.AddEvents(halfUpdatesKey, rateUpdate(0.4), rateUpdate(0.25))
+	rates := exchangerates.New(server.Client())
+	rates.PollInterval = time.Millisecond
+
+	updates := make(chan *exchangerates.Update)
+	done := make(chan error, 1)
+	go func() { done <- rates.Watch(ctx, "HALF", 1, updates) }()
+
+	update := <-updates
+	assert.Equal(t, diemamount.Currency("HALF"), update.Currency)
+	assert.Equal(t, float32(0.25), update.Rate)
+	assert.Equal(t, uint64(1), update.Event.SequenceNumber)
+	rate, err := rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.25), rate, "cached rate is updated")
+	xdx, err := rates.ToXDX(ctx, diemamount.New("HALF", 1000))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(250), xdx.Micro)
+
+	server.AddEvents(halfUpdatesKey, rateUpdate(0.5))
+	update = <-updates
+	assert.Equal(t, float32(0.5), update.Rate)
+	assert.Equal(t, uint64(2), update.Event.SequenceNumber)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	err = rates.Watch(context.Background(), "XXX", 0, updates)
+	assert.Equal(t, &diemamount.UnknownCurrencyError{Currency: "XXX"}, err)
+}
+
+func TestWatchedRateIsNotReplacedByOlderCurrencies(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := newServer()
+	client := server.Client()
+	rates := exchangerates.New(client)
+	rates.PollInterval = time.Millisecond
+	rates.TTL = 0
+	_, err := rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+
+	server.AddTransactions(&diemclient.Transaction{Events: []*diemclient.Event{rateUpdate(0.25)}})
+	updates := make(chan *exchangerates.Update)
+	go func() { _ = rates.Watch(ctx, "HALF", 0, updates) }()
+	update := <-updates
+	assert.Equal(t, float32(0.25), update.Rate)
+	rate, err := rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.25), rate, "currencies info is not newer than the event")
+
+	server.AddTransactions(&diemclient.Transaction{})
+	server.SetCurrencies(&diemclient.CurrencyInfo{Code: "HALF", ScalingFactor: 1_000_000, ToXdxExchangeRate: 0.2})
+	_, err = client.GetMetadata()
+	require.NoError(t, err)
+	rate, err = rates.Rate(ctx, "HALF")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.2), rate, "currencies info is newer than the event")
+}
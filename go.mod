@@ -4,12 +4,16 @@ go 1.14
 
 require (
 	github.com/avast/retry-go v3.0.0+incompatible
-	github.com/golang/protobuf v1.4.2
+	github.com/golang/protobuf v1.5.2
 	github.com/novifinancial/serde-reflection/serde-generate/runtime/golang v0.0.0-20201214184956-1fd02a932898
 	github.com/nsf/jsondiff v0.0.0-20200515183724-f29ed568f4ce
+	github.com/prometheus/client_golang v1.12.2
 	github.com/stretchr/testify v1.6.1
+	github.com/tyler-smith/go-bip39 v1.1.0
+	go.opentelemetry.io/otel v0.15.0
 	golang.org/x/crypto v0.0.0-20200728195943-123391ffb6de
-	golang.org/x/sys v0.0.0-20200812155832-6a926be9bd1d // indirect
-	google.golang.org/protobuf v1.25.0
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2 // indirect
+	google.golang.org/protobuf v1.27.1
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
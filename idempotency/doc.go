@@ -0,0 +1,5 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides idempotent transaction submission by a client-side key, e.g. a payout reference id.
+package idempotency
@@ -0,0 +1,226 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package idempotency
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/diem/client-sdk-go/internal/sqlutil"
+)
+
+// Status is status of a submission record
+type Status string
+
+// List of submission statuses
+const (
+	// StatusPending is for the transaction is signed, it may be submitted or not
+	StatusPending Status = "pending"
+	// StatusRejected is for the transaction is rejected by the server, it is not in mempool
+	StatusRejected Status = "rejected"
+	// StatusExecuted is for the transaction is found on chain, its vm status may be failure
+	StatusExecuted Status = "executed"
+)
+
+// Record is a submission of an idempotency key
+type Record struct {
+	Key                     string
+	Sender                  string
+	SequenceNumber          uint64
+	Hash                    string
+	ExpirationTimestampSecs uint64
+	// SignedTransaction is hex-encoded signed transaction bytes, for resubmitting
+	SignedTransaction string
+	Status            Status
+	// Version is the transaction version when the status is `StatusExecuted`
+	Version uint64
+}
+
+// Store persists submission records by idempotency key
+type Store interface {
+	// Get returns record of given key, returns nil without error if not found
+	Get(key string) (*Record, error)
+	// Put saves the record, overwrites existing record of same key
+	Put(record *Record) error
+	// Reserve saves the record if there is no record of same key and previous is empty, or the
+	// hash of the existing record is previous, and returns nil without error; otherwise returns
+	// the existing record without changing it.
+	// Check and save must be one atomic operation, so that only one transaction is submitted
+	// for a key when it is submitted concurrently.
+	Reserve(record *Record, previous string) (*Record, error)
+}
+
+func errRecordNotFound(key string) error {
+	return fmt.Errorf("record of key %s not found", key)
+}
+
+// MemoryStore implements `Store` in memory, records are lost after restart.
+type MemoryStore struct {
+	mux     sync.RWMutex
+	records map[string]Record
+}
+
+// NewMemoryStore creates `MemoryStore`
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+// Get implements `Store`
+func (s *MemoryStore) Get(key string) (*Record, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	record, ok := s.records[key]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// Put implements `Store`
+func (s *MemoryStore) Put(record *Record) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.records[record.Key] = *record
+	return nil
+}
+
+// Reserve implements `Store`
+func (s *MemoryStore) Reserve(record *Record, previous string) (*Record, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	existing, ok := s.records[record.Key]
+	if ok && existing.Hash != previous {
+		return &existing, nil
+	}
+	if !ok && previous != "" {
+		return nil, errRecordNotFound(record.Key)
+	}
+	s.records[record.Key] = *record
+	return nil, nil
+}
+
+// SQLStore implements `Store` with a SQL database table:
+//
+//	CREATE TABLE <table> (
+//	  idempotency_key VARCHAR(255) PRIMARY KEY,
+//	  sender VARCHAR(32) NOT NULL,
+//	  sequence_number BIGINT NOT NULL,
+//	  hash VARCHAR(64) NOT NULL,
+//	  expiration_timestamp_secs BIGINT NOT NULL,
+//	  signed_transaction TEXT NOT NULL,
+//	  status VARCHAR(16) NOT NULL,
+//	  version BIGINT NOT NULL
+//	)
+//
+// Call `CreateTable` to create the table if it does not exist.
+type SQLStore struct {
+	DB    *sql.DB
+	Table string
+	// Placeholder returns bind parameter placeholder for the n-th (starts from 1) parameter,
+	// defaults to "?"; set `DollarPlaceholder` for PostgreSQL.
+	Placeholder func(n int) string
+}
+
+// DollarPlaceholder returns "$n" bind parameter placeholder
+var DollarPlaceholder = sqlutil.DollarPlaceholder
+
+// NewSQLStore creates `SQLStore` with given db and table name
+func NewSQLStore(db *sql.DB, table string) *SQLStore {
+	return &SQLStore{DB: db, Table: table}
+}
+
+// CreateTable creates the records table if it does not exist
+func (s *SQLStore) CreateTable() error {
+	_, err := s.DB.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (idempotency_key VARCHAR(255) PRIMARY KEY, sender VARCHAR(32) NOT NULL, sequence_number BIGINT NOT NULL, hash VARCHAR(64) NOT NULL, expiration_timestamp_secs BIGINT NOT NULL, signed_transaction TEXT NOT NULL, status VARCHAR(16) NOT NULL, version BIGINT NOT NULL)",
+		s.Table))
+	return err
+}
+
+// Get implements `Store`
+func (s *SQLStore) Get(key string) (*Record, error) {
+	row := s.DB.QueryRow(fmt.Sprintf(
+		"SELECT sender, sequence_number, hash, expiration_timestamp_secs, signed_transaction, status, version FROM %s WHERE idempotency_key = %s",
+		s.Table, s.placeholder(1)), key)
+	record := Record{Key: key}
+	var seq, expiration, version int64
+	if err := row.Scan(&record.Sender, &seq, &record.Hash, &expiration,
+		&record.SignedTransaction, &record.Status, &version); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	record.SequenceNumber = uint64(seq)
+	record.ExpirationTimestampSecs = uint64(expiration)
+	record.Version = uint64(version)
+	return &record, nil
+}
+
+// Put implements `Store`
+func (s *SQLStore) Put(record *Record) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE idempotency_key = %s", s.Table, s.placeholder(1)), record.Key)
+	if err == nil {
+		_, err = tx.Exec(fmt.Sprintf(
+			"INSERT INTO %s (idempotency_key, sender, sequence_number, hash, expiration_timestamp_secs, signed_transaction, status, version) VALUES (%s, %s, %s, %s, %s, %s, %s, %s)",
+			s.Table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4),
+			s.placeholder(5), s.placeholder(6), s.placeholder(7), s.placeholder(8)),
+			record.Key, record.Sender, int64(record.SequenceNumber), record.Hash,
+			int64(record.ExpirationTimestampSecs), record.SignedTransaction, string(record.Status),
+			int64(record.Version))
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Reserve implements `Store`
+func (s *SQLStore) Reserve(record *Record, previous string) (*Record, error) {
+	var ret sql.Result
+	var err error
+	if previous == "" {
+		ret, err = s.DB.Exec(fmt.Sprintf(
+			"INSERT INTO %s (idempotency_key, sender, sequence_number, hash, expiration_timestamp_secs, signed_transaction, status, version) SELECT %s, %s, %s, %s, %s, %s, %s, %s WHERE NOT EXISTS (SELECT 1 FROM %s WHERE idempotency_key = %s)",
+			s.Table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4),
+			s.placeholder(5), s.placeholder(6), s.placeholder(7), s.placeholder(8),
+			s.Table, s.placeholder(9)),
+			record.Key, record.Sender, int64(record.SequenceNumber), record.Hash,
+			int64(record.ExpirationTimestampSecs), record.SignedTransaction, string(record.Status),
+			int64(record.Version), record.Key)
+	} else {
+		ret, err = s.DB.Exec(fmt.Sprintf(
+			"UPDATE %s SET sender = %s, sequence_number = %s, hash = %s, expiration_timestamp_secs = %s, signed_transaction = %s, status = %s, version = %s WHERE idempotency_key = %s AND hash = %s",
+			s.Table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4),
+			s.placeholder(5), s.placeholder(6), s.placeholder(7), s.placeholder(8), s.placeholder(9)),
+			record.Sender, int64(record.SequenceNumber), record.Hash,
+			int64(record.ExpirationTimestampSecs), record.SignedTransaction, string(record.Status),
+			int64(record.Version), record.Key, previous)
+	}
+	if err != nil {
+		return nil, err
+	}
+	saved, err := ret.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if saved > 0 {
+		return nil, nil
+	}
+	existing, err := s.Get(record.Key)
+	if err == nil && existing == nil {
+		err = errRecordNotFound(record.Key)
+	}
+	return existing, err
+}
+
+func (s *SQLStore) placeholder(n int) string {
+	return sqlutil.Placeholder(s.Placeholder, n)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package idempotency_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/diem/client-sdk-go/idempotency"
+	"github.com/diem/client-sdk-go/internal/sqlutil/sqltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	testStore(t, store)
+
+	require.NoError(t, store.Put(&idempotency.Record{Key: "key", Status: idempotency.StatusPending}))
+	record, err := store.Get("key")
+	require.NoError(t, err)
+	record.Status = idempotency.StatusExecuted
+	stored, err := store.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, idempotency.StatusPending, stored.Status, "records are copied")
+}
+
+func TestSQLStore(t *testing.T) {
+	db := newFakeDB()
+	store := idempotency.NewSQLStore(db.Open(), "submissions")
+	store.Placeholder = idempotency.DollarPlaceholder
+	require.NoError(t, store.CreateTable())
+	testStore(t, store)
+
+	queries := db.Queries()
+	assert.Contains(t, queries[0], "idempotency_key VARCHAR(255) PRIMARY KEY")
+	for _, query := range queries[1:] {
+		assert.NotContains(t, query, "?")
+	}
+
+	db.Fail(errors.New("connection lost"))
+	_, err := store.Get("key")
+	assert.EqualError(t, err, "connection lost")
+	_, err = store.Reserve(&idempotency.Record{Key: "key"}, "")
+	assert.EqualError(t, err, "connection lost")
+}
+
+func testStore(t *testing.T, store idempotency.Store) {
+	record := func(key, hash string, status idempotency.Status) *idempotency.Record {
+		return &idempotency.Record{
+			Key:                     key,
+			Sender:                  "f72589b71ff4f8d139674a3f7369c69b",
+			SequenceNumber:          1,
+			Hash:                    hash,
+			ExpirationTimestampSecs: 1600000000,
+			SignedTransaction:       "00",
+			Status:                  status,
+		}
+	}
+	ret, err := store.Get("payout-1")
+	require.NoError(t, err)
+	assert.Nil(t, ret)
+
+	existing, err := store.Reserve(record("payout-1", "h1", idempotency.StatusPending), "")
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+	ret, err = store.Get("payout-1")
+	require.NoError(t, err)
+	assert.Equal(t, record("payout-1", "h1", idempotency.StatusPending), ret)
+
+	existing, err = store.Reserve(record("payout-1", "h2", idempotency.StatusPending), "")
+	require.NoError(t, err)
+	assert.Equal(t, record("payout-1", "h1", idempotency.StatusPending), existing,
+		"not replaced when a record is not expected")
+
+	existing, err = store.Reserve(record("payout-1", "h2", idempotency.StatusPending), "h0")
+	require.NoError(t, err)
+	assert.Equal(t, record("payout-1", "h1", idempotency.StatusPending), existing,
+		"not replaced when the previous hash does not match")
+
+	existing, err = store.Reserve(record("payout-1", "h2", idempotency.StatusPending), "h1")
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+	ret, err = store.Get("payout-1")
+	require.NoError(t, err)
+	assert.Equal(t, record("payout-1", "h2", idempotency.StatusPending), ret)
+
+	_, err = store.Reserve(record("payout-2", "h3", idempotency.StatusPending), "h1")
+	assert.EqualError(t, err, "record of key payout-2 not found")
+
+	executed := record("payout-1", "h2", idempotency.StatusExecuted)
+	executed.Version = 10
+	require.NoError(t, store.Put(executed))
+	ret, err = store.Get("payout-1")
+	require.NoError(t, err)
+	assert.Equal(t, executed, ret)
+}
+
+// newFakeDB returns `sqltest.DB` serving the statements of `idempotency.SQLStore` from memory
+func newFakeDB() *sqltest.DB {
+	rows := make(map[string][]driver.Value)
+	return &sqltest.DB{
+		Exec: func(query string, args []driver.Value) (driver.Result, error) {
+			switch {
+			case strings.HasPrefix(query, "CREATE TABLE"):
+				return driver.RowsAffected(0), nil
+			case strings.HasPrefix(query, "DELETE"):
+				delete(rows, args[0].(string))
+				return driver.RowsAffected(1), nil
+			case strings.HasPrefix(query, "INSERT INTO") && strings.Contains(query, "WHERE NOT EXISTS"):
+				if _, ok := rows[args[8].(string)]; ok {
+					return driver.RowsAffected(0), nil
+				}
+				rows[args[0].(string)] = args[:8]
+				return driver.RowsAffected(1), nil
+			case strings.HasPrefix(query, "INSERT INTO"):
+				rows[args[0].(string)] = args
+				return driver.RowsAffected(1), nil
+			case strings.HasPrefix(query, "UPDATE"):
+				key := args[7].(string)
+				if row, ok := rows[key]; !ok || row[3] != args[8] {
+					return driver.RowsAffected(0), nil
+				}
+				rows[key] = append([]driver.Value{key}, args[:7]...)
+				return driver.RowsAffected(1), nil
+			}
+			return nil, fmt.Errorf("unexpected statement: %s", query)
+		},
+		Query: func(query string, args []driver.Value) (*sqltest.Rows, error) {
+			ret := &sqltest.Rows{Columns: []string{"sender", "sequence_number", "hash",
+				"expiration_timestamp_secs", "signed_transaction", "status", "version"}}
+			if row, ok := rows[args[0].(string)]; ok {
+				ret.Values = [][]driver.Value{row[1:]}
+			}
+			return ret, nil
+		},
+	}
+}
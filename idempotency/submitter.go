@@ -0,0 +1,177 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+)
+
+// SignFunc signs a new transaction for an idempotency key, e.g. by `txnbuilder.Builder#Sign`
+// with the latest sequence number.
+type SignFunc func(ctx context.Context) (*diemtypes.SignedTransaction, error)
+
+// ErrConcurrentSubmission is returned with the record saved by another submission of the same
+// key, the transaction signed by the `SignFunc` is not submitted.
+var ErrConcurrentSubmission = errors.New("idempotency key is submitted concurrently")
+
+// Submitter submits transactions idempotently by key. A new transaction is reserved in the
+// `Store` before it is submitted, only one transaction of a key is submitted when the key is
+// submitted concurrently.
+//
+//	submitter := idempotency.New(client, store)
+//	record, err := submitter.Submit(ctx, payoutID, func(ctx context.Context) (*diemtypes.SignedTransaction, error) {
+//		return txnbuilder.New(sender).Payload(payload).Context(ctx).Sign(client)
+//	})
+type Submitter struct {
+	client diemclient.ContextClient
+	store  Store
+}
+
+// New creates `Submitter` with the client and the store of submission records
+func New(client diemclient.Client, store Store) *Submitter {
+	return &Submitter{client: diemclient.AsContextClient(client), store: store}
+}
+
+// Submit submits a transaction of the key:
+//
+//   - no record or the recorded transaction is rejected: signs a new transaction by `sign`,
+//     records it as pending, then submits it.
+//   - the recorded transaction is executed: returns the record.
+//   - the recorded transaction may still be in mempool: resubmits the recorded transaction
+//     bytes, a transaction can't be executed twice.
+//   - the recorded transaction is expired, or its sequence number is used by another
+//     transaction: it can never be executed, signs a new transaction as above.
+//
+// The record is returned with the submit error if the submission failed; the record status
+// is `StatusRejected` if the server rejected the transaction, otherwise it is still pending
+// and the key should be retried. `ErrConcurrentSubmission` is returned if another submission
+// of the key recorded its transaction first.
+func (s *Submitter) Submit(ctx context.Context, key string, sign SignFunc) (*Record, error) {
+	record, err := s.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	previous := ""
+	if record != nil {
+		previous = record.Hash
+		switch record.Status {
+		case StatusExecuted:
+			return record, nil
+		case StatusPending:
+			live, err := s.check(ctx, record)
+			if err != nil || record.Status == StatusExecuted {
+				return record, err
+			}
+			if live {
+				return record, s.resubmit(ctx, record)
+			}
+		}
+	}
+	return s.submit(ctx, key, sign, previous)
+}
+
+// Wait waits for the transaction of the record until the context is done, and records it as
+// executed when it is found on chain. Returns the transaction with `*diemclient.ExecutionError`
+// if its execution failed.
+func (s *Submitter) Wait(ctx context.Context, record *Record) (*diemclient.Transaction, error) {
+	sender, err := diemtypes.MakeAccountAddress(record.Sender)
+	if err != nil {
+		return nil, err
+	}
+	txn, err := s.client.WaitForTransactionWithContext(
+		ctx, sender, record.SequenceNumber, record.Hash, record.ExpirationTimestampSecs)
+	var execErr *diemclient.ExecutionError
+	if errors.As(err, &execErr) {
+		txn = execErr.Transaction
+	}
+	if txn == nil {
+		return nil, err
+	}
+	if putErr := s.executed(record, txn); putErr != nil {
+		return txn, putErr
+	}
+	return txn, err
+}
+
+// submit signs and submits a new transaction, it replaces the record of the previous hash.
+func (s *Submitter) submit(ctx context.Context, key string, sign SignFunc, previous string) (*Record, error) {
+	txn, err := sign(ctx)
+	if err != nil {
+		return nil, err
+	}
+	record := &Record{
+		Key:                     key,
+		Sender:                  txn.RawTxn.Sender.Hex(),
+		SequenceNumber:          txn.RawTxn.SequenceNumber,
+		Hash:                    txn.TransactionHash(),
+		ExpirationTimestampSecs: txn.RawTxn.ExpirationTimestampSecs,
+		SignedTransaction:       diemtypes.ToHex(txn),
+		Status:                  StatusPending,
+	}
+	// record before submitting, so that a crash after submitting can't lose the transaction
+	existing, err := s.store.Reserve(record, previous)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, ErrConcurrentSubmission
+	}
+	err = s.client.SubmitTransactionWithContext(ctx, txn)
+	var submitErr *diemclient.SubmitError
+	if errors.As(err, &submitErr) {
+		record.Status = StatusRejected
+		if putErr := s.store.Put(record); putErr != nil {
+			return record, putErr
+		}
+	}
+	return record, err
+}
+
+// check returns true if the pending transaction may still be executed; the record is updated
+// if the transaction is found on chain.
+func (s *Submitter) check(ctx context.Context, record *Record) (bool, error) {
+	sender, err := diemtypes.MakeAccountAddress(record.Sender)
+	if err != nil {
+		return false, err
+	}
+	txn, err := s.client.GetAccountTransactionWithContext(ctx, sender, record.SequenceNumber, false)
+	if err != nil {
+		return false, err
+	}
+	if txn == nil {
+		state := s.client.LastResponseLedgerState()
+		return record.ExpirationTimestampSecs*1_000_000 > state.TimestampUsec, nil
+	}
+	if txn.Hash != record.Hash {
+		return false, nil
+	}
+	return false, s.executed(record, txn)
+}
+
+// resubmit submits the recorded transaction bytes again. Errors of the transaction is already
+// in mempool or executed are ignored.
+func (s *Submitter) resubmit(ctx context.Context, record *Record) error {
+	err := s.client.SubmitWithContext(ctx, record.SignedTransaction)
+	var rpcErr *jsonrpc.ResponseError
+	if errors.Is(err, diemclient.ErrSequenceNumberTooOld) ||
+		errors.As(err, &rpcErr) && rpcErr.Code == diemclient.ErrCodeMempoolInvalidUpdate {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("resubmit transaction %s of key %s failed: %w", record.Hash, record.Key, err)
+	}
+	return nil
+}
+
+func (s *Submitter) executed(record *Record, txn *diemclient.Transaction) error {
+	record.Status = StatusExecuted
+	record.Version = txn.Version
+	return s.store.Put(record)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/idempotency"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func payment(sender *diemkeys.Keys) *txnbuilder.Builder {
+	return txnbuilder.New(sender).Payload(stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+		diemtypes.Currency("XUS"), diemkeys.MustGenKeys().AccountAddress(), 100, nil, nil))
+}
+
+// sign returns `idempotency.SignFunc` signing transaction of the builder, and counts calls
+func sign(client diemclient.Client, signs *int, builder func() *txnbuilder.Builder) idempotency.SignFunc {
+	return func(ctx context.Context) (*diemtypes.SignedTransaction, error) {
+		*signs++
+		return builder().Context(ctx).Sign(client)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("executed", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		client := server.Client(diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		submitter := idempotency.New(client, idempotency.NewMemoryStore())
+		sender := server.GenAccount(&diemclient.Account{})
+		builder := func() *txnbuilder.Builder { return payment(sender) }
+		signs := 0
+		record, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+		assert.Equal(t, idempotency.StatusPending, record.Status)
+		txn, err := submitter.Wait(ctx, record)
+		require.NoError(t, err)
+		assert.Equal(t, txn.Hash, record.Hash)
+
+		again, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+		assert.Equal(t, idempotency.StatusExecuted, again.Status)
+		assert.Equal(t, txn.Version, again.Version)
+		assert.Equal(t, 1, signs)
+		assert.Len(t, server.Submitted(), 1)
+	})
+	t.Run("executed before crash", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		client := server.Client(diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		submitter := idempotency.New(client, idempotency.NewMemoryStore())
+		sender := server.GenAccount(&diemclient.Account{})
+		builder := func() *txnbuilder.Builder { return payment(sender) }
+		signs := 0
+		record, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+
+		again, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+		assert.Equal(t, idempotency.StatusExecuted, again.Status)
+		assert.Equal(t, record.Hash, again.Hash)
+		assert.Equal(t, 1, signs)
+	})
+	t.Run("resubmit pending transaction", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		client := server.Client(diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		store := idempotency.NewMemoryStore()
+		submitter := idempotency.New(client, store)
+		sender := server.GenAccount(&diemclient.Account{})
+		builder := func() *txnbuilder.Builder { return payment(sender) }
+		signs := 0
+		server.HoldTransactions(true)
+		server.Fail(diemclient.Submit, diemclienttest.NetworkError(errors.New("connection reset")))
+		record, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.Error(t, err)
+		assert.Equal(t, idempotency.StatusPending, record.Status)
+		assert.Empty(t, server.Submitted())
+
+		again, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+		assert.Equal(t, record.Hash, again.Hash)
+		_, err = submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+		assert.Equal(t, 1, signs)
+		require.Len(t, server.Submitted(), 2)
+		assert.Equal(t, record.Hash, server.Submitted()[1].TransactionHash())
+
+		require.Len(t, server.ExecutePending(), 1)
+		_, err = submitter.Wait(ctx, again)
+		require.NoError(t, err)
+		stored, err := store.Get("payout-1")
+		require.NoError(t, err)
+		assert.Equal(t, idempotency.StatusExecuted, stored.Status)
+	})
+	t.Run("sign new transaction after expired", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		client := server.Client(diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		submitter := idempotency.New(client, idempotency.NewMemoryStore())
+		sender := server.GenAccount(&diemclient.Account{})
+		builder := func() *txnbuilder.Builder { return payment(sender) }
+		signs := 0
+		server.HoldTransactions(true)
+		expired := func() *txnbuilder.Builder { return payment(sender).ExpireAt(time.Now().Add(-time.Hour)) }
+		record, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, expired))
+		require.NoError(t, err)
+
+		again, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+		assert.NotEqual(t, record.Hash, again.Hash)
+		assert.Equal(t, record.SequenceNumber, again.SequenceNumber)
+		assert.Equal(t, 2, signs)
+	})
+	t.Run("sign new transaction after sequence number is used", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		client := server.Client(diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		submitter := idempotency.New(client, idempotency.NewMemoryStore())
+		sender := server.GenAccount(&diemclient.Account{})
+		builder := func() *txnbuilder.Builder { return payment(sender) }
+		signs := 0
+		server.HoldTransactions(true)
+		record, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+		_, err = payment(sender).SequenceNumber(record.SequenceNumber).SignAndSubmit(client)
+		require.NoError(t, err)
+		require.Len(t, server.ExecutePending(), 1)
+
+		again, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+		assert.NotEqual(t, record.Hash, again.Hash)
+		assert.Equal(t, record.SequenceNumber+1, again.SequenceNumber)
+		assert.Equal(t, 2, signs)
+	})
+	t.Run("rejected", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		client := server.Client(diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		submitter := idempotency.New(client, idempotency.NewMemoryStore())
+		sender := server.GenAccount(&diemclient.Account{})
+		builder := func() *txnbuilder.Builder { return payment(sender) }
+		signs := 0
+		server.Fail(diemclient.Submit, diemclienttest.MempoolIsFull())
+		record, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.True(t, errors.Is(err, diemclient.ErrMempoolFull))
+		assert.Equal(t, idempotency.StatusRejected, record.Status)
+
+		again, err := submitter.Submit(ctx, "payout-1", sign(client, &signs, builder))
+		require.NoError(t, err)
+		assert.Equal(t, idempotency.StatusPending, again.Status)
+		assert.Equal(t, 2, signs)
+	})
+	t.Run("submitted concurrently", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		client := server.Client(diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+		store := idempotency.NewMemoryStore()
+		submitter := idempotency.New(client, store)
+		sender := server.GenAccount(&diemclient.Account{})
+		other := &idempotency.Record{Key: "payout-1", Hash: "other", Status: idempotency.StatusPending}
+		concurrent := func(ctx context.Context) (*diemtypes.SignedTransaction, error) {
+			require.NoError(t, store.Put(other))
+			return payment(sender).Context(ctx).Sign(client)
+		}
+		record, err := submitter.Submit(ctx, "payout-1", concurrent)
+		assert.Equal(t, idempotency.ErrConcurrentSubmission, err)
+		assert.Equal(t, other, record)
+		assert.Empty(t, server.Submitted())
+	})
+}
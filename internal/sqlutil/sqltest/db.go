@@ -0,0 +1,133 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides a `database/sql` driver connector serving statements by functions, for testing SQL
+// stores without a database.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DB is a `database/sql` driver connector, statements are served by `Exec` and `Query` one at a
+// time. Transactions are not isolated: statements are applied when they are executed, and
+// rollback is not supported.
+type DB struct {
+	Exec  func(query string, args []driver.Value) (driver.Result, error)
+	Query func(query string, args []driver.Value) (*Rows, error)
+
+	mux     sync.Mutex
+	queries []string
+	err     error
+}
+
+// Rows is result of a query
+type Rows struct {
+	Columns []string
+	Values  [][]driver.Value
+}
+
+// Open returns `*sql.DB` of the connector
+func (db *DB) Open() *sql.DB {
+	return sql.OpenDB(db)
+}
+
+// Queries returns all statements executed or queried
+func (db *DB) Queries() []string {
+	db.mux.Lock()
+	defer db.mux.Unlock()
+	return append([]string(nil), db.queries...)
+}
+
+// Fail makes following statements fail with the given error, nil for recovery
+func (db *DB) Fail(err error) {
+	db.mux.Lock()
+	defer db.mux.Unlock()
+	db.err = err
+}
+
+// Connect implements `driver.Connector`
+func (db *DB) Connect(context.Context) (driver.Conn, error) { return &conn{db}, nil }
+
+// Driver implements `driver.Connector`
+func (db *DB) Driver() driver.Driver { return nil }
+
+func (db *DB) exec(query string, args []driver.Value) (driver.Result, error) {
+	db.mux.Lock()
+	defer db.mux.Unlock()
+	if db.err != nil {
+		return nil, db.err
+	}
+	db.queries = append(db.queries, query)
+	if db.Exec == nil {
+		return nil, fmt.Errorf("unexpected statement: %s", query)
+	}
+	return db.Exec(query, args)
+}
+
+func (db *DB) query(query string, args []driver.Value) (driver.Rows, error) {
+	db.mux.Lock()
+	defer db.mux.Unlock()
+	if db.err != nil {
+		return nil, db.err
+	}
+	db.queries = append(db.queries, query)
+	if db.Query == nil {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	ret, err := db.Query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{Rows: *ret}, nil
+}
+
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return &stmt{c.db, query}, nil }
+func (c *conn) Close() error                              { return nil }
+func (c *conn) Begin() (driver.Tx, error)                 { return tx{}, nil }
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type stmt struct {
+	db    *DB
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.db.exec(s.query, args)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.db.query(s.query, args)
+}
+
+type rows struct {
+	Rows
+}
+
+func (r *rows) Columns() []string { return r.Rows.Columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.Values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.Values[0])
+	r.Values = r.Values[1:]
+	return nil
+}
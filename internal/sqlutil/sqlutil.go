@@ -0,0 +1,21 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides helpers shared by the SQL database stores.
+package sqlutil
+
+import "fmt"
+
+// DollarPlaceholder returns "$n" bind parameter placeholder, e.g. for PostgreSQL
+func DollarPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// Placeholder returns bind parameter placeholder for the n-th (starts from 1) parameter by the
+// given placeholder func, "?" if it is nil.
+func Placeholder(placeholder func(n int) string, n int) string {
+	if placeholder == nil {
+		return "?"
+	}
+	return placeholder(n)
+}
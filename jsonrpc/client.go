@@ -5,13 +5,13 @@ package jsonrpc
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
-	"time"
 )
 
 // Client is interface of the JSON-RPC client
@@ -20,18 +20,32 @@ type Client interface {
 	Call(...*Request) (map[RequestID]*Response, error)
 }
 
+// ContextClient is a `Client` supports cancellation and deadline by `context.Context`
+type ContextClient interface {
+	Client
+	CallWithContext(context.Context, ...*Request) (map[RequestID]*Response, error)
+}
+
+// CallWithContext calls `ContextClient.CallWithContext` if given client implements `ContextClient`,
+// otherwise calls `Client.Call` after checking the context is not done.
+func CallWithContext(ctx context.Context, c Client, requests ...*Request) (map[RequestID]*Response, error) {
+	if cc, ok := c.(ContextClient); ok {
+		return cc.CallWithContext(ctx, requests...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Call(requests...)
+}
+
 // NewClient creates a new JSON-RPC Client.
 // Creates http.Transport with 3 max idle connections and 30 seconds idle timeout, and 30 seconds connection timeout
+// HTTPOption can be used to override the round tripper, TLS config, proxy, connection pool and
+// timeout, see `NewHTTPClient`
 // NewClientWithHTTPClient can be used to override the connection timeout
 // NewClientWithTransport can be used to override the underlying transport
-func NewClient(url string) Client {
-	return NewClientWithHTTPClient(url, &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:    3,
-			IdleConnTimeout: 30 * time.Second,
-		},
-		Timeout: 30 * time.Second,
-	})
+func NewClient(url string, opts ...HTTPOption) Client {
+	return NewClientWithHTTPClient(url, NewHTTPClient(opts...))
 }
 
 // NewClientWithTransport creates a new JSON-RPC Client with given URL and
@@ -52,6 +66,11 @@ type client struct {
 
 // Call implements Client interface
 func (c *client) Call(requests ...*Request) (map[RequestID]*Response, error) {
+	return c.CallWithContext(context.Background(), requests...)
+}
+
+// CallWithContext implements ContextClient interface
+func (c *client) CallWithContext(ctx context.Context, requests ...*Request) (map[RequestID]*Response, error) {
 	switch len(requests) {
 	case 0:
 		return nil, errors.New("no requests")
@@ -62,7 +81,7 @@ func (c *client) Call(requests ...*Request) (map[RequestID]*Response, error) {
 			return nil, newError(SerializeRequestJsonError, err)
 		}
 		var resp Response
-		if err = c.httpPost(reqBody, &resp); err != nil {
+		if err = c.httpPost(ctx, reqBody, &resp); err != nil {
 			return nil, err
 		}
 		return valid(requests, &resp)
@@ -72,15 +91,20 @@ func (c *client) Call(requests ...*Request) (map[RequestID]*Response, error) {
 			return nil, newError(SerializeRequestJsonError, err)
 		}
 		var resps []*Response
-		if err = c.httpPost(reqBody, &resps); err != nil {
+		if err = c.httpPost(ctx, reqBody, &resps); err != nil {
 			return nil, err
 		}
 		return valid(requests, resps...)
 	}
 }
 
-func (c *client) httpPost(body []byte, ret interface{}) error {
-	resp, err := c.http.Post(c.url, "application/json", bytes.NewBuffer(body))
+func (c *client) httpPost(ctx context.Context, body []byte, ret interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewBuffer(body))
+	if err != nil {
+		return newError(HttpCallError, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
 	if err != nil {
 		return newError(HttpCallError, err)
 	}
@@ -4,7 +4,9 @@
 package jsonrpc_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -287,6 +289,23 @@ func TestHandleNon200Response(t *testing.T) {
 	assert.Nil(t, resps)
 }
 
+func TestCallWithContext(t *testing.T) {
+	server := serve(t, `{"jsonrpc": "2.0", "result": null, "id": 1}`, jsonrpc.NewRequest("hello"))
+	defer server.Close()
+	client := jsonrpc.NewClient(server.URL)
+
+	resps, err := jsonrpc.CallWithContext(context.Background(), client, jsonrpc.NewRequest("hello"))
+	require.NoError(t, err)
+	assert.Len(t, resps, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = jsonrpc.CallWithContext(ctx, client, jsonrpc.NewRequest("hello"))
+	require.Error(t, err)
+	assert.Equal(t, jsonrpc.HttpCallError, err.(*jsonrpc.Error).ErrorType)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
 func serve(t *testing.T, content string, expectedReqs ...*jsonrpc.Request) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "POST", r.Method)
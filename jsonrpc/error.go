@@ -32,3 +32,9 @@ func newError(t ErrorType, cause error) *Error {
 func (e *Error) Error() string {
 	return fmt.Sprintf("%s: %s", e.ErrorType, e.Cause.Error())
 }
+
+// Unwrap returns `Cause`, e.g. `context.Canceled` or `context.DeadlineExceeded` for canceled
+// context.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
@@ -0,0 +1,273 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default failover client config
+const (
+	// DefaultStaleVersionThreshold is number of versions an endpoint response ledger version can
+	// be behind the highest known version before the response is counted as a failure.
+	DefaultStaleVersionThreshold uint64 = 1_000
+	// DefaultHealthCheckInterval is default interval of `FailoverClient.RunHealthCheck`
+	DefaultHealthCheckInterval = 10 * time.Second
+
+	scoreDecay = 0.8
+)
+
+// Endpoint is a JSON-RPC server endpoint of `FailoverClient`
+type Endpoint struct {
+	URL    string
+	Client Client
+}
+
+// EndpointStatus is a snapshot of an endpoint's state
+type EndpointStatus struct {
+	URL string
+	// Score is moving average of call outcomes, 1 for all success and 0 for all failures.
+	Score float64
+	// LedgerVersion is the ledger version of the last successful response
+	LedgerVersion uint64
+	LastError     error
+}
+
+// FailoverClient is a `Client` calls multiple endpoints: a call is sent to the best scored
+// endpoint and fails over to the next one when the endpoint returns error. Errors and stale
+// responses (ledger version is behind the highest known version more than
+// `StaleVersionThreshold`) lower endpoint score, so that a flaky endpoint is deprioritized.
+// When `LoadBalance` is true, calls are distributed randomly weighted by endpoint scores.
+type FailoverClient struct {
+	StaleVersionThreshold uint64
+	LoadBalance           bool
+	// HealthCheckRequest creates request for health checking, default calls "get_metadata"
+	HealthCheckRequest func() *Request
+
+	mux       sync.Mutex
+	endpoints []*endpointState
+	highest   uint64
+	rand      *rand.Rand
+}
+
+type endpointState struct {
+	Endpoint
+	score     float64
+	version   uint64
+	lastError error
+}
+
+// NewFailoverClient creates `FailoverClient` with given endpoints, earlier endpoint has
+// higher priority when scores are same.
+func NewFailoverClient(endpoints ...Endpoint) *FailoverClient {
+	c := &FailoverClient{
+		StaleVersionThreshold: DefaultStaleVersionThreshold,
+		HealthCheckRequest:    func() *Request { return NewRequest("get_metadata") },
+		rand:                  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, e := range endpoints {
+		c.endpoints = append(c.endpoints, &endpointState{Endpoint: e, score: 1})
+	}
+	return c
+}
+
+// NewFailoverClientFromURLs creates `FailoverClient` with `NewClient` for each URL
+func NewFailoverClientFromURLs(urls ...string) *FailoverClient {
+	endpoints := make([]Endpoint, len(urls))
+	for i, url := range urls {
+		endpoints[i] = Endpoint{URL: url, Client: NewClient(url)}
+	}
+	return NewFailoverClient(endpoints...)
+}
+
+// Call implements `Client` interface
+func (c *FailoverClient) Call(requests ...*Request) (map[RequestID]*Response, error) {
+	return c.CallWithContext(context.Background(), requests...)
+}
+
+// CallWithContext implements `ContextClient` interface. A stale response is returned only when
+// no other endpoint responded successfully; returns the last error if all endpoints failed.
+func (c *FailoverClient) CallWithContext(ctx context.Context, requests ...*Request) (map[RequestID]*Response, error) {
+	endpoints := c.order()
+	if len(endpoints) == 0 {
+		return nil, errors.New("no endpoints")
+	}
+	var stale map[RequestID]*Response
+	var lastErr error
+	for _, e := range endpoints {
+		resps, err := CallWithContext(ctx, e.Client, requests...)
+		if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			return nil, err
+		}
+		if c.record(e, resps, err) {
+			return resps, nil
+		}
+		if err != nil {
+			lastErr = err
+		} else if stale == nil {
+			stale = resps
+		}
+	}
+	if stale != nil {
+		return stale, nil
+	}
+	return nil, lastErr
+}
+
+// HealthCheck calls `HealthCheckRequest` on all endpoints and updates their scores. Responses
+// are scored after all endpoints responded, so that the staleness of each response is checked
+// against the highest ledger version of the check.
+func (c *FailoverClient) HealthCheck(ctx context.Context) {
+	type result struct {
+		resps map[RequestID]*Response
+		err   error
+	}
+	endpoints := c.order()
+	results := make([]result, len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, e *endpointState) {
+			defer wg.Done()
+			resps, err := CallWithContext(ctx, e.Client, c.HealthCheckRequest())
+			results[i] = result{resps, err}
+		}(i, e)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for _, r := range results {
+		if r.err == nil {
+			c.raiseHighestLocked(ledgerVersion(r.resps))
+		}
+	}
+	for i, e := range endpoints {
+		c.recordLocked(e, results[i].resps, results[i].err)
+	}
+}
+
+// RunHealthCheck runs `HealthCheck` in given interval until the context is done
+func (c *FailoverClient) RunHealthCheck(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.HealthCheck(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status returns status of endpoints ordered by score
+func (c *FailoverClient) Status() []EndpointStatus {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ret := make([]EndpointStatus, len(c.endpoints))
+	for i, e := range c.sortedLocked() {
+		ret[i] = EndpointStatus{URL: e.URL, Score: e.score, LedgerVersion: e.version, LastError: e.lastError}
+	}
+	return ret
+}
+
+// order returns endpoints in calling order
+func (c *FailoverClient) order() []*endpointState {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	endpoints := c.sortedLocked()
+	if c.LoadBalance && len(endpoints) > 1 {
+		total := 0.0
+		for _, e := range endpoints {
+			total += e.score
+		}
+		if total > 0 {
+			pick := c.rand.Float64() * total
+			for i, e := range endpoints {
+				if pick -= e.score; pick < 0 {
+					endpoints[0], endpoints[i] = endpoints[i], endpoints[0]
+					break
+				}
+			}
+		}
+	}
+	return endpoints
+}
+
+func (c *FailoverClient) sortedLocked() []*endpointState {
+	endpoints := append([]*endpointState(nil), c.endpoints...)
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return endpoints[i].score > endpoints[j].score
+	})
+	return endpoints
+}
+
+// record updates endpoint score by call result, returns true if the call succeeded with
+// a response not stale.
+func (c *FailoverClient) record(e *endpointState, resps map[RequestID]*Response, err error) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if err == nil {
+		c.raiseHighestLocked(ledgerVersion(resps))
+	}
+	return c.recordLocked(e, resps, err)
+}
+
+// recordLocked scores the call result by the ledger version of the responses, the highest
+// version should be raised by the responses before it is called.
+func (c *FailoverClient) recordLocked(e *endpointState, resps map[RequestID]*Response, err error) bool {
+	ok := err == nil
+	if ok {
+		e.version = ledgerVersion(resps)
+		ok = e.version+c.StaleVersionThreshold >= c.highest
+		if !ok {
+			err = &StaleEndpointError{URL: e.URL, Version: e.version, Highest: c.highest}
+		}
+	}
+	e.lastError = err
+	outcome := 0.0
+	if ok {
+		outcome = 1
+	}
+	e.score = e.score*scoreDecay + outcome*(1-scoreDecay)
+	return ok
+}
+
+func (c *FailoverClient) raiseHighestLocked(version uint64) {
+	if version > c.highest {
+		c.highest = version
+	}
+}
+
+// ledgerVersion returns the highest ledger version of the responses
+func ledgerVersion(resps map[RequestID]*Response) uint64 {
+	var ret uint64
+	for _, resp := range resps {
+		if resp.DiemLedgerVersion > ret {
+			ret = resp.DiemLedgerVersion
+		}
+	}
+	return ret
+}
+
+// StaleEndpointError is recorded as endpoint last error when its ledger version is behind
+type StaleEndpointError struct {
+	URL     string
+	Version uint64
+	Highest uint64
+}
+
+// Error implements error interface
+func (e *StaleEndpointError) Error() string {
+	return fmt.Sprintf("stale endpoint %s: ledger version %d is behind %d", e.URL, e.Version, e.Highest)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endpointStub responds with given ledger version, or fails with err
+type endpointStub struct {
+	version uint64
+	err     error
+	calls   int
+}
+
+func (s *endpointStub) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	ret := make(map[jsonrpc.RequestID]*jsonrpc.Response)
+	for _, req := range requests {
+		ret[req.ID] = &jsonrpc.Response{JsonRpc: "2.0", ID: &req.ID, DiemLedgerVersion: s.version}
+	}
+	return ret, nil
+}
+
+func TestFailoverClient(t *testing.T) {
+	primary := &endpointStub{version: 100, err: errors.New("connection refused")}
+	secondary := &endpointStub{version: 100}
+	client := jsonrpc.NewFailoverClient(
+		jsonrpc.Endpoint{URL: "primary", Client: primary},
+		jsonrpc.Endpoint{URL: "secondary", Client: secondary},
+	)
+
+	resps, err := client.Call(jsonrpc.NewRequest("get_metadata"))
+	require.NoError(t, err)
+	assert.Len(t, resps, 1)
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 1, secondary.calls)
+
+	// flaky primary is deprioritized
+	status := client.Status()
+	assert.Equal(t, "secondary", status[0].URL)
+	assert.Equal(t, "primary", status[1].URL)
+	assert.EqualError(t, status[1].LastError, "connection refused")
+
+	_, err = client.Call(jsonrpc.NewRequest("get_metadata"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 2, secondary.calls)
+
+	// recovered primary is scored up by health checks
+	primary.err = nil
+	for i := 0; i < 5; i++ {
+		client.HealthCheck(context.Background())
+	}
+	secondary.err = errors.New("timeout")
+	client.HealthCheck(context.Background())
+	assert.Equal(t, "primary", client.Status()[0].URL)
+
+	primary.err = errors.New("down")
+	_, err = client.Call(jsonrpc.NewRequest("get_metadata"))
+	assert.EqualError(t, err, "timeout")
+}
+
+func TestFailoverClientStaleEndpoint(t *testing.T) {
+	stale := &endpointStub{version: 100}
+	latest := &endpointStub{version: 100 + jsonrpc.DefaultStaleVersionThreshold + 1}
+	client := jsonrpc.NewFailoverClient(
+		jsonrpc.Endpoint{URL: "stale", Client: stale},
+		jsonrpc.Endpoint{URL: "latest", Client: latest},
+	)
+	client.HealthCheck(context.Background())
+	status := client.Status()
+	assert.Equal(t, "latest", status[0].URL)
+	assert.IsType(t, &jsonrpc.StaleEndpointError{}, status[1].LastError)
+
+	resps, err := client.Call(jsonrpc.NewRequest("get_metadata"))
+	require.NoError(t, err)
+	for _, resp := range resps {
+		assert.Equal(t, latest.version, resp.DiemLedgerVersion)
+	}
+}
+
+func TestFailoverClientEndpointGoesBackwards(t *testing.T) {
+	a := &endpointStub{version: 5000}
+	b := &endpointStub{version: 5000}
+	client := jsonrpc.NewFailoverClient(
+		jsonrpc.Endpoint{URL: "a", Client: a},
+		jsonrpc.Endpoint{URL: "b", Client: b},
+	)
+	client.HealthCheck(context.Background())
+	for _, status := range client.Status() {
+		assert.NoError(t, status.LastError)
+	}
+
+	a.version = 100
+	client.HealthCheck(context.Background())
+	status := client.Status()
+	assert.Equal(t, "b", status[0].URL)
+	assert.Equal(t, "a", status[1].URL)
+	assert.Equal(t, uint64(100), status[1].LedgerVersion)
+	assert.IsType(t, &jsonrpc.StaleEndpointError{}, status[1].LastError)
+}
+
+func TestFailoverClientLoadBalance(t *testing.T) {
+	a := &endpointStub{version: 1}
+	b := &endpointStub{version: 1}
+	client := jsonrpc.NewFailoverClient(
+		jsonrpc.Endpoint{URL: "a", Client: a},
+		jsonrpc.Endpoint{URL: "b", Client: b},
+	)
+	client.LoadBalance = true
+	for i := 0; i < 100; i++ {
+		_, err := client.Call(jsonrpc.NewRequest("get_metadata"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 100, a.calls+b.calls)
+	assert.True(t, a.calls > 10)
+	assert.True(t, b.calls > 10)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonrpc
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// DefaultMaxIdleConns is the default max idle connections in total and per host of the
+	// `*http.Transport` created by `NewHTTPClient`
+	DefaultMaxIdleConns = 16
+	// DefaultIdleConnTimeout is the default idle connection timeout of the `*http.Transport`
+	// created by `NewHTTPClient`
+	DefaultIdleConnTimeout = 90 * time.Second
+	// DefaultHTTPTimeout is the default timeout of the `*http.Client` created by `NewHTTPClient`
+	DefaultHTTPTimeout = 30 * time.Second
+)
+
+// HTTPOption configures the `*http.Client` created by `NewHTTPClient` or `ConfigureHTTPClient`.
+// Options are order independent: the round tripper is chosen first, then the transport options
+// (TLS config, proxy, connection pool and HTTP/2) are applied to it.
+type HTTPOption func(*httpConfig)
+
+type httpConfig struct {
+	roundTripper http.RoundTripper
+	timeout      *time.Duration
+	transport    []func(*http.Transport)
+}
+
+func (c *httpConfig) configureTransport(fn func(*http.Transport)) {
+	c.transport = append(c.transport, fn)
+}
+
+// WithRoundTripper replaces the default `*http.Transport` by given `http.RoundTripper`, e.g. a
+// mTLS proxy transport or an instrumented transport.
+// When the round tripper is a `*http.Transport`, `WithTLSConfig`, `WithProxy` and the connection
+// pool options are applied to a clone of it. Other round trippers can not be configured by these
+// options, they are ignored; configure the transport wrapped by the round tripper instead.
+func WithRoundTripper(rt http.RoundTripper) HTTPOption {
+	return func(c *httpConfig) {
+		c.roundTripper = rt
+	}
+}
+
+// WithTLSConfig sets TLS config of the `*http.Transport`, e.g. client certificates for mTLS
+func WithTLSConfig(config *tls.Config) HTTPOption {
+	return func(c *httpConfig) {
+		c.configureTransport(func(t *http.Transport) {
+			t.TLSClientConfig = config
+		})
+	}
+}
+
+// WithProxy sets proxy function of the `*http.Transport`, e.g. `http.ProxyURL(proxyURL)`
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) HTTPOption {
+	return func(c *httpConfig) {
+		c.configureTransport(func(t *http.Transport) {
+			t.Proxy = proxy
+		})
+	}
+}
+
+// WithHTTPTimeout sets `http.Client` timeout, default is `DefaultHTTPTimeout`
+func WithHTTPTimeout(timeout time.Duration) HTTPOption {
+	return func(c *httpConfig) {
+		c.timeout = &timeout
+	}
+}
+
+// WithMaxIdleConns sets max idle (keep-alive) connections of the `*http.Transport` in total and
+// per host, default is `DefaultMaxIdleConns` for both, as the client usually talks to one host.
+// Concurrent requests beyond the per host limit open new connections and close them after use,
+// hence high-QPS submitters should set it close to their concurrency.
+func WithMaxIdleConns(total int, perHost int) HTTPOption {
+	return func(c *httpConfig) {
+		c.configureTransport(func(t *http.Transport) {
+			t.MaxIdleConns = total
+			t.MaxIdleConnsPerHost = perHost
+		})
+	}
+}
+
+// WithMaxConnsPerHost limits total connections (dialing, active and idle) per host of the
+// `*http.Transport`; requests wait for a connection when the limit is reached. Default is 0,
+// no limit.
+func WithMaxConnsPerHost(n int) HTTPOption {
+	return func(c *httpConfig) {
+		c.configureTransport(func(t *http.Transport) {
+			t.MaxConnsPerHost = n
+		})
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept alive by the `*http.Transport`,
+// default is `DefaultIdleConnTimeout`.
+func WithIdleConnTimeout(timeout time.Duration) HTTPOption {
+	return func(c *httpConfig) {
+		c.configureTransport(func(t *http.Transport) {
+			t.IdleConnTimeout = timeout
+		})
+	}
+}
+
+// WithHTTP2 enables or disables HTTP/2 of the `*http.Transport`, default is enabled. HTTP/2 is
+// only negotiated over TLS, and multiplexes concurrent requests over one connection per host.
+func WithHTTP2(enabled bool) HTTPOption {
+	return func(c *httpConfig) {
+		c.configureTransport(func(t *http.Transport) {
+			t.ForceAttemptHTTP2 = enabled
+			if !enabled {
+				t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+			}
+		})
+	}
+}
+
+// NewHTTPClient creates `*http.Client` used by `NewClient`: `*http.Transport` with
+// `DefaultMaxIdleConns` max idle connections in total and per host, `DefaultIdleConnTimeout` idle
+// timeout and HTTP/2 enabled; and `DefaultHTTPTimeout` timeout.
+func NewHTTPClient(opts ...HTTPOption) *http.Client {
+	return ConfigureHTTPClient(&http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:        DefaultMaxIdleConns,
+			MaxIdleConnsPerHost: DefaultMaxIdleConns,
+			IdleConnTimeout:     DefaultIdleConnTimeout,
+			ForceAttemptHTTP2:   true,
+		},
+		Timeout: DefaultHTTPTimeout,
+	}, opts...)
+}
+
+// ConfigureHTTPClient returns a copy of the given `*http.Client` configured by the options, the
+// given client and its transport are not modified. Transport options are applied to a clone of
+// the client transport (or the `WithRoundTripper` round tripper) when it is a `*http.Transport`,
+// or `http.DefaultTransport` when it is nil; otherwise they are ignored, see `IgnoresHTTPOptions`.
+func ConfigureHTTPClient(httpClient *http.Client, opts ...HTTPOption) *http.Client {
+	config := newHTTPConfig(httpClient, opts)
+	ret := *httpClient
+	ret.Transport = config.roundTripper
+	if config.timeout != nil {
+		ret.Timeout = *config.timeout
+	}
+	if len(config.transport) == 0 {
+		return &ret
+	}
+	if ret.Transport == nil {
+		ret.Transport = http.DefaultTransport
+	}
+	if t, ok := ret.Transport.(*http.Transport); ok {
+		t = t.Clone()
+		for _, fn := range config.transport {
+			fn(t)
+		}
+		ret.Transport = t
+	}
+	return &ret
+}
+
+// IgnoresHTTPOptions returns true if `ConfigureHTTPClient` ignores transport options of the
+// given options for the `*http.Client`, i.e. the round tripper is not a `*http.Transport`.
+func IgnoresHTTPOptions(httpClient *http.Client, opts ...HTTPOption) bool {
+	config := newHTTPConfig(httpClient, opts)
+	if len(config.transport) == 0 || config.roundTripper == nil {
+		return false
+	}
+	_, ok := config.roundTripper.(*http.Transport)
+	return !ok
+}
+
+func newHTTPConfig(httpClient *http.Client, opts []HTTPOption) *httpConfig {
+	config := &httpConfig{roundTripper: httpClient.Transport}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
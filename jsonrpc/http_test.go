@@ -0,0 +1,163 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonrpc_test
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type headerRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("X-Test", "hello")
+	return rt.next.RoundTrip(req)
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		c := jsonrpc.NewHTTPClient()
+		assert.Equal(t, 30*time.Second, c.Timeout)
+		transport, ok := c.Transport.(*http.Transport)
+		require.True(t, ok)
+		assert.Equal(t, jsonrpc.DefaultMaxIdleConns, transport.MaxIdleConns)
+		assert.Equal(t, jsonrpc.DefaultMaxIdleConns, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, jsonrpc.DefaultIdleConnTimeout, transport.IdleConnTimeout)
+		assert.True(t, transport.ForceAttemptHTTP2)
+	})
+	t.Run("connection pool", func(t *testing.T) {
+		c := jsonrpc.NewHTTPClient(
+			jsonrpc.WithMaxIdleConns(100, 50),
+			jsonrpc.WithMaxConnsPerHost(64),
+			jsonrpc.WithIdleConnTimeout(time.Minute),
+			jsonrpc.WithHTTP2(false),
+		)
+		transport := c.Transport.(*http.Transport)
+		assert.Equal(t, 100, transport.MaxIdleConns)
+		assert.Equal(t, 50, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, 64, transport.MaxConnsPerHost)
+		assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+		assert.False(t, transport.ForceAttemptHTTP2)
+		assert.NotNil(t, transport.TLSNextProto)
+	})
+	t.Run("connection pool options ignored by custom round tripper", func(t *testing.T) {
+		rt := &headerRoundTripper{http.DefaultTransport}
+		c := jsonrpc.NewHTTPClient(
+			jsonrpc.WithRoundTripper(rt),
+			jsonrpc.WithMaxIdleConns(100, 50),
+			jsonrpc.WithHTTP2(false),
+		)
+		assert.Same(t, rt, c.Transport)
+		assert.True(t, jsonrpc.IgnoresHTTPOptions(c, jsonrpc.WithMaxIdleConns(100, 50)))
+		assert.False(t, jsonrpc.IgnoresHTTPOptions(c, jsonrpc.WithHTTPTimeout(time.Second)))
+	})
+	t.Run("transport options apply to given transport in any order", func(t *testing.T) {
+		given := &http.Transport{}
+		config := &tls.Config{ServerName: "diem"}
+		for _, opts := range [][]jsonrpc.HTTPOption{
+			{jsonrpc.WithTLSConfig(config), jsonrpc.WithMaxIdleConns(8, 4), jsonrpc.WithRoundTripper(given)},
+			{jsonrpc.WithRoundTripper(given), jsonrpc.WithTLSConfig(config), jsonrpc.WithMaxIdleConns(8, 4)},
+		} {
+			c := jsonrpc.NewHTTPClient(opts...)
+			transport := c.Transport.(*http.Transport)
+			assert.NotSame(t, given, transport)
+			assert.Same(t, config, transport.TLSClientConfig)
+			assert.Equal(t, 8, transport.MaxIdleConns)
+			assert.Equal(t, 4, transport.MaxIdleConnsPerHost)
+		}
+		assert.Equal(t, 0, given.MaxIdleConns)
+	})
+	t.Run("configure given http client", func(t *testing.T) {
+		given := &http.Client{Timeout: time.Minute}
+		c := jsonrpc.ConfigureHTTPClient(given, jsonrpc.WithMaxConnsPerHost(64))
+		assert.Equal(t, time.Minute, c.Timeout)
+		assert.Equal(t, 64, c.Transport.(*http.Transport).MaxConnsPerHost)
+		assert.Nil(t, given.Transport)
+
+		c = jsonrpc.ConfigureHTTPClient(given, jsonrpc.WithHTTPTimeout(time.Second))
+		assert.Equal(t, time.Second, c.Timeout)
+		assert.Nil(t, c.Transport)
+	})
+	t.Run("tls config, proxy and timeout", func(t *testing.T) {
+		config := &tls.Config{ServerName: "diem"}
+		proxyURL, _ := url.Parse("http://proxy:8080")
+		c := jsonrpc.NewHTTPClient(
+			jsonrpc.WithTLSConfig(config),
+			jsonrpc.WithProxy(http.ProxyURL(proxyURL)),
+			jsonrpc.WithHTTPTimeout(time.Second),
+		)
+		assert.Equal(t, time.Second, c.Timeout)
+		transport := c.Transport.(*http.Transport)
+		assert.Same(t, config, transport.TLSClientConfig)
+		proxy, err := transport.Proxy(httptest.NewRequest(http.MethodPost, "http://diem", nil))
+		require.NoError(t, err)
+		assert.Equal(t, proxyURL, proxy)
+	})
+	t.Run("round tripper", func(t *testing.T) {
+		var header string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Get("X-Test")
+			w.Write([]byte(`{"jsonrpc": "2.0", "result": null, "id": 1}`))
+		}))
+		defer server.Close()
+
+		client := jsonrpc.NewClient(server.URL, jsonrpc.WithRoundTripper(&headerRoundTripper{http.DefaultTransport}))
+		_, err := client.Call(jsonrpc.NewRequest("get_metadata"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", header)
+	})
+}
+
+// BenchmarkHTTPClient compares connection pool settings under concurrent requests, e.g.
+// `go test ./jsonrpc -run none -bench HTTPClient -cpu 64`. With idle connections fewer than
+// concurrent requests, connections are closed and redialed, which shows as more `conns/op`.
+func BenchmarkHTTPClient(b *testing.B) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc": "2.0", "result": null, "id": 1}`))
+	}))
+	var conns int64
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	cases := []struct {
+		name string
+		opts []jsonrpc.HTTPOption
+	}{
+		{"2 idle conns", []jsonrpc.HTTPOption{jsonrpc.WithMaxIdleConns(2, 2)}},
+		{"default", nil},
+		{"128 idle conns", []jsonrpc.HTTPOption{jsonrpc.WithMaxIdleConns(128, 128)}},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			client := jsonrpc.NewClient(server.URL, tc.opts...)
+			atomic.StoreInt64(&conns, 0)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := client.Call(jsonrpc.NewRequest("get_metadata")); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+			b.ReportMetric(float64(atomic.LoadInt64(&conns))/float64(b.N), "conns/op")
+		})
+	}
+}
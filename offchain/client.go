@@ -0,0 +1,245 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// BaseURLResolver resolves off-chain API base url of a VASP account
+type BaseURLResolver interface {
+	BaseURL(address diemtypes.AccountAddress) (string, error)
+}
+
+// OnChainBaseURLResolver resolves base url from the DualAttestation::Credential of the parent
+// VASP account of given address by `diemclient.GetParentVASPInfo`.
+type OnChainBaseURLResolver struct {
+	Client diemclient.Client
+}
+
+// BaseURL implements `BaseURLResolver`
+func (r *OnChainBaseURLResolver) BaseURL(address diemtypes.AccountAddress) (string, error) {
+	info, err := diemclient.GetParentVASPInfo(r.Client, address)
+	if err != nil {
+		return "", err
+	}
+	if info.BaseURL == "" {
+		return "", fmt.Errorf("base url of %s is not set", address.Hex())
+	}
+	return info.BaseURL, nil
+}
+
+// Client is off-chain API client
+type Client struct {
+	// SenderAddress is account identifier (LIP-5) of the request sender
+	SenderAddress string
+	HTTP          *http.Client
+	// ComplianceKey signs request body as JWS message when it is set
+	ComplianceKey ed25519.PrivateKey
+	// Resolver resolves counterparty base url for `SendCommandTo` and `SendPaymentCommand`
+	Resolver BaseURLResolver
+	// Verifier verifies JWS response signed by counterparty compliance key for
+	// `SendCommandTo` and `SendPaymentCommand`, it is required by both
+	Verifier *Verifier
+}
+
+// NewClient creates off-chain API `Client` with given sender account identifier
+func NewClient(senderAddress string) *Client {
+	return &Client{
+		SenderAddress: senderAddress,
+		HTTP:          &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// WithComplianceKey sets compliance key for signing requests as JWS messages
+func (c *Client) WithComplianceKey(key ed25519.PrivateKey) *Client {
+	c.ComplianceKey = key
+	return c
+}
+
+// WithResolver sets `BaseURLResolver` for resolving counterparty base url
+func (c *Client) WithResolver(resolver BaseURLResolver) *Client {
+	c.Resolver = resolver
+	return c
+}
+
+// WithVerifier sets `Verifier` for verifying JWS responses signed by counterparty
+func (c *Client) WithVerifier(verifier *Verifier) *Client {
+	c.Verifier = verifier
+	return c
+}
+
+// Ping sends `PingCommand` to the counterparty service of given base url, and returns the
+// counterparty capabilities.
+func (c *Client) Ping(ctx context.Context, baseURL string) (*Capabilities, error) {
+	resp, err := c.SendCommand(ctx, baseURL, PingCommandType, &PingCommand{ObjectType: PingCommandType})
+	if err != nil {
+		return nil, err
+	}
+	var result PingCommandResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("decode ping command response result failed: %v", err)
+	}
+	return &Capabilities{
+		Versions:     result.SupportedVersions,
+		CommandTypes: result.SupportedCommandTypes,
+	}, nil
+}
+
+// Handshake pings counterparty service, and negotiates protocol version.
+// Returns error if there is no common protocol version or the counterparty does not support
+// any of the given required command types.
+func (c *Client) Handshake(ctx context.Context, baseURL string, requiredCommandTypes ...string) (string, *Capabilities, error) {
+	capabilities, err := c.Ping(ctx, baseURL)
+	if err != nil {
+		return "", nil, err
+	}
+	version, err := NegotiateVersion(SupportedVersions, capabilities.Versions)
+	if err != nil {
+		return "", capabilities, err
+	}
+	for _, t := range requiredCommandTypes {
+		if !capabilities.SupportsCommandType(t) {
+			return "", capabilities, fmt.Errorf("counterparty does not support command type: %s", t)
+		}
+	}
+	return version, capabilities, nil
+}
+
+// SendPaymentCommand sends `PaymentCommand` to the VASP of given counterparty address, the
+// base url is resolved by `Client.Resolver`.
+func (c *Client) SendPaymentCommand(ctx context.Context, counterparty diemtypes.AccountAddress, command *PaymentCommand) (*CommandResponseObject, error) {
+	return c.SendCommandTo(ctx, counterparty, PaymentCommandType, command)
+}
+
+// SendCommandTo sends a command request to the VASP of given counterparty address, the base
+// url is resolved by `Client.Resolver`.
+// The response must be JWS message signed by the counterparty compliance key, returns error
+// without sending the request if `Client.Verifier` is not set.
+func (c *Client) SendCommandTo(ctx context.Context, counterparty diemtypes.AccountAddress, commandType string, command interface{}) (*CommandResponseObject, error) {
+	if c.Verifier == nil {
+		return nil, fmt.Errorf("response verifier is not set")
+	}
+	if c.Resolver == nil {
+		return nil, fmt.Errorf("base url resolver is not set")
+	}
+	baseURL, err := c.Resolver.BaseURL(counterparty)
+	if err != nil {
+		return nil, fmt.Errorf("resolve base url of %s failed: %v", counterparty.Hex(), err)
+	}
+	return c.send(ctx, baseURL, &counterparty, commandType, command)
+}
+
+// SendCommand sends a command request to the counterparty service, and returns the response.
+// Returns `*Error` if the response status is failure.
+// The response is not verified, as the counterparty account is unknown; it should only be
+// used for commands that do not change any state, e.g. `PingCommand`.
+func (c *Client) SendCommand(ctx context.Context, baseURL string, commandType string, command interface{}) (*CommandResponseObject, error) {
+	return c.send(ctx, baseURL, nil, commandType, command)
+}
+
+func (c *Client) send(ctx context.Context, baseURL string, counterparty *diemtypes.AccountAddress, commandType string, command interface{}) (*CommandResponseObject, error) {
+	commandJSON, err := json.Marshal(command)
+	if err != nil {
+		return nil, fmt.Errorf("encode command failed: %v", err)
+	}
+	cid, err := NewReferenceID()
+	if err != nil {
+		return nil, fmt.Errorf("generate command request cid failed: %v", err)
+	}
+	request := CommandRequestObject{
+		ObjectType:  CommandRequestObjectType,
+		CommandType: commandType,
+		Command:     commandJSON,
+		Cid:         cid.String(),
+	}
+	body, err := json.Marshal(&request)
+	if err != nil {
+		return nil, fmt.Errorf("encode command request failed: %v", err)
+	}
+	if c.ComplianceKey != nil {
+		body = []byte(SignJWS(body, c.ComplianceKey))
+	}
+	respBody, err := c.post(ctx, commandURL(baseURL), request.Cid, body)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err = c.decodeResponse(counterparty, respBody)
+	if err != nil {
+		return nil, err
+	}
+	var resp CommandResponseObject
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("decode command response failed: %v", err)
+	}
+	// failure response may not have cid when the request can't be decoded by the counterparty
+	if resp.Cid != request.Cid && (resp.Status == StatusSuccess || resp.Cid != "") {
+		return nil, fmt.Errorf("command response cid %q does not match request cid %s", resp.Cid, request.Cid)
+	}
+	if resp.Status != StatusSuccess {
+		if resp.Error == nil {
+			return nil, fmt.Errorf("command failed with unknown error, status: %s", resp.Status)
+		}
+		return nil, resp.Error
+	}
+	return &resp, nil
+}
+
+// decodeResponse verifies the response body when counterparty is known; otherwise a JWS
+// response payload is decoded without verification.
+func (c *Client) decodeResponse(counterparty *diemtypes.AccountAddress, body []byte) ([]byte, error) {
+	if counterparty != nil {
+		payload, err := c.Verifier.Verify(*counterparty, string(body))
+		if err != nil {
+			return nil, fmt.Errorf("verify command response failed: %v", err)
+		}
+		return payload, nil
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] != '{' {
+		jws, err := DecodeJWS(string(trimmed))
+		if err != nil {
+			return nil, fmt.Errorf("decode command response failed: %v", err)
+		}
+		return jws.Payload, nil
+	}
+	return body, nil
+}
+
+func (c *Client) post(ctx context.Context, url string, requestID string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set(RequestIDHeader, requestID)
+	req.Header.Set(RequestSenderHeader, c.SenderAddress)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	ret, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return nil, fmt.Errorf("unexpected http response: %d, %s", resp.StatusCode, string(ret))
+	}
+	return ret, nil
+}
+
+func commandURL(baseURL string) string {
+	return fmt.Sprintf("%s/%s/command", strings.TrimRight(baseURL, "/"), V2)
+}
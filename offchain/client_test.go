@@ -0,0 +1,97 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const senderAddress = "tdm1p7ujcndcl7nudzwt8fglhx6wxnvqqqqqqqqqqqqqv88j4s"
+
+func TestPing(t *testing.T) {
+	server := offchain.NewServer()
+	server.Handle("PaymentCommand", func(r *http.Request, req *offchain.CommandRequestObject) (interface{}, *offchain.Error) {
+		return nil, nil
+	})
+	s := httptest.NewServer(server)
+	defer s.Close()
+
+	client := offchain.NewClient(senderAddress)
+	capabilities, err := client.Ping(context.Background(), s.URL)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2"}, capabilities.Versions)
+	assert.Equal(t, []string{"PaymentCommand", "PingCommand"}, capabilities.CommandTypes)
+}
+
+func TestHandshake(t *testing.T) {
+	s := httptest.NewServer(offchain.NewServer())
+	defer s.Close()
+	client := offchain.NewClient(senderAddress)
+
+	version, capabilities, err := client.Handshake(context.Background(), s.URL, "PingCommand")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", version)
+	assert.True(t, capabilities.SupportsCommandType("PingCommand"))
+
+	_, _, err = client.Handshake(context.Background(), s.URL, "PaymentCommand")
+	assert.EqualError(t, err, "counterparty does not support command type: PaymentCommand")
+}
+
+func TestSendCommandErrors(t *testing.T) {
+	s := httptest.NewServer(offchain.NewServer())
+	defer s.Close()
+
+	t.Run("unknown command type", func(t *testing.T) {
+		client := offchain.NewClient(senderAddress)
+		_, err := client.SendCommand(context.Background(), s.URL, "Unknown", map[string]string{})
+		require.Error(t, err)
+		offchainErr, ok := err.(*offchain.Error)
+		require.True(t, ok)
+		assert.Equal(t, offchain.ProtocolErrorType, offchainErr.Type)
+		assert.Equal(t, offchain.UnknownCommandTypeErrorCode, offchainErr.Code)
+	})
+	t.Run("missing sender address header", func(t *testing.T) {
+		client := offchain.NewClient("")
+		_, err := client.Ping(context.Background(), s.URL)
+		require.Error(t, err)
+		assert.Equal(t, offchain.MissingHTTPHeaderErrorCode, err.(*offchain.Error).Code)
+	})
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := offchain.NewClient(senderAddress).Ping(ctx, s.URL)
+		assert.Error(t, err)
+	})
+}
+
+func TestServerRequestBodyTooLarge(t *testing.T) {
+	server := offchain.NewServer().WithMaxRequestBodySize(64)
+	send := func(size int) int {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(bytes.Repeat([]byte{'{'}, size)))
+		req.Header.Set(offchain.RequestIDHeader, "request-id")
+		req.Header.Set(offchain.RequestSenderHeader, senderAddress)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send(65))
+	assert.Equal(t, http.StatusBadRequest, send(64), "invalid json")
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	version, err := offchain.NegotiateVersion([]string{"v1", "v2", "v3"}, []string{"v3", "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, "v3", version)
+
+	_, err = offchain.NegotiateVersion([]string{"v2"}, []string{"v1"})
+	assert.EqualError(t, err, "no common protocol version: local [v2], remote [v1]")
+}
@@ -0,0 +1,6 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides off-chain API client and server primitives
+// (https://github.com/diem/lip/blob/master/lips/lip-1.mdx).
+package offchain
@@ -0,0 +1,84 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// JWSAlgorithm is the JWS "alg" header value of ed25519 signature
+const JWSAlgorithm = "EdDSA"
+
+var jwsHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA"}`))
+
+// JWSMessage is decoded JWS compact serialization message
+type JWSMessage struct {
+	Header       map[string]interface{}
+	Payload      []byte
+	Signature    []byte
+	SigningInput []byte
+}
+
+// SignJWS signs the payload with ed25519 private key and returns JWS compact serialization
+func SignJWS(payload []byte, key ed25519.PrivateKey) string {
+	signingInput := jwsHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig := ed25519.Sign(key, []byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// DecodeJWS decodes JWS compact serialization message without verifying signature.
+// Returns error if the message is malformed or the "alg" header is not "EdDSA".
+func DecodeJWS(msg string) (*JWSMessage, error) {
+	parts := strings.Split(strings.TrimSpace(msg), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid jws: expected 3 parts")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid jws header: %v", err)
+	}
+	var header map[string]interface{}
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("invalid jws header: %v", err)
+	}
+	if header["alg"] != JWSAlgorithm {
+		return nil, fmt.Errorf("invalid jws header: unsupported alg %v", header["alg"])
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid jws payload: %v", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid jws signature: %v", err)
+	}
+	return &JWSMessage{
+		Header:       header,
+		Payload:      payload,
+		Signature:    sig,
+		SigningInput: []byte(parts[0] + "." + parts[1]),
+	}, nil
+}
+
+// Verify verifies the message signature by given public key
+func (m *JWSMessage) Verify(key ed25519.PublicKey) bool {
+	return len(key) == ed25519.PublicKeySize && ed25519.Verify(key, m.SigningInput, m.Signature)
+}
+
+// VerifyJWS decodes and verifies JWS compact serialization message, returns payload.
+func VerifyJWS(msg string, key ed25519.PublicKey) ([]byte, error) {
+	jws, err := DecodeJWS(msg)
+	if err != nil {
+		return nil, err
+	}
+	if !jws.Verify(key) {
+		return nil, errors.New("invalid jws signature")
+	}
+	return jws.Payload, nil
+}
@@ -0,0 +1,155 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import "time"
+
+// PaymentCommandType is the command type and object type of `PaymentCommand`
+const PaymentCommandType = "PaymentCommand"
+
+// Payment actor status, see `StatusObject`
+const (
+	StatusNone                    = "none"
+	StatusNeedsKycData            = "needs_kyc_data"
+	StatusNeedsRecipientSignature = "needs_recipient_signature"
+	StatusReadyForSettlement      = "ready_for_settlement"
+	StatusAbort                   = "abort"
+	StatusSoftMatch               = "soft_match"
+	StatusPendingReview           = "pending_review"
+)
+
+// Payment abort codes
+const (
+	AbortCodeNoKycNeeded = "no-kyc-needed"
+	AbortCodeRejected    = "rejected"
+)
+
+// KYC data types
+const (
+	KycDataTypeIndividual = "individual"
+	KycDataTypeEntity     = "entity"
+)
+
+// PaymentActionCharge is the only supported payment action
+const PaymentActionCharge = "charge"
+
+// PaymentCommand is the command for exchanging KYC data and recipient signature of a travel
+// rule payment between the sender and receiver VASPs.
+type PaymentCommand struct {
+	ObjectType string        `json:"_ObjectType"`
+	Payment    PaymentObject `json:"payment"`
+}
+
+// PaymentObject is the payment shared by the sender and receiver VASPs, identified by
+// `ReferenceID`.
+type PaymentObject struct {
+	Sender                     PaymentActorObject  `json:"sender"`
+	Receiver                   PaymentActorObject  `json:"receiver"`
+	ReferenceID                string              `json:"reference_id"`
+	OriginalPaymentReferenceID string              `json:"original_payment_reference_id,omitempty"`
+	RecipientSignature         string              `json:"recipient_signature,omitempty"`
+	Action                     PaymentActionObject `json:"action"`
+	Description                string              `json:"description,omitempty"`
+}
+
+// PaymentActorObject is the sender or receiver of a payment, `Address` is account identifier
+// (LIP-5) with sub-address.
+type PaymentActorObject struct {
+	Address           string         `json:"address"`
+	KycData           *KycDataObject `json:"kyc_data,omitempty"`
+	Status            StatusObject   `json:"status"`
+	Metadata          []string       `json:"metadata,omitempty"`
+	AdditionalKycData string         `json:"additional_kyc_data,omitempty"`
+}
+
+// KycDataObject is KYC data of an individual or entity
+type KycDataObject struct {
+	PayloadVersion  int               `json:"payload_version"`
+	Type            string            `json:"type"`
+	GivenName       string            `json:"given_name,omitempty"`
+	Surname         string            `json:"surname,omitempty"`
+	Address         *AddressObject    `json:"address,omitempty"`
+	DOB             string            `json:"dob,omitempty"`
+	PlaceOfBirth    *AddressObject    `json:"place_of_birth,omitempty"`
+	NationalID      *NationalIDObject `json:"national_id,omitempty"`
+	LegalEntityName string            `json:"legal_entity_name,omitempty"`
+}
+
+// AddressObject is a physical address
+type AddressObject struct {
+	City       string `json:"city,omitempty"`
+	Country    string `json:"country,omitempty"`
+	Line1      string `json:"line1,omitempty"`
+	Line2      string `json:"line2,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	State      string `json:"state,omitempty"`
+}
+
+// NationalIDObject is a national identity document
+type NationalIDObject struct {
+	IDValue string `json:"id_value"`
+	Country string `json:"country,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// StatusObject is payment actor status, `AbortCode` and `AbortMessage` are provided when
+// `Status` is `StatusAbort`.
+type StatusObject struct {
+	Status       string `json:"status"`
+	AbortCode    string `json:"abort_code,omitempty"`
+	AbortMessage string `json:"abort_message,omitempty"`
+}
+
+// PaymentActionObject is the payment amount and currency
+type PaymentActionObject struct {
+	Amount    uint64 `json:"amount"`
+	Currency  string `json:"currency"`
+	Action    string `json:"action"`
+	Timestamp uint64 `json:"timestamp"`
+}
+
+// NewPaymentCommand creates `PaymentCommand` for initializing a payment from sender to
+// receiver account identifiers with a new reference id.
+// The sender status is `StatusNeedsKycData` for requesting receiver KYC data, and the receiver
+// status is `StatusNone`.
+func NewPaymentCommand(sender, receiver string, amount uint64, currency string, senderKycData *KycDataObject) *PaymentCommand {
+	return &PaymentCommand{
+		ObjectType: PaymentCommandType,
+		Payment: PaymentObject{
+			Sender: PaymentActorObject{
+				Address: sender,
+				KycData: senderKycData,
+				Status:  StatusObject{Status: StatusNeedsKycData},
+			},
+			Receiver: PaymentActorObject{
+				Address: receiver,
+				Status:  StatusObject{Status: StatusNone},
+			},
+			ReferenceID: mustNewReferenceID(),
+			Action: PaymentActionObject{
+				Amount:    amount,
+				Currency:  currency,
+				Action:    PaymentActionCharge,
+				Timestamp: uint64(time.Now().Unix()),
+			},
+		},
+	}
+}
+
+// ReferenceID returns the payment reference id
+func (c *PaymentCommand) ReferenceID() string {
+	return c.Payment.ReferenceID
+}
+
+// IsAborted returns true if sender or receiver aborted the payment
+func (p *PaymentObject) IsAborted() bool {
+	return p.Sender.Status.Status == StatusAbort || p.Receiver.Status.Status == StatusAbort
+}
+
+// IsReadyForSettlement returns true if the sender is ready for settlement, which means
+// the receiver had provided KYC data and recipient signature and the sender can submit the
+// payment transaction.
+func (p *PaymentObject) IsReadyForSettlement() bool {
+	return p.Sender.Status.Status == StatusReadyForSettlement && !p.IsAborted()
+}
@@ -0,0 +1,135 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// PaymentStore stores the latest payment object of each reference id; UUID reference ids are
+// case insensitive, implementations should key payments by `NormalizeReferenceID`.
+type PaymentStore interface {
+	// Get returns payment of given reference id, returns nil without error if not found
+	Get(referenceID string) (*PaymentObject, error)
+	// Put saves the payment, overwrites existing payment of same reference id
+	Put(payment *PaymentObject) error
+}
+
+// MemoryPaymentStore implements `PaymentStore` in memory, payments are lost after restart.
+// Payments are keyed by `NormalizeReferenceID` of the reference id.
+type MemoryPaymentStore struct {
+	mux      sync.RWMutex
+	payments map[string]*PaymentObject
+}
+
+// NewMemoryPaymentStore creates `MemoryPaymentStore`
+func NewMemoryPaymentStore() *MemoryPaymentStore {
+	return &MemoryPaymentStore{payments: make(map[string]*PaymentObject)}
+}
+
+// Get implements `PaymentStore`
+func (s *MemoryPaymentStore) Get(referenceID string) (*PaymentObject, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.payments[NormalizeReferenceID(referenceID)], nil
+}
+
+// Put implements `PaymentStore`
+func (s *MemoryPaymentStore) Put(payment *PaymentObject) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.payments[NormalizeReferenceID(payment.ReferenceID)] = payment
+	return nil
+}
+
+// PaymentCallback is called with the stored prior payment (nil for a new payment) and the
+// inbound payment after it passed the state transition validation; returning `*Error` rejects
+// the payment and it is not saved.
+type PaymentCallback func(prior, payment *PaymentObject) *Error
+
+// PaymentHandler handles inbound `PaymentCommand`: it validates the command producer is the
+// request sender verified by the `Server` verifier (see `Server.WithVerifier`), validates the
+// payment state transition from the stored prior payment, calls the callback, and then saves
+// the payment into the store. Store failures are responded with HTTP status 500, see
+// `NewInternalError`.
+type PaymentHandler struct {
+	Store    PaymentStore
+	Prefix   diemid.NetworkPrefix
+	Callback PaymentCallback
+
+	mux sync.Mutex
+}
+
+// NewPaymentHandler creates `PaymentHandler`; account identifiers of the payment actors are
+// decoded with the given network prefix. The callback can be nil.
+func NewPaymentHandler(store PaymentStore, prefix diemid.NetworkPrefix, callback PaymentCallback) *PaymentHandler {
+	return &PaymentHandler{Store: store, Prefix: prefix, Callback: callback}
+}
+
+// Handle is `CommandHandler` of `PaymentCommandType`, register it by
+// `server.Handle(offchain.PaymentCommandType, handler.Handle)`
+func (h *PaymentHandler) Handle(r *http.Request, request *CommandRequestObject) (interface{}, *Error) {
+	var command PaymentCommand
+	if err := json.Unmarshal(request.Command, &command); err != nil {
+		return nil, NewCommandError(InvalidObjectErrorCode, "command", err.Error())
+	}
+	if command.ObjectType != PaymentCommandType {
+		return nil, NewCommandError(InvalidObjectErrorCode, "command._ObjectType", "invalid _ObjectType: "+command.ObjectType)
+	}
+	payment := &command.Payment
+	if payment.ReferenceID == "" {
+		return nil, NewCommandError(MissingFieldErrorCode, "payment.reference_id", "missing reference_id")
+	}
+	sender, ok := VerifiedSender(r)
+	if !ok {
+		return nil, NewProtocolError(InvalidJWSErrorCode, "request is not verified, server verifier is not set")
+	}
+	if err := h.validateProducer(sender, payment); err != nil {
+		return nil, err
+	}
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	prior, err := h.Store.Get(payment.ReferenceID)
+	if err != nil {
+		return nil, NewInternalError("read payment failed: " + err.Error())
+	}
+	if err := ValidatePaymentTransition(prior, payment); err != nil {
+		return nil, err
+	}
+	if h.Callback != nil {
+		if err := h.Callback(prior, payment); err != nil {
+			return nil, err
+		}
+	}
+	if err := h.Store.Put(payment); err != nil {
+		return nil, NewInternalError("save payment failed: " + err.Error())
+	}
+	return nil, nil
+}
+
+func (h *PaymentHandler) validateProducer(sender diemtypes.AccountAddress, payment *PaymentObject) *Error {
+	state, err := PaymentStateOf(payment)
+	if err != nil {
+		return NewCommandError(InvalidFieldValueErrorCode, "payment.sender.status.status", err.Error())
+	}
+	field, producer := "payment.receiver.address", payment.Receiver.Address
+	if state.IsProducedBySender() {
+		field, producer = "payment.sender.address", payment.Sender.Address
+	}
+	producerAccount, err := diemid.DecodeToAccount(h.Prefix, producer)
+	if err != nil {
+		return NewCommandError(InvalidFieldValueErrorCode, field, err.Error())
+	}
+	if producerAccount.AccountAddress != sender {
+		return NewCommandError(InvalidCommandProducerErrorCode, field,
+			"payment state "+string(state)+" must be produced by "+producer)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPaymentStore(t *testing.T) {
+	store := offchain.NewMemoryPaymentStore()
+	payment, err := store.Get("ref")
+	require.NoError(t, err)
+	assert.Nil(t, payment)
+
+	expected := &offchain.PaymentObject{ReferenceID: "ref"}
+	require.NoError(t, store.Put(expected))
+	payment, err = store.Get("ref")
+	require.NoError(t, err)
+	assert.Equal(t, expected, payment)
+}
+
+// failingPaymentStore fails all operations
+type failingPaymentStore struct{}
+
+func (failingPaymentStore) Get(string) (*offchain.PaymentObject, error) {
+	return nil, errors.New("connection lost")
+}
+
+func (failingPaymentStore) Put(*offchain.PaymentObject) error {
+	return errors.New("connection lost")
+}
+
+func TestPaymentHandler(t *testing.T) {
+	senderAccount := diemtypes.AccountAddress{1}
+	receiverAccount := diemtypes.AccountAddress{2}
+	sender, err := diemid.EncodeAccount(diemid.TestnetPrefix, senderAccount, diemtypes.SubAddress{1})
+	require.NoError(t, err)
+	receiver, err := diemid.EncodeAccount(diemid.TestnetPrefix, receiverAccount, diemtypes.SubAddress{2})
+	require.NoError(t, err)
+	senderPublic, senderPrivate := genKey(t)
+	receiverPublic, receiverPrivate := genKey(t)
+
+	store := offchain.NewMemoryPaymentStore()
+	var callbacks int
+	handler := offchain.NewPaymentHandler(store, diemid.TestnetPrefix, func(prior, payment *offchain.PaymentObject) *offchain.Error {
+		callbacks++
+		return nil
+	})
+	server := offchain.NewServer().
+		WithVerifier(offchain.NewVerifier(&keyResolver{key: senderPublic}), diemid.TestnetPrefix).
+		WithSigningKey(receiverPrivate)
+	server.Handle(offchain.PaymentCommandType, handler.Handle)
+	s := httptest.NewServer(server)
+	defer s.Close()
+
+	client := offchain.NewClient(sender).
+		WithComplianceKey(senderPrivate).
+		WithResolver(&baseURLResolver{url: s.URL}).
+		WithVerifier(offchain.NewVerifier(&keyResolver{key: receiverPublic}))
+
+	cmd := offchain.NewPaymentCommand(sender, receiver, 100, "XUS", nil)
+	_, err = client.SendPaymentCommand(context.Background(), receiverAccount, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, 1, callbacks)
+	stored, err := store.Get(cmd.ReferenceID())
+	require.NoError(t, err)
+	assert.Equal(t, &cmd.Payment, stored)
+
+	t.Run("invalid state transition", func(t *testing.T) {
+		next := *cmd
+		next.Payment.Sender.Status.Status = offchain.StatusReadyForSettlement
+		next.Payment.Receiver.Status.Status = offchain.StatusReadyForSettlement
+		_, err := client.SendPaymentCommand(context.Background(), receiverAccount, &next)
+		require.Error(t, err)
+		assert.Equal(t, offchain.InvalidOverwriteErrorCode, err.(*offchain.Error).Code)
+	})
+	t.Run("invalid command producer", func(t *testing.T) {
+		next := *cmd
+		next.Payment.Receiver.Status.Status = offchain.StatusReadyForSettlement
+		_, err := client.SendPaymentCommand(context.Background(), receiverAccount, &next)
+		require.Error(t, err)
+		assert.Equal(t, offchain.InvalidCommandProducerErrorCode, err.(*offchain.Error).Code)
+	})
+	t.Run("payment not found", func(t *testing.T) {
+		next := *offchain.NewPaymentCommand(sender, receiver, 100, "XUS", nil)
+		next.Payment.Sender.Status.Status = offchain.StatusAbort
+		_, err := client.SendPaymentCommand(context.Background(), receiverAccount, &next)
+		require.Error(t, err)
+		assert.Equal(t, offchain.InvalidInitialOrPriorNotFoundErrorCode, err.(*offchain.Error).Code)
+	})
+	t.Run("store failure", func(t *testing.T) {
+		failing := offchain.NewPaymentHandler(failingPaymentStore{}, diemid.TestnetPrefix, nil)
+		server.Handle(offchain.PaymentCommandType, failing.Handle)
+		defer server.Handle(offchain.PaymentCommandType, handler.Handle)
+		next := *offchain.NewPaymentCommand(sender, receiver, 100, "XUS", nil)
+		_, err := client.SendPaymentCommand(context.Background(), receiverAccount, &next)
+		assert.EqualError(t, err, "unexpected http response: 500, read payment failed: connection lost\n")
+	})
+	t.Run("server without verifier", func(t *testing.T) {
+		unverified := offchain.NewServer().WithSigningKey(receiverPrivate)
+		unverified.Handle(offchain.PaymentCommandType, handler.Handle)
+		us := httptest.NewServer(unverified)
+		defer us.Close()
+
+		client := offchain.NewClient(sender).
+			WithResolver(&baseURLResolver{url: us.URL}).
+			WithVerifier(offchain.NewVerifier(&keyResolver{key: receiverPublic}))
+		next := *offchain.NewPaymentCommand(sender, receiver, 100, "XUS", nil)
+		_, err := client.SendPaymentCommand(context.Background(), receiverAccount, &next)
+		require.Error(t, err)
+		assert.Equal(t, offchain.InvalidJWSErrorCode, err.(*offchain.Error).Code)
+	})
+	assert.Equal(t, 1, callbacks)
+}
@@ -0,0 +1,145 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diem/client-sdk-go/diemid"
+)
+
+// PaymentState is state of a payment decided by the sender and receiver statuses, the
+// state name prefix "S_" or "R_" indicates the state is produced by sender or receiver.
+type PaymentState string
+
+// Payment states (LIP-1)
+const (
+	PaymentStateSInit     PaymentState = "S_INIT"
+	PaymentStateSAbort    PaymentState = "S_ABORT"
+	PaymentStateSSoft     PaymentState = "S_SOFT"
+	PaymentStateSSoftSend PaymentState = "S_SOFT_SEND"
+	PaymentStateRAbort    PaymentState = "R_ABORT"
+	PaymentStateRSend     PaymentState = "R_SEND"
+	PaymentStateRSoft     PaymentState = "R_SOFT"
+	PaymentStateRSoftSend PaymentState = "R_SOFT_SEND"
+	PaymentStateReady     PaymentState = "READY"
+	paymentStateUnknown   PaymentState = ""
+)
+
+// payment state transitions, the key is prior state, the value is valid next states
+var paymentStateTransitions = map[PaymentState][]PaymentState{
+	PaymentStateSInit:     {PaymentStateRAbort, PaymentStateRSend, PaymentStateRSoft},
+	PaymentStateRSend:     {PaymentStateSAbort, PaymentStateSSoft, PaymentStateReady},
+	PaymentStateRSoft:     {PaymentStateSAbort, PaymentStateSSoftSend},
+	PaymentStateSSoftSend: {PaymentStateRAbort, PaymentStateRSend},
+	PaymentStateSSoft:     {PaymentStateRAbort, PaymentStateRSoftSend},
+	PaymentStateRSoftSend: {PaymentStateSAbort, PaymentStateReady},
+}
+
+// PaymentStateOf returns state of the payment, returns error if the sender and receiver
+// statuses do not match any state.
+func PaymentStateOf(p *PaymentObject) (PaymentState, error) {
+	sender, receiver := p.Sender.Status.Status, p.Receiver.Status.Status
+	state := paymentStateUnknown
+	switch {
+	case sender == StatusAbort && receiver != StatusAbort:
+		state = PaymentStateSAbort
+	case sender == StatusNeedsKycData && receiver == StatusAbort:
+		state = PaymentStateRAbort
+	case sender == StatusNeedsKycData && receiver == StatusNone:
+		state = PaymentStateSInit
+	case sender == StatusNeedsKycData && receiver == StatusReadyForSettlement:
+		state = PaymentStateRSend
+	case sender == StatusNeedsKycData && receiver == StatusSoftMatch:
+		state = PaymentStateRSoft
+		if p.Sender.AdditionalKycData != "" {
+			state = PaymentStateSSoftSend
+		}
+	case sender == StatusSoftMatch && receiver == StatusReadyForSettlement:
+		state = PaymentStateSSoft
+		if p.Receiver.AdditionalKycData != "" {
+			state = PaymentStateRSoftSend
+		}
+	case sender == StatusSoftMatch && receiver == StatusAbort:
+		state = PaymentStateRAbort
+	case sender == StatusReadyForSettlement && receiver == StatusReadyForSettlement:
+		state = PaymentStateReady
+	}
+	if state == paymentStateUnknown {
+		return state, fmt.Errorf("invalid payment state: sender status %s, receiver status %s", sender, receiver)
+	}
+	return state, nil
+}
+
+// IsFinal returns true for `PaymentStateReady`, `PaymentStateSAbort` and `PaymentStateRAbort`
+func (s PaymentState) IsFinal() bool {
+	return s == PaymentStateReady || s == PaymentStateSAbort || s == PaymentStateRAbort
+}
+
+// IsProducedBySender returns true if the state is produced by the payment sender, `READY` is
+// produced by the sender.
+func (s PaymentState) IsProducedBySender() bool {
+	return s == PaymentStateReady || strings.HasPrefix(string(s), "S_")
+}
+
+// ValidatePaymentTransition validates the payment can transit from prior to next; the prior is
+// nil for a new payment, which must be in state `PaymentStateSInit`.
+// Reference ids are compared normalized and actor addresses are compared decoded.
+// Returns `*Error` if the transition is invalid.
+func ValidatePaymentTransition(prior, next *PaymentObject) *Error {
+	nextState, err := PaymentStateOf(next)
+	if err != nil {
+		return NewCommandError(InvalidFieldValueErrorCode, "payment.sender.status.status", err.Error())
+	}
+	if prior == nil {
+		if nextState != PaymentStateSInit {
+			return NewCommandError(InvalidInitialOrPriorNotFoundErrorCode, "payment.reference_id",
+				fmt.Sprintf("payment not found, initial state must be %s, got %s", PaymentStateSInit, nextState))
+		}
+		return nil
+	}
+	if NormalizeReferenceID(prior.ReferenceID) != NormalizeReferenceID(next.ReferenceID) {
+		return NewCommandError(InvalidOverwriteErrorCode, "payment.reference_id", "reference_id can't be changed")
+	}
+	if !sameAccount(prior.Sender.Address, next.Sender.Address) || !sameAccount(prior.Receiver.Address, next.Receiver.Address) {
+		return NewCommandError(InvalidOverwriteErrorCode, "payment.sender.address", "actor address can't be changed")
+	}
+	if prior.Action != next.Action {
+		return NewCommandError(InvalidOverwriteErrorCode, "payment.action", "action can't be changed")
+	}
+	priorState, err := PaymentStateOf(prior)
+	if err != nil {
+		return NewCommandError(InvalidOverwriteErrorCode, "payment", err.Error())
+	}
+	for _, s := range paymentStateTransitions[priorState] {
+		if s == nextState {
+			return nil
+		}
+	}
+	return NewCommandError(InvalidOverwriteErrorCode, "payment",
+		fmt.Sprintf("invalid payment state transition: %s -> %s", priorState, nextState))
+}
+
+// sameAccount returns true if the two account identifiers decode to the same account address
+// and sub-address; identifiers failed to decode are compared as they are.
+func sameAccount(a, b string) bool {
+	if a == b {
+		return true
+	}
+	x, err := decodeAccount(a)
+	if err != nil {
+		return false
+	}
+	y, err := decodeAccount(b)
+	if err != nil {
+		return false
+	}
+	return x.AccountAddress == y.AccountAddress && x.SubAddress == y.SubAddress
+}
+
+func decodeAccount(identifier string) (*diemid.Account, error) {
+	prefix, _ := diemid.SuggestNetwork(identifier)
+	return diemid.DecodeToAccount(prefix, identifier)
+}
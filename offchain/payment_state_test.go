@@ -0,0 +1,105 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func paymentWithStatus(sender, receiver string) *offchain.PaymentObject {
+	cmd := offchain.NewPaymentCommand(senderAddress, receiverAddress, 100, "XUS", nil)
+	cmd.Payment.Action.Timestamp = 1
+	cmd.Payment.ReferenceID = "ref"
+	cmd.Payment.Sender.Status.Status = sender
+	cmd.Payment.Receiver.Status.Status = receiver
+	return &cmd.Payment
+}
+
+func TestPaymentStateOf(t *testing.T) {
+	cases := []struct {
+		sender, receiver string
+		additional       string
+		state            offchain.PaymentState
+	}{
+		{offchain.StatusNeedsKycData, offchain.StatusNone, "", offchain.PaymentStateSInit},
+		{offchain.StatusNeedsKycData, offchain.StatusReadyForSettlement, "", offchain.PaymentStateRSend},
+		{offchain.StatusNeedsKycData, offchain.StatusAbort, "", offchain.PaymentStateRAbort},
+		{offchain.StatusNeedsKycData, offchain.StatusSoftMatch, "", offchain.PaymentStateRSoft},
+		{offchain.StatusNeedsKycData, offchain.StatusSoftMatch, "sender", offchain.PaymentStateSSoftSend},
+		{offchain.StatusSoftMatch, offchain.StatusReadyForSettlement, "", offchain.PaymentStateSSoft},
+		{offchain.StatusSoftMatch, offchain.StatusReadyForSettlement, "receiver", offchain.PaymentStateRSoftSend},
+		{offchain.StatusAbort, offchain.StatusReadyForSettlement, "", offchain.PaymentStateSAbort},
+		{offchain.StatusReadyForSettlement, offchain.StatusReadyForSettlement, "", offchain.PaymentStateReady},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.state), func(t *testing.T) {
+			payment := paymentWithStatus(tc.sender, tc.receiver)
+			switch tc.additional {
+			case "sender":
+				payment.Sender.AdditionalKycData = "data"
+			case "receiver":
+				payment.Receiver.AdditionalKycData = "data"
+			}
+			state, err := offchain.PaymentStateOf(payment)
+			require.NoError(t, err)
+			assert.Equal(t, tc.state, state)
+		})
+	}
+
+	_, err := offchain.PaymentStateOf(paymentWithStatus(offchain.StatusNone, offchain.StatusNone))
+	assert.EqualError(t, err, "invalid payment state: sender status none, receiver status none")
+
+	assert.True(t, offchain.PaymentStateReady.IsFinal())
+	assert.True(t, offchain.PaymentStateRAbort.IsFinal())
+	assert.False(t, offchain.PaymentStateRSend.IsFinal())
+	assert.True(t, offchain.PaymentStateReady.IsProducedBySender())
+	assert.True(t, offchain.PaymentStateSSoft.IsProducedBySender())
+	assert.False(t, offchain.PaymentStateRSend.IsProducedBySender())
+}
+
+func TestValidatePaymentTransition(t *testing.T) {
+	initial := paymentWithStatus(offchain.StatusNeedsKycData, offchain.StatusNone)
+	rSend := paymentWithStatus(offchain.StatusNeedsKycData, offchain.StatusReadyForSettlement)
+	ready := paymentWithStatus(offchain.StatusReadyForSettlement, offchain.StatusReadyForSettlement)
+
+	assert.Nil(t, offchain.ValidatePaymentTransition(nil, initial))
+	assert.Nil(t, offchain.ValidatePaymentTransition(initial, rSend))
+	assert.Nil(t, offchain.ValidatePaymentTransition(rSend, ready))
+
+	err := offchain.ValidatePaymentTransition(nil, rSend)
+	require.NotNil(t, err)
+	assert.Equal(t, offchain.InvalidInitialOrPriorNotFoundErrorCode, err.Code)
+
+	err = offchain.ValidatePaymentTransition(initial, ready)
+	require.NotNil(t, err)
+	assert.Equal(t, offchain.InvalidOverwriteErrorCode, err.Code)
+	assert.Equal(t, "invalid payment state transition: S_INIT -> READY", err.Message)
+
+	changed := paymentWithStatus(offchain.StatusNeedsKycData, offchain.StatusReadyForSettlement)
+	changed.Action.Amount = 1
+	err = offchain.ValidatePaymentTransition(initial, changed)
+	require.NotNil(t, err)
+	assert.Equal(t, "payment.action", err.Field)
+}
+
+func TestValidatePaymentTransitionNormalizesIdentifiers(t *testing.T) {
+	id, err := offchain.NewReferenceID()
+	require.NoError(t, err)
+	prior := paymentWithStatus(offchain.StatusNeedsKycData, offchain.StatusNone)
+	prior.ReferenceID = id.String()
+	next := paymentWithStatus(offchain.StatusNeedsKycData, offchain.StatusReadyForSettlement)
+	next.ReferenceID = strings.ToUpper(id.String())
+	next.Sender.Address = strings.ToUpper(senderAddress)
+	assert.Nil(t, offchain.ValidatePaymentTransition(prior, next))
+
+	next.Receiver.Address = senderAddress
+	cmdErr := offchain.ValidatePaymentTransition(prior, next)
+	require.NotNil(t, cmdErr)
+	assert.Equal(t, offchain.InvalidOverwriteErrorCode, cmdErr.Code)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const receiverAddress = "tdm1p7ujcndcl7nudzwt8fglhx6wxn08kgs5tm6mz4ustv0tyx"
+
+type baseURLResolver struct {
+	url string
+}
+
+func (r *baseURLResolver) BaseURL(address diemtypes.AccountAddress) (string, error) {
+	return r.url, nil
+}
+
+func TestNewPaymentCommand(t *testing.T) {
+	kyc := &offchain.KycDataObject{PayloadVersion: 1, Type: offchain.KycDataTypeIndividual, GivenName: "Tom"}
+	cmd := offchain.NewPaymentCommand(senderAddress, receiverAddress, 1_000_000, "XUS", kyc)
+
+	assert.Equal(t, offchain.PaymentCommandType, cmd.ObjectType)
+	assert.Len(t, cmd.ReferenceID(), 36)
+	assert.Equal(t, offchain.StatusNeedsKycData, cmd.Payment.Sender.Status.Status)
+	assert.Equal(t, offchain.StatusNone, cmd.Payment.Receiver.Status.Status)
+	assert.Equal(t, offchain.PaymentActionCharge, cmd.Payment.Action.Action)
+	assert.False(t, cmd.Payment.IsAborted())
+	assert.False(t, cmd.Payment.IsReadyForSettlement())
+
+	cmd.Payment.Sender.Status.Status = offchain.StatusReadyForSettlement
+	assert.True(t, cmd.Payment.IsReadyForSettlement())
+	cmd.Payment.Receiver.Status = offchain.StatusObject{Status: offchain.StatusAbort, AbortCode: offchain.AbortCodeRejected}
+	assert.True(t, cmd.Payment.IsAborted())
+	assert.False(t, cmd.Payment.IsReadyForSettlement())
+
+	bytes, err := json.Marshal(cmd)
+	require.NoError(t, err)
+	var decoded offchain.PaymentCommand
+	require.NoError(t, json.Unmarshal(bytes, &decoded))
+	assert.Equal(t, *cmd, decoded)
+}
+
+func TestSendPaymentCommand(t *testing.T) {
+	public, private := genKey(t)
+	receiverPublic, receiverPrivate := genKey(t)
+	var received offchain.PaymentCommand
+	handler := func(r *http.Request, req *offchain.CommandRequestObject) (interface{}, *offchain.Error) {
+		if err := json.Unmarshal(req.Command, &received); err != nil {
+			return nil, offchain.NewCommandError(offchain.InvalidObjectErrorCode, "command", err.Error())
+		}
+		return nil, nil
+	}
+	server := offchain.NewServer().
+		WithVerifier(offchain.NewVerifier(&keyResolver{key: public}), diemid.TestnetPrefix).
+		WithSigningKey(receiverPrivate)
+	server.Handle(offchain.PaymentCommandType, handler)
+	s := httptest.NewServer(server)
+	defer s.Close()
+
+	cmd := offchain.NewPaymentCommand(senderAddress, receiverAddress, 1_000_000, "XUS", nil)
+	verifier := offchain.NewVerifier(&keyResolver{key: receiverPublic})
+	client := offchain.NewClient(senderAddress).
+		WithComplianceKey(private).
+		WithResolver(&baseURLResolver{url: s.URL}).
+		WithVerifier(verifier)
+
+	resp, err := client.SendPaymentCommand(context.Background(), diemtypes.AccountAddress{1}, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, offchain.StatusSuccess, resp.Status)
+	assert.Equal(t, *cmd, received)
+
+	t.Run("not signed", func(t *testing.T) {
+		client := offchain.NewClient(senderAddress).
+			WithResolver(&baseURLResolver{url: s.URL}).
+			WithVerifier(verifier)
+		_, err := client.SendPaymentCommand(context.Background(), diemtypes.AccountAddress{1}, cmd)
+		require.Error(t, err)
+		assert.Equal(t, offchain.InvalidJWSErrorCode, err.(*offchain.Error).Code)
+	})
+	t.Run("response is not signed", func(t *testing.T) {
+		unsigned := offchain.NewServer().
+			WithVerifier(offchain.NewVerifier(&keyResolver{key: public}), diemid.TestnetPrefix)
+		unsigned.Handle(offchain.PaymentCommandType, handler)
+		us := httptest.NewServer(unsigned)
+		defer us.Close()
+
+		client := offchain.NewClient(senderAddress).
+			WithComplianceKey(private).
+			WithResolver(&baseURLResolver{url: us.URL}).
+			WithVerifier(verifier)
+		_, err := client.SendPaymentCommand(context.Background(), diemtypes.AccountAddress{1}, cmd)
+		assert.Error(t, err)
+	})
+	t.Run("response cid does not match request cid", func(t *testing.T) {
+		cs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := json.Marshal(&offchain.CommandResponseObject{
+				ObjectType: offchain.CommandResponseObjectType,
+				Status:     offchain.StatusSuccess,
+				Cid:        newReferenceID(t),
+			})
+			w.Write([]byte(offchain.SignJWS(body, receiverPrivate)))
+		}))
+		defer cs.Close()
+
+		client := offchain.NewClient(senderAddress).
+			WithComplianceKey(private).
+			WithResolver(&baseURLResolver{url: cs.URL}).
+			WithVerifier(verifier)
+		_, err := client.SendPaymentCommand(context.Background(), diemtypes.AccountAddress{1}, cmd)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match request cid")
+	})
+	t.Run("missing verifier", func(t *testing.T) {
+		_, err := offchain.NewClient(senderAddress).
+			WithResolver(&baseURLResolver{url: s.URL}).
+			SendPaymentCommand(context.Background(), diemtypes.AccountAddress{1}, cmd)
+		assert.EqualError(t, err, "response verifier is not set")
+	})
+	t.Run("missing resolver", func(t *testing.T) {
+		_, err := offchain.NewClient(senderAddress).
+			WithVerifier(verifier).
+			SendPaymentCommand(context.Background(), diemtypes.AccountAddress{1}, cmd)
+		assert.EqualError(t, err, "base url resolver is not set")
+	})
+}
+
+func TestOnChainBaseURLResolver(t *testing.T) {
+	account := json.RawMessage(`{
+  "address": "f72589b71ff4f8d139674a3f7369c69b",
+  "role": {
+    "type": "parent_vasp",
+    "human_name": "vasp",
+    "base_url": "http://vasp.com",
+    "compliance_key": "447fc3be296803c2303951c7816624c7566730a5cc6860a4a1bd3c04731569f5"
+  }
+}`)
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+		Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: {Result: &account}},
+	})
+	resolver := &offchain.OnChainBaseURLResolver{Client: client}
+	url, err := resolver.BaseURL(diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b"))
+	require.NoError(t, err)
+	assert.Equal(t, "http://vasp.com", url)
+}
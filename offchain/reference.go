@@ -0,0 +1,91 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"fmt"
+
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// PaymentRegistry looks up off-chain payment by reference id, so that an application can find
+// the off-chain context of an on-chain transaction by the reference id in its metadata.
+// `PaymentStore` implements this interface.
+type PaymentRegistry interface {
+	// Get returns payment of given reference id, returns nil without error if not found
+	Get(referenceID string) (*PaymentObject, error)
+}
+
+// NewReferenceID generates random (version 4) UUID reference id, which is used as
+// `PaymentObject.ReferenceID` and `PaymentMetadata` reference id of the same payment.
+func NewReferenceID() (txnmetadata.ReferenceID, error) {
+	return txnmetadata.NewReferenceID()
+}
+
+// mustNewReferenceID returns string of `NewReferenceID`, panics if reading random bytes failed
+func mustNewReferenceID() string {
+	id, err := NewReferenceID()
+	if err != nil {
+		panic(err)
+	}
+	return id.String()
+}
+
+// NormalizeReferenceID returns lower case UUID string of the reference id, returns the given
+// reference id as it is if it is not UUID.
+func NormalizeReferenceID(referenceID string) string {
+	id, err := txnmetadata.ParseReferenceID(referenceID)
+	if err != nil {
+		return referenceID
+	}
+	return id.String()
+}
+
+// PaymentReferenceID parses the payment reference id for creating on-chain `PaymentMetadata`.
+// Returns error if the reference id is not UUID.
+func (p *PaymentObject) PaymentReferenceID() (txnmetadata.ReferenceID, error) {
+	return txnmetadata.ParseReferenceID(p.ReferenceID)
+}
+
+// PaymentMetadata creates BCS-encoded `PaymentMetadata` of the payment reference id for
+// creating peer to peer transaction script.
+func (p *PaymentObject) PaymentMetadata() ([]byte, error) {
+	id, err := p.PaymentReferenceID()
+	if err != nil {
+		return nil, err
+	}
+	return txnmetadata.NewPaymentMetadata(id), nil
+}
+
+// MetadataReferenceID returns off-chain reference id carried by the BCS-encoded on-chain
+// transaction metadata: UUID string of `PaymentMetadata` reference id, or off-chain reference
+// id of travel rule metadata. Returns false if the metadata has no reference id.
+func MetadataReferenceID(metadata []byte) (string, bool, error) {
+	inspection, err := txnmetadata.Inspect(metadata)
+	if err != nil {
+		return "", false, err
+	}
+	switch {
+	case inspection.ReferenceID != nil:
+		return inspection.ReferenceID.String(), true, nil
+	case inspection.OffChainReferenceID != nil:
+		return *inspection.OffChainReferenceID, true, nil
+	}
+	return "", false, nil
+}
+
+// LookupPayment finds off-chain payment of the reference id carried by the BCS-encoded on-chain
+// transaction metadata. Returns nil without error if the metadata has no reference id or the
+// payment is not found.
+func LookupPayment(registry PaymentRegistry, metadata []byte) (*PaymentObject, error) {
+	referenceID, ok, err := MetadataReferenceID(metadata)
+	if err != nil || !ok {
+		return nil, err
+	}
+	payment, err := registry.Get(NormalizeReferenceID(referenceID))
+	if err != nil {
+		return nil, fmt.Errorf("lookup payment %s failed: %v", referenceID, err)
+	}
+	return payment, nil
+}
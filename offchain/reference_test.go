@@ -0,0 +1,82 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReferenceID returns string of a new reference id, which is also used as request id and cid
+func newReferenceID(t *testing.T) string {
+	id, err := offchain.NewReferenceID()
+	require.NoError(t, err)
+	return id.String()
+}
+
+func TestNormalizeReferenceID(t *testing.T) {
+	id, err := offchain.NewReferenceID()
+	require.NoError(t, err)
+	assert.Equal(t, id.String(), offchain.NormalizeReferenceID(strings.ToUpper(id.String())))
+	assert.Equal(t, "ref id", offchain.NormalizeReferenceID("ref id"))
+}
+
+func TestLookupPaymentByMetadata(t *testing.T) {
+	store := offchain.NewMemoryPaymentStore()
+	cmd := offchain.NewPaymentCommand(senderAddress, receiverAddress, 100, "XUS", nil)
+	require.NoError(t, store.Put(&cmd.Payment))
+
+	metadata, err := cmd.Payment.PaymentMetadata()
+	require.NoError(t, err)
+	referenceID, ok, err := offchain.MetadataReferenceID(metadata)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, cmd.ReferenceID(), referenceID)
+
+	payment, err := offchain.LookupPayment(store, metadata)
+	require.NoError(t, err)
+	assert.Equal(t, &cmd.Payment, payment)
+
+	travelRule, _ := txnmetadata.NewTravelRuleMetadata(cmd.ReferenceID(), diemtypes.AccountAddress{1}, 100)
+	payment, err = offchain.LookupPayment(store, travelRule)
+	require.NoError(t, err)
+	assert.Equal(t, &cmd.Payment, payment)
+
+	payment, err = offchain.LookupPayment(store, nil)
+	require.NoError(t, err)
+	assert.Nil(t, payment)
+
+	other, err := offchain.NewReferenceID()
+	require.NoError(t, err)
+	payment, err = offchain.LookupPayment(store, txnmetadata.NewPaymentMetadata(other))
+	require.NoError(t, err)
+	assert.Nil(t, payment)
+
+	t.Run("mixed case reference id", func(t *testing.T) {
+		store := offchain.NewMemoryPaymentStore()
+		cmd := offchain.NewPaymentCommand(senderAddress, receiverAddress, 100, "XUS", nil)
+		cmd.Payment.ReferenceID = strings.ToUpper(cmd.Payment.ReferenceID)
+		require.NoError(t, store.Put(&cmd.Payment))
+
+		metadata, err := cmd.Payment.PaymentMetadata()
+		require.NoError(t, err)
+		payment, err := offchain.LookupPayment(store, metadata)
+		require.NoError(t, err)
+		assert.Equal(t, &cmd.Payment, payment)
+
+		payment, err = store.Get(cmd.Payment.ReferenceID)
+		require.NoError(t, err)
+		assert.Equal(t, &cmd.Payment, payment)
+	})
+
+	cmd.Payment.ReferenceID = "invalid"
+	_, err = cmd.Payment.PaymentMetadata()
+	assert.Error(t, err)
+}
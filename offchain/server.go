@@ -0,0 +1,310 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// DefaultReservationTTL is the default duration a command is reserved for handling, see
+// `Server#WithCommandStore`
+const DefaultReservationTTL = time.Minute
+
+// DefaultMaxRequestBodySize is the default max size of inbound request body in bytes, see
+// `Server#WithMaxRequestBodySize`
+const DefaultMaxRequestBodySize int64 = 1 << 20
+
+var (
+	errCommandInProgress = errors.New("command is being handled, retry later")
+	errRequestTooLarge   = errors.New("request body too large")
+)
+
+type verifiedSenderKey struct{}
+
+// VerifiedSender returns the account address of the request sender whose compliance key signed
+// the request body; returns false if the request was not verified, i.e. the `Server` has no
+// verifier.
+func VerifiedSender(r *http.Request) (diemtypes.AccountAddress, bool) {
+	address, ok := r.Context().Value(verifiedSenderKey{}).(diemtypes.AccountAddress)
+	return address, ok
+}
+
+// CommandHandler handles a command request, returns result (can be nil) for success response,
+// or `*Error` for failure response; `NewInternalError` is responded with HTTP status 500.
+type CommandHandler func(r *http.Request, request *CommandRequestObject) (interface{}, *Error)
+
+// Server is an `http.Handler` serves off-chain command requests.
+// It handles `PingCommand` by default.
+type Server struct {
+	mux      sync.RWMutex
+	handlers map[string]CommandHandler
+	store    CommandStore
+	ttl      time.Duration
+	maxBody  int64
+	verifier *Verifier
+	prefix   diemid.NetworkPrefix
+	key      ed25519.PrivateKey
+}
+
+// NewServer creates `Server`
+func NewServer() *Server {
+	s := &Server{handlers: make(map[string]CommandHandler), maxBody: DefaultMaxRequestBodySize}
+	s.Handle(PingCommandType, s.handlePing)
+	return s
+}
+
+// WithCommandStore sets `CommandStore` for deduplicating inbound command requests by sender
+// account address and cid: the response of a handled command is replayed for the same request,
+// and a conflict error is responded for a different request with the same cid.
+// The command is reserved in the store before it is handled; a request received while the same
+// command is being handled is responded with http status 503, and store errors are responded
+// with http status 500, so that the client retries it. A reservation without response expires
+// after `DefaultReservationTTL`, e.g. the server crashed while handling the command, then the
+// command is handled again by the next request.
+func (s *Server) WithCommandStore(store CommandStore) *Server {
+	s.store = store
+	if s.ttl == 0 {
+		s.ttl = DefaultReservationTTL
+	}
+	return s
+}
+
+// WithReservationTTL sets the duration a command is reserved for handling, it should be longer
+// than the time of handling a command.
+func (s *Server) WithReservationTTL(ttl time.Duration) *Server {
+	s.ttl = ttl
+	return s
+}
+
+// WithMaxRequestBodySize sets the max size of inbound request body in bytes, a larger request
+// is responded with http status 413. Default is `DefaultMaxRequestBodySize`.
+func (s *Server) WithMaxRequestBodySize(size int64) *Server {
+	s.maxBody = size
+	return s
+}
+
+// WithVerifier requires inbound request body to be JWS message signed by the compliance key
+// of the sender; the sender account identifier in `X-REQUEST-SENDER-ADDRESS` header is decoded
+// with the given network prefix. The verified sender is available to command handlers by
+// `VerifiedSender`.
+func (s *Server) WithVerifier(verifier *Verifier, prefix diemid.NetworkPrefix) *Server {
+	s.verifier = verifier
+	s.prefix = prefix
+	return s
+}
+
+// WithSigningKey signs response body as JWS message with the given compliance key
+func (s *Server) WithSigningKey(key ed25519.PrivateKey) *Server {
+	s.key = key
+	return s
+}
+
+// Handle registers handler for given command type
+func (s *Server) Handle(commandType string, handler CommandHandler) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.handlers[commandType] = handler
+}
+
+// Capabilities returns protocol versions and command types the server supports
+func (s *Server) Capabilities() *Capabilities {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	types := make([]string, 0, len(s.handlers))
+	for t := range s.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return &Capabilities{Versions: SupportedVersions, CommandTypes: types}
+}
+
+// ServeHTTP implements `http.Handler`
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxBody)
+	resp, err := s.handle(r)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case errCommandInProgress:
+			status = http.StatusServiceUnavailable
+		case errRequestTooLarge:
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	status := http.StatusOK
+	if resp.Status != StatusSuccess {
+		status = http.StatusBadRequest
+	}
+	body, _ := json.Marshal(resp)
+	if s.key != nil {
+		body = []byte(SignJWS(body, s.key))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (s *Server) handle(r *http.Request) (*CommandResponseObject, error) {
+	if r.Method != http.MethodPost {
+		return failure("", NewProtocolError(InvalidObjectErrorCode, "method not allowed: "+r.Method)), nil
+	}
+	if r.Header.Get(RequestIDHeader) == "" {
+		return failure("", NewProtocolError(MissingHTTPHeaderErrorCode, "missing "+RequestIDHeader)), nil
+	}
+	sender := r.Header.Get(RequestSenderHeader)
+	if sender == "" {
+		return failure("", NewProtocolError(MissingHTTPHeaderErrorCode, "missing "+RequestSenderHeader)), nil
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil && int64(len(body)) >= s.maxBody {
+		return nil, errRequestTooLarge
+	}
+	if err != nil {
+		return failure("", NewProtocolError(InvalidJSONErrorCode, err.Error())), nil
+	}
+	var address diemtypes.AccountAddress
+	if s.verifier != nil {
+		var verifyErr *Error
+		address, body, verifyErr = s.verify(sender, body)
+		if verifyErr != nil {
+			return failure("", verifyErr), nil
+		}
+		r = r.WithContext(context.WithValue(r.Context(), verifiedSenderKey{}, address))
+	} else if s.store != nil {
+		// deduplicate by the account address, the identifier encodes sub-address and it is
+		// case insensitive
+		var decodeErr *Error
+		if address, decodeErr = decodeSender(s.prefix, sender); decodeErr != nil {
+			return failure("", decodeErr), nil
+		}
+	}
+	var request CommandRequestObject
+	if err := json.Unmarshal(body, &request); err != nil {
+		return failure("", NewProtocolError(InvalidJSONErrorCode, err.Error())), nil
+	}
+	if request.ObjectType != CommandRequestObjectType {
+		return failure(request.Cid, NewProtocolError(InvalidObjectErrorCode, "invalid _ObjectType: "+request.ObjectType)), nil
+	}
+	if request.Cid == "" {
+		return failure("", NewProtocolError(MissingFieldErrorCode, "missing cid")), nil
+	}
+	if s.store == nil {
+		return s.dispatch(r, &request)
+	}
+	now := time.Now()
+	record := &CommandRecord{Sender: address.Hex(), Cid: request.Cid, RequestDigest: RequestDigest(body), ReservedAt: now}
+	existing, err := s.store.Reserve(record, now.Add(-s.ttl))
+	if err != nil {
+		return nil, fmt.Errorf("reserve command record failed: %v", err)
+	}
+	if existing != nil {
+		if existing.RequestDigest != record.RequestDigest {
+			return failure(request.Cid, NewCommandError(ConflictErrorCode, "cid", "cid was used by a different request")), nil
+		}
+		if existing.Response == nil {
+			return nil, errCommandInProgress
+		}
+		return existing.Response, nil
+	}
+	record.Response, err = s.dispatch(r, &request)
+	if err != nil {
+		if releaseErr := s.store.Release(record.Sender, request.Cid); releaseErr != nil {
+			return nil, fmt.Errorf("%v; release command record failed: %v", err, releaseErr)
+		}
+		return nil, err
+	}
+	if err := s.store.Complete(record); err != nil {
+		if releaseErr := s.store.Release(record.Sender, request.Cid); releaseErr != nil {
+			return nil, fmt.Errorf("save command record failed: %v; release command record failed: %v", err, releaseErr)
+		}
+		return nil, fmt.Errorf("save command record failed: %v", err)
+	}
+	return record.Response, nil
+}
+
+func (s *Server) verify(sender string, body []byte) (diemtypes.AccountAddress, []byte, *Error) {
+	address, decodeErr := decodeSender(s.prefix, sender)
+	if decodeErr != nil {
+		return address, nil, decodeErr
+	}
+	if _, err := DecodeJWS(string(body)); err != nil {
+		return diemtypes.AccountAddress{}, nil, NewProtocolError(InvalidJWSErrorCode, err.Error())
+	}
+	payload, err := s.verifier.Verify(address, string(body))
+	if err != nil {
+		return diemtypes.AccountAddress{}, nil, NewProtocolError(InvalidJWSSignatureErrorCode, err.Error())
+	}
+	return address, payload, nil
+}
+
+// decodeSender decodes the sender account identifier with the network prefix, or the prefix of
+// the identifier if the given prefix is empty.
+func decodeSender(prefix diemid.NetworkPrefix, sender string) (diemtypes.AccountAddress, *Error) {
+	if prefix == "" {
+		prefix, _ = diemid.SuggestNetwork(sender)
+	}
+	account, err := diemid.DecodeToAccount(prefix, sender)
+	if err != nil {
+		return diemtypes.AccountAddress{}, NewProtocolError(InvalidHTTPHeaderErrorCode, "invalid "+RequestSenderHeader+": "+err.Error())
+	}
+	return account.AccountAddress, nil
+}
+
+// dispatch returns error if the handler failed by `NewInternalError`
+func (s *Server) dispatch(r *http.Request, request *CommandRequestObject) (*CommandResponseObject, error) {
+	s.mux.RLock()
+	handler, ok := s.handlers[request.CommandType]
+	s.mux.RUnlock()
+	if !ok {
+		return failure(request.Cid, NewProtocolError(UnknownCommandTypeErrorCode, "unknown command type: "+request.CommandType)), nil
+	}
+	result, cmdErr := handler(r, request)
+	if cmdErr != nil && cmdErr.internal {
+		return nil, errors.New(cmdErr.Message)
+	}
+	if cmdErr != nil {
+		return failure(request.Cid, cmdErr), nil
+	}
+	resp := &CommandResponseObject{ObjectType: CommandResponseObjectType, Status: StatusSuccess, Cid: request.Cid}
+	if result != nil {
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return failure(request.Cid, NewCommandError(InvalidObjectErrorCode, "", err.Error())), nil
+		}
+		resp.Result = resultJSON
+	}
+	return resp, nil
+}
+
+func (s *Server) handlePing(r *http.Request, request *CommandRequestObject) (interface{}, *Error) {
+	capabilities := s.Capabilities()
+	return &PingCommandResponse{
+		ObjectType:            PingCommandResponseType,
+		SupportedVersions:     capabilities.Versions,
+		SupportedCommandTypes: capabilities.CommandTypes,
+	}, nil
+}
+
+func failure(cid string, err *Error) *CommandResponseObject {
+	return &CommandResponseObject{
+		ObjectType: CommandResponseObjectType,
+		Status:     StatusFailure,
+		Error:      err,
+		Cid:        cid,
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/internal/sqlutil"
+)
+
+// CommandRecord is a handled inbound command request digest and the response; the response is
+// nil while the command is being handled.
+type CommandRecord struct {
+	Sender        string
+	Cid           string
+	RequestDigest string
+	Response      *CommandResponseObject
+	// ReservedAt is the time the command is reserved for handling
+	ReservedAt time.Time
+}
+
+// CommandStore stores inbound command records for deduplicating command requests by sender
+// and cid.
+type CommandStore interface {
+	// Reserve inserts the record if there is no record of same sender and cid, or replaces the
+	// existing record that has no response and was reserved before staleBefore, e.g. the
+	// server crashed while handling the command; and returns nil without error. Otherwise
+	// returns the existing record without changing it.
+	// Insert and check must be one atomic operation, so that a command is handled only once
+	// when same request is received concurrently.
+	Reserve(record *CommandRecord, staleBefore time.Time) (*CommandRecord, error)
+	// Complete saves response of a reserved record
+	Complete(record *CommandRecord) error
+	// Release deletes a reserved record that has no response, so that the request can be
+	// retried.
+	Release(sender, cid string) error
+}
+
+// RequestDigest returns sha256 hex digest of request body, it is used for detecting
+// different requests sent with same cid.
+func RequestDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryCommandStore implements `CommandStore` in memory, records are lost after restart.
+type MemoryCommandStore struct {
+	mux     sync.Mutex
+	records map[commandKey]CommandRecord
+}
+
+type commandKey struct {
+	sender string
+	cid    string
+}
+
+// NewMemoryCommandStore creates `MemoryCommandStore`
+func NewMemoryCommandStore() *MemoryCommandStore {
+	return &MemoryCommandStore{records: make(map[commandKey]CommandRecord)}
+}
+
+// Reserve implements `CommandStore`
+func (s *MemoryCommandStore) Reserve(record *CommandRecord, staleBefore time.Time) (*CommandRecord, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	key := commandKey{record.Sender, record.Cid}
+	if existing, ok := s.records[key]; ok && (existing.Response != nil || !existing.ReservedAt.Before(staleBefore)) {
+		return &existing, nil
+	}
+	s.records[key] = *record
+	return nil, nil
+}
+
+// Complete implements `CommandStore`
+func (s *MemoryCommandStore) Complete(record *CommandRecord) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	key := commandKey{record.Sender, record.Cid}
+	if _, ok := s.records[key]; !ok {
+		return fmt.Errorf("command record of sender %s cid %s is not reserved", record.Sender, record.Cid)
+	}
+	s.records[key] = *record
+	return nil
+}
+
+// Release implements `CommandStore`
+func (s *MemoryCommandStore) Release(sender, cid string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	key := commandKey{sender, cid}
+	if record, ok := s.records[key]; ok && record.Response == nil {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// SQLCommandStore implements `CommandStore` with a SQL database table:
+//
+//	CREATE TABLE <table> (
+//	  sender VARCHAR(128) NOT NULL,
+//	  cid VARCHAR(64) NOT NULL,
+//	  request_digest VARCHAR(64) NOT NULL,
+//	  response TEXT,
+//	  reserved_at BIGINT NOT NULL,
+//	  PRIMARY KEY (sender, cid)
+//	)
+//
+// `reserved_at` is unix timestamp in milliseconds. Call `CreateTable` to create the table if
+// it does not exist.
+// When same request is reserved concurrently, the primary key constraint fails one of them,
+// and the error is returned.
+type SQLCommandStore struct {
+	DB    *sql.DB
+	Table string
+	// Placeholder returns bind parameter placeholder for the n-th (starts from 1) parameter,
+	// defaults to "?"; set `DollarPlaceholder` for PostgreSQL.
+	Placeholder func(n int) string
+}
+
+// DollarPlaceholder returns "$n" bind parameter placeholder
+var DollarPlaceholder = sqlutil.DollarPlaceholder
+
+// NewSQLCommandStore creates `SQLCommandStore` with given db and table name
+func NewSQLCommandStore(db *sql.DB, table string) *SQLCommandStore {
+	return &SQLCommandStore{DB: db, Table: table}
+}
+
+// CreateTable creates the records table if it does not exist
+func (s *SQLCommandStore) CreateTable() error {
+	_, err := s.DB.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (sender VARCHAR(128) NOT NULL, cid VARCHAR(64) NOT NULL, request_digest VARCHAR(64) NOT NULL, response TEXT, reserved_at BIGINT NOT NULL, PRIMARY KEY (sender, cid))",
+		s.Table))
+	return err
+}
+
+// Reserve implements `CommandStore`
+func (s *SQLCommandStore) Reserve(record *CommandRecord, staleBefore time.Time) (*CommandRecord, error) {
+	reservedAt := record.ReservedAt.UnixNano() / int64(time.Millisecond)
+	saved, err := s.exec(fmt.Sprintf(
+		"UPDATE %s SET request_digest = %s, reserved_at = %s WHERE sender = %s AND cid = %s AND response IS NULL AND reserved_at < %s",
+		s.Table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4), s.placeholder(5)),
+		record.RequestDigest, reservedAt, record.Sender, record.Cid, staleBefore.UnixNano()/int64(time.Millisecond))
+	if err == nil && !saved {
+		saved, err = s.exec(fmt.Sprintf(
+			"INSERT INTO %s (sender, cid, request_digest, reserved_at) SELECT %s, %s, %s, %s WHERE NOT EXISTS (SELECT 1 FROM %s WHERE sender = %s AND cid = %s)",
+			s.Table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4), s.Table, s.placeholder(5), s.placeholder(6)),
+			record.Sender, record.Cid, record.RequestDigest, reservedAt, record.Sender, record.Cid)
+	}
+	if err != nil || saved {
+		return nil, err
+	}
+	return s.get(record.Sender, record.Cid)
+}
+
+// exec executes the statement, returns true if any row is affected
+func (s *SQLCommandStore) exec(query string, args ...interface{}) (bool, error) {
+	ret, err := s.DB.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := ret.RowsAffected()
+	return affected > 0, err
+}
+
+// Complete implements `CommandStore`
+func (s *SQLCommandStore) Complete(record *CommandRecord) error {
+	response, err := json.Marshal(record.Response)
+	if err != nil {
+		return err
+	}
+	ret, err := s.DB.Exec(fmt.Sprintf(
+		"UPDATE %s SET response = %s WHERE sender = %s AND cid = %s",
+		s.Table, s.placeholder(1), s.placeholder(2), s.placeholder(3)),
+		string(response), record.Sender, record.Cid)
+	if err != nil {
+		return err
+	}
+	updated, err := ret.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("command record of sender %s cid %s is not reserved", record.Sender, record.Cid)
+	}
+	return nil
+}
+
+// Release implements `CommandStore`
+func (s *SQLCommandStore) Release(sender, cid string) error {
+	_, err := s.DB.Exec(fmt.Sprintf(
+		"DELETE FROM %s WHERE sender = %s AND cid = %s AND response IS NULL",
+		s.Table, s.placeholder(1), s.placeholder(2)),
+		sender, cid)
+	return err
+}
+
+func (s *SQLCommandStore) get(sender, cid string) (*CommandRecord, error) {
+	row := s.DB.QueryRow(fmt.Sprintf(
+		"SELECT request_digest, response, reserved_at FROM %s WHERE sender = %s AND cid = %s",
+		s.Table, s.placeholder(1), s.placeholder(2)),
+		sender, cid)
+	var digest string
+	var response sql.NullString
+	var reservedAt int64
+	if err := row.Scan(&digest, &response, &reservedAt); err != nil {
+		return nil, err
+	}
+	record := CommandRecord{Sender: sender, Cid: cid, RequestDigest: digest,
+		ReservedAt: time.Unix(0, reservedAt*int64(time.Millisecond))}
+	if response.Valid {
+		var resp CommandResponseObject
+		if err := json.Unmarshal([]byte(response.String), &resp); err != nil {
+			return nil, fmt.Errorf("decode stored response of sender %s cid %s failed: %v", sender, cid, err)
+		}
+		record.Response = &resp
+	}
+	return &record, nil
+}
+
+func (s *SQLCommandStore) placeholder(n int) string {
+	return sqlutil.Placeholder(s.Placeholder, n)
+}
@@ -0,0 +1,311 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain_test
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/internal/sqlutil/sqltest"
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCommandStore(t *testing.T) {
+	testCommandStore(t, offchain.NewMemoryCommandStore())
+}
+
+func TestSQLCommandStore(t *testing.T) {
+	db := newFakeDB()
+	store := offchain.NewSQLCommandStore(db.Open(), "commands")
+	store.Placeholder = offchain.DollarPlaceholder
+	require.NoError(t, store.CreateTable())
+	testCommandStore(t, store)
+
+	queries := db.Queries()
+	assert.Contains(t, queries[0], "PRIMARY KEY (sender, cid)")
+	for _, query := range queries[1:] {
+		assert.NotContains(t, query, "?")
+	}
+
+	db.Fail(errors.New("connection lost"))
+	_, err := store.Reserve(&offchain.CommandRecord{Sender: "sender", Cid: "other"}, time.Now())
+	assert.EqualError(t, err, "connection lost")
+}
+
+func testCommandStore(t *testing.T, store offchain.CommandStore) {
+	now := time.Unix(1600000000, 0)
+	staleBefore := now.Add(-time.Minute)
+	reserve := func(sender, digest string, reservedAt time.Time) (*offchain.CommandRecord, error) {
+		return store.Reserve(&offchain.CommandRecord{
+			Sender: sender, Cid: "cid", RequestDigest: digest, ReservedAt: reservedAt}, staleBefore)
+	}
+	record := &offchain.CommandRecord{Sender: "sender", Cid: "cid", RequestDigest: "digest", ReservedAt: now}
+	existing, err := store.Reserve(record, staleBefore)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	existing, err = reserve("sender", "other", now)
+	require.NoError(t, err)
+	assert.Equal(t, record, existing, "reserved record is not overwritten")
+
+	existing, err = reserve("other", "digest", staleBefore.Add(-time.Second))
+	require.NoError(t, err)
+	assert.Nil(t, existing, "cid is scoped by sender")
+	existing, err = reserve("other", "other", now)
+	require.NoError(t, err)
+	assert.Nil(t, existing, "stale reservation is replaced")
+	existing, err = reserve("other", "digest", now)
+	require.NoError(t, err)
+	assert.Equal(t, &offchain.CommandRecord{Sender: "other", Cid: "cid", RequestDigest: "other", ReservedAt: now}, existing)
+
+	record.Response = &offchain.CommandResponseObject{
+		ObjectType: offchain.CommandResponseObjectType,
+		Status:     offchain.StatusSuccess,
+		Cid:        "cid",
+	}
+	require.NoError(t, store.Complete(record))
+	require.NoError(t, store.Release("sender", "cid"))
+	existing, err = store.Reserve(&offchain.CommandRecord{Sender: "sender", Cid: "cid", RequestDigest: "digest", ReservedAt: now},
+		now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, record, existing, "completed record is not released or replaced")
+
+	require.NoError(t, store.Release("other", "cid"))
+	existing, err = reserve("other", "digest", now)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	assert.Error(t, store.Complete(&offchain.CommandRecord{Sender: "sender", Cid: "unknown"}))
+}
+
+func TestServerDeduplicatesCommands(t *testing.T) {
+	handled := 0
+	server := offchain.NewServer().WithCommandStore(offchain.NewMemoryCommandStore())
+	server.Handle("CountCommand", func(r *http.Request, req *offchain.CommandRequestObject) (interface{}, *offchain.Error) {
+		handled++
+		return map[string]int{"count": handled}, nil
+	})
+	s := httptest.NewServer(server)
+	defer s.Close()
+
+	send := func(sender, command string) *offchain.CommandResponseObject {
+		body, _ := json.Marshal(offchain.CommandRequestObject{
+			ObjectType:  offchain.CommandRequestObjectType,
+			CommandType: "CountCommand",
+			Command:     json.RawMessage(command),
+			Cid:         "2c8d1d0c-6f3e-4b8e-9d3b-3c1c0f0e9a11",
+		})
+		req, _ := http.NewRequest(http.MethodPost, s.URL, bytes.NewBuffer(body))
+		req.Header.Set(offchain.RequestIDHeader, newReferenceID(t))
+		req.Header.Set(offchain.RequestSenderHeader, sender)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		var ret offchain.CommandResponseObject
+		require.NoError(t, json.Unmarshal(respBody, &ret))
+		return &ret
+	}
+
+	first := send(senderAddress, `{"a": 1}`)
+	assert.Equal(t, offchain.StatusSuccess, first.Status)
+	assert.JSONEq(t, `{"count": 1}`, string(first.Result))
+
+	replay := send(senderAddress, `{"a": 1}`)
+	assert.Equal(t, first, replay)
+	assert.Equal(t, 1, handled)
+
+	account, err := diemid.DecodeToAccount(diemid.TestnetPrefix, senderAddress)
+	require.NoError(t, err)
+	withSubAddress, err := diemid.EncodeAccount(diemid.TestnetPrefix, account.AccountAddress, diemtypes.MustGenSubAddress())
+	require.NoError(t, err)
+	assert.Equal(t, first, send(withSubAddress, `{"a": 1}`), "deduplicated by account address")
+	assert.Equal(t, first, send(strings.ToUpper(senderAddress), `{"a": 1}`), "deduplicated by account address")
+	assert.Equal(t, 1, handled)
+
+	invalid := send("invalid", `{"a": 1}`)
+	assert.Equal(t, offchain.StatusFailure, invalid.Status)
+	assert.Equal(t, offchain.InvalidHTTPHeaderErrorCode, invalid.Error.Code)
+
+	conflict := send(senderAddress, `{"a": 2}`)
+	assert.Equal(t, offchain.StatusFailure, conflict.Status)
+	assert.Equal(t, offchain.ConflictErrorCode, conflict.Error.Code)
+	assert.Equal(t, 1, handled)
+}
+
+func TestServerCommandStoreErrors(t *testing.T) {
+	release := make(chan struct{})
+	store := &failingCommandStore{CommandStore: offchain.NewMemoryCommandStore()}
+	server := offchain.NewServer().WithCommandStore(store)
+	server.Handle("WaitCommand", func(r *http.Request, req *offchain.CommandRequestObject) (interface{}, *offchain.Error) {
+		<-release
+		return nil, nil
+	})
+	s := httptest.NewServer(server)
+	defer s.Close()
+
+	send := func(cid string) int {
+		body, _ := json.Marshal(offchain.CommandRequestObject{
+			ObjectType:  offchain.CommandRequestObjectType,
+			CommandType: "WaitCommand",
+			Cid:         cid,
+		})
+		req, _ := http.NewRequest(http.MethodPost, s.URL, bytes.NewBuffer(body))
+		req.Header.Set(offchain.RequestIDHeader, newReferenceID(t))
+		req.Header.Set(offchain.RequestSenderHeader, senderAddress)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	first := make(chan int)
+	go func() { first <- send("cid") }()
+	require.Eventually(t, func() bool { return send("cid") == http.StatusServiceUnavailable },
+		time.Second, 10*time.Millisecond)
+	close(release)
+	assert.Equal(t, http.StatusOK, <-first)
+	assert.Equal(t, http.StatusOK, send("cid"))
+
+	store.err = errors.New("database is down")
+	assert.Equal(t, http.StatusInternalServerError, send("new-cid"))
+	store.completeErr = errors.New("database is down")
+	store.err = nil
+	assert.Equal(t, http.StatusInternalServerError, send("new-cid"))
+	store.completeErr = nil
+	assert.Equal(t, http.StatusOK, send("new-cid"), "released for retry")
+}
+
+func TestServerCommandReservationExpires(t *testing.T) {
+	store := offchain.NewMemoryCommandStore()
+	server := offchain.NewServer().WithCommandStore(store).WithReservationTTL(time.Minute)
+	s := httptest.NewServer(server)
+	defer s.Close()
+
+	body, _ := json.Marshal(offchain.CommandRequestObject{
+		ObjectType:  offchain.CommandRequestObjectType,
+		CommandType: offchain.PingCommandType,
+		Cid:         "cid",
+	})
+	send := func() int {
+		req, _ := http.NewRequest(http.MethodPost, s.URL, bytes.NewBuffer(body))
+		req.Header.Set(offchain.RequestIDHeader, newReferenceID(t))
+		req.Header.Set(offchain.RequestSenderHeader, senderAddress)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	account, err := diemid.DecodeToAccount(diemid.TestnetPrefix, senderAddress)
+	require.NoError(t, err)
+	// reservation left by a server crashed while handling the command
+	reserve := func(reservedAt time.Time) {
+		require.NoError(t, store.Release(account.AccountAddress.Hex(), "cid"))
+		existing, err := store.Reserve(&offchain.CommandRecord{
+			Sender:        account.AccountAddress.Hex(),
+			Cid:           "cid",
+			RequestDigest: offchain.RequestDigest(body),
+			ReservedAt:    reservedAt,
+		}, reservedAt)
+		require.NoError(t, err)
+		require.Nil(t, existing)
+	}
+
+	reserve(time.Now())
+	assert.Equal(t, http.StatusServiceUnavailable, send())
+	reserve(time.Now().Add(-2 * time.Minute))
+	assert.Equal(t, http.StatusOK, send())
+	assert.Equal(t, http.StatusOK, send())
+}
+
+type failingCommandStore struct {
+	offchain.CommandStore
+	err         error
+	completeErr error
+}
+
+func (s *failingCommandStore) Reserve(record *offchain.CommandRecord, staleBefore time.Time) (*offchain.CommandRecord, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.CommandStore.Reserve(record, staleBefore)
+}
+
+func (s *failingCommandStore) Complete(record *offchain.CommandRecord) error {
+	if s.completeErr != nil {
+		return s.completeErr
+	}
+	return s.CommandStore.Complete(record)
+}
+
+type fakeRecord struct {
+	digest     string
+	response   driver.Value
+	reservedAt int64
+}
+
+// newFakeDB returns `sqltest.DB` serving the statements of `SQLCommandStore` from memory
+func newFakeDB() *sqltest.DB {
+	records := make(map[[2]string]fakeRecord)
+	return &sqltest.DB{
+		Exec: func(query string, args []driver.Value) (driver.Result, error) {
+			switch {
+			case strings.HasPrefix(query, "CREATE TABLE"):
+				return driver.RowsAffected(0), nil
+			case strings.HasPrefix(query, "INSERT INTO"):
+				key := [2]string{args[0].(string), args[1].(string)}
+				if _, ok := records[key]; ok {
+					return driver.RowsAffected(0), nil
+				}
+				records[key] = fakeRecord{digest: args[2].(string), reservedAt: args[3].(int64)}
+				return driver.RowsAffected(1), nil
+			case strings.HasPrefix(query, "UPDATE") && strings.Contains(query, "SET request_digest"):
+				key := [2]string{args[2].(string), args[3].(string)}
+				record, ok := records[key]
+				if !ok || record.response != nil || record.reservedAt >= args[4].(int64) {
+					return driver.RowsAffected(0), nil
+				}
+				records[key] = fakeRecord{digest: args[0].(string), reservedAt: args[1].(int64)}
+				return driver.RowsAffected(1), nil
+			case strings.HasPrefix(query, "UPDATE"):
+				key := [2]string{args[1].(string), args[2].(string)}
+				record, ok := records[key]
+				if !ok {
+					return driver.RowsAffected(0), nil
+				}
+				record.response = args[0]
+				records[key] = record
+				return driver.RowsAffected(1), nil
+			case strings.HasPrefix(query, "DELETE"):
+				key := [2]string{args[0].(string), args[1].(string)}
+				if record, ok := records[key]; ok && record.response == nil {
+					delete(records, key)
+					return driver.RowsAffected(1), nil
+				}
+				return driver.RowsAffected(0), nil
+			}
+			return nil, fmt.Errorf("unexpected statement: %s", query)
+		},
+		Query: func(query string, args []driver.Value) (*sqltest.Rows, error) {
+			rows := &sqltest.Rows{Columns: []string{"request_digest", "response", "reserved_at"}}
+			if record, ok := records[[2]string{args[0].(string), args[1].(string)}]; ok {
+				rows.Values = [][]driver.Value{{record.digest, record.response, record.reservedAt}}
+			}
+			return rows, nil
+		},
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Object types
+const (
+	CommandRequestObjectType  = "CommandRequestObject"
+	CommandResponseObjectType = "CommandResponseObject"
+	PingCommandType           = "PingCommand"
+	PingCommandResponseType   = "PingCommandResponse"
+)
+
+// Command response status
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// Off-chain error types
+const (
+	CommandErrorType  = "command_error"
+	ProtocolErrorType = "protocol_error"
+)
+
+// Off-chain error codes
+const (
+	InvalidJSONErrorCode                   = "invalid_json"
+	InvalidObjectErrorCode                 = "invalid_object"
+	MissingFieldErrorCode                  = "missing_field"
+	InvalidFieldValueErrorCode             = "invalid_field_value"
+	UnknownCommandTypeErrorCode            = "unknown_command_type"
+	InvalidHTTPHeaderErrorCode             = "invalid_http_header"
+	MissingHTTPHeaderErrorCode             = "missing_http_header"
+	UnsupportedProtocolVersionCode         = "unsupported_protocol_version"
+	InvalidJWSErrorCode                    = "invalid_jws"
+	InvalidJWSSignatureErrorCode           = "invalid_jws_signature"
+	ConflictErrorCode                      = "conflict"
+	InvalidCommandProducerErrorCode        = "invalid_command_producer"
+	InvalidOverwriteErrorCode              = "invalid_overwrite"
+	InvalidInitialOrPriorNotFoundErrorCode = "invalid_initial_or_prior_not_found"
+)
+
+// HTTP headers
+const (
+	RequestIDHeader     = "X-REQUEST-ID"
+	RequestSenderHeader = "X-REQUEST-SENDER-ADDRESS"
+)
+
+// CommandRequestObject is the off-chain request envelope, the `Command` is raw json of the
+// command object decided by `CommandType`.
+type CommandRequestObject struct {
+	ObjectType  string          `json:"_ObjectType"`
+	CommandType string          `json:"command_type"`
+	Command     json.RawMessage `json:"command"`
+	Cid         string          `json:"cid"`
+}
+
+// CommandResponseObject is the off-chain response envelope
+type CommandResponseObject struct {
+	ObjectType string          `json:"_ObjectType"`
+	Status     string          `json:"status"`
+	Error      *Error          `json:"error,omitempty"`
+	Cid        string          `json:"cid,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+}
+
+// Error is off-chain error object responded by the server, it implements error interface.
+type Error struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	internal bool
+}
+
+// Error implements error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Type, e.Code, e.Message)
+}
+
+// NewCommandError creates command error
+func NewCommandError(code string, field string, message string) *Error {
+	return &Error{Type: CommandErrorType, Code: code, Field: field, Message: message}
+}
+
+// NewProtocolError creates protocol error
+func NewProtocolError(code string, message string) *Error {
+	return &Error{Type: ProtocolErrorType, Code: code, Message: message}
+}
+
+// NewInternalError creates error of a server failure, e.g. database error. `Server` responds
+// it with HTTP status 500 instead of a failure response, so that the request can be retried.
+func NewInternalError(message string) *Error {
+	return &Error{Message: message, internal: true}
+}
+
+// PingCommand is for checking connectivity and capabilities of counterparty service
+type PingCommand struct {
+	ObjectType string `json:"_ObjectType"`
+}
+
+// PingCommandResponse is the result of `PingCommand`, it carries the protocol versions and
+// command types the responder supports.
+type PingCommandResponse struct {
+	ObjectType            string   `json:"_ObjectType"`
+	SupportedVersions     []string `json:"supported_versions"`
+	SupportedCommandTypes []string `json:"supported_command_types"`
+}
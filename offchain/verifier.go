@@ -0,0 +1,205 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// DefaultKeyRotationGracePeriod is default duration the previous compliance key is still
+// accepted after the counterparty rotated compliance key on-chain.
+const DefaultKeyRotationGracePeriod = 10 * time.Minute
+
+// DefaultKeyRefreshInterval is default minimum interval of re-fetching compliance key of same
+// account on verification failures.
+const DefaultKeyRefreshInterval = 10 * time.Second
+
+// ComplianceKeyResolver resolves compliance public key of a VASP account
+type ComplianceKeyResolver interface {
+	ComplianceKey(address diemtypes.AccountAddress) (ed25519.PublicKey, error)
+}
+
+// ComplianceKeyRotationResolver is optionally implemented by `ComplianceKeyResolver` for
+// resolving the on-chain time the current compliance key was rotated; the time is zero if the key
+// was never rotated.
+type ComplianceKeyRotationResolver interface {
+	ComplianceKeyRotation(address diemtypes.AccountAddress) (ed25519.PublicKey, time.Time, error)
+}
+
+// OnChainComplianceKeyResolver resolves compliance key from the parent VASP account of
+// given address by `diemclient.GetParentVASPInfo`.
+type OnChainComplianceKeyResolver struct {
+	Client diemclient.Client
+}
+
+// ComplianceKey implements `ComplianceKeyResolver`
+func (r *OnChainComplianceKeyResolver) ComplianceKey(address diemtypes.AccountAddress) (ed25519.PublicKey, error) {
+	info, err := diemclient.GetParentVASPInfo(r.Client, address)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeComplianceKey(info.ComplianceKey)
+}
+
+// ComplianceKeyRotation implements `ComplianceKeyRotationResolver`, the rotation time is the
+// `time_rotated_seconds` of the last compliance key rotation event of the parent VASP account.
+func (r *OnChainComplianceKeyResolver) ComplianceKeyRotation(address diemtypes.AccountAddress) (ed25519.PublicKey, time.Time, error) {
+	info, err := diemclient.GetParentVASPInfo(r.Client, address)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	key, err := DecodeComplianceKey(info.ComplianceKey)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var last *diemclient.Event
+	for start := uint64(0); ; {
+		events, err := r.Client.GetEvents(info.ComplianceKeyRotationEventsKey, start, rotationEventsPageSize)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if len(events) > 0 {
+			last = events[len(events)-1]
+		}
+		if len(events) < rotationEventsPageSize {
+			break
+		}
+		start += uint64(len(events))
+	}
+	if last == nil || last.Data == nil {
+		return key, time.Time{}, nil
+	}
+	rotatedTo, err := DecodeComplianceKey(last.Data.NewCompliancePublicKey)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if !rotatedTo.Equal(key) {
+		return nil, time.Time{}, fmt.Errorf(
+			"compliance key of %s does not match the last rotation event, the account state may be stale", address.Hex())
+	}
+	return key, time.Unix(int64(last.Data.TimeRotatedSeconds), 0), nil
+}
+
+const rotationEventsPageSize = 100
+
+// DecodeComplianceKey decodes hex-encoded ed25519 compliance public key
+func DecodeComplianceKey(key string) (ed25519.PublicKey, error) {
+	bytes, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compliance key: %v", err)
+	}
+	if len(bytes) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid compliance key length: %d", len(bytes))
+	}
+	return ed25519.PublicKey(bytes), nil
+}
+
+// Verifier verifies JWS messages signed by counterparty compliance keys.
+// It caches compliance keys; when verification failed with cached key, it re-fetches the key
+// in case the counterparty rotated its key, at most once per `RefreshInterval` for an account.
+// After a rotation, messages signed by the previous key are still accepted within the grace
+// period since the on-chain rotation time, which requires the `Resolver` implementing
+// `ComplianceKeyRotationResolver`; otherwise the previous key is not accepted.
+type Verifier struct {
+	Resolver        ComplianceKeyResolver
+	GracePeriod     time.Duration
+	RefreshInterval time.Duration
+
+	mux  sync.Mutex
+	keys map[diemtypes.AccountAddress]*keyRecord
+	now  func() time.Time
+}
+
+type keyRecord struct {
+	current   ed25519.PublicKey
+	previous  ed25519.PublicKey
+	rotatedAt time.Time
+	fetchedAt time.Time
+}
+
+// NewVerifier creates `Verifier` with `DefaultKeyRotationGracePeriod` and
+// `DefaultKeyRefreshInterval`
+func NewVerifier(resolver ComplianceKeyResolver) *Verifier {
+	return &Verifier{
+		Resolver:        resolver,
+		GracePeriod:     DefaultKeyRotationGracePeriod,
+		RefreshInterval: DefaultKeyRefreshInterval,
+		keys:            make(map[diemtypes.AccountAddress]*keyRecord),
+		now:             time.Now,
+	}
+}
+
+// Verify verifies JWS message is signed by compliance key of given address, returns the payload.
+func (v *Verifier) Verify(address diemtypes.AccountAddress, msg string) ([]byte, error) {
+	jws, err := DecodeJWS(msg)
+	if err != nil {
+		return nil, err
+	}
+	record, err := v.cached(address)
+	if err != nil {
+		return nil, err
+	}
+	if jws.Verify(record.current) {
+		return jws.Payload, nil
+	}
+	if v.now().Sub(record.fetchedAt) >= v.RefreshInterval {
+		record, err = v.refresh(address)
+		if err != nil {
+			return nil, err
+		}
+		if jws.Verify(record.current) {
+			return jws.Payload, nil
+		}
+	}
+	if record.previous != nil && !record.rotatedAt.IsZero() &&
+		v.now().Sub(record.rotatedAt) <= v.GracePeriod && jws.Verify(record.previous) {
+		return jws.Payload, nil
+	}
+	return nil, fmt.Errorf("invalid jws signature: not signed by compliance key of %s", address.Hex())
+}
+
+func (v *Verifier) cached(address diemtypes.AccountAddress) (keyRecord, error) {
+	v.mux.Lock()
+	record, ok := v.keys[address]
+	v.mux.Unlock()
+	if ok {
+		return *record, nil
+	}
+	return v.refresh(address)
+}
+
+func (v *Verifier) refresh(address diemtypes.AccountAddress) (keyRecord, error) {
+	key, rotatedAt, err := v.resolve(address)
+	if err != nil {
+		return keyRecord{}, fmt.Errorf("resolve compliance key of %s failed: %v", address.Hex(), err)
+	}
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	record, ok := v.keys[address]
+	if !ok {
+		record = &keyRecord{current: key}
+		v.keys[address] = record
+	} else if !record.current.Equal(key) {
+		record.previous = record.current
+		record.current = key
+	}
+	record.rotatedAt = rotatedAt
+	record.fetchedAt = v.now()
+	return *record, nil
+}
+
+func (v *Verifier) resolve(address diemtypes.AccountAddress) (ed25519.PublicKey, time.Time, error) {
+	if resolver, ok := v.Resolver.(ComplianceKeyRotationResolver); ok {
+		return resolver.ComplianceKeyRotation(address)
+	}
+	key, err := v.Resolver.ComplianceKey(address)
+	return key, time.Time{}, err
+}
@@ -0,0 +1,175 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyResolver struct {
+	key   ed25519.PublicKey
+	calls int
+}
+
+func (r *keyResolver) ComplianceKey(address diemtypes.AccountAddress) (ed25519.PublicKey, error) {
+	r.calls++
+	return r.key, nil
+}
+
+type rotationResolver struct {
+	keyResolver
+	rotatedAt time.Time
+}
+
+func (r *rotationResolver) ComplianceKeyRotation(address diemtypes.AccountAddress) (ed25519.PublicKey, time.Time, error) {
+	r.calls++
+	return r.key, r.rotatedAt, nil
+}
+
+func genKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return public, private
+}
+
+func TestSignAndVerifyJWS(t *testing.T) {
+	public, private := genKey(t)
+	msg := offchain.SignJWS([]byte(`{"hello":"world"}`), private)
+
+	payload, err := offchain.VerifyJWS(msg, public)
+	require.NoError(t, err)
+	assert.Equal(t, `{"hello":"world"}`, string(payload))
+
+	other, _ := genKey(t)
+	_, err = offchain.VerifyJWS(msg, other)
+	assert.EqualError(t, err, "invalid jws signature")
+
+	_, err = offchain.VerifyJWS("invalid", public)
+	assert.EqualError(t, err, "invalid jws: expected 3 parts")
+}
+
+func TestVerifierKeyRotation(t *testing.T) {
+	address := diemtypes.AccountAddress{1}
+	oldPublic, oldPrivate := genKey(t)
+	newPublic, newPrivate := genKey(t)
+	resolver := &rotationResolver{keyResolver: keyResolver{key: oldPublic}}
+	verifier := offchain.NewVerifier(resolver)
+	verifier.RefreshInterval = 0
+
+	_, err := verifier.Verify(address, offchain.SignJWS([]byte("1"), oldPrivate))
+	require.NoError(t, err)
+	assert.Equal(t, 1, resolver.calls)
+
+	// counterparty rotated key, the new key is re-fetched on verification failure
+	resolver.key = newPublic
+	resolver.rotatedAt = time.Now().Add(-time.Minute)
+	payload, err := verifier.Verify(address, offchain.SignJWS([]byte("2"), newPrivate))
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(payload))
+	assert.Equal(t, 2, resolver.calls)
+
+	// message signed by the previous key is accepted within grace period since on-chain rotation
+	_, err = verifier.Verify(address, offchain.SignJWS([]byte("3"), oldPrivate))
+	require.NoError(t, err)
+
+	// grace period is not extended by when the verifier observed the rotation
+	resolver.rotatedAt = time.Now().Add(-offchain.DefaultKeyRotationGracePeriod - time.Second)
+	_, err = verifier.Verify(address, offchain.SignJWS([]byte("4"), oldPrivate))
+	assert.EqualError(t, err, "invalid jws signature: not signed by compliance key of "+address.Hex())
+}
+
+func TestVerifierRotationTimeUnknown(t *testing.T) {
+	address := diemtypes.AccountAddress{1}
+	oldPublic, oldPrivate := genKey(t)
+	newPublic, newPrivate := genKey(t)
+	resolver := &keyResolver{key: oldPublic}
+	verifier := offchain.NewVerifier(resolver)
+	verifier.RefreshInterval = 0
+
+	_, err := verifier.Verify(address, offchain.SignJWS([]byte("1"), oldPrivate))
+	require.NoError(t, err)
+	resolver.key = newPublic
+	_, err = verifier.Verify(address, offchain.SignJWS([]byte("2"), newPrivate))
+	require.NoError(t, err)
+	_, err = verifier.Verify(address, offchain.SignJWS([]byte("3"), oldPrivate))
+	assert.Error(t, err, "previous key is not accepted without on-chain rotation time")
+}
+
+func TestVerifierRefreshInterval(t *testing.T) {
+	address := diemtypes.AccountAddress{1}
+	public, _ := genKey(t)
+	_, other := genKey(t)
+	resolver := &keyResolver{key: public}
+	verifier := offchain.NewVerifier(resolver)
+
+	for i := 0; i < 3; i++ {
+		_, err := verifier.Verify(address, offchain.SignJWS([]byte("invalid"), other))
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, resolver.calls, "key is not re-fetched within refresh interval")
+
+	verifier.RefreshInterval = 0
+	_, err := verifier.Verify(address, offchain.SignJWS([]byte("invalid"), other))
+	assert.Error(t, err)
+	assert.Equal(t, 2, resolver.calls)
+}
+
+func TestOnChainComplianceKeyRotation(t *testing.T) {
+	oldPublic, _ := genKey(t)
+	newPublic, _ := genKey(t)
+	address := diemtypes.AccountAddress{1}
+	eventsKey := "0000000000000000" + address.Hex()
+	server := diemclienttest.NewServer()
+	server.AddAccount(&diemclient.Account{
+		Address: address.Hex(),
+		Role: &diemclient.AccountRole{
+			Type:                           diemclient.AccountRoleParentVASP,
+			ComplianceKey:                  hex.EncodeToString(oldPublic),
+			ComplianceKeyRotationEventsKey: eventsKey,
+		},
+	})
+	resolver := &offchain.OnChainComplianceKeyResolver{Client: server.Client()}
+
+	key, rotatedAt, err := resolver.ComplianceKeyRotation(address)
+	require.NoError(t, err)
+	assert.Equal(t, oldPublic, key)
+	assert.True(t, rotatedAt.IsZero())
+
+	server.Account(address).Role.ComplianceKey = hex.EncodeToString(newPublic)
+	server.AddEvents(eventsKey, &diemclient.Event{Data: &diemclient.EventData{
+		Type:                   "compliancekeyrotation",
+		NewCompliancePublicKey: hex.EncodeToString(newPublic),
+		TimeRotatedSeconds:     1600000000,
+	}})
+	key, rotatedAt, err = resolver.ComplianceKeyRotation(address)
+	require.NoError(t, err)
+	assert.Equal(t, newPublic, key)
+	assert.Equal(t, time.Unix(1600000000, 0), rotatedAt)
+}
+
+func TestServerWithVerifier(t *testing.T) {
+	public, _ := genKey(t)
+	server := offchain.NewServer().WithVerifier(
+		offchain.NewVerifier(&keyResolver{key: public}), diemid.TestnetPrefix)
+	s := httptest.NewServer(server)
+	defer s.Close()
+
+	_, err := offchain.NewClient(senderAddress).Ping(context.Background(), s.URL)
+	require.Error(t, err)
+	assert.Equal(t, offchain.InvalidJWSErrorCode, err.(*offchain.Error).Code)
+}
@@ -0,0 +1,57 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package offchain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// V2 is the off-chain API protocol version this package implements
+const V2 = "v2"
+
+// SupportedVersions is list of the protocol versions this package supports
+var SupportedVersions = []string{V2}
+
+// Capabilities are the protocol versions and command types a party supports
+type Capabilities struct {
+	Versions     []string
+	CommandTypes []string
+}
+
+// SupportsCommandType returns true if the command type is supported
+func (c *Capabilities) SupportsCommandType(commandType string) bool {
+	for _, t := range c.CommandTypes {
+		if t == commandType {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateVersion returns the highest protocol version supported by both local and remote.
+// Returns error if there is no common version.
+func NegotiateVersion(local []string, remote []string) (string, error) {
+	var ret string
+	for _, l := range local {
+		for _, r := range remote {
+			if l == r && versionNumber(l) > versionNumber(ret) {
+				ret = l
+			}
+		}
+	}
+	if ret == "" {
+		return "", fmt.Errorf("no common protocol version: local %v, remote %v", local, remote)
+	}
+	return ret, nil
+}
+
+func versionNumber(version string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,7 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides merchant payment requests: builds and validates intent identifiers with amount,
+// currency and expiration constraints, and matches incoming payments against the original
+// request with amount tolerance, currency and expiration checks.
+package paymentrequest
@@ -0,0 +1,114 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package paymentrequest
+
+import (
+	"time"
+
+	"github.com/diem/client-sdk-go/deposits"
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// Mismatch is reason an incoming payment does not match the payment request
+type Mismatch string
+
+const (
+	// MismatchReceiver payment receiver account address is not the request account address
+	MismatchReceiver Mismatch = "receiver"
+	// MismatchSubAddress payment receiver sub-address is not the request sub-address
+	MismatchSubAddress Mismatch = "subaddress"
+	// MismatchCurrency payment currency is not the requested currency
+	MismatchCurrency Mismatch = "currency"
+	// MismatchUnderpaid payment amount is less than requested amount minus tolerance
+	MismatchUnderpaid Mismatch = "underpaid"
+	// MismatchOverpaid payment amount is greater than requested amount plus tolerance
+	MismatchOverpaid Mismatch = "overpaid"
+	// MismatchExpired payment is made after the request expiration time
+	MismatchExpired Mismatch = "expired"
+	// MismatchMissingTime payment time is unknown, while the request has expiration time
+	MismatchMissingTime Mismatch = "missing_time"
+)
+
+// Payment is an incoming payment to match against a payment request
+type Payment struct {
+	Receiver diemtypes.AccountAddress
+	// SubAddress is receiver sub-address, nil if the payment has no receiver sub-address
+	SubAddress *diemtypes.SubAddress
+	Amount     diemamount.Amount
+	// Time is the payment transaction time, it is required for matching request that has
+	// expiration time
+	Time time.Time
+}
+
+// PaymentFromDeposit creates `Payment` from a detected deposit and its transaction time
+func PaymentFromDeposit(d *deposits.Deposit, txnTime time.Time) *Payment {
+	return &Payment{Receiver: d.Receiver, SubAddress: d.SubAddress, Amount: d.Amount, Time: txnTime}
+}
+
+// Result is the result of matching a payment against a payment request
+type Result struct {
+	// Mismatches is empty if the payment matches the request
+	Mismatches []Mismatch
+	// Difference is paid micro-units minus requested micro-units, zero if the request has no
+	// amount or currency mismatched
+	Difference int64
+}
+
+// Matched returns true if there is no mismatch
+func (r *Result) Matched() bool {
+	return len(r.Mismatches) == 0
+}
+
+// Has returns true if the result has the given mismatch
+func (r *Result) Has(m Mismatch) bool {
+	for _, mismatch := range r.Mismatches {
+		if mismatch == m {
+			return true
+		}
+	}
+	return false
+}
+
+// Match matches the payment against the request: receiver account address and sub-address,
+// currency if requested, amount within tolerance if requested, and expiration time.
+// A request without sub-address matches payment without receiver sub-address; a request with
+// expiration time does not match payment without time.
+func (r *Request) Match(p *Payment) *Result {
+	ret := &Result{}
+	if p.Receiver != r.Account.AccountAddress {
+		ret.Mismatches = append(ret.Mismatches, MismatchReceiver)
+	}
+	subAddress := diemtypes.EmptySubAddress
+	if p.SubAddress != nil {
+		subAddress = *p.SubAddress
+	}
+	if subAddress != r.Account.SubAddress {
+		ret.Mismatches = append(ret.Mismatches, MismatchSubAddress)
+	}
+	if r.Currency != "" && p.Amount.Currency != r.Currency {
+		ret.Mismatches = append(ret.Mismatches, MismatchCurrency)
+	} else if r.Amount != nil {
+		requested := *r.Amount
+		if p.Amount.Micro >= requested {
+			ret.Difference = int64(p.Amount.Micro - requested)
+			if p.Amount.Micro-requested > r.Tolerance {
+				ret.Mismatches = append(ret.Mismatches, MismatchOverpaid)
+			}
+		} else {
+			ret.Difference = -int64(requested - p.Amount.Micro)
+			if requested-p.Amount.Micro > r.Tolerance {
+				ret.Mismatches = append(ret.Mismatches, MismatchUnderpaid)
+			}
+		}
+	}
+	if !r.ExpiresAt.IsZero() {
+		if p.Time.IsZero() {
+			ret.Mismatches = append(ret.Mismatches, MismatchMissingTime)
+		} else if r.IsExpired(p.Time) {
+			ret.Mismatches = append(ret.Mismatches, MismatchExpired)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,164 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package paymentrequest
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// ErrInvalidRequest is wrapped by errors returned by `Request#Validate`
+var ErrInvalidRequest = errors.New("invalid payment request")
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// Request is a payment request of a merchant, it is encoded as intent identifier for
+// customer wallets to pay.
+type Request struct {
+	Account diemid.Account
+	// Currency is required when `Amount` is set
+	Currency diemamount.Currency
+	// Amount is requested micro-units amount, nil accepts any amount
+	Amount *uint64
+	// Tolerance is the max micro-units difference between the paid amount and the requested
+	// amount that is still accepted. It is merchant side policy, not encoded in the intent.
+	Tolerance uint64
+	// ExpiresAt is the time after which payment is not accepted, zero value never expires.
+	// It is encoded in seconds.
+	ExpiresAt         time.Time
+	MerchantReference string
+	RedirectURL       string
+}
+
+// New creates a `Request` paid to the given account address and sub-address
+func New(prefix diemid.NetworkPrefix, address diemtypes.AccountAddress, subAddress diemtypes.SubAddress) *Request {
+	return &Request{Account: *diemid.NewAccount(prefix, address, subAddress)}
+}
+
+// WithAmount sets requested currency and micro-units amount
+func (r *Request) WithAmount(currency diemamount.Currency, amount uint64) *Request {
+	r.Currency = currency
+	r.Amount = &amount
+	return r
+}
+
+// WithCurrency sets requested currency without amount
+func (r *Request) WithCurrency(currency diemamount.Currency) *Request {
+	r.Currency = currency
+	return r
+}
+
+// WithTolerance sets max micro-units difference between paid amount and requested amount
+func (r *Request) WithTolerance(tolerance uint64) *Request {
+	r.Tolerance = tolerance
+	return r
+}
+
+// WithExpiration sets request expiration time, it is truncated to seconds
+func (r *Request) WithExpiration(expiresAt time.Time) *Request {
+	r.ExpiresAt = expiresAt.Truncate(time.Second)
+	return r
+}
+
+// WithMerchantReference sets merchant order reference id
+func (r *Request) WithMerchantReference(ref string) *Request {
+	r.MerchantReference = ref
+	return r
+}
+
+// WithRedirectURL sets url wallet redirects to after payment
+func (r *Request) WithRedirectURL(redirectURL string) *Request {
+	r.RedirectURL = redirectURL
+	return r
+}
+
+// Validate returns error wrapping `ErrInvalidRequest` if the request is invalid: amount
+// without currency, invalid currency code, tolerance greater than amount, or invalid redirect
+// url.
+func (r *Request) Validate() error {
+	if r.Amount != nil && r.Currency == "" {
+		return fmt.Errorf("%w: currency is required for amount", ErrInvalidRequest)
+	}
+	if r.Currency != "" && !currencyCodePattern.MatchString(string(r.Currency)) {
+		return fmt.Errorf("%w: invalid currency code %q", ErrInvalidRequest, r.Currency)
+	}
+	if r.Amount != nil && r.Tolerance > *r.Amount {
+		return fmt.Errorf("%w: tolerance %d is greater than amount %d", ErrInvalidRequest, r.Tolerance, *r.Amount)
+	}
+	if !r.ExpiresAt.IsZero() && r.ExpiresAt.Unix() < 0 {
+		return fmt.Errorf("%w: invalid expiration time %v", ErrInvalidRequest, r.ExpiresAt)
+	}
+	if r.RedirectURL != "" {
+		if err := diemid.ValidateRedirectURL(r.RedirectURL); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+		}
+	}
+	return nil
+}
+
+// Intent validates the request and converts it into `diemid.Intent`
+func (r *Request) Intent() (*diemid.Intent, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	params := diemid.Params{
+		Currency:          string(r.Currency),
+		Amount:            r.Amount,
+		MerchantReference: r.MerchantReference,
+		RedirectURL:       r.RedirectURL,
+	}
+	if !r.ExpiresAt.IsZero() {
+		exp := uint64(r.ExpiresAt.Unix())
+		params.ExpirationTime = &exp
+	}
+	return &diemid.Intent{Account: r.Account, Params: params}, nil
+}
+
+// Encode validates the request and encodes it into intent identifier string
+func (r *Request) Encode() (string, error) {
+	intent, err := r.Intent()
+	if err != nil {
+		return "", err
+	}
+	return intent.Encode()
+}
+
+// FromIntent creates `Request` from a decoded intent; `Tolerance` is zero.
+func FromIntent(intent *diemid.Intent) *Request {
+	ret := &Request{
+		Account:           intent.Account,
+		Currency:          diemamount.Currency(intent.Params.Currency),
+		Amount:            intent.Params.Amount,
+		MerchantReference: intent.Params.MerchantReference,
+		RedirectURL:       intent.Params.RedirectURL,
+	}
+	if intent.Params.ExpirationTime != nil {
+		ret.ExpiresAt = time.Unix(int64(*intent.Params.ExpirationTime), 0)
+	}
+	return ret
+}
+
+// Decode decodes and validates intent identifier string of the given network prefix
+func Decode(prefix diemid.NetworkPrefix, intent string) (*Request, error) {
+	decoded, err := diemid.DecodeToIntent(prefix, intent)
+	if err != nil {
+		return nil, err
+	}
+	ret := FromIntent(decoded)
+	if err := ret.Validate(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// IsExpired returns true if the request has expiration time and it is before the given time
+func (r *Request) IsExpired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && now.After(r.ExpiresAt)
+}
@@ -0,0 +1,133 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package paymentrequest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/deposits"
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/paymentrequest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	address    = diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	subAddress = diemtypes.SubAddress{0xcf, 0xf1, 0x3e, 0x6f, 0x0a, 0x55, 0x48, 0x8e}
+	expiresAt  = time.Unix(1700000000, 0)
+)
+
+func newRequest() *paymentrequest.Request {
+	return paymentrequest.New(diemid.TestnetPrefix, address, subAddress).
+		WithAmount("XUS", 1000000).
+		WithTolerance(100).
+		WithExpiration(expiresAt).
+		WithMerchantReference("order-1")
+}
+
+func TestEncodeAndDecode(t *testing.T) {
+	encoded, err := newRequest().Encode()
+	require.NoError(t, err)
+	assert.Contains(t, encoded, "c=XUS")
+	assert.Contains(t, encoded, "am=1000000")
+	assert.Contains(t, encoded, "exp=1700000000")
+
+	decoded, err := paymentrequest.Decode(diemid.TestnetPrefix, encoded)
+	require.NoError(t, err)
+	assert.Equal(t, diemamount.Currency("XUS"), decoded.Currency)
+	require.NotNil(t, decoded.Amount)
+	assert.Equal(t, uint64(1000000), *decoded.Amount)
+	assert.True(t, expiresAt.Equal(decoded.ExpiresAt))
+	assert.Equal(t, "order-1", decoded.MerchantReference)
+	assert.Equal(t, uint64(0), decoded.Tolerance)
+	assert.Equal(t, address, decoded.Account.AccountAddress)
+	assert.Equal(t, subAddress, decoded.Account.SubAddress)
+
+	_, err = paymentrequest.Decode(diemid.MainnetPrefix, encoded)
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		request *paymentrequest.Request
+	}{
+		{"amount without currency", &paymentrequest.Request{Amount: new(uint64)}},
+		{"invalid currency code", paymentrequest.New(diemid.TestnetPrefix, address, subAddress).WithCurrency("X-US")},
+		{"tolerance greater than amount", newRequest().WithTolerance(1000001)},
+		{"invalid redirect url", newRequest().WithRedirectURL("not a url")},
+		{"invalid redirect url scheme", newRequest().WithRedirectURL("javascript:alert(1)")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.request.Validate()
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, paymentrequest.ErrInvalidRequest))
+			_, err = tc.request.Encode()
+			assert.True(t, errors.Is(err, paymentrequest.ErrInvalidRequest))
+		})
+	}
+	assert.NoError(t, newRequest().WithRedirectURL("https://merchant.com/orders/1").Validate())
+}
+
+func TestMatch(t *testing.T) {
+	paidAt := expiresAt.Add(-time.Minute)
+	payment := func(amount diemamount.Amount) *paymentrequest.Payment {
+		sub := subAddress
+		return &paymentrequest.Payment{Receiver: address, SubAddress: &sub, Amount: amount, Time: paidAt}
+	}
+
+	t.Run("matched within tolerance", func(t *testing.T) {
+		ret := newRequest().Match(payment(diemamount.New("XUS", 999950)))
+		assert.True(t, ret.Matched())
+		assert.Equal(t, int64(-50), ret.Difference)
+	})
+	t.Run("underpaid", func(t *testing.T) {
+		ret := newRequest().Match(payment(diemamount.New("XUS", 999800)))
+		assert.Equal(t, []paymentrequest.Mismatch{paymentrequest.MismatchUnderpaid}, ret.Mismatches)
+		assert.Equal(t, int64(-200), ret.Difference)
+	})
+	t.Run("overpaid", func(t *testing.T) {
+		ret := newRequest().Match(payment(diemamount.New("XUS", 1000101)))
+		assert.True(t, ret.Has(paymentrequest.MismatchOverpaid))
+		assert.Equal(t, int64(101), ret.Difference)
+	})
+	t.Run("currency mismatch", func(t *testing.T) {
+		ret := newRequest().Match(payment(diemamount.New("XDX", 1000000)))
+		assert.Equal(t, []paymentrequest.Mismatch{paymentrequest.MismatchCurrency}, ret.Mismatches)
+		assert.Equal(t, int64(0), ret.Difference)
+	})
+	t.Run("expired", func(t *testing.T) {
+		p := payment(diemamount.New("XUS", 1000000))
+		p.Time = expiresAt.Add(time.Second)
+		ret := newRequest().Match(p)
+		assert.Equal(t, []paymentrequest.Mismatch{paymentrequest.MismatchExpired}, ret.Mismatches)
+	})
+	t.Run("missing payment time", func(t *testing.T) {
+		p := payment(diemamount.New("XUS", 1000000))
+		p.Time = time.Time{}
+		ret := newRequest().Match(p)
+		assert.Equal(t, []paymentrequest.Mismatch{paymentrequest.MismatchMissingTime}, ret.Mismatches)
+	})
+	t.Run("receiver and sub-address mismatch", func(t *testing.T) {
+		d := &deposits.Deposit{
+			Receiver: diemtypes.MustMakeAccountAddress("a74fd7c46952c497e75afb0a7932586d"),
+			Amount:   diemamount.New("XUS", 1000000),
+		}
+		ret := newRequest().Match(paymentrequest.PaymentFromDeposit(d, paidAt))
+		assert.True(t, ret.Has(paymentrequest.MismatchReceiver))
+		assert.True(t, ret.Has(paymentrequest.MismatchSubAddress))
+		assert.False(t, ret.Has(paymentrequest.MismatchUnderpaid))
+	})
+	t.Run("any amount", func(t *testing.T) {
+		req := paymentrequest.New(diemid.TestnetPrefix, address, diemtypes.EmptySubAddress).WithCurrency("XUS")
+		ret := req.Match(&paymentrequest.Payment{Receiver: address, Amount: diemamount.New("XUS", 1)})
+		assert.True(t, ret.Matched())
+	})
+}
@@ -0,0 +1,11 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides payout batch processing: peer to peer payments of a batch of entries are scheduled
+// across one or more sender accounts with local sequence number management, submitted
+// idempotently by entry id (see package `idempotency`), retried on transient failures, and
+// reported per entry.
+//
+// A batch can be run again with the same entries and store after a crash, entries already
+// paid are not paid twice.
+package payouts
@@ -0,0 +1,296 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/idempotency"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnbuilder"
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// Processor defaults
+const (
+	// DefaultConcurrency is the default number of payouts in flight per sender account
+	DefaultConcurrency = 4
+	// DefaultRetries is the default max number of retries of a payout
+	DefaultRetries = 3
+	// DefaultBackoff is the default base wait duration before retrying a payout, it grows
+	// exponentially up to `DefaultMaxBackoff`
+	DefaultBackoff = time.Second
+	// DefaultMaxBackoff is the default max wait duration before retrying a payout
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Entry is a payout
+type Entry struct {
+	// ID identifies the payout, e.g. withdrawal id; it is the idempotency key of the
+	// submission, hence must be unique across batches sharing the store.
+	ID       string
+	Payee    diemtypes.AccountAddress
+	Amount   uint64
+	Currency string
+	// SubAddress is the payee sub-address, it is encoded into general metadata when Metadata
+	// is nil
+	SubAddress *diemtypes.SubAddress
+	// Metadata is the BCS-encoded payment metadata, e.g. travel rule metadata
+	Metadata          []byte
+	MetadataSignature []byte
+}
+
+// Status is status of a payout
+type Status string
+
+// List of payout statuses
+const (
+	// StatusSucceeded is for the payment is executed
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed is for the payment failed and is not retried, e.g. executed with
+	// move_abort, rejected by invalid signature, or failed after all retries
+	StatusFailed Status = "failed"
+	// StatusUnknown is for the processing is stopped by the context before the payment is
+	// confirmed, the batch should be run again
+	StatusUnknown Status = "unknown"
+)
+
+// Result is the result of a payout
+type Result struct {
+	Entry  Entry
+	Status Status
+	// Sender is the sender account of the last submission
+	Sender diemtypes.AccountAddress
+	// Transaction is the executed transaction, its vm status is failure if `Err` is
+	// `*diemclient.ExecutionError`
+	Transaction *diemclient.Transaction
+	// Attempts is the number of attempts of submitting and waiting
+	Attempts int
+	Err      error
+}
+
+// Report is the results of a batch, in the order of the entries
+type Report struct {
+	Results []*Result
+}
+
+// Count returns number of results of the status
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Processor pays batches of payouts. Create it by `New`.
+type Processor struct {
+	Client  diemclient.Client
+	Senders []diemsigner.Signer
+	// Store persists submissions by entry id, default is `idempotency.NewMemoryStore()`; use a
+	// persistent store for resuming a batch after a crash.
+	Store idempotency.Store
+	// Concurrency is number of payouts in flight per sender account
+	Concurrency int
+	// Retries is the max number of retries of a payout failed by transient errors
+	Retries int
+	// Backoff returns delay before retrying a payout, nil for no delay
+	Backoff diemclient.BackoffFunc
+	// Builder creates the transaction builder of the sender for a payout, e.g. for custom gas
+	// settings; the payload and sequence number are set by the processor.
+	Builder func(sender diemsigner.Signer) *txnbuilder.Builder
+	// OnResult is called with the result of each payout when it is finished, it may be called
+	// concurrently.
+	OnResult func(*Result)
+
+	sequences *diemclient.SequenceNumberManager
+}
+
+// New creates `Processor` paying by the sender accounts with default settings
+func New(client diemclient.Client, senders ...diemsigner.Signer) *Processor {
+	return &Processor{
+		Client:      client,
+		Senders:     senders,
+		Store:       idempotency.NewMemoryStore(),
+		Concurrency: DefaultConcurrency,
+		Retries:     DefaultRetries,
+		Backoff:     diemclient.ExponentialBackoff(DefaultBackoff, DefaultMaxBackoff),
+		Builder:     txnbuilder.NewWithSigner,
+		sequences:   diemclient.NewSequenceNumberManager(client),
+	}
+}
+
+// Run pays the entries and returns the report after all of them are finished or the context
+// is done. Returns error without paying any entry if the entries or processor settings are
+// invalid, e.g. duplicated entry ids.
+func (p *Processor) Run(ctx context.Context, entries []Entry) (*Report, error) {
+	if err := p.validate(entries); err != nil {
+		return nil, err
+	}
+	report := &Report{Results: make([]*Result, len(entries))}
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	submitter := idempotency.New(p.Client, p.Store)
+	for _, sender := range p.Senders {
+		for i := 0; i < p.Concurrency; i++ {
+			wg.Add(1)
+			go func(sender diemsigner.Signer) {
+				defer wg.Done()
+				for index := range queue {
+					result := p.pay(ctx, submitter, sender, entries[index])
+					report.Results[index] = result
+					if p.OnResult != nil {
+						p.OnResult(result)
+					}
+				}
+			}(sender)
+		}
+	}
+	for i := range entries {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return report, nil
+}
+
+func (p *Processor) validate(entries []Entry) error {
+	if len(p.Senders) == 0 {
+		return errors.New("payouts: sender is required")
+	}
+	if p.Concurrency <= 0 {
+		return fmt.Errorf("payouts: invalid concurrency %d", p.Concurrency)
+	}
+	ids := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		if entry.ID == "" {
+			return fmt.Errorf("payouts: entry %d id is empty", i)
+		}
+		if ids[entry.ID] {
+			return fmt.Errorf("payouts: entry id %#v is duplicated", entry.ID)
+		}
+		ids[entry.ID] = true
+		if entry.Amount == 0 {
+			return fmt.Errorf("payouts: entry %#v amount is zero", entry.ID)
+		}
+		if entry.Metadata != nil {
+			if err := txnmetadata.ValidateMetadataSize(entry.Metadata); err != nil {
+				return fmt.Errorf("payouts: entry %#v: %w", entry.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Processor) pay(ctx context.Context, submitter *idempotency.Submitter, sender diemsigner.Signer, entry Entry) *Result {
+	result := &Result{Entry: entry}
+	builder := p.Builder(sender).Payload(payload(entry)).SequenceNumbers(p.sequences)
+	result.Sender = diemkeys.NewAuthKey(sender.PublicKey()).AccountAddress()
+	for {
+		result.Attempts++
+		record, txn, err := p.attempt(ctx, submitter, builder, entry)
+		result.Transaction, result.Err = txn, err
+		if record != nil {
+			if address, addrErr := diemtypes.MakeAccountAddress(record.Sender); addrErr == nil {
+				result.Sender = address
+			}
+			p.settle(result.Sender, record, err)
+		}
+		var execErr *diemclient.ExecutionError
+		switch {
+		case err == nil:
+			result.Status = StatusSucceeded
+			return result
+		case errors.As(err, &execErr):
+			result.Status = StatusFailed
+			return result
+		case ctx.Err() != nil:
+			result.Status = StatusUnknown
+			return result
+		case !isRetryable(err) || result.Attempts > p.Retries:
+			result.Status = StatusFailed
+			return result
+		}
+		select {
+		case <-ctx.Done():
+			result.Status = StatusUnknown
+			return result
+		case <-time.After(p.backoff(result.Attempts)):
+		}
+	}
+}
+
+// attempt submits the entry payment or resubmits the recorded transaction, then waits for it
+func (p *Processor) attempt(ctx context.Context, submitter *idempotency.Submitter, builder *txnbuilder.Builder, entry Entry) (*idempotency.Record, *diemclient.Transaction, error) {
+	var signed *diemtypes.SignedTransaction
+	record, err := submitter.Submit(ctx, entry.ID, func(ctx context.Context) (*diemtypes.SignedTransaction, error) {
+		txn, err := builder.Context(ctx).Sign(p.Client)
+		signed = txn
+		return txn, err
+	})
+	if errors.Is(err, idempotency.ErrConcurrentSubmission) {
+		// the signed transaction is not submitted, wait for the recorded one instead
+		p.sequences.Release(signed.RawTxn.Sender, signed.RawTxn.SequenceNumber)
+		err = nil
+	}
+	if err != nil {
+		return record, nil, err
+	}
+	txn, err := submitter.Wait(ctx, record)
+	return record, txn, err
+}
+
+// settle confirms or releases the sequence number of the recorded transaction by the attempt
+// result. It stays in flight when the transaction may still be executed, and the next attempt
+// resubmits the recorded transaction.
+func (p *Processor) settle(sender diemtypes.AccountAddress, record *idempotency.Record, err error) {
+	var execErr *diemclient.ExecutionError
+	switch {
+	case err == nil || errors.As(err, &execErr):
+		p.sequences.Confirm(sender, record.SequenceNumber)
+	case diemclient.IsSequenceNumberTooOld(err):
+		// same with `SequenceNumberManager.SubmitWithSequenceNumber`, the sequence number is
+		// used by other transactions, reload it from chain
+		p.sequences.Confirm(sender, record.SequenceNumber)
+		p.sequences.Reset(sender)
+	case record.Status == idempotency.StatusRejected || diemclient.IsTransactionExpired(err):
+		p.sequences.Release(sender, record.SequenceNumber)
+	}
+}
+
+func (p *Processor) backoff(attempt int) time.Duration {
+	if p.Backoff == nil {
+		return 0
+	}
+	return p.Backoff(uint(attempt - 1))
+}
+
+func payload(entry Entry) diemtypes.TransactionPayload {
+	metadata := entry.Metadata
+	if metadata == nil && entry.SubAddress != nil {
+		metadata = txnmetadata.NewGeneralMetadataToSubAddress(*entry.SubAddress)
+	}
+	return stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+		diemtypes.Currency(entry.Currency), entry.Payee, entry.Amount, metadata, entry.MetadataSignature)
+}
+
+// isRetryable returns true for transient failures and submissions rejected by mempool state,
+// which may succeed with a new sequence number or later.
+func isRetryable(err error) bool {
+	return diemclient.IsRetryable(err) ||
+		diemclient.IsTransactionExpired(err) ||
+		errors.Is(err, diemclient.ErrMempoolFull) ||
+		errors.Is(err, diemclient.ErrSequenceNumberTooOld) ||
+		errors.Is(err, diemclient.ErrSequenceNumberTooNew)
+}
@@ -0,0 +1,194 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package payouts_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/payouts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProcessor(server *diemclienttest.Server, senders int) *payouts.Processor {
+	client := server.Client(diemclient.WithRetryPolicy(diemclient.NoRetryPolicy()))
+	processor := payouts.New(client)
+	for i := 0; i < senders; i++ {
+		keys := diemkeys.MustGenKeys()
+		server.AddAccount(&diemclient.Account{
+			Address:           keys.AccountAddress().Hex(),
+			AuthenticationKey: keys.AuthKey().Hex(),
+		})
+		processor.Senders = append(processor.Senders, diemsigner.NewKeysSigner(keys))
+	}
+	processor.Backoff = nil
+	return processor
+}
+
+func newEntries(n int) []payouts.Entry {
+	entries := make([]payouts.Entry, n)
+	for i := range entries {
+		sub := diemtypes.SubAddress{byte(i + 1)}
+		entries[i] = payouts.Entry{
+			ID:         fmt.Sprintf("withdrawal-%d", i),
+			Payee:      diemkeys.MustGenKeys().AccountAddress(),
+			SubAddress: &sub,
+			Amount:     uint64(i+1) * 100,
+			Currency:   "XUS",
+		}
+	}
+	return entries
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pays entries by all senders", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		server.HoldTransactions(true)
+		processor := newProcessor(server, 2)
+		// one payout in flight per sender, the second entry is paid by the other sender
+		processor.Concurrency = 1
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case <-time.After(10 * time.Millisecond):
+					server.ExecutePending()
+				}
+			}
+		}()
+		entries := newEntries(10)
+		report, err := processor.Run(ctx, entries)
+		require.NoError(t, err)
+		require.Len(t, report.Results, len(entries))
+		assert.Equal(t, len(entries), report.Count(payouts.StatusSucceeded))
+
+		senders := map[diemtypes.AccountAddress]int{}
+		for i, result := range report.Results {
+			assert.Equal(t, entries[i], result.Entry)
+			assert.Equal(t, 1, result.Attempts)
+			assert.NoError(t, result.Err)
+			require.NotNil(t, result.Transaction)
+			assert.Equal(t, result.Sender.Hex(), result.Transaction.Transaction.Sender)
+			senders[result.Sender]++
+		}
+		assert.Len(t, senders, 2)
+		assert.Len(t, server.Submitted(), len(entries))
+	})
+	t.Run("retry rejected submission", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		processor := newProcessor(server, 1)
+		server.Fail(diemclient.Submit, diemclienttest.MempoolIsFull(), diemclienttest.MempoolIsFull())
+		report, err := processor.Run(ctx, newEntries(1))
+		require.NoError(t, err)
+		result := report.Results[0]
+		assert.Equal(t, payouts.StatusSucceeded, result.Status)
+		assert.Equal(t, 3, result.Attempts)
+	})
+	t.Run("retry rejected submission keeps sequence numbers in flight", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		server.HoldTransactions(true)
+		processor := newProcessor(server, 1)
+		entries := newEntries(2)
+		pending, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		report, err := processor.Run(pending, entries[:1])
+		require.NoError(t, err)
+		assert.Equal(t, payouts.StatusUnknown, report.Results[0].Status)
+
+		server.Fail(diemclient.Submit, diemclienttest.MempoolIsFull())
+		rejected, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		report, err = processor.Run(rejected, entries[1:])
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Results[0].Attempts)
+
+		submitted := server.Submitted()
+		require.Len(t, submitted, 2)
+		assert.Equal(t, uint64(0), submitted[0].RawTxn.SequenceNumber)
+		assert.Equal(t, uint64(1), submitted[1].RawTxn.SequenceNumber)
+		assert.Len(t, server.ExecutePending(), 2)
+	})
+	t.Run("failed after all retries", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		processor := newProcessor(server, 1)
+		processor.Retries = 1
+		server.Fail(diemclient.Submit, diemclienttest.MempoolIsFull(), diemclienttest.MempoolIsFull())
+		report, err := processor.Run(ctx, newEntries(1))
+		require.NoError(t, err)
+		result := report.Results[0]
+		assert.Equal(t, payouts.StatusFailed, result.Status)
+		assert.Equal(t, 2, result.Attempts)
+		assert.True(t, errors.Is(result.Err, diemclient.ErrMempoolFull))
+	})
+	t.Run("execution failure is not retried", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		server.WithExecutor(func(_ *diemtypes.SignedTransaction, txn *diemclient.Transaction) {
+			txn.VmStatus = &diemclient.VmStatus{Type: diemclient.VmStatusMoveAbort}
+		})
+		processor := newProcessor(server, 1)
+		var results []*payouts.Result
+		processor.OnResult = func(result *payouts.Result) { results = append(results, result) }
+		report, err := processor.Run(ctx, newEntries(1))
+		require.NoError(t, err)
+		result := report.Results[0]
+		assert.Equal(t, payouts.StatusFailed, result.Status)
+		assert.Equal(t, 1, result.Attempts)
+		var execErr *diemclient.ExecutionError
+		assert.True(t, errors.As(result.Err, &execErr))
+		require.NotNil(t, result.Transaction)
+		assert.Equal(t, []*payouts.Result{result}, results)
+	})
+	t.Run("run again does not pay twice", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		processor := newProcessor(server, 2)
+		entries := newEntries(5)
+		_, err := processor.Run(ctx, entries)
+		require.NoError(t, err)
+
+		report, err := processor.Run(ctx, entries)
+		require.NoError(t, err)
+		assert.Equal(t, len(entries), report.Count(payouts.StatusSucceeded))
+		assert.Len(t, server.Submitted(), len(entries))
+	})
+	t.Run("context is done before executed", func(t *testing.T) {
+		server := diemclienttest.NewServer()
+		server.HoldTransactions(true)
+		processor := newProcessor(server, 1)
+		timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		report, err := processor.Run(timeout, newEntries(1))
+		require.NoError(t, err)
+		assert.Equal(t, payouts.StatusUnknown, report.Results[0].Status)
+		assert.Len(t, server.Submitted(), 1)
+	})
+	t.Run("invalid entries", func(t *testing.T) {
+		processor := newProcessor(diemclienttest.NewServer(), 1)
+		entries := newEntries(2)
+		entries[1].ID = entries[0].ID
+		_, err := processor.Run(ctx, entries)
+		assert.EqualError(t, err, `payouts: entry id "withdrawal-0" is duplicated`)
+
+		entries = newEntries(1)
+		entries[0].ID = ""
+		_, err = processor.Run(ctx, entries)
+		assert.EqualError(t, err, "payouts: entry 0 id is empty")
+
+		_, err = payouts.New(processor.Client).Run(ctx, newEntries(1))
+		assert.EqualError(t, err, "payouts: sender is required")
+	})
+}
@@ -0,0 +1,7 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides payment reconciliation: replays sent and received payment events of a set of
+// accounts within a range of ledger versions, and reports balance deltas per currency and per
+// sub-address. Event cursors are returned with the report for resuming the next reconciliation.
+package reconcile
@@ -0,0 +1,181 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/events"
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// Cursor is next event sequence number of each event key to replay
+type Cursor map[string]uint64
+
+// Delta is balance delta of a currency
+type Delta struct {
+	Received diemamount.Amount
+	Sent     diemamount.Amount
+}
+
+// Net returns received minus sent amount, and true if the net amount is negative
+func (d *Delta) Net() (diemamount.Amount, bool) {
+	if d.Received.Micro >= d.Sent.Micro {
+		return diemamount.New(d.Received.Currency, d.Received.Micro-d.Sent.Micro), false
+	}
+	return diemamount.New(d.Sent.Currency, d.Sent.Micro-d.Received.Micro), true
+}
+
+// AccountReport is balance deltas of an account
+type AccountReport struct {
+	Currencies map[diemamount.Currency]*Delta
+	// SubAddresses is balance deltas per sub-address: received payments are attributed to the
+	// receiver sub-address, and sent payments are attributed to the sender sub-address of the
+	// general metadata.
+	SubAddresses map[diemtypes.SubAddress]map[diemamount.Currency]*Delta
+}
+
+// Report is the reconciliation report of a range of ledger versions
+type Report struct {
+	StartVersion uint64
+	EndVersion   uint64
+	Accounts     map[diemtypes.AccountAddress]*AccountReport
+	// Cursor is for resuming next reconciliation starts from `EndVersion+1`
+	Cursor Cursor
+}
+
+// Reconciler replays payment events of accounts
+type Reconciler struct {
+	Client    diemclient.Client
+	Accounts  []diemtypes.AccountAddress
+	BatchSize uint64
+}
+
+// New creates `Reconciler` for given accounts
+func New(client diemclient.Client, accounts ...diemtypes.AccountAddress) *Reconciler {
+	return &Reconciler{Client: client, Accounts: accounts, BatchSize: events.DefaultBatchSize}
+}
+
+// Reconcile replays sent and received payment events of transactions in the version range
+// [start, end] from the given cursor, nil cursor replays from the first event.
+// Events before the start version are skipped, and replay of an event key stops at the first
+// event after the end version, which is the returned cursor of the event key.
+func (r *Reconciler) Reconcile(ctx context.Context, start, end uint64, cursor Cursor) (*Report, error) {
+	ret := &Report{
+		StartVersion: start,
+		EndVersion:   end,
+		Accounts:     make(map[diemtypes.AccountAddress]*AccountReport),
+		Cursor:       make(Cursor),
+	}
+	for _, address := range r.Accounts {
+		account, err := diemclient.AsContextClient(r.Client).GetAccountWithContext(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		if account == nil {
+			return nil, fmt.Errorf("account %s not found", address.Hex())
+		}
+		report := &AccountReport{
+			Currencies:   make(map[diemamount.Currency]*Delta),
+			SubAddresses: make(map[diemtypes.SubAddress]map[diemamount.Currency]*Delta),
+		}
+		for _, key := range []string{account.SentEventsKey, account.ReceivedEventsKey} {
+			stream := events.NewStream(r.Client, key, cursor[key])
+			stream.BatchSize = r.BatchSize
+			if err := r.replay(ctx, stream, start, end, report); err != nil {
+				return nil, err
+			}
+			ret.Cursor[key] = stream.Cursor()
+		}
+		ret.Accounts[address] = report
+	}
+	return ret, nil
+}
+
+func (r *Reconciler) replay(ctx context.Context, stream *events.Stream, start, end uint64, report *AccountReport) error {
+	for {
+		batch, err := stream.Poll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, event := range batch {
+			if event.TransactionVersion > end {
+				stream.Seek(event.SequenceNumber)
+				return nil
+			}
+			if event.TransactionVersion < start {
+				continue
+			}
+			if err := report.add(event); err != nil {
+				return fmt.Errorf("event %s#%d: %v", event.Key, event.SequenceNumber, err)
+			}
+		}
+		if uint64(len(batch)) < stream.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (r *AccountReport) add(event *diemclient.Event) error {
+	data, err := events.DecodeEventData(event)
+	if err != nil {
+		return err
+	}
+	var amount diemamount.Amount
+	var metadata []byte
+	var received bool
+	switch e := data.(type) {
+	case *events.ReceivedPaymentEvent:
+		amount, metadata, received = e.Amount, e.Metadata, true
+	case *events.SentPaymentEvent:
+		amount, metadata = e.Amount, e.Metadata
+	default:
+		return nil
+	}
+	if err := addDelta(r.Currencies, amount, received); err != nil {
+		return err
+	}
+	subAddress := subAddressOf(metadata, received)
+	if subAddress == nil {
+		return nil
+	}
+	deltas := r.SubAddresses[*subAddress]
+	if deltas == nil {
+		deltas = make(map[diemamount.Currency]*Delta)
+		r.SubAddresses[*subAddress] = deltas
+	}
+	return addDelta(deltas, amount, received)
+}
+
+func addDelta(deltas map[diemamount.Currency]*Delta, amount diemamount.Amount, received bool) error {
+	delta := deltas[amount.Currency]
+	if delta == nil {
+		delta = &Delta{Received: diemamount.Zero(amount.Currency), Sent: diemamount.Zero(amount.Currency)}
+		deltas[amount.Currency] = delta
+	}
+	var err error
+	if received {
+		delta.Received, err = delta.Received.Add(amount)
+	} else {
+		delta.Sent, err = delta.Sent.Add(amount)
+	}
+	return err
+}
+
+// subAddressOf returns receiver sub-address of received payment metadata, or sender sub-address
+// of sent payment metadata; nil if the metadata has no such sub-address or is invalid.
+func subAddressOf(metadata []byte, received bool) *diemtypes.SubAddress {
+	inspection, err := txnmetadata.Inspect(metadata)
+	if err != nil {
+		return nil
+	}
+	if received {
+		return inspection.ToSubAddress
+	}
+	return inspection.FromSubAddress
+}
@@ -0,0 +1,128 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package reconcile_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/events"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/reconcile"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	account = "f72589b71ff4f8d139674a3f7369c69b"
+	other   = "a74fd7c46952c497e75afb0a7932586d"
+)
+
+// server serves get_account and get_events of the account
+type server struct {
+	events map[string][]*diemclient.Event
+}
+
+func (s *server) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	req := requests[0]
+	var result interface{}
+	switch req.Method {
+	case diemclient.GetAccount:
+		result = map[string]string{
+			"address":             account,
+			"sent_events_key":     "sent",
+			"received_events_key": "received",
+		}
+	case diemclient.GetEvents:
+		start := req.Params[1].(uint64)
+		limit := req.Params[2].(uint64)
+		ret := []*diemclient.Event{}
+		for _, e := range s.events[req.Params[0].(string)] {
+			if e.SequenceNumber >= start && uint64(len(ret)) < limit {
+				ret = append(ret, e)
+			}
+		}
+		result = ret
+	}
+	bytes, _ := json.Marshal(result)
+	raw := json.RawMessage(bytes)
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{req.ID: {Result: &raw}}}
+	return stub.Call(requests...)
+}
+
+func (s *server) add(key string, version uint64, amount uint64, currency string, metadata []byte) {
+	eventType := events.SentPaymentEventType
+	if key == "received" {
+		eventType = events.ReceivedPaymentEventType
+	}
+	s.events[key] = append(s.events[key], &diemclient.Event{
+		Key:                key,
+		SequenceNumber:     uint64(len(s.events[key])),
+		TransactionVersion: version,
+		Data: &diemclient.EventData{
+			Type:     eventType,
+			Amount:   &diemclient.Amount{Amount: amount, Currency: currency},
+			Sender:   other,
+			Receiver: account,
+			Metadata: hex.EncodeToString(metadata),
+		},
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	sub1, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	sub2, _ := diemtypes.MakeSubAddress("111111153010a111")
+	s := &server{events: make(map[string][]*diemclient.Event)}
+	s.add("received", 5, 100, "XUS", txnmetadata.NewGeneralMetadataToSubAddress(sub1))
+	s.add("received", 10, 200, "XUS", txnmetadata.NewGeneralMetadataToSubAddress(sub1))
+	s.add("received", 12, 300, "XUS", txnmetadata.NewGeneralMetadataToSubAddress(sub2))
+	s.add("received", 15, 400, "XDX", nil)
+	s.add("sent", 11, 50, "XUS", txnmetadata.NewGeneralMetadataFromSubAddress(sub1))
+	s.add("received", 21, 1000, "XUS", txnmetadata.NewGeneralMetadataToSubAddress(sub1))
+	s.add("sent", 22, 500, "XUS", nil)
+
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, s)
+	address := diemtypes.MustMakeAccountAddress(account)
+	reconciler := reconcile.New(client, address)
+	reconciler.BatchSize = 2
+
+	report, err := reconciler.Reconcile(context.Background(), 10, 20, nil)
+	require.NoError(t, err)
+	assert.Equal(t, reconcile.Cursor{"sent": 1, "received": 4}, report.Cursor)
+
+	accountReport := report.Accounts[address]
+	require.NotNil(t, accountReport)
+	assert.Equal(t, map[diemamount.Currency]*reconcile.Delta{
+		"XUS": {Received: diemamount.New("XUS", 500), Sent: diemamount.New("XUS", 50)},
+		"XDX": {Received: diemamount.New("XDX", 400), Sent: diemamount.Zero("XDX")},
+	}, accountReport.Currencies)
+	assert.Equal(t, map[diemtypes.SubAddress]map[diemamount.Currency]*reconcile.Delta{
+		sub1: {"XUS": {Received: diemamount.New("XUS", 200), Sent: diemamount.New("XUS", 50)}},
+		sub2: {"XUS": {Received: diemamount.New("XUS", 300), Sent: diemamount.Zero("XUS")}},
+	}, accountReport.SubAddresses)
+
+	net, negative := accountReport.Currencies["XUS"].Net()
+	assert.Equal(t, diemamount.New("XUS", 450), net)
+	assert.False(t, negative)
+
+	t.Run("resume from cursor", func(t *testing.T) {
+		report, err := reconciler.Reconcile(context.Background(), 21, 30, report.Cursor)
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.Cursor{"sent": 2, "received": 5}, report.Cursor)
+
+		delta := report.Accounts[address].Currencies["XUS"]
+		assert.Equal(t, diemamount.New("XUS", 1000), delta.Received)
+		assert.Equal(t, diemamount.New("XUS", 500), delta.Sent)
+		_, negative := delta.Net()
+		assert.False(t, negative)
+	})
+}
@@ -0,0 +1,7 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides refund orchestration for received peer to peer payments: finds the received payment
+// event of the original transaction, creates refund metadata for the original metadata, and
+// prepares the refund peer to peer transaction for signing.
+package refunds
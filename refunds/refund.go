@@ -0,0 +1,113 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package refunds
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnbuilder"
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// ErrTravelRuleRefund is returned for refunding payment with travel rule metadata: the refund is
+// a new travel rule payment, which requires dual attestation signature of the original sender
+// by off-chain API.
+var ErrTravelRuleRefund = errors.New("refund travel rule payment requires off-chain dual attestation")
+
+// Refund is a prepared refund of a received payment
+type Refund struct {
+	// OriginalVersion is the original payment transaction version
+	OriginalVersion uint64
+	// Event is the receivedpayment event of the original payment
+	Event *diemclient.Event
+	// Sender is the refund sender: the original payment receiver
+	Sender diemtypes.AccountAddress
+	// Payee is the refund payee: the original payment sender
+	Payee    diemtypes.AccountAddress
+	Currency string
+	Amount   uint64
+	// Metadata is the BCS-encoded refund metadata
+	Metadata []byte
+	Script   diemtypes.Script
+}
+
+// Prepare prepares refund of the payment received by the receiver in the transaction of given
+// version: full amount is refunded to the original sender with RefundMetadata version 0 of the
+// original transaction version and the given reason, see `NewRefundMetadata`.
+// Returns error if the transaction is not found, or there is no receivedpayment event of the
+// receiver, or `ErrTravelRuleRefund` if the payment has travel rule metadata.
+func Prepare(client diemclient.Client, version uint64, receiver diemtypes.AccountAddress, reason diemtypes.RefundReason) (*Refund, error) {
+	return PrepareWithContext(context.Background(), client, version, receiver, reason)
+}
+
+// PrepareWithContext is `Prepare` with context
+func PrepareWithContext(ctx context.Context, client diemclient.Client, version uint64, receiver diemtypes.AccountAddress, reason diemtypes.RefundReason) (*Refund, error) {
+	txns, err := diemclient.AsContextClient(client).GetTransactionsWithContext(ctx, version, 1, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(txns) == 0 || txns[0].Version != version {
+		return nil, fmt.Errorf("transaction %d not found", version)
+	}
+	event := txnmetadata.FindRefundReferenceEventFromTransaction(txns[0], receiver)
+	if event == nil {
+		return nil, fmt.Errorf("receivedpayment event of receiver %s not found in transaction %d", receiver.Hex(), version)
+	}
+	metadata, err := hex.DecodeString(event.Data.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("decode event metadata failed: %v", err)
+	}
+	original, err := txnmetadata.Inspect(metadata)
+	if err != nil {
+		return nil, err
+	}
+	payee, err := diemtypes.MakeAccountAddress(event.Data.Sender)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event sender address %#v: %v", event.Data.Sender, err)
+	}
+	if event.Data.Amount == nil {
+		return nil, fmt.Errorf("receivedpayment event of transaction %d has no amount", version)
+	}
+	ret := &Refund{
+		OriginalVersion: version,
+		Event:           event,
+		Sender:          receiver,
+		Payee:           payee,
+		Currency:        event.Data.Amount.Currency,
+		Amount:          event.Data.Amount.Amount,
+	}
+	if ret.Metadata, err = NewRefundMetadata(original, version, reason); err != nil {
+		return nil, err
+	}
+	ret.Script = stdlib.EncodePeerToPeerWithMetadataScript(
+		diemtypes.Currency(ret.Currency), ret.Payee, ret.Amount, ret.Metadata, nil)
+	return ret, nil
+}
+
+// NewRefundMetadata creates RefundMetadata version 0 of the original transaction version and the
+// given reason for all original metadata kinds except TravelRuleMetadata.
+// The original payment metadata is inspected from the receivedpayment event of the original
+// transaction version, returns `ErrTravelRuleRefund` for TravelRuleMetadata.
+func NewRefundMetadata(original *txnmetadata.Inspection, version uint64, reason diemtypes.RefundReason) ([]byte, error) {
+	if original.Kind == txnmetadata.MetadataKindTravelRule {
+		return nil, ErrTravelRuleRefund
+	}
+	return txnmetadata.NewRefundMetadata(version, reason), nil
+}
+
+// Builder returns `txnbuilder.Builder` of the refund transaction signed by given signer, which
+// should be the signer of the original payment receiver account.
+func (r *Refund) Builder(signer diemsigner.Signer) *txnbuilder.Builder {
+	return txnbuilder.NewWithSigner(signer).
+		Sender(r.Sender).
+		Script(r.Script).
+		GasCurrency(r.Currency)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package refunds_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/refunds"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sender   = "f72589b71ff4f8d139674a3f7369c69b"
+	receiver = "a74fd7c46952c497e75afb0a7932586d"
+)
+
+func newClient(version uint64, receiver string, metadata []byte) diemclient.Client {
+	txns := json.RawMessage(fmt.Sprintf(`[{
+  "version": %d,
+  "events": [{
+    "sequence_number": 7,
+    "data": {
+      "type": "receivedpayment",
+      "amount": {"amount": 1000000, "currency": "XUS"},
+      "sender": "%s",
+      "receiver": "%s",
+      "metadata": "%s"
+    }
+  }]
+}]`, version, sender, receiver, hex.EncodeToString(metadata)))
+	return diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+		Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: {Result: &txns}},
+	})
+}
+
+func TestPrepare(t *testing.T) {
+	address := diemtypes.MustMakeAccountAddress(receiver)
+	reason := &diemtypes.RefundReason__InvalidSubaddress{}
+	subAddress, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+
+	t.Run("general metadata", func(t *testing.T) {
+		client := newClient(42, receiver, txnmetadata.NewGeneralMetadataToSubAddress(subAddress))
+		refund, err := refunds.Prepare(client, 42, address, reason)
+		require.NoError(t, err)
+		assert.Equal(t, txnmetadata.NewRefundMetadata(42, reason), refund.Metadata)
+	})
+	t.Run("payment metadata", func(t *testing.T) {
+		referenceID, err := txnmetadata.NewReferenceID()
+		require.NoError(t, err)
+		client := newClient(42, receiver, txnmetadata.NewPaymentMetadata(referenceID))
+		refund, err := refunds.Prepare(client, 42, address, reason)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(42), refund.OriginalVersion)
+		assert.Equal(t, address, refund.Sender)
+		assert.Equal(t, diemtypes.MustMakeAccountAddress(sender), refund.Payee)
+		assert.Equal(t, "XUS", refund.Currency)
+		assert.Equal(t, uint64(1000000), refund.Amount)
+		assert.Equal(t, txnmetadata.NewRefundMetadata(42, reason), refund.Metadata)
+
+		call, err := stdlib.DecodeScript(&refund.Script)
+		require.NoError(t, err)
+		p2p := call.(*stdlib.ScriptCall__PeerToPeerWithMetadata)
+		assert.Equal(t, refund.Payee, p2p.Payee)
+		assert.Equal(t, refund.Amount, p2p.Amount)
+		assert.Equal(t, refund.Metadata, p2p.Metadata)
+	})
+	t.Run("no metadata", func(t *testing.T) {
+		refund, err := refunds.Prepare(newClient(42, receiver, nil), 42, address, reason)
+		require.NoError(t, err)
+		assert.Equal(t, txnmetadata.NewRefundMetadata(42, reason), refund.Metadata)
+	})
+	t.Run("travel rule metadata", func(t *testing.T) {
+		metadata, _ := txnmetadata.NewTravelRuleMetadata("ref id", diemtypes.MustMakeAccountAddress(sender), 1000000)
+		_, err := refunds.Prepare(newClient(42, receiver, metadata), 42, address, reason)
+		assert.True(t, errors.Is(err, refunds.ErrTravelRuleRefund))
+	})
+	t.Run("transaction not found", func(t *testing.T) {
+		_, err := refunds.Prepare(newClient(43, receiver, nil), 42, address, reason)
+		assert.EqualError(t, err, "transaction 42 not found")
+	})
+	t.Run("event not found", func(t *testing.T) {
+		_, err := refunds.Prepare(newClient(42, sender, nil), 42, address, reason)
+		assert.EqualError(t, err, "receivedpayment event of receiver "+receiver+" not found in transaction 42")
+	})
+}
+
+func TestRefundBuilder(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	client := newClient(42, keys.AccountAddress().Hex(), nil)
+	refund, err := refunds.Prepare(client, 42, keys.AccountAddress(), &diemtypes.RefundReason__OtherReason{})
+	require.NoError(t, err)
+
+	txn, err := refund.Builder(diemsigner.NewKeysSigner(keys)).SequenceNumber(3).Sign(client)
+	require.NoError(t, err)
+	assert.Equal(t, keys.AccountAddress(), txn.RawTxn.Sender)
+	assert.Equal(t, uint64(3), txn.RawTxn.SequenceNumber)
+	assert.Equal(t, "XUS", txn.RawTxn.GasCurrencyCode)
+	assert.Equal(t, &diemtypes.TransactionPayload__Script{Value: refund.Script}, txn.RawTxn.Payload)
+}
@@ -0,0 +1,5 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides overflow-checked arithmetic for uint64 micro-unit currency amounts.
+package smallmath
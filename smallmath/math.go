@@ -0,0 +1,88 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package smallmath
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// OverflowError is error for the case result of an arithmetic operation can't
+// be represented by uint64, e.g. a + b > math.MaxUint64 or a - b < 0
+type OverflowError struct {
+	Op string
+	A  uint64
+	B  uint64
+}
+
+// Error implements error interface
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("uint64 overflow: %d %s %d", e.A, e.Op, e.B)
+}
+
+// Add returns a + b, or `*OverflowError` if the sum overflows uint64
+func Add(a, b uint64) (uint64, error) {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return 0, &OverflowError{Op: "+", A: a, B: b}
+	}
+	return sum, nil
+}
+
+// Sub returns a - b, or `*OverflowError` if b is greater than a
+func Sub(a, b uint64) (uint64, error) {
+	diff, borrow := bits.Sub64(a, b, 0)
+	if borrow != 0 {
+		return 0, &OverflowError{Op: "-", A: a, B: b}
+	}
+	return diff, nil
+}
+
+// Mul returns a * b, or `*OverflowError` if the product overflows uint64
+func Mul(a, b uint64) (uint64, error) {
+	hi, lo := bits.Mul64(a, b)
+	if hi != 0 {
+		return 0, &OverflowError{Op: "*", A: a, B: b}
+	}
+	return lo, nil
+}
+
+// Sum adds up all given amounts, returns `*OverflowError` on the first overflow
+func Sum(amounts ...uint64) (uint64, error) {
+	var ret uint64
+	var err error
+	for _, amount := range amounts {
+		if ret, err = Add(ret, amount); err != nil {
+			return 0, err
+		}
+	}
+	return ret, nil
+}
+
+// MustAdd calls `Add` and panics if got error
+func MustAdd(a, b uint64) uint64 {
+	ret, err := Add(a, b)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// MustSub calls `Sub` and panics if got error
+func MustSub(a, b uint64) uint64 {
+	ret, err := Sub(a, b)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// MustMul calls `Mul` and panics if got error
+func MustMul(a, b uint64) uint64 {
+	ret, err := Mul(a, b)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
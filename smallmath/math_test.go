@@ -0,0 +1,71 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package smallmath_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/diem/client-sdk-go/smallmath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdd(t *testing.T) {
+	ret, err := smallmath.Add(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), ret)
+
+	ret, err = smallmath.Add(math.MaxUint64, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), ret)
+
+	_, err = smallmath.Add(math.MaxUint64, 1)
+	assert.EqualError(t, err, "uint64 overflow: 18446744073709551615 + 1")
+}
+
+func TestSub(t *testing.T) {
+	ret, err := smallmath.Sub(3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), ret)
+
+	_, err = smallmath.Sub(2, 3)
+	assert.EqualError(t, err, "uint64 overflow: 2 - 3")
+}
+
+func TestMul(t *testing.T) {
+	ret, err := smallmath.Mul(1_000_000, 1_000_000)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1_000_000_000_000), ret)
+
+	ret, err = smallmath.Mul(0, math.MaxUint64)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), ret)
+
+	_, err = smallmath.Mul(math.MaxUint64/2+1, 2)
+	assert.Error(t, err)
+	assert.IsType(t, &smallmath.OverflowError{}, err)
+}
+
+func TestSum(t *testing.T) {
+	ret, err := smallmath.Sum()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), ret)
+
+	ret, err = smallmath.Sum(1, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), ret)
+
+	_, err = smallmath.Sum(1, math.MaxUint64, 3)
+	assert.Error(t, err)
+}
+
+func TestMustPanics(t *testing.T) {
+	assert.Panics(t, func() { smallmath.MustAdd(math.MaxUint64, 1) })
+	assert.Panics(t, func() { smallmath.MustSub(0, 1) })
+	assert.Panics(t, func() { smallmath.MustMul(math.MaxUint64, 2) })
+	assert.Equal(t, uint64(2), smallmath.MustAdd(1, 1))
+	assert.Equal(t, uint64(0), smallmath.MustSub(1, 1))
+	assert.Equal(t, uint64(4), smallmath.MustMul(2, 2))
+}
@@ -0,0 +1,199 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package stdlib
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// CustomArgumentType is Move type of custom script argument, e.g. "u64".
+// Besides the following constants, script function argument type can be vector of supported
+// types, e.g. "vector<u64>" and "vector<vector<u8>>"; argument values are encoded by
+// `diemtypes.NewTransactionArgument` and `diemtypes.EncodeBCSArgument`.
+type CustomArgumentType string
+
+const (
+	// CustomArgumentTypeBool argument value type is `bool`
+	CustomArgumentTypeBool CustomArgumentType = "bool"
+	// CustomArgumentTypeU8 argument value type is `uint8`
+	CustomArgumentTypeU8 CustomArgumentType = "u8"
+	// CustomArgumentTypeU64 argument value type is `uint64`
+	CustomArgumentTypeU64 CustomArgumentType = "u64"
+	// CustomArgumentTypeU128 argument value type is `serde.Uint128`
+	CustomArgumentTypeU128 CustomArgumentType = "u128"
+	// CustomArgumentTypeAddress argument value type is `diemtypes.AccountAddress`
+	CustomArgumentTypeAddress CustomArgumentType = "address"
+	// CustomArgumentTypeU8Vector argument value type is `[]byte`
+	CustomArgumentTypeU8Vector CustomArgumentType = "vector<u8>"
+)
+
+// ErrUnknownCustomScript is returned when encoding a custom script that is not registered
+var ErrUnknownCustomScript = errors.New("unknown custom script")
+
+// CustomArgumentABI is custom script argument name and type
+type CustomArgumentABI struct {
+	Name string
+	Type CustomArgumentType
+}
+
+// CustomScriptABI is ABI of a custom Move script function, or a custom transaction script
+// (legacy).
+// Module is required for script function; Code is required for transaction script.
+type CustomScriptABI struct {
+	// Name is the script or script function name, e.g. "peer_to_peer_with_metadata"
+	Name   string
+	Module *diemtypes.ModuleId
+	Code   []byte
+	// TyArgs is the type argument names, only the count is validated on encoding
+	TyArgs []string
+	Args   []CustomArgumentABI
+}
+
+// IsScriptFunction returns true if the ABI is script function ABI
+func (abi *CustomScriptABI) IsScriptFunction() bool {
+	return abi.Module != nil
+}
+
+// CustomRegistry registers custom script ABIs, and encodes custom scripts and script
+// functions by the registered ABIs.
+// It is safe for concurrent use.
+type CustomRegistry struct {
+	mux       sync.RWMutex
+	scripts   map[string]*customScript
+	functions map[string]*customScript
+}
+
+type customScript struct {
+	abi      CustomScriptABI
+	argTypes []diemtypes.TypeTag
+}
+
+// DefaultCustomRegistry is used by `RegisterCustomScript`, `EncodeCustomScript` and
+// `EncodeCustomScriptFunction`
+var DefaultCustomRegistry = NewCustomRegistry()
+
+// NewCustomRegistry creates an empty `CustomRegistry`
+func NewCustomRegistry() *CustomRegistry {
+	return &CustomRegistry{
+		scripts:   make(map[string]*customScript),
+		functions: make(map[string]*customScript),
+	}
+}
+
+// RegisterCustomScript registers custom script ABI to `DefaultCustomRegistry`
+func RegisterCustomScript(abi CustomScriptABI) error {
+	return DefaultCustomRegistry.Register(abi)
+}
+
+// EncodeCustomScript encodes a registered custom transaction script by `DefaultCustomRegistry`
+func EncodeCustomScript(name string, typeArgs []diemtypes.TypeTag, args []interface{}) (diemtypes.Script, error) {
+	return DefaultCustomRegistry.EncodeScript(name, typeArgs, args)
+}
+
+// EncodeCustomScriptFunction encodes a registered custom script function by
+// `DefaultCustomRegistry`
+func EncodeCustomScriptFunction(name string, typeArgs []diemtypes.TypeTag, args []interface{}) (diemtypes.TransactionPayload, error) {
+	return DefaultCustomRegistry.EncodeScriptFunction(name, typeArgs, args)
+}
+
+// Register validates and registers the custom script ABI, returns error if the ABI is invalid,
+// or a script or script function with same name is registered.
+func (r *CustomRegistry) Register(abi CustomScriptABI) error {
+	if abi.Name == "" {
+		return errors.New("custom script name is required")
+	}
+	if !abi.IsScriptFunction() && len(abi.Code) == 0 {
+		return fmt.Errorf("custom script %s: module or code is required", abi.Name)
+	}
+	script := customScript{abi: abi, argTypes: make([]diemtypes.TypeTag, len(abi.Args))}
+	for i, arg := range abi.Args {
+		typ, err := diemtypes.ParseTypeTag(string(arg.Type))
+		if err != nil {
+			return fmt.Errorf("custom script %s argument %s: %v", abi.Name, arg.Name, err)
+		}
+		if abi.IsScriptFunction() && !diemtypes.IsBCSArgumentType(typ) ||
+			!abi.IsScriptFunction() && !diemtypes.IsTransactionArgumentType(typ) {
+			return fmt.Errorf("custom script %s argument %s: unsupported type %q", abi.Name, arg.Name, arg.Type)
+		}
+		script.argTypes[i] = typ
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	scripts := r.scripts
+	if abi.IsScriptFunction() {
+		scripts = r.functions
+	}
+	if _, ok := scripts[abi.Name]; ok {
+		return fmt.Errorf("custom script %s is already registered", abi.Name)
+	}
+	scripts[abi.Name] = &script
+	return nil
+}
+
+// EncodeScript encodes a registered custom transaction script.
+// Returns `ErrUnknownCustomScript` if the script is not registered, or error if the type
+// arguments or arguments do not match the ABI.
+func (r *CustomRegistry) EncodeScript(name string, typeArgs []diemtypes.TypeTag, args []interface{}) (diemtypes.Script, error) {
+	script, err := r.get(r.scripts, name, typeArgs, args)
+	if err != nil {
+		return diemtypes.Script{}, err
+	}
+	txnArgs := make([]diemtypes.TransactionArgument, len(args))
+	for i, arg := range script.abi.Args {
+		if txnArgs[i], err = diemtypes.NewTransactionArgument(script.argTypes[i], args[i]); err != nil {
+			return diemtypes.Script{}, fmt.Errorf("custom script %s argument %s: %v", name, arg.Name, err)
+		}
+	}
+	return diemtypes.Script{
+		Code:   append([]byte(nil), script.abi.Code...),
+		TyArgs: append([]diemtypes.TypeTag{}, typeArgs...),
+		Args:   txnArgs,
+	}, nil
+}
+
+// EncodeScriptFunction encodes a registered custom script function, arguments are serialized
+// as BCS bytes.
+// Returns `ErrUnknownCustomScript` if the script function is not registered, or error if the
+// type arguments or arguments do not match the ABI.
+func (r *CustomRegistry) EncodeScriptFunction(name string, typeArgs []diemtypes.TypeTag, args []interface{}) (diemtypes.TransactionPayload, error) {
+	script, err := r.get(r.functions, name, typeArgs, args)
+	if err != nil {
+		return nil, err
+	}
+	bcsArgs := make([][]byte, len(args))
+	for i, arg := range script.abi.Args {
+		if bcsArgs[i], err = diemtypes.EncodeBCSArgument(script.argTypes[i], args[i]); err != nil {
+			return nil, fmt.Errorf("custom script function %s argument %s: %v", name, arg.Name, err)
+		}
+	}
+	return &diemtypes.TransactionPayload__ScriptFunction{
+		Value: diemtypes.ScriptFunction{
+			Module:   *script.abi.Module,
+			Function: diemtypes.Identifier(name),
+			TyArgs:   append([]diemtypes.TypeTag{}, typeArgs...),
+			Args:     bcsArgs,
+		},
+	}, nil
+}
+
+func (r *CustomRegistry) get(scripts map[string]*customScript, name string, typeArgs []diemtypes.TypeTag, args []interface{}) (*customScript, error) {
+	r.mux.RLock()
+	script, ok := scripts[name]
+	r.mux.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCustomScript, name)
+	}
+	if len(typeArgs) != len(script.abi.TyArgs) {
+		return nil, fmt.Errorf("custom script %s: expected %d type arguments, got %d", name, len(script.abi.TyArgs), len(typeArgs))
+	}
+	if len(args) != len(script.abi.Args) {
+		return nil, fmt.Errorf("custom script %s: expected %d arguments, got %d", name, len(script.abi.Args), len(args))
+	}
+	return script, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package stdlib_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var p2pArgs = []stdlib.CustomArgumentABI{
+	{Name: "payee", Type: stdlib.CustomArgumentTypeAddress},
+	{Name: "amount", Type: stdlib.CustomArgumentTypeU64},
+	{Name: "metadata", Type: stdlib.CustomArgumentTypeU8Vector},
+	{Name: "metadata_signature", Type: stdlib.CustomArgumentTypeU8Vector},
+}
+
+func TestCustomRegistry(t *testing.T) {
+	expectedScript := stdlib.EncodePeerToPeerWithMetadataScript(diemtypes.Currency("XUS"), payee, 100, []byte{1}, []byte{})
+	registry := stdlib.NewCustomRegistry()
+	require.NoError(t, registry.Register(stdlib.CustomScriptABI{
+		Name:   "peer_to_peer_with_metadata",
+		Module: &diemtypes.ModuleId{Address: diemtypes.MustMakeAccountAddress("00000000000000000000000000000001"), Name: "PaymentScripts"},
+		TyArgs: []string{"Currency"},
+		Args:   p2pArgs,
+	}))
+	require.NoError(t, registry.Register(stdlib.CustomScriptABI{
+		Name:   "peer_to_peer_with_metadata",
+		Code:   expectedScript.Code,
+		TyArgs: []string{"Currency"},
+		Args:   p2pArgs,
+	}))
+	require.NoError(t, registry.Register(stdlib.CustomScriptABI{
+		Name:   "all_types",
+		Module: &diemtypes.ModuleId{Name: "Custom"},
+		Args: []stdlib.CustomArgumentABI{
+			{Name: "b", Type: stdlib.CustomArgumentTypeBool},
+			{Name: "u8", Type: stdlib.CustomArgumentTypeU8},
+			{Name: "u128", Type: stdlib.CustomArgumentTypeU128},
+		},
+	}))
+	args := []interface{}{payee, uint64(100), []byte{1}, []byte{}}
+	typeArgs := []diemtypes.TypeTag{diemtypes.Currency("XUS")}
+
+	t.Run("encode script function", func(t *testing.T) {
+		payload, err := registry.EncodeScriptFunction("peer_to_peer_with_metadata", typeArgs, args)
+		require.NoError(t, err)
+		assert.Equal(t, stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			diemtypes.Currency("XUS"), payee, 100, []byte{1}, []byte{}), payload)
+
+		payload, err = registry.EncodeScriptFunction("all_types", nil, []interface{}{
+			true, uint8(8), serde.Uint128{High: 1, Low: 2}})
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{{1}, {8}, {2, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}},
+			payload.(*diemtypes.TransactionPayload__ScriptFunction).Value.Args)
+	})
+	t.Run("encode script", func(t *testing.T) {
+		script, err := registry.EncodeScript("peer_to_peer_with_metadata", typeArgs, args)
+		require.NoError(t, err)
+		assert.Equal(t, expectedScript, script)
+	})
+	t.Run("unknown script", func(t *testing.T) {
+		_, err := registry.EncodeScriptFunction("unknown", nil, nil)
+		assert.True(t, errors.Is(err, stdlib.ErrUnknownCustomScript))
+		_, err = registry.EncodeScript("all_types", nil, nil)
+		assert.True(t, errors.Is(err, stdlib.ErrUnknownCustomScript))
+	})
+	t.Run("arguments mismatch", func(t *testing.T) {
+		_, err := registry.EncodeScriptFunction("peer_to_peer_with_metadata", nil, args)
+		assert.EqualError(t, err, "custom script peer_to_peer_with_metadata: expected 1 type arguments, got 0")
+		_, err = registry.EncodeScriptFunction("peer_to_peer_with_metadata", typeArgs, args[:3])
+		assert.EqualError(t, err, "custom script peer_to_peer_with_metadata: expected 4 arguments, got 3")
+		_, err = registry.EncodeScriptFunction("peer_to_peer_with_metadata", typeArgs, []interface{}{payee, 100, []byte{1}, []byte{}})
+		assert.EqualError(t, err, "custom script function peer_to_peer_with_metadata argument amount: expected u64 value, got int")
+		_, err = registry.EncodeScript("peer_to_peer_with_metadata", typeArgs, []interface{}{"payee", uint64(100), []byte{1}, []byte{}})
+		assert.EqualError(t, err, "custom script peer_to_peer_with_metadata argument payee: expected address value, got string")
+	})
+	t.Run("invalid abi", func(t *testing.T) {
+		module := &diemtypes.ModuleId{Name: "Custom"}
+		assert.Error(t, registry.Register(stdlib.CustomScriptABI{Module: module}))
+		assert.Error(t, registry.Register(stdlib.CustomScriptABI{Name: "no_code"}))
+		assert.Error(t, registry.Register(stdlib.CustomScriptABI{Name: "all_types", Module: module}))
+		assert.Error(t, registry.Register(stdlib.CustomScriptABI{
+			Name: "u64_vector",
+			Code: []byte{1},
+			Args: []stdlib.CustomArgumentABI{{Name: "a", Type: "vector<u64>"}},
+		}))
+		assert.Error(t, registry.Register(stdlib.CustomScriptABI{
+			Name:   "signer",
+			Module: module,
+			Args:   []stdlib.CustomArgumentABI{{Name: "a", Type: "signer"}},
+		}))
+		assert.Error(t, registry.Register(stdlib.CustomScriptABI{
+			Name:   "invalid_type",
+			Module: module,
+			Args:   []stdlib.CustomArgumentABI{{Name: "a", Type: "vector<"}},
+		}))
+	})
+}
+
+func TestEncodeCustomScriptFunction(t *testing.T) {
+	require.NoError(t, stdlib.RegisterCustomScript(stdlib.CustomScriptABI{
+		Name:   "set_limit",
+		Module: &diemtypes.ModuleId{Name: "CustomScripts"},
+		Args: []stdlib.CustomArgumentABI{
+			{Name: "limit", Type: stdlib.CustomArgumentTypeU64},
+			{Name: "tiers", Type: "vector<u64>"},
+		},
+	}))
+	payload, err := stdlib.EncodeCustomScriptFunction("set_limit", nil, []interface{}{uint64(1), []uint64{2}})
+	require.NoError(t, err)
+	assert.Equal(t, diemtypes.ScriptFunction{
+		Module:   diemtypes.ModuleId{Name: "CustomScripts"},
+		Function: "set_limit",
+		TyArgs:   []diemtypes.TypeTag{},
+		Args:     [][]byte{{1, 0, 0, 0, 0, 0, 0, 0}, {1, 2, 0, 0, 0, 0, 0, 0, 0}},
+	}, payload.(*diemtypes.TransactionPayload__ScriptFunction).Value)
+
+	_, err = stdlib.EncodeCustomScript("set_limit", nil, []interface{}{uint64(1), []uint64{2}})
+	assert.True(t, errors.Is(err, stdlib.ErrUnknownCustomScript))
+}
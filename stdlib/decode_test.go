@@ -0,0 +1,67 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package stdlib_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var payee = diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+
+func TestDecodeScript(t *testing.T) {
+	call := &stdlib.ScriptCall__PeerToPeerWithMetadata{
+		Currency:          diemtypes.Currency("XUS"),
+		Payee:             payee,
+		Amount:            100,
+		Metadata:          []byte{1, 2},
+		MetadataSignature: []byte{},
+	}
+	script := stdlib.EncodeScript(call)
+
+	ret, err := stdlib.DecodeScript(&script)
+	require.NoError(t, err)
+	assert.Equal(t, call, ret)
+
+	t.Run("unknown script", func(t *testing.T) {
+		_, err := stdlib.DecodeScript(&diemtypes.Script{Code: []byte{1}})
+		assert.Error(t, err)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		invalid := script
+		invalid.Args = invalid.Args[:1]
+		_, err := stdlib.DecodeScript(&invalid)
+		assert.Error(t, err)
+	})
+}
+
+func TestDecodeScriptFunctionPayload(t *testing.T) {
+	call := &stdlib.ScriptFunctionCall__PeerToPeerWithMetadata{
+		Currency:          diemtypes.Currency("XUS"),
+		Payee:             payee,
+		Amount:            100,
+		Metadata:          []byte{1, 2},
+		MetadataSignature: []byte{},
+	}
+	payload := stdlib.EncodeScriptFunction(call)
+
+	ret, err := stdlib.DecodeScriptFunctionPayload(payload)
+	require.NoError(t, err)
+	assert.Equal(t, call, ret)
+
+	t.Run("unknown script function", func(t *testing.T) {
+		_, err := stdlib.DecodeScriptFunctionPayload(&diemtypes.TransactionPayload__ScriptFunction{
+			Value: diemtypes.ScriptFunction{Module: diemtypes.ModuleId{Name: "Unknown"}, Function: "f"},
+		})
+		assert.Error(t, err)
+	})
+	t.Run("script payload", func(t *testing.T) {
+		_, err := stdlib.DecodeScriptFunctionPayload(&diemtypes.TransactionPayload__Script{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,21 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides Treasury Compliance (TC) and Designated Dealer (DD) account operations: creating
+// parent VASP and DD accounts, tiered mint, preburn / burn / cancel burn, freezing accounts and
+// updating dual attestation limit and currency exchange rates.
+//
+// Outstanding preburn requests of a DD are read from its `Diem::PreburnQueue<Currency>` resource
+// by `GetPreburnQueue`, and can be burnt or cancelled by `Operator#BurnAll` or
+// `Operator#CancelAllBurns`.
+//
+// Sliding nonces required by the TC scripts are managed by `SlidingNonceManager`, which loads
+// the `SlidingNonce` resource of the account from chain, reserves available nonces locally, and
+// retries transactions aborted by ENONCE_TOO_OLD, ENONCE_TOO_NEW or ENONCE_ALREADY_RECORDED
+// with a reloaded nonce.
+//
+// Example:
+//
+//	operator := tcops.New(client, tcops.TreasuryComplianceAddress, diemsigner.NewKeysSigner(tcKeys))
+//	txn, err := operator.TieredMint("XUS", ddAddress, 1_000_000, 0)
+package tcops
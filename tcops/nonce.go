@@ -0,0 +1,162 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package tcops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// DefaultNonceRetries is default max number of resubmissions after sliding nonce is reloaded
+const DefaultNonceRetries = 3
+
+// MaxNonceJump is the max distance between a new nonce and the on-chain min nonce, greater
+// nonce is aborted with ENONCE_TOO_NEW
+const MaxNonceJump = 10_000
+
+// `0x1::SlidingNonce` module abort reasons
+const (
+	ReasonNonceTooOld          uint64 = 1
+	ReasonNonceTooNew          uint64 = 2
+	ReasonNonceAlreadyRecorded uint64 = 3
+)
+
+// IsNonceError returns true if the error is `*diemclient.ExecutionError` of transaction aborted
+// by `0x1::SlidingNonce` module with ENONCE_TOO_OLD, ENONCE_TOO_NEW or ENONCE_ALREADY_RECORDED.
+func IsNonceError(err error) bool {
+	var execErr *diemclient.ExecutionError
+	if !errors.As(err, &execErr) {
+		return false
+	}
+	status := execErr.VmStatus()
+	if status == nil || status.Type != diemclient.VmStatusMoveAbort ||
+		!strings.HasSuffix(status.Location, "::SlidingNonce") {
+		return false
+	}
+	switch status.AbortCode >> 8 {
+	case ReasonNonceTooOld, ReasonNonceTooNew, ReasonNonceAlreadyRecorded:
+		return true
+	}
+	return false
+}
+
+// SlidingNonceManager reserves sliding nonces of an account locally, so that TC operations can
+// be submitted concurrently. The on-chain `SlidingNonce` resource is loaded on first
+// reservation and after `Reset`; reserved nonces are the lowest nonces that are neither
+// recorded on chain nor used locally.
+type SlidingNonceManager struct {
+	Client  diemclient.Client
+	Address diemtypes.AccountAddress
+	Retries int
+
+	mux      sync.Mutex
+	onChain  *accountstate.SlidingNonce
+	reserved map[uint64]bool
+}
+
+// NewSlidingNonceManager creates `SlidingNonceManager` for the account address
+func NewSlidingNonceManager(client diemclient.Client, address diemtypes.AccountAddress) *SlidingNonceManager {
+	return &SlidingNonceManager{
+		Client:   client,
+		Address:  address,
+		Retries:  DefaultNonceRetries,
+		reserved: make(map[uint64]bool),
+	}
+}
+
+// Reserve reserves next available sliding nonce
+func (m *SlidingNonceManager) Reserve(ctx context.Context) (uint64, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.onChain == nil {
+		onChain, err := m.load(ctx)
+		if err != nil {
+			return 0, err
+		}
+		m.onChain = onChain
+		for nonce := range m.reserved {
+			if !onChain.IsAvailable(nonce) {
+				delete(m.reserved, nonce)
+			}
+		}
+	}
+	for nonce := m.onChain.MinNonce; nonce < m.onChain.MinNonce+MaxNonceJump; nonce++ {
+		if m.onChain.IsAvailable(nonce) && !m.reserved[nonce] {
+			m.reserved[nonce] = true
+			return nonce, nil
+		}
+	}
+	return 0, fmt.Errorf("no sliding nonce available for account %s", m.Address.Hex())
+}
+
+// Release releases the reserved nonce that is not recorded on chain, e.g. the transaction is
+// rejected by submission or aborted, so that it can be reserved again.
+func (m *SlidingNonceManager) Release(nonce uint64) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.reserved, nonce)
+}
+
+// Reset drops the loaded on-chain sliding nonce, it is reloaded by next reservation.
+// Locally reserved nonces are kept until they are recorded on chain or released.
+func (m *SlidingNonceManager) Reset() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.onChain = nil
+}
+
+// SubmitWithNonce reserves sliding nonce and calls `submit` with it. The nonce is released if
+// the transaction is rejected or aborted. When the transaction is aborted by a nonce error,
+// see `IsNonceError`, it reloads the on-chain sliding nonce and retries with a new nonce up to
+// `Retries` times.
+func (m *SlidingNonceManager) SubmitWithNonce(
+	ctx context.Context,
+	submit func(nonce uint64) (*diemclient.Transaction, error),
+) (*diemclient.Transaction, error) {
+	for attempt := 0; ; attempt++ {
+		nonce, err := m.Reserve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ret, err := submit(nonce)
+		if err == nil {
+			return ret, nil
+		}
+		var execErr *diemclient.ExecutionError
+		var submitErr *diemclient.SubmitError
+		if errors.As(err, &execErr) || errors.As(err, &submitErr) {
+			m.Release(nonce)
+		}
+		m.Reset()
+		if !IsNonceError(err) || attempt >= m.Retries {
+			return nil, err
+		}
+	}
+}
+
+func (m *SlidingNonceManager) load(ctx context.Context) (*accountstate.SlidingNonce, error) {
+	client, ok := m.Client.(diemclient.StateClient)
+	if !ok {
+		return nil, &diemclient.UnsupportedClientError{Client: m.Client, Interface: "StateClient"}
+	}
+	blob, err := client.GetAccountStateBlobWithContext(ctx, m.Address)
+	if err != nil {
+		return nil, err
+	}
+	if blob == nil {
+		return nil, fmt.Errorf("account %s not found", m.Address.Hex())
+	}
+	state, err := accountstate.Decode(blob)
+	if err != nil {
+		return nil, err
+	}
+	return state.SlidingNonce()
+}
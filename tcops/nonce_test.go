@@ -0,0 +1,102 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package tcops_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/avast/retry-go"
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/tcops"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlidingNonceManager(t *testing.T) {
+	c := &chain{minNonce: 100}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+	nonces := tcops.NewSlidingNonceManager(client, tcops.TreasuryComplianceAddress)
+	ctx := context.Background()
+
+	for i := uint64(0); i < 3; i++ {
+		nonce, err := nonces.Reserve(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 100+i, nonce)
+	}
+	assert.Equal(t, 1, c.stateLoad)
+
+	c.minNonce = 200
+	nonces.Reset()
+	nonce, err := nonces.Reserve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(200), nonce)
+	assert.Equal(t, 2, c.stateLoad)
+}
+
+func TestSlidingNonceManagerSelectsAvailableNonces(t *testing.T) {
+	// nonces 100, 101 and 103 are recorded
+	c := &chain{minNonce: 100, nonceMask: 0b1011}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+	nonces := tcops.NewSlidingNonceManager(client, tcops.TreasuryComplianceAddress)
+	ctx := context.Background()
+
+	reserve := func() uint64 {
+		nonce, err := nonces.Reserve(ctx)
+		require.NoError(t, err)
+		return nonce
+	}
+	assert.Equal(t, uint64(102), reserve())
+	assert.Equal(t, uint64(104), reserve())
+	assert.Equal(t, uint64(105), reserve())
+
+	nonces.Release(104)
+	assert.Equal(t, uint64(104), reserve())
+
+	// 102 and 104 are recorded, 105 is still pending
+	c.nonceMask = 0b11111
+	nonces.Reset()
+	assert.Equal(t, uint64(106), reserve())
+	nonces.Release(105)
+	assert.Equal(t, uint64(105), reserve())
+	assert.Equal(t, 2, c.stateLoad)
+}
+
+func TestIsNonceError(t *testing.T) {
+	newError := func(location string, code uint64) error {
+		return &diemclient.ExecutionError{Transaction: &diemclient.Transaction{
+			VmStatus: &diemclient.VmStatus{Type: diemclient.VmStatusMoveAbort, Location: location, AbortCode: code},
+		}}
+	}
+	module := "00000000000000000000000000000001::SlidingNonce"
+	assert.True(t, tcops.IsNonceError(newError(module, 263)))
+	assert.True(t, tcops.IsNonceError(newError(module, 519)))
+	assert.True(t, tcops.IsNonceError(newError(module, 775)))
+	assert.False(t, tcops.IsNonceError(newError(module, 1031)))
+	assert.False(t, tcops.IsNonceError(newError("00000000000000000000000000000001::DiemAccount", 263)))
+	assert.False(t, tcops.IsNonceError(errors.New("nonce too old")))
+}
+
+func TestSlidingNonceManagerErrors(t *testing.T) {
+	newManager := func(result string) *tcops.SlidingNonceManager {
+		raw := json.RawMessage(result)
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, &jsonrpctest.Stub{
+			Responses: map[jsonrpc.RequestID]jsonrpc.Response{1: {Result: &raw}},
+		}).WithRetryOptions(retry.Attempts(1))
+		return tcops.NewSlidingNonceManager(client, tcops.TreasuryComplianceAddress)
+	}
+
+	_, err := newManager(`{"version": 1}`).Reserve(context.Background())
+	assert.EqualError(t, err, "account 0000000000000000000000000b1e55ed not found")
+
+	// account state without SlidingNonce resource
+	_, err = newManager(`{"version": 1, "blob": "0100"}`).Reserve(context.Background())
+	assert.True(t, errors.Is(err, accountstate.ErrResourceNotFound))
+}
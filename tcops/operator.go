@@ -0,0 +1,131 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package tcops
+
+import (
+	"context"
+	"errors"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnbuilder"
+)
+
+// TreasuryComplianceAddress is the account address of Treasury Compliance account
+var TreasuryComplianceAddress = diemtypes.MustMakeAccountAddress("0000000000000000000000000b1e55ed")
+
+// Operator signs, submits and waits for TC or DD operation transactions sent by the account.
+// Operations requiring sliding nonce are submitted by `SlidingNonceManager#SubmitWithNonce` of
+// `Nonces`, which retries transactions aborted by nonce errors.
+type Operator struct {
+	txnbuilder.Operator
+	Nonces *SlidingNonceManager
+}
+
+// New creates `Operator` for the account address and its signer
+func New(client diemclient.Client, address diemtypes.AccountAddress, signer diemsigner.Signer) *Operator {
+	return &Operator{
+		Operator: txnbuilder.NewOperator(client, address, signer),
+		Nonces:   NewSlidingNonceManager(client, address),
+	}
+}
+
+// WithContext sets context for client calls
+func (o *Operator) WithContext(ctx context.Context) *Operator {
+	o.Operator.WithContext(ctx)
+	return o
+}
+
+// CreateParentVASPAccount creates parent VASP account of the auth key by TC account.
+// The account is created with balance of the currency, or all currencies if
+// `addAllCurrencies` is true.
+func (o *Operator) CreateParentVASPAccount(currency string, authKey diemkeys.AuthKey, humanName string, addAllCurrencies bool) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeCreateParentVaspAccountScriptFunction(
+			diemtypes.Currency(currency), nonce, authKey.AccountAddress(), authKey.Prefix(),
+			[]byte(humanName), addAllCurrencies)
+	})
+}
+
+// CreateDesignatedDealer creates designated dealer account of the auth key by TC account.
+// The account is created with balance and preburn of the currency, or all currencies if
+// `addAllCurrencies` is true.
+func (o *Operator) CreateDesignatedDealer(currency string, authKey diemkeys.AuthKey, humanName string, addAllCurrencies bool) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeCreateDesignatedDealerScriptFunction(
+			diemtypes.Currency(currency), nonce, authKey.AccountAddress(), authKey.Prefix(),
+			[]byte(humanName), addAllCurrencies)
+	})
+}
+
+// TieredMint mints amount of the currency to the designated dealer account by TC account.
+// The amount must be within the limit of the designated dealer's tier.
+func (o *Operator) TieredMint(currency string, dd diemtypes.AccountAddress, amount uint64, tierIndex uint64) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeTieredMintScriptFunction(
+			diemtypes.Currency(currency), nonce, dd, amount, tierIndex)
+	})
+}
+
+// Preburn moves amount of the currency from the designated dealer balance to its preburn area,
+// it is sent by the designated dealer account, and does not require sliding nonce.
+func (o *Operator) Preburn(currency string, amount uint64) (*diemclient.Transaction, error) {
+	return o.Submit(stdlib.EncodePreburnScriptFunction(diemtypes.Currency(currency), amount))
+}
+
+// Burn burns the preburn of the amount of the currency held by the preburn address, by TC
+// account.
+func (o *Operator) Burn(currency string, preburnAddress diemtypes.AccountAddress, amount uint64) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeBurnWithAmountScriptFunction(
+			diemtypes.Currency(currency), nonce, preburnAddress, amount)
+	})
+}
+
+// CancelBurn cancels the preburn of the amount of the currency held by the preburn address,
+// and returns the coins to the preburn address balance, by TC account.
+func (o *Operator) CancelBurn(currency string, preburnAddress diemtypes.AccountAddress, amount uint64) (*diemclient.Transaction, error) {
+	return o.Submit(stdlib.EncodeCancelBurnWithAmountScriptFunction(
+		diemtypes.Currency(currency), preburnAddress, amount))
+}
+
+// Freeze freezes the account by TC account
+func (o *Operator) Freeze(address diemtypes.AccountAddress) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeFreezeAccountScriptFunction(nonce, address)
+	})
+}
+
+// Unfreeze unfreezes the account by TC account
+func (o *Operator) Unfreeze(address diemtypes.AccountAddress) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeUnfreezeAccountScriptFunction(nonce, address)
+	})
+}
+
+// UpdateDualAttestationLimit updates the dual attestation limit in micro-XDX by TC account
+func (o *Operator) UpdateDualAttestationLimit(newMicroXDXLimit uint64) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeUpdateDualAttestationLimitScriptFunction(nonce, newMicroXDXLimit)
+	})
+}
+
+// UpdateExchangeRate updates the currency to XDX exchange rate to numerator / denominator by TC
+// account
+func (o *Operator) UpdateExchangeRate(currency string, numerator uint64, denominator uint64) (*diemclient.Transaction, error) {
+	if denominator == 0 {
+		return nil, errors.New("exchange rate denominator is 0")
+	}
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeUpdateExchangeRateScriptFunction(
+			diemtypes.Currency(currency), nonce, numerator, denominator)
+	})
+}
+
+func (o *Operator) submitWithNonce(payload func(nonce uint64) diemtypes.TransactionPayload) (*diemclient.Transaction, error) {
+	return o.SubmitWithNonce(o.Nonces, payload)
+}
@@ -0,0 +1,236 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package tcops_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/tcops"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnbuilder"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chain executes submitted transactions immediately, transactions are aborted with vm status
+// in aborts in order. Account state of all accounts has sliding nonce and XUS preburn queue of
+// the preburns amounts.
+type chain struct {
+	sequence  uint64
+	minNonce  uint64
+	nonceMask uint64
+	preburns  []uint64
+	stateLoad int
+	aborts    []string
+	submitted []*diemtypes.SignedTransaction
+}
+
+const nonceTooOldStatus = `{"type": "move_abort", "location": "00000000000000000000000000000001::SlidingNonce", "abort_code": 263}`
+
+func (c *chain) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	req := requests[0]
+	var result string
+	switch req.Method {
+	case diemclient.GetAccountStateWithProof:
+		c.stateLoad++
+		result = fmt.Sprintf(`{"version": 1, "blob": %q}`, hex.EncodeToString(c.accountStateBlob()))
+	case diemclient.GetAccount:
+		result = fmt.Sprintf(`{"sequence_number": %d}`, c.sequence)
+	case diemclient.Submit:
+		bytes, _ := hex.DecodeString(req.Params[0].(string))
+		txn, _ := diemtypes.BcsDeserializeSignedTransaction(bytes)
+		c.submitted = append(c.submitted, &txn)
+		c.sequence++
+	case diemclient.GetAccountTransaction:
+		txn := c.submitted[len(c.submitted)-1]
+		status := `{"type": "executed"}`
+		if len(c.aborts) > 0 {
+			status, c.aborts = c.aborts[0], c.aborts[1:]
+		}
+		result = fmt.Sprintf(`{"version": 1, "hash": %q, "vm_status": %s}`, txn.TransactionHash(), status)
+	}
+	var resp jsonrpc.Response
+	if result != "" {
+		raw := json.RawMessage(result)
+		resp.Result = &raw
+	}
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{req.ID: resp}}
+	return stub.Call(requests...)
+}
+
+func (c *chain) lastCall(t *testing.T) stdlib.ScriptFunctionCall {
+	require.NotEmpty(t, c.submitted)
+	call, err := stdlib.DecodeScriptFunctionPayload(c.submitted[len(c.submitted)-1].RawTxn.Payload)
+	require.NoError(t, err)
+	return call
+}
+
+func (c *chain) accountStateBlob() []byte {
+	nonce := bcs.NewSerializer()
+	_ = nonce.SerializeU64(c.minNonce)
+	_ = nonce.SerializeU64(c.nonceMask)
+	_ = nonce.SerializeU64(0)
+
+	queue := bcs.NewSerializer()
+	_ = queue.SerializeLen(uint64(len(c.preburns)))
+	for _, amount := range c.preburns {
+		_ = queue.SerializeU64(amount)
+		_ = queue.SerializeBytes(nil)
+	}
+
+	state := bcs.NewSerializer()
+	_ = state.SerializeLen(2)
+	_ = state.SerializeBytes(accountstate.ResourcePath(accountstate.SlidingNonceTag))
+	_ = state.SerializeBytes(nonce.GetBytes())
+	_ = state.SerializeBytes(accountstate.ResourcePath(accountstate.PreburnQueueTag("XUS")))
+	_ = state.SerializeBytes(queue.GetBytes())
+
+	blob := bcs.NewSerializer()
+	_ = blob.SerializeBytes(state.GetBytes())
+	return blob.GetBytes()
+}
+
+func TestOperator(t *testing.T) {
+	c := &chain{minNonce: 5}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+	tc := diemkeys.MustGenKeys()
+	operator := tcops.New(client, tcops.TreasuryComplianceAddress, diemsigner.NewKeysSigner(tc))
+	operator.Configure = func(b *txnbuilder.Builder) { b.GasCurrency("XDX") }
+	dd := diemkeys.MustGenKeys()
+	xus := diemtypes.Currency("XUS")
+
+	_, err := operator.CreateDesignatedDealer("XUS", dd.AuthKey(), "dd", true)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__CreateDesignatedDealer{
+		Currency:         xus,
+		SlidingNonce:     5,
+		Addr:             dd.AccountAddress(),
+		AuthKeyPrefix:    dd.AuthKey().Prefix(),
+		HumanName:        []byte("dd"),
+		AddAllCurrencies: true,
+	}, c.lastCall(t))
+	assert.Equal(t, tcops.TreasuryComplianceAddress, c.submitted[0].RawTxn.Sender)
+	assert.Equal(t, "XDX", c.submitted[0].RawTxn.GasCurrencyCode)
+
+	_, err = operator.CreateParentVASPAccount("XUS", dd.AuthKey(), "vasp", false)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__CreateParentVaspAccount{
+		CoinType:          xus,
+		SlidingNonce:      6,
+		NewAccountAddress: dd.AccountAddress(),
+		AuthKeyPrefix:     dd.AuthKey().Prefix(),
+		HumanName:         []byte("vasp"),
+		AddAllCurrencies:  false,
+	}, c.lastCall(t))
+
+	_, err = operator.TieredMint("XUS", dd.AccountAddress(), 1_000, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__TieredMint{
+		CoinType: xus, SlidingNonce: 7, DesignatedDealerAddress: dd.AccountAddress(), MintAmount: 1_000, TierIndex: 1,
+	}, c.lastCall(t))
+
+	_, err = operator.Burn("XUS", dd.AccountAddress(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__BurnWithAmount{
+		Token: xus, SlidingNonce: 8, PreburnAddress: dd.AccountAddress(), Amount: 100,
+	}, c.lastCall(t))
+
+	_, err = operator.CancelBurn("XUS", dd.AccountAddress(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__CancelBurnWithAmount{
+		Token: xus, PreburnAddress: dd.AccountAddress(), Amount: 100,
+	}, c.lastCall(t))
+
+	_, err = operator.Freeze(dd.AccountAddress())
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__FreezeAccount{
+		SlidingNonce: 9, ToFreezeAccount: dd.AccountAddress(),
+	}, c.lastCall(t))
+
+	_, err = operator.Unfreeze(dd.AccountAddress())
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__UnfreezeAccount{
+		SlidingNonce: 10, ToUnfreezeAccount: dd.AccountAddress(),
+	}, c.lastCall(t))
+
+	_, err = operator.UpdateDualAttestationLimit(2_000_000_000)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__UpdateDualAttestationLimit{
+		SlidingNonce: 11, NewMicroXdxLimit: 2_000_000_000,
+	}, c.lastCall(t))
+
+	_, err = operator.UpdateExchangeRate("XUS", 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__UpdateExchangeRate{
+		Currency: xus, SlidingNonce: 12, NewExchangeRateNumerator: 1, NewExchangeRateDenominator: 2,
+	}, c.lastCall(t))
+	_, err = operator.UpdateExchangeRate("XUS", 1, 0)
+	assert.EqualError(t, err, "exchange rate denominator is 0")
+
+	assert.Equal(t, 1, c.stateLoad)
+}
+
+func TestOperatorPreburn(t *testing.T) {
+	c := &chain{}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+	dd := diemkeys.MustGenKeys()
+	operator := tcops.New(client, dd.AccountAddress(), diemsigner.NewKeysSigner(dd))
+
+	_, err := operator.Preburn("XUS", 100)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__Preburn{
+		Token: diemtypes.Currency("XUS"), Amount: 100,
+	}, c.lastCall(t))
+	assert.Equal(t, 0, c.stateLoad)
+}
+
+func TestOperatorRetriesNonceError(t *testing.T) {
+	c := &chain{minNonce: 5, aborts: []string{nonceTooOldStatus}}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+	operator := tcops.New(client, tcops.TreasuryComplianceAddress, diemsigner.NewKeysSigner(diemkeys.MustGenKeys()))
+
+	_, err := operator.Freeze(testnet.DDAccountAddress)
+	require.NoError(t, err)
+	assert.Len(t, c.submitted, 2)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__FreezeAccount{
+		SlidingNonce: 5, ToFreezeAccount: testnet.DDAccountAddress,
+	}, c.lastCall(t))
+	assert.Equal(t, 2, c.stateLoad)
+
+	c.aborts = []string{nonceTooOldStatus, nonceTooOldStatus, nonceTooOldStatus, nonceTooOldStatus}
+	_, err = operator.Freeze(testnet.DDAccountAddress)
+	assert.True(t, tcops.IsNonceError(err))
+	assert.Len(t, c.submitted, 6)
+}
+
+func TestOperatorReleasesNonceOfAbortedTransaction(t *testing.T) {
+	c := &chain{minNonce: 5, aborts: []string{
+		`{"type": "move_abort", "location": "00000000000000000000000000000001::AccountFreezing", "abort_code": 1}`,
+	}}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+	operator := tcops.New(client, tcops.TreasuryComplianceAddress, diemsigner.NewKeysSigner(diemkeys.MustGenKeys()))
+
+	_, err := operator.Freeze(testnet.DDAccountAddress)
+	assert.IsType(t, &diemclient.ExecutionError{}, err)
+	assert.False(t, tcops.IsNonceError(err))
+
+	_, err = operator.Freeze(testnet.DDAccountAddress)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__FreezeAccount{
+		SlidingNonce: 5, ToFreezeAccount: testnet.DDAccountAddress,
+	}, c.lastCall(t))
+	assert.Equal(t, 2, c.stateLoad)
+}
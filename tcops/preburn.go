@@ -0,0 +1,76 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package tcops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// GetPreburnQueue reads `0x1::Diem::PreburnQueue<Currency>` resource of the designated dealer
+// account, returns empty queue if the resource is not published under the account.
+func GetPreburnQueue(client diemclient.Client, dd diemtypes.AccountAddress, currency string) (*accountstate.PreburnQueue, error) {
+	return GetPreburnQueueWithContext(context.Background(), client, dd, currency)
+}
+
+// GetPreburnQueueWithContext reads `0x1::Diem::PreburnQueue<Currency>` resource with context,
+// see `GetPreburnQueue`
+func GetPreburnQueueWithContext(ctx context.Context, client diemclient.Client, dd diemtypes.AccountAddress, currency string) (*accountstate.PreburnQueue, error) {
+	stateClient, ok := client.(diemclient.StateClient)
+	if !ok {
+		return nil, &diemclient.UnsupportedClientError{Client: client, Interface: "StateClient"}
+	}
+	blob, err := stateClient.GetAccountStateBlobWithContext(ctx, dd)
+	if err != nil {
+		return nil, err
+	}
+	if blob == nil {
+		return nil, fmt.Errorf("account %s not found", dd.Hex())
+	}
+	state, err := accountstate.Decode(blob)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := state.PreburnQueue(currency)
+	if errors.Is(err, accountstate.ErrResourceNotFound) {
+		return &accountstate.PreburnQueue{}, nil
+	}
+	return ret, err
+}
+
+// BurnAll burns all outstanding preburn requests of the currency in the designated dealer's
+// preburn queue by TC account, returns transactions of the burns in order.
+func (o *Operator) BurnAll(currency string, preburnAddress diemtypes.AccountAddress) ([]*diemclient.Transaction, error) {
+	return o.forEachPreburn(currency, preburnAddress, o.Burn)
+}
+
+// CancelAllBurns cancels all outstanding preburn requests of the currency in the designated
+// dealer's preburn queue by TC account, returns transactions of the cancellations in order.
+func (o *Operator) CancelAllBurns(currency string, preburnAddress diemtypes.AccountAddress) ([]*diemclient.Transaction, error) {
+	return o.forEachPreburn(currency, preburnAddress, o.CancelBurn)
+}
+
+func (o *Operator) forEachPreburn(
+	currency string, preburnAddress diemtypes.AccountAddress,
+	fn func(string, diemtypes.AccountAddress, uint64) (*diemclient.Transaction, error),
+) ([]*diemclient.Transaction, error) {
+	queue, err := GetPreburnQueueWithContext(o.Context(), o.Client, preburnAddress, currency)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*diemclient.Transaction
+	for _, preburn := range queue.Preburns {
+		txn, err := fn(currency, preburnAddress, preburn.Amount)
+		if err != nil {
+			return ret, err
+		}
+		ret = append(ret, txn)
+	}
+	return ret, nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package tcops_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/tcops"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPreburnQueue(t *testing.T) {
+	c := &chain{preburns: []uint64{100, 200}}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+
+	queue, err := tcops.GetPreburnQueue(client, testnet.DDAccountAddress, "XUS")
+	require.NoError(t, err)
+	assert.Equal(t, &accountstate.PreburnQueue{Preburns: []accountstate.PreburnWithMetadata{
+		{Amount: 100, Metadata: []byte{}},
+		{Amount: 200, Metadata: []byte{}},
+	}}, queue)
+	assert.Equal(t, uint64(300), queue.Total())
+	assert.True(t, queue.Contains(200))
+	assert.False(t, queue.Contains(300))
+
+	queue, err = tcops.GetPreburnQueue(client, testnet.DDAccountAddress, "XDX")
+	require.NoError(t, err)
+	assert.Empty(t, queue.Preburns)
+	assert.Equal(t, uint64(0), queue.Total())
+}
+
+func TestOperatorBurnAll(t *testing.T) {
+	c := &chain{minNonce: 1, preburns: []uint64{100, 200}}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+	operator := tcops.New(client, tcops.TreasuryComplianceAddress, diemsigner.NewKeysSigner(diemkeys.MustGenKeys()))
+	xus := diemtypes.Currency("XUS")
+
+	txns, err := operator.BurnAll("XUS", testnet.DDAccountAddress)
+	require.NoError(t, err)
+	assert.Len(t, txns, 2)
+	require.Len(t, c.submitted, 2)
+	for i, amount := range []uint64{100, 200} {
+		call, err := stdlib.DecodeScriptFunctionPayload(c.submitted[i].RawTxn.Payload)
+		require.NoError(t, err)
+		assert.Equal(t, &stdlib.ScriptFunctionCall__BurnWithAmount{
+			Token: xus, SlidingNonce: uint64(i + 1), PreburnAddress: testnet.DDAccountAddress, Amount: amount,
+		}, call)
+	}
+
+	txns, err = operator.CancelAllBurns("XUS", testnet.DDAccountAddress)
+	require.NoError(t, err)
+	assert.Len(t, txns, 2)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__CancelBurnWithAmount{
+		Token: xus, PreburnAddress: testnet.DDAccountAddress, Amount: 200,
+	}, c.lastCall(t))
+
+	txns, err = operator.BurnAll("XDX", testnet.DDAccountAddress)
+	require.NoError(t, err)
+	assert.Empty(t, txns)
+	assert.Len(t, c.submitted, 4)
+}
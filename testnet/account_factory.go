@@ -0,0 +1,203 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package testnet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+)
+
+// Default settings of `NewAccountFactory`
+const (
+	DefaultAccountCurrency              = "XUS"
+	DefaultAccountAmount         uint64 = 1_000_000
+	DefaultAccountConcurrency           = 4
+	DefaultFaucetRequestInterval        = 100 * time.Millisecond
+)
+
+// DefaultAccountFactory creates accounts by `DefaultFaucet`
+var DefaultAccountFactory = NewAccountFactory(DefaultFaucet)
+
+// GenAccounts generates n accounts with single keys in parallel by `DefaultAccountFactory`,
+// panics if any account creation failed.
+func GenAccounts(n int) []*diemkeys.Keys {
+	ret, err := DefaultAccountFactory.GenAccounts(context.Background(), n)
+	if err != nil {
+		panic(fmt.Sprintf("generate accounts failed: %s", err))
+	}
+	return ret
+}
+
+// AccountFactory creates funded accounts by faucet in parallel, with limited concurrency and
+// rate of faucet requests. Accounts can be released into a pool for reuse by later `Acquire`
+// calls, which saves faucet requests when tests don't depend on fresh account state.
+type AccountFactory struct {
+	Faucet   *Faucet
+	Currency string
+	Amount   uint64
+	// Concurrency is max number of accounts being created at the same time
+	Concurrency int
+	// Interval is min interval between faucet requests
+	Interval time.Duration
+
+	mu       sync.Mutex
+	pool     []*diemkeys.Keys
+	nextSend time.Time
+}
+
+// NewAccountFactory creates `AccountFactory` with default settings
+func NewAccountFactory(faucet *Faucet) *AccountFactory {
+	return &AccountFactory{
+		Faucet:      faucet,
+		Currency:    DefaultAccountCurrency,
+		Amount:      DefaultAccountAmount,
+		Concurrency: DefaultAccountConcurrency,
+		Interval:    DefaultFaucetRequestInterval,
+	}
+}
+
+// WithAmount sets currency and amount funded to created accounts
+func (f *AccountFactory) WithAmount(currency string, amount uint64) *AccountFactory {
+	f.Currency = currency
+	f.Amount = amount
+	return f
+}
+
+// WithRateLimit sets max number of concurrent account creations, and min interval between
+// faucet requests
+func (f *AccountFactory) WithRateLimit(concurrency int, interval time.Duration) *AccountFactory {
+	f.Concurrency = concurrency
+	f.Interval = interval
+	return f
+}
+
+// GenAccount creates a funded account
+func (f *AccountFactory) GenAccount(ctx context.Context) (*diemkeys.Keys, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	keys := diemkeys.MustGenKeys()
+	if _, err := f.Faucet.Fund(ctx, keys.AuthKey(), f.Currency, f.Amount); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GenAccounts creates n funded accounts in parallel, it returns the first error if any
+// account creation failed, and cancels the others; accounts created before the failure are
+// released into the pool.
+func (f *AccountFactory) GenAccounts(ctx context.Context, n int) ([]*diemkeys.Keys, error) {
+	return f.genAccounts(ctx, n, f.GenAccount)
+}
+
+// Acquire returns an account released into the pool, or creates a new account if the pool is
+// empty.
+func (f *AccountFactory) Acquire(ctx context.Context) (*diemkeys.Keys, error) {
+	f.mu.Lock()
+	if len(f.pool) > 0 {
+		ret := f.pool[len(f.pool)-1]
+		f.pool = f.pool[:len(f.pool)-1]
+		f.mu.Unlock()
+		return ret, nil
+	}
+	f.mu.Unlock()
+	return f.GenAccount(ctx)
+}
+
+// AcquireAccounts acquires n accounts in parallel, see `Acquire`
+func (f *AccountFactory) AcquireAccounts(ctx context.Context, n int) ([]*diemkeys.Keys, error) {
+	return f.genAccounts(ctx, n, f.Acquire)
+}
+
+// Release puts the accounts into the pool for reuse. The accounts keep their on-chain state,
+// e.g. balances and sequence numbers, callers should only release accounts that are still
+// usable by other tests.
+func (f *AccountFactory) Release(accounts ...*diemkeys.Keys) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pool = append(f.pool, accounts...)
+}
+
+// PoolSize returns number of accounts in the pool
+func (f *AccountFactory) PoolSize() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.pool)
+}
+
+func (f *AccountFactory) genAccounts(ctx context.Context, n int, gen func(context.Context) (*diemkeys.Keys, error)) ([]*diemkeys.Keys, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := f.Concurrency
+	if concurrency <= 0 || concurrency > n {
+		concurrency = n
+	}
+	ret := make([]*diemkeys.Keys, n)
+	indexes := make(chan int, n)
+	for i := range ret {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				keys, err := gen(ctx)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+				ret[i] = keys
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		f.Release(nonNil(ret)...)
+		return nil, firstErr
+	}
+	return ret, nil
+}
+
+// wait blocks until next faucet request is allowed by `Interval`
+func (f *AccountFactory) wait(ctx context.Context) error {
+	f.mu.Lock()
+	now := time.Now()
+	send := f.nextSend
+	if send.Before(now) {
+		send = now
+	}
+	f.nextSend = send.Add(f.Interval)
+	f.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(send)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func nonNil(accounts []*diemkeys.Keys) []*diemkeys.Keys {
+	var ret []*diemkeys.Keys
+	for _, keys := range accounts {
+		if keys != nil {
+			ret = append(ret, keys)
+		}
+	}
+	return ret
+}
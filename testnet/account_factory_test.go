@@ -0,0 +1,133 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package testnet_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mintingFaucet serves faucet requests by new mint transactions, and responds the transactions
+// executed for JSON-RPC calls.
+type mintingFaucet struct {
+	t        *testing.T
+	dd       *diemkeys.Keys
+	fail     bool
+	mu       sync.Mutex
+	txns     []*diemtypes.SignedTransaction
+	inflight int
+	max      int
+	starts   []time.Time
+}
+
+func (f *mintingFaucet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.inflight++
+	if f.inflight > f.max {
+		f.max = f.inflight
+	}
+	f.starts = append(f.starts, time.Now())
+	if f.fail {
+		f.inflight--
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	payload := stdlib.EncodePeerToPeerWithMetadataScriptFunction(testnet.XUS, f.dd.AccountAddress(), 100, nil, nil)
+	txn := diemsigner.SignTxn(f.dd, testnet.DDAccountAddress, uint64(len(f.txns)), payload,
+		1_000_000, 0, "XUS", uint64(time.Now().Add(time.Minute).Unix()), testnet.ChainID)
+	f.txns = append(f.txns, txn)
+	f.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	_, _ = w.Write([]byte(encodeTransactions(f.t, []*diemtypes.SignedTransaction{txn})))
+
+	f.mu.Lock()
+	f.inflight--
+	f.mu.Unlock()
+}
+
+func (f *mintingFaucet) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	req := requests[0]
+	f.mu.Lock()
+	hash := f.txns[req.Params[1].(uint64)].TransactionHash()
+	f.mu.Unlock()
+	raw := json.RawMessage(fmt.Sprintf(`{"version": 1, "hash": %q, "vm_status": {"type": "executed"}}`, hash))
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{req.ID: {Result: &raw}}}
+	return stub.Call(requests...)
+}
+
+func newAccountFactory(t *testing.T) (*testnet.AccountFactory, *mintingFaucet) {
+	faucet := &mintingFaucet{t: t, dd: diemkeys.MustGenKeys()}
+	server := httptest.NewServer(faucet)
+	t.Cleanup(server.Close)
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, faucet)
+	factory := testnet.NewAccountFactory(&testnet.Faucet{URL: server.URL, Client: client})
+	return factory, faucet
+}
+
+func TestAccountFactoryGenAccounts(t *testing.T) {
+	factory, faucet := newAccountFactory(t)
+	factory.WithRateLimit(3, 5*time.Millisecond).WithAmount("XDX", 10)
+
+	accounts, err := factory.GenAccounts(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, accounts, 10)
+	addresses := make(map[diemtypes.AccountAddress]bool)
+	for _, keys := range accounts {
+		require.NotNil(t, keys)
+		addresses[keys.AccountAddress()] = true
+	}
+	assert.Len(t, addresses, 10)
+	assert.Len(t, faucet.txns, 10)
+	assert.LessOrEqual(t, faucet.max, 3)
+	assert.Greater(t, faucet.max, 1)
+	for i := 1; i < len(faucet.starts); i++ {
+		assert.GreaterOrEqual(t, int64(faucet.starts[i].Sub(faucet.starts[0])), int64(time.Duration(i)*4*time.Millisecond))
+	}
+}
+
+func TestAccountFactoryPool(t *testing.T) {
+	factory, faucet := newAccountFactory(t)
+	factory.WithRateLimit(2, 0)
+
+	accounts, err := factory.AcquireAccounts(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Len(t, faucet.txns, 2)
+	factory.Release(accounts...)
+	assert.Equal(t, 2, factory.PoolSize())
+
+	reused, err := factory.AcquireAccounts(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Len(t, reused, 3)
+	assert.Len(t, faucet.txns, 3)
+	assert.Contains(t, reused, accounts[0])
+	assert.Contains(t, reused, accounts[1])
+	assert.Equal(t, 0, factory.PoolSize())
+
+	t.Run("failure", func(t *testing.T) {
+		faucet.mu.Lock()
+		faucet.fail = true
+		faucet.mu.Unlock()
+		_, err := factory.GenAccounts(context.Background(), 2)
+		assert.EqualError(t, err, "Non 200 response: 400 ")
+	})
+}
@@ -14,6 +14,13 @@ const (
 	ChainID   byte = 2
 )
 
+// Chain ids of Diem networks other than testnet, for configuring `Config.ChainID`
+const (
+	DevnetChainID     byte = 3
+	TestingChainID    byte = 4
+	PremainnetChainID byte = 5
+)
+
 var (
 	// DDAccountAddress is testnet default dd account address
 	DDAccountAddress = diemtypes.MustMakeAccountAddress("000000000000000000000000000000DD")
@@ -26,3 +33,22 @@ var (
 	XDX = diemtypes.Currency("XDX")
 	XUS = diemtypes.Currency("XUS")
 )
+
+// Config is configuration of a network with faucet service, e.g. testnet, devnet, premainnet
+// or a private network.
+type Config struct {
+	JSONRPCURL string
+	FaucetURL  string
+	ChainID    byte
+	// Currencies are currency codes supported by the faucet, any currency is accepted if it is
+	// empty.
+	Currencies []string
+}
+
+// TestnetConfig is the configuration of testnet
+var TestnetConfig = Config{
+	JSONRPCURL: URL,
+	FaucetURL:  FaucetURL,
+	ChainID:    ChainID,
+	Currencies: []string{"XUS", "XDX"},
+}
@@ -2,4 +2,20 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Provides Diem Testnet testing utilities.
+//
+// `Faucet` mints coins by the faucet service of testnet, or another network configured by
+// `Config`, e.g. devnet, premainnet or a private network:
+//
+//	faucet := testnet.NewFaucet(testnet.Config{
+//		JSONRPCURL: "http://localhost:8080/v1",
+//		FaucetURL:  "http://localhost:8000/mint",
+//		ChainID:    testnet.TestingChainID,
+//	})
+//	txn, err := faucet.Fund(ctx, keys.AuthKey(), "XUS", 1_000_000)
+//
+// `AccountFactory` creates funded accounts in parallel with limited concurrency and rate of
+// faucet requests, and keeps released accounts in a pool for reuse:
+//
+//	factory := testnet.NewAccountFactory(faucet).WithRateLimit(8, 50*time.Millisecond)
+//	accounts, err := factory.GenAccounts(ctx, 20)
 package testnet
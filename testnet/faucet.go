@@ -5,17 +5,53 @@ package testnet
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/diem/client-sdk-go/diemclient"
 	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemlog"
 	"github.com/diem/client-sdk-go/diemtypes"
 	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
 )
 
+// Default faucet retry settings of `NewFaucet`
+const (
+	DefaultFaucetRetries        = 5
+	DefaultFaucetBackoff        = 500 * time.Millisecond
+	DefaultFaucetMaxBackoff     = 5 * time.Second
+	maxFaucetErrorBodyLogLength = 512
+)
+
+// ErrUnsupportedCurrency is returned by `Faucet#Fund` for currency not in `Faucet.Currencies`
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// FaucetError is returned when faucet service responds non 200 status code
+type FaucetError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error implements error interface
+func (e *FaucetError) Error() string {
+	body := e.Body
+	if len(body) > maxFaucetErrorBodyLogLength {
+		body = body[:maxFaucetErrorBodyLogLength] + "..."
+	}
+	return fmt.Sprintf("Non 200 response: %d %s", e.StatusCode, body)
+}
+
+// Temporary returns true for 5xx status code, the request can be retried
+func (e *FaucetError) Temporary() bool {
+	return e.StatusCode >= 500
+}
+
 // GenAccount generate account with single keys
 func GenAccount() *diemkeys.Keys {
 	keys := diemkeys.MustGenKeys()
@@ -33,24 +69,156 @@ func GenMultiSigAccount() *diemkeys.Keys {
 // MustMint mints coins with retry, and panics if all retries failed.
 // This func also wait for next account seq.
 func MustMint(authKey string, amount uint64, currencyCode string) {
+	DefaultFaucet.MustMint(authKey, amount, currencyCode)
+}
+
+// Mint mints coints once without retry
+func Mint(authKey string, amount uint64, currencyCode string) ([]diemtypes.SignedTransaction, error) {
+	return DefaultFaucet.Mint(authKey, amount, currencyCode)
+}
+
+// Fund funds the account of the auth key by `DefaultFaucet`, see `Faucet#Fund`
+func Fund(ctx context.Context, authKey diemkeys.AuthKey, currency string, amount uint64) (*diemclient.Transaction, error) {
+	return DefaultFaucet.Fund(ctx, authKey, currency, amount)
+}
+
+// Faucet mints coins by faucet service, it can target testnet or a devnet with faucet service.
+//
+// `Mint` and `MustMint` ignore the `Currencies`, `Retries` and `Backoff` settings.
+type Faucet struct {
+	URL    string
+	Client diemclient.Client
+	// Currencies are currency codes supported by the faucet, any currency is accepted if it is
+	// empty.
+	Currencies []string
+	// Retries is max number of retries of `Fund` on faucet 5xx responses and network errors
+	Retries int
+	// Backoff is the wait duration before the first retry, it doubles on every following retry
+	// up to `DefaultFaucetMaxBackoff`.
+	Backoff time.Duration
+	// HTTPClient sends requests to faucet service, `http.DefaultClient` is used if it is nil.
+	HTTPClient *http.Client
+	// Logger logs retries of minting, nothing is logged if it is nil.
+	Logger diemlog.Logger
+}
+
+// DefaultFaucet is testnet faucet
+var DefaultFaucet = &Faucet{
+	URL:        FaucetURL,
+	Client:     Client,
+	Currencies: TestnetConfig.Currencies,
+	Retries:    DefaultFaucetRetries,
+	Backoff:    DefaultFaucetBackoff,
+}
+
+// NewFaucet creates `Faucet` for the network config with default retry settings
+func NewFaucet(config Config) *Faucet {
+	return &Faucet{
+		URL:        config.FaucetURL,
+		Client:     diemclient.New(config.ChainID, config.JSONRPCURL),
+		Currencies: config.Currencies,
+		Retries:    DefaultFaucetRetries,
+		Backoff:    DefaultFaucetBackoff,
+	}
+}
+
+// WithRetry sets max number of retries and backoff of the first retry for `Fund`
+func (f *Faucet) WithRetry(retries int, backoff time.Duration) *Faucet {
+	f.Retries = retries
+	f.Backoff = backoff
+	return f
+}
+
+// WithHTTPClient sets http client for sending requests to faucet service
+func (f *Faucet) WithHTTPClient(client *http.Client) *Faucet {
+	f.HTTPClient = client
+	return f
+}
+
+// WithLogger sets logger for logging retries of minting
+func (f *Faucet) WithLogger(logger diemlog.Logger) *Faucet {
+	f.Logger = logger
+	return f
+}
+
+// Fund mints amount of the currency to the account of the auth key, the account is created
+// if it does not exist. It retries on faucet 5xx responses and network errors with backoff,
+// waits for the faucet transactions executed, and returns the last one, which is the funding
+// transaction.
+// The context is used for faucet requests, backoff and waiting for transactions; use a context
+// with deadline to limit the total time.
+func (f *Faucet) Fund(ctx context.Context, authKey diemkeys.AuthKey, currency string, amount uint64) (*diemclient.Transaction, error) {
+	if !f.supports(currency) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	backoff := f.Backoff
+	var txns []diemtypes.SignedTransaction
+	var err error
+	for retry := 0; ; retry++ {
+		txns, err = f.MintWithContext(ctx, authKey.Hex(), amount, currency)
+		if err == nil || retry >= f.Retries || !isTemporary(ctx, err) {
+			break
+		}
+		f.logger().Log(diemlog.LevelWarn, "faucet mint failed, retrying",
+			diemlog.F("auth_key", authKey.Hex()), diemlog.F("retry", retry+1),
+			diemlog.F("backoff", backoff), diemlog.F("error", err))
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if backoff *= 2; backoff > DefaultFaucetMaxBackoff {
+			backoff = DefaultFaucetMaxBackoff
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(txns) == 0 {
+		return nil, errors.New("faucet returned no transaction")
+	}
+	var ret *diemclient.Transaction
+	for i := range txns {
+		if ret, err = diemclient.AsContextClient(f.Client).WaitForTransaction2WithContext(ctx, &txns[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// MustMint mints coins with retry, and panics if all retries failed.
+// This func also wait for next account seq.
+func (f *Faucet) MustMint(authKey string, amount uint64, currencyCode string) {
 	retry := 5
 	var err error
 	var txns []diemtypes.SignedTransaction
 	for i := 0; i < retry; i++ {
-		if txns, err = Mint(authKey, amount, currencyCode); err == nil {
-			if err = waitForTransactionsExecuted(txns); err == nil {
+		if txns, err = f.Mint(authKey, amount, currencyCode); err == nil {
+			if err = f.waitForTransactionsExecuted(txns); err == nil {
 				return
 			}
 		}
+		f.logger().Log(diemlog.LevelWarn, "faucet mint failed",
+			diemlog.F("auth_key", authKey), diemlog.F("attempt", i+1), diemlog.F("error", err))
 		time.Sleep(500 * time.Millisecond)
 	}
 	panic(fmt.Sprintf("mint coins failed with retry: %s", err))
 }
 
 // Mint mints coints once without retry
-func Mint(authKey string, amount uint64, currencyCode string) ([]diemtypes.SignedTransaction, error) {
-	url := fmt.Sprintf("%v?amount=%d&auth_key=%s&currency_code=%s&return_txns=true", FaucetURL, amount, authKey, currencyCode)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer([]byte{}))
+func (f *Faucet) Mint(authKey string, amount uint64, currencyCode string) ([]diemtypes.SignedTransaction, error) {
+	return f.MintWithContext(context.Background(), authKey, amount, currencyCode)
+}
+
+// MintWithContext mints coins once without retry, returns `*FaucetError` for non 200 response
+func (f *Faucet) MintWithContext(ctx context.Context, authKey string, amount uint64, currencyCode string) ([]diemtypes.SignedTransaction, error) {
+	endpoint := fmt.Sprintf("%v?amount=%d&auth_key=%s&currency_code=%s&return_txns=true", f.URL, amount, authKey, currencyCode)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer([]byte{}))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.httpClient().Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -60,15 +228,37 @@ func Mint(authKey string, amount uint64, currencyCode string) ([]diemtypes.Signe
 		return nil, err
 	}
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Non 200 response: %s", string(body))
+		return nil, &FaucetError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
-
 	return deserializeMintTransactions(body)
 }
 
-func waitForTransactionsExecuted(txns []diemtypes.SignedTransaction) error {
+func (f *Faucet) logger() diemlog.Logger {
+	return diemlog.Redacting(f.Logger)
+}
+
+func (f *Faucet) supports(currency string) bool {
+	if len(f.Currencies) == 0 {
+		return true
+	}
+	for _, c := range f.Currencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Faucet) httpClient() *http.Client {
+	if f.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return f.HTTPClient
+}
+
+func (f *Faucet) waitForTransactionsExecuted(txns []diemtypes.SignedTransaction) error {
 	for i := range txns {
-		_, err := Client.WaitForTransaction2(&txns[i], time.Second*30)
+		_, err := f.Client.WaitForTransaction2(&txns[i], time.Second*30)
 		if err != nil {
 			return err
 		}
@@ -76,6 +266,20 @@ func waitForTransactionsExecuted(txns []diemtypes.SignedTransaction) error {
 	return nil
 }
 
+// isTemporary returns true for faucet 5xx response and network errors, which are not caused
+// by the context done.
+func isTemporary(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var faucetErr *FaucetError
+	if errors.As(err, &faucetErr) {
+		return faucetErr.Temporary()
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
 func deserializeMintTransactions(body []byte) ([]diemtypes.SignedTransaction, error) {
 	bytes, err := hex.DecodeString(string(body))
 	if err != nil {
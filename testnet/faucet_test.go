@@ -4,10 +4,25 @@
 package testnet_test
 
 import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/diem/client-sdk-go/diemclient"
 	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
 	"github.com/diem/client-sdk-go/testnet"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -33,3 +48,100 @@ func TestMustMintPanic(t *testing.T) {
 
 	testnet.MustMint("invalid", 1000, "HELLO")
 }
+
+// executedChain responds all transactions executed with hash of the given transactions by
+// sequence number
+type executedChain struct {
+	txns []*diemtypes.SignedTransaction
+}
+
+func (c *executedChain) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	req := requests[0]
+	seq := req.Params[1].(uint64)
+	raw := json.RawMessage(fmt.Sprintf(`{"version": 1, "hash": %q, "vm_status": {"type": "executed"}}`,
+		c.txns[seq].TransactionHash()))
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{req.ID: {Result: &raw}}}
+	return stub.Call(requests...)
+}
+
+func mintTransactions(t *testing.T, n int) []*diemtypes.SignedTransaction {
+	dd := diemkeys.MustGenKeys()
+	var ret []*diemtypes.SignedTransaction
+	for i := 0; i < n; i++ {
+		payload := stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			testnet.XUS, dd.AccountAddress(), 100, nil, nil)
+		txn := diemsigner.SignTxn(dd, testnet.DDAccountAddress, uint64(i), payload,
+			1_000_000, 0, "XUS", uint64(time.Now().Add(time.Minute).Unix()), testnet.ChainID)
+		ret = append(ret, txn)
+	}
+	return ret
+}
+
+func encodeTransactions(t *testing.T, txns []*diemtypes.SignedTransaction) string {
+	s := bcs.NewSerializer()
+	require.NoError(t, s.SerializeLen(uint64(len(txns))))
+	for _, txn := range txns {
+		require.NoError(t, txn.Serialize(s))
+	}
+	return hex.EncodeToString(s.GetBytes())
+}
+
+func TestFaucetFund(t *testing.T) {
+	txns := mintTransactions(t, 2)
+	statuses := []int{http.StatusServiceUnavailable, http.StatusBadGateway}
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if len(statuses) > 0 {
+			w.WriteHeader(statuses[0])
+			statuses = statuses[1:]
+			return
+		}
+		_, _ = w.Write([]byte(encodeTransactions(t, txns)))
+	}))
+	defer server.Close()
+
+	faucet := testnet.NewFaucet(testnet.Config{FaucetURL: server.URL, Currencies: []string{"XUS"}}).
+		WithRetry(2, time.Millisecond)
+	faucet.Client = diemclient.NewWithJsonRpcClient(testnet.ChainID, &executedChain{txns: txns})
+	keys := diemkeys.MustGenKeys()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	txn, err := faucet.Fund(ctx, keys.AuthKey(), "XUS", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, txns[1].TransactionHash(), txn.Hash)
+	require.Len(t, requests, 3)
+	assert.Equal(t, http.MethodPost, requests[2].Method)
+	assert.Equal(t, "1000", requests[2].URL.Query().Get("amount"))
+	assert.Equal(t, keys.AuthKey().Hex(), requests[2].URL.Query().Get("auth_key"))
+	assert.Equal(t, "XUS", requests[2].URL.Query().Get("currency_code"))
+
+	t.Run("unsupported currency", func(t *testing.T) {
+		_, err := faucet.Fund(ctx, keys.AuthKey(), "XDX", 1000)
+		assert.True(t, errors.Is(err, testnet.ErrUnsupportedCurrency))
+		assert.Len(t, requests, 3)
+	})
+	t.Run("retries exhausted", func(t *testing.T) {
+		statuses = []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}
+		_, err := faucet.Fund(ctx, keys.AuthKey(), "XUS", 1000)
+		require.Error(t, err)
+		faucetErr, ok := err.(*testnet.FaucetError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, faucetErr.StatusCode)
+		assert.Len(t, requests, 6)
+	})
+	t.Run("no retry for 4xx", func(t *testing.T) {
+		statuses = []int{http.StatusBadRequest}
+		_, err := faucet.Fund(ctx, keys.AuthKey(), "XUS", 1000)
+		assert.EqualError(t, err, "Non 200 response: 400 ")
+		assert.Len(t, requests, 7)
+	})
+	t.Run("context canceled", func(t *testing.T) {
+		statuses = []int{http.StatusInternalServerError}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := faucet.Fund(ctx, keys.AuthKey(), "XUS", 1000)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package testsuite
+
+import (
+	"io/ioutil"
+	"path/filepath"
+)
+
+// ComposeFile is the docker-compose file of a single validator network and a faucet connecting
+// to it, it is used by `StartLocalNetwork` when no compose file is given.
+// The service ports are published on random host ports, so that multiple networks can run
+// side by side; the images tag defaults to "devnet" and can be changed by `IMAGE_TAG`
+// environment variable.
+const ComposeFile = `version: "3.8"
+services:
+  validator:
+    image: "diem/validator:${IMAGE_TAG:-devnet}"
+    volumes:
+      - type: volume
+        source: diem-shared
+        target: /opt/diem/var
+      - type: bind
+        source: ./validator_node_template.yaml
+        target: /opt/diem/var/validator_node_template.yaml
+    command:
+      - /opt/diem/bin/diem-node
+      - --test
+      - --config
+      - /opt/diem/var
+      - --genesis-modules
+      - /opt/diem/etc/genesis/
+      - --template
+      - /opt/diem/var/validator_node_template.yaml
+    ports:
+      - "127.0.0.1::8080"
+  faucet:
+    image: "diem/faucet:${IMAGE_TAG:-devnet}"
+    depends_on:
+      - validator
+    volumes:
+      - type: volume
+        source: diem-shared
+        target: /opt/diem/var
+    command:
+      - /opt/diem/bin/diem-faucet
+      - --address
+      - 0.0.0.0
+      - --port
+      - "8000"
+      - --mint-key-file-path
+      - /opt/diem/var/mint.key
+      - --server-url
+      - http://validator:8080
+      - --chain-id
+      - TESTING
+    ports:
+      - "127.0.0.1::8000"
+volumes:
+  diem-shared:
+`
+
+// ValidatorNodeTemplate is the validator node config template referenced by `ComposeFile`,
+// JSON-RPC service listens on all interfaces for the faucet and published port.
+const ValidatorNodeTemplate = `base:
+  data_dir: "/opt/diem/var"
+json_rpc:
+  address: "0.0.0.0:8080"
+`
+
+// Service and container ports of `ComposeFile`
+const (
+	validatorService = "validator"
+	validatorPort    = "8080"
+	faucetService    = "faucet"
+	faucetPort       = "8000"
+)
+
+// writeComposeFile writes `ComposeFile` and `ValidatorNodeTemplate` into the dir, returns
+// the compose file path.
+func writeComposeFile(dir string) (string, error) {
+	file := filepath.Join(dir, "docker-compose.yaml")
+	if err := ioutil.WriteFile(file, []byte(ComposeFile), 0o644); err != nil {
+		return "", err
+	}
+	template := filepath.Join(dir, "validator_node_template.yaml")
+	if err := ioutil.WriteFile(template, []byte(ValidatorNodeTemplate), 0o644); err != nil {
+		return "", err
+	}
+	return file, nil
+}
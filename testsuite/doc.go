@@ -0,0 +1,18 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides integration test harness running tests against an ephemeral local Diem network,
+// instead of the shared testnet.
+//
+// `NewLocalNetwork` connects to the network configured by `DIEM_JSON_RPC_URL`,
+// `DIEM_FAUCET_URL` and `DIEM_CHAIN_ID` environment variables; or starts a validator and faucet
+// by `docker-compose` when `DIEM_DOCKER` or `DIEM_COMPOSE_FILE` is set, with the compose file of
+// `DIEM_COMPOSE_FILE` or the shipped `ComposeFile`, and tears it down when the test finishes.
+// Service ports are published on random host ports. The test is skipped if none is configured.
+//
+//	func TestTransfer(t *testing.T) {
+//		network := testsuite.NewLocalNetwork(t)
+//		sender := network.GenAccount(t, "XUS", 1_000_000)
+//		...
+//	}
+package testsuite
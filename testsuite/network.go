@@ -0,0 +1,215 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package testsuite
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/testnet"
+)
+
+// Environment variables for configuring `NewLocalNetwork`
+const (
+	JSONRPCURLEnvVar  = "DIEM_JSON_RPC_URL"
+	FaucetURLEnvVar   = "DIEM_FAUCET_URL"
+	ChainIDEnvVar     = "DIEM_CHAIN_ID"
+	DockerEnvVar      = "DIEM_DOCKER"
+	ComposeFileEnvVar = "DIEM_COMPOSE_FILE"
+)
+
+// Local network started by docker-compose
+const (
+	LocalChainID            = testnet.TestingChainID
+	DefaultStartTimeout     = 5 * time.Minute
+	DefaultTransactionWait  = 30 * time.Second
+	readinessPollInterval   = time.Second
+	composeProjectPrefix    = "diem-testsuite-"
+	dockerComposeExecutable = "docker-compose"
+)
+
+// LocalNetwork is a Diem network for integration tests
+type LocalNetwork struct {
+	ChainID    byte
+	JSONRPCURL string
+	FaucetURL  string
+	Client     diemclient.Client
+	Faucet     *testnet.Faucet
+}
+
+// NewLocalNetwork returns `LocalNetwork` configured by environment variables, or started by
+// `StartLocalNetwork` when `DIEM_DOCKER` or `DIEM_COMPOSE_FILE` is set. It skips the test if the
+// network is not configured.
+func NewLocalNetwork(t testing.TB) *LocalNetwork {
+	t.Helper()
+	if url := os.Getenv(JSONRPCURLEnvVar); url != "" {
+		network, err := connect(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return network
+	}
+	file := os.Getenv(ComposeFileEnvVar)
+	if file == "" && os.Getenv(DockerEnvVar) == "" {
+		t.Skipf("set %s, %s or %s to run tests against a local network",
+			JSONRPCURLEnvVar, DockerEnvVar, ComposeFileEnvVar)
+	}
+	return StartLocalNetwork(t, file)
+}
+
+// StartLocalNetwork starts a validator and faucet by docker-compose with the given compose file,
+// or `ComposeFile` if the file is empty, and tears it down by `t.Cleanup`.
+// The compose file must publish JSON-RPC port 8080 of "validator" service and port 8000 of
+// "faucet" service, the host ports are resolved by `docker-compose port`.
+// It skips the test if docker-compose is not installed, and fails the test if the network is not
+// ready within `DefaultStartTimeout`.
+func StartLocalNetwork(t testing.TB, file string) *LocalNetwork {
+	t.Helper()
+	if _, err := exec.LookPath(dockerComposeExecutable); err != nil {
+		t.Skipf("%s is required for starting local network: %v", dockerComposeExecutable, err)
+	}
+	if file == "" {
+		var err error
+		if file, err = writeComposeFile(t.TempDir()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return start(t, file)
+}
+
+// GenAccount creates an account with given amount of the currency by the faucet
+func (n *LocalNetwork) GenAccount(t testing.TB, currency string, amount uint64) *diemkeys.Keys {
+	t.Helper()
+	keys := diemkeys.MustGenKeys()
+	n.Fund(t, keys.AuthKey(), currency, amount)
+	return keys
+}
+
+// Fund mints amount of the currency to the account of the auth key by the faucet, and waits
+// for the transaction executed.
+func (n *LocalNetwork) Fund(t testing.TB, authKey diemkeys.AuthKey, currency string, amount uint64) *diemclient.Transaction {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTransactionWait)
+	defer cancel()
+	txn, err := n.Faucet.Fund(ctx, authKey, currency, amount)
+	if err != nil {
+		t.Fatalf("fund account %s failed: %v", authKey.AccountAddress().Hex(), err)
+	}
+	return txn
+}
+
+// Balance returns balance of the currency of the account, fails the test if the account is not
+// found.
+func (n *LocalNetwork) Balance(t testing.TB, address diemtypes.AccountAddress, currency string) uint64 {
+	t.Helper()
+	account, err := n.Client.GetAccount(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account == nil {
+		t.Fatalf("account %s not found", address.Hex())
+	}
+	for _, balance := range account.Balances {
+		if balance.Currency == currency {
+			return balance.Amount
+		}
+	}
+	return 0
+}
+
+func connect(url string) (*LocalNetwork, error) {
+	faucetURL := os.Getenv(FaucetURLEnvVar)
+	if faucetURL == "" {
+		return nil, fmt.Errorf("%s is required when %s is set", FaucetURLEnvVar, JSONRPCURLEnvVar)
+	}
+	chainID := LocalChainID
+	if value := os.Getenv(ChainIDEnvVar); value != "" {
+		id, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", ChainIDEnvVar, err)
+		}
+		chainID = byte(id)
+	}
+	return newLocalNetwork(chainID, url, faucetURL), nil
+}
+
+func newLocalNetwork(chainID byte, url, faucetURL string) *LocalNetwork {
+	faucet := testnet.NewFaucet(testnet.Config{JSONRPCURL: url, FaucetURL: faucetURL, ChainID: chainID})
+	return &LocalNetwork{
+		ChainID:    chainID,
+		JSONRPCURL: url,
+		FaucetURL:  faucetURL,
+		Client:     faucet.Client,
+		Faucet:     faucet,
+	}
+}
+
+func start(t testing.TB, file string) *LocalNetwork {
+	project := composeProjectPrefix + strconv.FormatInt(time.Now().UnixNano(), 36)
+	compose := func(args ...string) (string, error) {
+		cmd := exec.Command(dockerComposeExecutable, append([]string{"-f", file, "-p", project}, args...)...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("%s %v failed: %v\n%s", dockerComposeExecutable, args, err, output)
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+	t.Cleanup(func() {
+		if _, err := compose("down", "--volumes"); err != nil {
+			t.Log(err)
+		}
+	})
+	if _, err := compose("up", "--detach"); err != nil {
+		t.Fatal(err)
+	}
+	hostPort := func(service, port string) string {
+		address, err := compose("port", service, port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			t.Fatalf("invalid published address of %s port %s: %#v", service, port, address)
+		}
+		if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+			host = "127.0.0.1"
+		}
+		return net.JoinHostPort(host, port)
+	}
+	network := newLocalNetwork(LocalChainID,
+		fmt.Sprintf("http://%s/v1", hostPort(validatorService, validatorPort)),
+		fmt.Sprintf("http://%s/mint", hostPort(faucetService, faucetPort)))
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultStartTimeout)
+	defer cancel()
+	if err := network.waitForReady(ctx); err != nil {
+		t.Fatalf("local network is not ready: %v", err)
+	}
+	return network
+}
+
+// waitForReady waits for JSON-RPC service responding metadata, faucet requests are retried by
+// `testnet.Faucet#Fund` until the faucet service is ready.
+func (n *LocalNetwork) waitForReady(ctx context.Context) error {
+	for {
+		_, err := diemclient.AsContextClient(n.Client).GetMetadataWithContext(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%v: %v", ctx.Err(), err)
+		case <-time.After(readinessPollInterval):
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package testsuite_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/testsuite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLocalNetworkFromEnviron(t *testing.T) {
+	t.Setenv(testsuite.JSONRPCURLEnvVar, "http://localhost:8080/v1")
+	t.Setenv(testsuite.FaucetURLEnvVar, "http://localhost:8000/mint")
+	t.Setenv(testsuite.ChainIDEnvVar, "5")
+	network := testsuite.NewLocalNetwork(t)
+	assert.Equal(t, testnet.PremainnetChainID, network.ChainID)
+	assert.Equal(t, "http://localhost:8080/v1", network.JSONRPCURL)
+	assert.Equal(t, "http://localhost:8000/mint", network.FaucetURL)
+	assert.Equal(t, "http://localhost:8000/mint", network.Faucet.URL)
+	assert.Equal(t, testnet.PremainnetChainID, network.Client.(diemclient.ChainStateClient).ChainID())
+	assert.Equal(t, network.Client, network.Faucet.Client)
+
+	t.Setenv(testsuite.ChainIDEnvVar, "")
+	assert.Equal(t, testsuite.LocalChainID, testsuite.NewLocalNetwork(t).ChainID)
+}
+
+func TestNewLocalNetworkSkipped(t *testing.T) {
+	t.Setenv(testsuite.JSONRPCURLEnvVar, "")
+	t.Setenv(testsuite.DockerEnvVar, "")
+	t.Setenv(testsuite.ComposeFileEnvVar, "")
+	var sub *testing.T
+	t.Run("not configured", func(t *testing.T) {
+		sub = t
+		testsuite.NewLocalNetwork(t)
+	})
+	assert.True(t, sub.Skipped())
+
+	t.Setenv(testsuite.DockerEnvVar, "1")
+	t.Setenv("PATH", "")
+	t.Run("docker-compose not found", func(t *testing.T) {
+		sub = t
+		testsuite.NewLocalNetwork(t)
+	})
+	assert.True(t, sub.Skipped())
+}
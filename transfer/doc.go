@@ -0,0 +1,5 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides peer to peer transfer helper choosing transaction metadata by the travel rule threshold.
+package transfer
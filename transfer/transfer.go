@@ -0,0 +1,244 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnbuilder"
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// Sender is the sender account of transfers
+type Sender struct {
+	Signer diemsigner.Signer
+	// Address is the sender account address, default is the address derived from the signer
+	// public key
+	Address *diemtypes.AccountAddress
+	// SubAddress is the sender custodial account sub-address, nil for non-custodial account
+	SubAddress *diemtypes.SubAddress
+	// OffChain sends the initial payment command of transfers requiring off-chain travel rule
+	// data exchange when it is set
+	OffChain *offchain.Client
+	// KycData is the sender KYC data of the initial payment command
+	KycData *offchain.KycDataObject
+	// CheckFrozen checks the sender and receiver accounts are not frozen before submitting or
+	// starting off-chain exchange, `*diemclient.AccountFrozenError` is returned instead of
+	// submitting a transaction which can't be executed.
+	CheckFrozen bool
+}
+
+// AccountAddress returns the sender account address
+func (s *Sender) AccountAddress() diemtypes.AccountAddress {
+	if s.Address != nil {
+		return *s.Address
+	}
+	return diemkeys.NewAuthKey(s.Signer.PublicKey()).AccountAddress()
+}
+
+// Result is the result of `Send`, exactly one of `Transaction` and `OffChainRequired` is not nil
+type Result struct {
+	// Transaction is the executed transaction of the transfer submitted on-chain
+	Transaction *diemclient.Transaction
+	// OffChainRequired is the transfer requires off-chain travel rule data exchange
+	OffChainRequired *OffChainRequired
+}
+
+// OffChainRequired is a transfer between two different VASPs with amount greater than or equal
+// to the travel rule threshold, which requires off-chain travel rule data exchange before
+// submitting the payment by `Settle`.
+type OffChainRequired struct {
+	Sender   diemtypes.AccountAddress
+	Receiver diemtypes.AccountAddress
+	// SenderAccountIdentifier and ReceiverAccountIdentifier are the payment actor addresses
+	// (LIP-5 account identifiers) of the off-chain payment
+	SenderAccountIdentifier   string
+	ReceiverAccountIdentifier string
+	Currency                  string
+	Amount                    uint64
+	// Command is the initial payment command sent to the receiver VASP, nil if
+	// `Sender.OffChain` is not set.
+	Command *offchain.PaymentCommand
+	// Response is the receiver VASP response of the command
+	Response *offchain.CommandResponseObject
+}
+
+// NewPaymentCommand creates the initial off-chain payment command of the transfer
+func (r *OffChainRequired) NewPaymentCommand(senderKycData *offchain.KycDataObject) *offchain.PaymentCommand {
+	return offchain.NewPaymentCommand(r.SenderAccountIdentifier, r.ReceiverAccountIdentifier,
+		r.Amount, r.Currency, senderKycData)
+}
+
+// Send transfers the amount to the intent account in the intent currency, the metadata is
+// chosen by the travel rule threshold:
+//
+//   - under the threshold, or between accounts of the same VASP: general metadata with the
+//     sender and receiver sub-addresses (LIP-4) is submitted on-chain directly.
+//   - otherwise, between two different VASPs: the payment requires the off-chain travel rule
+//     data exchange (LIP-1); returns `OffChainRequired`, and sends the initial payment command
+//     by `Sender.OffChain` client if it is set. `Settle` submits the payment with travel rule
+//     metadata after the receiver provided the recipient signature.
+//
+// Returns error if the intent has no currency or its amount does not match the amount.
+func Send(client diemclient.Client, sender *Sender, intent *diemid.Intent, amount uint64) (*Result, error) {
+	return SendWithContext(context.Background(), client, sender, intent, amount)
+}
+
+// SendWithContext is `Send` with context
+func SendWithContext(ctx context.Context, client diemclient.Client, sender *Sender, intent *diemid.Intent, amount uint64) (*Result, error) {
+	currency := intent.Params.Currency
+	if currency == "" {
+		return nil, errors.New("intent currency is required")
+	}
+	if intent.Params.Amount != nil && *intent.Params.Amount != amount {
+		return nil, fmt.Errorf("amount %d does not match intent amount %d", amount, *intent.Params.Amount)
+	}
+	if amount == 0 {
+		return nil, errors.New("amount is zero")
+	}
+	receiver := intent.Account.AccountAddress
+	if err := checkNotFrozen(ctx, client, sender, receiver); err != nil {
+		return nil, err
+	}
+	required, err := isOffChainRequired(ctx, client, sender.AccountAddress(), receiver, amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	if !required {
+		var to *diemtypes.SubAddress
+		if intent.Account.SubAddress != diemtypes.EmptySubAddress {
+			to = &intent.Account.SubAddress
+		}
+		metadata := generalMetadata(sender.SubAddress, to)
+		txn, err := submit(ctx, client, sender, receiver, amount, currency, metadata, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Transaction: txn}, nil
+	}
+
+	ret := &OffChainRequired{
+		Sender:   sender.AccountAddress(),
+		Receiver: receiver,
+		Currency: currency,
+		Amount:   amount,
+	}
+	senderSubAddress := diemtypes.EmptySubAddress
+	if sender.SubAddress != nil {
+		senderSubAddress = *sender.SubAddress
+	}
+	ret.SenderAccountIdentifier, err = diemid.EncodeAccount(intent.Account.Prefix, ret.Sender, senderSubAddress)
+	if err != nil {
+		return nil, err
+	}
+	ret.ReceiverAccountIdentifier, err = intent.Account.Encode()
+	if err != nil {
+		return nil, err
+	}
+	if sender.OffChain != nil {
+		ret.Command = ret.NewPaymentCommand(sender.KycData)
+		ret.Response, err = sender.OffChain.SendPaymentCommand(ctx, receiver, ret.Command)
+		if err != nil {
+			return nil, fmt.Errorf("send off-chain payment command failed: %w", err)
+		}
+	}
+	return &Result{OffChainRequired: ret}, nil
+}
+
+// Settle submits the transfer with travel rule metadata of the off-chain payment reference id
+// and the recipient signature provided by the receiver VASP, and waits for it executed.
+func Settle(client diemclient.Client, sender *Sender, required *OffChainRequired, referenceID string, recipientSignature []byte) (*diemclient.Transaction, error) {
+	return SettleWithContext(context.Background(), client, sender, required, referenceID, recipientSignature)
+}
+
+// SettleWithContext is `Settle` with context
+func SettleWithContext(ctx context.Context, client diemclient.Client, sender *Sender, required *OffChainRequired, referenceID string, recipientSignature []byte) (*diemclient.Transaction, error) {
+	if referenceID == "" || len(recipientSignature) == 0 {
+		return nil, errors.New("reference id and recipient signature are required")
+	}
+	if err := checkNotFrozen(ctx, client, sender, required.Receiver); err != nil {
+		return nil, err
+	}
+	metadata, _ := txnmetadata.NewTravelRuleMetadata(referenceID, required.Sender, required.Amount)
+	return submit(ctx, client, sender, required.Receiver, required.Amount, required.Currency,
+		metadata, recipientSignature)
+}
+
+func checkNotFrozen(ctx context.Context, client diemclient.Client, sender *Sender, receiver diemtypes.AccountAddress) error {
+	if !sender.CheckFrozen {
+		return nil
+	}
+	return diemclient.CheckNotFrozen(ctx, client, sender.AccountAddress(), receiver)
+}
+
+// isOffChainRequired returns true if the amount is greater than or equal to the travel rule
+// threshold and both sender and receiver are VASP accounts with different parent VASPs.
+func isOffChainRequired(ctx context.Context, client diemclient.Client, sender, receiver diemtypes.AccountAddress, amount uint64, currency string) (bool, error) {
+	compliance, ok := client.(diemclient.ComplianceClient)
+	if !ok {
+		return false, &diemclient.UnsupportedClientError{Client: client, Interface: "ComplianceClient"}
+	}
+	required, err := compliance.IsTravelRuleRequiredWithContext(ctx, amount, currency)
+	if err != nil || !required {
+		return false, err
+	}
+	senderVASP, err := parentVASP(ctx, client, sender)
+	if err != nil || senderVASP == nil {
+		return false, err
+	}
+	receiverVASP, err := parentVASP(ctx, client, receiver)
+	if err != nil || receiverVASP == nil {
+		return false, err
+	}
+	return *senderVASP != *receiverVASP, nil
+}
+
+// parentVASP returns parent VASP address of the account, nil if the account is not found or
+// it is not a VASP account.
+func parentVASP(ctx context.Context, client diemclient.Client, address diemtypes.AccountAddress) (*diemtypes.AccountAddress, error) {
+	account, err := diemclient.AsContextClient(client).GetAccountWithContext(ctx, address)
+	if err != nil || account == nil {
+		return nil, err
+	}
+	info, err := diemclient.NewAccountInfo(account)
+	if err != nil {
+		return nil, err
+	}
+	parent, ok := info.ParentVASPAddress()
+	if !ok {
+		return nil, nil
+	}
+	return &parent, nil
+}
+
+func generalMetadata(from, to *diemtypes.SubAddress) []byte {
+	switch {
+	case from != nil && to != nil:
+		return txnmetadata.NewGeneralMetadataWithFromToSubAddresses(*from, *to)
+	case from != nil:
+		return txnmetadata.NewGeneralMetadataFromSubAddress(*from)
+	case to != nil:
+		return txnmetadata.NewGeneralMetadataToSubAddress(*to)
+	}
+	return nil
+}
+
+func submit(ctx context.Context, client diemclient.Client, sender *Sender, receiver diemtypes.AccountAddress, amount uint64, currency string, metadata, signature []byte) (*diemclient.Transaction, error) {
+	return txnbuilder.NewWithSigner(sender.Signer).
+		Sender(sender.AccountAddress()).
+		Payload(stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			diemtypes.Currency(currency), receiver, amount, metadata, signature)).
+		GasCurrency(currency).
+		Context(ctx).
+		SignSubmitAndWait(client)
+}
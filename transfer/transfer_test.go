@@ -0,0 +1,228 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package transfer_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemid"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/offchain"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/transfer"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const limit = 1_000_000_000
+
+// newServer creates fake full node with the travel rule threshold `limit`
+func newServer() *diemclienttest.Server {
+	server := diemclienttest.NewServer()
+	server.SetDualAttestationLimit(limit)
+	return server
+}
+
+// vasp adds parent VASP account, returns the keys of the account
+func vasp(server *diemclienttest.Server) *diemkeys.Keys {
+	return server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleParentVASP}})
+}
+
+func custodial(keys *diemkeys.Keys) *transfer.Sender {
+	sub := diemtypes.MustGenSubAddress()
+	return &transfer.Sender{Signer: diemsigner.NewKeysSigner(keys), SubAddress: &sub}
+}
+
+func intent(receiver diemtypes.AccountAddress, sub diemtypes.SubAddress) *diemid.Intent {
+	return &diemid.Intent{
+		Account: *diemid.NewAccount(diemid.TestnetPrefix, receiver, sub),
+		Params:  diemid.Params{Currency: "XUS"},
+	}
+}
+
+type resolver string
+
+func (r resolver) BaseURL(diemtypes.AccountAddress) (string, error) {
+	return string(r), nil
+}
+
+type complianceKey ed25519.PublicKey
+
+func (k complianceKey) ComplianceKey(diemtypes.AccountAddress) (ed25519.PublicKey, error) {
+	return ed25519.PublicKey(k), nil
+}
+
+func TestSend(t *testing.T) {
+	t.Run("under threshold", func(t *testing.T) {
+		server := newServer()
+		sender := custodial(vasp(server))
+		receiver := vasp(server).AccountAddress()
+		to := diemtypes.MustGenSubAddress()
+		ret, err := transfer.Send(server.Client(), sender, intent(receiver, to), limit-1)
+		require.NoError(t, err)
+		require.NotNil(t, ret.Transaction)
+		assert.Nil(t, ret.OffChainRequired)
+
+		require.Len(t, server.Submitted(), 1)
+		metadata := txnmetadata.NewGeneralMetadataWithFromToSubAddresses(*sender.SubAddress, to)
+		assert.Equal(t, stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			diemtypes.Currency("XUS"), receiver, limit-1, metadata, nil),
+			server.Submitted()[0].RawTxn.Payload)
+	})
+	t.Run("non-custodial accounts", func(t *testing.T) {
+		server := newServer()
+		keys := vasp(server)
+		sender := &transfer.Sender{Signer: diemsigner.NewKeysSigner(keys)}
+		receiver := vasp(server).AccountAddress()
+		ret, err := transfer.Send(server.Client(), sender, intent(receiver, diemtypes.EmptySubAddress), 100)
+		require.NoError(t, err)
+		require.NotNil(t, ret.Transaction)
+		assert.Equal(t, stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			diemtypes.Currency("XUS"), receiver, 100, nil, nil),
+			server.Submitted()[0].RawTxn.Payload)
+	})
+	t.Run("above threshold between different VASPs", func(t *testing.T) {
+		server := newServer()
+		sender := custodial(vasp(server))
+		parent := vasp(server).AccountAddress()
+		receiver := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleChildVASP, ParentVaspAddress: parent.Hex()}}).AccountAddress()
+		to := diemtypes.MustGenSubAddress()
+		ret, err := transfer.Send(server.Client(), sender, intent(receiver, to), limit)
+		require.NoError(t, err)
+		assert.Nil(t, ret.Transaction)
+		required := ret.OffChainRequired
+		require.NotNil(t, required)
+		assert.Empty(t, server.Submitted())
+		assert.Equal(t, receiver, required.Receiver)
+		assert.Equal(t, uint64(limit), required.Amount)
+		assert.Nil(t, required.Command)
+
+		account, err := diemid.DecodeToAccount(diemid.TestnetPrefix, required.SenderAccountIdentifier)
+		require.NoError(t, err)
+		assert.Equal(t, *sender.SubAddress, account.SubAddress)
+		account, err = diemid.DecodeToAccount(diemid.TestnetPrefix, required.ReceiverAccountIdentifier)
+		require.NoError(t, err)
+		assert.Equal(t, to, account.SubAddress)
+	})
+	t.Run("above threshold between accounts of same VASP", func(t *testing.T) {
+		server := newServer()
+		parent := vasp(server)
+		parentAddress := parent.AccountAddress()
+		receiver := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleChildVASP, ParentVaspAddress: parentAddress.Hex()}}).AccountAddress()
+		ret, err := transfer.Send(server.Client(), custodial(parent), intent(receiver, diemtypes.MustGenSubAddress()), limit)
+		require.NoError(t, err)
+		assert.NotNil(t, ret.Transaction)
+	})
+	t.Run("above threshold to non-VASP account", func(t *testing.T) {
+		server := newServer()
+		receiver := server.GenAccount(&diemclient.Account{Role: &diemclient.AccountRole{Type: diemclient.AccountRoleDesignatedDealer}}).AccountAddress()
+		ret, err := transfer.Send(server.Client(), custodial(vasp(server)), intent(receiver, diemtypes.EmptySubAddress), limit)
+		require.NoError(t, err)
+		assert.NotNil(t, ret.Transaction)
+	})
+	t.Run("send off-chain payment command", func(t *testing.T) {
+		server := newServer()
+		var received *offchain.PaymentCommand
+		public, private, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		handler := offchain.NewServer().WithSigningKey(private)
+		handler.Handle(offchain.PaymentCommandType, func(_ *http.Request, req *offchain.CommandRequestObject) (interface{}, *offchain.Error) {
+			received = &offchain.PaymentCommand{}
+			require.NoError(t, json.Unmarshal(req.Command, received))
+			return nil, nil
+		})
+		s := httptest.NewServer(handler)
+		defer s.Close()
+
+		sender := custodial(vasp(server))
+		sender.OffChain = offchain.NewClient("sender").
+			WithResolver(resolver(s.URL)).
+			WithVerifier(offchain.NewVerifier(complianceKey(public)))
+		sender.KycData = &offchain.KycDataObject{Type: offchain.KycDataTypeIndividual}
+		ret, err := transfer.Send(server.Client(), sender, intent(vasp(server).AccountAddress(), diemtypes.MustGenSubAddress()), limit)
+		require.NoError(t, err)
+		required := ret.OffChainRequired
+		require.NotNil(t, required.Command)
+		require.NotNil(t, required.Response)
+		require.NotNil(t, received)
+		assert.Equal(t, required.Command.ReferenceID(), received.ReferenceID())
+		assert.Equal(t, required.SenderAccountIdentifier, received.Payment.Sender.Address)
+		assert.Equal(t, sender.KycData, received.Payment.Sender.KycData)
+	})
+	t.Run("frozen account", func(t *testing.T) {
+		server := newServer()
+		sender := custodial(vasp(server))
+		receiver := vasp(server).AccountAddress()
+		server.SetFrozen(receiver, true)
+		_, err := transfer.Send(server.Client(), sender, intent(receiver, diemtypes.EmptySubAddress), 100)
+		require.NoError(t, err, "not checked by default")
+
+		sender.CheckFrozen = true
+		_, err = transfer.Send(server.Client(), sender, intent(receiver, diemtypes.EmptySubAddress), 100)
+		var frozenErr *diemclient.AccountFrozenError
+		require.True(t, errors.As(err, &frozenErr))
+		assert.Equal(t, receiver, frozenErr.Address)
+
+		server.SetFrozen(receiver, false)
+		server.SetFrozen(sender.AccountAddress(), true)
+		_, err = transfer.Send(server.Client(), sender, intent(receiver, diemtypes.EmptySubAddress), limit)
+		require.True(t, errors.As(err, &frozenErr))
+		assert.Equal(t, sender.AccountAddress(), frozenErr.Address)
+		assert.Len(t, server.Submitted(), 1)
+	})
+	t.Run("invalid intent", func(t *testing.T) {
+		server := newServer()
+		sender := custodial(vasp(server))
+		in := intent(vasp(server).AccountAddress(), diemtypes.EmptySubAddress)
+		amount := uint64(10)
+		in.Params.Amount = &amount
+		_, err := transfer.Send(server.Client(), sender, in, 11)
+		assert.EqualError(t, err, "amount 11 does not match intent amount 10")
+
+		in.Params.Currency = ""
+		_, err = transfer.Send(server.Client(), sender, in, 10)
+		assert.EqualError(t, err, "intent currency is required")
+		assert.Empty(t, server.Submitted())
+	})
+}
+
+func TestSettle(t *testing.T) {
+	server := newServer()
+	sender := custodial(vasp(server))
+	receiver := vasp(server).AccountAddress()
+	ret, err := transfer.SendWithContext(context.Background(), server.Client(), sender, intent(receiver, diemtypes.MustGenSubAddress()), limit)
+	require.NoError(t, err)
+	required := ret.OffChainRequired
+	require.NotNil(t, required)
+
+	signature := []byte("recipient signature")
+	txn, err := transfer.Settle(server.Client(), sender, required, "ref-id", signature)
+	require.NoError(t, err)
+	assert.NotNil(t, txn)
+	metadata, _ := txnmetadata.NewTravelRuleMetadata("ref-id", sender.AccountAddress(), limit)
+	assert.Equal(t, stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+		diemtypes.Currency("XUS"), receiver, limit, metadata, signature),
+		server.Submitted()[0].RawTxn.Payload)
+
+	_, err = transfer.Settle(server.Client(), sender, required, "", nil)
+	assert.EqualError(t, err, "reference id and recipient signature are required")
+
+	sender.CheckFrozen = true
+	server.SetFrozen(receiver, true)
+	_, err = transfer.Settle(server.Client(), sender, required, "ref-id", signature)
+	var frozenErr *diemclient.AccountFrozenError
+	assert.True(t, errors.As(err, &frozenErr))
+	assert.Len(t, server.Submitted(), 1)
+}
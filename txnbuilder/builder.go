@@ -0,0 +1,322 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnbuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// Builder defaults
+const (
+	DefaultMaxGasAmount uint64 = 1_000_000
+	DefaultGasCurrency         = "XUS"
+	DefaultExpiration          = 30 * time.Second
+)
+
+// Builder builds, signs and submits a transaction. Create it by `New`.
+type Builder struct {
+	signer       diemsigner.Signer
+	address      *diemtypes.AccountAddress
+	payload      diemtypes.TransactionPayload
+	seq          *uint64
+	sequences    *diemclient.SequenceNumberManager
+	maxGasAmount uint64
+	gasUnitPrice uint64
+	gasCurrency  string
+	expireIn     time.Duration
+	expireAt     time.Time
+	waitTimeout  time.Duration
+	resubmits    int
+	onResubmit   func(attempt int, cause error)
+	chainID      *byte
+	ctx          context.Context
+}
+
+// New creates `Builder` for transaction sent by the account of given keys
+func New(sender *diemkeys.Keys) *Builder {
+	return NewWithSigner(diemsigner.NewKeysSigner(sender))
+}
+
+// NewWithSigner creates `Builder` for transaction signed by given `diemsigner.Signer`, e.g. keys
+// held in HSM or a remote signing service. The sender is the account address derived from the
+// signer public key, call `Sender` if the account authentication key is rotated.
+func NewWithSigner(signer diemsigner.Signer) *Builder {
+	return &Builder{
+		signer:       signer,
+		maxGasAmount: DefaultMaxGasAmount,
+		gasCurrency:  DefaultGasCurrency,
+		expireIn:     DefaultExpiration,
+		ctx:          context.Background(),
+	}
+}
+
+// Sender sets sender account address, default is address derived from the public key; it is required
+// when the account authentication key is rotated.
+func (b *Builder) Sender(address diemtypes.AccountAddress) *Builder {
+	b.address = &address
+	return b
+}
+
+// Script sets transaction script
+func (b *Builder) Script(script diemtypes.Script) *Builder {
+	return b.Payload(&diemtypes.TransactionPayload__Script{Value: script})
+}
+
+// Payload sets transaction payload, e.g. script function payload
+func (b *Builder) Payload(payload diemtypes.TransactionPayload) *Builder {
+	b.payload = payload
+	return b
+}
+
+// SequenceNumber sets sequence number, default is fetched from the sender account on chain
+func (b *Builder) SequenceNumber(seq uint64) *Builder {
+	b.seq = &seq
+	return b
+}
+
+// SequenceNumbers reserves sequence number from given `SequenceNumberManager`.
+// `SignSubmitAndWait` confirms the sequence number when the transaction is executed; callers of
+// `Sign` and `SignAndSubmit` confirm or release it by the transaction result.
+func (b *Builder) SequenceNumbers(m *diemclient.SequenceNumberManager) *Builder {
+	b.sequences = m
+	return b
+}
+
+// MaxGas sets max gas amount, default is `DefaultMaxGasAmount`
+func (b *Builder) MaxGas(amount uint64) *Builder {
+	b.maxGasAmount = amount
+	return b
+}
+
+// GasUnitPrice sets gas unit price, default is 0
+func (b *Builder) GasUnitPrice(price uint64) *Builder {
+	b.gasUnitPrice = price
+	return b
+}
+
+// GasCurrency sets gas currency code, default is `DefaultGasCurrency`
+func (b *Builder) GasCurrency(currency string) *Builder {
+	b.gasCurrency = currency
+	return b
+}
+
+// ExpireIn sets expiration duration from now, default is `DefaultExpiration`
+func (b *Builder) ExpireIn(duration time.Duration) *Builder {
+	b.expireIn = duration
+	b.expireAt = time.Time{}
+	return b
+}
+
+// ExpireAt sets expiration time
+func (b *Builder) ExpireAt(t time.Time) *Builder {
+	b.expireAt = t
+	return b
+}
+
+// WaitTimeout sets max duration of waiting for the transaction executed by `SignSubmitAndWait`,
+// default is waiting until the transaction expired
+func (b *Builder) WaitTimeout(timeout time.Duration) *Builder {
+	b.waitTimeout = timeout
+	return b
+}
+
+// Resubmit enables `SignSubmitAndWait` to rebuild and resubmit the transaction with a fresh
+// sequence number and expiration time when it is expired, up to `maxRetries` times. A transaction
+// is expired when its submission is rejected as expired, or it is not executed, its sequence
+// number is not used on chain and the ledger time is past its expiration time.
+// `onResubmit` is called with the attempt number and the expiration error before each
+// resubmission for auditing, it can be nil.
+func (b *Builder) Resubmit(maxRetries int, onResubmit func(attempt int, cause error)) *Builder {
+	b.resubmits = maxRetries
+	b.onResubmit = onResubmit
+	return b
+}
+
+// ChainID sets chain id, default is `diemclient.ChainStateClient#ChainID` of the client; it is required
+// if the client does not implement `diemclient.ChainStateClient`.
+func (b *Builder) ChainID(chainID byte) *Builder {
+	b.chainID = &chainID
+	return b
+}
+
+// Context sets context for client calls
+func (b *Builder) Context(ctx context.Context) *Builder {
+	b.ctx = ctx
+	return b
+}
+
+// Sign builds and signs the transaction
+func (b *Builder) Sign(client diemclient.Client) (*diemtypes.SignedTransaction, error) {
+	if b.payload == nil {
+		return nil, errors.New("transaction script or payload is required")
+	}
+	seq, err := b.sequenceNumber(client)
+	if err != nil {
+		return nil, err
+	}
+	return b.sign(client, seq)
+}
+
+// SignAndSubmit signs and submits the transaction
+func (b *Builder) SignAndSubmit(client diemclient.Client) (*diemtypes.SignedTransaction, error) {
+	if b.sequences != nil && b.seq == nil {
+		if b.payload == nil {
+			return nil, errors.New("transaction script or payload is required")
+		}
+		return b.sequences.SubmitWithSequenceNumber(b.ctx, b.sender(), func(seq uint64) (*diemtypes.SignedTransaction, error) {
+			return b.sign(client, seq)
+		})
+	}
+	txn, err := b.Sign(client)
+	if err != nil {
+		return nil, err
+	}
+	if err := diemclient.AsContextClient(client).SubmitTransactionWithContext(b.ctx, txn); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// SignSubmitAndWait signs, submits and waits for the transaction executed.
+// When `Resubmit` is set, expired transaction is rebuilt and resubmitted.
+func (b *Builder) SignSubmitAndWait(client diemclient.Client) (*diemclient.Transaction, error) {
+	attempt := *b
+	for i := 0; ; i++ {
+		executed, err := attempt.signSubmitAndWait(client)
+		if err == nil || !diemclient.IsTransactionExpired(err) || i >= b.resubmits {
+			return executed, err
+		}
+		if b.onResubmit != nil {
+			b.onResubmit(i+1, err)
+		}
+		// given expiration time is passed, resubmit with expiration duration from now
+		attempt.expireAt = time.Time{}
+	}
+}
+
+func (b *Builder) signSubmitAndWait(client diemclient.Client) (*diemclient.Transaction, error) {
+	txn, err := b.SignAndSubmit(client)
+	if err != nil {
+		return nil, err
+	}
+	executed, err := b.wait(client, txn)
+	var execErr *diemclient.ExecutionError
+	if b.sequences != nil && b.seq == nil && (err == nil || errors.As(err, &execErr)) {
+		b.sequences.Confirm(txn.RawTxn.Sender, txn.RawTxn.SequenceNumber)
+	}
+	return executed, err
+}
+
+func (b *Builder) wait(client diemclient.Client, txn *diemtypes.SignedTransaction) (*diemclient.Transaction, error) {
+	deadline := time.Unix(int64(txn.RawTxn.ExpirationTimestampSecs), 0).Add(time.Second)
+	timeout := b.waitTimeout > 0 && time.Now().Add(b.waitTimeout).Before(deadline)
+	if timeout {
+		deadline = time.Now().Add(b.waitTimeout)
+	}
+	ctx, cancel := context.WithDeadline(b.ctx, deadline)
+	defer cancel()
+	executed, err := diemclient.AsContextClient(client).WaitForTransaction2WithContext(ctx, txn)
+	if errors.Is(err, context.DeadlineExceeded) && b.ctx.Err() == nil {
+		if timeout {
+			return nil, fmt.Errorf("transaction %v not found within timeout period: %v", txn.TransactionHash(), b.waitTimeout)
+		}
+		return b.confirmExpired(client, txn)
+	}
+	if diemclient.IsTransactionExpired(err) {
+		return b.confirmExpired(client, txn)
+	}
+	return executed, err
+}
+
+// confirmExpired returns `diemclient.ErrTransactionExpired` only when the ledger time is past the
+// submitted transaction expiration time and its sequence number is not used on chain, so that
+// the transaction can never be executed and it is safe to resubmit a new one. Local clock is not
+// trusted for it, it may be ahead of the ledger time.
+func (b *Builder) confirmExpired(client diemclient.Client, txn *diemtypes.SignedTransaction) (*diemclient.Transaction, error) {
+	hash := txn.TransactionHash()
+	executed, err := diemclient.AsContextClient(client).GetAccountTransactionWithContext(b.ctx, txn.RawTxn.Sender, txn.RawTxn.SequenceNumber, true)
+	if err != nil {
+		return nil, fmt.Errorf("confirm transaction %v expired: %w", hash, err)
+	}
+	if executed != nil {
+		if executed.Hash != hash {
+			return nil, fmt.Errorf("sequence number %d of transaction %v is used by transaction %v",
+				txn.RawTxn.SequenceNumber, hash, executed.Hash)
+		}
+		if executed.VmStatus.Type != diemclient.VmStatusExecuted {
+			return nil, &diemclient.ExecutionError{Transaction: executed}
+		}
+		return executed, nil
+	}
+	if client.LastResponseLedgerState().TimestampUsec < txn.RawTxn.ExpirationTimestampSecs*1_000_000 {
+		return nil, fmt.Errorf("transaction %v not found before expiration, ledger time is not past expiration yet", hash)
+	}
+	if b.sequences != nil && b.seq == nil {
+		b.sequences.Release(txn.RawTxn.Sender, txn.RawTxn.SequenceNumber)
+	}
+	return nil, fmt.Errorf("%w: %v not executed before expiration", diemclient.ErrTransactionExpired, hash)
+}
+
+// SubmitAndWait signs and submits a transaction of given script with default gas and expiration
+// settings, then waits for it executed. Use `New` for customizing the transaction.
+func SubmitAndWait(client diemclient.Client, sender *diemkeys.Keys, script diemtypes.Script) (*diemclient.Transaction, error) {
+	return New(sender).Script(script).SignSubmitAndWait(client)
+}
+
+func (b *Builder) sender() diemtypes.AccountAddress {
+	if b.address != nil {
+		return *b.address
+	}
+	return diemkeys.NewAuthKey(b.signer.PublicKey()).AccountAddress()
+}
+
+func (b *Builder) sequenceNumber(client diemclient.Client) (uint64, error) {
+	if b.seq != nil {
+		return *b.seq, nil
+	}
+	if b.sequences != nil {
+		return b.sequences.Reserve(b.ctx, b.sender())
+	}
+	account, err := diemclient.AsContextClient(client).GetAccountWithContext(b.ctx, b.sender())
+	if err != nil {
+		return 0, err
+	}
+	if account == nil {
+		return 0, fmt.Errorf("account %s not found", b.sender().Hex())
+	}
+	return account.SequenceNumber, nil
+}
+
+func (b *Builder) sign(client diemclient.Client, seq uint64) (*diemtypes.SignedTransaction, error) {
+	expireAt := b.expireAt
+	if expireAt.IsZero() {
+		expireAt = time.Now().Add(b.expireIn)
+	}
+	var chainID byte
+	if b.chainID != nil {
+		chainID = *b.chainID
+	} else if chain, ok := client.(diemclient.ChainStateClient); ok {
+		chainID = chain.ChainID()
+	} else {
+		return nil, errors.New("chain id is required, client does not implement diemclient.ChainStateClient")
+	}
+	return diemsigner.SignTxnWithSigner(
+		b.ctx,
+		b.signer,
+		b.sender(),
+		seq,
+		b.payload,
+		b.maxGasAmount, b.gasUnitPrice, b.gasCurrency,
+		uint64(expireAt.Unix()),
+		chainID,
+	)
+}
@@ -0,0 +1,188 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnbuilder_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chain accepts submitted transactions and executes them immediately
+type chain struct {
+	sequence  uint64
+	submitted []*diemtypes.SignedTransaction
+	pending   bool
+	expire    int
+	// ledgerLag is duration of the ledger time behind the local clock
+	ledgerLag time.Duration
+}
+
+func (c *chain) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	req := requests[0]
+	var resp jsonrpc.Response
+	var result string
+	switch req.Method {
+	case diemclient.GetAccount:
+		result = fmt.Sprintf(`{"sequence_number": %d}`, c.sequence)
+	case diemclient.Submit:
+		if c.expire > 0 {
+			c.expire--
+			resp.Error = &jsonrpc.ResponseError{Code: -32001, Message: "Server error: VM Validation error: TRANSACTION_EXPIRED"}
+			break
+		}
+		bytes, _ := hex.DecodeString(req.Params[0].(string))
+		txn, _ := diemtypes.BcsDeserializeSignedTransaction(bytes)
+		c.submitted = append(c.submitted, &txn)
+		c.sequence++
+	case diemclient.GetAccountTransaction:
+		for _, txn := range c.submitted {
+			if !c.pending && txn.RawTxn.SequenceNumber == req.Params[1].(uint64) {
+				result = fmt.Sprintf(`{"version": 1, "hash": %q, "vm_status": {"type": "executed"}}`, txn.TransactionHash())
+			}
+		}
+	}
+	if result != "" {
+		raw := json.RawMessage(result)
+		resp.Result = &raw
+	}
+	resp.DiemLedgerTimestampusec = uint64(time.Now().Add(-c.ledgerLag).UnixNano() / 1000)
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{req.ID: resp}}
+	return stub.Call(requests...)
+}
+
+func TestBuilder(t *testing.T) {
+	sender := diemkeys.MustGenKeys()
+	script := stdlib.EncodePeerToPeerWithMetadataScript(testnet.XUS, sender.AccountAddress(), 10, nil, nil)
+	c := &chain{sequence: 3}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+
+	t.Run("sign and submit", func(t *testing.T) {
+		txn, err := txnbuilder.New(sender).
+			Script(script).
+			MaxGas(500_000).
+			GasCurrency("XDX").
+			ExpireIn(time.Minute).
+			SignAndSubmit(client)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), txn.RawTxn.SequenceNumber)
+		assert.Equal(t, uint64(500_000), txn.RawTxn.MaxGasAmount)
+		assert.Equal(t, "XDX", txn.RawTxn.GasCurrencyCode)
+		assert.Equal(t, diemtypes.ChainId(testnet.ChainID), txn.RawTxn.ChainId)
+		assert.InDelta(t, time.Now().Add(time.Minute).Unix(), int64(txn.RawTxn.ExpirationTimestampSecs), 2)
+		assert.Equal(t, sender.AccountAddress(), txn.RawTxn.Sender)
+		assert.Len(t, c.submitted, 1)
+	})
+	t.Run("sign submit and wait", func(t *testing.T) {
+		executed, err := txnbuilder.New(sender).Script(script).SignSubmitAndWait(client)
+		require.NoError(t, err)
+		assert.Equal(t, "executed", executed.VmStatus.Type)
+	})
+	t.Run("sign with given sequence number and chain id", func(t *testing.T) {
+		txn, err := txnbuilder.New(sender).Script(script).SequenceNumber(10).ChainID(4).Sign(client)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), txn.RawTxn.SequenceNumber)
+		assert.Equal(t, diemtypes.ChainId(4), txn.RawTxn.ChainId)
+	})
+	t.Run("sign with signer", func(t *testing.T) {
+		txn, err := txnbuilder.NewWithSigner(diemsigner.NewKeysSigner(sender)).Script(script).SequenceNumber(10).Sign(client)
+		require.NoError(t, err)
+		assert.Equal(t, sender.AccountAddress(), txn.RawTxn.Sender)
+		assert.NoError(t, txn.VerifySignature())
+	})
+	t.Run("with sequence number manager", func(t *testing.T) {
+		manager := diemclient.NewSequenceNumberManager(client)
+		builder := txnbuilder.New(sender).Script(script).SequenceNumbers(manager)
+		first, err := builder.SignAndSubmit(client)
+		require.NoError(t, err)
+		second, err := builder.SignAndSubmit(client)
+		require.NoError(t, err)
+		assert.Equal(t, first.RawTxn.SequenceNumber+1, second.RawTxn.SequenceNumber)
+	})
+	t.Run("submit and wait", func(t *testing.T) {
+		executed, err := txnbuilder.SubmitAndWait(client, sender, script)
+		require.NoError(t, err)
+		assert.Equal(t, "executed", executed.VmStatus.Type)
+	})
+	t.Run("wait timeout", func(t *testing.T) {
+		c.pending = true
+		defer func() { c.pending = false }()
+		_, err := txnbuilder.New(sender).Script(script).WaitTimeout(10 * time.Millisecond).SignSubmitAndWait(client)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found within timeout period: 10ms")
+	})
+	t.Run("resubmit expired transaction", func(t *testing.T) {
+		c.expire = 2
+		var attempts []int
+		executed, err := txnbuilder.New(sender).
+			Script(script).
+			ExpireAt(time.Now().Add(time.Minute)).
+			Resubmit(2, func(attempt int, cause error) {
+				assert.True(t, diemclient.IsTransactionExpired(cause))
+				attempts = append(attempts, attempt)
+			}).
+			SignSubmitAndWait(client)
+		require.NoError(t, err)
+		assert.Equal(t, "executed", executed.VmStatus.Type)
+		assert.Equal(t, []int{1, 2}, attempts)
+	})
+	t.Run("resubmit exceeds max retries", func(t *testing.T) {
+		c.expire = 2
+		defer func() { c.expire = 0 }()
+		_, err := txnbuilder.New(sender).Script(script).Resubmit(1, nil).SignSubmitAndWait(client)
+		assert.True(t, diemclient.IsTransactionExpired(err))
+	})
+	t.Run("resubmit transaction expired by ledger time", func(t *testing.T) {
+		c.pending = true
+		defer func() { c.pending = false }()
+		submitted := len(c.submitted)
+		var attempts []int
+		_, err := txnbuilder.New(sender).
+			Script(script).
+			ExpireIn(-time.Second).
+			Resubmit(1, func(attempt int, cause error) {
+				attempts = append(attempts, attempt)
+			}).
+			SignSubmitAndWait(client)
+		assert.True(t, diemclient.IsTransactionExpired(err))
+		assert.Equal(t, []int{1}, attempts)
+		assert.Len(t, c.submitted, submitted+2)
+	})
+	t.Run("no resubmission before ledger time past expiration", func(t *testing.T) {
+		c.pending = true
+		c.ledgerLag = time.Minute
+		defer func() { c.pending, c.ledgerLag = false, 0 }()
+		submitted := len(c.submitted)
+		client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+		_, err := txnbuilder.New(sender).
+			Script(script).
+			ExpireAt(time.Now().Add(100*time.Millisecond)).
+			Resubmit(1, func(attempt int, cause error) {
+				t.Errorf("unexpected resubmission: %v", cause)
+			}).
+			SignSubmitAndWait(client)
+		require.Error(t, err)
+		assert.False(t, diemclient.IsTransactionExpired(err))
+		assert.Contains(t, err.Error(), "ledger time is not past expiration yet")
+		assert.Len(t, c.submitted, submitted+1)
+	})
+	t.Run("missing script", func(t *testing.T) {
+		_, err := txnbuilder.New(sender).SignAndSubmit(client)
+		assert.EqualError(t, err, "transaction script or payload is required")
+	})
+}
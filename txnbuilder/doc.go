@@ -0,0 +1,16 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides a fluent transaction builder, which fetches sequence number, sets gas and expiration,
+// signs and submits transaction.
+//
+//	txn, err := txnbuilder.New(sender).
+//		Script(stdlib.EncodePeerToPeerWithMetadataScript(...)).
+//		MaxGas(1_000_000).
+//		GasCurrency("XUS").
+//		ExpireIn(30 * time.Second).
+//		SignAndSubmit(client)
+//
+// `Operator` submits transactions sent by an account with shared context and builder
+// customization, it is embedded by account operators, e.g. `tcops.Operator`.
+package txnbuilder
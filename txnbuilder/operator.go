@@ -0,0 +1,76 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnbuilder
+
+import (
+	"context"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// NonceSubmitter reserves a sliding nonce and submits transaction with it, e.g.
+// `tcops.SlidingNonceManager`.
+type NonceSubmitter interface {
+	SubmitWithNonce(ctx context.Context, submit func(nonce uint64) (*diemclient.Transaction, error)) (*diemclient.Transaction, error)
+}
+
+// Operator signs, submits and waits for transactions sent by the account. It is the common base
+// of account operation types, e.g. `tcops.Operator`, `validatorops.Operator` and
+// `childvasp.Manager`, which embed it.
+type Operator struct {
+	Client  diemclient.Client
+	Address diemtypes.AccountAddress
+	Signer  diemsigner.Signer
+	// Configure is called with the transaction builder of every operation for customizing
+	// gas and expiration settings, it can be nil.
+	Configure func(*Builder)
+
+	ctx context.Context
+}
+
+// NewOperator creates `Operator` for the account address and its signer
+func NewOperator(client diemclient.Client, address diemtypes.AccountAddress, signer diemsigner.Signer) Operator {
+	return Operator{Client: client, Address: address, Signer: signer, ctx: context.Background()}
+}
+
+// WithContext sets context for client calls
+func (o *Operator) WithContext(ctx context.Context) *Operator {
+	o.ctx = ctx
+	return o
+}
+
+// Context returns the context set by `WithContext`, or `context.Background()`
+func (o *Operator) Context() context.Context {
+	if o.ctx == nil {
+		return context.Background()
+	}
+	return o.ctx
+}
+
+// Submit signs and submits transaction of the payload sent by the account, and waits for it
+// executed.
+func (o *Operator) Submit(payload diemtypes.TransactionPayload) (*diemclient.Transaction, error) {
+	return o.SubmitBuilder(NewWithSigner(o.Signer).Sender(o.Address).Payload(payload))
+}
+
+// SubmitWithNonce submits transaction of the payload created with a sliding nonce reserved by
+// the nonce submitter.
+func (o *Operator) SubmitWithNonce(nonces NonceSubmitter, payload func(nonce uint64) diemtypes.TransactionPayload) (*diemclient.Transaction, error) {
+	return nonces.SubmitWithNonce(o.Context(), func(nonce uint64) (*diemclient.Transaction, error) {
+		return o.Submit(payload(nonce))
+	})
+}
+
+// SubmitBuilder sets the context, calls `Configure` with the builder, then signs and submits
+// the transaction, and waits for it executed. It is for transactions that are not sent by the
+// account or need extra settings, e.g. gas currency.
+func (o *Operator) SubmitBuilder(builder *Builder) (*diemclient.Transaction, error) {
+	builder.Context(o.Context())
+	if o.Configure != nil {
+		o.Configure(builder)
+	}
+	return builder.SignSubmitAndWait(o.Client)
+}
@@ -0,0 +1,8 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides human-readable transaction explanation: converts an on-chain transaction returned by
+// get_transactions or get_account_transaction into a structured summary of the script call,
+// payment currency, amount, payee, metadata kind and gas used, for audit logs and customer
+// support tooling.
+package txnexplain
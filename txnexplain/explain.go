@@ -0,0 +1,246 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnexplain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// UserTransactionType is JSON-RPC transaction data type of user transaction
+const UserTransactionType = "user"
+
+// Explanation is structured summary of an on-chain transaction
+type Explanation struct {
+	Version uint64
+	Hash    string
+	// Type is JSON-RPC transaction data type, e.g. "user", "blockmetadata" and "writeset"
+	Type string
+	// VMStatus is vm status type, e.g. "executed" and "move_abort"
+	VMStatus string
+
+	// Following fields are only set for user transaction
+	Sender         *diemtypes.AccountAddress
+	SequenceNumber uint64
+	// ScriptName is snake case name of the script or script function, e.g.
+	// "peer_to_peer_with_metadata"; empty if the script is unknown
+	ScriptName string
+	// Call is decoded `stdlib.ScriptCall` or `stdlib.ScriptFunctionCall`, nil if the script is
+	// unknown
+	Call interface{}
+
+	// Following fields are only set for peer to peer payment
+	Payee  *diemtypes.AccountAddress
+	Amount *diemamount.Amount
+	// Metadata is the decoded payment metadata, nil if the metadata is invalid
+	Metadata        *txnmetadata.Inspection
+	InvalidMetadata bool
+
+	GasUsed      uint64
+	GasUnitPrice uint64
+	GasCurrency  diemamount.Currency
+}
+
+// Explain converts the transaction into `Explanation`. User transaction script is decoded from
+// the transaction BCS bytes by `stdlib.DecodeScript` and `stdlib.DecodeScriptFunctionPayload`;
+// if the bytes are not provided, the JSON-RPC script fields are used.
+// Returns error if the transaction bytes can't be decoded.
+func Explain(txn *diemclient.Transaction) (*Explanation, error) {
+	ret := &Explanation{
+		Version: txn.Version,
+		Hash:    txn.Hash,
+		GasUsed: txn.GasUsed,
+	}
+	if txn.VmStatus != nil {
+		ret.VMStatus = txn.VmStatus.Type
+	}
+	data := txn.Transaction
+	if data == nil {
+		return ret, nil
+	}
+	ret.Type = data.Type
+	if data.Type != UserTransactionType {
+		return ret, nil
+	}
+	sender, err := diemtypes.MakeAccountAddress(data.Sender)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction sender: %v", err)
+	}
+	ret.Sender = &sender
+	ret.SequenceNumber = data.SequenceNumber
+	ret.GasUnitPrice = data.GasUnitPrice
+	ret.GasCurrency = diemamount.Currency(data.GasCurrency)
+
+	if txn.Bytes == "" {
+		ret.explainScript(data.Script)
+		return ret, nil
+	}
+	signed, err := decodeSignedTransaction(txn.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ret.explainPayload(signed)
+	return ret, nil
+}
+
+// Success returns true if the transaction is executed successfully
+func (e *Explanation) Success() bool {
+	return e.VMStatus == diemclient.VmStatusExecuted
+}
+
+// GasFee returns gas used multiplied by gas unit price in gas currency, returns error if
+// overflow.
+func (e *Explanation) GasFee() (diemamount.Amount, error) {
+	return diemamount.New(e.GasCurrency, e.GasUsed).Mul(e.GasUnitPrice)
+}
+
+// String returns one line human-readable summary, e.g. "version 10 user
+// peer_to_peer_with_metadata: 1.5 XUS from <sender> to <payee>, metadata: general, gas used: 400,
+// vm status: executed"
+func (e *Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "version %d %s", e.Version, e.Type)
+	if e.Type == UserTransactionType {
+		name := e.ScriptName
+		if name == "" {
+			name = "unknown script"
+		}
+		fmt.Fprintf(&b, " %s", name)
+		if e.Amount != nil {
+			fmt.Fprintf(&b, ": %s from %s", e.Amount, e.Sender)
+			if e.Payee != nil {
+				fmt.Fprintf(&b, " to %s", e.Payee)
+			}
+			switch {
+			case e.InvalidMetadata:
+				b.WriteString(", metadata: invalid")
+			case e.Metadata != nil:
+				fmt.Fprintf(&b, ", metadata: %s", e.Metadata.Kind)
+			}
+		} else {
+			fmt.Fprintf(&b, " from %s", e.Sender)
+		}
+		fmt.Fprintf(&b, ", gas used: %d", e.GasUsed)
+	}
+	fmt.Fprintf(&b, ", vm status: %s", e.VMStatus)
+	return b.String()
+}
+
+func decodeSignedTransaction(str string) (*diemtypes.SignedTransaction, error) {
+	bytes, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction bytes: %v", err)
+	}
+	txn, err := diemtypes.BcsDeserializeTransaction(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("decode transaction bytes failed: %v", err)
+	}
+	user, ok := txn.(*diemtypes.Transaction__UserTransaction)
+	if !ok {
+		return nil, fmt.Errorf("decode transaction bytes failed: expected user transaction, got %T", txn)
+	}
+	return &user.Value, nil
+}
+
+func (e *Explanation) explainPayload(txn *diemtypes.SignedTransaction) {
+	switch payload := txn.RawTxn.Payload.(type) {
+	case *diemtypes.TransactionPayload__Script:
+		call, err := stdlib.DecodeScript(&payload.Value)
+		if err != nil {
+			return
+		}
+		e.Call = call
+		e.ScriptName = callName(call, "ScriptCall__")
+		if p2p, ok := call.(*stdlib.ScriptCall__PeerToPeerWithMetadata); ok {
+			e.setPayment(p2p.Currency, p2p.Amount, &p2p.Payee, p2p.Metadata)
+		}
+	case *diemtypes.TransactionPayload__ScriptFunction:
+		call, err := stdlib.DecodeScriptFunctionPayload(payload)
+		if err != nil {
+			return
+		}
+		e.Call = call
+		e.ScriptName = string(payload.Value.Function)
+		switch p2p := call.(type) {
+		case *stdlib.ScriptFunctionCall__PeerToPeerWithMetadata:
+			e.setPayment(p2p.Currency, p2p.Amount, &p2p.Payee, p2p.Metadata)
+		case *stdlib.ScriptFunctionCall__PeerToPeerBySigners:
+			var payee *diemtypes.AccountAddress
+			if auth, ok := txn.Authenticator.(*diemtypes.TransactionAuthenticator__MultiAgent); ok &&
+				len(auth.SecondarySignerAddresses) > 0 {
+				payee = &auth.SecondarySignerAddresses[0]
+			}
+			e.setPayment(p2p.Currency, p2p.Amount, payee, p2p.Metadata)
+		}
+	}
+}
+
+func (e *Explanation) explainScript(script *diemclient.Script) {
+	if script == nil || script.Type == "" || script.Type == "unknown" {
+		return
+	}
+	e.ScriptName = script.Type
+	if script.Receiver == "" {
+		return
+	}
+	payee, err := diemtypes.MakeAccountAddress(script.Receiver)
+	if err != nil {
+		return
+	}
+	amount := diemamount.New(diemamount.Currency(script.Currency), script.Amount)
+	e.Payee = &payee
+	e.Amount = &amount
+	metadata, err := hex.DecodeString(script.Metadata)
+	if err != nil {
+		e.InvalidMetadata = true
+		return
+	}
+	e.inspectMetadata(metadata)
+}
+
+func (e *Explanation) setPayment(currency diemtypes.TypeTag, amount uint64, payee *diemtypes.AccountAddress, metadata []byte) {
+	code := ""
+	if tag, ok := currency.(*diemtypes.TypeTag__Struct); ok {
+		code = string(tag.Value.Name)
+	}
+	ret := diemamount.New(diemamount.Currency(code), amount)
+	e.Amount = &ret
+	e.Payee = payee
+	e.inspectMetadata(metadata)
+}
+
+func (e *Explanation) inspectMetadata(metadata []byte) {
+	inspection, err := txnmetadata.Inspect(metadata)
+	if err != nil {
+		e.InvalidMetadata = true
+		return
+	}
+	e.Metadata = inspection
+}
+
+// callName converts call type name to snake case, e.g.
+// `*stdlib.ScriptCall__PeerToPeerWithMetadata` to "peer_to_peer_with_metadata"
+func callName(call interface{}, prefix string) string {
+	name := strings.TrimPrefix(reflect.TypeOf(call).Elem().Name(), prefix)
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnexplain_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/txnexplain"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var payee = diemtypes.MustMakeAccountAddress("a74fd7c46952c497e75afb0a7932586d")
+
+func newTransaction(keys *diemkeys.Keys, payload diemtypes.TransactionPayload) *diemclient.Transaction {
+	signed := diemsigner.SignTxn(keys, keys.AccountAddress(), 5, payload, 1_000_000, 1, "XUS", 1593189628, testnet.ChainID)
+	return &diemclient.Transaction{
+		Version: 10,
+		Hash:    "hash",
+		Bytes:   diemtypes.ToHex(&diemtypes.Transaction__UserTransaction{Value: *signed}),
+		Transaction: &diemclient.TransactionData{
+			Type:           "user",
+			Sender:         keys.AccountAddress().Hex(),
+			SequenceNumber: 5,
+			GasUnitPrice:   1,
+			GasCurrency:    "XUS",
+		},
+		VmStatus: &diemclient.VmStatus{Type: "executed"},
+		GasUsed:  400,
+	}
+}
+
+func TestExplain(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	subAddress, err := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	require.NoError(t, err)
+	metadata := txnmetadata.NewGeneralMetadataToSubAddress(subAddress)
+	script := &diemtypes.TransactionPayload__Script{
+		Value: stdlib.EncodePeerToPeerWithMetadataScript(diemtypes.Currency("XUS"), payee, 1_500_000, metadata, nil),
+	}
+
+	t.Run("peer to peer script", func(t *testing.T) {
+		ret, err := txnexplain.Explain(newTransaction(keys, script))
+		require.NoError(t, err)
+		assert.Equal(t, "peer_to_peer_with_metadata", ret.ScriptName)
+		assert.IsType(t, &stdlib.ScriptCall__PeerToPeerWithMetadata{}, ret.Call)
+		assert.Equal(t, keys.AccountAddress(), *ret.Sender)
+		assert.Equal(t, payee, *ret.Payee)
+		assert.Equal(t, diemamount.New("XUS", 1_500_000), *ret.Amount)
+		assert.Equal(t, txnmetadata.MetadataKindGeneral, ret.Metadata.Kind)
+		assert.Equal(t, subAddress, *ret.Metadata.ToSubAddress)
+		assert.True(t, ret.Success())
+		fee, err := ret.GasFee()
+		require.NoError(t, err)
+		assert.Equal(t, diemamount.New("XUS", 400), fee)
+		assert.Equal(t, "version 10 user peer_to_peer_with_metadata: 1.5 XUS from "+keys.AccountAddress().Hex()+
+			" to "+payee.Hex()+", metadata: general, gas used: 400, vm status: executed", ret.String())
+	})
+
+	t.Run("peer to peer script function", func(t *testing.T) {
+		txn := newTransaction(keys, stdlib.EncodePeerToPeerWithMetadataScriptFunction(
+			diemtypes.Currency("XDX"), payee, 100, []byte{0xff}, nil))
+		ret, err := txnexplain.Explain(txn)
+		require.NoError(t, err)
+		assert.Equal(t, "peer_to_peer_with_metadata", ret.ScriptName)
+		assert.Equal(t, diemamount.New("XDX", 100), *ret.Amount)
+		assert.Equal(t, payee, *ret.Payee)
+		assert.True(t, ret.InvalidMetadata)
+		assert.Nil(t, ret.Metadata)
+	})
+
+	t.Run("other script", func(t *testing.T) {
+		txn := newTransaction(keys, &diemtypes.TransactionPayload__Script{
+			Value: stdlib.EncodeAddCurrencyToAccountScript(diemtypes.Currency("XUS")),
+		})
+		ret, err := txnexplain.Explain(txn)
+		require.NoError(t, err)
+		assert.Equal(t, "add_currency_to_account", ret.ScriptName)
+		assert.Nil(t, ret.Amount)
+		assert.Nil(t, ret.Payee)
+		assert.Equal(t, "version 10 user add_currency_to_account from "+keys.AccountAddress().Hex()+
+			", gas used: 400, vm status: executed", ret.String())
+	})
+
+	t.Run("unknown script", func(t *testing.T) {
+		txn := newTransaction(keys, &diemtypes.TransactionPayload__Script{Value: diemtypes.Script{Code: []byte{1}}})
+		ret, err := txnexplain.Explain(txn)
+		require.NoError(t, err)
+		assert.Equal(t, "", ret.ScriptName)
+		assert.Nil(t, ret.Call)
+	})
+
+	t.Run("without bytes", func(t *testing.T) {
+		txn := newTransaction(keys, script)
+		txn.Bytes = ""
+		txn.Transaction.Script = &diemclient.Script{
+			Type:     "peer_to_peer_with_metadata",
+			Receiver: payee.Hex(),
+			Amount:   100,
+			Currency: "XUS",
+			Metadata: hex.EncodeToString(metadata),
+		}
+		ret, err := txnexplain.Explain(txn)
+		require.NoError(t, err)
+		assert.Equal(t, "peer_to_peer_with_metadata", ret.ScriptName)
+		assert.Equal(t, diemamount.New("XUS", 100), *ret.Amount)
+		assert.Equal(t, payee, *ret.Payee)
+		assert.Equal(t, txnmetadata.MetadataKindGeneral, ret.Metadata.Kind)
+	})
+
+	t.Run("block metadata", func(t *testing.T) {
+		ret, err := txnexplain.Explain(&diemclient.Transaction{
+			Version:     11,
+			Transaction: &diemclient.TransactionData{Type: "blockmetadata"},
+			VmStatus:    &diemclient.VmStatus{Type: "executed"},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, ret.Sender)
+		assert.Equal(t, "version 11 blockmetadata, vm status: executed", ret.String())
+	})
+
+	t.Run("invalid bytes", func(t *testing.T) {
+		txn := newTransaction(keys, script)
+		txn.Bytes = "xyz"
+		_, err := txnexplain.Explain(txn)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,212 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnexport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/events"
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// Cursor is next event sequence number of each event key to export
+type Cursor map[string]uint64
+
+// Result is the result of an export
+type Result struct {
+	// Records is number of records written
+	Records int
+	// Cursor is for resuming next export starts from the version after the exported range; when
+	// the export failed, it is the next event sequence number after the last written record of
+	// each event key, for resuming the export of the same version range.
+	Cursor Cursor
+}
+
+// Exporter exports payments of accounts: it streams sent and received payment events of an
+// account, decodes the payment metadata, and writes records with the stable schema `Columns`.
+type Exporter struct {
+	Client    diemclient.Client
+	BatchSize uint64
+}
+
+// New creates `Exporter` with the client; enable `diemclient.WithResponseCache` on the client
+// for caching timestamps of versions when exporting multiple accounts.
+func New(client diemclient.Client) *Exporter {
+	return &Exporter{Client: client, BatchSize: events.DefaultBatchSize}
+}
+
+// Export writes sent and received payments of the account in the version range [start, end]
+// ordered by version, from the given cursor; nil cursor exports from the first event.
+// The writer is flushed after all records are written.
+// The result is returned with the error when the export failed, records written before the
+// failure are not exported again by resuming with the result cursor.
+func (e *Exporter) Export(ctx context.Context, w Writer, address diemtypes.AccountAddress, start, end uint64, cursor Cursor) (*Result, error) {
+	ret := &Result{Cursor: make(Cursor)}
+	for key, seq := range cursor {
+		ret.Cursor[key] = seq
+	}
+	account, err := diemclient.AsContextClient(e.Client).GetAccountWithContext(ctx, address)
+	if err != nil {
+		return ret, err
+	}
+	if account == nil {
+		return ret, fmt.Errorf("account %s not found", address.Hex())
+	}
+	var sources []*source
+	for _, key := range []string{account.SentEventsKey, account.ReceivedEventsKey} {
+		stream := events.NewStream(e.Client, key, cursor[key])
+		stream.BatchSize = e.BatchSize
+		sources = append(sources, &source{stream: stream, start: start, end: end})
+	}
+
+	var timestamps timestampCache
+	for {
+		next, err := earliest(ctx, sources)
+		if err != nil {
+			return ret, err
+		}
+		if next == nil {
+			break
+		}
+		event := next.pop()
+		record, err := newRecord(event)
+		if err != nil {
+			return ret, fmt.Errorf("event %s#%d: %v", event.Key, event.SequenceNumber, err)
+		}
+		if record.Timestamp, err = timestamps.get(ctx, e.Client, event.TransactionVersion); err != nil {
+			return ret, err
+		}
+		if err := w.Write(record); err != nil {
+			return ret, err
+		}
+		ret.Records++
+		ret.Cursor[event.Key] = event.SequenceNumber + 1
+	}
+	for _, source := range sources {
+		ret.Cursor[source.stream.Key] = source.stream.Cursor()
+	}
+	return ret, w.Flush()
+}
+
+// newRecord creates `Record` of sent or received payment event
+func newRecord(event *diemclient.Event) (*Record, error) {
+	data, err := events.DecodeEventData(event)
+	if err != nil {
+		return nil, err
+	}
+	ret := &Record{
+		Version:             event.TransactionVersion,
+		Type:                data.EventType(),
+		EventKey:            event.Key,
+		EventSequenceNumber: event.SequenceNumber,
+	}
+	var metadata []byte
+	switch e := data.(type) {
+	case *events.SentPaymentEvent:
+		ret.Sender, ret.Payee = e.Sender, e.Receiver
+		ret.Amount, ret.Currency = e.Amount.Micro, string(e.Amount.Currency)
+		metadata = e.Metadata
+	case *events.ReceivedPaymentEvent:
+		ret.Sender, ret.Payee = e.Sender, e.Receiver
+		ret.Amount, ret.Currency = e.Amount.Micro, string(e.Amount.Currency)
+		metadata = e.Metadata
+	default:
+		return nil, fmt.Errorf("unexpected event type %s", data.EventType())
+	}
+	inspection, err := txnmetadata.Inspect(metadata)
+	if err != nil {
+		ret.MetadataKind = MetadataKindInvalid
+		return ret, nil
+	}
+	ret.MetadataKind = inspection.Kind
+	ret.FromSubAddress = inspection.FromSubAddress
+	ret.ToSubAddress = inspection.ToSubAddress
+	ret.ReferencedEvent = inspection.ReferencedEvent
+	ret.RefundTransactionVersion = inspection.RefundTransactionVersion
+	ret.RefundReason = inspection.RefundReason
+	ret.ReferenceID = inspection.ReferenceID
+	ret.OffChainReferenceID = inspection.OffChainReferenceID
+	return ret, nil
+}
+
+// source buffers events of an event key in the version range
+type source struct {
+	stream     *events.Stream
+	start, end uint64
+	buffer     []*diemclient.Event
+	done       bool
+}
+
+// peek returns next event in the version range, nil if there is no more events. The stream
+// cursor is moved back to the first event after the end version.
+func (s *source) peek(ctx context.Context) (*diemclient.Event, error) {
+	for len(s.buffer) == 0 && !s.done {
+		batch, err := s.stream.Poll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.done = uint64(len(batch)) < s.stream.BatchSize
+		for _, event := range batch {
+			if event.TransactionVersion > s.end {
+				s.stream.Seek(event.SequenceNumber)
+				s.done = true
+				break
+			}
+			if event.TransactionVersion >= s.start {
+				s.buffer = append(s.buffer, event)
+			}
+		}
+	}
+	if len(s.buffer) == 0 {
+		return nil, nil
+	}
+	return s.buffer[0], nil
+}
+
+func (s *source) pop() *diemclient.Event {
+	ret := s.buffer[0]
+	s.buffer = s.buffer[1:]
+	return ret
+}
+
+// earliest returns the source of the next event with the lowest version, nil if all sources
+// are done.
+func earliest(ctx context.Context, sources []*source) (*source, error) {
+	var ret *source
+	var version uint64
+	for _, source := range sources {
+		event, err := source.peek(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if event != nil && (ret == nil || event.TransactionVersion < version) {
+			ret, version = source, event.TransactionVersion
+		}
+	}
+	return ret, nil
+}
+
+// timestampCache caches the ledger timestamp of the last queried version, as events of a
+// transaction are exported together.
+type timestampCache struct {
+	version   uint64
+	timestamp *time.Time
+}
+
+func (c *timestampCache) get(ctx context.Context, client diemclient.Client, version uint64) (time.Time, error) {
+	if c.timestamp != nil && c.version == version {
+		return *c.timestamp, nil
+	}
+	metadata, err := diemclient.AsContextClient(client).GetMetadataByVersionWithContext(ctx, version)
+	if err != nil {
+		return time.Time{}, err
+	}
+	timestamp := time.Unix(0, int64(metadata.Timestamp)*int64(time.Microsecond)).UTC()
+	c.version, c.timestamp = version, &timestamp
+	return timestamp, nil
+}
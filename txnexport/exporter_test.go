@@ -0,0 +1,198 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnexport_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/events"
+	"github.com/diem/client-sdk-go/txnexport"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addPayment commits a transaction with sent or received payment event of the account by the
+// event key, returns the transaction version
+func addPayment(server *diemclienttest.Server, account, other diemtypes.AccountAddress, key string, amount uint64, metadata []byte) uint64 {
+	data := &diemclient.EventData{
+		Type:     events.SentPaymentEventType,
+		Amount:   &diemclient.Amount{Amount: amount, Currency: "XUS"},
+		Sender:   account.Hex(),
+		Receiver: other.Hex(),
+		Metadata: hex.EncodeToString(metadata),
+	}
+	if key == "received" {
+		data.Type = events.ReceivedPaymentEventType
+		data.Sender, data.Receiver = data.Receiver, data.Sender
+	}
+	txn := &diemclient.Transaction{Events: []*diemclient.Event{{Key: key, Data: data}}}
+	server.AddTransactions(txn)
+	return txn.Version
+}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	server := diemclienttest.NewServer()
+	account := server.GenAccount(&diemclient.Account{SentEventsKey: "sent", ReceivedEventsKey: "received"}).AccountAddress()
+	other := diemkeys.MustGenKeys().AccountAddress()
+	pay := func(key string, amount uint64, metadata []byte) uint64 {
+		return addPayment(server, account, other, key, amount, metadata)
+	}
+	sub1, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	sub2, _ := diemtypes.MakeSubAddress("111111153010a111")
+	referenceID, err := txnmetadata.NewReferenceID()
+	require.NoError(t, err)
+
+	skipped := pay("received", 1, nil)
+	v1 := pay("received", 100, txnmetadata.NewGeneralMetadataToSubAddress(sub1))
+	v2 := pay("sent", 50, txnmetadata.NewGeneralMetadataWithFromToSubAddresses(sub2, sub1))
+	v3 := pay("sent", 100, txnmetadata.NewRefundMetadata(v1, &diemtypes.RefundReason__InvalidSubaddress{}))
+	v4 := pay("received", 200, txnmetadata.NewPaymentMetadata(referenceID))
+	v5 := pay("received", 300, []byte{0xff})
+	next := pay("sent", 1, nil)
+
+	exporter := txnexport.New(server.Client())
+	exporter.BatchSize = 2
+	var buf bytes.Buffer
+	result, err := exporter.Export(ctx, txnexport.NewCSVWriter(&buf), account, v1, v5, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Records)
+	assert.Equal(t, txnexport.Cursor{"sent": 2, "received": 4}, result.Cursor)
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 6)
+	header := []string{}
+	for _, column := range txnexport.Columns {
+		header = append(header, column.Name)
+	}
+	assert.Equal(t, header, rows[0])
+	column := func(row []string, name string) string {
+		for i, c := range txnexport.Columns {
+			if c.Name == name {
+				return row[i]
+			}
+		}
+		t.Fatalf("column %s not found", name)
+		return ""
+	}
+
+	versions := []string{}
+	for _, row := range rows[1:] {
+		versions = append(versions, column(row, "version"))
+		timestamp, err := time.Parse(time.RFC3339Nano, column(row, "timestamp"))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), timestamp, time.Minute)
+	}
+	assert.Equal(t, []string{fmtUint(v1), fmtUint(v2), fmtUint(v3), fmtUint(v4), fmtUint(v5)}, versions)
+
+	received := rows[1]
+	assert.Equal(t, "receivedpayment", column(received, "type"))
+	assert.Equal(t, "received", column(received, "event_key"))
+	assert.Equal(t, "1", column(received, "event_sequence_number"))
+	assert.Equal(t, other.Hex(), column(received, "sender"))
+	assert.Equal(t, account.Hex(), column(received, "payee"))
+	assert.Equal(t, "100", column(received, "amount"))
+	assert.Equal(t, "XUS", column(received, "currency"))
+	assert.Equal(t, "general", column(received, "metadata_kind"))
+	assert.Equal(t, "", column(received, "from_subaddress"))
+	assert.Equal(t, sub1.Hex(), column(received, "to_subaddress"))
+
+	sent := rows[2]
+	assert.Equal(t, "sentpayment", column(sent, "type"))
+	assert.Equal(t, account.Hex(), column(sent, "sender"))
+	assert.Equal(t, other.Hex(), column(sent, "payee"))
+	assert.Equal(t, sub2.Hex(), column(sent, "from_subaddress"))
+	assert.Equal(t, sub1.Hex(), column(sent, "to_subaddress"))
+
+	refund := rows[3]
+	assert.Equal(t, "refund", column(refund, "metadata_kind"))
+	assert.Equal(t, fmtUint(v1), column(refund, "refund_transaction_version"))
+	assert.Equal(t, "invalid_subaddress", column(refund, "refund_reason"))
+
+	assert.Equal(t, "payment", column(rows[4], "metadata_kind"))
+	assert.Equal(t, referenceID.String(), column(rows[4], "reference_id"))
+	assert.Equal(t, "invalid", column(rows[5], "metadata_kind"))
+
+	t.Run("resume from cursor", func(t *testing.T) {
+		var buf bytes.Buffer
+		resumed, err := exporter.Export(ctx, txnexport.NewCSVWriter(&buf), account, v5+1, next, result.Cursor)
+		require.NoError(t, err)
+		assert.Equal(t, 1, resumed.Records)
+		assert.Equal(t, txnexport.Cursor{"sent": 3, "received": 4}, resumed.Cursor)
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, fmtUint(next), column(rows[1], "version"))
+	})
+	t.Run("resume failed export", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := &failingWriter{Writer: txnexport.NewCSVWriter(&buf), limit: 2}
+		failed, err := exporter.Export(ctx, w, account, v1, v5, nil)
+		assert.Equal(t, errWriteFailed, err)
+		require.NotNil(t, failed)
+		assert.Equal(t, 2, failed.Records)
+		assert.Equal(t, txnexport.Cursor{"sent": 1, "received": 2}, failed.Cursor)
+
+		w.limit = -1
+		resumed, err := exporter.Export(ctx, w, account, v1, v5, failed.Cursor)
+		require.NoError(t, err)
+		assert.Equal(t, 3, resumed.Records)
+		assert.Equal(t, result.Cursor, resumed.Cursor)
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		versions := []string{}
+		for _, row := range rows[1:] {
+			versions = append(versions, column(row, "version"))
+		}
+		assert.Equal(t, []string{fmtUint(v1), fmtUint(v2), fmtUint(v3), fmtUint(v4), fmtUint(v5)}, versions)
+	})
+	t.Run("no records", func(t *testing.T) {
+		var buf bytes.Buffer
+		ret, err := exporter.Export(ctx, txnexport.NewCSVWriter(&buf), account, skipped, skipped-1, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 0, ret.Records)
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{header}, rows)
+	})
+	t.Run("account not found", func(t *testing.T) {
+		unknown := diemkeys.MustGenKeys().AccountAddress()
+		_, err := exporter.Export(ctx, txnexport.NewCSVWriter(&bytes.Buffer{}), unknown, 0, next, nil)
+		assert.EqualError(t, err, "account "+unknown.Hex()+" not found")
+	})
+}
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter fails writing records after the limit number of records is written, negative
+// limit for no limit.
+type failingWriter struct {
+	txnexport.Writer
+	limit int
+}
+
+func (w *failingWriter) Write(record *txnexport.Record) error {
+	if w.limit == 0 {
+		return errWriteFailed
+	}
+	w.limit--
+	return w.Writer.Write(record)
+}
+
+func fmtUint(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
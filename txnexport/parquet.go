@@ -0,0 +1,404 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// DefaultParquetRowGroupSize is the default max number of records of a Parquet row group
+const DefaultParquetRowGroupSize = 100_000
+
+// parquetMagic is the magic bytes at the beginning and the end of Parquet files
+const parquetMagic = "PAR1"
+
+// Parquet physical types, converted types, encodings and page type, see parquet.thrift of
+// Apache Parquet format.
+const (
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMicros = 10
+	parquetConvertedUint64          = 14
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+	parquetPageTypeData      = 0
+)
+
+// ParquetWriter writes records as a Parquet file with the schema of `Columns`: uint64 columns
+// are INT64 annotated as UINT_64, timestamp columns are INT64 annotated as TIMESTAMP_MICROS,
+// string columns are BYTE_ARRAY annotated as UTF8, and optional columns are null when the value
+// is not set, e.g. nil `Record.OffChainReferenceID` is null and empty string is an empty value.
+// Pages are PLAIN encoded and uncompressed.
+//
+// Records are buffered in memory until `RowGroupSize` records are written as a row group; the
+// file metadata is written by `Flush`, hence the file is complete only after `Flush` is called,
+// and records can't be written after it.
+type ParquetWriter struct {
+	// RowGroupSize is the max number of records of a row group
+	RowGroupSize int
+
+	w         *countingWriter
+	rows      []*Record
+	rowGroups []*parquetRowGroup
+	numRows   int64
+	flushed   bool
+}
+
+// NewParquetWriter creates `ParquetWriter` writing to w
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{RowGroupSize: DefaultParquetRowGroupSize, w: &countingWriter{w: w}}
+}
+
+// Write implements `Writer`
+func (w *ParquetWriter) Write(record *Record) error {
+	if w.flushed {
+		return errors.New("parquet file is flushed")
+	}
+	w.rows = append(w.rows, record)
+	if len(w.rows) >= w.RowGroupSize {
+		return w.writeRowGroup()
+	}
+	return nil
+}
+
+// Flush implements `Writer`, it writes buffered records as the last row group and the file
+// metadata.
+func (w *ParquetWriter) Flush() error {
+	if w.flushed {
+		return nil
+	}
+	if err := w.writeRowGroup(); err != nil {
+		return err
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	footer := w.fileMetaData()
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	if _, err := w.w.Write(append(append(footer, length[:]...), parquetMagic...)); err != nil {
+		return err
+	}
+	w.flushed = true
+	return nil
+}
+
+// writeMagic writes the magic bytes at the beginning of the file
+func (w *ParquetWriter) writeMagic() error {
+	if w.w.n > 0 {
+		return nil
+	}
+	_, err := w.w.Write([]byte(parquetMagic))
+	return err
+}
+
+type parquetRowGroup struct {
+	columns []*parquetColumnChunk
+	size    int64
+	rows    int64
+}
+
+type parquetColumnChunk struct {
+	column Column
+	offset int64
+	size   int64
+	values int64
+}
+
+func (w *ParquetWriter) writeRowGroup() error {
+	if len(w.rows) == 0 {
+		return nil
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	values := make([][]string, len(w.rows))
+	set := make([][]bool, len(w.rows))
+	for i, record := range w.rows {
+		values[i], set[i] = record.values()
+	}
+	group := &parquetRowGroup{rows: int64(len(w.rows))}
+	for i, column := range Columns {
+		data, err := encodeParquetColumn(column, values, set, i)
+		if err != nil {
+			return fmt.Errorf("encode parquet column %s failed: %v", column.Name, err)
+		}
+		page := parquetPageHeader(len(w.rows), len(data))
+		chunk := &parquetColumnChunk{
+			column: column,
+			offset: w.w.n,
+			size:   int64(len(page) + len(data)),
+			values: int64(len(w.rows)),
+		}
+		if _, err := w.w.Write(append(page, data...)); err != nil {
+			return err
+		}
+		group.columns = append(group.columns, chunk)
+		group.size += chunk.size
+	}
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += group.rows
+	w.rows = nil
+	return nil
+}
+
+// encodeParquetColumn returns data page bytes of the column values: definition levels of
+// optional column, followed by PLAIN encoded non-null values.
+func encodeParquetColumn(column Column, rows [][]string, set [][]bool, index int) ([]byte, error) {
+	var buf bytes.Buffer
+	if column.Optional {
+		levels := make([]byte, len(rows))
+		for i := range rows {
+			if set[i][index] {
+				levels[i] = 1
+			}
+		}
+		buf.Write(encodeParquetLevels(levels))
+	}
+	for i, row := range rows {
+		text := row[index]
+		if !set[i][index] {
+			continue
+		}
+		switch column.Type {
+		case ColumnTypeUint64:
+			v, err := strconv.ParseUint(text, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			_ = binary.Write(&buf, binary.LittleEndian, v)
+		case ColumnTypeTimestamp:
+			t, err := time.Parse(time.RFC3339Nano, text)
+			if err != nil {
+				return nil, err
+			}
+			micros := t.Unix()*int64(time.Second/time.Microsecond) + int64(t.Nanosecond())/int64(time.Microsecond)
+			_ = binary.Write(&buf, binary.LittleEndian, micros)
+		default:
+			_ = binary.Write(&buf, binary.LittleEndian, uint32(len(text)))
+			buf.WriteString(text)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeParquetLevels encodes 0 or 1 levels by RLE / bit-packed hybrid encoding of bit width 1
+// with runs of repeated values, prefixed by 4 bytes length.
+func encodeParquetLevels(levels []byte) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		writeUvarint(&buf, uint64(j-i)<<1)
+		buf.WriteByte(levels[i])
+		i = j
+	}
+	ret := make([]byte, 4, 4+buf.Len())
+	binary.LittleEndian.PutUint32(ret, uint32(buf.Len()))
+	return append(ret, buf.Bytes()...)
+}
+
+func parquetPhysicalType(column Column) (int32, int32) {
+	switch column.Type {
+	case ColumnTypeUint64:
+		return parquetTypeInt64, parquetConvertedUint64
+	case ColumnTypeTimestamp:
+		return parquetTypeInt64, parquetConvertedTimestampMicros
+	default:
+		return parquetTypeByteArray, parquetConvertedUTF8
+	}
+}
+
+// parquetPageHeader returns thrift `PageHeader` of the data page
+func parquetPageHeader(numValues, size int) []byte {
+	t := newThriftWriter()
+	t.i32(1, parquetPageTypeData)
+	t.i32(2, int32(size))
+	t.i32(3, int32(size))
+	t.beginStruct(5) // DataPageHeader
+	t.i32(1, int32(numValues))
+	t.i32(2, parquetEncodingPlain)
+	t.i32(3, parquetEncodingRLE)
+	t.i32(4, parquetEncodingRLE)
+	t.endStruct()
+	return t.end()
+}
+
+// fileMetaData returns thrift `FileMetaData` of the written row groups
+func (w *ParquetWriter) fileMetaData() []byte {
+	t := newThriftWriter()
+	t.i32(1, 1)
+	t.beginList(2, thriftStruct, len(Columns)+1)
+	t.beginElement()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(Columns)))
+	t.endStruct()
+	for _, column := range Columns {
+		physical, converted := parquetPhysicalType(column)
+		repetition := int32(parquetRequired)
+		if column.Optional {
+			repetition = parquetOptional
+		}
+		t.beginElement()
+		t.i32(1, physical)
+		t.i32(3, repetition)
+		t.binary(4, column.Name)
+		t.i32(6, converted)
+		t.endStruct()
+	}
+	t.i64(3, w.numRows)
+	t.beginList(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		t.beginElement()
+		t.beginList(1, thriftStruct, len(group.columns))
+		for _, chunk := range group.columns {
+			physical, _ := parquetPhysicalType(chunk.column)
+			t.beginElement()
+			t.i64(2, chunk.offset)
+			t.beginStruct(3) // ColumnMetaData
+			t.i32(1, physical)
+			t.beginList(2, thriftI32, 2)
+			t.listI32(parquetEncodingPlain)
+			t.listI32(parquetEncodingRLE)
+			t.beginList(3, thriftBinary, 1)
+			t.listBinary(chunk.column.Name)
+			t.i32(4, parquetCodecUncompressed)
+			t.i64(5, chunk.values)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, group.size)
+		t.i64(3, group.rows)
+		t.endStruct()
+	}
+	t.binary(6, "diem client-sdk-go txnexport")
+	return t.end()
+}
+
+// countingWriter counts bytes written for the offsets of column chunks
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes a struct by thrift compact protocol, which is used by Parquet file
+// metadata and page headers. Nested structs are written by `beginStruct` or `beginElement`
+// (list element), and ended by `endStruct`.
+type thriftWriter struct {
+	buf bytes.Buffer
+	// fields is the last field id of the structs being written, for field id deltas
+	fields []int16
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{fields: []int16{0}}
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.fields[len(t.fields)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		writeUvarint(&t.buf, zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	writeUvarint(&t.buf, zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	writeUvarint(&t.buf, zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, v string) {
+	t.field(id, thriftBinary)
+	t.listBinary(v)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginElement()
+}
+
+func (t *thriftWriter) beginList(id int16, elemType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		writeUvarint(&t.buf, uint64(size))
+	}
+}
+
+func (t *thriftWriter) beginElement() {
+	t.fields = append(t.fields, 0)
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	writeUvarint(&t.buf, zigzag(int64(v)))
+}
+
+func (t *thriftWriter) listBinary(v string) {
+	writeUvarint(&t.buf, uint64(len(v)))
+	t.buf.WriteString(v)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.fields = t.fields[:len(t.fields)-1]
+}
+
+// end ends the top level struct and returns the encoded bytes
+func (t *thriftWriter) end() []byte {
+	t.endStruct()
+	return t.buf.Bytes()
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
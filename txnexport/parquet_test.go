@@ -0,0 +1,285 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnexport_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/txnexport"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParquetWriter(t *testing.T) {
+	ctx := context.Background()
+	server := diemclienttest.NewServer()
+	account := server.GenAccount(&diemclient.Account{SentEventsKey: "sent", ReceivedEventsKey: "received"}).AccountAddress()
+	other := diemkeys.MustGenKeys().AccountAddress()
+	sub, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	addPayment(server, account, other, "received", 100, txnmetadata.NewGeneralMetadataToSubAddress(sub))
+	addPayment(server, account, other, "sent", 50, nil)
+	last := addPayment(server, account, other, "received", 300, []byte{0xff})
+	exporter := txnexport.New(server.Client())
+
+	var csvBuf bytes.Buffer
+	_, err := exporter.Export(ctx, txnexport.NewCSVWriter(&csvBuf), account, 0, last, nil)
+	require.NoError(t, err)
+	expected := make([][]string, len(txnexport.Columns))
+	rows := bytes.Split(bytes.TrimSpace(csvBuf.Bytes()), []byte("\n"))[1:]
+	for _, row := range rows {
+		for i, value := range bytes.Split(row, []byte(",")) {
+			expected[i] = append(expected[i], string(value))
+		}
+	}
+
+	for _, rowGroupSize := range []int{txnexport.DefaultParquetRowGroupSize, 2} {
+		t.Run(fmt.Sprintf("row group size %d", rowGroupSize), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := txnexport.NewParquetWriter(&buf)
+			w.RowGroupSize = rowGroupSize
+			ret, err := exporter.Export(ctx, w, account, 0, last, nil)
+			require.NoError(t, err)
+			assert.Equal(t, 3, ret.Records)
+			values, _ := readParquet(t, buf.Bytes())
+			assert.Equal(t, expected, values)
+		})
+	}
+	t.Run("no records", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, txnexport.NewParquetWriter(&buf).Flush())
+		values, _ := readParquet(t, buf.Bytes())
+		assert.Equal(t, make([][]string, len(txnexport.Columns)), values)
+	})
+	t.Run("null and empty values", func(t *testing.T) {
+		empty := ""
+		var buf bytes.Buffer
+		w := txnexport.NewParquetWriter(&buf)
+		require.NoError(t, w.Write(&txnexport.Record{OffChainReferenceID: &empty}))
+		require.NoError(t, w.Write(&txnexport.Record{}))
+		require.NoError(t, w.Flush())
+		values, nulls := readParquet(t, buf.Bytes())
+		assert.Equal(t, []string{"", ""}, values[16])
+		assert.Equal(t, []bool{false, true}, nulls[16])
+		assert.Equal(t, []bool{false, false}, nulls[0])
+	})
+	t.Run("golden file", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := txnexport.NewParquetWriter(&buf)
+		w.RowGroupSize = 2
+		for _, record := range goldenRecords() {
+			require.NoError(t, w.Write(record))
+		}
+		require.NoError(t, w.Flush())
+		if *update {
+			require.NoError(t, ioutil.WriteFile(goldenFile, buf.Bytes(), 0644))
+		}
+		golden, err := ioutil.ReadFile(goldenFile)
+		require.NoError(t, err)
+		assert.Equal(t, golden, buf.Bytes())
+	})
+	t.Run("write after flush", func(t *testing.T) {
+		w := txnexport.NewParquetWriter(&bytes.Buffer{})
+		require.NoError(t, w.Flush())
+		assert.Error(t, w.Write(&txnexport.Record{}))
+	})
+}
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenFile is the Parquet file of `goldenRecords`, update it by running tests with -update
+// flag after changing the file format.
+const goldenFile = "testdata/records.parquet"
+
+// goldenRecords returns records covering all column types, null values and empty values
+func goldenRecords() []*txnexport.Record {
+	sender := diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	payee := diemtypes.MustMakeAccountAddress("000000000000000000000000000000dd")
+	sub := diemtypes.SubAddress{0x8f, 0x8b, 0x82, 0x15, 0x30, 0x10, 0xa1, 0xbd}
+	referenced, refunded, empty := uint64(7), uint64(12), ""
+	offChain := "d3a8dbc7-0a0c-4ab2-9c5e-5b2bd10e6a53"
+	return []*txnexport.Record{
+		{
+			Version: 10, Timestamp: time.Unix(1600000000, 123456000), Type: txnexport.RecordTypeReceived,
+			EventKey: "0000000000000000f72589b71ff4f8d139674a3f7369c69b", EventSequenceNumber: 1,
+			Sender: payee, Payee: sender, Amount: 100, Currency: "XUS", MetadataKind: "general_metadata",
+			ToSubAddress: &sub, ReferencedEvent: &referenced,
+		},
+		{
+			Version: 11, Timestamp: time.Unix(1600000001, 0), Type: txnexport.RecordTypeSent,
+			EventKey: "0100000000000000f72589b71ff4f8d139674a3f7369c69b", EventSequenceNumber: 2,
+			Sender: sender, Payee: payee, Amount: 50, Currency: "XUS", MetadataKind: "refund_metadata",
+			FromSubAddress: &sub, RefundTransactionVersion: &refunded, RefundReason: &diemtypes.RefundReason__InvalidSubaddress{},
+		},
+		{
+			Version: 12, Timestamp: time.Unix(1600000002, 0), Type: txnexport.RecordTypeSent,
+			EventKey: "0100000000000000f72589b71ff4f8d139674a3f7369c69b", EventSequenceNumber: 3,
+			Sender: sender, Payee: payee, Amount: 1, Currency: "XDX", MetadataKind: "travel_rule_metadata",
+			OffChainReferenceID: &offChain,
+		},
+		{
+			Version: 13, Timestamp: time.Unix(1600000003, 0), Type: txnexport.RecordTypeReceived,
+			EventKey: "0000000000000000f72589b71ff4f8d139674a3f7369c69b", EventSequenceNumber: 4,
+			Sender: payee, Payee: sender, Amount: 2, Currency: "XUS", MetadataKind: "travel_rule_metadata",
+			OffChainReferenceID: &empty,
+		},
+	}
+}
+
+// readParquet reads the Parquet file written by `ParquetWriter`, and returns text values of
+// each column, same with `Record#Values`, and whether each value is null.
+func readParquet(t *testing.T, file []byte) ([][]string, [][]bool) {
+	require.True(t, len(file) >= 12)
+	require.Equal(t, "PAR1", string(file[:4]))
+	require.Equal(t, "PAR1", string(file[len(file)-4:]))
+	length := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	metadata := (&thriftReader{buf: file[len(file)-8-length : len(file)-8]}).readStruct()
+
+	schema := metadata[2].([]interface{})
+	require.Len(t, schema, len(txnexport.Columns)+1)
+	for i, column := range txnexport.Columns {
+		element := schema[i+1].(map[int16]interface{})
+		assert.Equal(t, column.Name, string(element[4].([]byte)))
+	}
+	ret := make([][]string, len(txnexport.Columns))
+	nulls := make([][]bool, len(txnexport.Columns))
+	var numRows int64
+	for _, group := range metadata[4].([]interface{}) {
+		group := group.(map[int16]interface{})
+		numRows += group[3].(int64)
+		for i, chunk := range group[1].([]interface{}) {
+			meta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			offset := meta[9].(int64)
+			r := &thriftReader{buf: file[offset:]}
+			header := r.readStruct()
+			numValues := int(header[5].(map[int16]interface{})[1].(int64))
+			data := r.buf[r.pos : r.pos+int(header[2].(int64))]
+			values, isNull := decodeParquetValues(t, txnexport.Columns[i], numValues, data)
+			ret[i] = append(ret[i], values...)
+			nulls[i] = append(nulls[i], isNull...)
+		}
+	}
+	assert.Equal(t, metadata[3].(int64), numRows)
+	return ret, nulls
+}
+
+func decodeParquetValues(t *testing.T, column txnexport.Column, numValues int, data []byte) ([]string, []bool) {
+	levels := make([]byte, numValues)
+	for i := range levels {
+		levels[i] = 1
+	}
+	if column.Optional {
+		length := binary.LittleEndian.Uint32(data)
+		runs := bytes.NewReader(data[4 : 4+length])
+		for i := 0; i < numValues; {
+			header, err := binary.ReadUvarint(runs)
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), header&1, "rle run")
+			value, err := runs.ReadByte()
+			require.NoError(t, err)
+			for j := 0; j < int(header>>1); j++ {
+				levels[i] = value
+				i++
+			}
+		}
+		data = data[4+length:]
+	}
+	ret := make([]string, numValues)
+	nulls := make([]bool, numValues)
+	for i := range ret {
+		if levels[i] == 0 {
+			nulls[i] = true
+			continue
+		}
+		switch column.Type {
+		case txnexport.ColumnTypeUint64:
+			ret[i] = strconv.FormatUint(binary.LittleEndian.Uint64(data), 10)
+			data = data[8:]
+		case txnexport.ColumnTypeTimestamp:
+			micros := int64(binary.LittleEndian.Uint64(data))
+			ret[i] = time.Unix(0, micros*int64(time.Microsecond)).UTC().Format(time.RFC3339Nano)
+			data = data[8:]
+		default:
+			length := binary.LittleEndian.Uint32(data)
+			ret[i] = string(data[4 : 4+length])
+			data = data[4+length:]
+		}
+	}
+	assert.Empty(t, data)
+	return ret, nulls
+}
+
+// thriftReader decodes thrift compact protocol structs into maps of field id to values:
+// int64 for integers, []byte for binary, []interface{} for lists and maps for structs.
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	ret := make(map[int16]interface{})
+	var id int16
+	for {
+		b := r.buf[r.pos]
+		r.pos++
+		if b == 0 {
+			return ret
+		}
+		if delta := int16(b >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.readInt())
+		}
+		ret[id] = r.readValue(b & 0x0f)
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) interface{} {
+	switch typ {
+	case 5, 6:
+		return r.readInt()
+	case 8:
+		n := int(r.readUvarint())
+		r.pos += n
+		return r.buf[r.pos-n : r.pos]
+	case 9:
+		b := r.buf[r.pos]
+		r.pos++
+		size := int(b >> 4)
+		if size == 15 {
+			size = int(r.readUvarint())
+		}
+		ret := make([]interface{}, size)
+		for i := range ret {
+			ret[i] = r.readValue(b & 0x0f)
+		}
+		return ret
+	case 12:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %d", typ))
+}
+
+func (r *thriftReader) readUvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) readInt() int64 {
+	v := r.readUvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
@@ -0,0 +1,148 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnexport
+
+import (
+	"encoding"
+	"strconv"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/txnmetadata"
+)
+
+// ColumnType is type of column values
+type ColumnType string
+
+// Column types
+const (
+	// ColumnTypeUint64 is unsigned 64 bits integer
+	ColumnTypeUint64 ColumnType = "uint64"
+	// ColumnTypeString is UTF-8 string
+	ColumnTypeString ColumnType = "string"
+	// ColumnTypeTimestamp is microseconds since unix epoch in UTC, formatted as RFC 3339 in
+	// text formats
+	ColumnTypeTimestamp ColumnType = "timestamp"
+)
+
+// Record types, same with the payment event data types
+const (
+	RecordTypeSent     = "sentpayment"
+	RecordTypeReceived = "receivedpayment"
+)
+
+// MetadataKindInvalid is metadata kind of records with metadata can't be decoded
+const MetadataKindInvalid txnmetadata.MetadataKind = "invalid"
+
+// Column is a column of the export schema
+type Column struct {
+	Name string
+	Type ColumnType
+	// Optional column values are empty (null) if the metadata does not have the value
+	Optional bool
+}
+
+// Columns is the stable schema of exported records; new columns are only appended.
+var Columns = []Column{
+	{Name: "version", Type: ColumnTypeUint64},
+	{Name: "timestamp", Type: ColumnTypeTimestamp},
+	{Name: "type", Type: ColumnTypeString},
+	{Name: "event_key", Type: ColumnTypeString},
+	{Name: "event_sequence_number", Type: ColumnTypeUint64},
+	{Name: "sender", Type: ColumnTypeString},
+	{Name: "payee", Type: ColumnTypeString},
+	{Name: "amount", Type: ColumnTypeUint64},
+	{Name: "currency", Type: ColumnTypeString},
+	{Name: "metadata_kind", Type: ColumnTypeString},
+	{Name: "from_subaddress", Type: ColumnTypeString, Optional: true},
+	{Name: "to_subaddress", Type: ColumnTypeString, Optional: true},
+	{Name: "referenced_event", Type: ColumnTypeUint64, Optional: true},
+	{Name: "refund_transaction_version", Type: ColumnTypeUint64, Optional: true},
+	{Name: "refund_reason", Type: ColumnTypeString, Optional: true},
+	{Name: "reference_id", Type: ColumnTypeString, Optional: true},
+	{Name: "off_chain_reference_id", Type: ColumnTypeString, Optional: true},
+}
+
+// Record is an exported payment of the account
+type Record struct {
+	Version   uint64
+	Timestamp time.Time
+	// Type is `RecordTypeSent` or `RecordTypeReceived`
+	Type                string
+	EventKey            string
+	EventSequenceNumber uint64
+	Sender              diemtypes.AccountAddress
+	Payee               diemtypes.AccountAddress
+	// Amount is micro-units of the currency
+	Amount   uint64
+	Currency string
+	// MetadataKind is `MetadataKindInvalid` if the metadata can't be decoded, and other
+	// metadata fields are nil
+	MetadataKind txnmetadata.MetadataKind
+	// FromSubAddress, ToSubAddress and ReferencedEvent (refund reference) are from general
+	// metadata
+	FromSubAddress  *diemtypes.SubAddress
+	ToSubAddress    *diemtypes.SubAddress
+	ReferencedEvent *uint64
+	// RefundTransactionVersion and RefundReason are from refund metadata
+	RefundTransactionVersion *uint64
+	RefundReason             diemtypes.RefundReason
+	// ReferenceID is from payment metadata
+	ReferenceID *txnmetadata.ReferenceID
+	// OffChainReferenceID is from travel rule metadata
+	OffChainReferenceID *string
+}
+
+// Values returns text values of the record in the order of `Columns`, optional values are
+// empty strings if not set.
+func (r *Record) Values() []string {
+	ret, _ := r.values()
+	return ret
+}
+
+// values returns text values of the record, and whether each value is set; values of required
+// columns are always set, and set optional values can be empty strings.
+func (r *Record) values() ([]string, []bool) {
+	ret := []string{
+		strconv.FormatUint(r.Version, 10),
+		r.Timestamp.UTC().Format(time.RFC3339Nano),
+		r.Type,
+		r.EventKey,
+		strconv.FormatUint(r.EventSequenceNumber, 10),
+		r.Sender.Hex(),
+		r.Payee.Hex(),
+		strconv.FormatUint(r.Amount, 10),
+		r.Currency,
+		string(r.MetadataKind),
+		"", "", "", "", "", "", "",
+	}
+	set := make([]bool, len(ret))
+	for i, column := range Columns {
+		set[i] = !column.Optional
+	}
+	if r.FromSubAddress != nil {
+		ret[10], set[10] = r.FromSubAddress.Hex(), true
+	}
+	if r.ToSubAddress != nil {
+		ret[11], set[11] = r.ToSubAddress.Hex(), true
+	}
+	if r.ReferencedEvent != nil {
+		ret[12], set[12] = strconv.FormatUint(*r.ReferencedEvent, 10), true
+	}
+	if r.RefundTransactionVersion != nil {
+		ret[13], set[13] = strconv.FormatUint(*r.RefundTransactionVersion, 10), true
+	}
+	if reason, ok := r.RefundReason.(encoding.TextMarshaler); ok {
+		if text, err := reason.MarshalText(); err == nil {
+			ret[14], set[14] = string(text), true
+		}
+	}
+	if r.ReferenceID != nil {
+		ret[15], set[15] = r.ReferenceID.String(), true
+	}
+	if r.OffChainReferenceID != nil {
+		ret[16], set[16] = *r.OffChainReferenceID, true
+	}
+	return ret, set
+}
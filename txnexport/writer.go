@@ -0,0 +1,59 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnexport
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Writer writes records into a file format; other formats than `CSVWriter` and `ParquetWriter`
+// are written by implementing it with the format library of choice, `Columns` defines the
+// column types of the file schema.
+type Writer interface {
+	Write(*Record) error
+	// Flush writes buffered records, it is called after all records are written by `Export`
+	Flush() error
+}
+
+// CSVWriter writes records as CSV rows, with a header row of `Columns` names before the first
+// record.
+type CSVWriter struct {
+	csv         *csv.Writer
+	wroteHeader bool
+}
+
+// NewCSVWriter creates `CSVWriter` writing to w
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{csv: csv.NewWriter(w)}
+}
+
+// Write implements `Writer`
+func (w *CSVWriter) Write(record *Record) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	return w.csv.Write(record.Values())
+}
+
+// Flush implements `Writer`, it writes the header row if no record is written.
+func (w *CSVWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *CSVWriter) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+	header := make([]string, len(Columns))
+	for i, column := range Columns {
+		header[i] = column.Name
+	}
+	return w.csv.Write(header)
+}
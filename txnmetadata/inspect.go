@@ -0,0 +1,129 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnmetadata
+
+import (
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+)
+
+// MetadataKind is kind of peer to peer transaction metadata
+type MetadataKind string
+
+// List of metadata kinds
+const (
+	// MetadataKindNone is for transaction without metadata
+	MetadataKindNone              MetadataKind = "none"
+	MetadataKindUndefined         MetadataKind = "undefined"
+	MetadataKindGeneral           MetadataKind = "general"
+	MetadataKindTravelRule        MetadataKind = "travel_rule"
+	MetadataKindUnstructuredBytes MetadataKind = "unstructured_bytes"
+	MetadataKindRefund            MetadataKind = "refund"
+	MetadataKindCoinTrade         MetadataKind = "coin_trade"
+	MetadataKindPayment           MetadataKind = "payment"
+)
+
+// Inspection is normalized view of peer to peer transaction metadata, regardless of the
+// metadata kind and version. Fields not carried by the metadata are nil.
+type Inspection struct {
+	Kind MetadataKind
+	// FromSubAddress and ToSubAddress are sub-addresses of general metadata
+	FromSubAddress *diemtypes.SubAddress
+	ToSubAddress   *diemtypes.SubAddress
+	// ReferencedEvent is the referenced event sequence number of general metadata for refund
+	ReferencedEvent *uint64
+	// ReferenceID is reference id of payment metadata
+	ReferenceID *ReferenceID
+	// OffChainReferenceID is off-chain reference id of travel rule metadata
+	OffChainReferenceID *string
+	// RefundTransactionVersion and RefundReason are from refund metadata
+	RefundTransactionVersion *uint64
+	RefundReason             diemtypes.RefundReason
+	// Metadata is the decoded metadata, nil for `MetadataKindNone`
+	Metadata diemtypes.Metadata
+}
+
+// Inspect decodes given BCS-encoded metadata bytes into `Inspection`, so that wallet
+// reconciliation code doesn't need to type switch over all metadata variants.
+// Empty bytes is `MetadataKindNone`.
+// Returns error if the bytes can't be deserialized, or the metadata version is unknown.
+func Inspect(bytes []byte) (*Inspection, error) {
+	if len(bytes) == 0 {
+		return &Inspection{Kind: MetadataKindNone}, nil
+	}
+	metadata, err := diemtypes.DeserializeMetadata(bcs.NewDeserializer(bytes))
+	if err != nil {
+		return nil, fmt.Errorf("can't deserialize metadata: %v", err)
+	}
+	return InspectMetadata(metadata)
+}
+
+// InspectMetadata creates `Inspection` of given decoded metadata, e.g. returned by
+// `DeserializeMetadata`.
+func InspectMetadata(metadata diemtypes.Metadata) (*Inspection, error) {
+	ret := &Inspection{Metadata: metadata}
+	switch m := metadata.(type) {
+	case nil:
+		ret.Kind = MetadataKindNone
+	case *diemtypes.Metadata__Undefined:
+		ret.Kind = MetadataKindUndefined
+	case *diemtypes.Metadata__GeneralMetadata:
+		ret.Kind = MetadataKindGeneral
+		v0, ok := m.Value.(*diemtypes.GeneralMetadata__GeneralMetadataVersion0)
+		if !ok {
+			return nil, fmt.Errorf("can't handle GeneralMetadata: %T", m.Value)
+		}
+		var err error
+		if ret.FromSubAddress, err = subAddress(v0.Value.FromSubaddress); err != nil {
+			return nil, fmt.Errorf("invalid from sub-address: %v", err)
+		}
+		if ret.ToSubAddress, err = subAddress(v0.Value.ToSubaddress); err != nil {
+			return nil, fmt.Errorf("invalid to sub-address: %v", err)
+		}
+		ret.ReferencedEvent = v0.Value.ReferencedEvent
+	case *diemtypes.Metadata__TravelRuleMetadata:
+		ret.Kind = MetadataKindTravelRule
+		v0, ok := m.Value.(*diemtypes.TravelRuleMetadata__TravelRuleMetadataVersion0)
+		if !ok {
+			return nil, fmt.Errorf("can't handle TravelRuleMetadata: %T", m.Value)
+		}
+		ret.OffChainReferenceID = v0.Value.OffChainReferenceId
+	case *diemtypes.Metadata__UnstructuredBytesMetadata:
+		ret.Kind = MetadataKindUnstructuredBytes
+	case *diemtypes.Metadata__RefundMetadata:
+		ret.Kind = MetadataKindRefund
+		v0, ok := m.Value.(*diemtypes.RefundMetadata__RefundMetadataV0)
+		if !ok {
+			return nil, fmt.Errorf("can't handle RefundMetadata: %T", m.Value)
+		}
+		version := v0.Value.TransactionVersion
+		ret.RefundTransactionVersion = &version
+		ret.RefundReason = v0.Value.Reason
+	case *diemtypes.Metadata__CoinTradeMetadata:
+		ret.Kind = MetadataKindCoinTrade
+	case *diemtypes.Metadata__PaymentMetadata:
+		ret.Kind = MetadataKindPayment
+		referenceID, err := GetPaymentReferenceID(m)
+		if err != nil {
+			return nil, err
+		}
+		ret.ReferenceID = &referenceID
+	default:
+		return nil, fmt.Errorf("can't handle metadata: %T", metadata)
+	}
+	return ret, nil
+}
+
+func subAddress(bytes *[]byte) (*diemtypes.SubAddress, error) {
+	if bytes == nil {
+		return nil, nil
+	}
+	ret, err := diemtypes.MakeSubAddressFromBytes(*bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
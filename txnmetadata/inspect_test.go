@@ -0,0 +1,111 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnmetadata_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	from, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
+	to, _ := diemtypes.MakeSubAddress("111111153010a111")
+	address := diemtypes.MustMakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	referenceID, _ := txnmetadata.ParseReferenceID("5b8403c9-86f5-4e6e-9d8e-62f4b9e4f1b2")
+	refundReason := &diemtypes.RefundReason__UserInitiatedFullRefund{}
+	offChainReferenceID := "ref id"
+	referencedEvent := uint64(12)
+	refundVersion := uint64(1234)
+	travelRule, _ := txnmetadata.NewTravelRuleMetadata(offChainReferenceID, address, 100)
+	unstructured, _ := txnmetadata.NewUnstructuredBytesMetadata([]byte("abc"))
+	refundGeneral, _ := txnmetadata.NewRefundMetadataFromEventMetadata(referencedEvent, &diemtypes.Metadata__GeneralMetadata{
+		Value: &diemtypes.GeneralMetadata__GeneralMetadataVersion0{},
+	})
+
+	cases := []struct {
+		name     string
+		bytes    []byte
+		expected txnmetadata.Inspection
+	}{
+		{
+			name:     "none",
+			bytes:    nil,
+			expected: txnmetadata.Inspection{Kind: txnmetadata.MetadataKindNone},
+		},
+		{
+			name:     "undefined",
+			bytes:    []byte{0},
+			expected: txnmetadata.Inspection{Kind: txnmetadata.MetadataKindUndefined},
+		},
+		{
+			name:  "general metadata with from and to sub-addresses",
+			bytes: txnmetadata.NewGeneralMetadataWithFromToSubAddresses(from, to),
+			expected: txnmetadata.Inspection{
+				Kind:           txnmetadata.MetadataKindGeneral,
+				FromSubAddress: &from,
+				ToSubAddress:   &to,
+			},
+		},
+		{
+			name:  "general metadata refund",
+			bytes: refundGeneral,
+			expected: txnmetadata.Inspection{
+				Kind:            txnmetadata.MetadataKindGeneral,
+				ReferencedEvent: &referencedEvent,
+			},
+		},
+		{
+			name:  "travel rule metadata",
+			bytes: travelRule,
+			expected: txnmetadata.Inspection{
+				Kind:                txnmetadata.MetadataKindTravelRule,
+				OffChainReferenceID: &offChainReferenceID,
+			},
+		},
+		{
+			name:     "unstructured bytes metadata",
+			bytes:    unstructured,
+			expected: txnmetadata.Inspection{Kind: txnmetadata.MetadataKindUnstructuredBytes},
+		},
+		{
+			name:  "refund metadata",
+			bytes: txnmetadata.NewRefundMetadata(refundVersion, refundReason),
+			expected: txnmetadata.Inspection{
+				Kind:                     txnmetadata.MetadataKindRefund,
+				RefundTransactionVersion: &refundVersion,
+				RefundReason:             refundReason,
+			},
+		},
+		{
+			name:     "coin trade metadata",
+			bytes:    txnmetadata.NewCoinTradeMetadata([]string{"abc"}),
+			expected: txnmetadata.Inspection{Kind: txnmetadata.MetadataKindCoinTrade},
+		},
+		{
+			name:  "payment metadata",
+			bytes: txnmetadata.NewPaymentMetadata(referenceID),
+			expected: txnmetadata.Inspection{
+				Kind:        txnmetadata.MetadataKindPayment,
+				ReferenceID: &referenceID,
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := txnmetadata.Inspect(tc.bytes)
+			require.NoError(t, err)
+			ret.Metadata = nil
+			assert.Equal(t, &tc.expected, ret)
+		})
+	}
+
+	t.Run("invalid bytes", func(t *testing.T) {
+		_, err := txnmetadata.Inspect([]byte{1})
+		assert.Error(t, err)
+	})
+}
@@ -4,15 +4,32 @@
 package txnmetadata
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/diem/client-sdk-go/diemclient"
 	"github.com/diem/client-sdk-go/diemtypes"
 	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
 )
 
+// DualAttestationDomainSeparator is appended to the travel rule metadata signing message
+const DualAttestationDomainSeparator = "@@$$DIEM_ATTEST$$@@"
+
+// MaxMetadataSize is the max size of BCS-encoded metadata bytes: Diem VM rejects transactions
+// larger than 4096 bytes, hence a peer to peer transaction with metadata exceeding the size can
+// never be executed.
+const MaxMetadataSize = 4096
+
+// ErrMetadataTooLarge is returned when metadata size exceeds `MaxMetadataSize`
+var ErrMetadataTooLarge = errors.New("metadata is too large")
+
+// ErrInvalidMetadataSignature is returned when travel rule metadata signature verification failed
+var ErrInvalidMetadataSignature = errors.New("invalid travel rule metadata signature")
+
 // NewTravelRuleMetadata creates metadata and signature message for given
 // offChainReferenceID.
 // This is used for peer to peer transfer between 2 custodial accounts.
@@ -29,13 +46,47 @@ func NewTravelRuleMetadata(
 		},
 	}
 
+	bytes := diemtypes.ToBCS(&metadata)
+	return bytes, NewTravelRuleSigningMessage(bytes, senderAccountAddress, amount)
+}
+
+// NewTravelRuleSigningMessage creates dual attestation signature message of given BCS-encoded
+// travel rule metadata: metadata, sender account address, amount and the
+// `DualAttestationDomainSeparator`.
+// The payee VASP signs the message by its compliance key, and the signature is verified by
+// the on-chain payee VASP compliance public key.
+func NewTravelRuleSigningMessage(
+	metadata []byte,
+	senderAccountAddress diemtypes.AccountAddress,
+	amount uint64,
+) []byte {
 	s := bcs.NewSerializer()
-	metadata.Serialize(s)
 	senderAccountAddress.Serialize(s)
 	s.SerializeU64(amount)
-	sigMsg := append(s.GetBytes(), []byte("@@$$DIEM_ATTEST$$@@")...)
+	ret := append(append([]byte(nil), metadata...), s.GetBytes()...)
+	return append(ret, []byte(DualAttestationDomainSeparator)...)
+}
 
-	return diemtypes.ToBCS(&metadata), sigMsg
+// VerifyTravelRuleMetadataSignature verifies the dual attestation signature of given BCS-encoded
+// travel rule metadata, payer account address and amount is signed by the payee compliance key.
+// Receivers can validate the dual attestation before accepting the payment.
+// Returns `ErrInvalidMetadataSignature` if the signature is invalid, or error if the compliance
+// public key is invalid.
+func VerifyTravelRuleMetadataSignature(
+	metadata []byte,
+	payerAccountAddress diemtypes.AccountAddress,
+	amount uint64,
+	signature []byte,
+	compliancePublicKey ed25519.PublicKey,
+) error {
+	if len(compliancePublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid compliance public key length: %d", len(compliancePublicKey))
+	}
+	sigMsg := NewTravelRuleSigningMessage(metadata, payerAccountAddress, amount)
+	if !ed25519.Verify(compliancePublicKey, sigMsg, signature) {
+		return ErrInvalidMetadataSignature
+	}
+	return nil
 }
 
 // NewGeneralMetadataToSubAddress creates metadata for creating peer to peer
@@ -104,6 +155,132 @@ func NewCoinTradeMetadata(tradeIds []string) []byte {
 	return diemtypes.ToBCS(&metadata)
 }
 
+// ReferenceID is 16 bytes payment reference id of `PaymentMetadata`, formatted as UUID string
+type ReferenceID [16]byte
+
+// NewReferenceID generates random (version 4) UUID reference id
+func NewReferenceID() (ReferenceID, error) {
+	var ret ReferenceID
+	if _, err := rand.Read(ret[:]); err != nil {
+		return ret, err
+	}
+	ret[6] = ret[6]&0x0f | 0x40
+	ret[8] = ret[8]&0x3f | 0x80
+	return ret, nil
+}
+
+// ParseReferenceID parses UUID string, e.g. "5b8403c9-86f5-4e6e-9d8e-62f4b9e4f1b2", or 32
+// hex-encoded characters into `ReferenceID`
+func ParseReferenceID(id string) (ReferenceID, error) {
+	var ret ReferenceID
+	bytes, err := hex.DecodeString(strings.ReplaceAll(id, "-", ""))
+	if err != nil {
+		return ret, fmt.Errorf("invalid reference id %#v: %v", id, err)
+	}
+	if len(bytes) != len(ret) {
+		return ret, fmt.Errorf("invalid reference id %#v: expected 16 bytes, got %d", id, len(bytes))
+	}
+	copy(ret[:], bytes)
+	return ret, nil
+}
+
+// String returns UUID string of the reference id
+func (id ReferenceID) String() string {
+	h := hex.EncodeToString(id[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// NewPaymentMetadata creates metadata for creating peer to peer transaction script with
+// payment reference id.
+// The reference id is exchanged off-chain by sender and receiver before the payment.
+func NewPaymentMetadata(referenceID ReferenceID) []byte {
+	metadata := diemtypes.Metadata__PaymentMetadata{
+		Value: &diemtypes.PaymentMetadata__PaymentMetadataVersion0{
+			Value: diemtypes.PaymentMetadataV0{
+				ReferenceId: referenceID,
+			},
+		},
+	}
+	return diemtypes.ToBCS(&metadata)
+}
+
+// DeserializePaymentMetadata decodes BCS-encoded metadata bytes as payment metadata, and
+// returns its reference id.
+// Returns error if the metadata is not `PaymentMetadata`.
+func DeserializePaymentMetadata(metadata []byte) (ReferenceID, error) {
+	ret, err := diemtypes.DeserializeMetadata(bcs.NewDeserializer(metadata))
+	if err != nil {
+		return ReferenceID{}, fmt.Errorf("can't deserialize metadata: %v", err)
+	}
+	return GetPaymentReferenceID(ret)
+}
+
+// GetPaymentReferenceID returns reference id of given payment metadata, e.g. returned by
+// `DeserializeMetadata`.
+// Returns error if the metadata is not `PaymentMetadata` version 0.
+func GetPaymentReferenceID(metadata diemtypes.Metadata) (ReferenceID, error) {
+	pm, ok := metadata.(*diemtypes.Metadata__PaymentMetadata)
+	if !ok {
+		return ReferenceID{}, fmt.Errorf("not payment metadata: %T", metadata)
+	}
+	v0, ok := pm.Value.(*diemtypes.PaymentMetadata__PaymentMetadataVersion0)
+	if !ok {
+		return ReferenceID{}, fmt.Errorf("can't handle PaymentMetadata: %T", pm.Value)
+	}
+	return v0.Value.ReferenceId, nil
+}
+
+// NewUnstructuredBytesMetadata creates metadata for creating peer to peer transaction script with
+// opaque bytes, nil bytes is encoded as none.
+// Returns error wraps `ErrMetadataTooLarge` if the metadata size exceeds `MaxMetadataSize`.
+func NewUnstructuredBytesMetadata(bytes []byte) ([]byte, error) {
+	metadata := diemtypes.Metadata__UnstructuredBytesMetadata{
+		Value: diemtypes.UnstructuredBytesMetadata{},
+	}
+	if bytes != nil {
+		metadata.Value.Metadata = &bytes
+	}
+	ret := diemtypes.ToBCS(&metadata)
+	if err := ValidateMetadataSize(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// DeserializeUnstructuredBytesMetadata decodes BCS-encoded metadata bytes as unstructured bytes
+// metadata, and returns the opaque bytes, nil if none.
+// Returns error if the metadata is not `UnstructuredBytesMetadata`.
+func DeserializeUnstructuredBytesMetadata(metadata []byte) ([]byte, error) {
+	ret, err := diemtypes.DeserializeMetadata(bcs.NewDeserializer(metadata))
+	if err != nil {
+		return nil, fmt.Errorf("can't deserialize metadata: %v", err)
+	}
+	return GetUnstructuredBytes(ret)
+}
+
+// GetUnstructuredBytes returns opaque bytes of given unstructured bytes metadata, e.g. returned by
+// `DeserializeMetadata`, nil if none.
+// Returns error if the metadata is not `UnstructuredBytesMetadata`.
+func GetUnstructuredBytes(metadata diemtypes.Metadata) ([]byte, error) {
+	ubm, ok := metadata.(*diemtypes.Metadata__UnstructuredBytesMetadata)
+	if !ok {
+		return nil, fmt.Errorf("not unstructured bytes metadata: %T", metadata)
+	}
+	if ubm.Value.Metadata == nil {
+		return nil, nil
+	}
+	return *ubm.Value.Metadata, nil
+}
+
+// ValidateMetadataSize returns error wraps `ErrMetadataTooLarge` if given BCS-encoded metadata size
+// exceeds `MaxMetadataSize`
+func ValidateMetadataSize(metadata []byte) error {
+	if len(metadata) > MaxMetadataSize {
+		return fmt.Errorf("%w: %d bytes, max %d bytes", ErrMetadataTooLarge, len(metadata), MaxMetadataSize)
+	}
+	return nil
+}
+
 // FindRefundReferenceEventFromTransaction looks for receivedpayment type event in the
 // given transaction and event receiver is given receiver account address.
 func FindRefundReferenceEventFromTransaction(txn *diemclient.Transaction, receiver diemtypes.AccountAddress) *diemclient.Event {
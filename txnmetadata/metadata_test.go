@@ -4,7 +4,9 @@
 package txnmetadata_test
 
 import (
+	"crypto/ed25519"
 	"encoding/hex"
+	"errors"
 	"testing"
 
 	"github.com/diem/client-sdk-go/diemclient"
@@ -26,6 +28,36 @@ func TestNewTravelRuleMetadata(t *testing.T) {
 	assert.Equal(t, "020001166f666620636861696e207265666572656e6365206964f72589b71ff4f8d139674a3f7369c69be803000000000000404024244449454d5f41545445535424244040", hex.EncodeToString(sigMsg))
 }
 
+func TestVerifyTravelRuleMetadataSignature(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	publicKey := ed25519.PublicKey(keys.PublicKey.Bytes())
+	address, _ := diemtypes.MakeAccountAddress("f72589b71ff4f8d139674a3f7369c69b")
+	metadata, sigMsg := txnmetadata.NewTravelRuleMetadata("off chain reference id", address, 1000)
+	signature := keys.PrivateKey.Sign(sigMsg)
+
+	t.Run("valid", func(t *testing.T) {
+		err := txnmetadata.VerifyTravelRuleMetadataSignature(metadata, address, 1000, signature, publicKey)
+		assert.NoError(t, err)
+	})
+	t.Run("amount mismatch", func(t *testing.T) {
+		err := txnmetadata.VerifyTravelRuleMetadataSignature(metadata, address, 1001, signature, publicKey)
+		assert.Equal(t, txnmetadata.ErrInvalidMetadataSignature, err)
+	})
+	t.Run("payer mismatch", func(t *testing.T) {
+		err := txnmetadata.VerifyTravelRuleMetadataSignature(metadata, keys.AccountAddress(), 1000, signature, publicKey)
+		assert.Equal(t, txnmetadata.ErrInvalidMetadataSignature, err)
+	})
+	t.Run("signed by other key", func(t *testing.T) {
+		other := diemkeys.MustGenKeys().PublicKey.Bytes()
+		err := txnmetadata.VerifyTravelRuleMetadataSignature(metadata, address, 1000, signature, other)
+		assert.Equal(t, txnmetadata.ErrInvalidMetadataSignature, err)
+	})
+	t.Run("invalid compliance public key", func(t *testing.T) {
+		err := txnmetadata.VerifyTravelRuleMetadataSignature(metadata, address, 1000, signature, publicKey[:31])
+		assert.EqualError(t, err, "invalid compliance public key length: 31")
+	})
+}
+
 func TestNewGeneralMetadataToSubAddress(t *testing.T) {
 	subAddress, _ := diemtypes.MakeSubAddress("8f8b82153010a1bd")
 	ret := txnmetadata.NewGeneralMetadataToSubAddress(subAddress)
@@ -110,6 +142,78 @@ func TestCoinTradeMetadata(t *testing.T) {
 	assert.Equal(t, md.TradeIds, tradeIds)
 }
 
+func TestPaymentMetadata(t *testing.T) {
+	referenceID, err := txnmetadata.ParseReferenceID("5b8403c9-86f5-4e6e-9d8e-62f4b9e4f1b2")
+	require.NoError(t, err)
+	assert.Equal(t, "5b8403c9-86f5-4e6e-9d8e-62f4b9e4f1b2", referenceID.String())
+
+	ret := txnmetadata.NewPaymentMetadata(referenceID)
+	assert.Equal(t, "06005b8403c986f54e6e9d8e62f4b9e4f1b2", hex.EncodeToString(ret))
+
+	decoded, err := txnmetadata.DeserializePaymentMetadata(ret)
+	require.NoError(t, err)
+	assert.Equal(t, referenceID, decoded)
+
+	t.Run("not payment metadata", func(t *testing.T) {
+		_, err := txnmetadata.DeserializePaymentMetadata(txnmetadata.NewCoinTradeMetadata([]string{"abc"}))
+		assert.EqualError(t, err, "not payment metadata: *diemtypes.Metadata__CoinTradeMetadata")
+	})
+	t.Run("invalid metadata bytes", func(t *testing.T) {
+		_, err := txnmetadata.DeserializePaymentMetadata([]byte{6})
+		assert.Error(t, err)
+	})
+}
+
+func TestReferenceID(t *testing.T) {
+	id, err := txnmetadata.NewReferenceID()
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x40), id[6]&0xf0)
+
+	parsed, err := txnmetadata.ParseReferenceID(hex.EncodeToString(id[:]))
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+
+	_, err = txnmetadata.ParseReferenceID("invalid")
+	assert.Error(t, err)
+	_, err = txnmetadata.ParseReferenceID("5b8403c9")
+	assert.EqualError(t, err, `invalid reference id "5b8403c9": expected 16 bytes, got 4`)
+}
+
+func TestUnstructuredBytesMetadata(t *testing.T) {
+	ret, err := txnmetadata.NewUnstructuredBytesMetadata([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, "030103616263", hex.EncodeToString(ret))
+
+	bytes, err := txnmetadata.DeserializeUnstructuredBytesMetadata(ret)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("abc"), bytes)
+
+	t.Run("none", func(t *testing.T) {
+		ret, err := txnmetadata.NewUnstructuredBytesMetadata(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "0300", hex.EncodeToString(ret))
+
+		bytes, err := txnmetadata.DeserializeUnstructuredBytesMetadata(ret)
+		require.NoError(t, err)
+		assert.Nil(t, bytes)
+	})
+	t.Run("too large", func(t *testing.T) {
+		_, err := txnmetadata.NewUnstructuredBytesMetadata(make([]byte, txnmetadata.MaxMetadataSize))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, txnmetadata.ErrMetadataTooLarge))
+	})
+	t.Run("not unstructured bytes metadata", func(t *testing.T) {
+		_, err := txnmetadata.DeserializeUnstructuredBytesMetadata(txnmetadata.NewCoinTradeMetadata([]string{"abc"}))
+		assert.EqualError(t, err, "not unstructured bytes metadata: *diemtypes.Metadata__CoinTradeMetadata")
+	})
+}
+
+func TestValidateMetadataSize(t *testing.T) {
+	assert.NoError(t, txnmetadata.ValidateMetadataSize(make([]byte, txnmetadata.MaxMetadataSize)))
+	err := txnmetadata.ValidateMetadataSize(make([]byte, txnmetadata.MaxMetadataSize+1))
+	assert.EqualError(t, err, "metadata is too large: 4097 bytes, max 4096 bytes")
+}
+
 func TestNewRefundMetadataFromEvent(t *testing.T) {
 	referencedEventSeqNum := uint64(123)
 
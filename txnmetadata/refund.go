@@ -0,0 +1,87 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnmetadata
+
+import (
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+)
+
+// RefundReasonCode is string code of `diemtypes.RefundReason` variants, for storing or
+// configuring refund reasons without the generated types.
+type RefundReasonCode string
+
+// List of refund reason codes
+const (
+	RefundReasonOther                      RefundReasonCode = "other"
+	RefundReasonInvalidSubAddress          RefundReasonCode = "invalid_subaddress"
+	RefundReasonUserInitiatedPartialRefund RefundReasonCode = "user_initiated_partial_refund"
+	RefundReasonUserInitiatedFullRefund    RefundReasonCode = "user_initiated_full_refund"
+	RefundReasonInvalidReferenceID         RefundReasonCode = "invalid_reference_id"
+)
+
+// RefundReason returns `diemtypes.RefundReason` of the code, returns error for unknown code
+func (c RefundReasonCode) RefundReason() (diemtypes.RefundReason, error) {
+	switch c {
+	case RefundReasonOther:
+		return &diemtypes.RefundReason__OtherReason{}, nil
+	case RefundReasonInvalidSubAddress:
+		return &diemtypes.RefundReason__InvalidSubaddress{}, nil
+	case RefundReasonUserInitiatedPartialRefund:
+		return &diemtypes.RefundReason__UserInitiatedPartialRefund{}, nil
+	case RefundReasonUserInitiatedFullRefund:
+		return &diemtypes.RefundReason__UserInitiatedFullRefund{}, nil
+	case RefundReasonInvalidReferenceID:
+		return &diemtypes.RefundReason__InvalidReferenceId{}, nil
+	}
+	return nil, fmt.Errorf("unknown refund reason code: %#v", string(c))
+}
+
+// RefundReasonCodeOf returns code of given `diemtypes.RefundReason`, returns error for unknown
+// refund reason type.
+func RefundReasonCodeOf(reason diemtypes.RefundReason) (RefundReasonCode, error) {
+	switch reason.(type) {
+	case *diemtypes.RefundReason__OtherReason:
+		return RefundReasonOther, nil
+	case *diemtypes.RefundReason__InvalidSubaddress:
+		return RefundReasonInvalidSubAddress, nil
+	case *diemtypes.RefundReason__UserInitiatedPartialRefund:
+		return RefundReasonUserInitiatedPartialRefund, nil
+	case *diemtypes.RefundReason__UserInitiatedFullRefund:
+		return RefundReasonUserInitiatedFullRefund, nil
+	case *diemtypes.RefundReason__InvalidReferenceId:
+		return RefundReasonInvalidReferenceID, nil
+	}
+	return "", fmt.Errorf("unknown refund reason: %T", reason)
+}
+
+// NewRefundMetadataWithReasonCode creates refund metadata with original payment transaction
+// version and refund reason code, see `NewRefundMetadata`.
+func NewRefundMetadataWithReasonCode(originalTransactionVersion uint64, code RefundReasonCode) ([]byte, error) {
+	reason, err := code.RefundReason()
+	if err != nil {
+		return nil, err
+	}
+	return NewRefundMetadata(originalTransactionVersion, reason), nil
+}
+
+// DeserializeRefundMetadata decodes BCS-encoded metadata bytes as refund metadata.
+// Returns error if the metadata is not `RefundMetadata` version 0.
+func DeserializeRefundMetadata(metadata []byte) (*diemtypes.RefundMetadataV0, error) {
+	ret, err := diemtypes.DeserializeMetadata(bcs.NewDeserializer(metadata))
+	if err != nil {
+		return nil, fmt.Errorf("can't deserialize metadata: %v", err)
+	}
+	rm, ok := ret.(*diemtypes.Metadata__RefundMetadata)
+	if !ok {
+		return nil, fmt.Errorf("not refund metadata: %T", ret)
+	}
+	v0, ok := rm.Value.(*diemtypes.RefundMetadata__RefundMetadataV0)
+	if !ok {
+		return nil, fmt.Errorf("can't handle RefundMetadata: %T", rm.Value)
+	}
+	return &v0.Value, nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package txnmetadata_test
+
+import (
+	"testing"
+
+	"github.com/diem/client-sdk-go/txnmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefundReasonCode(t *testing.T) {
+	codes := []txnmetadata.RefundReasonCode{
+		txnmetadata.RefundReasonOther,
+		txnmetadata.RefundReasonInvalidSubAddress,
+		txnmetadata.RefundReasonUserInitiatedPartialRefund,
+		txnmetadata.RefundReasonUserInitiatedFullRefund,
+		txnmetadata.RefundReasonInvalidReferenceID,
+	}
+	for _, code := range codes {
+		t.Run(string(code), func(t *testing.T) {
+			reason, err := code.RefundReason()
+			require.NoError(t, err)
+			ret, err := txnmetadata.RefundReasonCodeOf(reason)
+			require.NoError(t, err)
+			assert.Equal(t, code, ret)
+
+			metadata, err := txnmetadata.NewRefundMetadataWithReasonCode(123, code)
+			require.NoError(t, err)
+			refund, err := txnmetadata.DeserializeRefundMetadata(metadata)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(123), refund.TransactionVersion)
+			assert.Equal(t, reason, refund.Reason)
+		})
+	}
+
+	t.Run("unknown code", func(t *testing.T) {
+		_, err := txnmetadata.RefundReasonCode("unknown").RefundReason()
+		assert.EqualError(t, err, `unknown refund reason code: "unknown"`)
+		_, err = txnmetadata.NewRefundMetadataWithReasonCode(123, "unknown")
+		assert.Error(t, err)
+	})
+	t.Run("unknown reason", func(t *testing.T) {
+		_, err := txnmetadata.RefundReasonCodeOf(nil)
+		assert.EqualError(t, err, "unknown refund reason: <nil>")
+	})
+}
+
+func TestDeserializeRefundMetadataError(t *testing.T) {
+	_, err := txnmetadata.DeserializeRefundMetadata(txnmetadata.NewCoinTradeMetadata([]string{"abc"}))
+	assert.EqualError(t, err, "not refund metadata: *diemtypes.Metadata__CoinTradeMetadata")
+	_, err = txnmetadata.DeserializeRefundMetadata([]byte{4})
+	assert.Error(t, err)
+}
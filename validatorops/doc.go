@@ -0,0 +1,15 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides validator lifecycle operations: creating validator and validator operator accounts,
+// setting validator operator, registering validator config, adding / removing validators and
+// reconfiguring the validator set; and Diem network address parsing, BCS encoding and decoding
+// for the validator config.
+//
+// Validator network addresses in the validator config are encrypted by the key derived from the
+// shared validator network address key and the validator account address, see
+// `EncryptNetworkAddresses`; full node network addresses are not encrypted.
+//
+// Operations sent by Diem Root account require sliding nonce, which is managed by
+// `tcops.SlidingNonceManager`.
+package validatorops
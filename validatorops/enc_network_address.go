@@ -0,0 +1,202 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package validatorops
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+)
+
+// NetworkAddressKeyLength is the length of the shared validator network address key
+const NetworkAddressKeyLength = 32
+
+// NetworkAddressKey is the shared validator network address key, validator network addresses
+// in validator config are encrypted by the key derived from it and the validator account address.
+type NetworkAddressKey [NetworkAddressKeyLength]byte
+
+// hkdfSalt is sha3-256 of "DIEM_ENCRYPTED_NETWORK_ADDRESS_SALT", same with Diem `HKDF_SALT`
+var hkdfSalt = func() []byte {
+	ret := sha3.Sum256([]byte("DIEM_ENCRYPTED_NETWORK_ADDRESS_SALT"))
+	return ret[:]
+}()
+
+// EncNetworkAddress is encrypted `NetworkAddress`, same with Diem `EncNetworkAddress`.
+// The `EncAddr` is AES-256-GCM ciphertext of the network address BCS bytes followed by the tag,
+// the nonce is `SeqNum` and the index of the address in the addresses list, and the additional
+// authenticated data is big endian bytes of `KeyVersion`.
+type EncNetworkAddress struct {
+	KeyVersion uint32
+	SeqNum     uint64
+	EncAddr    []byte
+}
+
+// EncryptNetworkAddress encrypts the network address of the validator account by the shared
+// validator network address key.
+// The `seqNum` and `addrIdx` make the nonce, they must not be reused with the same key and
+// account, Diem uses the sequence number of the validator operator account sending the config
+// transaction as `seqNum`, and the index of the address in the addresses list as `addrIdx`.
+func EncryptNetworkAddress(
+	address NetworkAddress, key NetworkAddressKey, keyVersion uint32,
+	account diemtypes.AccountAddress, seqNum uint64, addrIdx uint32,
+) (*EncNetworkAddress, error) {
+	bytes, err := address.BcsSerialize()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newNetworkAddressAEAD(key, account)
+	if err != nil {
+		return nil, err
+	}
+	encAddr := aead.Seal(nil, networkAddressNonce(seqNum, addrIdx), bytes, keyVersionBytes(keyVersion))
+	return &EncNetworkAddress{KeyVersion: keyVersion, SeqNum: seqNum, EncAddr: encAddr}, nil
+}
+
+// Decrypt decrypts the network address of the validator account by the shared validator network
+// address key; `addrIdx` is the index of the address in the addresses list.
+func (a *EncNetworkAddress) Decrypt(key NetworkAddressKey, account diemtypes.AccountAddress, addrIdx uint32) (NetworkAddress, error) {
+	aead, err := newNetworkAddressAEAD(key, account)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := aead.Open(nil, networkAddressNonce(a.SeqNum, addrIdx), a.EncAddr, keyVersionBytes(a.KeyVersion))
+	if err != nil {
+		return nil, fmt.Errorf("%w: decrypt network address failed: %v", ErrInvalidNetworkAddress, err)
+	}
+	return BcsDeserializeNetworkAddress(bytes)
+}
+
+// EncryptNetworkAddresses encrypts the network addresses of the validator account by
+// `EncryptNetworkAddress` with their indexes, and returns BCS bytes of the encrypted addresses
+// list, which is `ValidatorConfig#ValidatorAddresses`.
+func EncryptNetworkAddresses(
+	key NetworkAddressKey, keyVersion uint32, account diemtypes.AccountAddress, seqNum uint64,
+	addresses ...NetworkAddress,
+) ([]byte, error) {
+	encrypted := make([]*EncNetworkAddress, len(addresses))
+	for i, address := range addresses {
+		enc, err := EncryptNetworkAddress(address, key, keyVersion, account, seqNum, uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		encrypted[i] = enc
+	}
+	return EncodeEncNetworkAddresses(encrypted...)
+}
+
+// DecryptNetworkAddresses decodes and decrypts BCS bytes of the encrypted network addresses
+// list, it is the reverse of `EncryptNetworkAddresses`.
+func DecryptNetworkAddresses(key NetworkAddressKey, account diemtypes.AccountAddress, bytes []byte) ([]NetworkAddress, error) {
+	encrypted, err := DecodeEncNetworkAddresses(bytes)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]NetworkAddress, len(encrypted))
+	for i, enc := range encrypted {
+		if ret[i], err = enc.Decrypt(key, account, uint32(i)); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// EncodeEncNetworkAddresses returns BCS bytes of the encrypted network addresses list
+func EncodeEncNetworkAddresses(addresses ...*EncNetworkAddress) ([]byte, error) {
+	s := bcs.NewSerializer()
+	if err := s.SerializeLen(uint64(len(addresses))); err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if err := address.serialize(s); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetBytes(), nil
+}
+
+// DecodeEncNetworkAddresses decodes BCS bytes of the encrypted network addresses list, it is
+// the reverse of `EncodeEncNetworkAddresses`.
+func DecodeEncNetworkAddresses(bytes []byte) ([]*EncNetworkAddress, error) {
+	d := bcs.NewDeserializer(bytes)
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNetworkAddress, err)
+	}
+	ret := make([]*EncNetworkAddress, 0, length)
+	for i := uint64(0); i < length; i++ {
+		address, err := deserializeEncNetworkAddress(d)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidNetworkAddress, err)
+		}
+		ret = append(ret, address)
+	}
+	if d.GetBufferOffset() != uint64(len(bytes)) {
+		return nil, fmt.Errorf("%w: some input bytes were not read", ErrInvalidNetworkAddress)
+	}
+	return ret, nil
+}
+
+func (a *EncNetworkAddress) serialize(s serde.Serializer) error {
+	if err := s.SerializeU32(a.KeyVersion); err != nil {
+		return err
+	}
+	if err := s.SerializeU64(a.SeqNum); err != nil {
+		return err
+	}
+	return s.SerializeBytes(a.EncAddr)
+}
+
+func deserializeEncNetworkAddress(d serde.Deserializer) (*EncNetworkAddress, error) {
+	var (
+		ret EncNetworkAddress
+		err error
+	)
+	if ret.KeyVersion, err = d.DeserializeU32(); err != nil {
+		return nil, err
+	}
+	if ret.SeqNum, err = d.DeserializeU64(); err != nil {
+		return nil, err
+	}
+	if ret.EncAddr, err = d.DeserializeBytes(); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// newNetworkAddressAEAD creates AES-256-GCM with the key derived from the shared validator
+// network address key and the account address by HKDF-SHA3-256.
+func newNetworkAddressAEAD(key NetworkAddressKey, account diemtypes.AccountAddress) (cipher.AEAD, error) {
+	derived := make([]byte, NetworkAddressKeyLength)
+	if _, err := io.ReadFull(hkdf.New(sha3.New256, key[:], hkdfSalt, account[:]), derived); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// networkAddressNonce returns 12 bytes nonce: big endian bytes of seqNum followed by big endian
+// bytes of addrIdx
+func networkAddressNonce(seqNum uint64, addrIdx uint32) []byte {
+	ret := make([]byte, 12)
+	binary.BigEndian.PutUint64(ret, seqNum)
+	binary.BigEndian.PutUint32(ret[8:], addrIdx)
+	return ret
+}
+
+func keyVersionBytes(keyVersion uint32) []byte {
+	ret := make([]byte, 4)
+	binary.BigEndian.PutUint32(ret, keyVersion)
+	return ret
+}
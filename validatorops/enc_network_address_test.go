@@ -0,0 +1,68 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package validatorops_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/validatorops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptNetworkAddresses(t *testing.T) {
+	var key validatorops.NetworkAddressKey
+	copy(key[:], "shared validator network address")
+	account := diemtypes.AccountAddress{1}
+	addresses := []validatorops.NetworkAddress{
+		validatorops.MustParseNetworkAddress("/ip4/10.0.0.1/tcp/6180/noise-ik/" + noiseKey + "/handshake/0"),
+		validatorops.MustParseNetworkAddress("/dns4/example.com/tcp/6180"),
+	}
+
+	bytes, err := validatorops.EncryptNetworkAddresses(key, 2, account, 7, addresses...)
+	require.NoError(t, err)
+	encrypted, err := validatorops.DecodeEncNetworkAddresses(bytes)
+	require.NoError(t, err)
+	require.Len(t, encrypted, 2)
+	for i, enc := range encrypted {
+		assert.Equal(t, uint32(2), enc.KeyVersion)
+		assert.Equal(t, uint64(7), enc.SeqNum)
+		plain, err := addresses[i].BcsSerialize()
+		require.NoError(t, err)
+		assert.Len(t, enc.EncAddr, len(plain)+16, "ciphertext followed by 16 bytes tag")
+		assert.NotContains(t, string(enc.EncAddr), "example.com")
+	}
+	assert.NotEqual(t, encrypted[0].EncAddr, encrypted[1].EncAddr)
+
+	decrypted, err := validatorops.DecryptNetworkAddresses(key, account, bytes)
+	require.NoError(t, err)
+	assert.Equal(t, addresses, decrypted)
+
+	t.Run("decrypt by other account", func(t *testing.T) {
+		_, err := validatorops.DecryptNetworkAddresses(key, diemtypes.AccountAddress{2}, bytes)
+		assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+	})
+	t.Run("decrypt by other key", func(t *testing.T) {
+		other := key
+		other[0]++
+		_, err := validatorops.DecryptNetworkAddresses(other, account, bytes)
+		assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+	})
+	t.Run("decrypt with other address index", func(t *testing.T) {
+		_, err := encrypted[1].Decrypt(key, account, 0)
+		assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+	})
+	t.Run("key version is authenticated", func(t *testing.T) {
+		enc := *encrypted[0]
+		enc.KeyVersion++
+		_, err := enc.Decrypt(key, account, 0)
+		assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+	})
+	t.Run("trailing bytes", func(t *testing.T) {
+		_, err := validatorops.DecodeEncNetworkAddresses(append(bytes, 0))
+		assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+	})
+}
@@ -0,0 +1,426 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package validatorops
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// ErrInvalidNetworkAddress is returned for parsing malformed network address, or encoding
+// network address with invalid protocol value
+var ErrInvalidNetworkAddress = errors.New("invalid network address")
+
+// Protocol is a component of `NetworkAddress`
+type Protocol interface {
+	// String returns the protocol in network address string format, e.g. "/tcp/6180"
+	String() string
+	serialize(s serde.Serializer) error
+}
+
+// Protocols of network address
+type (
+	// IP4 is IPv4 address protocol, e.g. "/ip4/127.0.0.1"
+	IP4 net.IP
+	// IP6 is IPv6 address protocol, e.g. "/ip6/::1"
+	IP6 net.IP
+	// DNS is DNS name protocol, e.g. "/dns/example.com"
+	DNS string
+	// DNS4 is DNS name protocol resolved to IPv4 address only, e.g. "/dns4/example.com"
+	DNS4 string
+	// DNS6 is DNS name protocol resolved to IPv6 address only, e.g. "/dns6/example.com"
+	DNS6 string
+	// TCP is TCP port protocol, e.g. "/tcp/6180"
+	TCP uint16
+	// Memory is in-memory transport port protocol for testing, e.g. "/memory/6180"
+	Memory uint16
+	// NoiseIK is x25519 public key of Noise IK handshake, e.g. "/noise-ik/<hex-encoded key>"
+	NoiseIK [32]byte
+	// Handshake is Diem network handshake protocol version, e.g. "/handshake/0"
+	Handshake uint8
+)
+
+// Protocol variant indexes of BCS encoding
+const (
+	ip4Variant = iota
+	ip6Variant
+	dnsVariant
+	dns4Variant
+	dns6Variant
+	tcpVariant
+	memoryVariant
+	noiseIKVariant
+	handshakeVariant
+)
+
+// maxDNSNameLength is the max length of DNS name
+const maxDNSNameLength = 255
+
+// NetworkAddress is Diem network address, a sequence of protocols, e.g.
+// "/dns4/example.com/tcp/6180/noise-ik/<hex-encoded key>/handshake/0"
+type NetworkAddress []Protocol
+
+// ParseNetworkAddress parses network address string
+func ParseNetworkAddress(address string) (NetworkAddress, error) {
+	parts := strings.Split(address, "/")
+	if len(parts) < 3 || parts[0] != "" {
+		return nil, fmt.Errorf("%w: %#v", ErrInvalidNetworkAddress, address)
+	}
+	parts = parts[1:]
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf("%w: %#v: missing protocol value", ErrInvalidNetworkAddress, address)
+	}
+	var ret NetworkAddress
+	for i := 0; i < len(parts); i += 2 {
+		protocol, err := parseProtocol(parts[i], parts[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v: %v", ErrInvalidNetworkAddress, address, err)
+		}
+		ret = append(ret, protocol)
+	}
+	return ret, nil
+}
+
+// MustParseNetworkAddress parses network address string, panics if it is invalid
+func MustParseNetworkAddress(address string) NetworkAddress {
+	ret, err := ParseNetworkAddress(address)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// String returns network address string, e.g. "/ip4/127.0.0.1/tcp/6180"
+func (a NetworkAddress) String() string {
+	var b strings.Builder
+	for _, p := range a {
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+// BcsSerialize returns BCS bytes of the network address, which is BCS bytes of the protocols
+// wrapped as bytes, same with Diem `NetworkAddress` serialization.
+func (a NetworkAddress) BcsSerialize() ([]byte, error) {
+	s := bcs.NewSerializer()
+	if err := a.serialize(s); err != nil {
+		return nil, err
+	}
+	return s.GetBytes(), nil
+}
+
+func (a NetworkAddress) serialize(s serde.Serializer) error {
+	protocols := bcs.NewSerializer()
+	if err := protocols.SerializeLen(uint64(len(a))); err != nil {
+		return err
+	}
+	for _, p := range a {
+		if err := p.serialize(protocols); err != nil {
+			return err
+		}
+	}
+	return s.SerializeBytes(protocols.GetBytes())
+}
+
+// EncodeNetworkAddresses returns BCS bytes of the network addresses list, which is the
+// `validator_network_addresses` and `fullnode_network_addresses` arguments of validator config
+// scripts.
+func EncodeNetworkAddresses(addresses ...NetworkAddress) ([]byte, error) {
+	s := bcs.NewSerializer()
+	if err := s.SerializeLen(uint64(len(addresses))); err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if err := address.serialize(s); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetBytes(), nil
+}
+
+// BcsDeserializeNetworkAddress decodes BCS bytes of network address, it is the reverse of
+// `NetworkAddress#BcsSerialize`.
+func BcsDeserializeNetworkAddress(bytes []byte) (NetworkAddress, error) {
+	d := bcs.NewDeserializer(bytes)
+	ret, err := deserializeNetworkAddress(d)
+	if err != nil {
+		return nil, err
+	}
+	if d.GetBufferOffset() != uint64(len(bytes)) {
+		return nil, fmt.Errorf("%w: some input bytes were not read", ErrInvalidNetworkAddress)
+	}
+	return ret, nil
+}
+
+// DecodeNetworkAddresses decodes BCS bytes of network addresses list, it is the reverse of
+// `EncodeNetworkAddresses`.
+func DecodeNetworkAddresses(bytes []byte) ([]NetworkAddress, error) {
+	d := bcs.NewDeserializer(bytes)
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNetworkAddress, err)
+	}
+	ret := make([]NetworkAddress, 0, length)
+	for i := uint64(0); i < length; i++ {
+		address, err := deserializeNetworkAddress(d)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, address)
+	}
+	if d.GetBufferOffset() != uint64(len(bytes)) {
+		return nil, fmt.Errorf("%w: some input bytes were not read", ErrInvalidNetworkAddress)
+	}
+	return ret, nil
+}
+
+func deserializeNetworkAddress(d serde.Deserializer) (NetworkAddress, error) {
+	bytes, err := d.DeserializeBytes()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNetworkAddress, err)
+	}
+	protocols := bcs.NewDeserializer(bytes)
+	length, err := protocols.DeserializeLen()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNetworkAddress, err)
+	}
+	ret := make(NetworkAddress, 0, length)
+	for i := uint64(0); i < length; i++ {
+		protocol, err := deserializeProtocol(protocols)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidNetworkAddress, err)
+		}
+		ret = append(ret, protocol)
+	}
+	if protocols.GetBufferOffset() != uint64(len(bytes)) {
+		return nil, fmt.Errorf("%w: some protocol bytes were not read", ErrInvalidNetworkAddress)
+	}
+	return ret, nil
+}
+
+func deserializeProtocol(d serde.Deserializer) (Protocol, error) {
+	variant, err := d.DeserializeVariantIndex()
+	if err != nil {
+		return nil, err
+	}
+	switch variant {
+	case ip4Variant:
+		ip, err := deserializeFixedBytes(d, net.IPv4len)
+		return IP4(ip), err
+	case ip6Variant:
+		ip, err := deserializeFixedBytes(d, net.IPv6len)
+		return IP6(ip), err
+	case dnsVariant, dns4Variant, dns6Variant:
+		name, err := d.DeserializeStr()
+		if err != nil {
+			return nil, err
+		}
+		if err := validateDNSName(name); err != nil {
+			return nil, err
+		}
+		switch variant {
+		case dns4Variant:
+			return DNS4(name), nil
+		case dns6Variant:
+			return DNS6(name), nil
+		}
+		return DNS(name), nil
+	case tcpVariant:
+		port, err := d.DeserializeU16()
+		return TCP(port), err
+	case memoryVariant:
+		port, err := d.DeserializeU16()
+		return Memory(port), err
+	case noiseIKVariant:
+		key, err := d.DeserializeBytes()
+		if err != nil {
+			return nil, err
+		}
+		var ret NoiseIK
+		if len(key) != len(ret) {
+			return nil, fmt.Errorf("invalid noise-ik public key length %d", len(key))
+		}
+		copy(ret[:], key)
+		return ret, nil
+	case handshakeVariant:
+		version, err := d.DeserializeU8()
+		return Handshake(version), err
+	}
+	return nil, fmt.Errorf("unknown protocol variant index %d", variant)
+}
+
+func deserializeFixedBytes(d serde.Deserializer, length int) ([]byte, error) {
+	ret := make([]byte, length)
+	for i := range ret {
+		b, err := d.DeserializeU8()
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = b
+	}
+	return ret, nil
+}
+
+func parseProtocol(name, value string) (Protocol, error) {
+	switch name {
+	case "ip4":
+		ip := net.ParseIP(value).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("invalid ip4 %#v", value)
+		}
+		return IP4(ip), nil
+	case "ip6":
+		ip := net.ParseIP(value)
+		if ip == nil || ip.To4() != nil {
+			return nil, fmt.Errorf("invalid ip6 %#v", value)
+		}
+		return IP6(ip), nil
+	case "dns", "dns4", "dns6":
+		if err := validateDNSName(value); err != nil {
+			return nil, err
+		}
+		switch name {
+		case "dns4":
+			return DNS4(value), nil
+		case "dns6":
+			return DNS6(value), nil
+		}
+		return DNS(value), nil
+	case "tcp", "memory":
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s port %#v", name, value)
+		}
+		if name == "memory" {
+			return Memory(port), nil
+		}
+		return TCP(port), nil
+	case "noise-ik":
+		key, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		if err != nil || len(key) != len(NoiseIK{}) {
+			return nil, fmt.Errorf("invalid noise-ik public key %#v", value)
+		}
+		var ret NoiseIK
+		copy(ret[:], key)
+		return ret, nil
+	case "handshake":
+		version, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid handshake version %#v", value)
+		}
+		return Handshake(version), nil
+	}
+	return nil, fmt.Errorf("unknown protocol %#v", name)
+}
+
+func validateDNSName(name string) error {
+	if name == "" || len(name) > maxDNSNameLength || strings.ContainsRune(name, '/') {
+		return fmt.Errorf("invalid dns name %#v", name)
+	}
+	return nil
+}
+
+// String implements `Protocol`
+func (p IP4) String() string { return "/ip4/" + net.IP(p).String() }
+
+// String implements `Protocol`
+func (p IP6) String() string { return "/ip6/" + net.IP(p).String() }
+
+// String implements `Protocol`
+func (p DNS) String() string { return "/dns/" + string(p) }
+
+// String implements `Protocol`
+func (p DNS4) String() string { return "/dns4/" + string(p) }
+
+// String implements `Protocol`
+func (p DNS6) String() string { return "/dns6/" + string(p) }
+
+// String implements `Protocol`
+func (p TCP) String() string { return fmt.Sprintf("/tcp/%d", uint16(p)) }
+
+// String implements `Protocol`
+func (p Memory) String() string { return fmt.Sprintf("/memory/%d", uint16(p)) }
+
+// String implements `Protocol`
+func (p NoiseIK) String() string { return "/noise-ik/" + hex.EncodeToString(p[:]) }
+
+// String implements `Protocol`
+func (p Handshake) String() string { return fmt.Sprintf("/handshake/%d", uint8(p)) }
+
+func (p IP4) serialize(s serde.Serializer) error {
+	ip := net.IP(p).To4()
+	if ip == nil {
+		return fmt.Errorf("%w: invalid ip4 %v", ErrInvalidNetworkAddress, net.IP(p))
+	}
+	return serializeFixedBytes(s, ip4Variant, ip)
+}
+
+func (p IP6) serialize(s serde.Serializer) error {
+	ip := net.IP(p).To16()
+	if ip == nil {
+		return fmt.Errorf("%w: invalid ip6 %v", ErrInvalidNetworkAddress, net.IP(p))
+	}
+	return serializeFixedBytes(s, ip6Variant, ip)
+}
+
+func (p DNS) serialize(s serde.Serializer) error  { return serializeDNSName(s, dnsVariant, string(p)) }
+func (p DNS4) serialize(s serde.Serializer) error { return serializeDNSName(s, dns4Variant, string(p)) }
+func (p DNS6) serialize(s serde.Serializer) error { return serializeDNSName(s, dns6Variant, string(p)) }
+
+func (p TCP) serialize(s serde.Serializer) error {
+	if err := s.SerializeVariantIndex(tcpVariant); err != nil {
+		return err
+	}
+	return s.SerializeU16(uint16(p))
+}
+
+func (p Memory) serialize(s serde.Serializer) error {
+	if err := s.SerializeVariantIndex(memoryVariant); err != nil {
+		return err
+	}
+	return s.SerializeU16(uint16(p))
+}
+
+func (p NoiseIK) serialize(s serde.Serializer) error {
+	if err := s.SerializeVariantIndex(noiseIKVariant); err != nil {
+		return err
+	}
+	return s.SerializeBytes(p[:])
+}
+
+func (p Handshake) serialize(s serde.Serializer) error {
+	if err := s.SerializeVariantIndex(handshakeVariant); err != nil {
+		return err
+	}
+	return s.SerializeU8(uint8(p))
+}
+
+func serializeFixedBytes(s serde.Serializer, variant uint32, bytes []byte) error {
+	if err := s.SerializeVariantIndex(variant); err != nil {
+		return err
+	}
+	for _, b := range bytes {
+		if err := s.SerializeU8(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func serializeDNSName(s serde.Serializer, variant uint32, name string) error {
+	if err := validateDNSName(name); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidNetworkAddress, err)
+	}
+	if err := s.SerializeVariantIndex(variant); err != nil {
+		return err
+	}
+	return s.SerializeStr(name)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package validatorops_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/diem/client-sdk-go/validatorops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const noiseKey = "d6f8a7b8e2b7fcbb4a1ddcd5e9e9e0b5a0d2b2f4f4c1b8c5fbb2c3d7e9a1b2c3"
+
+func TestParseNetworkAddress(t *testing.T) {
+	cases := []struct {
+		address  string
+		expected validatorops.NetworkAddress
+	}{
+		{"/ip4/127.0.0.1/tcp/6180", validatorops.NetworkAddress{
+			validatorops.IP4(net.IPv4(127, 0, 0, 1).To4()), validatorops.TCP(6180)}},
+		{"/ip6/::1/tcp/6180", validatorops.NetworkAddress{
+			validatorops.IP6(net.IPv6loopback), validatorops.TCP(6180)}},
+		{"/dns/example.com/memory/6180", validatorops.NetworkAddress{
+			validatorops.DNS("example.com"), validatorops.Memory(6180)}},
+		{"/dns4/example.com/tcp/6180/noise-ik/" + noiseKey + "/handshake/0", validatorops.NetworkAddress{
+			validatorops.DNS4("example.com"), validatorops.TCP(6180), noiseIK(t), validatorops.Handshake(0)}},
+		{"/dns6/example.com/tcp/80", validatorops.NetworkAddress{
+			validatorops.DNS6("example.com"), validatorops.TCP(80)}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.address, func(t *testing.T) {
+			address, err := validatorops.ParseNetworkAddress(tc.address)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, address)
+			assert.Equal(t, tc.address, address.String())
+		})
+	}
+
+	t.Run("noise-ik key with 0x prefix", func(t *testing.T) {
+		address, err := validatorops.ParseNetworkAddress("/noise-ik/0x" + noiseKey)
+		require.NoError(t, err)
+		assert.Equal(t, validatorops.NetworkAddress{noiseIK(t)}, address)
+	})
+
+	for _, invalid := range []string{
+		"",
+		"/",
+		"ip4/127.0.0.1",
+		"/ip4",
+		"/ip4/127.0.0.1/tcp",
+		"/ip4/::1",
+		"/ip6/127.0.0.1",
+		"/dns/",
+		"/tcp/65536",
+		"/memory/-1",
+		"/noise-ik/abcd",
+		"/handshake/256",
+		"/udp/6180",
+	} {
+		t.Run("invalid "+invalid, func(t *testing.T) {
+			_, err := validatorops.ParseNetworkAddress(invalid)
+			assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+		})
+	}
+	assert.Panics(t, func() { validatorops.MustParseNetworkAddress("/udp/6180") })
+}
+
+func TestNetworkAddressBcsSerialize(t *testing.T) {
+	address := validatorops.MustParseNetworkAddress("/ip4/127.0.0.1/tcp/6180")
+	bytes, err := address.BcsSerialize()
+	require.NoError(t, err)
+	assert.Equal(t, "09"+"02"+"007f000001"+"052418", hex.EncodeToString(bytes))
+
+	address = validatorops.MustParseNetworkAddress("/dns/a.io/noise-ik/" + noiseKey + "/handshake/1")
+	bytes, err = address.BcsSerialize()
+	require.NoError(t, err)
+	assert.Equal(t, "2b"+"03"+"0204612e696f"+"0720"+noiseKey+"0801", hex.EncodeToString(bytes))
+
+	encoded, err := validatorops.EncodeNetworkAddresses(
+		validatorops.MustParseNetworkAddress("/ip4/127.0.0.1/tcp/6180"),
+		validatorops.MustParseNetworkAddress("/memory/1"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "02"+"0902007f000001052418"+"0401060100", hex.EncodeToString(encoded))
+
+	encoded, err = validatorops.EncodeNetworkAddresses()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0}, encoded)
+
+	t.Run("invalid protocol value", func(t *testing.T) {
+		_, err := validatorops.NetworkAddress{validatorops.IP4{1, 2}}.BcsSerialize()
+		assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+		_, err = validatorops.EncodeNetworkAddresses(validatorops.NetworkAddress{validatorops.DNS("a/b")})
+		assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+	})
+}
+
+func TestDecodeNetworkAddress(t *testing.T) {
+	addresses := []validatorops.NetworkAddress{
+		validatorops.MustParseNetworkAddress("/ip4/127.0.0.1/tcp/6180"),
+		validatorops.MustParseNetworkAddress("/ip6/::1/memory/1"),
+		validatorops.MustParseNetworkAddress("/dns/a.io/noise-ik/" + noiseKey + "/handshake/1"),
+		validatorops.MustParseNetworkAddress("/dns4/a.io/dns6/b.io/tcp/80"),
+		{},
+	}
+	for _, address := range addresses {
+		bytes, err := address.BcsSerialize()
+		require.NoError(t, err)
+		decoded, err := validatorops.BcsDeserializeNetworkAddress(bytes)
+		require.NoError(t, err)
+		assert.Equal(t, address, decoded)
+		assert.Equal(t, address.String(), decoded.String())
+	}
+
+	encoded, err := validatorops.EncodeNetworkAddresses(addresses...)
+	require.NoError(t, err)
+	decoded, err := validatorops.DecodeNetworkAddresses(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, addresses, decoded)
+
+	for name, invalid := range map[string]string{
+		"empty":                   "",
+		"extra bytes":             "0902007f00000105241800",
+		"extra protocol bytes":    "0a02007f00000105241800",
+		"unexpected end of input": "0902007f0000010524",
+		"unknown variant":         "020109",
+		"invalid dns name":        "03010200",
+		"invalid noise-ik key":    "0401070100",
+	} {
+		t.Run(name, func(t *testing.T) {
+			bytes, _ := hex.DecodeString(invalid)
+			_, err := validatorops.BcsDeserializeNetworkAddress(bytes)
+			assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+		})
+	}
+	_, err = validatorops.DecodeNetworkAddresses(append(encoded, 0))
+	assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+	_, err = validatorops.DecodeNetworkAddresses(encoded[:len(encoded)-1])
+	assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress))
+}
+
+func noiseIK(t *testing.T) validatorops.NoiseIK {
+	bytes, err := hex.DecodeString(noiseKey)
+	require.NoError(t, err)
+	var ret validatorops.NoiseIK
+	copy(ret[:], bytes)
+	return ret
+}
@@ -0,0 +1,132 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package validatorops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/tcops"
+	"github.com/diem/client-sdk-go/txnbuilder"
+)
+
+// DiemRootAddress is the account address of Diem Root account
+var DiemRootAddress = diemtypes.MustMakeAccountAddress("0000000000000000000000000a550c18")
+
+// ValidatorConfig is config of a validator, registered by its validator operator
+type ValidatorConfig struct {
+	ConsensusPublicKey *diemkeys.Ed25519PublicKey
+	// ValidatorAddresses is BCS-encoded `Vec<EncNetworkAddress>`: network addresses for
+	// validators connecting to the validator, encrypted by the shared validator network address
+	// key, see `EncryptNetworkAddresses`.
+	ValidatorAddresses []byte
+	// FullnodeAddresses is BCS-encoded `Vec<NetworkAddress>`: network addresses for full nodes
+	// connecting to the validator, see `EncodeNetworkAddresses`.
+	FullnodeAddresses []byte
+}
+
+// Operator signs, submits and waits for validator operation transactions sent by the account,
+// which is Diem Root, validator or validator operator account depends on the operation.
+// Operations requiring sliding nonce are submitted by `tcops.SlidingNonceManager#SubmitWithNonce`
+// of `Nonces`.
+type Operator struct {
+	txnbuilder.Operator
+	Nonces *tcops.SlidingNonceManager
+}
+
+// New creates `Operator` for the account address and its signer
+func New(client diemclient.Client, address diemtypes.AccountAddress, signer diemsigner.Signer) *Operator {
+	return &Operator{
+		Operator: txnbuilder.NewOperator(client, address, signer),
+		Nonces:   tcops.NewSlidingNonceManager(client, address),
+	}
+}
+
+// WithContext sets context for client calls
+func (o *Operator) WithContext(ctx context.Context) *Operator {
+	o.Operator.WithContext(ctx)
+	return o
+}
+
+// CreateValidatorAccount creates validator account of the auth key by Diem Root account
+func (o *Operator) CreateValidatorAccount(authKey diemkeys.AuthKey, humanName string) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeCreateValidatorAccountScriptFunction(
+			nonce, authKey.AccountAddress(), authKey.Prefix(), []byte(humanName))
+	})
+}
+
+// CreateValidatorOperatorAccount creates validator operator account of the auth key by Diem
+// Root account
+func (o *Operator) CreateValidatorOperatorAccount(authKey diemkeys.AuthKey, humanName string) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeCreateValidatorOperatorAccountScriptFunction(
+			nonce, authKey.AccountAddress(), authKey.Prefix(), []byte(humanName))
+	})
+}
+
+// SetOperator sets the validator operator of the validator, by the validator account.
+// The operator name must match the human name of the validator operator account.
+func (o *Operator) SetOperator(operatorName string, operator diemtypes.AccountAddress) (*diemclient.Transaction, error) {
+	return o.Submit(stdlib.EncodeSetValidatorOperatorScriptFunction([]byte(operatorName), operator))
+}
+
+// RegisterConfig registers config of the validator by its validator operator account, the
+// validator set is not reconfigured, call `AddValidator` to add the validator into the set.
+func (o *Operator) RegisterConfig(validator diemtypes.AccountAddress, config ValidatorConfig) (*diemclient.Transaction, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return o.Submit(stdlib.EncodeRegisterValidatorConfigScriptFunction(
+		validator, config.ConsensusPublicKey.Bytes(), config.ValidatorAddresses, config.FullnodeAddresses))
+}
+
+// SetConfigAndReconfigure updates config of the validator in the validator set and
+// reconfigures the set, by its validator operator account.
+func (o *Operator) SetConfigAndReconfigure(validator diemtypes.AccountAddress, config ValidatorConfig) (*diemclient.Transaction, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return o.Submit(stdlib.EncodeSetValidatorConfigAndReconfigureScriptFunction(
+		validator, config.ConsensusPublicKey.Bytes(), config.ValidatorAddresses, config.FullnodeAddresses))
+}
+
+// AddValidator adds the validator into the validator set and reconfigures the set, by Diem Root
+// account. The validator name must match the human name of the validator account.
+func (o *Operator) AddValidator(validatorName string, validator diemtypes.AccountAddress) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeAddValidatorAndReconfigureScriptFunction(nonce, []byte(validatorName), validator)
+	})
+}
+
+// RemoveValidator removes the validator from the validator set and reconfigures the set, by
+// Diem Root account. The validator name must match the human name of the validator account.
+func (o *Operator) RemoveValidator(validatorName string, validator diemtypes.AccountAddress) (*diemclient.Transaction, error) {
+	return o.submitWithNonce(func(nonce uint64) diemtypes.TransactionPayload {
+		return stdlib.EncodeRemoveValidatorAndReconfigureScriptFunction(nonce, []byte(validatorName), validator)
+	})
+}
+
+func (c *ValidatorConfig) validate() error {
+	if c.ConsensusPublicKey == nil {
+		return errors.New("consensus public key is required")
+	}
+	if _, err := DecodeEncNetworkAddresses(c.ValidatorAddresses); err != nil {
+		return fmt.Errorf("invalid validator addresses: %w", err)
+	}
+	if _, err := DecodeNetworkAddresses(c.FullnodeAddresses); err != nil {
+		return fmt.Errorf("invalid fullnode addresses: %w", err)
+	}
+	return nil
+}
+
+func (o *Operator) submitWithNonce(payload func(nonce uint64) diemtypes.TransactionPayload) (*diemclient.Transaction, error) {
+	return o.SubmitWithNonce(o.Nonces, payload)
+}
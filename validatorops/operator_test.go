@@ -0,0 +1,180 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package validatorops_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/diem/client-sdk-go/accountstate"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/stdlib"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/validatorops"
+	"github.com/novifinancial/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chain executes submitted transactions immediately
+type chain struct {
+	sequence  uint64
+	minNonce  uint64
+	submitted []*diemtypes.SignedTransaction
+}
+
+func (c *chain) Call(requests ...*jsonrpc.Request) (map[jsonrpc.RequestID]*jsonrpc.Response, error) {
+	req := requests[0]
+	var result string
+	switch req.Method {
+	case diemclient.GetAccountStateWithProof:
+		result = fmt.Sprintf(`{"version": 1, "blob": %q}`, hex.EncodeToString(slidingNonceBlob(c.minNonce)))
+	case diemclient.GetAccount:
+		result = fmt.Sprintf(`{"sequence_number": %d}`, c.sequence)
+	case diemclient.Submit:
+		bytes, _ := hex.DecodeString(req.Params[0].(string))
+		txn, _ := diemtypes.BcsDeserializeSignedTransaction(bytes)
+		c.submitted = append(c.submitted, &txn)
+		c.sequence++
+	case diemclient.GetAccountTransaction:
+		txn := c.submitted[len(c.submitted)-1]
+		result = fmt.Sprintf(`{"version": 1, "hash": %q, "vm_status": {"type": "executed"}}`, txn.TransactionHash())
+	}
+	var resp jsonrpc.Response
+	if result != "" {
+		raw := json.RawMessage(result)
+		resp.Result = &raw
+	}
+	stub := jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{req.ID: resp}}
+	return stub.Call(requests...)
+}
+
+func (c *chain) lastCall(t *testing.T) stdlib.ScriptFunctionCall {
+	require.NotEmpty(t, c.submitted)
+	call, err := stdlib.DecodeScriptFunctionPayload(c.submitted[len(c.submitted)-1].RawTxn.Payload)
+	require.NoError(t, err)
+	return call
+}
+
+func slidingNonceBlob(minNonce uint64) []byte {
+	value := bcs.NewSerializer()
+	_ = value.SerializeU64(minNonce)
+	_ = value.SerializeU64(0)
+	_ = value.SerializeU64(0)
+
+	state := bcs.NewSerializer()
+	_ = state.SerializeLen(1)
+	_ = state.SerializeBytes(accountstate.ResourcePath(accountstate.SlidingNonceTag))
+	_ = state.SerializeBytes(value.GetBytes())
+
+	blob := bcs.NewSerializer()
+	_ = blob.SerializeBytes(state.GetBytes())
+	return blob.GetBytes()
+}
+
+func TestOperatorDiemRoot(t *testing.T) {
+	c := &chain{minNonce: 3}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+	root := validatorops.New(client, validatorops.DiemRootAddress, diemsigner.NewKeysSigner(diemkeys.MustGenKeys()))
+	validator := diemkeys.MustGenKeys()
+	operator := diemkeys.MustGenKeys()
+
+	_, err := root.CreateValidatorAccount(validator.AuthKey(), "validator")
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__CreateValidatorAccount{
+		SlidingNonce:      3,
+		NewAccountAddress: validator.AccountAddress(),
+		AuthKeyPrefix:     validator.AuthKey().Prefix(),
+		HumanName:         []byte("validator"),
+	}, c.lastCall(t))
+	assert.Equal(t, validatorops.DiemRootAddress, c.submitted[0].RawTxn.Sender)
+
+	_, err = root.CreateValidatorOperatorAccount(operator.AuthKey(), "operator")
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__CreateValidatorOperatorAccount{
+		SlidingNonce:      4,
+		NewAccountAddress: operator.AccountAddress(),
+		AuthKeyPrefix:     operator.AuthKey().Prefix(),
+		HumanName:         []byte("operator"),
+	}, c.lastCall(t))
+
+	_, err = root.AddValidator("validator", validator.AccountAddress())
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__AddValidatorAndReconfigure{
+		SlidingNonce: 5, ValidatorName: []byte("validator"), ValidatorAddress: validator.AccountAddress(),
+	}, c.lastCall(t))
+
+	_, err = root.RemoveValidator("validator", validator.AccountAddress())
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__RemoveValidatorAndReconfigure{
+		SlidingNonce: 6, ValidatorName: []byte("validator"), ValidatorAddress: validator.AccountAddress(),
+	}, c.lastCall(t))
+}
+
+func TestOperatorValidatorConfig(t *testing.T) {
+	c := &chain{}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, c)
+	validator := diemkeys.MustGenKeys()
+	operator := diemkeys.MustGenKeys()
+
+	_, err := validatorops.New(client, validator.AccountAddress(), diemsigner.NewKeysSigner(validator)).
+		SetOperator("operator", operator.AccountAddress())
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__SetValidatorOperator{
+		OperatorName: []byte("operator"), OperatorAccount: operator.AccountAddress(),
+	}, c.lastCall(t))
+
+	consensusKey := diemkeys.MustGenKeys().PublicKey.(*diemkeys.Ed25519PublicKey)
+	var key validatorops.NetworkAddressKey
+	key[0] = 1
+	validatorAddresses, err := validatorops.EncryptNetworkAddresses(key, 0, validator.AccountAddress(), 0,
+		validatorops.MustParseNetworkAddress("/ip4/10.0.0.1/tcp/6180"))
+	require.NoError(t, err)
+	fullnodeAddresses, err := validatorops.EncodeNetworkAddresses(
+		validatorops.MustParseNetworkAddress("/dns4/example.com/tcp/6182"))
+	require.NoError(t, err)
+	config := validatorops.ValidatorConfig{
+		ConsensusPublicKey: consensusKey,
+		ValidatorAddresses: validatorAddresses,
+		FullnodeAddresses:  fullnodeAddresses,
+	}
+
+	ops := validatorops.New(client, operator.AccountAddress(), diemsigner.NewKeysSigner(operator))
+	_, err = ops.RegisterConfig(validator.AccountAddress(), config)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__RegisterValidatorConfig{
+		ValidatorAccount:          validator.AccountAddress(),
+		ConsensusPubkey:           consensusKey.Bytes(),
+		ValidatorNetworkAddresses: validatorAddresses,
+		FullnodeNetworkAddresses:  fullnodeAddresses,
+	}, c.lastCall(t))
+	assert.Equal(t, operator.AccountAddress(), c.submitted[1].RawTxn.Sender)
+
+	_, err = ops.SetConfigAndReconfigure(validator.AccountAddress(), config)
+	require.NoError(t, err)
+	assert.Equal(t, &stdlib.ScriptFunctionCall__SetValidatorConfigAndReconfigure{
+		ValidatorAccount:          validator.AccountAddress(),
+		ConsensusPubkey:           consensusKey.Bytes(),
+		ValidatorNetworkAddresses: validatorAddresses,
+		FullnodeNetworkAddresses:  fullnodeAddresses,
+	}, c.lastCall(t))
+
+	_, err = ops.RegisterConfig(validator.AccountAddress(), validatorops.ValidatorConfig{})
+	assert.EqualError(t, err, "consensus public key is required")
+	_, err = ops.RegisterConfig(validator.AccountAddress(), validatorops.ValidatorConfig{
+		ConsensusPublicKey: consensusKey,
+		ValidatorAddresses: fullnodeAddresses,
+		FullnodeAddresses:  fullnodeAddresses,
+	})
+	assert.True(t, errors.Is(err, validatorops.ErrInvalidNetworkAddress), "validator addresses are not encrypted")
+	assert.Len(t, c.submitted, 3)
+}
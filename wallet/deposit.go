@@ -0,0 +1,119 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package wallet
+
+import (
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// Deposit is an incoming payment to a sub-address of the wallet
+type Deposit struct {
+	Version          uint64
+	Sender           diemtypes.AccountAddress
+	SenderSubAddress diemtypes.SubAddress
+	SubAddress       diemtypes.SubAddress
+	Amount           diemamount.Amount
+}
+
+// DepositOutcome is the result of routing a deposit
+type DepositOutcome string
+
+const (
+	// DepositCredited deposit should be credited to the owner of the sub-address
+	DepositCredited DepositOutcome = "credited"
+	// DepositInReview deposit is queued for manual review
+	DepositInReview DepositOutcome = "in_review"
+	// DepositRefunded deposit is refunded to the sender automatically
+	DepositRefunded DepositOutcome = "refunded"
+)
+
+// ReviewItem is a deposit queued for review
+type ReviewItem struct {
+	Deposit Deposit
+	// Reason is the status of sub-address when the deposit arrived, or "unknown"
+	Reason string
+	// RefundError is set when automatic refund failed
+	RefundError error
+}
+
+// Refunder refunds a deposit back to its sender, e.g. submits a p2p transaction with
+// `txnmetadata.NewRefundMetadata(deposit.Version, reason)`.
+type Refunder interface {
+	Refund(deposit Deposit, reason diemtypes.RefundReason) error
+}
+
+// DepositRouter routes incoming deposits by sub-address lifecycle: deposits to active
+// sub-addresses are credited, others are queued for review, or refunded automatically
+// when a `Refunder` is configured.
+type DepositRouter struct {
+	SubAddresses *SubAddresses
+	Refunder     Refunder
+
+	mux    sync.Mutex
+	review []ReviewItem
+}
+
+// NewDepositRouter creates `DepositRouter`
+func NewDepositRouter(subAddresses *SubAddresses) *DepositRouter {
+	return &DepositRouter{SubAddresses: subAddresses}
+}
+
+// WithAutoRefund refunds deposits arriving on expired, used or unknown sub-addresses.
+func (r *DepositRouter) WithAutoRefund(refunder Refunder) *DepositRouter {
+	r.Refunder = refunder
+	return r
+}
+
+// Route routes the deposit, the sub-address is claimed by `SubAddresses.Claim` when the deposit
+// is credited. Returns error if claiming the sub-address failed for reasons other than the
+// sub-address is unknown or inactive.
+func (r *DepositRouter) Route(deposit Deposit) (DepositOutcome, error) {
+	var reason string
+	_, err := r.SubAddresses.Claim(deposit.SubAddress)
+	switch e := err.(type) {
+	case nil:
+		return DepositCredited, nil
+	case *UnknownSubAddressError:
+		reason = "unknown"
+	case *InactiveSubAddressError:
+		reason = string(e.Status)
+	default:
+		return "", err
+	}
+	item := ReviewItem{Deposit: deposit, Reason: reason}
+	if r.Refunder != nil {
+		item.RefundError = r.Refunder.Refund(deposit, &diemtypes.RefundReason__InvalidSubaddress{})
+		if item.RefundError == nil {
+			return DepositRefunded, nil
+		}
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.review = append(r.review, item)
+	return DepositInReview, nil
+}
+
+// ReviewQueue returns deposits queued for review
+func (r *DepositRouter) ReviewQueue() []ReviewItem {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return append([]ReviewItem(nil), r.review...)
+}
+
+// Resolve removes a deposit from the review queue by transaction version, returns false if
+// not found.
+func (r *DepositRouter) Resolve(version uint64) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for i, item := range r.review {
+		if item.Deposit.Version == version {
+			r.review = append(r.review[:i], r.review[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,6 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Provides custodial wallet utils, including deposit sub-address lifecycle management and
+// hot wallet key rotation.
+package wallet
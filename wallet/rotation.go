@@ -0,0 +1,250 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/stdlib"
+)
+
+// DefaultRotationTimeout is default timeout of waiting for the rotation transaction executed
+const DefaultRotationTimeout = 30 * time.Second
+
+// RotationClient is the subset of `diemclient.Client` used by `KeyRotationDrill`
+type RotationClient interface {
+	GetAccount(diemtypes.AccountAddress) (*diemclient.Account, error)
+	SubmitTransaction(txn *diemtypes.SignedTransaction) error
+	WaitForTransaction2(txn *diemtypes.SignedTransaction, timeout time.Duration) (*diemclient.Transaction, error)
+}
+
+// RotationStep is a step of the key rotation drill
+type RotationStep string
+
+const (
+	// StepPreflight checks on-chain authentication key matches the current key
+	StepPreflight RotationStep = "preflight"
+	// StepGenerate generates new key in the key backend
+	StepGenerate RotationStep = "generate"
+	// StepRotate submits rotate authentication key transaction
+	StepRotate RotationStep = "rotate"
+	// StepVerify checks on-chain authentication key matches the new key
+	StepVerify RotationStep = "verify"
+	// StepRevoke revokes the old key in the key backend
+	StepRevoke RotationStep = "revoke"
+)
+
+// RotationStepResult is result of a drill step
+type RotationStepResult struct {
+	Step    RotationStep
+	Skipped bool
+	Message string
+	Err     error
+}
+
+// RotationReport is the report of a key rotation drill
+type RotationReport struct {
+	DryRun   bool
+	OldKeyID string
+	NewKeyID string
+	Version  uint64
+	Steps    []RotationStepResult
+}
+
+// KeyRotationError is returned when a drill step failed
+type KeyRotationError struct {
+	Step RotationStep
+	Err  error
+}
+
+// Error implements error interface
+func (e *KeyRotationError) Error() string {
+	return fmt.Sprintf("key rotation %s step failed: %v", e.Step, e.Err)
+}
+
+// KeyRotationDrill rotates hot wallet account authentication key:
+//  1. preflight: on-chain authentication key matches the current key
+//  2. generate: new key in the key backend
+//  3. rotate: submit rotate authentication key transaction signed by the current key
+//  4. verify: on-chain authentication key matches the new key
+//  5. revoke: revoke the current key in the key backend
+//
+// It is designed to be run regularly as an operational drill. In dry-run mode, the rotation
+// transaction is signed but not submitted, and the generated key is revoked at the end.
+// Failed drill before the rotate step leaves the current key untouched; the new key should be
+// kept when failed after rotate step, see `RotationReport.NewKeyID`.
+type KeyRotationDrill struct {
+	Client       RotationClient
+	Keys         KeyBackend
+	Address      diemtypes.AccountAddress
+	CurrentKeyID string
+	ChainID      byte
+	DryRun       bool
+
+	GasCurrency  string
+	MaxGasAmount uint64
+	GasUnitPrice uint64
+	Timeout      time.Duration
+	Logger       *log.Logger
+}
+
+// Run runs the drill, returns report and `*KeyRotationError` if any step failed.
+func (d *KeyRotationDrill) Run() (*RotationReport, error) {
+	report := &RotationReport{DryRun: d.DryRun, OldKeyID: d.CurrentKeyID}
+	account, err := d.preflight(report)
+	if err != nil {
+		return report, err
+	}
+	newAuthKey, err := d.generate(report)
+	if err != nil {
+		return report, err
+	}
+	txn, err := d.signRotation(account.SequenceNumber, newAuthKey)
+	if err == nil && !d.DryRun {
+		err = d.submit(report, txn)
+	}
+	if err != nil {
+		return report, d.fail(report, StepRotate, err)
+	}
+	if d.DryRun {
+		d.step(report, StepRotate, true, "signed rotation transaction %s, not submitted", txn.TransactionHash())
+		d.step(report, StepVerify, true, "dry run")
+		if err := d.Keys.Revoke(report.NewKeyID); err != nil {
+			return report, d.fail(report, StepRevoke, err)
+		}
+		d.step(report, StepRevoke, true, "revoked generated key %s, current key is kept", report.NewKeyID)
+		return report, nil
+	}
+	d.step(report, StepRotate, false, "rotated at version %d", report.Version)
+
+	account, err = d.Client.GetAccount(d.Address)
+	if err == nil && account == nil {
+		err = errors.New("account not found")
+	}
+	if err == nil && account.AuthenticationKey != newAuthKey.Hex() {
+		err = fmt.Errorf("on-chain authentication key %s does not match new key %s",
+			account.AuthenticationKey, newAuthKey.Hex())
+	}
+	if err != nil {
+		return report, d.fail(report, StepVerify, err)
+	}
+	d.step(report, StepVerify, false, "on-chain authentication key is %s", newAuthKey.Hex())
+
+	if err := d.Keys.Revoke(d.CurrentKeyID); err != nil {
+		return report, d.fail(report, StepRevoke, err)
+	}
+	d.step(report, StepRevoke, false, "revoked key %s", d.CurrentKeyID)
+	return report, nil
+}
+
+func (d *KeyRotationDrill) preflight(report *RotationReport) (*diemclient.Account, error) {
+	signer, err := d.Keys.Signer(d.CurrentKeyID)
+	if err != nil {
+		return nil, d.fail(report, StepPreflight, err)
+	}
+	account, err := d.Client.GetAccount(d.Address)
+	if err != nil {
+		return nil, d.fail(report, StepPreflight, err)
+	}
+	if account == nil {
+		return nil, d.fail(report, StepPreflight, fmt.Errorf("account %s not found", d.Address.Hex()))
+	}
+	authKey := diemkeys.NewAuthKey(signer.PublicKey())
+	if account.AuthenticationKey != authKey.Hex() {
+		return nil, d.fail(report, StepPreflight, fmt.Errorf(
+			"on-chain authentication key %s does not match current key %s",
+			account.AuthenticationKey, authKey.Hex()))
+	}
+	d.step(report, StepPreflight, false, "account %s sequence number %d",
+		d.Address.Hex(), account.SequenceNumber)
+	return account, nil
+}
+
+func (d *KeyRotationDrill) generate(report *RotationReport) (diemkeys.AuthKey, error) {
+	keyID, err := d.Keys.GenerateKey()
+	if err != nil {
+		return nil, d.fail(report, StepGenerate, err)
+	}
+	report.NewKeyID = keyID
+	signer, err := d.Keys.Signer(keyID)
+	if err != nil {
+		return nil, d.fail(report, StepGenerate, err)
+	}
+	d.step(report, StepGenerate, false, "generated key %s", keyID)
+	return diemkeys.NewAuthKey(signer.PublicKey()), nil
+}
+
+func (d *KeyRotationDrill) signRotation(sequenceNumber uint64, newAuthKey diemkeys.AuthKey) (*diemtypes.SignedTransaction, error) {
+	signer, err := d.Keys.Signer(d.CurrentKeyID)
+	if err != nil {
+		return nil, err
+	}
+	gasCurrency := d.GasCurrency
+	if gasCurrency == "" {
+		gasCurrency = "XUS"
+	}
+	maxGasAmount := d.MaxGasAmount
+	if maxGasAmount == 0 {
+		maxGasAmount = 1_000_000
+	}
+	return diemsigner.SignTxnWithSigner(
+		context.Background(),
+		signer,
+		d.Address,
+		sequenceNumber,
+		&diemtypes.TransactionPayload__Script{
+			Value: stdlib.EncodeRotateAuthenticationKeyScript(newAuthKey),
+		},
+		maxGasAmount, d.GasUnitPrice, gasCurrency,
+		uint64(time.Now().Add(d.timeout()).Unix()),
+		d.ChainID,
+	)
+}
+
+func (d *KeyRotationDrill) submit(report *RotationReport, txn *diemtypes.SignedTransaction) error {
+	if err := d.Client.SubmitTransaction(txn); err != nil {
+		if _, ok := err.(*diemclient.StaleResponseError); !ok {
+			return err
+		}
+	}
+	executed, err := d.Client.WaitForTransaction2(txn, d.timeout())
+	if err != nil {
+		return err
+	}
+	report.Version = executed.Version
+	return nil
+}
+
+func (d *KeyRotationDrill) timeout() time.Duration {
+	if d.Timeout > 0 {
+		return d.Timeout
+	}
+	return DefaultRotationTimeout
+}
+
+func (d *KeyRotationDrill) step(report *RotationReport, step RotationStep, skipped bool, format string, args ...interface{}) {
+	result := RotationStepResult{Step: step, Skipped: skipped, Message: fmt.Sprintf(format, args...)}
+	report.Steps = append(report.Steps, result)
+	d.logf("%s: %s", step, result.Message)
+}
+
+func (d *KeyRotationDrill) fail(report *RotationReport, step RotationStep, err error) error {
+	report.Steps = append(report.Steps, RotationStepResult{Step: step, Err: err})
+	d.logf("%s failed: %v", step, err)
+	return &KeyRotationError{Step: step, Err: err}
+}
+
+func (d *KeyRotationDrill) logf(format string, args ...interface{}) {
+	if d.Logger != nil {
+		d.Logger.Printf(format, args...)
+	}
+}
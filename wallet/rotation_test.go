@@ -0,0 +1,108 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotationChain applies submitted rotation transactions to the account authentication key
+type rotationChain struct {
+	account   *diemclient.Account
+	submitted []*diemtypes.SignedTransaction
+	submitErr error
+}
+
+func (c *rotationChain) GetAccount(diemtypes.AccountAddress) (*diemclient.Account, error) {
+	return c.account, nil
+}
+
+func (c *rotationChain) SubmitTransaction(txn *diemtypes.SignedTransaction) error {
+	if c.submitErr != nil {
+		return c.submitErr
+	}
+	c.submitted = append(c.submitted, txn)
+	script := txn.RawTxn.Payload.(*diemtypes.TransactionPayload__Script).Value
+	newKey := *script.Args[0].(*diemtypes.TransactionArgument__U8Vector)
+	c.account.AuthenticationKey = diemkeys.AuthKey(newKey).Hex()
+	c.account.SequenceNumber++
+	return nil
+}
+
+func (c *rotationChain) WaitForTransaction2(txn *diemtypes.SignedTransaction, timeout time.Duration) (*diemclient.Transaction, error) {
+	return &diemclient.Transaction{Version: 42}, nil
+}
+
+func newDrill(t *testing.T) (*wallet.KeyRotationDrill, *rotationChain, *wallet.MemoryKeyBackend) {
+	backend := wallet.NewMemoryKeyBackend()
+	keys := diemkeys.MustGenKeys()
+	chain := &rotationChain{account: &diemclient.Account{
+		AuthenticationKey: keys.AuthKey().Hex(),
+		SequenceNumber:    5,
+	}}
+	return &wallet.KeyRotationDrill{
+		Client:       chain,
+		Keys:         backend,
+		Address:      keys.AccountAddress(),
+		CurrentKeyID: backend.Import(keys),
+		ChainID:      2,
+	}, chain, backend
+}
+
+func TestKeyRotationDrill(t *testing.T) {
+	drill, chain, backend := newDrill(t)
+	report, err := drill.Run()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), report.Version)
+	assert.Len(t, report.Steps, 5)
+	assert.Equal(t, report.NewKeyID, chain.account.AuthenticationKey)
+	assert.Equal(t, uint64(5), chain.submitted[0].RawTxn.SequenceNumber)
+
+	_, err = backend.Signer(report.OldKeyID)
+	assert.IsType(t, &wallet.UnknownKeyError{}, err)
+	_, err = backend.Signer(report.NewKeyID)
+	assert.NoError(t, err)
+}
+
+func TestKeyRotationDrillDryRun(t *testing.T) {
+	drill, chain, backend := newDrill(t)
+	drill.DryRun = true
+	report, err := drill.Run()
+	require.NoError(t, err)
+	assert.Empty(t, chain.submitted)
+	assert.True(t, report.Steps[2].Skipped)
+
+	_, err = backend.Signer(report.OldKeyID)
+	assert.NoError(t, err)
+	_, err = backend.Signer(report.NewKeyID)
+	assert.IsType(t, &wallet.UnknownKeyError{}, err)
+}
+
+func TestKeyRotationDrillFailures(t *testing.T) {
+	t.Run("preflight: auth key mismatch", func(t *testing.T) {
+		drill, chain, _ := newDrill(t)
+		chain.account.AuthenticationKey = "00"
+		_, err := drill.Run()
+		require.Error(t, err)
+		assert.Equal(t, wallet.StepPreflight, err.(*wallet.KeyRotationError).Step)
+	})
+	t.Run("rotate: submit failed", func(t *testing.T) {
+		drill, chain, backend := newDrill(t)
+		chain.submitErr = errors.New("mempool is full")
+		report, err := drill.Run()
+		require.Error(t, err)
+		assert.Equal(t, wallet.StepRotate, err.(*wallet.KeyRotationError).Step)
+		_, err = backend.Signer(report.OldKeyID)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package wallet
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"sync"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemsigner"
+)
+
+// KeyBackend manages wallet signing keys, e.g. a KMS / HSM backed implementation.
+// Private keys never leave the backend; keys are referenced by key id, and messages are signed
+// by the `diemsigner.Signer` of a key.
+type KeyBackend interface {
+	GenerateKey() (keyID string, err error)
+	Signer(keyID string) (diemsigner.Signer, error)
+	Revoke(keyID string) error
+}
+
+// UnknownKeyError is returned when the key id is unknown or revoked
+type UnknownKeyError struct {
+	KeyID string
+}
+
+// Error implements error interface
+func (e *UnknownKeyError) Error() string {
+	return fmt.Sprintf("unknown or revoked key: %s", e.KeyID)
+}
+
+// MemoryKeyBackend is an in-memory `KeyBackend` for testing and development
+type MemoryKeyBackend struct {
+	mux  sync.Mutex
+	keys map[string]*diemkeys.Keys
+}
+
+// NewMemoryKeyBackend creates `MemoryKeyBackend`
+func NewMemoryKeyBackend() *MemoryKeyBackend {
+	return &MemoryKeyBackend{keys: make(map[string]*diemkeys.Keys)}
+}
+
+// Import adds existing keys, returns key id
+func (b *MemoryKeyBackend) Import(keys *diemkeys.Keys) string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	keyID := keys.AuthKey().Hex()
+	b.keys[keyID] = keys
+	return keyID
+}
+
+// GenerateKey implements `KeyBackend`, key id is the hex-encoded authentication key
+func (b *MemoryKeyBackend) GenerateKey() (string, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return b.Import(diemkeys.NewKeysFromPublicAndPrivateKeys(
+		diemkeys.NewEd25519PublicKey(public),
+		diemkeys.NewEd25519PrivateKey(private),
+	)), nil
+}
+
+// Signer implements `KeyBackend`, the signer fails with `UnknownKeyError` after the key is
+// revoked.
+func (b *MemoryKeyBackend) Signer(keyID string) (diemsigner.Signer, error) {
+	keys, err := b.get(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &memorySigner{backend: b, keyID: keyID, publicKey: keys.PublicKey}, nil
+}
+
+// Revoke implements `KeyBackend`
+func (b *MemoryKeyBackend) Revoke(keyID string) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if _, ok := b.keys[keyID]; !ok {
+		return &UnknownKeyError{keyID}
+	}
+	delete(b.keys, keyID)
+	return nil
+}
+
+func (b *MemoryKeyBackend) get(keyID string) (*diemkeys.Keys, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	keys, ok := b.keys[keyID]
+	if !ok {
+		return nil, &UnknownKeyError{keyID}
+	}
+	return keys, nil
+}
+
+type memorySigner struct {
+	backend   *MemoryKeyBackend
+	keyID     string
+	publicKey diemkeys.PublicKey
+}
+
+func (s *memorySigner) SignMessage(ctx context.Context, msg []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	keys, err := s.backend.get(s.keyID)
+	if err != nil {
+		return nil, err
+	}
+	return keys.PrivateKey.Sign(msg), nil
+}
+
+func (s *memorySigner) PublicKey() diemkeys.PublicKey {
+	return s.publicKey
+}
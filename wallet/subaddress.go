@@ -0,0 +1,185 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package wallet
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// SubAddressStatus is status of an allocated deposit sub-address
+type SubAddressStatus string
+
+const (
+	// SubAddressActive sub-address can receive deposit
+	SubAddressActive SubAddressStatus = "active"
+	// SubAddressUsed single-use sub-address received a deposit
+	SubAddressUsed SubAddressStatus = "used"
+	// SubAddressExpired sub-address is expired
+	SubAddressExpired SubAddressStatus = "expired"
+)
+
+// SubAddressPolicy configures lifecycle of allocated sub-addresses.
+// Zero TTL means sub-address never expires.
+type SubAddressPolicy struct {
+	TTL       time.Duration
+	SingleUse bool
+}
+
+// SubAddressRecord is an allocated sub-address and its lifecycle state
+type SubAddressRecord struct {
+	SubAddress  diemtypes.SubAddress
+	Status      SubAddressStatus
+	AllocatedAt time.Time
+	// ExpiresAt is zero if the sub-address never expires
+	ExpiresAt time.Time
+	UsedAt    time.Time
+}
+
+// UnknownSubAddressError is returned when sub-address is not allocated by the `SubAddresses`
+type UnknownSubAddressError struct {
+	SubAddress diemtypes.SubAddress
+}
+
+// Error implements error interface
+func (e *UnknownSubAddressError) Error() string {
+	return fmt.Sprintf("unknown sub-address: %s", e.SubAddress.Hex())
+}
+
+// InactiveSubAddressError is returned when claiming a sub-address that is expired or used
+type InactiveSubAddressError struct {
+	SubAddress diemtypes.SubAddress
+	Status     SubAddressStatus
+}
+
+// Error implements error interface
+func (e *InactiveSubAddressError) Error() string {
+	return fmt.Sprintf("sub-address %s is %s", e.SubAddress.Hex(), e.Status)
+}
+
+// SubAddresses allocates deposit sub-addresses and tracks their lifecycle.
+// A sub-address is never re-issued once allocated, even after it is expired or used.
+type SubAddresses struct {
+	Policy SubAddressPolicy
+
+	mux     sync.Mutex
+	records map[diemtypes.SubAddress]*SubAddressRecord
+	gen     func() (diemtypes.SubAddress, error)
+	now     func() time.Time
+}
+
+// NewSubAddresses creates `SubAddresses` with given policy
+func NewSubAddresses(policy SubAddressPolicy) *SubAddresses {
+	return &SubAddresses{
+		Policy:  policy,
+		records: make(map[diemtypes.SubAddress]*SubAddressRecord),
+		gen:     diemtypes.GenSubAddress,
+		now:     time.Now,
+	}
+}
+
+// Allocate generates a new sub-address that was never issued before
+func (s *SubAddresses) Allocate() (*SubAddressRecord, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for {
+		subAddress, err := s.gen()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.records[subAddress]; ok || subAddress == diemtypes.EmptySubAddress {
+			continue
+		}
+		now := s.now()
+		record := &SubAddressRecord{SubAddress: subAddress, Status: SubAddressActive, AllocatedAt: now}
+		if s.Policy.TTL > 0 {
+			record.ExpiresAt = now.Add(s.Policy.TTL)
+		}
+		s.records[subAddress] = record
+		copy := *record
+		return &copy, nil
+	}
+}
+
+// Get returns sub-address record with up-to-date status, returns `UnknownSubAddressError`
+// if sub-address is not allocated.
+func (s *SubAddresses) Get(subAddress diemtypes.SubAddress) (*SubAddressRecord, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	record, err := s.get(subAddress)
+	if err != nil {
+		return nil, err
+	}
+	copy := *record
+	return &copy, nil
+}
+
+// MarkUsed marks sub-address used, it is expired if the policy is single use.
+func (s *SubAddresses) MarkUsed(subAddress diemtypes.SubAddress) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	record, err := s.get(subAddress)
+	if err != nil {
+		return err
+	}
+	if record.UsedAt.IsZero() {
+		record.UsedAt = s.now()
+	}
+	if s.Policy.SingleUse && record.Status == SubAddressActive {
+		record.Status = SubAddressUsed
+	}
+	return nil
+}
+
+// Claim checks the sub-address is active and marks it used in one step, so that a single-use
+// sub-address is claimed by one deposit only. Returns `InactiveSubAddressError` if the
+// sub-address is expired or used, `UnknownSubAddressError` if it is not allocated.
+func (s *SubAddresses) Claim(subAddress diemtypes.SubAddress) (*SubAddressRecord, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	record, err := s.get(subAddress)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != SubAddressActive {
+		return nil, &InactiveSubAddressError{SubAddress: subAddress, Status: record.Status}
+	}
+	if record.UsedAt.IsZero() {
+		record.UsedAt = s.now()
+	}
+	if s.Policy.SingleUse {
+		record.Status = SubAddressUsed
+	}
+	copy := *record
+	return &copy, nil
+}
+
+// MarkExpired expires sub-address immediately
+func (s *SubAddresses) MarkExpired(subAddress diemtypes.SubAddress) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	record, err := s.get(subAddress)
+	if err != nil {
+		return err
+	}
+	if record.Status == SubAddressActive {
+		record.Status = SubAddressExpired
+		record.ExpiresAt = s.now()
+	}
+	return nil
+}
+
+func (s *SubAddresses) get(subAddress diemtypes.SubAddress) (*SubAddressRecord, error) {
+	record, ok := s.records[subAddress]
+	if !ok {
+		return nil, &UnknownSubAddressError{subAddress}
+	}
+	if record.Status == SubAddressActive && !record.ExpiresAt.IsZero() && !s.now().Before(record.ExpiresAt) {
+		record.Status = SubAddressExpired
+	}
+	return record, nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package wallet_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemamount"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type refunder struct {
+	refunded []wallet.Deposit
+	err      error
+}
+
+func (r *refunder) Refund(deposit wallet.Deposit, reason diemtypes.RefundReason) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.refunded = append(r.refunded, deposit)
+	return nil
+}
+
+func TestSubAddressLifecycle(t *testing.T) {
+	subAddresses := wallet.NewSubAddresses(wallet.SubAddressPolicy{SingleUse: true})
+	record, err := subAddresses.Allocate()
+	require.NoError(t, err)
+	assert.Equal(t, wallet.SubAddressActive, record.Status)
+	assert.True(t, record.ExpiresAt.IsZero())
+
+	require.NoError(t, subAddresses.MarkUsed(record.SubAddress))
+	record, err = subAddresses.Get(record.SubAddress)
+	require.NoError(t, err)
+	assert.Equal(t, wallet.SubAddressUsed, record.Status)
+	assert.False(t, record.UsedAt.IsZero())
+
+	_, err = subAddresses.Get(diemtypes.MustGenSubAddress())
+	assert.IsType(t, &wallet.UnknownSubAddressError{}, err)
+}
+
+func TestSubAddressExpiry(t *testing.T) {
+	subAddresses := wallet.NewSubAddresses(wallet.SubAddressPolicy{TTL: time.Nanosecond})
+	record, err := subAddresses.Allocate()
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	record, err = subAddresses.Get(record.SubAddress)
+	require.NoError(t, err)
+	assert.Equal(t, wallet.SubAddressExpired, record.Status)
+
+	subAddresses = wallet.NewSubAddresses(wallet.SubAddressPolicy{})
+	record, err = subAddresses.Allocate()
+	require.NoError(t, err)
+	require.NoError(t, subAddresses.MarkExpired(record.SubAddress))
+	record, err = subAddresses.Get(record.SubAddress)
+	require.NoError(t, err)
+	assert.Equal(t, wallet.SubAddressExpired, record.Status)
+}
+
+func TestDepositRouter(t *testing.T) {
+	subAddresses := wallet.NewSubAddresses(wallet.SubAddressPolicy{SingleUse: true})
+	record, err := subAddresses.Allocate()
+	require.NoError(t, err)
+	deposit := wallet.Deposit{
+		Version:    1,
+		SubAddress: record.SubAddress,
+		Amount:     diemamount.New(diemamount.XUS, 100),
+	}
+
+	router := wallet.NewDepositRouter(subAddresses)
+	assertRoute(t, router, deposit, wallet.DepositCredited)
+
+	deposit.Version = 2
+	assertRoute(t, router, deposit, wallet.DepositInReview)
+	queue := router.ReviewQueue()
+	require.Len(t, queue, 1)
+	assert.Equal(t, "used", queue[0].Reason)
+	assert.True(t, router.Resolve(2))
+	assert.Empty(t, router.ReviewQueue())
+
+	refund := &refunder{}
+	router.WithAutoRefund(refund)
+	deposit.Version = 3
+	assertRoute(t, router, deposit, wallet.DepositRefunded)
+	assert.Len(t, refund.refunded, 1)
+
+	refund.err = errors.New("submit failed")
+	deposit.Version = 4
+	assertRoute(t, router, deposit, wallet.DepositInReview)
+	queue = router.ReviewQueue()
+	require.Len(t, queue, 1)
+	assert.EqualError(t, queue[0].RefundError, "submit failed")
+}
+
+func TestSubAddressesClaim(t *testing.T) {
+	subAddresses := wallet.NewSubAddresses(wallet.SubAddressPolicy{SingleUse: true})
+	record, err := subAddresses.Allocate()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var claimed int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := subAddresses.Claim(record.SubAddress); err == nil {
+				atomic.AddInt32(&claimed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), claimed)
+
+	_, err = subAddresses.Claim(record.SubAddress)
+	assert.EqualError(t, err, "sub-address "+record.SubAddress.Hex()+" is used")
+	_, err = subAddresses.Claim(diemtypes.SubAddress{1})
+	assert.IsType(t, &wallet.UnknownSubAddressError{}, err)
+}
+
+func assertRoute(t *testing.T, router *wallet.DepositRouter, deposit wallet.Deposit, expected wallet.DepositOutcome) {
+	outcome, err := router.Route(deposit)
+	require.NoError(t, err)
+	assert.Equal(t, expected, outcome)
+}
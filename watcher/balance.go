@@ -0,0 +1,198 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package watcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+// DefaultPollInterval is default interval of polling accounts
+const DefaultPollInterval = time.Second
+
+// BalanceChange represents an account balance change of a currency
+type BalanceChange struct {
+	Address  diemtypes.AccountAddress
+	Currency string
+	Before   uint64
+	After    uint64
+	// Version is the ledger version of the account state that the change is observed
+	Version uint64
+}
+
+// BalanceCallback is called with a balance change
+type BalanceCallback func(BalanceChange)
+
+// BalanceWatcher polls a set of accounts and emits balance changes.
+// The first poll of an account records its balances without emitting changes.
+// Changes are deduplicated: an account state older than the last observed version, e.g. a
+// response from a lagging full node, is ignored instead of emitting a change back and forth.
+type BalanceWatcher struct {
+	client    diemclient.Client
+	addresses []diemtypes.AccountAddress
+	interval  time.Duration
+	alerts    diemclient.Alerts
+	callbacks []currencyCallback
+
+	mux      sync.Mutex
+	balances map[diemtypes.AccountAddress]map[string]uint64
+	frozen   map[diemtypes.AccountAddress]bool
+	versions map[diemtypes.AccountAddress]uint64
+}
+
+type currencyCallback struct {
+	// currency is empty for callback of all currencies
+	currency string
+	fn       BalanceCallback
+}
+
+// NewBalanceWatcher creates `BalanceWatcher` for given addresses, polls with `DefaultPollInterval`
+func NewBalanceWatcher(client diemclient.Client, addresses ...diemtypes.AccountAddress) *BalanceWatcher {
+	return &BalanceWatcher{
+		client:    client,
+		addresses: addresses,
+		interval:  DefaultPollInterval,
+		balances:  make(map[diemtypes.AccountAddress]map[string]uint64),
+		frozen:    make(map[diemtypes.AccountAddress]bool),
+		versions:  make(map[diemtypes.AccountAddress]uint64),
+	}
+}
+
+// WithInterval sets poll interval
+func (w *BalanceWatcher) WithInterval(interval time.Duration) *BalanceWatcher {
+	w.interval = interval
+	return w
+}
+
+// WithAlerts sets `diemclient.Alerts` for receiving `AccountFrozen` alert when a watched account
+// is found frozen.
+func (w *BalanceWatcher) WithAlerts(alerts diemclient.Alerts) *BalanceWatcher {
+	w.alerts = alerts
+	return w
+}
+
+// WithCallback adds callback that is called for every balance change emitted by `Poll` and `Run`
+func (w *BalanceWatcher) WithCallback(fn BalanceCallback) *BalanceWatcher {
+	w.callbacks = append(w.callbacks, currencyCallback{fn: fn})
+	return w
+}
+
+// WithCurrencyCallback adds callback that is called for balance changes of the given currency
+func (w *BalanceWatcher) WithCurrencyCallback(currency string, fn BalanceCallback) *BalanceWatcher {
+	w.callbacks = append(w.callbacks, currencyCallback{currency: currency, fn: fn})
+	return w
+}
+
+// Balance returns last known balance of the given account and currency
+func (w *BalanceWatcher) Balance(address diemtypes.AccountAddress, currency string) (uint64, bool) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	balances, ok := w.balances[address]
+	if !ok {
+		return 0, false
+	}
+	amount, ok := balances[currency]
+	return amount, ok
+}
+
+// Poll gets all watched accounts once, calls callbacks and returns balance changes since last
+// poll. It returns first error of getting account, changes of other accounts are still returned.
+func (w *BalanceWatcher) Poll() ([]BalanceChange, error) {
+	var changes []BalanceChange
+	var firstErr error
+	for _, address := range w.addresses {
+		account, err := w.client.GetAccount(address)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		changes = append(changes, w.update(address, account)...)
+	}
+	for _, change := range changes {
+		for _, callback := range w.callbacks {
+			if callback.currency == "" || callback.currency == change.Currency {
+				callback.fn(change)
+			}
+		}
+	}
+	return changes, firstErr
+}
+
+// Run polls accounts by interval and sends balance changes into given channel until the
+// context is done. The channel can be nil if changes are consumed by callbacks only.
+// Errors of polling are ignored, and retried in next poll.
+func (w *BalanceWatcher) Run(ctx context.Context, changes chan<- BalanceChange) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		ret, _ := w.Poll()
+		if changes == nil {
+			ret = nil
+		}
+		for _, change := range ret {
+			select {
+			case changes <- change:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *BalanceWatcher) update(address diemtypes.AccountAddress, account *diemclient.Account) []BalanceChange {
+	current := make(map[string]uint64)
+	var version uint64
+	var frozen bool
+	if account != nil {
+		for _, b := range account.Balances {
+			current[b.Currency] = b.Amount
+		}
+		version = account.Version
+		frozen = account.IsFrozen
+	}
+
+	w.mux.Lock()
+	if version != 0 && version < w.versions[address] {
+		w.mux.Unlock()
+		return nil
+	}
+	w.versions[address] = version
+	previous, known := w.balances[address]
+	w.balances[address] = current
+	wasFrozen := w.frozen[address]
+	w.frozen[address] = frozen
+	w.mux.Unlock()
+
+	if frozen && !wasFrozen && w.alerts != nil {
+		w.alerts.AccountFrozen(address)
+	}
+	if !known {
+		return nil
+	}
+	var changes []BalanceChange
+	for currency, after := range current {
+		if before := previous[currency]; before != after {
+			changes = append(changes, BalanceChange{
+				Address: address, Currency: currency, Before: before, After: after, Version: version})
+		}
+	}
+	for currency, before := range previous {
+		if _, ok := current[currency]; !ok && before != 0 {
+			changes = append(changes, BalanceChange{
+				Address: address, Currency: currency, Before: before, After: 0, Version: version})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,137 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package watcher_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/jsonrpc"
+	"github.com/diem/client-sdk-go/jsonrpc/jsonrpctest"
+	"github.com/diem/client-sdk-go/testnet"
+	"github.com/diem/client-sdk-go/watcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceWatcherPoll(t *testing.T) {
+	stub := &jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{}}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub).WithRetryOptions(retry.Attempts(1))
+	address := diemkeys.MustGenKeys().AccountAddress()
+	alerts := &frozenAlerts{}
+	w := watcher.NewBalanceWatcher(client, address).WithAlerts(alerts)
+
+	stub.Responses[1] = accountResponse(`[{"amount": 100, "currency": "XUS"}]`, false)
+	changes, err := w.Poll()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	balance, ok := w.Balance(address, "XUS")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(100), balance)
+
+	stub.Responses[1] = accountResponse(`[{"amount": 80, "currency": "XUS"}, {"amount": 5, "currency": "XDX"}]`, true)
+	changes, err = w.Poll()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []watcher.BalanceChange{
+		{Address: address, Currency: "XUS", Before: 100, After: 80, Version: 12},
+		{Address: address, Currency: "XDX", Before: 0, After: 5, Version: 12},
+	}, changes)
+	assert.Equal(t, []diemtypes.AccountAddress{address}, alerts.frozen)
+
+	changes, err = w.Poll()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Len(t, alerts.frozen, 1)
+}
+
+func TestBalanceWatcherRun(t *testing.T) {
+	stub := &jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{
+		1: accountResponse(`[{"amount": 100, "currency": "XUS"}]`, false),
+	}}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub).WithRetryOptions(retry.Attempts(1))
+	address := diemkeys.MustGenKeys().AccountAddress()
+	w := watcher.NewBalanceWatcher(client, address).WithInterval(time.Millisecond)
+	_, err := w.Poll()
+	require.NoError(t, err)
+	stub.Responses[1] = accountResponse(`[{"amount": 200, "currency": "XUS"}]`, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	changes := make(chan watcher.BalanceChange)
+	go w.Run(ctx, changes)
+	select {
+	case change := <-changes:
+		assert.Equal(t, uint64(100), change.Before)
+		assert.Equal(t, uint64(200), change.After)
+	case <-ctx.Done():
+		assert.Fail(t, "timeout")
+	}
+}
+
+func TestBalanceWatcherCallbacks(t *testing.T) {
+	stub := &jsonrpctest.Stub{Responses: map[jsonrpc.RequestID]jsonrpc.Response{}}
+	client := diemclient.NewWithJsonRpcClient(testnet.ChainID, stub).WithRetryOptions(retry.Attempts(1))
+	address := diemkeys.MustGenKeys().AccountAddress()
+	var all, xus []watcher.BalanceChange
+	w := watcher.NewBalanceWatcher(client, address).
+		WithCallback(func(change watcher.BalanceChange) { all = append(all, change) }).
+		WithCurrencyCallback("XUS", func(change watcher.BalanceChange) { xus = append(xus, change) })
+
+	stub.Responses[1] = accountResponseAt(`[{"amount": 100, "currency": "XUS"}]`, false, 10)
+	_, err := w.Poll()
+	require.NoError(t, err)
+	stub.Responses[1] = accountResponseAt(`[{"amount": 80, "currency": "XUS"}, {"amount": 5, "currency": "XDX"}]`, false, 20)
+	_, err = w.Poll()
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.Equal(t, []watcher.BalanceChange{
+		{Address: address, Currency: "XUS", Before: 100, After: 80, Version: 20},
+	}, xus)
+
+	// stale account state from a lagging full node is ignored
+	stub.Responses[1] = accountResponseAt(`[{"amount": 100, "currency": "XUS"}]`, false, 15)
+	changes, err := w.Poll()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	balance, _ := w.Balance(address, "XUS")
+	assert.Equal(t, uint64(80), balance)
+
+	stub.Responses[1] = accountResponseAt(`[{"amount": 80, "currency": "XUS"}, {"amount": 5, "currency": "XDX"}]`, false, 20)
+	changes, err = w.Poll()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Len(t, all, 2)
+	assert.Len(t, xus, 1)
+}
+
+func accountResponse(balances string, frozen bool) jsonrpc.Response {
+	return accountResponseAt(balances, frozen, 12)
+}
+
+func accountResponseAt(balances string, frozen bool, version uint64) jsonrpc.Response {
+	msg := json.RawMessage(fmt.Sprintf(`{
+  "address": "f72589b71ff4f8d139674a3f7369c69b",
+  "balances": %s,
+  "is_frozen": %v,
+  "sequence_number": 1,
+  "version": %d
+}`, balances, frozen, version))
+	return jsonrpc.Response{Result: &msg}
+}
+
+type frozenAlerts struct {
+	diemclient.LogAlerts
+	frozen []diemtypes.AccountAddress
+}
+
+func (a *frozenAlerts) AccountFrozen(address diemtypes.AccountAddress) {
+	a.frozen = append(a.frozen, address)
+}
@@ -0,0 +1,325 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/events"
+)
+
+// Dispatcher defaults
+const (
+	DefaultMaxAttempts = 10
+	DefaultBatchSize   = 100
+	DefaultInterval    = time.Second
+	DefaultTimeout     = 10 * time.Second
+	// DefaultBackoff and DefaultMaxBackoff are the base and max delay of the exponential
+	// backoff of retrying failed deliveries
+	DefaultBackoff    = 10 * time.Second
+	DefaultMaxBackoff = time.Hour
+)
+
+// Subscription subscribes events by type and account
+type Subscription struct {
+	ID  string
+	URL string
+	// Secret signs the payloads, the receiver verifies them by `Verify` with the same secret
+	Secret []byte
+	// EventTypes filters events by event data type, e.g. "receivedpayment"; empty for all types
+	EventTypes []string
+	// Accounts filters events by the sender or receiver of the event data; empty for all
+	// events
+	Accounts []diemtypes.AccountAddress
+}
+
+// Matches returns true if the event matches the subscription filters
+func (s *Subscription) Matches(event *diemclient.Event) bool {
+	if event.Data == nil {
+		return len(s.EventTypes) == 0 && len(s.Accounts) == 0
+	}
+	if len(s.EventTypes) > 0 && !contains(s.EventTypes, event.Data.Type) {
+		return false
+	}
+	if len(s.Accounts) == 0 {
+		return true
+	}
+	for _, account := range s.Accounts {
+		if strings.EqualFold(account.Hex(), event.Data.Sender) ||
+			strings.EqualFold(account.Hex(), event.Data.Receiver) {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON request body of a webhook
+type Payload struct {
+	DeliveryID     string            `json:"delivery_id"`
+	SubscriptionID string            `json:"subscription_id"`
+	EventType      string            `json:"event_type"`
+	Event          *diemclient.Event `json:"event"`
+}
+
+// Dispatcher dispatches events to subscriptions and delivers them. Create it by `New`, then
+// run `Consume` for event streams and `Run` for delivering in background.
+//
+// Events are matched with subscriptions by event type and account, and POSTed as JSON payloads
+// signed by HMAC-SHA256 of the subscription secret. Deliveries are persisted in the `Store`
+// before they are sent; failed deliveries are retried with backoff, and moved to dead letters
+// after `MaxAttempts` attempts, which can be redelivered by `Redeliver`.
+type Dispatcher struct {
+	Store Store
+	HTTP  *http.Client
+	// MaxAttempts is the max number of attempts of a delivery before it is dead
+	MaxAttempts int
+	// Backoff returns delay before the next attempt of a failed delivery
+	Backoff diemclient.BackoffFunc
+	// BatchSize is max number of due deliveries sent by each `Deliver` call
+	BatchSize int
+	// Interval is the interval of delivering due deliveries by `Run`
+	Interval time.Duration
+	// OnDeadLetter is called when a delivery is dead
+	OnDeadLetter func(*Delivery)
+	// OnError is called when a poll of events stream or a delivery attempt failed
+	OnError func(error)
+
+	mux           sync.RWMutex
+	subscriptions map[string]*Subscription
+	now           func() time.Time
+}
+
+// New creates `Dispatcher` with the deliveries store and default settings
+func New(store Store) *Dispatcher {
+	return &Dispatcher{
+		Store:         store,
+		HTTP:          &http.Client{Timeout: DefaultTimeout},
+		MaxAttempts:   DefaultMaxAttempts,
+		Backoff:       diemclient.ExponentialBackoff(DefaultBackoff, DefaultMaxBackoff),
+		BatchSize:     DefaultBatchSize,
+		Interval:      DefaultInterval,
+		subscriptions: make(map[string]*Subscription),
+		now:           time.Now,
+	}
+}
+
+// Subscribe adds or replaces the subscription by its id
+func (d *Dispatcher) Subscribe(subscription Subscription) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.subscriptions[subscription.ID] = &subscription
+}
+
+// Unsubscribe removes the subscription, its pending deliveries are dead at next attempt
+func (d *Dispatcher) Unsubscribe(id string) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	delete(d.subscriptions, id)
+}
+
+// Dispatch saves a pending delivery of the event for each matching subscription; an event
+// already dispatched to a subscription is skipped.
+func (d *Dispatcher) Dispatch(event *diemclient.Event) error {
+	d.mux.RLock()
+	var matches []*Subscription
+	for _, subscription := range d.subscriptions {
+		if subscription.Matches(event) {
+			matches = append(matches, subscription)
+		}
+	}
+	d.mux.RUnlock()
+
+	for _, subscription := range matches {
+		id := fmt.Sprintf("%s:%s:%d", subscription.ID, event.Key, event.SequenceNumber)
+		existing, err := d.Store.Get(id)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		payload := Payload{DeliveryID: id, SubscriptionID: subscription.ID, Event: event}
+		if event.Data != nil {
+			payload.EventType = event.Data.Type
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encode webhook payload failed: %w", err)
+		}
+		err = d.Store.Put(&Delivery{
+			ID:             id,
+			SubscriptionID: subscription.ID,
+			URL:            subscription.URL,
+			Payload:        body,
+			Status:         StatusPending,
+			NextAttemptAt:  d.now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Consume polls events from the stream and dispatches them until the context is done.
+// The stream cursor moves forward after the event is dispatched; save `Stream.Cursor()` for
+// resuming, an event dispatched twice is delivered once.
+// Failed polls are reported to `OnError` and retried after the stream interval. Returns the
+// context error, or the dispatch error with the stream cursor at the failed event.
+func (d *Dispatcher) Consume(ctx context.Context, stream *events.Stream) error {
+	for {
+		batch, err := stream.Poll(ctx)
+		if err != nil && ctx.Err() == nil && d.OnError != nil {
+			d.OnError(err)
+		}
+		for _, event := range batch {
+			if err := d.Dispatch(event); err != nil {
+				stream.Seek(event.SequenceNumber)
+				return err
+			}
+		}
+		if err == nil && uint64(len(batch)) == stream.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(stream.Interval):
+		}
+	}
+}
+
+// Run delivers due deliveries every `Interval` until the context is done, returns the context
+// error. Store errors are reported to `OnError`.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		if _, err := d.Deliver(ctx); err != nil && ctx.Err() == nil && d.OnError != nil {
+			d.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d.Interval):
+		}
+	}
+}
+
+// Deliver sends a batch of due deliveries, returns number of deliveries delivered.
+// Failed deliveries are rescheduled by `Backoff`, or dead after `MaxAttempts` attempts.
+func (d *Dispatcher) Deliver(ctx context.Context) (int, error) {
+	due, err := d.Store.Due(d.now(), d.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		err := d.send(ctx, delivery)
+		delivery.Attempts++
+		switch {
+		case err == nil:
+			delivery.Status = StatusDelivered
+			delivery.LastError = ""
+			delivered++
+		case ctx.Err() != nil:
+			// canceled attempt is not counted
+			return delivered, ctx.Err()
+		default:
+			delivery.LastError = err.Error()
+			if d.OnError != nil {
+				d.OnError(fmt.Errorf("webhook delivery %s failed: %w", delivery.ID, err))
+			}
+			if _, ok := err.(*subscriptionNotFoundError); ok || delivery.Attempts >= d.MaxAttempts {
+				delivery.Status = StatusDead
+			} else {
+				delivery.NextAttemptAt = d.now().Add(d.backoff(delivery.Attempts))
+			}
+		}
+		if err := d.Store.Put(delivery); err != nil {
+			return delivered, err
+		}
+		if delivery.Status == StatusDead && d.OnDeadLetter != nil {
+			d.OnDeadLetter(delivery)
+		}
+	}
+	return delivered, nil
+}
+
+// Redeliver moves the dead delivery back to pending for delivering by next `Deliver`
+func (d *Dispatcher) Redeliver(id string) error {
+	delivery, err := d.Store.Get(id)
+	if err != nil {
+		return err
+	}
+	if delivery == nil || delivery.Status != StatusDead {
+		return fmt.Errorf("dead delivery %s not found", id)
+	}
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = d.now()
+	return d.Store.Put(delivery)
+}
+
+type subscriptionNotFoundError struct {
+	id string
+}
+
+func (e *subscriptionNotFoundError) Error() string {
+	return fmt.Sprintf("subscription %s not found", e.id)
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *Delivery) error {
+	d.mux.RLock()
+	subscription := d.subscriptions[delivery.SubscriptionID]
+	d.mux.RUnlock()
+	if subscription == nil {
+		return &subscriptionNotFoundError{delivery.SubscriptionID}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryIDHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, d.now(), delivery.Payload))
+	resp, err := d.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	if d.Backoff == nil {
+		return 0
+	}
+	return d.Backoff(uint(attempts - 1))
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
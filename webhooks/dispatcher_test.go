@@ -0,0 +1,278 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/diemclient"
+	"github.com/diem/client-sdk-go/diemclient/diemclienttest"
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+	"github.com/diem/client-sdk-go/events"
+	"github.com/diem/client-sdk-go/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receiver struct {
+	*httptest.Server
+	mux      sync.Mutex
+	status   int
+	payloads []*webhooks.Payload
+	errors   []error
+}
+
+func newReceiver(secret []byte) *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mux.Lock()
+		defer r.mux.Unlock()
+		body, _ := ioutil.ReadAll(req.Body)
+		err := webhooks.Verify(secret, req.Header.Get(webhooks.SignatureHeader), body, time.Now(), time.Minute)
+		if err != nil {
+			r.errors = append(r.errors, err)
+		}
+		if r.status != http.StatusOK {
+			w.WriteHeader(r.status)
+			return
+		}
+		var payload webhooks.Payload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			r.errors = append(r.errors, err)
+		}
+		if id := req.Header.Get(webhooks.DeliveryIDHeader); id != payload.DeliveryID {
+			r.errors = append(r.errors, fmt.Errorf("unexpected delivery id header: %s", id))
+		}
+		r.payloads = append(r.payloads, &payload)
+	}))
+	return r
+}
+
+func (r *receiver) respond(status int) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.status = status
+}
+
+func (r *receiver) received() []*webhooks.Payload {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return append([]*webhooks.Payload(nil), r.payloads...)
+}
+
+func event(seq uint64, typ string, sender, receiver diemtypes.AccountAddress) *diemclient.Event {
+	return &diemclient.Event{
+		Key:            "key",
+		SequenceNumber: seq,
+		Data: &diemclient.EventData{
+			Type:     typ,
+			Sender:   sender.Hex(),
+			Receiver: receiver.Hex(),
+		},
+	}
+}
+
+func newDispatcher() (*webhooks.Dispatcher, *webhooks.MemoryStore) {
+	store := webhooks.NewMemoryStore()
+	d := webhooks.New(store)
+	d.Backoff = nil
+	return d, store
+}
+
+func TestDispatch(t *testing.T) {
+	alice := diemkeys.MustGenKeys().AccountAddress()
+	bob := diemkeys.MustGenKeys().AccountAddress()
+	d, store := newDispatcher()
+	d.Subscribe(webhooks.Subscription{ID: "all", URL: "http://all"})
+	d.Subscribe(webhooks.Subscription{ID: "received", URL: "http://received", EventTypes: []string{"receivedpayment"}})
+	d.Subscribe(webhooks.Subscription{ID: "alice", URL: "http://alice", Accounts: []diemtypes.AccountAddress{alice}})
+
+	require.NoError(t, d.Dispatch(event(0, "receivedpayment", bob, alice)))
+	require.NoError(t, d.Dispatch(event(1, "sentpayment", bob, bob)))
+	require.NoError(t, d.Dispatch(event(2, "receivedpayment", bob, bob)))
+	require.NoError(t, d.Dispatch(event(2, "receivedpayment", bob, bob)), "dispatched twice")
+
+	due, err := store.Due(time.Now(), 0)
+	require.NoError(t, err)
+	ids := []string{}
+	for _, delivery := range due {
+		ids = append(ids, delivery.ID)
+		assert.Equal(t, webhooks.StatusPending, delivery.Status)
+	}
+	assert.ElementsMatch(t, []string{
+		"all:key:0", "received:key:0", "alice:key:0",
+		"all:key:1",
+		"all:key:2", "received:key:2",
+	}, ids)
+
+	d.Unsubscribe("all")
+	require.NoError(t, d.Dispatch(event(3, "sentpayment", alice, bob)))
+	delivery, err := store.Get("alice:key:3")
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+	assert.Equal(t, "http://alice", delivery.URL)
+	delivery, err = store.Get("all:key:3")
+	require.NoError(t, err)
+	assert.Nil(t, delivery)
+}
+
+func TestDeliver(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte("secret")
+	alice := diemkeys.MustGenKeys().AccountAddress()
+
+	t.Run("delivered", func(t *testing.T) {
+		r := newReceiver(secret)
+		defer r.Close()
+		d, store := newDispatcher()
+		d.Subscribe(webhooks.Subscription{ID: "sub", URL: r.URL, Secret: secret})
+		require.NoError(t, d.Dispatch(event(0, "receivedpayment", alice, alice)))
+
+		n, err := d.Deliver(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		require.Len(t, r.received(), 1)
+		payload := r.received()[0]
+		assert.Equal(t, "sub:key:0", payload.DeliveryID)
+		assert.Equal(t, "sub", payload.SubscriptionID)
+		assert.Equal(t, "receivedpayment", payload.EventType)
+		assert.Equal(t, alice.Hex(), payload.Event.Data.Sender)
+		assert.Empty(t, r.errors)
+
+		delivery, err := store.Get("sub:key:0")
+		require.NoError(t, err)
+		assert.Equal(t, webhooks.StatusDelivered, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+
+		n, err = d.Deliver(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Len(t, r.received(), 1)
+	})
+	t.Run("retry and dead letter", func(t *testing.T) {
+		r := newReceiver(secret)
+		defer r.Close()
+		r.respond(http.StatusInternalServerError)
+		d, store := newDispatcher()
+		d.MaxAttempts = 3
+		var dead []*webhooks.Delivery
+		d.OnDeadLetter = func(delivery *webhooks.Delivery) { dead = append(dead, delivery) }
+		var errs []error
+		d.OnError = func(err error) { errs = append(errs, err) }
+		d.Subscribe(webhooks.Subscription{ID: "sub", URL: r.URL, Secret: secret})
+		require.NoError(t, d.Dispatch(event(0, "receivedpayment", alice, alice)))
+
+		for i := 1; i <= 2; i++ {
+			_, err := d.Deliver(ctx)
+			require.NoError(t, err)
+			delivery, err := store.Get("sub:key:0")
+			require.NoError(t, err)
+			assert.Equal(t, webhooks.StatusPending, delivery.Status)
+			assert.Equal(t, i, delivery.Attempts)
+			assert.Contains(t, delivery.LastError, "unexpected response status 500")
+		}
+		_, err := d.Deliver(ctx)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, webhooks.StatusDead, dead[0].Status)
+		assert.Len(t, errs, 3)
+		letters, err := store.DeadLetters(0)
+		require.NoError(t, err)
+		require.Len(t, letters, 1)
+		assert.Equal(t, "sub:key:0", letters[0].ID)
+
+		n, err := d.Deliver(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n, "dead letters are not retried")
+
+		r.respond(http.StatusOK)
+		require.NoError(t, d.Redeliver("sub:key:0"))
+		n, err = d.Deliver(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Len(t, r.received(), 1)
+		assert.Error(t, d.Redeliver("sub:key:0"), "delivered")
+	})
+	t.Run("backoff", func(t *testing.T) {
+		r := newReceiver(secret)
+		defer r.Close()
+		r.respond(http.StatusBadGateway)
+		d, store := newDispatcher()
+		d.Backoff = func(uint) time.Duration { return time.Hour }
+		d.Subscribe(webhooks.Subscription{ID: "sub", URL: r.URL, Secret: secret})
+		require.NoError(t, d.Dispatch(event(0, "receivedpayment", alice, alice)))
+
+		_, err := d.Deliver(ctx)
+		require.NoError(t, err)
+		due, err := store.Due(time.Now(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+		due, err = store.Due(time.Now().Add(time.Hour), 0)
+		require.NoError(t, err)
+		assert.Len(t, due, 1)
+	})
+	t.Run("unsubscribed", func(t *testing.T) {
+		d, store := newDispatcher()
+		d.Subscribe(webhooks.Subscription{ID: "sub", URL: "http://localhost"})
+		require.NoError(t, d.Dispatch(event(0, "receivedpayment", alice, alice)))
+		d.Unsubscribe("sub")
+
+		_, err := d.Deliver(ctx)
+		require.NoError(t, err)
+		delivery, err := store.Get("sub:key:0")
+		require.NoError(t, err)
+		assert.Equal(t, webhooks.StatusDead, delivery.Status)
+		assert.Equal(t, "subscription sub not found", delivery.LastError)
+	})
+}
+
+func TestConsumeAndRun(t *testing.T) {
+	secret := []byte("secret")
+	alice := diemkeys.MustGenKeys().AccountAddress()
+	r := newReceiver(secret)
+	defer r.Close()
+
+	server := diemclienttest.NewServer()
+	server.AddEvents("key",
+		event(0, "receivedpayment", alice, alice),
+		event(1, "sentpayment", alice, alice),
+		event(2, "receivedpayment", alice, alice))
+	stream := events.NewStream(server.Client(), "key", 0)
+	stream.Interval = 10 * time.Millisecond
+	stream.BatchSize = 2
+
+	d, _ := newDispatcher()
+	d.Interval = 10 * time.Millisecond
+	d.Subscribe(webhooks.Subscription{ID: "sub", URL: r.URL, Secret: secret, EventTypes: []string{"receivedpayment"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		assert.Equal(t, context.Canceled, d.Consume(ctx, stream))
+	}()
+	go func() {
+		defer wg.Done()
+		assert.Equal(t, context.Canceled, d.Run(ctx))
+	}()
+	require.Eventually(t, func() bool { return len(r.received()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	received := r.received()
+	assert.Equal(t, "sub:key:0", received[0].DeliveryID)
+	assert.Equal(t, "sub:key:2", received[1].DeliveryID)
+	assert.Equal(t, uint64(3), stream.Cursor())
+}
@@ -0,0 +1,90 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook request headers
+const (
+	// SignatureHeader is the header of payload signature: "t=<unix timestamp>,v1=<hex-encoded
+	// HMAC-SHA256 of "<unix timestamp>.<body>">"
+	SignatureHeader = "X-Diem-Webhook-Signature"
+	// DeliveryIDHeader is the header of delivery id, which is the same for retries of a
+	// delivery, receivers should use it for deduplication.
+	DeliveryIDHeader = "X-Diem-Webhook-Delivery"
+)
+
+// ErrInvalidSignature is returned by `Verify` for mismatched or expired signature
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns signature header value of the body signed by the secret at the timestamp
+func Sign(secret []byte, timestamp time.Time, body []byte) string {
+	t := timestamp.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", t, hex.EncodeToString(mac(secret, t, body)))
+}
+
+// Verify verifies the signature header value of the body by the secret; the signature
+// timestamp must be within the tolerance of now for preventing replay attacks, 0 tolerance
+// skips the timestamp check.
+// Returns `ErrInvalidSignature` if the signature does not match or it is expired.
+//
+//	body, _ := ioutil.ReadAll(r.Body)
+//	err := webhooks.Verify(secret, r.Header.Get(webhooks.SignatureHeader), body, time.Now(), 5*time.Minute)
+func Verify(secret []byte, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp int64
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("malformed webhook signature header: %#v", header)
+		}
+		switch kv[0] {
+		case "t":
+			t, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("malformed webhook signature timestamp: %#v", kv[1])
+			}
+			timestamp = t
+		case "v1":
+			sig, err := hex.DecodeString(kv[1])
+			if err != nil {
+				return fmt.Errorf("malformed webhook signature: %#v", kv[1])
+			}
+			signatures = append(signatures, sig)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return fmt.Errorf("malformed webhook signature header: %#v", header)
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrInvalidSignature
+		}
+	}
+	expected := mac(secret, timestamp, body)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func mac(secret []byte, timestamp int64, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
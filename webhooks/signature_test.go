@@ -0,0 +1,32 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/webhooks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"event_type":"receivedpayment"}`)
+	now := time.Unix(1600000000, 0)
+	header := webhooks.Sign(secret, now, body)
+
+	assert.NoError(t, webhooks.Verify(secret, header, body, now.Add(time.Minute), 5*time.Minute))
+
+	err := webhooks.Verify([]byte("other"), header, body, now, 5*time.Minute)
+	assert.True(t, errors.Is(err, webhooks.ErrInvalidSignature))
+	err = webhooks.Verify(secret, header, []byte("{}"), now, 5*time.Minute)
+	assert.True(t, errors.Is(err, webhooks.ErrInvalidSignature))
+	err = webhooks.Verify(secret, header, body, now.Add(time.Hour), 5*time.Minute)
+	assert.True(t, errors.Is(err, webhooks.ErrInvalidSignature), "expired")
+
+	err = webhooks.Verify(secret, "v1=ab", body, now, 5*time.Minute)
+	assert.EqualError(t, err, "malformed webhook signature header: \"v1=ab\"")
+}
@@ -0,0 +1,251 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/diem/client-sdk-go/internal/sqlutil"
+)
+
+// Status is status of a delivery
+type Status string
+
+// List of delivery statuses
+const (
+	// StatusPending is for the delivery is waiting for next attempt
+	StatusPending Status = "pending"
+	// StatusDelivered is for the receiver responded 2xx status code
+	StatusDelivered Status = "delivered"
+	// StatusDead is for the delivery failed after max attempts, or its subscription is removed
+	StatusDead Status = "dead"
+)
+
+// Delivery is a webhook payload to be POSTed to a subscription URL
+type Delivery struct {
+	// ID is "<subscription id>:<event key>:<event sequence number>", an event is delivered to a
+	// subscription once.
+	ID             string
+	SubscriptionID string
+	URL            string
+	// Payload is the JSON request body
+	Payload       []byte
+	Status        Status
+	Attempts      int
+	NextAttemptAt time.Time
+	// LastError is the error of the last failed attempt
+	LastError string
+}
+
+// Store persists deliveries by id
+type Store interface {
+	// Get returns delivery of given id, returns nil without error if not found
+	Get(id string) (*Delivery, error)
+	// Put saves the delivery, overwrites existing delivery of same id
+	Put(delivery *Delivery) error
+	// Due returns up to limit (0 for no limit) pending deliveries of which the next attempt time is not after
+	// the given time, ordered by next attempt time
+	Due(now time.Time, limit int) ([]*Delivery, error)
+	// DeadLetters returns up to limit (0 for no limit) dead deliveries
+	DeadLetters(limit int) ([]*Delivery, error)
+}
+
+// MemoryStore implements `Store` in memory, deliveries are lost after restart.
+type MemoryStore struct {
+	mux        sync.RWMutex
+	deliveries map[string]Delivery
+}
+
+// NewMemoryStore creates `MemoryStore`
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{deliveries: make(map[string]Delivery)}
+}
+
+// Get implements `Store`
+func (s *MemoryStore) Get(id string) (*Delivery, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	delivery, ok := s.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+	return &delivery, nil
+}
+
+// Put implements `Store`
+func (s *MemoryStore) Put(delivery *Delivery) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+// Due implements `Store`
+func (s *MemoryStore) Due(now time.Time, limit int) ([]*Delivery, error) {
+	ret := s.filter(func(d *Delivery) bool {
+		return d.Status == StatusPending && !d.NextAttemptAt.After(now)
+	})
+	sort.Slice(ret, func(i, j int) bool { return ret[i].NextAttemptAt.Before(ret[j].NextAttemptAt) })
+	return truncate(ret, limit), nil
+}
+
+// DeadLetters implements `Store`
+func (s *MemoryStore) DeadLetters(limit int) ([]*Delivery, error) {
+	ret := s.filter(func(d *Delivery) bool { return d.Status == StatusDead })
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	return truncate(ret, limit), nil
+}
+
+func (s *MemoryStore) filter(match func(*Delivery) bool) []*Delivery {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	var ret []*Delivery
+	for _, delivery := range s.deliveries {
+		d := delivery
+		if match(&d) {
+			ret = append(ret, &d)
+		}
+	}
+	return ret
+}
+
+func truncate(deliveries []*Delivery, limit int) []*Delivery {
+	if limit > 0 && len(deliveries) > limit {
+		return deliveries[:limit]
+	}
+	return deliveries
+}
+
+// SQLStore implements `Store` with a SQL database table:
+//
+//	CREATE TABLE <table> (
+//	  id VARCHAR(255) PRIMARY KEY,
+//	  subscription_id VARCHAR(255) NOT NULL,
+//	  url TEXT NOT NULL,
+//	  payload TEXT NOT NULL,
+//	  status VARCHAR(16) NOT NULL,
+//	  attempts INT NOT NULL,
+//	  next_attempt_at BIGINT NOT NULL,
+//	  last_error TEXT NOT NULL
+//	)
+//
+// `next_attempt_at` is unix timestamp in milliseconds. Call `CreateTable` to create the table
+// if it does not exist.
+type SQLStore struct {
+	DB    *sql.DB
+	Table string
+	// Placeholder returns bind parameter placeholder for the n-th (starts from 1) parameter,
+	// defaults to "?"; set `DollarPlaceholder` for PostgreSQL.
+	Placeholder func(n int) string
+}
+
+// DollarPlaceholder returns "$n" bind parameter placeholder
+var DollarPlaceholder = sqlutil.DollarPlaceholder
+
+// NewSQLStore creates `SQLStore` with given db and table name
+func NewSQLStore(db *sql.DB, table string) *SQLStore {
+	return &SQLStore{DB: db, Table: table}
+}
+
+// CreateTable creates the deliveries table and the index of pending deliveries if they do not
+// exist
+func (s *SQLStore) CreateTable() error {
+	_, err := s.DB.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (id VARCHAR(255) PRIMARY KEY, subscription_id VARCHAR(255) NOT NULL, url TEXT NOT NULL, payload TEXT NOT NULL, status VARCHAR(16) NOT NULL, attempts INT NOT NULL, next_attempt_at BIGINT NOT NULL, last_error TEXT NOT NULL)",
+		s.Table))
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.Exec(fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s_status_next_attempt_at ON %s (status, next_attempt_at)",
+		s.Table, s.Table))
+	return err
+}
+
+const sqlColumns = "id, subscription_id, url, payload, status, attempts, next_attempt_at, last_error"
+
+// Get implements `Store`
+func (s *SQLStore) Get(id string) (*Delivery, error) {
+	ret, err := s.query(fmt.Sprintf("SELECT %s FROM %s WHERE id = %s",
+		sqlColumns, s.Table, s.placeholder(1)), id)
+	if err != nil || len(ret) == 0 {
+		return nil, err
+	}
+	return ret[0], nil
+}
+
+// Put implements `Store`
+func (s *SQLStore) Put(delivery *Delivery) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = %s", s.Table, s.placeholder(1)), delivery.ID)
+	if err == nil {
+		_, err = tx.Exec(fmt.Sprintf(
+			"INSERT INTO %s (%s) VALUES (%s, %s, %s, %s, %s, %s, %s, %s)",
+			s.Table, sqlColumns, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4),
+			s.placeholder(5), s.placeholder(6), s.placeholder(7), s.placeholder(8)),
+			delivery.ID, delivery.SubscriptionID, delivery.URL, string(delivery.Payload),
+			string(delivery.Status), delivery.Attempts, delivery.NextAttemptAt.UnixNano()/int64(time.Millisecond),
+			delivery.LastError)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Due implements `Store`
+func (s *SQLStore) Due(now time.Time, limit int) ([]*Delivery, error) {
+	return s.query(fmt.Sprintf(
+		"SELECT %s FROM %s WHERE status = %s AND next_attempt_at <= %s ORDER BY next_attempt_at%s",
+		sqlColumns, s.Table, s.placeholder(1), s.placeholder(2), limitClause(limit)),
+		string(StatusPending), now.UnixNano()/int64(time.Millisecond))
+}
+
+// DeadLetters implements `Store`
+func (s *SQLStore) DeadLetters(limit int) ([]*Delivery, error) {
+	return s.query(fmt.Sprintf("SELECT %s FROM %s WHERE status = %s ORDER BY id%s",
+		sqlColumns, s.Table, s.placeholder(1), limitClause(limit)), string(StatusDead))
+}
+
+func (s *SQLStore) query(query string, args ...interface{}) ([]*Delivery, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret []*Delivery
+	for rows.Next() {
+		var d Delivery
+		var payload, status string
+		var nextAttemptAt int64
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.URL, &payload, &status,
+			&d.Attempts, &nextAttemptAt, &d.LastError); err != nil {
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		d.Status = Status(status)
+		d.NextAttemptAt = time.Unix(0, nextAttemptAt*int64(time.Millisecond))
+		ret = append(ret, &d)
+	}
+	return ret, rows.Err()
+}
+
+func limitClause(limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" LIMIT %d", limit)
+}
+
+func (s *SQLStore) placeholder(n int) string {
+	return sqlutil.Placeholder(s.Placeholder, n)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) The Diem Core Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diem/client-sdk-go/internal/sqlutil/sqltest"
+	"github.com/diem/client-sdk-go/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeDB returns `sqltest.DB` serving the statements of `webhooks.SQLStore` from memory
+func newFakeDB() *sqltest.DB {
+	rows := make(map[string][]driver.Value)
+	columns := []string{"id", "subscription_id", "url", "payload", "status", "attempts", "next_attempt_at", "last_error"}
+	return &sqltest.DB{
+		Exec: func(query string, args []driver.Value) (driver.Result, error) {
+			switch {
+			case strings.HasPrefix(query, "CREATE"):
+				return driver.RowsAffected(0), nil
+			case strings.HasPrefix(query, "DELETE"):
+				delete(rows, args[0].(string))
+				return driver.RowsAffected(1), nil
+			case strings.HasPrefix(query, "INSERT INTO"):
+				rows[args[0].(string)] = args
+				return driver.RowsAffected(1), nil
+			}
+			return nil, fmt.Errorf("unexpected statement: %s", query)
+		},
+		Query: func(query string, args []driver.Value) (*sqltest.Rows, error) {
+			ret := &sqltest.Rows{Columns: columns}
+			for _, row := range rows {
+				switch {
+				case strings.Contains(query, "WHERE id ="):
+					if row[0] != args[0] {
+						continue
+					}
+				case strings.Contains(query, "AND next_attempt_at <="):
+					if row[4] != args[0] || row[6].(int64) > args[1].(int64) {
+						continue
+					}
+				default:
+					if row[4] != args[0] {
+						continue
+					}
+				}
+				ret.Values = append(ret.Values, row)
+			}
+			orderBy := 0
+			if strings.Contains(query, "ORDER BY next_attempt_at") {
+				orderBy = 6
+			}
+			sort.Slice(ret.Values, func(i, j int) bool {
+				return fmt.Sprint(ret.Values[i][orderBy]) < fmt.Sprint(ret.Values[j][orderBy])
+			})
+			var limit int
+			if i := strings.Index(query, " LIMIT "); i > 0 {
+				fmt.Sscanf(query[i:], " LIMIT %d", &limit)
+			}
+			if limit > 0 && len(ret.Values) > limit {
+				ret.Values = ret.Values[:limit]
+			}
+			return ret, nil
+		},
+	}
+}
+
+func TestSQLStore(t *testing.T) {
+	db := newFakeDB()
+	store := webhooks.NewSQLStore(db.Open(), "deliveries")
+	store.Placeholder = webhooks.DollarPlaceholder
+	require.NoError(t, store.CreateTable())
+
+	now := time.Unix(1600000000, 0)
+	delivery := func(id string, status webhooks.Status, next time.Duration) *webhooks.Delivery {
+		return &webhooks.Delivery{
+			ID:             id,
+			SubscriptionID: "sub",
+			URL:            "https://example.com/hook",
+			Payload:        []byte(`{"delivery_id":"` + id + `"}`),
+			Status:         status,
+			Attempts:       1,
+			NextAttemptAt:  now.Add(next),
+			LastError:      "status code 500",
+		}
+	}
+
+	ret, err := store.Get("sub:key:1")
+	require.NoError(t, err)
+	assert.Nil(t, ret)
+
+	require.NoError(t, store.Put(delivery("sub:key:1", webhooks.StatusPending, time.Second)))
+	require.NoError(t, store.Put(delivery("sub:key:2", webhooks.StatusPending, -time.Second)))
+	require.NoError(t, store.Put(delivery("sub:key:3", webhooks.StatusPending, -2*time.Second)))
+	require.NoError(t, store.Put(delivery("sub:key:4", webhooks.StatusDead, -time.Second)))
+
+	ret, err = store.Get("sub:key:1")
+	require.NoError(t, err)
+	assert.Equal(t, delivery("sub:key:1", webhooks.StatusPending, time.Second), ret)
+
+	t.Run("put overwrites existing delivery", func(t *testing.T) {
+		updated := delivery("sub:key:1", webhooks.StatusDelivered, time.Second)
+		updated.Attempts = 2
+		require.NoError(t, store.Put(updated))
+		ret, err := store.Get("sub:key:1")
+		require.NoError(t, err)
+		assert.Equal(t, updated, ret)
+	})
+
+	t.Run("due deliveries ordered by next attempt time", func(t *testing.T) {
+		due, err := store.Due(now, 0)
+		require.NoError(t, err)
+		require.Len(t, due, 2)
+		assert.Equal(t, "sub:key:3", due[0].ID)
+		assert.Equal(t, "sub:key:2", due[1].ID)
+
+		due, err = store.Due(now, 1)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, "sub:key:3", due[0].ID)
+	})
+
+	t.Run("dead letters", func(t *testing.T) {
+		dead, err := store.DeadLetters(0)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, delivery("sub:key:4", webhooks.StatusDead, -time.Second), dead[0])
+	})
+
+	t.Run("placeholders", func(t *testing.T) {
+		for _, query := range db.Queries()[2:] {
+			assert.NotContains(t, query, "?")
+			assert.Contains(t, query, "$1")
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db.Fail(errors.New("connection lost"))
+		defer db.Fail(nil)
+		_, err := store.Get("sub:key:1")
+		assert.EqualError(t, err, "connection lost")
+		assert.EqualError(t, store.Put(delivery("sub:key:5", webhooks.StatusPending, 0)), "connection lost")
+	})
+}